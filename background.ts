@@ -10,12 +10,15 @@ import semver from 'semver';
 
 import { State } from '@pkg/backend/backend';
 import BackendHelper from '@pkg/backend/backendHelper';
+import { connectToEngine } from '@pkg/backend/engineSocket';
 import K8sFactory from '@pkg/backend/factory';
 import { getImageProcessor } from '@pkg/backend/images/imageFactory';
 import { ImageProcessor } from '@pkg/backend/images/imageProcessor';
 import * as K8s from '@pkg/backend/k8s';
 import { Steve } from '@pkg/backend/steve';
-import { FatalCommandLineOptionError, LockedFieldError, updateFromCommandLine } from '@pkg/config/commandLineOptions';
+import {
+  AutostartOverrides, FatalCommandLineOptionError, LockedFieldError, updateFromCommandLine,
+} from '@pkg/config/commandLineOptions';
 import { Help } from '@pkg/config/help';
 import * as settings from '@pkg/config/settings';
 import * as settingsImpl from '@pkg/config/settingsImpl';
@@ -23,12 +26,15 @@ import { TransientSettings } from '@pkg/config/transientSettings';
 import { IntegrationManager, getIntegrationManager } from '@pkg/integrations/integrationManager';
 import { PathManager } from '@pkg/integrations/pathManager';
 import { getPathManagerFor } from '@pkg/integrations/pathManagerImpl';
-import { CommandWorkerInterface, HttpCommandServer, BackendState } from '@pkg/main/commandServer/httpCommandServer';
+import {
+  CommandWorkerInterface, HttpCommandServer, BackendState, VMLifecycleAction,
+} from '@pkg/main/commandServer/httpCommandServer';
+import type { JobProgress } from '@pkg/main/commandServer/jobs';
 import SettingsValidator from '@pkg/main/commandServer/settingsValidator';
 import { HttpCredentialHelperServer } from '@pkg/main/credentialServer/httpCredentialHelperServer';
 import { DashboardServer } from '@pkg/main/dashboardServer';
 import { DeploymentProfileError, readDeploymentProfiles } from '@pkg/main/deploymentProfiles';
-import { DiagnosticsManager, DiagnosticsResultCollection } from '@pkg/main/diagnostics/diagnostics';
+import { DiagnosticsManager, DiagnosticsResult, DiagnosticsResultCollection } from '@pkg/main/diagnostics/diagnostics';
 import { ExtensionErrorCode, isExtensionError } from '@pkg/main/extensions';
 import { ImageEventHandler } from '@pkg/main/imageEvents';
 import { getIpcMainProxy } from '@pkg/main/ipcMain';
@@ -57,6 +63,13 @@ import { openPreferences, preferencesSetDirtyFlag } from '@pkg/window/preference
 
 Electron.app.setPath('cache', paths.cache);
 Electron.app.setAppLogsPath(paths.logs);
+// Have the Go helpers we launch write their crash reports next to our logs.
+process.env.RD_CRASH_DIR ||= paths.logs;
+if (process.env.RD_INSTANCE) {
+  // The single instance lock is tied to the userData directory; give each
+  // named instance its own so they can run side by side.
+  Electron.app.setPath('userData', `${ Electron.app.getPath('userData') }-${ process.env.RD_INSTANCE }`);
+}
 
 const console = Logging.background;
 
@@ -153,6 +166,12 @@ mainEvents.on('settings-update', async(newSettings) => {
   }
 
   await runRdctlSetup(newSettings);
+
+  try {
+    await dockerDirManager.setNoneCredHelperEncryption(newSettings.containerEngine.credentials.encrypt);
+  } catch (ex) {
+    console.error('Failed to configure docker-credential-none encryption:', ex);
+  }
 });
 
 mainEvents.handle('settings-fetch', () => {
@@ -295,7 +314,7 @@ Electron.app.whenReady().then(async() => {
       Tray.getInstance(cfg).show();
     }
 
-    if (!cfg.application.startInBackground) {
+    if (!cfg.application.startInBackground && !AutostartOverrides.startInBackground) {
       window.openMain();
     } else if (Electron.app.dock) {
       Electron.app.dock.hide();
@@ -425,14 +444,18 @@ async function startBackend() {
  */
 async function startK8sManager() {
   const changedContainerEngine = currentContainerEngine !== cfg.containerEngine.name;
+  // `--autostart=no-kubernetes` only applies to the first start; the saved
+  // setting is used once the backend is restarted.
+  const startCfg = AutostartOverrides.disableKubernetes ? _.merge({}, cfg, { kubernetes: { enabled: false } }) : cfg;
 
+  AutostartOverrides.disableKubernetes = false;
   currentContainerEngine = cfg.containerEngine.name;
-  enabledK8s = cfg.kubernetes.enabled;
+  enabledK8s = startCfg.kubernetes.enabled;
 
   if (changedContainerEngine) {
     setupImageProcessor();
   }
-  await k8smanager.start(cfg);
+  await k8smanager.start(startCfg);
 
   const getEM = (await import('@pkg/main/extensions/manager')).default;
 
@@ -630,6 +653,28 @@ function backendIsBusy() {
   return [K8s.State.STARTING, K8s.State.STOPPING].includes(k8smanager.state);
 }
 
+/**
+ * Reset the backend, letting any errors propagate; see doK8sReset().
+ */
+async function resetK8s(arg: 'fast' | 'wipe' | 'fullRestart'): Promise<void> {
+  switch (arg) {
+  case 'fast':
+    await k8smanager.reset(cfg);
+    break;
+  case 'fullRestart':
+    await k8smanager.stop();
+    console.log(`Stopped Kubernetes backend cleanly.`);
+    await startK8sManager();
+    break;
+  case 'wipe':
+    console.log('Deleting VM to reset...');
+    await k8smanager.del();
+    console.log(`Deleted VM to reset exited cleanly.`);
+    await startK8sManager();
+    break;
+  }
+}
+
 async function doK8sReset(arg: 'fast' | 'wipe' | 'fullRestart', context: CommandWorkerInterface.CommandContext): Promise<void> {
   // If not in a place to restart than skip it
   if (backendIsBusy()) {
@@ -639,22 +684,7 @@ async function doK8sReset(arg: 'fast' | 'wipe' | 'fullRestart', context: Command
   }
 
   try {
-    switch (arg) {
-    case 'fast':
-      await k8smanager.reset(cfg);
-      break;
-    case 'fullRestart':
-      await k8smanager.stop();
-      console.log(`Stopped Kubernetes backend cleanly.`);
-      await startK8sManager();
-      break;
-    case 'wipe':
-      console.log('Deleting VM to reset...');
-      await k8smanager.del();
-      console.log(`Deleted VM to reset exited cleanly.`);
-      await startK8sManager();
-      break;
-    }
+    await resetK8s(arg);
   } catch (ex) {
     if (context.interactive) {
       handleFailure(ex);
@@ -739,7 +769,7 @@ async function doFactoryReset(keepSystemImages: boolean) {
   // Don't wait for this process to return -- the whole point is for us to not be running.
   const tmpdir = os.tmpdir();
   const outfile = await fs.promises.open(path.join(tmpdir, 'rdctl-stdout.txt'), 'w');
-  const args = ['factory-reset', `--remove-kubernetes-cache=${ (!keepSystemImages) ? 'true' : 'false' }`];
+  const args = ['factory-reset', '--yes', `--remove-kubernetes-cache=${ (!keepSystemImages) ? 'true' : 'false' }`];
 
   if (cfg.application.debug) {
     args.push('--verbose=true');
@@ -1188,12 +1218,28 @@ class BackgroundCommandWorker implements CommandWorkerInterface {
     return diagnostics.getIdsForCategory(category);
   }
 
-  getDiagnosticChecks(category: string|null, checkID: string|null): Promise<DiagnosticsResultCollection> {
-    return diagnostics.getChecks(category, checkID);
+  async getDiagnosticChecks(category: string|null, checkID: string|null): Promise<DiagnosticsResultCollection> {
+    return this.applyMutedChecks(await diagnostics.getChecks(category, checkID));
+  }
+
+  async runDiagnosticChecks(): Promise<DiagnosticsResultCollection> {
+    return this.applyMutedChecks(await diagnostics.runChecks());
   }
 
-  runDiagnosticChecks(): Promise<DiagnosticsResultCollection> {
-    return diagnostics.runChecks();
+  async repairDiagnostic(context: CommandWorkerInterface.CommandContext, checkID: string): Promise<DiagnosticsResult|undefined> {
+    const result = await diagnostics.repair(checkID);
+
+    return result && { ...result, mute: !!cfg.diagnostics.mutedChecks[result.id] };
+  }
+
+  /**
+   * The diagnostics manager doesn't know which checks the user muted; fill
+   * that in from the settings so API clients see the same state as the UI.
+   */
+  protected applyMutedChecks(results: DiagnosticsResultCollection): DiagnosticsResultCollection {
+    const mutedChecks = cfg.diagnostics.mutedChecks;
+
+    return { ...results, checks: results.checks.map(check => ({ ...check, mute: !!mutedChecks[check.id] })) };
   }
 
   factoryReset(keepSystemImages: boolean) {
@@ -1229,9 +1275,10 @@ class BackgroundCommandWorker implements CommandWorkerInterface {
    *   1. a description of the status of the request, if it was valid
    *   2. a list of any errors in the request body.
    * @param specifiedNewSettings: a subset of the Settings object, containing the desired values
-   * @returns [{string} description of final state if no error, {string} error message]
+   * @returns [{string} description of final state if no error, {string} error message,
+   *   {boolean} whether the backend will restart]
    */
-  async updateSettings(context: CommandWorkerInterface.CommandContext, specifiedNewSettings: RecursivePartial<settings.Settings>): Promise<[string, string]> {
+  async updateSettings(context: CommandWorkerInterface.CommandContext, specifiedNewSettings: RecursivePartial<settings.Settings>): Promise<[string, string, boolean?]> {
     let errors: string[] = [];
     let needToUpdate = false;
     let newSettings: RecursivePartial<settings.Settings> = {};
@@ -1270,12 +1317,12 @@ class BackgroundCommandWorker implements CommandWorkerInterface {
       pendingRestartContext = undefined;
       setImmediate(doFullRestart, context);
 
-      return ['reconfiguring Rancher Desktop to apply changes (this may take a while)', ''];
+      return ['reconfiguring Rancher Desktop to apply changes (this may take a while)', '', true];
     } else {
       // Call doFullRestart once the UI is finished starting or stopping
       pendingRestartContext = context;
 
-      return ['UI is currently busy, but will eventually be reconfigured to apply requested changes', ''];
+      return ['UI is currently busy, but will eventually be reconfigured to apply requested changes', '', true];
     }
   }
 
@@ -1422,12 +1469,71 @@ class BackgroundCommandWorker implements CommandWorkerInterface {
     }
   }
 
+  async changeVMState(context: CommandWorkerInterface.CommandContext, action: VMLifecycleAction): Promise<{status: number, data: string}> {
+    if (backendIsLocked) {
+      return { status: 409, data: `Cannot ${ action } the VM: ${ backendIsLocked }` };
+    }
+    if (backendIsBusy()) {
+      return { status: 409, data: `Cannot ${ action } the VM while it is ${ k8smanager.state }` };
+    }
+    const running = [State.STARTED, State.DISABLED].includes(k8smanager.state);
+
+    switch (action) {
+    case 'start':
+      if (running) {
+        return { status: 200, data: 'The VM is already running' };
+      }
+      setImmediate(() => startBackend());
+
+      return { status: 202, data: 'Starting the VM' };
+    case 'stop':
+      if (k8smanager.state === State.STOPPED) {
+        return { status: 200, data: 'The VM is already stopped' };
+      }
+      setImmediate(() => k8smanager.stop());
+
+      return { status: 202, data: 'Stopping the VM' };
+    case 'restart':
+      setImmediate(() => doK8sReset('fullRestart', context));
+
+      return { status: 202, data: 'Restarting the VM' };
+    case 'pause':
+      return { status: 501, data: `Pausing the VM is not supported by the ${ k8smanager.backend } backend` };
+    }
+  }
+
+  async resetKubernetes(context: CommandWorkerInterface.CommandContext, mode: 'fast' | 'wipe', progress: (progress: JobProgress) => void): Promise<void> {
+    if (backendIsLocked) {
+      throw new Error(`Cannot reset Kubernetes: ${ backendIsLocked }`);
+    }
+    if (backendIsBusy()) {
+      throw new Error(`Cannot reset Kubernetes while the backend is ${ k8smanager.state }`);
+    }
+
+    const listener = () => {
+      const { current, max, description } = k8smanager.progress;
+
+      progress({ current, max, description });
+    };
+
+    k8smanager.on('progress', listener);
+    try {
+      await resetK8s(mode);
+    } finally {
+      k8smanager.off('progress', listener);
+    }
+  }
+
+  connectToEngine(context: CommandWorkerInterface.CommandContext) {
+    return connectToEngine(k8smanager, cfg.containerEngine.name);
+  }
+
   async listSnapshots(context: CommandWorkerInterface.CommandContext) {
     return await Snapshots.list();
   }
 
   async createSnapshot(context: CommandWorkerInterface.CommandContext, snapshot: Snapshot) {
-    return await Snapshots.create(snapshot);
+    return await Snapshots.create(snapshot, context.signal);
   }
 
   async restoreSnapshot(context: CommandWorkerInterface.CommandContext, name: string) {
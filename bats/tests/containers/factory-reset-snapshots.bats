@@ -14,7 +14,7 @@ load '../helpers/load'
 @test "Verify the snapshot dir isn't deleted on factory-reset" {
     rdctl shutdown
     rdctl snapshot create shortlived-snapshot
-    rdctl factory-reset
+    rdctl factory-reset --yes
     assert_not_exists "$PATH_APP_HOME/rd-engine.json"
     assert_exists "$PATH_SNAPSHOTS"
     run ls -A "$PATH_SNAPSHOTS"
@@ -23,7 +23,7 @@ load '../helpers/load'
 }
 
 @test 'Verify factory-reset deletes an empty snapshots directory' {
-    rdctl snapshot delete shortlived-snapshot
-    rdctl factory-reset
+    rdctl snapshot delete shortlived-snapshot --yes
+    rdctl factory-reset --yes
     assert_not_exists "$PATH_APP_HOME"
 }
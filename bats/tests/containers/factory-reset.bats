@@ -142,7 +142,7 @@ load '../helpers/load'
 
 rdctl_factory_reset() {
     capture_logs
-    rdctl factory-reset "$@"
+    rdctl factory-reset --yes "$@"
 
     if [[ $1 == "--remove-kubernetes-cache=true" ]]; then
         assert_not_exist "$PATH_CACHE"
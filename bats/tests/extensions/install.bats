@@ -71,7 +71,7 @@ encoded_id() { # variant
     assert_success
     port="$(jq_output .port)"
     assert [ -n "$port" ]
-    run curl --fail "http://127.0.0.1:${port}/v1/settings"
+    run curl --fail --cacert "${PATH_APP_HOME}/rd-engine.crt" "https://127.0.0.1:${port}/v1/settings"
     assert_failure
     assert_output --partial "The requested URL returned error: 401"
 }
@@ -2,7 +2,7 @@ delete_all_snapshots() {
     run rdctl snapshot list --json
     assert_success
     jq_output .name | while IFS= read -r name; do
-        run rdctl snapshot delete "$name"
+        run rdctl snapshot delete "$name" --yes
         assert_success
     done
 }
@@ -59,7 +59,7 @@ factory_reset() {
         clear_iptables_chain "CNI"
         clear_iptables_chain "KUBE"
     fi
-    rdctl factory-reset
+    rdctl factory-reset --yes
 }
 
 # Turn `rdctl start` arguments into `yarn dev` arguments
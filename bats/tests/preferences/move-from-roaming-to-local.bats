@@ -37,6 +37,6 @@ local_setup() {
 }
 
 @test 'verify factory-reset deletes all of Roaming/rancher-desktop' {
-    rdctl factory-reset
+    rdctl factory-reset --yes
     assert_not_exists "$ROAMING_HOME"
 }
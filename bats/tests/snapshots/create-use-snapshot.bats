@@ -32,7 +32,7 @@ local_setup() {
     assert_output --partial "$SNAPSHOT"
     assert_output --partial "$snapshot_description"
 
-    rdctl factory-reset
+    rdctl factory-reset --yes
 }
 
 @test 'startup, verify using new settings' {
@@ -53,7 +53,7 @@ local_setup() {
 # This should be one long test because if `snapshot restore` fails there's no point starting up
 @test 'shutdown, restore, restart and verify snapshot state' {
     rdctl shutdown
-    run rdctl snapshot restore "$SNAPSHOT"
+    run rdctl snapshot restore "$SNAPSHOT" --yes
     assert_success
     refute_output --partial fail
 
@@ -115,7 +115,7 @@ local_setup() {
     assert_output "$snapshot_description"
 
     # And we can delete that snapshot
-    run rdctl snapshot delete "$snapshot_id" --json
+    run rdctl snapshot delete "$snapshot_id" --yes --json
     assert_success
     assert_output ""
 }
@@ -166,13 +166,13 @@ local_setup() {
 }
 
 @test "factory-reset doesn't delete a non-empty snapshots directory" {
-    rdctl factory-reset
+    rdctl factory-reset --yes
     assert_exists "$PATH_SNAPSHOTS"
 }
 
 @test 'factory-reset does delete an empty snapshots directory' {
     delete_all_snapshots
-    rdctl factory-reset
+    rdctl factory-reset --yes
     assert_not_exists "$PATH_SNAPSHOTS"
 }
 
@@ -33,11 +33,11 @@ local_setup() {
 }
 
 @test 'do a factory reset' {
-    rdctl factory-reset
+    rdctl factory-reset --yes
 }
 
 @test 'restore the snapshot without starting up first' {
-    run rdctl snapshot restore "$SNAPSHOT"
+    run rdctl snapshot restore "$SNAPSHOT" --yes
     assert_success
 }
 
@@ -63,7 +63,7 @@ local_setup() {
 }
 
 @test 'delete the snapshot and verify there are no others' {
-    rdctl snapshot delete "$SNAPSHOT"
+    rdctl snapshot delete "$SNAPSHOT" --yes
     run rdctl snapshot list --json
     assert_success
     assert_output ''
@@ -1,4 +1,5 @@
 import fs from 'fs';
+import https from 'https';
 import os from 'os';
 import path from 'path';
 
@@ -38,6 +39,7 @@ test.describe.serial('KubernetesBackend', () => {
 
   test.describe('requiresRestartReasons', () => {
     let serverState: { user: string, password: string, port: string, pid: string };
+    let agent: https.Agent;
 
     test.afterEach(async() => {
       // Wait for the backend to stop (it's okay to fail to start here though)
@@ -59,11 +61,12 @@ test.describe.serial('KubernetesBackend', () => {
         port:     expect.any(Number),
         pid:      expect.any(Number),
       }));
+      agent = new https.Agent({ ca: await fs.promises.readFile(path.join(paths.appHome, 'rd-engine.crt'), 'utf-8') });
     });
 
     async function get(requestPath: string) {
       const auth = Buffer.from(`${ serverState.user }:${ serverState.password }`).toString('base64');
-      const result = await fetch(`http://127.0.0.1:${ serverState.port }/${ requestPath.replace(/^\//, '') }`, { headers: { Authorization: `basic ${ auth }` } });
+      const result = await fetch(`https://127.0.0.1:${ serverState.port }/${ requestPath.replace(/^\//, '') }`, { agent, headers: { Authorization: `basic ${ auth }` } });
 
       expect(result).toEqual(expect.objectContaining({ ok: true }));
 
@@ -72,7 +75,8 @@ test.describe.serial('KubernetesBackend', () => {
 
     async function put(requestPath: string, body: any) {
       const auth = Buffer.from(`${ serverState.user }:${ serverState.password }`).toString('base64');
-      const result = await fetch(`http://127.0.0.1:${ serverState.port }/${ requestPath.replace(/^\//, '') }`, {
+      const result = await fetch(`https://127.0.0.1:${ serverState.port }/${ requestPath.replace(/^\//, '') }`, {
+        agent,
         body:    JSON.stringify(body),
         headers: { Authorization: `basic ${ auth }` },
         method:  'PUT',
@@ -224,7 +224,7 @@ describeWithCreds('Credentials server', () => {
   test.describe.configure({ mode: 'serial' });
 
   test.beforeAll(async() => {
-    await tool('rdctl', 'factory-reset', '--verbose');
+    await tool('rdctl', 'factory-reset', '--yes', '--verbose');
     const result = await startSlowerDesktop(__filename, { kubernetes: { enabled: false } });
 
     electronApp = result[0] as ElectronApplication;
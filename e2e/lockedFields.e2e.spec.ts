@@ -73,7 +73,7 @@ test.describe('Locked fields', () => {
   const proposedK8sVersion = '1.26.1';
 
   test.beforeAll(async() => {
-    await tool('rdctl', 'factory-reset', '--verbose');
+    await tool('rdctl', 'factory-reset', '--yes', '--verbose');
     reopenLogs();
   });
 
@@ -94,7 +94,7 @@ test.describe('Locked fields', () => {
 
   test.afterAll(async() => {
     await teardown(electronApp, __filename);
-    await tool('rdctl', 'factory-reset', '--verbose');
+    await tool('rdctl', 'factory-reset', '--yes', '--verbose');
     reopenLogs();
   });
 
@@ -32,7 +32,7 @@ test.describe.serial('Main App Test', () => {
 
   test.afterAll(async() => {
     await teardown(electronApp, __filename);
-    await tool('rdctl', 'factory-reset', '--verbose');
+    await tool('rdctl', 'factory-reset', '--yes', '--verbose');
     reopenLogs();
   });
 
@@ -19,6 +19,7 @@ limitations under the License.
  */
 
 import fs from 'fs';
+import https from 'https';
 import os from 'os';
 import path from 'path';
 
@@ -54,14 +55,16 @@ import type { ElectronApplication, Page } from '@playwright/test';
 test.describe('Command server', () => {
   let electronApp: ElectronApplication;
   let serverState: ServerState;
+  let agent: https.Agent;
   let page: Page;
   const ENOENTMessage = os.platform() === 'win32' ? 'The system cannot find the file specified' : 'no such file or directory';
   const appPath = path.join(__dirname, '../');
 
   async function doRequest(path: string, body = '', method = 'GET') {
-    const url = `http://127.0.0.1:${ serverState.port }/${ path.replace(/^\/*/, '') }`;
+    const url = `https://127.0.0.1:${ serverState.port }/${ path.replace(/^\/*/, '') }`;
     const auth = `${ serverState.user }:${ serverState.password }`;
     const init: RequestInit = {
+      agent,
       method,
       headers: {
         Authorization: `Basic ${ Buffer.from(auth)
@@ -143,11 +146,12 @@ test.describe('Command server', () => {
       port:     expect.any(Number),
       pid:      expect.any(Number),
     }));
+    agent = new https.Agent({ ca: await fs.promises.readFile(path.join(paths.appHome, 'rd-engine.crt'), 'utf-8') });
   });
 
   test('should require authentication, settings request', async() => {
-    const url = `http://127.0.0.1:${ serverState.port }/v1/settings`;
-    const resp = await fetch(url);
+    const url = `https://127.0.0.1:${ serverState.port }/v1/settings`;
+    const resp = await fetch(url, { agent });
 
     expect(resp).toEqual(expect.objectContaining({
       ok:     false,
@@ -349,8 +353,8 @@ test.describe('Command server', () => {
   });
 
   test('should require authentication, transient settings request', async() => {
-    const url = `http://127.0.0.1:${ serverState.port }/v1/transient_settings`;
-    const resp = await fetch(url);
+    const url = `https://127.0.0.1:${ serverState.port }/v1/transient_settings`;
+    const resp = await fetch(url, { agent });
 
     expect(resp).toEqual(expect.objectContaining({
       ok:     false,
@@ -543,6 +547,19 @@ test.describe('Command server', () => {
     expect(body).toContain('no settings specified in the request');
   });
 
+  test('read-only tokens only allow GET requests', async() => {
+    const resp = await doRequest('/v1/tokens', JSON.stringify({ scope: 'read', ttl: 60 }), 'POST');
+
+    expect(resp.ok).toBeTruthy();
+    const { token } = await resp.json();
+    const withToken = (method: string) => fetch(`https://127.0.0.1:${ serverState.port }/v1/transient_settings`, {
+      agent, method, headers: { Authorization: `Bearer ${ token }` }, body: method === 'GET' ? undefined : '{}',
+    });
+
+    expect((await withToken('GET')).status).toEqual(200);
+    expect((await withToken('PUT')).status).toEqual(403);
+  });
+
   test.describe('v0 API', () => {
     const endpoints = {
       GET:  ['diagnostic_categories', 'diagnostic_checks', 'diagnostic_ids', 'settings', 'transient_settings'],
@@ -604,7 +621,9 @@ test.describe('Command server', () => {
         });
 
         test('it works with all parameters,', async() => {
-          const { stdout, stderr, error } = await rdctl(parameters.concat(['list-settings']));
+          // The server certificate is normally found next to the config file.
+          const certificate = `--certificate=${ path.join(paths.appHome, 'rd-engine.crt') }`;
+          const { stdout, stderr, error } = await rdctl(parameters.concat([certificate, 'list-settings']));
 
           expect({
             stdout, stderr, error,
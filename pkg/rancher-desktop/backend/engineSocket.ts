@@ -0,0 +1,72 @@
+import net from 'net';
+import path from 'path';
+import stream from 'stream';
+
+import { State, VMBackend } from '@pkg/backend/backend';
+import WSLBackend from '@pkg/backend/wsl';
+import { ContainerEngine } from '@pkg/config/settings';
+import Logging from '@pkg/utils/logging';
+import paths from '@pkg/utils/paths';
+
+const console = Logging.background;
+
+/** The sockets of the container engines, inside the VM. */
+const GUEST_ENGINE_SOCKETS: Partial<Record<ContainerEngine, string>> = {
+  [ContainerEngine.MOBY]:       '/var/run/docker.sock',
+  [ContainerEngine.CONTAINERD]: '/run/k3s/containerd/containerd.sock',
+};
+
+/**
+ * EngineUnavailableError is thrown when the container engine socket can't be
+ * reached in the current configuration.
+ */
+export class EngineUnavailableError extends Error {
+}
+
+/**
+ * Open a connection to the socket of the container engine inside the VM.
+ * This doesn't rely on the socket forwarding set up for the user (the docker
+ * context, or the named pipe on Windows):
+ * - On Windows, `wsl-helper dial-stdio` relays the socket inside the distro.
+ * - Otherwise, the moby socket forwarded by Lima is used; the containerd
+ *   socket is not forwarded, so it is not available.
+ */
+export async function connectToEngine(backend: VMBackend, engine: ContainerEngine): Promise<stream.Duplex> {
+  if (![State.STARTED, State.DISABLED].includes(backend.state)) {
+    throw new EngineUnavailableError(`The container engine is not running (the backend is ${ backend.state })`);
+  }
+  const guestSocket = GUEST_ENGINE_SOCKETS[engine];
+
+  if (!guestSocket) {
+    throw new EngineUnavailableError(`Unknown container engine ${ engine }`);
+  }
+
+  if (backend.executor instanceof WSLBackend) {
+    const helper = await backend.executor.getWSLHelperPath();
+    const child = backend.executor.spawn(helper, 'dial-stdio', '--socket', guestSocket);
+    const connection = stream.Duplex.from({ readable: child.stdout, writable: child.stdin });
+
+    child.stderr?.on('data', (data: Buffer) => {
+      console.log(`engine proxy: ${ data.toString().trimEnd() }`);
+    });
+    connection.on('close', () => child.kill());
+    child.on('exit', () => connection.destroy());
+
+    return connection;
+  }
+
+  if (engine !== ContainerEngine.MOBY) {
+    throw new EngineUnavailableError(`The engine proxy is not available for ${ engine } on this platform`);
+  }
+
+  const hostSocket = path.join(paths.altAppHome, 'docker.sock');
+
+  return await new Promise((resolve, reject) => {
+    const connection = net.connect(hostSocket)
+      .once('connect', () => {
+        connection.off('error', reject);
+        resolve(connection);
+      })
+      .once('error', reject);
+  });
+}
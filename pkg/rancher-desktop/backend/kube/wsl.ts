@@ -298,6 +298,10 @@ export default class WSLKubernetesBackend extends events.EventEmitter implements
         'kubernetes.options.flannel':            undefined,
         'kubernetes.options.traefik':            undefined,
         'kubernetes.port':                       undefined,
+        'virtualMachine.dns.domains':            undefined,
+        'virtualMachine.dns.ignoreVPN':          undefined,
+        'virtualMachine.dns.upstreamServers':    undefined,
+        'virtualMachine.gpu':                    undefined,
         'virtualMachine.hostResolver':           undefined,
         'WSL.integrations':                      undefined,
       },
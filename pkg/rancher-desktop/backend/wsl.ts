@@ -45,25 +45,28 @@ import WSL_EXEC from '@pkg/assets/scripts/wsl-exec';
 import WSL_INIT_SCRIPT from '@pkg/assets/scripts/wsl-init';
 import WSL_INIT_RD_NETWORKING_SCRIPT from '@pkg/assets/scripts/wsl-init-rd-networking';
 import { ContainerEngine } from '@pkg/config/settings';
-import { getServerCredentialsPath, ServerState } from '@pkg/main/credentialServer/httpCredentialHelperServer';
+import {
+  getServerCredentialsPath, PEER_PORT as CREDENTIAL_PEER_PORT, SERVER_PORT as CREDENTIAL_SERVER_PORT, ServerState,
+} from '@pkg/main/credentialServer/httpCredentialHelperServer';
 import mainEvents from '@pkg/main/mainEvents';
 import { getVtunnelInstance, getVtunnelConfigPath } from '@pkg/main/networking/vtunnel';
 import BackgroundProcess from '@pkg/utils/backgroundProcess';
 import * as childProcess from '@pkg/utils/childProcess';
 import clone from '@pkg/utils/clone';
+import { instanceSuffix, wslDistroNames } from '@pkg/utils/instance';
 import Logging from '@pkg/utils/logging';
 import { wslHostIPv4Address } from '@pkg/utils/networks';
 import paths from '@pkg/utils/paths';
 import { jsonStringifyWithWhiteSpace } from '@pkg/utils/stringify';
 import { defined, RecursivePartial } from '@pkg/utils/typeUtils';
+import { getWSLPreflight } from '@pkg/utils/wslVersion';
 
 import type { KubernetesBackend } from './k8s';
 
 /* eslint @typescript-eslint/switch-exhaustiveness-check: "error" */
 
 const console = Logging.wsl;
-const INSTANCE_NAME = 'rancher-desktop';
-const DATA_INSTANCE_NAME = 'rancher-desktop-data';
+const { main: INSTANCE_NAME, data: DATA_INSTANCE_NAME } = wslDistroNames();
 
 const ETC_RANCHER_DESKTOP_DIR = '/etc/rancher/desktop';
 const CREDENTIAL_FORWARDER_SETTINGS_PATH = `${ ETC_RANCHER_DESKTOP_DIR }/credfwd`;
@@ -92,6 +95,20 @@ const DISTRO_DATA_DIRS = [
   '/var/lib',
 ];
 
+/**
+ * WSLGPUState describes the GPU support found in the VM when it last started.
+ */
+export type WSLGPUState = {
+  /** Whether the user asked for the GPU to be exposed to containers. */
+  enabled: boolean;
+  /** Whether the GPU can be used in the VM. */
+  available: boolean;
+  /** Why the GPU can't be used, or why configuring it failed. */
+  reason?: string;
+  directML: boolean;
+  cuda: boolean;
+};
+
 type wslExecOptions = execOptions & {
   /** Output encoding; defaults to utf16le. */
   encoding?: BufferEncoding;
@@ -142,10 +159,15 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
         const exe = path.join(paths.resources, 'win32', 'internal', 'host-resolver.exe');
         const stream = await Logging['host-resolver-host'].fdStream;
         const wslHostAddr = wslHostIPv4Address();
-
-        return childProcess.spawn(exe, ['vsock-host',
+        const args = ['vsock-host',
           '--built-in-hosts',
-          `host.rancher-desktop.internal=${ wslHostAddr },host.docker.internal=${ wslHostAddr }`], {
+          `host.rancher-desktop.internal=${ wslHostAddr },host.docker.internal=${ wslHostAddr }`];
+
+        if (this.resolverUpstreamServers.length > 0) {
+          args.push('--upstream-servers', this.resolverUpstreamServers.join(','));
+        }
+
+        return childProcess.spawn(exe, args, {
           stdio:       ['ignore', stream, stream],
           windowsHide: true,
         });
@@ -163,6 +185,7 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
         peerPort:              3040,
         upstreamServerAddress: 'npipe:////./pipe/rancher_desktop/privileged_service',
       });
+      this.vtun.setPeerReloader(() => this.execService('vtunnel-peer', 'reload', '--ifstarted'));
     }
 
     this.kubeBackend = kubeFactory(this);
@@ -220,6 +243,12 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
    */
   protected resolverHostProcess: BackgroundProcess;
 
+  /**
+   * The DNS servers the host resolver forwards queries to; if empty, it uses
+   * the system APIs.
+   */
+  protected resolverUpstreamServers: string[] = [];
+
   /**
    * Windows-side process for the Rancher Desktop Networking,
    * it is used to provide DNS, DHCP and Port Forwarding
@@ -555,6 +584,57 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
       ]));
   }
 
+  /**
+   * Return the DNS servers to forward queries to: those configured, or if
+   * asked to ignore VPN DNS servers, those of the host's other adapters.  An
+   * empty list means the host's configuration is used as is.
+   */
+  protected async getUpstreamDNSServers(config: BackendSettings): Promise<string[]> {
+    const { upstreamServers, ignoreVPN } = config.virtualMachine.dns;
+
+    if (upstreamServers.length > 0 || !ignoreVPN) {
+      return upstreamServers;
+    }
+    try {
+      const wslHelper = path.join(paths.resources, 'win32', 'wsl-helper.exe');
+      const { stdout } = await childProcess.spawnFile(wslHelper, ['wsl', 'host-dns', '--exclude-vpn'], { stdio: ['ignore', 'pipe', console] });
+
+      return JSON.parse(stdout);
+    } catch (ex) {
+      console.error('Failed to list the DNS servers of the host; using its configuration as is:', ex);
+
+      return [];
+    }
+  }
+
+  /**
+   * Write the dnsmasq configuration for the upstream and per-domain DNS
+   * servers.
+   * @param splitDNS Whether dnsmasq sits in front of the host resolver.
+   */
+  protected async writeDnsmasqServers(config: BackendSettings, splitDNS: boolean) {
+    const confPath = '/etc/dnsmasq.d/rancher-desktop-servers.conf';
+    const lines: string[] = [];
+
+    if (splitDNS) {
+      lines.push('no-resolv', 'bind-interfaces', 'server=127.0.0.1');
+    } else if (!config.virtualMachine.hostResolver && this.resolverUpstreamServers.length > 0) {
+      lines.push('no-resolv', ...this.resolverUpstreamServers.map(server => `server=${ server }`));
+    }
+    if (!config.virtualMachine.hostResolver || splitDNS) {
+      for (const rule of config.virtualMachine.dns.domains) {
+        const [domain, server] = rule.split('=');
+
+        lines.push(`server=/${ domain }/${ server }`);
+      }
+    }
+    if (lines.length > 0) {
+      await this.writeFile(confPath, lines.map(line => `${ line }\n`).join(''));
+    } else {
+      await this.execCommand('rm', '-f', confPath);
+    }
+  }
+
   /**
    * Mount the data distribution over.
    *
@@ -563,7 +643,8 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
    * killed once things are up.
    */
   protected async mountData(): Promise<childProcess.ChildProcess> {
-    const mountRoot = '/mnt/wsl/rancher-desktop/run/data';
+    // This is shared by all distributions, so each instance needs its own.
+    const mountRoot = `/mnt/wsl/rancher-desktop${ instanceSuffix() }/run/data`;
 
     await this.execCommand('mkdir', '-p', mountRoot);
     // Only bind mount the root if it doesn't exist; because this is in the
@@ -774,11 +855,11 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
     const credsPath = getServerCredentialsPath();
 
     try {
-      const vtunnelPeerServerAddr = '127.0.0.1:3030';
-      const credentialServerAddr = '192.168.127.254:6109';
+      const vtunnelPeerServerAddr = `127.0.0.1:${ CREDENTIAL_PEER_PORT }`;
+      const credentialServerAddr = `192.168.127.254:${ CREDENTIAL_SERVER_PORT }`;
       // When networkTunnel is enabled we talk directly to the host which is assigned
       // with 192.168.127.254 static address. Otherwise, we talk to the vtunnel peer
-      // which is listening in the WSL VM on 127.0.0.1:3030.
+      // which is listening in the WSL VM (on 127.0.0.1:3030 for the default instance).
       const credForwarderURL = this.cfg?.experimental.virtualMachine.networkingTunnel ? credentialServerAddr : vtunnelPeerServerAddr;
       const stateInfo: ServerState = JSON.parse(await fs.promises.readFile(credsPath, { encoding: 'utf-8' }));
       const escapedPassword = stateInfo.password.replace(/\\/g, '\\\\')
@@ -885,6 +966,29 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
     await this.wslInstall(trivyExecPath, '/usr/local/bin');
   }
 
+  /**
+   * Detect the GPU support WSL provides, and expose it to containers if it
+   * was requested.  Failures are reported via diagnostics instead of stopping
+   * the backend, as containers work fine without the GPU.
+   */
+  protected async configureGPU(enabled: boolean) {
+    const wslHelper = await this.getWSLHelperPath();
+    let state: WSLGPUState = {
+      enabled, available: false, directML: false, cuda: false,
+    };
+
+    try {
+      state = { ...state, ...JSON.parse(await this.captureCommand(wslHelper, 'wsl', 'gpu', '--mode=detect')) };
+      const mode = enabled && state.available ? 'enable' : 'disable';
+
+      await this.execCommand({ root: true }, wslHelper, 'wsl', 'gpu', `--mode=${ mode }`);
+    } catch (ex) {
+      console.error('Failed to configure GPU support:', ex);
+      state = { ...state, available: false, reason: `Failed to configure GPU support: ${ ex }` };
+    }
+    mainEvents.emit('wsl-gpu-state', state);
+  }
+
   protected async installGuestAgent(kubeVersion: semver.SemVer | undefined, cfg: BackendSettings | undefined) {
     let guestAgentConfig: Record<string, any>;
     const enableKubernetes = !!kubeVersion;
@@ -1093,6 +1197,27 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
       throw ex;
     }
 
+    try {
+      const report = await getWSLPreflight();
+      const fatal = report.problems.filter(problem => problem.fatal);
+
+      if (fatal.length > 0) {
+        const message = fatal
+          .map(problem => [problem.description, problem.fix].filter(Boolean).join('\n'))
+          .join('\n\n');
+
+        console.log(`WSL preflight checks failed: ${ fatal.map(problem => problem.id).join(', ') }`);
+
+        return new BackendError('Error: WSL Not Usable', message, true);
+      }
+      for (const problem of report.problems) {
+        console.log(`WSL preflight warning (${ problem.id }): ${ problem.description }`);
+      }
+    } catch (ex) {
+      // Don't block startup if wsl-helper can't run the checks.
+      console.error('Failed to run WSL preflight checks:', ex);
+    }
+
     return null;
   }
 
@@ -1372,21 +1497,32 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
                   await this.execCommand('/sbin/rc-update', 'add', 'host-resolver', 'default');
                   await this.execCommand('/sbin/rc-update', 'add', 'dnsmasq', 'default');
                   await this.execCommand('/sbin/rc-update', 'add', 'dnsmasq-generate', 'default');
+                  const { hostResolver, dns } = config.virtualMachine;
+                  // With per-domain servers, dnsmasq routes the queries, and
+                  // forwards the rest to the host resolver on the loopback
+                  // address.
+                  const splitDNS = hostResolver && dns.domains.length > 0;
+
+                  this.resolverUpstreamServers = await this.getUpstreamDNSServers(config);
                   await this.writeConf('host-resolver', {
                     RESOLVER_PEER_BINARY: await this.getHostResolverPeerPath(),
                     LOG_DIR:              logPath,
+                    ...(splitDNS ? { RESOLVER_LISTEN_ADDRESS: '127.0.0.1' } : {}),
                   });
+                  await this.writeDnsmasqServers(config, splitDNS);
                   // dnsmasq requires /var/lib/misc to exist
                   await this.execCommand('mkdir', '-p', '/var/lib/misc');
-                  if (config.virtualMachine.hostResolver) {
+                  if (hostResolver) {
                     console.debug(`setting DNS to host-resolver`);
                     try {
                       this.resolverHostProcess.start();
                     } catch (error) {
                       console.error('Failed to run host-resolver vsock-host process:', error);
                     }
-                    await this.execCommand('/sbin/rc-update', 'del', 'dnsmasq-generate', 'default');
-                    await this.execCommand('/sbin/rc-update', 'del', 'dnsmasq', 'default');
+                    if (!splitDNS) {
+                      await this.execCommand('/sbin/rc-update', 'del', 'dnsmasq-generate', 'default');
+                      await this.execCommand('/sbin/rc-update', 'del', 'dnsmasq', 'default');
+                    }
                   } else {
                     await this.execCommand('/sbin/rc-update', 'del', 'host-resolver', 'default');
                   }
@@ -1454,6 +1590,7 @@ export default class WSLBackend extends events.EventEmitter implements VMBackend
 
                 await this.execCommand({ root: true }, 'rm', '-f', obsoleteIALConfFile);
               }),
+              this.progressTracker.action('GPU configuration', 50, this.configureGPU(config.virtualMachine.gpu)),
               await this.progressTracker.action('Rancher Desktop guest agent', 50, this.installGuestAgent(kubernetesVersion, this.cfg)),
             ]);
 
@@ -3,7 +3,9 @@ import os from 'os';
 
 import _ from 'lodash';
 
-import { getObjectRepresentation, LockedFieldError, updateFromCommandLine } from '@pkg/config/commandLineOptions';
+import {
+  AutostartOverrides, getObjectRepresentation, LockedFieldError, updateFromCommandLine,
+} from '@pkg/config/commandLineOptions';
 import * as settings from '@pkg/config/settings';
 import { TransientSettings } from '@pkg/config/transientSettings';
 import clone from '@pkg/utils/clone';
@@ -352,6 +354,45 @@ describe('commandLineOptions', () => {
     });
   });
 
+  describe('--autostart', () => {
+    afterEach(() => {
+      Object.assign(AutostartOverrides, { startInBackground: false, disableKubernetes: false });
+    });
+
+    test('applies the overrides without saving them', () => {
+      const writeFileSync = jest.spyOn(fs, 'writeFileSync');
+
+      writeFileSync.mockClear();
+      const newPrefs = updateFromCommandLine(prefs, lockedSettings, ['--autostart=background,no-kubernetes']);
+
+      expect(AutostartOverrides).toEqual({ startInBackground: true, disableKubernetes: true });
+      expect(newPrefs).toEqual(origPrefs);
+      expect(newPrefs.application.startInBackground).toBeFalsy();
+      expect(newPrefs.kubernetes.enabled).toBeTruthy();
+      expect(writeFileSync).not.toHaveBeenCalled();
+    });
+
+    test('accepts no overrides', () => {
+      updateFromCommandLine(prefs, lockedSettings, ['--autostart']);
+      expect(AutostartOverrides).toEqual({ startInBackground: false, disableKubernetes: false });
+    });
+
+    test('can be combined with settings', () => {
+      const newPrefs = updateFromCommandLine(prefs, lockedSettings, ['--autostart=background', '--kubernetes.enabled=false']);
+
+      expect(AutostartOverrides).toEqual({ startInBackground: true, disableKubernetes: false });
+      expect(newPrefs.kubernetes.enabled).toBeFalsy();
+    });
+
+    test('complains about an unknown override', () => {
+      const arg = '--autostart=background,sideways';
+
+      expect(() => {
+        updateFromCommandLine(prefs, lockedSettings, [arg]);
+      }).toThrow(`Invalid associated value for ${ arg }: sideways is not one of background or no-kubernetes`);
+    });
+  });
+
   describe('getObjectRepresentation', () => {
     test('handles more than 2 dots', () => {
       expect(getObjectRepresentation('a.b.c.d' as RecursiveKeys<settings.Settings>, 3))
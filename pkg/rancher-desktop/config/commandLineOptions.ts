@@ -16,6 +16,40 @@ export class LockedFieldError extends Error {}
 
 export class FatalCommandLineOptionError extends Error {}
 
+/**
+ * Overrides requested by `--autostart` when the application is started at
+ * login (see `rdctl autostart`).  They only apply to this run of the
+ * application, and are never saved to the settings.
+ */
+export const AutostartOverrides = {
+  /** Start minimized to the tray, whatever application.startInBackground says. */
+  startInBackground: false,
+  /** Start the backend without Kubernetes, whatever kubernetes.enabled says. */
+  disableKubernetes: false,
+};
+
+/**
+ * Parses the value of `--autostart`: a comma-separated list of overrides
+ * (`background` and `no-kubernetes`), which may be empty.
+ */
+function setAutostartOverrides(arg: string, value: string) {
+  const overrides = { startInBackground: false, disableKubernetes: false };
+
+  for (const item of value.split(',').filter(item => item)) {
+    switch (item) {
+    case 'background':
+      overrides.startInBackground = true;
+      break;
+    case 'no-kubernetes':
+      overrides.disableKubernetes = true;
+      break;
+    default:
+      throw new Error(`Invalid associated value for ${ arg }: ${ item } is not one of background or no-kubernetes`);
+    }
+  }
+  Object.assign(AutostartOverrides, overrides);
+}
+
 /**
  * Takes an array of strings, presumably from a command-line used to launch the app.
  * Key operations:
@@ -24,6 +58,7 @@ export class FatalCommandLineOptionError extends Error {}
  * * Complain about any unrecognized options after a recognized option has been processed.
  * * This calls the same settings-validator as used by `rdctl set` and the API to catch
  *   any attempts to update a locked field.
+ * * `--no-modal-dialogs` and `--autostart` are not settings; they only affect this run.
  *
  *  * All errors are fatal as this function is like an API for launching the application.
  * @param cfg - current loaded settings - this is updated and also returned
@@ -70,6 +105,11 @@ export function updateFromCommandLine(cfg: Settings, lockedFields: LockedSetting
       processingExternalArguments = false;
       continue;
     }
+    if (fqFieldName === 'autostart') {
+      setAutostartOverrides(arg, value);
+      processingExternalArguments = false;
+      continue;
+    }
     const currentValue: boolean|string|number|Record<string, undefined>|undefined = _.get(cfg, fqFieldName);
 
     if (currentValue === undefined) {
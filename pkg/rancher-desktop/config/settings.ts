@@ -75,7 +75,27 @@ export const defaultSettings = {
       enabled:  false,
       patterns: [] as Array<string>,
     },
-    name: ContainerEngine.MOBY,
+    name:        ContainerEngine.MOBY,
+    /** Settings for the `docker-credential-none` credential helper. */
+    credentials: {
+      /** Whether to encrypt stored credentials, with a key held by the OS. */
+      encrypt: false,
+    },
+    /**
+     * Restrictions on what clients of the docker socket exposed on the host
+     * may do, on Windows platform only.
+     */
+    socketPolicy: {
+      enabled:           false,
+      /**
+       * Whether privileged containers and exec sessions may be created, along
+       * with containers that have extra capabilities, devices, host namespaces
+       * or unconfined security options.
+       */
+      allowPrivileged:   true,
+      /** Host directories that may be bind mounted, along with their contents. */
+      allowedMountRoots: [] as Array<string>,
+    },
   },
   virtualMachine: {
     memoryInGB:   2,
@@ -85,8 +105,46 @@ export const defaultSettings = {
      * is handled by host-resolver on Windows platform only.
      */
     hostResolver: true,
+    /**
+     * when set to true, the GPU support provided by WSL is made available to
+     * containers, on Windows platform only.
+     */
+    gpu:          false,
+    /**
+     * DNS configuration for the VM, on Windows platform only.
+     */
+    dns:          {
+      /**
+       * The DNS servers to forward queries to; if empty, those of the host
+       * are used.
+       */
+      upstreamServers: [] as Array<string>,
+      /**
+       * Per-domain DNS servers, as `domain=server`; queries for names in the
+       * domain (and its subdomains) go to that server.
+       */
+      domains:         [] as Array<string>,
+      /**
+       * When upstream servers are not given, skip the DNS servers of VPN
+       * adapters on the host.
+       */
+      ignoreVPN:       false,
+    },
+  },
+  WSL: {
+    integrations:    {} as Record<string, boolean>,
+    /**
+     * Rules for distros without an explicit entry in `integrations`, so that
+     * tools creating distros don't need to enable integration for each one.
+     * Patterns are distro names, where `*` and `?` are wildcards.
+     */
+    autoIntegration: {
+      /** Integrate with distros matching any of these patterns. */
+      include: [] as Array<string>,
+      /** Never integrate with distros matching these; this wins over `include`. */
+      exclude: [] as Array<string>,
+    },
   },
-  WSL:        { integrations: {} as Record<string, boolean> },
   kubernetes: {
     /** The version of Kubernetes to launch, as a semver (without v prefix). */
     version: '',
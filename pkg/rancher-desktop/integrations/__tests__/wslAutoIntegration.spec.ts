@@ -0,0 +1,59 @@
+import { autoIntegrationState, integrationState, matchesDistroPatterns } from '@pkg/integrations/wslAutoIntegration';
+
+describe('matchesDistroPatterns', () => {
+  test.each([
+    ['ci-1234', ['ci-*'], true],
+    ['CI-1234', ['ci-*'], true],
+    ['ci-1234', ['ci-?'], false],
+    ['ci-1', ['ci-?'], true],
+    ['Ubuntu', ['ci-*', 'Ubuntu'], true],
+    ['Ubuntu-22.04', ['Ubuntu'], false],
+    ['Ubuntu-22.04', ['Ubuntu-22.04'], true],
+    ['Ubuntu-22x04', ['Ubuntu-22.04'], false],
+    ['anything', [], false],
+  ])('%s matches %j: %s', (distro, patterns, expected) => {
+    expect(matchesDistroPatterns(distro, patterns)).toBe(expected);
+  });
+});
+
+describe('autoIntegrationState', () => {
+  const rules = { include: ['ci-*'], exclude: ['ci-private-*'] };
+
+  it('includes matching distros', () => {
+    expect(autoIntegrationState(rules, 'ci-1')).toBe(true);
+  });
+
+  it('prefers exclusions', () => {
+    expect(autoIntegrationState(rules, 'ci-private-1')).toBe(false);
+  });
+
+  it('ignores other distros', () => {
+    expect(autoIntegrationState(rules, 'Ubuntu')).toBeUndefined();
+  });
+});
+
+describe('integrationState', () => {
+  const settings = {
+    WSL: {
+      integrations:    { 'ci-1': false, Ubuntu: true },
+      autoIntegration: { include: ['ci-*'], exclude: [] },
+    },
+  };
+  const locked = { WSL: { autoIntegration: { include: true } } };
+
+  it('prefers explicit choices', () => {
+    expect(integrationState(settings, {}, 'ci-1')).toBe(false);
+    expect(integrationState(settings, {}, 'Ubuntu')).toBe(true);
+  });
+
+  it('uses the rules for other distros', () => {
+    expect(integrationState(settings, {}, 'ci-2')).toBe(true);
+    expect(integrationState(settings, {}, 'Debian')).toBe(false);
+  });
+
+  it('enforces locked rules', () => {
+    expect(integrationState(settings, locked, 'ci-1')).toBe(true);
+    expect(integrationState(settings, locked, 'Ubuntu')).toBe(true);
+    expect(integrationState(settings, locked, 'Debian')).toBe(false);
+  });
+});
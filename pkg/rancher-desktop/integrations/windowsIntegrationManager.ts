@@ -7,8 +7,9 @@ import { findHomeDir } from '@kubernetes/client-node';
 import K3sHelper from '@pkg/backend/k3sHelper';
 import { State } from '@pkg/backend/k8s';
 import { Settings, ContainerEngine } from '@pkg/config/settings';
-import { runInDebugMode } from '@pkg/config/settingsImpl';
+import { getLockedSettings, runInDebugMode } from '@pkg/config/settingsImpl';
 import type { IntegrationManager } from '@pkg/integrations/integrationManager';
+import { integrationState } from '@pkg/integrations/wslAutoIntegration';
 import mainEvents from '@pkg/main/mainEvents';
 import BackgroundProcess from '@pkg/utils/backgroundProcess';
 import { spawn, spawnFile } from '@pkg/utils/childProcess';
@@ -24,12 +25,28 @@ const console = Logging.integrations;
  * A list of distributions in which we should never attempt to integrate with.
  */
 const DISTRO_BLACKLIST = [
-  'rancher-desktop', // That's ourselves
-  'rancher-desktop-data', // Another internal distro
   'docker-desktop', // Not meant for interactive use
   'docker-desktop-data', // Not meant for interactive use
 ];
 
+/**
+ * Matches our own distributions ("rancher-desktop" and "rancher-desktop-data"),
+ * including those of other Rancher Desktop instances (see utils/instance).
+ */
+const RD_DISTRO_PATTERN = /^rancher-desktop(?:-[a-z0-9-]+)?$/;
+
+/**
+ * How often the host's CA certificates are copied into the integrated
+ * distributions again, to pick up changes to them, in milliseconds.
+ */
+const CERTIFICATE_SYNC_INTERVAL = 60 * 60 * 1000;
+
+/**
+ * How often to check for newly registered distributions that the
+ * WSL.autoIntegration rules select, in milliseconds.
+ */
+const DISTRO_WATCH_INTERVAL = 15 * 1000;
+
 /**
  * Represents a WSL distro, as output by `wsl.exe --list --verbose`.
  */
@@ -46,6 +63,42 @@ export class WSLDistro {
   }
 }
 
+/**
+ * The state of name resolution in a WSL distribution, as reported by
+ * `wsl-helper wsl integration dns`.
+ */
+export type DistroDNSState = {
+  /** Whether any of the name servers can resolve names. */
+  working:     boolean;
+  /** Whether resolv.conf was pinned by a previous repair. */
+  pinned:      boolean;
+  nameservers: { address: string, working: boolean, error?: string }[];
+  /** Set if the state could not be checked, or the repair failed. */
+  error?:      string;
+};
+
+/**
+ * A problem with the integration of a WSL distribution, as reported by
+ * `wsl-helper wsl integration doctor`.
+ */
+export type DistroIntegrationProblem = {
+  id:          string;
+  description: string;
+  /** A suggestion for the user to fix the problem. */
+  fix?:        string;
+  /** Whether the problem can be fixed automatically. */
+  repairable:  boolean;
+};
+
+/**
+ * The state of the integration of a WSL distribution.
+ */
+export type DistroIntegrationState = {
+  problems: DistroIntegrationProblem[];
+  /** Set if the integration could not be checked. */
+  error?:   string;
+};
+
 /**
  * WindowsIntegrationManager manages various integrations on Windows, for both
  * the Win32 host, as well as for each (foreign) WSL distribution.
@@ -53,6 +106,8 @@ export class WSLDistro {
  * - Docker socket forwarding.
  * - Kubeconfig.
  * - docker CLI plugin executables (WSL distributions only).
+ * - The rancher-desktop docker context (WSL distributions only).
+ * - CA certificates trusted by the host (WSL distributions only).
  */
 export default class WindowsIntegrationManager implements IntegrationManager {
   /** A snapshot of the application-wide settings. */
@@ -70,9 +125,18 @@ export default class WindowsIntegrationManager implements IntegrationManager {
   /** Whether the backend is in a state where the processes should run. */
   protected backendReady = false;
 
+  /**
+   * Arguments passing the docker socket policy (see the
+   * containerEngine.socketPolicy setting) to the Windows socket proxy.
+   */
+  protected windowsSocketPolicyArgs: string[] = [];
+
   /** Extra debugging arguments for wsl-helper. */
   protected wslHelperDebugArgs: string[] = [];
 
+  /** The distributions seen by the last sync, to detect new ones. */
+  protected knownDistros = new Set<string>();
+
   constructor() {
     mainEvents.on('settings-update', (settings) => {
       this.wslHelperDebugArgs = runInDebugMode(settings.application.debug) ? ['--verbose'] : [];
@@ -83,6 +147,18 @@ export default class WindowsIntegrationManager implements IntegrationManager {
       this.backendReady = [State.STARTED, State.STARTING, State.DISABLED].includes(mgr.state);
       this.sync();
     });
+    mainEvents.handle('integration-dns', repair => this.checkDNS(repair));
+    mainEvents.handle('integration-doctor', repair => this.checkIntegration(repair));
+    setInterval(() => {
+      this.syncCertificates().catch((ex) => {
+        console.error(`Failed to sync CA certificates: ${ ex }`);
+      });
+    }, CERTIFICATE_SYNC_INTERVAL).unref();
+    setInterval(() => {
+      this.syncNewDistros().catch((ex) => {
+        console.error(`Failed to sync new distributions: ${ ex }`);
+      });
+    }, DISTRO_WATCH_INTERVAL).unref();
     this.windowsSocketProxyProcess = new BackgroundProcess(
       'Win32 socket proxy',
       {
@@ -93,7 +169,7 @@ export default class WindowsIntegrationManager implements IntegrationManager {
 
           return spawn(
             path.join(paths.resources, 'win32', 'wsl-helper.exe'),
-            ['docker-proxy', 'serve', ...this.wslHelperDebugArgs], {
+            ['docker-proxy', 'serve', ...this.windowsSocketPolicyArgs, ...this.wslHelperDebugArgs], {
               stdio:       ['ignore', stream, stream],
               windowsHide: true,
             });
@@ -117,12 +193,15 @@ export default class WindowsIntegrationManager implements IntegrationManager {
   async sync(): Promise<void> {
     try {
       const kubeconfigPath = await K3sHelper.findKubeConfigToUpdate('rancher-desktop');
+      const distros = await this.supportedDistros;
 
+      this.knownDistros = new Set(distros.map(distro => distro.name));
       await Promise.all([
         this.syncHostSocketProxy(),
         this.syncHostDockerPlugins(),
         this.syncHostFile(),
-        ...(await this.supportedDistros).map(distro => this.syncDistro(distro.name, kubeconfigPath)),
+        this.syncCertificates(),
+        ...distros.map(distro => this.syncDistro(distro.name, kubeconfigPath)),
       ]);
     } catch (ex) {
       console.error(`Integration sync: Error: ${ ex }`);
@@ -131,14 +210,55 @@ export default class WindowsIntegrationManager implements IntegrationManager {
     }
   }
 
+  /**
+   * syncNewDistros sets up the distributions registered since the last sync,
+   * integrating with those selected by the WSL.autoIntegration rules.  Other
+   * new distributions have no integration, so there's nothing to do for them.
+   */
+  protected async syncNewDistros(): Promise<void> {
+    if (!this.enforcing || !this.backendReady || !this.settings.WSL?.autoIntegration?.include?.length) {
+      return;
+    }
+    const distros = (await this.supportedDistros).map(distro => distro.name);
+    const newDistros = distros.filter(distro => !this.knownDistros.has(distro) && this.integrationState(distro));
+
+    this.knownDistros = new Set(distros);
+    if (newDistros.length === 0) {
+      return;
+    }
+    const kubeconfigPath = await K3sHelper.findKubeConfigToUpdate('rancher-desktop');
+    const certificatesPath = await this.writeHostCertificates();
+
+    await Promise.all(newDistros.map((distro) => {
+      console.log(`Integrating with new distribution ${ distro } due to the WSL.autoIntegration rules`);
+
+      return Promise.all([
+        this.syncDistro(distro, kubeconfigPath),
+        this.syncDistroCertificates(distro, certificatesPath, true),
+        this.updateHostsFile(distro),
+      ]);
+    }));
+    mainEvents.emit('integration-update', await this.listIntegrations());
+  }
+
+  /**
+   * Whether to integrate with the given distribution, taking into account both
+   * the user's choices and the WSL.autoIntegration rules.
+   */
+  protected integrationState(distro: string): boolean {
+    return integrationState(this.settings, getLockedSettings(), distro);
+  }
+
   async syncDistro(distro: string, kubeconfigPath: string): Promise<void> {
-    let state = this.settings.WSL?.integrations?.[distro] === true;
+    let state = this.integrationState(distro);
 
     console.debug(`Integration sync: ${ distro } -> ${ state }`);
     try {
       await Promise.all([
         this.syncDistroSocketProxy(distro, state),
         this.syncDistroDockerPlugins(distro, state),
+        this.syncDistroDockerContext(distro, state),
+        this.syncDistroDockerConfig(distro, state),
         this.syncDistroKubeconfig(distro, kubeconfigPath, state),
       ]);
     } catch (ex) {
@@ -211,12 +331,16 @@ export default class WindowsIntegrationManager implements IntegrationManager {
    * WSL distro. Returns whatever it prints to stdout, and logs whatever
    * it prints to stderr.
    */
-  protected async captureCommand(opts: {distro?: string, encoding?: BufferEncoding, env?: Record<string, string>}, ...command: string[]):Promise<string> {
+  protected async captureCommand(opts: {distro?: string, encoding?: BufferEncoding, root?: boolean, env?: Record<string, string>}, ...command: string[]):Promise<string> {
     const logStream = opts.distro ? Logging[`wsl-helper.${ opts.distro }`] : console;
     const args = [];
 
     if (opts.distro) {
-      args.push('--distribution', opts.distro, '--exec');
+      args.push('--distribution', opts.distro);
+      if (opts.root) {
+        args.push('--user', 'root');
+      }
+      args.push('--exec');
     }
     args.push(...command);
     console.debug(`Running ${ await this.wslExe } ${ args.join(' ') }`);
@@ -257,11 +381,53 @@ export default class WindowsIntegrationManager implements IntegrationManager {
     const reason = this.dockerSocketProxyReason;
 
     console.debug(`Syncing Win32 socket proxy: ${ reason ? `should not run (${ reason })` : 'should run' }`);
-    if (!reason) {
-      this.windowsSocketProxyProcess.start();
-    } else {
+    if (reason) {
       await this.windowsSocketProxyProcess.stop();
+
+      return;
+    }
+
+    let policyArgs: string[];
+
+    try {
+      policyArgs = this.hostSocketPolicyArgs;
+    } catch (ex) {
+      // Don't expose the socket without the policy that should apply to it.
+      console.error(`Not running the Win32 socket proxy, failed to get the docker socket policy: ${ ex }`);
+      await this.windowsSocketProxyProcess.stop();
+
+      return;
+    }
+    if (JSON.stringify(policyArgs) !== JSON.stringify(this.windowsSocketPolicyArgs)) {
+      // The policy is fixed when the proxy starts; restart it to apply a new one.
+      this.windowsSocketPolicyArgs = policyArgs;
+      await this.windowsSocketProxyProcess.stop();
+    }
+    this.windowsSocketProxyProcess.start();
+  }
+
+  /**
+   * The arguments passing the docker socket policy to the Windows socket
+   * proxy; they are empty if the policy is disabled.  The policy is passed
+   * directly, rather than through a file, so that it can't be changed or
+   * removed behind our back.
+   */
+  protected get hostSocketPolicyArgs(): string[] {
+    const policy = this.settings.containerEngine?.socketPolicy;
+
+    if (!policy?.enabled) {
+      return [];
     }
+    const allowedMountRoots = policy.allowedMountRoots ?? [];
+
+    if (!Array.isArray(allowedMountRoots) || !allowedMountRoots.every(root => typeof root === 'string')) {
+      throw new TypeError(`Invalid allowed mount roots ${ JSON.stringify(allowedMountRoots) }`);
+    }
+
+    return ['--policy', JSON.stringify({
+      allowPrivileged: policy.allowPrivileged ?? true,
+      allowedMountRoots,
+    })];
   }
 
   /**
@@ -399,6 +565,222 @@ export default class WindowsIntegrationManager implements IntegrationManager {
     }
   }
 
+  /**
+   * syncDistroDockerContext creates or removes the rancher-desktop docker
+   * context in the given distro.  The context is created even if the user
+   * has a different current context, so that they can switch to it.
+   * @note this function must not throw.
+   */
+  protected async syncDistroDockerContext(distro: string, state: boolean) {
+    try {
+      const shouldExist = state && this.enforcing && this.settings.containerEngine?.name === ContainerEngine.MOBY;
+      const executable = await this.getLinuxToolPath(distro, 'wsl-helper');
+
+      console.debug(`Syncing ${ distro } docker context: ${ shouldExist }`);
+      await this.execCommand({ distro }, executable, 'wsl', 'integration', 'docker-context', `--state=${ shouldExist }`);
+    } catch (error) {
+      console.error(`Failed to sync ${ distro } docker context: ${ error }`.trim());
+    }
+  }
+
+  /**
+   * syncDistroDockerConfig sets the credential helper and proxies in the docker
+   * CLI configuration of the given distro, so that it matches the host (the
+   * credential helper is run on the host through WSL interop).
+   * @note this function must not throw.
+   */
+  protected async syncDistroDockerConfig(distro: string, state: boolean) {
+    try {
+      const executable = await this.getLinuxToolPath(distro, 'wsl-helper');
+      const args = [`--state=${ state && this.enforcing }`];
+      const credsStore = await this.getHostCredsStore();
+      const proxy = this.settings.experimental?.virtualMachine?.proxy;
+
+      if (credsStore) {
+        args.push(`--credential-helper=${ credsStore }.exe`);
+      }
+      if (proxy?.enabled && proxy.address && proxy.port) {
+        const url = new URL(/^[a-z0-9]+:\/\//i.test(proxy.address) ? proxy.address : `http://${ proxy.address }`);
+
+        url.port = `${ proxy.port }`;
+        url.username = proxy.username ?? '';
+        url.password = proxy.password ?? '';
+        const proxyURL = url.href.replace(/\/$/, '');
+
+        args.push(`--http-proxy=${ proxyURL }`, `--https-proxy=${ proxyURL }`, `--no-proxy=${ (proxy.noproxy ?? []).join(',') }`);
+      }
+
+      console.debug(`Syncing ${ distro } docker config: ${ args.join(' ').replace(/\/\/[^@\s]*@/g, '//***@') }`);
+      await this.execCommand({ distro }, executable, 'wsl', 'integration', 'docker-config', ...args);
+    } catch (error) {
+      console.error(`Failed to sync ${ distro } docker config: ${ error }`.trim());
+    }
+  }
+
+  /**
+   * Get the credential helper configured for the docker CLI on the host.
+   */
+  protected async getHostCredsStore(): Promise<string | undefined> {
+    const homeDir = findHomeDir();
+
+    if (!homeDir) {
+      return undefined;
+    }
+    try {
+      const contents = await fs.promises.readFile(path.join(homeDir, '.docker', 'config.json'), 'utf-8');
+      const credsStore = JSON.parse(contents).credsStore;
+
+      return typeof credsStore === 'string' && credsStore !== 'none' ? credsStore : undefined;
+    } catch (ex) {
+      console.debug(`Could not read the host docker credential helper: ${ ex }`);
+
+      return undefined;
+    }
+  }
+
+  /**
+   * Install the CA certificates trusted by the host into the integrated
+   * distributions (and remove them from the others), so that tools there can
+   * reach servers using enterprise certificates.
+   */
+  protected async syncCertificates() {
+    const certificatesPath = await this.writeHostCertificates();
+
+    await Promise.all(
+      (await this.supportedDistros).map((distro) => {
+        const state = this.integrationState(distro.name);
+
+        return this.syncDistroCertificates(distro.name, certificatesPath, state);
+      }),
+    );
+  }
+
+  /**
+   * Write the CA certificates trusted by the host into a PEM file.
+   * @returns The path to the file.
+   */
+  protected async writeHostCertificates(): Promise<string> {
+    const certs: (string | Buffer)[] = await new Promise((resolve) => {
+      mainEvents.once('cert-ca-certificates', resolve);
+      mainEvents.emit('cert-get-ca-certificates');
+    });
+    const certificatesPath = path.join(paths.cache, 'host-ca-certificates.pem');
+
+    await fs.promises.mkdir(paths.cache, { recursive: true });
+    await fs.promises.writeFile(certificatesPath, certs.map(cert => `${ cert.toString().trim() }\n`).join(''));
+
+    return certificatesPath;
+  }
+
+  /**
+   * syncDistroCertificates installs or removes the host's CA certificates in
+   * the given distro.
+   * @note this function must not throw.
+   */
+  protected async syncDistroCertificates(distro: string, certificatesPath: string, state: boolean) {
+    try {
+      const executable = await this.getLinuxToolPath(distro, 'wsl-helper');
+
+      console.debug(`Syncing ${ distro } CA certificates: ${ state }`);
+      await this.execCommand(
+        {
+          distro,
+          root: true,
+          env:  {
+            ...process.env,
+            CERTIFICATES: certificatesPath,
+            WSLENV:       `${ process.env.WSLENV }:CERTIFICATES/p`,
+          },
+        },
+        executable, 'wsl', 'integration', 'certificates', `--state=${ state }`);
+    } catch (error) {
+      console.error(`Failed to sync ${ distro } CA certificates: ${ error }`.trim());
+    }
+  }
+
+  /**
+   * Check name resolution in the integrated distributions, optionally
+   * repairing it in the ones where it is broken.
+   */
+  protected async checkDNS(repair: boolean): Promise<Record<string, DistroDNSState>> {
+    const distros = (await this.supportedDistros)
+      .filter(distro => this.integrationState(distro.name));
+
+    return Object.fromEntries(await Promise.all(distros.map(async(distro) => {
+      let state = await this.checkDistroDNS(distro.name, 'check');
+
+      if (repair && !state.working && !state.error) {
+        state = await this.checkDistroDNS(distro.name, 'repair');
+      }
+
+      return [distro.name, state] as const;
+    })));
+  }
+
+  /**
+   * checkDistroDNS runs the DNS helper in the given distro.
+   * @note this function must not throw.
+   */
+  protected async checkDistroDNS(distro: string, mode: 'check' | 'repair'): Promise<DistroDNSState> {
+    try {
+      const executable = await this.getLinuxToolPath(distro, 'wsl-helper');
+      const stdout = await this.captureCommand(
+        { distro, root: mode === 'repair' },
+        executable, 'wsl', 'integration', 'dns', `--mode=${ mode }`);
+
+      return JSON.parse(stdout);
+    } catch (error) {
+      console.error(`Failed to ${ mode } ${ distro } DNS: ${ error }`.trim());
+
+      return {
+        working: false, pinned: false, nameservers: [], error: `${ error }`,
+      };
+    }
+  }
+
+  /**
+   * Check the integration of the integrated distributions, optionally
+   * repairing the ones with problems.  Repairing fixes what can be fixed
+   * from within the distribution, then sets up the integration again.
+   */
+  protected async checkIntegration(repair: boolean): Promise<Record<string, DistroIntegrationState>> {
+    const distros = (await this.supportedDistros)
+      .filter(distro => this.integrationState(distro.name));
+    const kubeconfigPath = repair ? await K3sHelper.findKubeConfigToUpdate('rancher-desktop') : '';
+
+    return Object.fromEntries(await Promise.all(distros.map(async(distro) => {
+      let state = await this.checkDistroIntegration(distro.name, 'check');
+
+      if (repair && state.problems.length > 0 && !state.error) {
+        await this.checkDistroIntegration(distro.name, 'repair');
+        await this.syncDistro(distro.name, kubeconfigPath);
+        state = await this.checkDistroIntegration(distro.name, 'check');
+      }
+
+      return [distro.name, state] as const;
+    })));
+  }
+
+  /**
+   * checkDistroIntegration runs the integration doctor in the given distro.
+   * @note this function must not throw.
+   */
+  protected async checkDistroIntegration(distro: string, mode: 'check' | 'repair'): Promise<DistroIntegrationState> {
+    try {
+      const executable = await this.getLinuxToolPath(distro, 'wsl-helper');
+      const docker = !this.dockerSocketProxyReason;
+      const stdout = await this.captureCommand(
+        { distro },
+        executable, 'wsl', 'integration', 'doctor', `--mode=${ mode }`, `--docker=${ docker }`);
+
+      return { problems: JSON.parse(stdout) };
+    } catch (error) {
+      console.error(`Failed to ${ mode } ${ distro } integration: ${ error }`.trim());
+
+      return { problems: [], error: `${ error }` };
+    }
+  }
+
   protected async syncHostFile() {
     await Promise.all(
       (await this.supportedDistros).map((distro) => {
@@ -485,7 +867,7 @@ export default class WindowsIntegrationManager implements IntegrationManager {
         .map(line => line.match(parser)?.groups)
         .filter(defined)
         .map(group => new WSLDistro(group.name, parseInt(group.version)))
-        .filter((distro: WSLDistro) => !DISTRO_BLACKLIST.includes(distro.name));
+        .filter((distro: WSLDistro) => !DISTRO_BLACKLIST.includes(distro.name) && !RD_DISTRO_PATTERN.test(distro.name));
     })();
   }
 
@@ -0,0 +1,77 @@
+/**
+ * This module decides whether Rancher Desktop integrates with a WSL distro
+ * that the user hasn't made an explicit choice for, based on the patterns in
+ * the `WSL.autoIntegration` setting.
+ */
+
+import _ from 'lodash';
+
+import type { LockedSettingsType, Settings } from '@pkg/config/settings';
+import type { RecursivePartial } from '@pkg/utils/typeUtils';
+
+type AutoIntegrationRules = Partial<Settings['WSL']['autoIntegration']>;
+
+/**
+ * Convert a distro name pattern, where `*` matches any number of characters
+ * and `?` matches a single character, into a regular expression.  Like WSL
+ * itself, matching is case insensitive.
+ */
+function patternToRegExp(pattern: string): RegExp {
+  const source = pattern.split('').map((c) => {
+    switch (c) {
+    case '*':
+      return '.*';
+    case '?':
+      return '.';
+    default:
+      return _.escapeRegExp(c);
+    }
+  }).join('');
+
+  return new RegExp(`^${ source }$`, 'i');
+}
+
+/**
+ * Check whether the given distro name matches any of the patterns.
+ */
+export function matchesDistroPatterns(distro: string, patterns: string[] = []): boolean {
+  return patterns.some(pattern => patternToRegExp(pattern).test(distro));
+}
+
+/**
+ * Returns whether the rules ask for integration with the given distro, or
+ * undefined if the distro doesn't match any of them.  Exclusions take
+ * precedence over inclusions.
+ */
+export function autoIntegrationState(rules: AutoIntegrationRules | undefined, distro: string): boolean | undefined {
+  if (matchesDistroPatterns(distro, rules?.exclude)) {
+    return false;
+  }
+  if (matchesDistroPatterns(distro, rules?.include)) {
+    return true;
+  }
+
+  return undefined;
+}
+
+/**
+ * Check whether the rules have been locked by a deployment profile; in that
+ * case they are enforced, overriding any choices made by the user.
+ */
+export function isAutoIntegrationLocked(lockedSettings: LockedSettingsType): boolean {
+  return ['include', 'exclude'].some(key => !!_.get(lockedSettings, ['WSL', 'autoIntegration', key]));
+}
+
+/**
+ * Returns whether Rancher Desktop should integrate with the given distro: the
+ * locked rules win, then any explicit choice, then the rules.
+ */
+export function integrationState(settings: RecursivePartial<Settings>, lockedSettings: LockedSettingsType, distro: string): boolean {
+  const ruleState = autoIntegrationState(settings.WSL?.autoIntegration as AutoIntegrationRules, distro);
+
+  if (ruleState !== undefined && isAutoIntegrationLocked(lockedSettings)) {
+    return ruleState;
+  }
+
+  return settings.WSL?.integrations?.[distro] ?? ruleState ?? false;
+}
@@ -0,0 +1,51 @@
+import {
+  applyListQuery, ListFields, ListQueryError, parseListQuery,
+} from '../listQuery';
+
+type Item = { name: string, size: number, note?: string };
+
+const fields: ListFields<Item> = {
+  sortable: {
+    name: item => item.name,
+    size: item => item.size,
+  },
+  searchable:  [item => item.name, item => item.note],
+  defaultSort: 'name',
+};
+
+const items: Item[] = [
+  { name: 'charlie', size: 3 },
+  { name: 'alpha', size: 2, note: 'First' },
+  { name: 'bravo', size: 1, note: 'also first-class' },
+];
+
+function query(params: string) {
+  return applyListQuery(items, parseListQuery(new URLSearchParams(params), fields), fields);
+}
+
+describe('listQuery', () => {
+  it('sorts by the default field', () => {
+    expect(query('')).toEqual({ page: [items[1], items[2], items[0]], total: 3 });
+  });
+
+  it('sorts in descending order', () => {
+    expect(query('sort=-size').page.map(item => item.name)).toEqual(['charlie', 'alpha', 'bravo']);
+  });
+
+  it('filters case-insensitively', () => {
+    expect(query('filter=FIRST')).toEqual({ page: [items[1], items[2]], total: 2 });
+  });
+
+  it('pages after filtering', () => {
+    expect(query('filter=a&offset=1&limit=1')).toEqual({ page: [items[2]], total: 3 });
+  });
+
+  it.each([
+    ['sort=colour', /Invalid sort field "colour"/],
+    ['limit=-1', /Invalid limit "-1"/],
+    ['offset=two', /Invalid offset "two"/],
+  ])('rejects %s', (params, message) => {
+    expect(() => parseListQuery(new URLSearchParams(params), fields)).toThrow(ListQueryError);
+    expect(() => parseListQuery(new URLSearchParams(params), fields)).toThrow(message);
+  });
+});
@@ -82,12 +82,16 @@ describe(SettingsValidator, () => {
       ['experimental', 'virtualMachine', 'proxy', 'noproxy'],
       ['kubernetes', 'version'],
       ['version'],
+      ['virtualMachine', 'dns', 'domains'],
+      ['virtualMachine', 'dns', 'upstreamServers'],
       ['WSL', 'integrations'],
     ];
 
     // Fields that can only be set on specific platforms.
     const platformSpecificFields: Record<string, ReturnType<typeof os.platform>> = {
       'application.adminAccess':                      'linux',
+      'containerEngine.socketPolicy.allowPrivileged': 'win32',
+      'containerEngine.socketPolicy.enabled':         'win32',
       'experimental.virtualMachine.socketVMNet':      'darwin',
       'experimental.virtualMachine.networkingTunnel': 'win32',
       'experimental.virtualMachine.proxy.enabled':    'win32',
@@ -96,6 +100,10 @@ describe(SettingsValidator, () => {
       'experimental.virtualMachine.proxy.port':       'win32',
       'experimental.virtualMachine.proxy.username':   'win32',
       'kubernetes.ingress.localhostOnly':             'win32',
+      'virtualMachine.dns.ignoreVPN':                 'win32',
+      'virtualMachine.dns.domains':                   'win32',
+      'virtualMachine.dns.upstreamServers':           'win32',
+      'virtualMachine.gpu':                           'win32',
       'virtualMachine.hostResolver':                  'win32',
       'virtualMachine.memoryInGB':                    'darwin',
       'virtualMachine.numberCPUs':                    'linux',
@@ -339,6 +347,32 @@ describe(SettingsValidator, () => {
         errors:       [],
       });
     });
+
+    describe('with locked automatic integration rules', () => {
+      const lockedSettings = { WSL: { autoIntegration: { include: true, exclude: true } } };
+      const lockedConfig = _.merge({}, cfg, { WSL: { autoIntegration: { include: ['ci-*'], exclude: ['ci-private-*'] } } });
+
+      it('should reject choices contrary to the rules', () => {
+        const input = { WSL: { integrations: { 'ci-private-1': true } } };
+        const [needToUpdate, errors, isFatal] = subject.validateSettings(lockedConfig, input, lockedSettings);
+
+        expect({ needToUpdate, errors, isFatal }).toEqual({
+          needToUpdate: false,
+          errors:       ['field "WSL.integrations.ci-private-1" is locked by the WSL.autoIntegration rules'],
+          isFatal:      true,
+        });
+      });
+
+      it('should allow choices for distros the rules do not cover', () => {
+        const input = { WSL: { integrations: { 'ci-1': true, Ubuntu: true } } };
+        const [needToUpdate, errors] = subject.validateSettings(lockedConfig, input, lockedSettings);
+
+        expect({ needToUpdate, errors }).toEqual({
+          needToUpdate: true,
+          errors:       [],
+        });
+      });
+    });
   });
 
   describe('kubernetes.version', () => {
@@ -429,6 +463,47 @@ describe(SettingsValidator, () => {
     });
   });
 
+  describe('virtualMachine.dns', () => {
+    beforeEach(() => {
+      spyPlatform.mockReturnValue('win32');
+    });
+
+    it('accepts valid servers and domains', () => {
+      const input: RecursivePartial<settings.Settings> = {
+        virtualMachine: {
+          dns: {
+            upstreamServers: ['192.0.2.53', '2001:db8::53'],
+            domains:         ['corp.example.com=10.0.0.53'],
+          },
+        },
+      };
+      const [needToUpdate, errors] = subject.validateSettings(cfg, input);
+
+      expect({ needToUpdate, errors }).toEqual({
+        needToUpdate: true,
+        errors:       [],
+      });
+    });
+    it('rejects invalid servers', () => {
+      const input: RecursivePartial<settings.Settings> = { virtualMachine: { dns: { upstreamServers: ['dns.example.com'] } } };
+      const [needToUpdate, errors] = subject.validateSettings(cfg, input);
+
+      expect({ needToUpdate, errors }).toEqual({
+        needToUpdate: false,
+        errors:       ['virtualMachine.dns.upstreamServers: "dns.example.com" is not an IP address'],
+      });
+    });
+    it.each(['corp.example.com', 'corp.example.com=server', '-corp=10.0.0.53', 'corp=10.0.0.53=10.0.0.54'])('rejects invalid domain rule %s', (rule) => {
+      const input: RecursivePartial<settings.Settings> = { virtualMachine: { dns: { domains: [rule] } } };
+      const [needToUpdate, errors] = subject.validateSettings(cfg, input);
+
+      expect({ needToUpdate, errors }).toEqual({
+        needToUpdate: false,
+        errors:       [`virtualMachine.dns.domains: "${ rule }" is not of the form "domain=server", where server is an IP address`],
+      });
+    });
+  });
+
   describe('allowedImage lists', () => {
     it('complains about a single duplicate', () => {
       const input: RecursivePartial<settings.Settings> = {
@@ -0,0 +1,115 @@
+import fs from 'fs';
+import path from 'path';
+
+import forge from 'node-forge';
+
+import Logging from '@pkg/utils/logging';
+
+const console = Logging.server;
+
+/**
+ * The basename of the files (next to rd-engine.json) holding the certificate
+ * and private key of the CLI server.  Clients pin the certificate, so it is
+ * self-signed, and kept across restarts until it is about to expire.
+ */
+export const CERTIFICATE_FILE_BASENAME = 'rd-engine.crt';
+export const KEY_FILE_BASENAME = 'rd-engine.key';
+
+/** How long a newly generated certificate is valid for, in days. */
+const VALIDITY_DAYS = 3650;
+/** Certificates expiring sooner than this (in days) are replaced. */
+const RENEWAL_DAYS = 30;
+
+/** The certificate currently used by the CLI server, in PEM format. */
+let currentCertificate = '';
+
+export type ServerCertificate = {
+  /** The certificate, in PEM format. */
+  cert: string;
+  /** The private key, in PEM format. */
+  key: string;
+};
+
+/**
+ * Load the certificate for the CLI server from the given directory, creating
+ * a new self-signed one if it doesn't exist or is about to expire.
+ */
+export async function ensureServerCertificate(dir: string): Promise<ServerCertificate> {
+  const certPath = path.join(dir, CERTIFICATE_FILE_BASENAME);
+  const keyPath = path.join(dir, KEY_FILE_BASENAME);
+
+  try {
+    const [cert, key] = await Promise.all([
+      fs.promises.readFile(certPath, 'utf-8'),
+      fs.promises.readFile(keyPath, 'utf-8'),
+    ]);
+    const renewalDate = new Date(Date.now() + RENEWAL_DAYS * 24 * 60 * 60 * 1000);
+
+    if (forge.pki.certificateFromPem(cert).validity.notAfter > renewalDate) {
+      currentCertificate = cert;
+
+      return { cert, key };
+    }
+    console.log('CLI server certificate is about to expire; replacing it.');
+  } catch (ex: any) {
+    if (ex.code !== 'ENOENT') {
+      console.log(`Could not load the CLI server certificate, replacing it: ${ ex }`);
+    }
+  }
+
+  const result = generateCertificate();
+
+  // Write the key first, so that clients never see a certificate without one.
+  await fs.promises.writeFile(`${ keyPath }.tmp`, result.key, { mode: 0o600 });
+  await fs.promises.rename(`${ keyPath }.tmp`, keyPath);
+  await fs.promises.writeFile(`${ certPath }.tmp`, result.cert, { mode: 0o644 });
+  await fs.promises.rename(`${ certPath }.tmp`, certPath);
+  currentCertificate = result.cert;
+
+  return result;
+}
+
+/**
+ * Check whether the given certificate (in PEM format, as passed to the
+ * Electron certificate-error event) is the one used by the CLI server, so
+ * that the UI can talk to the server.
+ */
+export function isServerCertificate(url: string, certificate: string): boolean {
+  const { hostname } = new URL(url);
+
+  if (!currentCertificate || !['localhost', '127.0.0.1'].includes(hostname)) {
+    return false;
+  }
+
+  return certificate.replace(/\r/g, '').trim() === currentCertificate.replace(/\r/g, '').trim();
+}
+
+function generateCertificate(): ServerCertificate {
+  const keys = forge.pki.rsa.generateKeyPair(2048);
+  const cert = forge.pki.createCertificate();
+  const attrs = [{ name: 'commonName', value: 'Rancher Desktop CLI server' }];
+
+  cert.publicKey = keys.publicKey;
+  // Serial numbers must be positive; force the high bit off.
+  cert.serialNumber = `0${ forge.util.bytesToHex(forge.random.getBytesSync(15)) }`;
+  cert.validity.notBefore = new Date();
+  cert.validity.notAfter = new Date(Date.now() + VALIDITY_DAYS * 24 * 60 * 60 * 1000);
+  cert.setSubject(attrs);
+  cert.setIssuer(attrs);
+  cert.setExtensions([
+    { name: 'extKeyUsage', serverAuth: true },
+    {
+      name:     'subjectAltName',
+      altNames: [
+        { type: 2, value: 'localhost' },
+        { type: 7, ip: '127.0.0.1' },
+      ],
+    },
+  ]);
+  cert.sign(keys.privateKey, forge.md.sha256.create());
+
+  return {
+    cert: forge.pki.certificateToPem(cert),
+    key:  forge.pki.privateKeyToPem(keys.privateKey),
+  };
+}
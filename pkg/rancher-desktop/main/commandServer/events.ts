@@ -0,0 +1,106 @@
+import express from 'express';
+
+import Logging from '@pkg/utils/logging';
+
+const console = Logging.server;
+
+/**
+ * The types of events that can be subscribed to via GET /v1/events.
+ * - `settings`: the settings have changed; the data is the new settings.
+ * - `backend-state`: the state of the VM or the backend lock has changed; the
+ *   data is the same as that returned by GET /v1/backend_state.
+ */
+export const EVENT_TYPES = ['settings', 'backend-state'] as const;
+export type EventType = typeof EVENT_TYPES[number];
+
+/** How often to send a comment to idle subscribers, in milliseconds. */
+const KEEPALIVE_INTERVAL = 15_000;
+
+type Subscriber = {
+  response: express.Response;
+  types:    ReadonlySet<EventType>;
+};
+
+/**
+ * EventStream sends events to clients using server-sent events; see
+ * https://html.spec.whatwg.org/multipage/server-sent-events.html
+ */
+export class EventStream {
+  protected subscribers = new Set<Subscriber>();
+  protected nextID = 1;
+  protected keepAliveTimer: ReturnType<typeof setInterval> | undefined;
+
+  /**
+   * Parse the comma-separated list of event types requested by a client.
+   * @returns The event types (all of them if none were given), or a string
+   *          describing the error.
+   */
+  static parseTypes(query: unknown): EventType[] | string {
+    if (query === undefined || query === '') {
+      return [...EVENT_TYPES];
+    }
+    if (typeof query !== 'string') {
+      return 'The types parameter must be given once';
+    }
+    const types = query.split(',').map(type => type.trim());
+    const unknown = types.filter(type => !(EVENT_TYPES as readonly string[]).includes(type));
+
+    if (unknown.length > 0) {
+      return `Unknown event types ${ unknown.join(', ') }; must be one of ${ EVENT_TYPES.join(', ') }`;
+    }
+
+    return types as EventType[];
+  }
+
+  /**
+   * Turn the response into an event stream of the given types.  The stream
+   * stays open until the client disconnects.
+   * @param initial Events to send to this subscriber straight away, so that
+   *        it doesn't miss any changes made before it subscribed.
+   */
+  subscribe(request: express.Request, response: express.Response, types: EventType[], initial: (readonly [EventType, any])[] = []) {
+    const subscriber: Subscriber = { response, types: new Set(types) };
+
+    response.status(200).set({
+      'Content-Type':  'text/event-stream',
+      'Cache-Control': 'no-cache',
+      Connection:      'keep-alive',
+    });
+    response.flushHeaders();
+    this.subscribers.add(subscriber);
+    console.debug(`Event subscriber connected (${ this.subscribers.size } active)`);
+    for (const [type, data] of initial) {
+      this.write(subscriber, this.nextID++, type, data);
+    }
+    this.keepAliveTimer ??= setInterval(() => {
+      for (const { response } of this.subscribers) {
+        response.write(': keep-alive\n\n');
+      }
+    }, KEEPALIVE_INTERVAL);
+
+    request.on('close', () => {
+      this.subscribers.delete(subscriber);
+      console.debug(`Event subscriber disconnected (${ this.subscribers.size } active)`);
+      if (this.subscribers.size === 0 && this.keepAliveTimer) {
+        clearInterval(this.keepAliveTimer);
+        this.keepAliveTimer = undefined;
+      }
+    });
+  }
+
+  /** Send an event to all clients subscribed to its type. */
+  publish(type: EventType, data: any) {
+    const id = this.nextID++;
+
+    for (const subscriber of this.subscribers) {
+      if (subscriber.types.has(type)) {
+        this.write(subscriber, id, type, data);
+      }
+    }
+  }
+
+  protected write({ response }: Subscriber, id: number, type: EventType, data: any) {
+    // JSON.stringify never emits raw newlines, so the data fits on one line.
+    response.write(`id: ${ id }\nevent: ${ type }\ndata: ${ JSON.stringify(data) }\n\n`);
+  }
+}
@@ -1,20 +1,38 @@
 import fs from 'fs';
 import http from 'http';
+import https from 'https';
+import net from 'net';
+import os from 'os';
 import path from 'path';
+import stream from 'stream';
 import { URL } from 'url';
 
 import express from 'express';
 import _ from 'lodash';
 
+import { ensureServerCertificate } from './certificate';
+import { EventStream, EventType } from './events';
+import {
+  JOB_KINDS, JobControl, JobKind, JobNotCancelableError, JobProgress, JobStore,
+} from './jobs';
+import {
+  applyListQuery, ListFields, ListQuery, ListQueryError, parseListQuery, TOTAL_COUNT_HEADER,
+} from './listQuery';
+import {
+  DEFAULT_TOKEN_TTL, MAX_TOKEN_TTL, TOKEN_SCOPES, TokenScope, TokenStore,
+} from './tokens';
+
+import COMMAND_API_SPEC from '@pkg/assets/specs/command-api.yaml';
 import { State } from '@pkg/backend/backend';
 import type { Settings } from '@pkg/config/settings';
 import type { TransientSettings } from '@pkg/config/transientSettings';
-import type { DiagnosticsResultCollection } from '@pkg/main/diagnostics/diagnostics';
+import { DiagnosticsNotRepairableError, DiagnosticsResult, DiagnosticsResultCollection } from '@pkg/main/diagnostics/diagnostics';
 import { ExtensionMetadata } from '@pkg/main/extensions/types';
 import mainEvents from '@pkg/main/mainEvents';
 import { getVtunnelInstance } from '@pkg/main/networking/vtunnel';
 import * as serverHelper from '@pkg/main/serverHelper';
 import { Snapshot } from '@pkg/main/snapshots/types';
+import { instancePort } from '@pkg/utils/instance';
 import Logging from '@pkg/utils/logging';
 import paths from '@pkg/utils/paths';
 import { jsonStringifyWithWhiteSpace } from '@pkg/utils/stringify';
@@ -31,24 +49,96 @@ export type BackendState = {
   locked: boolean,
 };
 
+/** The actions of the VM lifecycle endpoints. */
+export type VMLifecycleAction = 'start' | 'stop' | 'restart' | 'pause';
+
 export type ServerState = {
   user: string;
   password: string;
   port: number;
   pid: number;
+  /**
+   * The Unix domain socket (or Windows named pipe) the server also listens
+   * on; connections through it need no credentials.
+   */
+  socket?: string;
 };
 
 type DispatchFunctionType = (request: express.Request, response: express.Response, context: commandContext) => Promise<void>;
 type HttpMethod = 'get' | 'put' | 'post';
 
 const console = Logging.server;
-const SERVER_PORT = 6107;
+const SERVER_PORT = instancePort(6107);
 const SERVER_FILE_BASENAME = 'rd-engine.json';
+const SERVER_SOCKET_BASENAME = 'rd-engine.sock';
 const MAX_REQUEST_BODY_LENGTH = 4194304; // 4MiB
+/**
+ * Clients set this header to ask for long-running operations to be aborted if
+ * they disconnect before the response is sent (e.g. on Ctrl-C in rdctl).
+ */
+const CANCEL_ON_DISCONNECT_HEADER = 'X-RD-Cancel-On-Disconnect';
+
+/**
+ * The header in the response to a settings update that says whether the
+ * backend will restart to apply the new settings.
+ */
+const RESTARTING_HEADER = 'X-RD-Restarting';
+/**
+ * The endpoint for the engine proxy, and the protocol clients upgrade to; the
+ * upgraded connection is a raw stream to the socket of the container engine.
+ */
+const ENGINE_PROXY_PATH = '/v1/engine_proxy';
+const ENGINE_PROXY_PROTOCOL = 'rd-engine-proxy';
+/**
+ * The delay, in seconds, clients should wait before retrying a request that
+ * failed with 503 because the backend is still starting.
+ */
+const BACKEND_STARTING_RETRY_AFTER = '5';
+
+type ExtensionListEntry = [string, { version: string, metadata: ExtensionMetadata, labels: Record<string, string> }];
+
+const DIAGNOSTIC_CHECK_FIELDS: ListFields<DiagnosticsResult> = {
+  sortable: {
+    id:       check => check.id,
+    category: check => check.category,
+    passed:   check => check.passed,
+  },
+  searchable: [check => check.id, check => check.description],
+};
+
+const SNAPSHOT_FIELDS: ListFields<Snapshot> = {
+  sortable: {
+    name:    snapshot => snapshot.name,
+    created: snapshot => snapshot.created,
+  },
+  searchable:  [snapshot => snapshot.name, snapshot => snapshot.description],
+  defaultSort: 'name',
+};
+
+const EXTENSION_FIELDS: ListFields<ExtensionListEntry> = {
+  sortable: {
+    id:      ([id]) => id,
+    version: ([, info]) => info.version,
+  },
+  searchable:  [([id]) => id, ([, info]) => info.metadata?.ui?.['dashboard-tab']?.title],
+  defaultSort: 'id',
+};
+
+/** The outcome of checking the credentials of a request. */
+type AuthResult =
+  { ok: true, interactive: boolean, tokenScope?: TokenScope } |
+  { ok: false, status: 401 | 403, message?: string };
 
 export class HttpCommandServer {
   protected vtun = getVtunnelInstance();
-  protected server = http.createServer();
+  protected server = https.createServer();
+  /** The server listening on the Unix domain socket or named pipe. */
+  protected socketServer: http.Server | undefined;
+  /**
+   * Connections made through the socket server; access to these is controlled
+   * by the permissions on the socket, so they are not authenticated.
+   */
+  protected readonly trustedConnections = new WeakSet<net.Socket>();
   protected app = express();
   protected readonly externalState: ServerState = {
     user:     'user',
@@ -64,12 +154,20 @@ export class HttpCommandServer {
     pid:      process.pid,
   };
 
+  /** Short-lived bearer tokens, minted by clients holding the credentials above. */
+  protected readonly tokens = new TokenStore();
+  protected readonly jobs = new JobStore();
+
+  /** Clients subscribed to GET /v1/events. */
+  protected readonly eventStream = new EventStream();
+
   protected commandWorker: CommandWorkerInterface;
 
   protected dispatchTable: Record<HttpMethod, Record<string, readonly [number, DispatchFunctionType]>> = _.merge(
     {
       get: {
         '/v1/about':                 [1, this.about],
+        '/v1/openapi':               [1, this.openAPISpec],
         '/v1/diagnostic_categories': [0, this.diagnosticCategories],
         '/v1/diagnostic_ids':        [0, this.diagnosticIDsForCategory],
         '/v1/diagnostic_checks':     [0, this.diagnosticChecks],
@@ -77,8 +175,14 @@ export class HttpCommandServer {
         '/v1/settings/locked':       [0, this.listLockedSettings],
         '/v1/transient_settings':    [0, this.listTransientSettings],
         '/v1/backend_state':         [1, this.getBackendState],
+        '/v1/events':                [1, this.subscribeEvents],
+        [ENGINE_PROXY_PATH]:         [1, this.engineProxy],
+      },
+      post: {
+        '/v1/diagnostic_checks': [0, this.diagnosticRunChecks],
+        '/v1/diagnostic_repair': [1, this.diagnosticRepair],
+        '/v1/tokens':            [1, this.createToken],
       },
-      post: { '/v1/diagnostic_checks': [0, this.diagnosticRunChecks] },
       put:  {
         '/v1/factory_reset':      [0, this.factoryReset],
         '/v1/propose_settings':   [0, this.proposeSettings],
@@ -103,11 +207,38 @@ export class HttpCommandServer {
       },
       delete: { '/v1/snapshots': [0, this.deleteSnapshot] },
     } as const,
+    {
+      get: {
+        '/v1/jobs': [1, this.listJobs],
+        '/v1/job':  [1, this.getJob],
+      },
+      post: {
+        '/v1/jobs':       [1, this.createJob],
+        '/v1/job/cancel': [1, this.cancelJob],
+      },
+    } as const,
+    {
+      post: {
+        '/v1/vm/start':   [1, this.startVM],
+        '/v1/vm/stop':    [1, this.stopVM],
+        '/v1/vm/restart': [1, this.restartVM],
+        '/v1/vm/pause':   [1, this.pauseVM],
+      },
+    } as const,
   );
 
   constructor(commandWorker: CommandWorkerInterface) {
     this.commandWorker = commandWorker;
     mainEvents.handle('api-get-credentials', () => Promise.resolve(this.interactiveState));
+    mainEvents.on('settings-update', (settings) => {
+      this.eventStream.publish('settings', settings);
+    });
+    mainEvents.on('k8s-check-state', () => {
+      this.eventStream.publish('backend-state', this.commandWorker.getBackendState());
+    });
+    mainEvents.on('backend-locked-update', () => {
+      this.eventStream.publish('backend-state', this.commandWorker.getBackendState());
+    });
   }
 
   async init() {
@@ -118,8 +249,8 @@ export class HttpCommandServer {
     if (process.platform === 'win32') {
       this.vtun.addTunnel({
         name:                  'CLI Server',
-        handshakePort:         17372,
-        vsockHostPort:         17371,
+        handshakePort:         instancePort(17372),
+        vsockHostPort:         instancePort(17371),
         peerAddress:           localHost,
         peerPort:              SERVER_PORT,
         upstreamServerAddress: `${ localHost }:${ SERVER_PORT }`,
@@ -128,15 +259,31 @@ export class HttpCommandServer {
     const statePath = path.join(paths.appHome, SERVER_FILE_BASENAME);
 
     await fs.promises.mkdir(paths.appHome, { recursive: true });
-    await fs.promises.writeFile(statePath,
+    // Serve over TLS with a self-signed certificate that clients pin, so that
+    // other local users can't intercept or replay requests.
+    const { cert, key } = await ensureServerCertificate(paths.appHome);
+
+    try {
+      this.externalState.socket = await this.listenOnSocket(SERVER_SOCKET_BASENAME);
+    } catch (ex) {
+      console.log('Failed to listen on the CLI server socket; continuing with TCP only:', ex);
+      delete this.externalState.socket;
+    }
+
+    // Write to a temporary file and rename it into place, so that clients
+    // never observe a partially written file.
+    await fs.promises.writeFile(`${ statePath }.tmp`,
       jsonStringifyWithWhiteSpace(this.externalState),
       { mode: 0o600 });
+    await fs.promises.rename(`${ statePath }.tmp`, statePath);
 
-    this.server = this.app
+    this.app
       .disable('etag')
       .disable('x-powered-by')
       .use(this.handleCORS)
-      .use(this.checkAuth)
+      .use(this.checkAuth);
+    this.server = https.createServer({ cert, key }, this.app)
+      .on('upgrade', this.handleUpgrade)
       .listen(SERVER_PORT, localHost)
       .on('error', (err) => {
         console.log(`Error: ${ err }`);
@@ -146,6 +293,48 @@ export class HttpCommandServer {
     console.log('CLI server is now ready.');
   }
 
+  /**
+   * Serve the API, without TLS or authentication, on a Unix domain socket in
+   * the application directory that only the current user can access, or on
+   * Windows, a named pipe (whose default security only allows the current
+   * user, administrators, and the system to write to it).
+   * @returns The path of the socket or pipe.
+   */
+  protected async listenOnSocket(basename: string): Promise<string> {
+    let socketPath: string;
+
+    if (process.platform === 'win32') {
+      socketPath = `\\\\.\\pipe\\${ path.basename(paths.appHome) }-${ os.userInfo().username }-${ basename }`;
+    } else {
+      socketPath = path.join(paths.appHome, basename);
+      // Remove any socket left behind by a previous run.
+      await fs.promises.rm(socketPath, { force: true });
+    }
+
+    const server = http.createServer(this.app)
+      .on('connection', (socket) => {
+        this.trustedConnections.add(socket);
+      })
+      .on('upgrade', this.handleUpgrade);
+
+    await new Promise<void>((resolve, reject) => {
+      server.once('error', reject);
+      server.listen(socketPath, () => {
+        server.off('error', reject);
+        resolve();
+      });
+    });
+    server.on('error', (err) => {
+      console.log(`Error: ${ err }`);
+    });
+    if (process.platform !== 'win32') {
+      await fs.promises.chmod(socketPath, 0o600);
+    }
+    this.socketServer = server;
+
+    return socketPath;
+  }
+
   /**
    * Set up HTTP routes for express.
    * This takes the information from the route decorators and applies it to the
@@ -170,6 +359,17 @@ export class HttpCommandServer {
         this.app[method](`/v${ version }/${ path }`, (req, resp, next) => {
           const context: commandContext = { interactive: resp.locals.interactive };
 
+          if (req.get(CANCEL_ON_DISCONNECT_HEADER) === 'true') {
+            const controller = new AbortController();
+
+            resp.on('close', () => {
+              if (!resp.writableFinished) {
+                controller.abort();
+              }
+            });
+            context.signal = controller.signal;
+          }
+
           handler.call(this, req, resp, context).catch(next);
         });
 
@@ -192,6 +392,9 @@ export class HttpCommandServer {
     this.app.get('/', (req, resp) => {
       this.listEndpoints('', req, resp);
     });
+    this.app.get('/versions', (req, resp) => {
+      this.listVersions(req, resp);
+    });
     // Set up catch-all handler for customized HTTP 404 message.
     this.app.all('*', ({ method, path }, resp) => {
       console.log(`404: No handler for URL ${ method } ${ path }.`);
@@ -202,36 +405,129 @@ export class HttpCommandServer {
     this.app.use(this.handleError.bind(this));
   }
 
-  /** checkAuth is middleware to verify authentication. */
-  protected checkAuth = (request: express.Request, response: express.Response, next: express.NextFunction) => {
+  /** Check the credentials of a request. */
+  protected authenticate(request: http.IncomingMessage): AuthResult {
     const authHeader = request.headers.authorization ?? '';
     const userDB = {
       [this.externalState.user]:    this.externalState.password,
       [this.interactiveState.user]: this.interactiveState.password,
     };
 
+    if (this.trustedConnections.has(request.socket)) {
+      return { ok: true, interactive: false };
+    }
+
+    const bearer = /^Bearer\s+(\S+)$/i.exec(authHeader);
+
+    if (bearer) {
+      const scope = this.tokens.verify(bearer[1]);
+
+      if (!scope) {
+        console.log('Auth failure: unknown or expired token');
+
+        return { ok: false, status: 401 };
+      }
+      if (scope === 'read' && !['GET', 'HEAD', 'OPTIONS'].includes(request.method ?? '')) {
+        return { ok: false, status: 403, message: `This token does not allow ${ request.method } requests` };
+      }
+
+      return { ok: true, interactive: false, tokenScope: scope };
+    }
+
     switch (serverHelper.basicAuth(userDB, authHeader)) {
     case this.externalState.user:
-      response.locals.interactive = false;
-      break;
+      return { ok: true, interactive: false };
     case this.interactiveState.user:
-      response.locals.interactive = true;
-      break;
+      return { ok: true, interactive: true };
     default:
-      response.type('txt').sendStatus(401);
+      return { ok: false, status: 401 };
+    }
+  }
+
+  /** checkAuth is middleware to verify authentication. */
+  protected checkAuth = (request: express.Request, response: express.Response, next: express.NextFunction) => {
+    const result = this.authenticate(request);
+
+    if (!result.ok) {
+      if (result.message) {
+        response.status(result.status).type('txt').send(result.message);
+      } else {
+        response.type('txt').sendStatus(result.status);
+      }
 
       return;
     }
+    response.locals.interactive = result.interactive;
+    if (result.tokenScope) {
+      response.locals.tokenScope = result.tokenScope;
+    }
     next();
   };
 
+  /**
+   * Handle requests to switch protocols.  The only protocol supported is the
+   * engine proxy, which turns the connection into a raw stream to the socket
+   * of the container engine, for tools that can't use the usual forwarded
+   * socket.  Express doesn't see these requests, so authentication is checked
+   * here.
+   */
+  protected handleUpgrade = async(request: http.IncomingMessage, socket: stream.Duplex, head: Buffer) => {
+    const reject = (status: number, message: string) => {
+      socket.end(`HTTP/1.1 ${ status } ${ http.STATUS_CODES[status] }\r\nContent-Type: text/plain\r\nConnection: close\r\n\r\n${ message }`);
+    };
+    const { pathname } = new URL(request.url ?? '/', 'http://localhost');
+
+    if (pathname !== ENGINE_PROXY_PATH || request.headers.upgrade?.toLowerCase() !== ENGINE_PROXY_PROTOCOL) {
+      reject(404, `Unknown command: ${ request.method } ${ pathname } (upgrade to ${ request.headers.upgrade })`);
+
+      return;
+    }
+
+    const auth = this.authenticate(request);
+
+    if (!auth.ok) {
+      reject(auth.status, auth.message ?? '');
+
+      return;
+    }
+    if (auth.tokenScope === 'read') {
+      reject(403, 'Read-only tokens cannot use the engine proxy');
+
+      return;
+    }
+
+    let engine: stream.Duplex;
+
+    try {
+      engine = await this.commandWorker.connectToEngine({ interactive: auth.interactive });
+    } catch (ex) {
+      console.log('Failed to connect to the container engine:', ex);
+      reject(503, `Failed to connect to the container engine: ${ ex }`);
+
+      return;
+    }
+
+    const close = () => {
+      socket.destroy();
+      engine.destroy();
+    };
+
+    socket.write(`HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: ${ ENGINE_PROXY_PROTOCOL }\r\n\r\n`);
+    if (head.length > 0) {
+      engine.write(head);
+    }
+    socket.pipe(engine).pipe(socket);
+    socket.on('error', close).on('close', close);
+    engine.on('error', close).on('close', close);
+  };
+
   /**
    * Calculate the headers needed for CORS, and set them on the response.
    */
   protected handleCORS(request: express.Request, response: express.Response, next: express.NextFunction): void {
     response.set({
       'Access-Control-Allow-Headers': 'Authorization',
-      'Access-Control-Allow-Methods': 'GET, PUT, DELETE',
+      'Access-Control-Allow-Methods': 'GET, PUT, POST, DELETE',
       'Access-Control-Allow-Origin':  '*',
     });
 
@@ -303,11 +599,43 @@ export class HttpCommandServer {
     const searchParams = url.searchParams;
     const category = searchParams.get('category');
     const id = searchParams.get('id');
-    const checks = await this.commandWorker.getDiagnosticChecks(category, id, context);
+    const passed = searchParams.get('passed');
+    const query = this.parseListQuery(searchParams, response, DIAGNOSTIC_CHECK_FIELDS);
+
+    if (!query) {
+      return;
+    }
+    if (passed !== null && !['true', 'false'].includes(passed)) {
+      response.status(400).type('txt').send(`Invalid passed ${ JSON.stringify(passed) }: must be true or false`);
+
+      return;
+    }
+
+    const result = await this.commandWorker.getDiagnosticChecks(category, id, context);
+    const checks = passed === null ? result.checks : result.checks.filter(check => String(check.passed) === passed);
+    const { page, total } = applyListQuery(checks, query, DIAGNOSTIC_CHECK_FIELDS);
 
     console.debug('diagnostic_checks: succeeded 200');
-    response.type('json').status(200)
-      .send(jsonStringifyWithWhiteSpace(checks));
+    response.type('json').status(200).set(TOTAL_COUNT_HEADER, `${ total }`)
+      .send(jsonStringifyWithWhiteSpace({ ...result, checks: page }));
+  }
+
+  /**
+   * Parse the list parameters of a request, replying with a 400 if they are
+   * invalid.
+   * @returns The query, or undefined if the response has been sent.
+   */
+  protected parseListQuery<T>(params: URLSearchParams, response: express.Response, fields: ListFields<T>): ListQuery | undefined {
+    try {
+      return parseListQuery(params, fields);
+    } catch (ex) {
+      if (ex instanceof ListQueryError) {
+        response.status(400).type('txt').send(ex.message);
+
+        return undefined;
+      }
+      throw ex;
+    }
   }
 
   protected async diagnosticRunChecks(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
@@ -318,6 +646,44 @@ export class HttpCommandServer {
       .send(jsonStringifyWithWhiteSpace(results));
   }
 
+  /**
+   * Attempt to fix the problem found by a diagnostic check, replying with the
+   * new result of the check.
+   */
+  protected async diagnosticRepair(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const id = request.query.id ?? '';
+
+    if (!id) {
+      response.status(400).type('txt').send('Diagnostic check ID is required in the id= parameter.');
+
+      return;
+    } else if (typeof id !== 'string') {
+      response.status(400).type('txt').send(`Invalid diagnostic check ID ${ JSON.stringify(id) }: not a string.`);
+
+      return;
+    }
+
+    try {
+      const result = await this.commandWorker.repairDiagnostic(context, id);
+
+      if (result) {
+        console.debug('diagnostic_repair: succeeded 200');
+        response.status(200).type('json').send(jsonStringifyWithWhiteSpace(result));
+      } else {
+        console.debug('diagnostic_repair: failed 404');
+        response.status(404).type('txt').send(`Unknown diagnostic check ${ JSON.stringify(id) }`);
+      }
+    } catch (ex) {
+      if (ex instanceof DiagnosticsNotRepairableError) {
+        console.debug('diagnostic_repair: failed 409');
+        response.status(409).type('txt').send(ex.message);
+      } else {
+        console.debug(`diagnostic_repair: failed 500: ${ ex }`);
+        response.status(500).type('txt').send(`Failed to repair ${ id }: ${ ex }`);
+      }
+    }
+  }
+
   protected invalidAPIVersionCall(neededVersion: number, request: express.Request, response: express.Response): Promise<void> {
     const method = request.method;
     const path = request.path;
@@ -331,6 +697,14 @@ export class HttpCommandServer {
     return Promise.resolve();
   }
 
+  /** Publish the OpenAPI definition of this API, as JSON. */
+  protected openAPISpec(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    console.debug('openapi: succeeded 200');
+    response.status(200).json(COMMAND_API_SPEC);
+
+    return Promise.resolve();
+  }
+
   protected about(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
     const msg = 'The API is currently at version 1, but is still considered internal and experimental, and is subject to change without any advance notice.';
 
@@ -368,6 +742,29 @@ export class HttpCommandServer {
     return Promise.resolve();
   }
 
+  /**
+   * List the API versions that have endpoints, so that clients can pick one
+   * they support.  Versions only listed for backwards compatibility (where all
+   * the endpoints have moved to a newer version) are not included.
+   */
+  protected listVersions(request: express.Request, response: express.Response): Promise<void> {
+    const versions = new Set<number>();
+
+    for (const data of Object.values(this.dispatchTable)) {
+      for (const route of Object.keys(data)) {
+        const [, version] = /^\/v(\d+)\//.exec(route) ?? [];
+
+        versions.add(parseInt(version, 10));
+      }
+    }
+    const result = Array.from(versions).sort((a, b) => a - b).map(version => `v${ version }`);
+
+    console.debug('listVersions: succeeded 200');
+    response.status(200).type('json').send(JSON.stringify({ versions: result }));
+
+    return Promise.resolve();
+  }
+
   protected listEndpoints(version: string, request: express.Request, response: express.Response): Promise<void> {
     // Determine all API paths, possibly filtered by the requested version.
     const apiPaths: [Uppercase<HttpMethod>, string][] = [];
@@ -392,6 +789,7 @@ export class HttpCommandServer {
     } else {
       // If no version is given, provide the unversioned API to list APIs.
       apiPaths.push(['GET', '/']);
+      apiPaths.push(['GET', '/versions']);
       for (let listVersion = 0; listVersion <= maxVersion; ++listVersion) {
         apiPaths.push(['GET', `/v${ listVersion }`]);
       }
@@ -463,13 +861,14 @@ export class HttpCommandServer {
     let error: string;
     let errorCode = 400;
     let result = '';
+    let restarting = false;
     const body = await this.readRequestSettings(request, 'updateSettings');
 
     if (Array.isArray(body)) {
       [errorCode, error] = body;
     } else {
       try {
-        [result, error] = await this.commandWorker.updateSettings(context, body);
+        [result, error, restarting = false] = await this.commandWorker.updateSettings(context, body);
       } catch (ex) {
         console.error(`updateSettings: exception when updating:`, ex);
         errorCode = 500;
@@ -482,7 +881,7 @@ export class HttpCommandServer {
       response.status(errorCode).type('txt').send(error);
     } else {
       console.debug(`updateSettings: write back status 202, result: ${ result }`);
-      response.status(202).type('txt').send(result);
+      response.status(202).type('txt').set(RESTARTING_HEADER, `${ restarting }`).send(result);
     }
   }
 
@@ -560,6 +959,7 @@ export class HttpCommandServer {
 
   closeServer() {
     this.server.close();
+    this.socketServer?.close();
   }
 
   protected listTransientSettings(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
@@ -602,9 +1002,17 @@ export class HttpCommandServer {
   }
 
   protected async listExtensions(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const query = this.parseListQuery(new URL(request.url, 'http://localhost').searchParams, response, EXTENSION_FIELDS);
+
+    if (!query) {
+      return;
+    }
+
     const extensions = await this.commandWorker.listExtensions();
+    const { page, total } = applyListQuery(Object.entries(extensions), query, EXTENSION_FIELDS);
 
-    response.status(200).type('json').send(extensions);
+    response.status(200).type('json').set(TOTAL_COUNT_HEADER, `${ total }`)
+      .send(Object.fromEntries(page));
   }
 
   protected async installExtension(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
@@ -618,6 +1026,11 @@ export class HttpCommandServer {
       response.writeProcessing();
       const { status, data } = await this.commandWorker.installExtension(id, 'install');
 
+      if (status === 503) {
+        // The extension manager is not available until the backend has started.
+        response.set('Retry-After', BACKEND_STARTING_RETRY_AFTER);
+      }
+
       if (data) {
         if (typeof data === 'string') {
           response.status(status).type('txt').send(data);
@@ -642,6 +1055,11 @@ export class HttpCommandServer {
       const { status, data: rawData } = await this.commandWorker.installExtension(id, 'uninstall');
       const data = rawData || `Deleted ${ id }`;
 
+      if (status === 503) {
+        // The extension manager is not available until the backend has started.
+        response.set('Retry-After', BACKEND_STARTING_RETRY_AFTER);
+      }
+
       if (data) {
         if (typeof data === 'string') {
           response.status(status).type('txt').send(data);
@@ -654,6 +1072,37 @@ export class HttpCommandServer {
     }
   }
 
+  /**
+   * Stream events to the client as server-sent events.  The `types` query
+   * parameter is a comma-separated list of the event types to subscribe to;
+   * by default, all events are sent.  The current state is sent as the first
+   * event of each type.
+   */
+  protected subscribeEvents(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const types = EventStream.parseTypes(request.query.types);
+
+    if (typeof types === 'string') {
+      response.status(400).type('txt').send(types);
+
+      return Promise.resolve();
+    }
+
+    const initial = types.map(type => [type, this.currentEventData(type, context)] as const);
+
+    this.eventStream.subscribe(request, response, types, initial);
+
+    return Promise.resolve();
+  }
+
+  protected currentEventData(type: EventType, context: commandContext): any {
+    switch (type) {
+    case 'settings':
+      return JSON.parse(this.commandWorker.getSettings(context));
+    case 'backend-state':
+      return this.commandWorker.getBackendState();
+    }
+  }
+
   protected getBackendState(_: express.Request, response: express.Response, context: commandContext): Promise<void> {
     const backendState = this.commandWorker.getBackendState();
 
@@ -663,6 +1112,47 @@ export class HttpCommandServer {
     return Promise.resolve();
   }
 
+  /**
+   * The engine proxy only works over an upgraded connection (see handleUpgrade);
+   * plain requests are told how to ask for it.
+   */
+  protected engineProxy(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    response.status(426)
+      .set({ Connection: 'Upgrade', Upgrade: ENGINE_PROXY_PROTOCOL })
+      .type('txt')
+      .send(`The engine proxy requires "Upgrade: ${ ENGINE_PROXY_PROTOCOL }"`);
+
+    return Promise.resolve();
+  }
+
+  protected startVM(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    return this.changeVMState('start', response, context);
+  }
+
+  protected stopVM(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    return this.changeVMState('stop', response, context);
+  }
+
+  protected restartVM(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    return this.changeVMState('restart', response, context);
+  }
+
+  protected pauseVM(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    return this.changeVMState('pause', response, context);
+  }
+
+  /**
+   * Ask the command worker to carry out a VM lifecycle action; these return as
+   * soon as the action has started (202), and clients follow the progress
+   * through the backend state.
+   */
+  protected async changeVMState(action: VMLifecycleAction, response: express.Response, context: commandContext): Promise<void> {
+    const { status, data } = await this.commandWorker.changeVMState(context, action);
+
+    console.debug(`vm/${ action }: write back status ${ status }, result: ${ data }`);
+    response.status(status).type('txt').send(data);
+  }
+
   protected async setBackendState(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
     let result = 'received backend state';
     let statusCode = 202;
@@ -682,10 +1172,62 @@ export class HttpCommandServer {
     return Promise.resolve();
   }
 
+  /**
+   * Create a short-lived bearer token.  The request body may specify the
+   * `scope` of the token (`read` or `full`, defaulting to `full`) and its
+   * lifetime in seconds as `ttl`.  Tokens can only be created using the API
+   * credentials, so that a token can't be used to extend its own lifetime or
+   * widen its own scope.
+   */
+  protected async createToken(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    if (response.locals.tokenScope) {
+      response.status(403).type('txt').send('Tokens can only be created using the API credentials');
+
+      return;
+    }
+
+    const [data, payloadError, payloadErrorCode] = await serverHelper.getRequestBody(request, MAX_REQUEST_BODY_LENGTH);
+
+    if (payloadError) {
+      response.status(payloadErrorCode).type('txt').send(payloadError);
+
+      return;
+    }
+
+    let options: { scope?: unknown, ttl?: unknown };
+
+    try {
+      options = data.trim() ? JSON.parse(data) : {};
+    } catch (ex) {
+      response.status(400).type('txt').send(`Invalid token request: ${ ex }`);
+
+      return;
+    }
+
+    const scope = options.scope ?? 'full';
+    const ttl = options.ttl ?? DEFAULT_TOKEN_TTL;
+
+    if (!TOKEN_SCOPES.includes(scope as TokenScope)) {
+      response.status(400).type('txt').send(`Invalid token scope ${ JSON.stringify(scope) }; must be one of ${ TOKEN_SCOPES.join(', ') }`);
+    } else if (typeof ttl !== 'number' || !Number.isInteger(ttl) || ttl <= 0 || ttl > MAX_TOKEN_TTL) {
+      response.status(400).type('txt').send(`Invalid token lifetime ${ JSON.stringify(ttl) }; must be a whole number of seconds up to ${ MAX_TOKEN_TTL }`);
+    } else {
+      response.status(200).type('json').send(this.tokens.create(scope as TokenScope, ttl));
+    }
+  }
+
   protected async listSnapshots(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const query = this.parseListQuery(new URL(request.url, 'http://localhost').searchParams, response, SNAPSHOT_FIELDS);
+
+    if (!query) {
+      return;
+    }
+
     const snapshots = await this.commandWorker.listSnapshots(context);
+    const { page, total } = applyListQuery(snapshots, query, SNAPSHOT_FIELDS);
 
-    response.status(200).type('json').send(snapshots);
+    response.status(200).type('json').set(TOTAL_COUNT_HEADER, `${ total }`)
+      .send(page);
   }
 
   protected async createSnapshot(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
@@ -759,10 +1301,184 @@ export class HttpCommandServer {
       }
     }
   }
+
+  protected listJobs(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    response.status(200).type('json').send(this.jobs.list());
+
+    return Promise.resolve();
+  }
+
+  protected getJob(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const id = request.query.id ?? '';
+    const job = typeof id === 'string' ? this.jobs.get(id) : undefined;
+
+    if (!id) {
+      response.status(400).type('txt').send('Job ID is required in the id= parameter.');
+    } else if (!job) {
+      response.status(404).type('txt').send(`Unknown job ${ JSON.stringify(id) }`);
+    } else {
+      response.status(200).type('json').send(job);
+    }
+
+    return Promise.resolve();
+  }
+
+  protected cancelJob(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const id = request.query.id ?? '';
+
+    if (!id) {
+      response.status(400).type('txt').send('Job ID is required in the id= parameter.');
+    } else if (typeof id !== 'string') {
+      response.status(400).type('txt').send(`Invalid job ID ${ JSON.stringify(id) }: not a string.`);
+    } else {
+      try {
+        const job = this.jobs.cancel(id);
+
+        if (job) {
+          response.status(202).type('json').send(job);
+        } else {
+          response.status(404).type('txt').send(`Unknown job ${ JSON.stringify(id) }`);
+        }
+      } catch (ex) {
+        if (ex instanceof JobNotCancelableError) {
+          response.status(409).type('txt').send(ex.message);
+        } else {
+          throw ex;
+        }
+      }
+    }
+
+    return Promise.resolve();
+  }
+
+  /**
+   * Start a long-running operation, replying with the new job; clients
+   * follow it through GET /v1/job?id=...
+   */
+  protected async createJob(request: express.Request, response: express.Response, context: commandContext): Promise<void> {
+    const [data, payloadError, payloadErrorCode] = await serverHelper.getRequestBody(request, MAX_REQUEST_BODY_LENGTH);
+
+    if (payloadError) {
+      response.status(payloadErrorCode).type('txt').send(payloadError);
+
+      return;
+    }
+
+    let params: Record<string, unknown>;
+
+    try {
+      params = JSON.parse(data);
+    } catch (ex) {
+      response.status(400).type('txt').send(`Invalid job request: ${ ex }`);
+
+      return;
+    }
+
+    const kind = params?.kind as JobKind;
+
+    if (!JOB_KINDS.includes(kind)) {
+      response.status(400).type('txt').send(`Invalid job kind ${ JSON.stringify(kind) }; must be one of ${ JOB_KINDS.join(', ') }`);
+
+      return;
+    }
+
+    // The job outlives the request, so it must not be aborted when the client
+    // disconnects.
+    const jobContext: commandContext = { interactive: context.interactive };
+    let run: (control: JobControl) => Promise<string>;
+    let cancelable = false;
+
+    switch (kind) {
+    case 'factory-reset': {
+      const keepSystemImages = params.keepSystemImages ?? false;
+
+      if (typeof keepSystemImages !== 'boolean') {
+        response.status(400).type('txt').send(`Invalid keepSystemImages ${ JSON.stringify(keepSystemImages) }: not a boolean.`);
+
+        return;
+      }
+      // The application exits during the reset, so the job only covers
+      // starting it.
+      run = () => {
+        setImmediate(() => {
+          this.closeServer();
+          this.commandWorker.factoryReset(keepSystemImages);
+        });
+
+        return Promise.resolve('Doing a full factory reset....');
+      };
+      break;
+    }
+    case 'kubernetes-reset': {
+      const mode = params.mode ?? 'fast';
+
+      if (mode !== 'fast' && mode !== 'wipe') {
+        response.status(400).type('txt').send(`Invalid reset mode ${ JSON.stringify(mode) }; must be fast or wipe`);
+
+        return;
+      }
+      run = async({ progress }) => {
+        await this.commandWorker.resetKubernetes(jobContext, mode, progress);
+
+        return 'Kubernetes successfully reset';
+      };
+      break;
+    }
+    case 'snapshot-create':
+    case 'snapshot-restore':
+    case 'snapshot-delete': {
+      const { name, description } = params;
+
+      if (!name || typeof name !== 'string') {
+        response.status(400).type('txt').send('The name field is required');
+
+        return;
+      }
+      if (description !== undefined && typeof description !== 'string') {
+        response.status(400).type('txt').send(`Invalid description ${ JSON.stringify(description) }: not a string.`);
+
+        return;
+      }
+      if (kind === 'snapshot-create') {
+        // Snapshots can't be interrupted on Windows; see Snapshots.create().
+        cancelable = process.platform !== 'win32';
+        run = async({ signal }) => {
+          await this.commandWorker.createSnapshot({ ...jobContext, signal }, { name, description, created: '' });
+
+          return 'Snapshot successfully created';
+        };
+      } else if (kind === 'snapshot-restore') {
+        run = async() => {
+          await this.commandWorker.restoreSnapshot(jobContext, name);
+
+          return 'Snapshot successfully restored';
+        };
+      } else {
+        run = async() => {
+          await this.commandWorker.deleteSnapshot(jobContext, name);
+
+          return 'Snapshot successfully deleted';
+        };
+      }
+      break;
+    }
+    }
+
+    const job = this.jobs.create(kind, run, cancelable);
+
+    console.debug(`jobs: started ${ kind } job ${ job.id }`);
+    response.status(202).type('json').set('Location', `/v1/job?id=${ job.id }`)
+      .send(job);
+  }
 }
 
 interface commandContext {
   interactive: boolean;
+  /**
+   * Aborted when the client disconnects before the response has been sent,
+   * if the client asked for that; long-running operations should stop.
+   */
+  signal?: AbortSignal;
 }
 
 /**
@@ -775,19 +1491,42 @@ export interface CommandWorkerInterface {
   factoryReset: (keepSystemImages: boolean) => void;
   getSettings: (context: commandContext) => string;
   getLockedSettings: (context: commandContext) => string;
-  updateSettings: (context: commandContext, newSettings: RecursivePartial<Settings>) => Promise<[string, string]>;
+  /**
+   * Apply the given settings.
+   * @returns The description of the outcome, any error, and whether the
+   * backend will restart to apply the settings.
+   */
+  updateSettings: (context: commandContext, newSettings: RecursivePartial<Settings>) => Promise<[string, string, boolean?]>;
   proposeSettings: (context: commandContext, newSettings: RecursivePartial<Settings>) => Promise<[string, string]>;
   requestShutdown: (context: commandContext) => void;
   getDiagnosticCategories: (context: commandContext) => string[]|undefined;
   getDiagnosticIdsByCategory: (category: string, context: commandContext) => string[]|undefined;
   getDiagnosticChecks: (category: string|null, checkID: string|null, context: commandContext) => Promise<DiagnosticsResultCollection>;
   runDiagnosticChecks: (context: commandContext) => Promise<DiagnosticsResultCollection>;
+  /**
+   * Attempt to fix the problem found by a diagnostic check.
+   * @returns The new result, or undefined if the check is unknown.
+   */
+  repairDiagnostic: (context: commandContext, checkID: string) => Promise<DiagnosticsResult|undefined>;
   getTransientSettings: (context: commandContext) => string;
   updateTransientSettings: (context: commandContext, newTransientSettings: RecursivePartial<TransientSettings>) => Promise<[string, string]>;
   /** Get the state of the backend */
   getBackendState: () => BackendState;
   /** Set the desired state of the backend */
   setBackendState: (state: BackendState) => void;
+  /**
+   * Start, stop, restart or pause the VM, returning an appropriate HTTP status
+   * code and message.
+   */
+  changeVMState: (context: commandContext, action: VMLifecycleAction) => Promise<{status: number, data: string}>;
+  /**
+   * Reset Kubernetes, waiting for it to come back up.
+   * @param mode Whether to only reset the cluster, or to delete the VM.
+   * @param progress Called as the backend reports progress.
+   */
+  resetKubernetes: (context: commandContext, mode: 'fast' | 'wipe', progress: (progress: JobProgress) => void) => Promise<void>;
+  /** Open a connection to the socket of the container engine */
+  connectToEngine: (context: commandContext) => Promise<stream.Duplex>;
 
   // #region extensions
   /** List the installed extensions with their versions */
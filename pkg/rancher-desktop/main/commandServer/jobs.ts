@@ -0,0 +1,163 @@
+import crypto from 'crypto';
+
+import _ from 'lodash';
+
+/**
+ * The operations that can be run as jobs, so that clients don't need to hold
+ * a request open (and risk timing out) until they finish.
+ */
+export type JobKind = 'factory-reset' | 'kubernetes-reset' | 'snapshot-create' | 'snapshot-restore' | 'snapshot-delete';
+
+export const JOB_KINDS: readonly JobKind[] = ['factory-reset', 'kubernetes-reset', 'snapshot-create', 'snapshot-restore', 'snapshot-delete'];
+
+export type JobState = 'running' | 'succeeded' | 'failed' | 'canceled';
+
+/** How long finished jobs are kept around for clients to collect, in milliseconds. */
+export const FINISHED_JOB_TTL = 60 * 60 * 1000;
+
+export type JobProgress = {
+  /** The current progress; valid values are 0 to max. */
+  current:      number;
+  /** Maximum progress possible; if less than zero, the progress is indeterminate. */
+  max:          number;
+  /** Details on the current action. */
+  description?: string;
+};
+
+/** JobInfo is the description of a job returned over the API. */
+export type JobInfo = {
+  id:          string;
+  kind:        JobKind;
+  state:       JobState;
+  /** Whether the job stops when it is canceled. */
+  cancelable:  boolean;
+  progress?:   JobProgress;
+  /** A message describing the outcome of a successful job. */
+  result?:     string;
+  /** Why the job failed or was canceled. */
+  error?:      string;
+  /** When the job was created, as an ISO 8601 timestamp. */
+  createdAt:   string;
+  /** When the job finished, as an ISO 8601 timestamp. */
+  finishedAt?: string;
+};
+
+/** JobControl is given to the function running a job. */
+export interface JobControl {
+  /** Aborted when the job is canceled. */
+  readonly signal: AbortSignal;
+  /** Report the progress of the job. */
+  progress(progress: JobProgress): void;
+}
+
+export class JobNotCancelableError extends Error {
+}
+
+type JobEntry = {
+  info:       JobInfo;
+  controller: AbortController;
+  finished?:  number;
+};
+
+/**
+ * JobStore keeps track of the long-running operations started through the
+ * API.  Like tokens, jobs only live in memory.
+ */
+export class JobStore {
+  protected jobs = new Map<string, JobEntry>();
+
+  /**
+   * Start a new job.
+   * @param kind The operation the job runs.
+   * @param run Runs the operation, returning a message describing the outcome.
+   * @param cancelable Whether run stops (by throwing) when the signal is
+   * aborted; other jobs can't be canceled.
+   */
+  create(kind: JobKind, run: (control: JobControl) => Promise<string>, cancelable = false): JobInfo {
+    const id = crypto.randomUUID();
+    const controller = new AbortController();
+    const entry: JobEntry = {
+      info: {
+        id, kind, state: 'running', cancelable, createdAt: new Date().toISOString(),
+      },
+      controller,
+    };
+    const control: JobControl = {
+      signal: controller.signal,
+      progress(progress) {
+        if (entry.info.state === 'running') {
+          entry.info.progress = { ...progress };
+        }
+      },
+    };
+
+    this.prune();
+    this.jobs.set(id, entry);
+    run(control).then((result) => {
+      this.finish(entry, 'succeeded', { result });
+    }, (ex) => {
+      console.error(`Job ${ id } (${ kind }) failed:`, ex);
+      this.finish(entry, controller.signal.aborted ? 'canceled' : 'failed', { error: `${ ex?.message ?? ex }` });
+    });
+
+    return { ...entry.info };
+  }
+
+  /** Look up a job, returning undefined if it is unknown or expired. */
+  get(id: string): JobInfo | undefined {
+    this.prune();
+    const entry = this.jobs.get(id);
+
+    return entry ? { ...entry.info } : undefined;
+  }
+
+  /** List the known jobs, oldest first. */
+  list(): JobInfo[] {
+    this.prune();
+
+    return Array.from(this.jobs.values(), entry => ({ ...entry.info }));
+  }
+
+  /**
+   * Ask a running job to stop; it is marked as canceled once it does.
+   * @returns The job, or undefined if it is unknown.
+   * @throws JobNotCancelableError if the job is running but can't be canceled.
+   */
+  cancel(id: string): JobInfo | undefined {
+    const entry = this.jobs.get(id);
+
+    if (entry?.info.state === 'running') {
+      if (!entry.info.cancelable) {
+        throw new JobNotCancelableError(`The ${ entry.info.kind } job ${ id } can't be canceled`);
+      }
+      entry.controller.abort();
+    }
+
+    return entry ? { ...entry.info } : undefined;
+  }
+
+  protected finish(entry: JobEntry, state: JobState, outcome: { result?: string, error?: string }) {
+    entry.finished = Date.now();
+    entry.info = {
+      // The progress of a finished job is no longer meaningful.
+      ..._.omit(entry.info, 'progress'),
+      ...outcome,
+      state,
+      finishedAt: new Date(entry.finished).toISOString(),
+    };
+    if (state === 'canceled' && !entry.info.error) {
+      entry.info.error = 'The job was canceled';
+    }
+  }
+
+  /** Forget the jobs that finished too long ago. */
+  protected prune() {
+    const cutoff = Date.now() - FINISHED_JOB_TTL;
+
+    for (const [id, { finished }] of this.jobs) {
+      if (finished !== undefined && finished <= cutoff) {
+        this.jobs.delete(id);
+      }
+    }
+  }
+}
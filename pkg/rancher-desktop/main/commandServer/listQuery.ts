@@ -0,0 +1,110 @@
+/**
+ * The parameters shared by the list endpoints: `filter` keeps the items with a
+ * text field containing the given string (case-insensitively), `sort` orders
+ * them by a field (descending if prefixed with `-`), and `offset` and `limit`
+ * select a page of the result.  The number of items matching the filter is
+ * returned in the `X-Total-Count` header, so the body keeps its usual shape.
+ */
+
+export const TOTAL_COUNT_HEADER = 'X-Total-Count';
+
+/** ListQueryError is thrown for invalid list parameters; it maps to a 400. */
+export class ListQueryError extends Error {
+}
+
+type FieldValue = string | number | boolean | undefined;
+
+/**
+ * ListFields describes how to filter and sort the items of a list endpoint.
+ */
+export type ListFields<T> = {
+  /** The fields `sort` may name, and how to get their values. */
+  sortable: Record<string, (item: T) => FieldValue>;
+  /** The text fields `filter` searches. */
+  searchable: ((item: T) => string | undefined)[];
+  /** The field to sort by when the client doesn't ask for one. */
+  defaultSort?: string;
+};
+
+export type ListQuery = {
+  filter?:    string;
+  sortField?: string;
+  descending: boolean;
+  offset:     number;
+  limit?:     number;
+};
+
+function parseCount(params: URLSearchParams, name: string): number | undefined {
+  const value = params.get(name);
+
+  if (value === null) {
+    return undefined;
+  }
+  if (!/^\d+$/.test(value)) {
+    throw new ListQueryError(`Invalid ${ name } ${ JSON.stringify(value) }: must be a non-negative integer`);
+  }
+
+  return parseInt(value, 10);
+}
+
+/** Parse the list parameters of a request. */
+export function parseListQuery<T>(params: URLSearchParams, fields: ListFields<T>): ListQuery {
+  const sort = params.get('sort') ?? fields.defaultSort;
+  const descending = !!sort?.startsWith('-');
+  const sortField = descending ? sort?.substring(1) : sort;
+
+  if (sortField !== undefined && !(sortField in fields.sortable)) {
+    const names = Object.keys(fields.sortable).join(', ');
+
+    throw new ListQueryError(`Invalid sort field ${ JSON.stringify(sortField) }: must be one of ${ names }`);
+  }
+
+  return {
+    filter: params.get('filter') || undefined,
+    sortField,
+    descending,
+    offset: parseCount(params, 'offset') ?? 0,
+    limit:  parseCount(params, 'limit'),
+  };
+}
+
+function compare(a: FieldValue, b: FieldValue): number {
+  if (a === b) {
+    return 0;
+  }
+  if (a === undefined) {
+    return 1;
+  }
+  if (b === undefined) {
+    return -1;
+  }
+  if (typeof a === 'string' && typeof b === 'string') {
+    return a.localeCompare(b);
+  }
+
+  return a < b ? -1 : 1;
+}
+
+/**
+ * Apply the list parameters to the items, returning the requested page and
+ * the number of items that matched the filter.
+ */
+export function applyListQuery<T>(items: readonly T[], query: ListQuery, fields: ListFields<T>): { page: T[], total: number } {
+  let result = [...items];
+
+  if (query.filter) {
+    const needle = query.filter.toLowerCase();
+
+    result = result.filter(item => fields.searchable.some(field => field(item)?.toLowerCase().includes(needle)));
+  }
+  if (query.sortField) {
+    const getValue = fields.sortable[query.sortField];
+    const direction = query.descending ? -1 : 1;
+
+    result.sort((a, b) => direction * compare(getValue(a), getValue(b)));
+  }
+
+  const end = query.limit === undefined ? undefined : query.offset + query.limit;
+
+  return { page: result.slice(query.offset, end), total: result.length };
+}
@@ -1,3 +1,4 @@
+import net from 'net';
 import os from 'os';
 
 import Electron from 'electron';
@@ -16,6 +17,7 @@ import {
 } from '@pkg/config/settings';
 import { NavItemName, navItemNames, TransientSettings } from '@pkg/config/transientSettings';
 import { PathManagementStrategy } from '@pkg/integrations/pathManager';
+import { autoIntegrationState, isAutoIntegrationLocked } from '@pkg/integrations/wslAutoIntegration';
 import { parseImageReference, validateImageName, validateImageTag } from '@pkg/utils/dockerUtils';
 import { getMacOsVersion } from '@pkg/utils/osVersion';
 import { RecursivePartial } from '@pkg/utils/typeUtils';
@@ -101,12 +103,24 @@ export default class SettingsValidator {
           patterns: this.checkUniqueStringArray,
         },
         // 'docker' has been canonicalized to 'moby' already, but we want to include it as a valid value in the error message
-        name: this.checkEnum('containerd', 'moby', 'docker'),
+        name:         this.checkEnum('containerd', 'moby', 'docker'),
+        credentials:  { encrypt: this.checkBoolean },
+        socketPolicy: {
+          enabled:           this.checkPlatform('win32', this.checkBoolean),
+          allowPrivileged:   this.checkPlatform('win32', this.checkBoolean),
+          allowedMountRoots: this.checkPlatform('win32', this.checkUniqueStringArray),
+        },
       },
       virtualMachine: {
         memoryInGB:   this.checkLima(this.checkNumber(1, Number.POSITIVE_INFINITY)),
         numberCPUs:   this.checkLima(this.checkNumber(1, Number.POSITIVE_INFINITY)),
         hostResolver: this.checkPlatform('win32', this.checkBoolean),
+        gpu:          this.checkPlatform('win32', this.checkBoolean),
+        dns:          {
+          upstreamServers: this.checkPlatform('win32', this.checkDNSServers),
+          domains:         this.checkPlatform('win32', this.checkDNSDomains),
+          ignoreVPN:       this.checkPlatform('win32', this.checkBoolean),
+        },
       },
       experimental: {
         virtualMachine: {
@@ -139,7 +153,13 @@ export default class SettingsValidator {
           },
         },
       },
-      WSL:        { integrations: this.checkPlatform('win32', this.checkBooleanMapping) },
+      WSL: {
+        integrations:    this.checkPlatform('win32', this.checkWSLIntegrations),
+        autoIntegration: {
+          include: this.checkPlatform('win32', this.checkUniqueStringArray),
+          exclude: this.checkPlatform('win32', this.checkUniqueStringArray),
+        },
+      },
       kubernetes: {
         version: this.checkKubernetesVersion,
         port:    this.checkNumber(1, 65535),
@@ -524,6 +544,29 @@ export default class SettingsValidator {
     return errors.length === 0 && changed;
   }
 
+  /**
+   * checkWSLIntegrations checks the per-distro integration choices; if the
+   * automatic integration rules are locked, choices contrary to them are
+   * rejected.
+   */
+  protected checkWSLIntegrations(mergedSettings: Settings, currentValue: Record<string, boolean>, desiredValue: Record<string, boolean>, errors: string[], fqname: string): boolean {
+    const changed = this.checkBooleanMapping(mergedSettings, currentValue, desiredValue, errors, fqname);
+
+    if (!changed || !isAutoIntegrationLocked(this.lockedSettings)) {
+      return changed;
+    }
+    for (const [distro, value] of Object.entries(desiredValue)) {
+      const ruleState = autoIntegrationState(mergedSettings.WSL.autoIntegration, distro);
+
+      if (typeof value === 'boolean' && ruleState !== undefined && value !== ruleState) {
+        errors.push(`field "${ fqname }.${ distro }" is locked by the WSL.autoIntegration rules`);
+        this.isFatal = true;
+      }
+    }
+
+    return errors.length === 0;
+  }
+
   protected checkUniqueStringArray<S>(mergedSettings: S, currentValue: string[], desiredValue: string[], errors: string[], fqname: string): boolean {
     if (!Array.isArray(desiredValue) || desiredValue.some(s => typeof (s) !== 'string')) {
       errors.push(this.invalidSettingMessage(fqname, desiredValue));
@@ -623,6 +666,55 @@ export default class SettingsValidator {
     return errors.length === 0 && changed;
   }
 
+  protected checkDNSServers(
+    mergedSettings: Settings,
+    currentValue: string[],
+    desiredValue: any,
+    errors: string[],
+    fqname: string,
+  ): boolean {
+    const changed = this.checkUniqueStringArray(mergedSettings, currentValue, desiredValue, errors, fqname);
+
+    if (errors.length) {
+      return changed;
+    }
+
+    for (const server of desiredValue as string[]) {
+      if (!net.isIP(server)) {
+        errors.push(`${ fqname }: "${ server }" is not an IP address`);
+      }
+    }
+
+    return errors.length === 0 && changed;
+  }
+
+  /**
+   * checkDNSDomains checks per-domain DNS servers, given as `domain=server`.
+   */
+  protected checkDNSDomains(
+    mergedSettings: Settings,
+    currentValue: string[],
+    desiredValue: any,
+    errors: string[],
+    fqname: string,
+  ): boolean {
+    const changed = this.checkUniqueStringArray(mergedSettings, currentValue, desiredValue, errors, fqname);
+
+    if (errors.length) {
+      return changed;
+    }
+
+    for (const rule of desiredValue as string[]) {
+      const [domain, server, ...rest] = rule.split('=');
+
+      if (rest.length > 0 || !/^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$/i.test(domain ?? '') || !net.isIP(server ?? '')) {
+        errors.push(`${ fqname }: "${ rule }" is not of the form "domain=server", where server is an IP address`);
+      }
+    }
+
+    return errors.length === 0 && changed;
+  }
+
   protected checkPreferencesNavItemCurrent(
     mergedSettings: TransientSettings,
     currentValue: NavItemName,
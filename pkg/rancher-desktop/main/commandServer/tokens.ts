@@ -0,0 +1,83 @@
+import crypto from 'crypto';
+
+/**
+ * The access granted by an API token: `read` tokens can only make GET
+ * requests, while `full` tokens can do anything the API credentials can
+ * (except creating further tokens).
+ */
+export type TokenScope = 'read' | 'full';
+
+export const TOKEN_SCOPES: readonly TokenScope[] = ['read', 'full'];
+
+/** The lifetime of a token, in seconds, if the client doesn't ask for one. */
+export const DEFAULT_TOKEN_TTL = 15 * 60;
+/** The longest lifetime a token may be given, in seconds. */
+export const MAX_TOKEN_TTL = 24 * 60 * 60;
+
+export type TokenInfo = {
+  token:     string;
+  scope:     TokenScope;
+  /** When the token stops being accepted, as an ISO 8601 timestamp. */
+  expiresAt: string;
+};
+
+type TokenEntry = {
+  scope:   TokenScope;
+  expires: number;
+};
+
+/**
+ * TokenStore keeps track of the short-lived bearer tokens handed out by the
+ * CLI server.  Tokens only live in memory, so restarting the application
+ * revokes all of them.
+ */
+export class TokenStore {
+  protected tokens = new Map<string, TokenEntry>();
+
+  /**
+   * Create a new token.
+   * @param scope The access granted by the token.
+   * @param ttl The lifetime of the token, in seconds.
+   */
+  create(scope: TokenScope, ttl: number): TokenInfo {
+    const token = crypto.randomBytes(32).toString('base64url');
+    const expires = Date.now() + ttl * 1000;
+
+    this.prune();
+    this.tokens.set(token, { scope, expires });
+
+    return {
+      token, scope, expiresAt: new Date(expires).toISOString(),
+    };
+  }
+
+  /**
+   * Look up a token.
+   * @returns The scope of the token, or undefined if it is unknown or expired.
+   */
+  verify(token: string): TokenScope | undefined {
+    const entry = this.tokens.get(token);
+
+    if (!entry) {
+      return undefined;
+    }
+    if (entry.expires <= Date.now()) {
+      this.tokens.delete(token);
+
+      return undefined;
+    }
+
+    return entry.scope;
+  }
+
+  /** Forget all expired tokens. */
+  protected prune() {
+    const now = Date.now();
+
+    for (const [token, { expires }] of this.tokens) {
+      if (expires <= now) {
+        this.tokens.delete(token);
+      }
+    }
+  }
+}
@@ -34,8 +34,9 @@ describe('runCommand', () => {
       expect(command).toEqual('docker-credential-pikachu');
       expect(args).toEqual(['pika']);
       expect(options).toMatchObject({
-        env:   { PATH: expect.stringContaining(resourcesPath) },
-        stdio: [expect.anything(), 'pipe', expect.anything()],
+        env:     { PATH: expect.stringContaining(resourcesPath) },
+        stdio:   [expect.anything(), 'pipe', expect.anything()],
+        timeout: expect.any(Number),
       });
 
       return Promise.resolve({ stdout: expected }) as any;
@@ -60,6 +61,13 @@ describe('runCommand', () => {
     expect(jest.mocked(spawnFile)).not.toHaveBeenCalled();
   });
 
+  it('reports the helper that failed', async() => {
+    jest.spyOn(fs.promises, 'readFile').mockResolvedValue(JSON.stringify({ credsStore: 'pikachu' }));
+    jest.mocked(spawnFile).mockRejectedValue(new Error('helper failed'));
+
+    await expect(runCommand('get', 'host.test')).rejects.toMatchObject({ helper: 'pikachu' });
+  });
+
   // Check managing credentials, for the case where there's a per-host override
   // in the `credHelpers` key, as well as the case where there is no such
   // override.
@@ -110,7 +118,7 @@ describe('runCommand', () => {
 });
 
 describe('list', () => {
-  let config: { credsStore: string, credHelpers?: Record<string, string>} = { credsStore: 'unset' };
+  let config: { credsStore?: string, credHelpers?: Record<string, string>} = { credsStore: 'unset' };
   let helpers: Record<string, any> = {};
 
   beforeEach(() => {
@@ -149,6 +157,12 @@ describe('list', () => {
     });
   });
 
+  it('works without a default helper', async() => {
+    config = { credHelpers: { 'example.test': 'bulbasaur' } };
+    helpers = { bulbasaur: { 'example.test': 'moar stuff', 'host.test': 'ignored' } };
+    await expect(list()).resolves.toEqual({ 'example.test': 'moar stuff' });
+  });
+
   it('only returns matching results', async() => {
     config = { credsStore: 'pikachu', credHelpers: { 'example.test': 'bulbasaur' } };
     helpers = {
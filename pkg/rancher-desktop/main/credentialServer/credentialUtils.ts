@@ -17,6 +17,14 @@ type credHelperInfo = {
 
 const console = Logging.server;
 
+/**
+ * How long a credential helper may run before it is killed, so that requests
+ * from the guest don't hang forever.  This is long enough for the helper to
+ * wait for the user to unlock the keychain; the guest side of the credential
+ * forwarder waits a little longer than this.
+ */
+const HELPER_TIMEOUT = 150_000;
+
 /**
  * Run the credential helper with the given command.
  * @param command The one-word command to run.
@@ -31,7 +39,7 @@ export default async function runCommand(command: string, input?: string): Promi
   const { credsStore } = await getCredentialHelperInfo(command, input ?? '');
 
   try {
-    return runCredHelper(credsStore, command, input);
+    return await runCredHelper(credsStore, command, input);
   } catch (ex: any) {
     ex.helper = credsStore;
     throw ex;
@@ -50,7 +58,8 @@ export async function list(): Promise<Record<string, string>> {
   // Return the creds list from the default helper, plus any data from
   // additional credential helpers as listed in the `credHelpers` section.
   const { credsStore, credHelpers } = await getCredentialHelperInfo('list', '');
-  const results = JSON.parse(await runCredHelper(credsStore, 'list'));
+  // Without a default helper, only the additional helpers have credentials.
+  const results = credsStore ? JSON.parse(await runCredHelper(credsStore, 'list')) : {};
   const helperNames = new Set(Object.values(credHelpers ?? {}));
 
   for (const helperName of helperNames) {
@@ -122,8 +131,11 @@ async function runCredHelper(helper: string, command: string, input?: string): P
   const helperName = `docker-credential-${ helper }`;
   const body = stream.Readable.from(input ?? '');
   const { stdout } = await spawnFile(helperName, [command], {
-    env:   { ...process.env, PATH: pathVar.join(path.delimiter) },
-    stdio: [body, 'pipe', console],
+    env:        { ...process.env, PATH: pathVar.join(path.delimiter) },
+    stdio:      [body, 'pipe', console],
+    timeout:    HELPER_TIMEOUT,
+    // spawnFile treats SIGTERM as a normal exit.
+    killSignal: 'SIGKILL',
   });
 
   return stdout;
@@ -7,6 +7,7 @@ import runCredentialHelper from './credentialUtils';
 
 import { getVtunnelInstance } from '@pkg/main/networking/vtunnel';
 import * as serverHelper from '@pkg/main/serverHelper';
+import { instancePort } from '@pkg/utils/instance';
 import Logging from '@pkg/utils/logging';
 import paths from '@pkg/utils/paths';
 import { jsonStringifyWithWhiteSpace } from '@pkg/utils/stringify';
@@ -18,7 +19,9 @@ export type ServerState = {
   pid: number;
 };
 
-const SERVER_PORT = 6109;
+export const SERVER_PORT = instancePort(6109);
+/** The port the credential server is reachable on from within the VM. */
+export const PEER_PORT = instancePort(3030);
 const console = Logging.server;
 const SERVER_USERNAME = 'user';
 const SERVER_FILE_BASENAME = 'credential-server.json';
@@ -70,10 +73,10 @@ export class HttpCredentialHelperServer {
     if (process.platform === 'win32') {
       this.vtun.addTunnel({
         name:                  'Credential Server',
-        handshakePort:         17362,
-        vsockHostPort:         17361,
+        handshakePort:         instancePort(17362),
+        vsockHostPort:         instancePort(17361),
         peerAddress:           this.listenAddr,
-        peerPort:              3030,
+        peerPort:              PEER_PORT,
         upstreamServerAddress: `${ this.listenAddr }:${ SERVER_PORT }`,
       });
     }
@@ -1,7 +1,7 @@
 import dayjs from 'dayjs';
 import relativeTime from 'dayjs/plugin/relativeTime';
 
-import { DiagnosticsManager, DiagnosticsResult } from '../diagnostics';
+import { DiagnosticsManager, DiagnosticsNotRepairableError, DiagnosticsResult } from '../diagnostics';
 import { DiagnosticsCategory, DiagnosticsChecker } from '../types';
 
 describe(DiagnosticsManager, () => {
@@ -69,6 +69,7 @@ describe(DiagnosticsManager, () => {
           description:   'The ~/.rd/bin directory has not been added to the PATH, so command-line utilities are not configured in your bash shell.',
           passed:        true,
           mute:          false,
+          repairable:    false,
           fixes:         [
           // { description: 'You have selected manual PATH configuration. You can let Rancher Desktop automatically configure it.' },
           ],
@@ -80,6 +81,7 @@ describe(DiagnosticsManager, () => {
           description:   'Are the files under ~/.docker/cli-plugins symlinks to ~/.rd/bin?',
           passed:        false,
           mute:          false,
+          repairable:    false,
           fixes:         [
           // { description: 'Replace existing files in ~/.rd/bin with symlinks to the application\'s internal utility directory' },
           ],
@@ -91,6 +93,7 @@ describe(DiagnosticsManager, () => {
           description:   'The application cannot reach the general internet for updated kubernetes versions and other components, but can still operate.',
           passed:        false,
           mute:          false,
+          repairable:    false,
           fixes:         [],
         },
       ]),
@@ -126,6 +129,42 @@ describe(DiagnosticsManager, () => {
     });
     await internetCheck.not.toMatchObject({ checks: { 0: { fixes: { description: expect.any(String) } } } });
   });
+
+  describe('repair', () => {
+    let broken = true;
+    const repairable: DiagnosticsChecker = {
+      id:       'REPAIRABLE',
+      category: DiagnosticsCategory.Testing,
+      applicable() {
+        return Promise.resolve(true);
+      },
+      check: () => Promise.resolve({
+        description: 'Something that can be repaired',
+        passed:      !broken,
+        fixes:       [],
+      }),
+      repair() {
+        broken = false;
+
+        return Promise.resolve();
+      },
+    };
+    const manager = new DiagnosticsManager([...mockDiagnostics, repairable]);
+
+    test('it repairs and checks again', async() => {
+      await manager.runChecks();
+      await expect(manager.getChecks(null, 'REPAIRABLE')).resolves.toMatchObject({ checks: [{ passed: false, repairable: true }] });
+      await expect(manager.repair('REPAIRABLE')).resolves.toMatchObject({ id: 'REPAIRABLE', passed: true, repairable: true });
+    });
+
+    test('it rejects checks that are not repairable', async() => {
+      await expect(manager.repair('RD_BIN_SYMLINKS')).rejects.toBeInstanceOf(DiagnosticsNotRepairableError);
+    });
+
+    test('it ignores unknown checks', async() => {
+      await expect(manager.repair('gallop the friendly purple')).resolves.toBeUndefined();
+    });
+  });
 });
 
 dayjs.extend(relativeTime);
@@ -16,8 +16,17 @@ export type DiagnosticsResult = DiagnosticsCheckerResult & {
   /** Whether to avoid notifying the user about failures for this check. */
   mute: boolean,
   category: DiagnosticsCategory,
+  /** Whether the checker can attempt to fix failures itself. */
+  repairable: boolean,
 };
 
+/**
+ * DiagnosticsNotRepairableError is thrown when trying to repair a check that
+ * does not support it.
+ */
+export class DiagnosticsNotRepairableError extends Error {
+}
+
 /**
  * DiagnosticsResultCollection is the data structure that will be returned to
  * clients over the HTTP API.
@@ -51,8 +60,13 @@ export class DiagnosticsManager {
         import('./connectedToInternet'),
         import('./dockerCliSymlinks'),
         import('./rdBinInShell'),
+        import('./staleLocalData'),
         import('./kubeContext'),
         import('./wslFromStore'),
+        import('./wslPreflight'),
+        import('./wslDNS'),
+        import('./wslGPU'),
+        import('./wslIntegration'),
         import('./mockForScreenshots'),
         import('./limaDarwin'),
       ])).map(obj => obj.default);
@@ -126,9 +140,10 @@ export class DiagnosticsManager {
       checks:      checkers
         .map(checker => ({
           ...this.results[checker.id],
-          id:       checker.id,
-          category: checker.category,
-          mute:     false,
+          id:         checker.id,
+          category:   checker.category,
+          mute:       false,
+          repairable: !!checker.repair,
         })),
     };
   }
@@ -146,6 +161,29 @@ export class DiagnosticsManager {
     }
   }
 
+  /**
+   * Attempt to repair the problem found by the given checker, then check
+   * again.
+   * @returns The new result, or undefined if the checker is unknown or not
+   * applicable.
+   * @throws DiagnosticsNotRepairableError if the checker can't repair.
+   */
+  async repair(id: string): Promise<DiagnosticsResult | undefined> {
+    const [checker] = await this.applicableCheckers(null, id);
+
+    if (!checker) {
+      return undefined;
+    }
+    if (!checker.repair) {
+      throw new DiagnosticsNotRepairableError(`Diagnostic check ${ id } can't be repaired automatically`);
+    }
+    console.debug(`Repairing ${ checker.id }`);
+    await checker.repair();
+    await this.runChecker(checker);
+
+    return (await this.getChecks(null, checker.id)).checks[0];
+  }
+
   /**
    * Run all checks, and return the results.
    */
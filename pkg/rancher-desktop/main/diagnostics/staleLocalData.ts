@@ -0,0 +1,32 @@
+import fs from 'fs';
+import path from 'path';
+
+import { DiagnosticsCategory, DiagnosticsChecker } from './types';
+
+import paths from '@pkg/utils/paths';
+
+/**
+ * CheckStaleLocalData points out the VM disks and cache files left in the
+ * home directory after they moved to a local disk (because the home directory
+ * is on a network file system); `rdctl paths` lists them as `staleData`.
+ */
+const CheckStaleLocalData: DiagnosticsChecker = {
+  id:       'STALE_LOCAL_DATA',
+  category: DiagnosticsCategory.Utilities,
+  applicable() {
+    return Promise.resolve(paths.staleData.length > 0);
+  },
+  check() {
+    const staleData = paths.staleData.filter(dir => fs.existsSync(dir));
+    const localDataDir = path.dirname(paths.cache);
+
+    return Promise.resolve({
+      description: 'The home directory is on a network file system, so the VM disks and cache are kept in ' +
+        `\`${ localDataDir }\` instead. These directories are no longer used: ${ staleData.map(dir => `\`${ dir }\``).join(', ') }.`,
+      passed: staleData.length === 0,
+      fixes:  [{ description: `Move their contents to \`${ localDataDir }\` while Rancher Desktop is shut down to keep them, or remove them to free up space.` }],
+    });
+  },
+};
+
+export default CheckStaleLocalData;
@@ -39,4 +39,9 @@ export interface DiagnosticsChecker {
    * Perform the check.
    */
   check(): Promise<DiagnosticsCheckerResult>;
+  /**
+   * Attempt to fix the problem found by the check, for checkers that can do
+   * so without user interaction.  The check is run again afterwards.
+   */
+  repair?(): Promise<void>;
 }
@@ -0,0 +1,56 @@
+import { DiagnosticsCategory, DiagnosticsChecker } from './types';
+
+import mainEvents from '@pkg/main/mainEvents';
+
+/**
+ * Check that names can be resolved in the integrated WSL distributions; VPN
+ * clients on the host commonly leave them with name servers that can't be
+ * reached.
+ */
+class CheckWSLDNS implements DiagnosticsChecker {
+  readonly id = 'WSL_INTEGRATION_DNS';
+
+  category = DiagnosticsCategory.Networking;
+  applicable(): Promise<boolean> {
+    return Promise.resolve(process.platform === 'win32');
+  }
+
+  async check() {
+    const states = await mainEvents.invoke('integration-dns', false);
+    const broken = Object.entries(states).filter(([, state]) => !state.working);
+
+    if (broken.length === 0) {
+      return {
+        passed:      true,
+        description: 'Name resolution works in the integrated WSL distributions.',
+        fixes:       [],
+      };
+    }
+
+    const details = broken.map(([distro, state]) => {
+      const servers = state.nameservers.map(server => `\`${ server.address }\``).join(', ');
+
+      return `- \`${ distro }\`: ${ state.error ?? (servers ? `no response from ${ servers }` : 'no name servers configured') }`;
+    });
+
+    return {
+      passed:      false,
+      description: `Names can't be resolved in some integrated WSL distributions:\n${ details.join('\n') }`,
+      fixes:       [
+        { description: 'Run `rdctl doctor --repair` to point `/etc/resolv.conf` in those distributions at name servers that work.' },
+        { description: 'If a VPN client is running, check whether it supports split tunneling for WSL.' },
+      ],
+    };
+  }
+
+  async repair() {
+    const states = await mainEvents.invoke('integration-dns', true);
+    const failures = Object.entries(states).filter(([, state]) => state.error);
+
+    if (failures.length > 0) {
+      throw new Error(failures.map(([distro, state]) => `${ distro }: ${ state.error }`).join('; '));
+    }
+  }
+}
+
+export default new CheckWSLDNS();
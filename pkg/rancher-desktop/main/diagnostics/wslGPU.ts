@@ -0,0 +1,62 @@
+import { DiagnosticsCategory, DiagnosticsChecker } from './types';
+
+import type { WSLGPUState } from '@pkg/backend/wsl';
+import mainEvents from '@pkg/main/mainEvents';
+import Logging from '@pkg/utils/logging';
+
+const console = Logging.diagnostics;
+
+let gpuEnabled = false;
+let gpuState: WSLGPUState | undefined;
+
+mainEvents.on('settings-update', (cfg) => {
+  gpuEnabled = cfg.virtualMachine.gpu;
+});
+
+mainEvents.on('wsl-gpu-state', (state) => {
+  gpuState = state;
+  mainEvents.invoke('diagnostics-trigger', CheckWSLGPU.id).catch((ex) => {
+    console.error(`Failed to trigger ${ CheckWSLGPU.id }:`, ex);
+  });
+});
+
+/**
+ * CheckWSLGPU reports whether the GPU could be exposed to containers when
+ * GPU support is enabled.
+ */
+const CheckWSLGPU: DiagnosticsChecker = {
+  id:       'WSL_GPU',
+  category: DiagnosticsCategory.ContainerEngine,
+  applicable() {
+    return Promise.resolve(process.platform === 'win32' && gpuEnabled);
+  },
+  check() {
+    if (!gpuState?.enabled) {
+      return Promise.resolve({
+        passed:      true,
+        description: 'GPU support will be checked when the virtual machine next starts.',
+        fixes:       [],
+      });
+    }
+    if (gpuState.available) {
+      const apis = [gpuState.directML && 'DirectML', gpuState.cuda && 'CUDA'].filter(Boolean).join(' and ');
+
+      return Promise.resolve({
+        passed:      true,
+        description: `The GPU is available to containers (${ apis }); request it with \`--device rancherdesktop.io/gpu=all\`.`,
+        fixes:       [],
+      });
+    }
+
+    return Promise.resolve({
+      passed:      false,
+      description: `The GPU is not available to containers: ${ gpuState.reason ?? 'unknown error' }`,
+      fixes:       [
+        { description: 'Update WSL by running `wsl --update`, then restart Rancher Desktop.' },
+        { description: 'Install the latest GPU driver from the vendor; it must support WSL.' },
+      ],
+    });
+  },
+};
+
+export default CheckWSLGPU;
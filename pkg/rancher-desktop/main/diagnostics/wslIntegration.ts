@@ -0,0 +1,61 @@
+import { DiagnosticsCategory, DiagnosticsChecker } from './types';
+
+import mainEvents from '@pkg/main/mainEvents';
+
+/**
+ * Check the integration with the integrated WSL distributions: links to the
+ * docker CLI plugins, WSL interop settings, and access to the docker socket.
+ */
+class CheckWSLIntegration implements DiagnosticsChecker {
+  readonly id = 'WSL_INTEGRATION';
+
+  category = DiagnosticsCategory.Utilities;
+  applicable(): Promise<boolean> {
+    return Promise.resolve(process.platform === 'win32');
+  }
+
+  async check() {
+    const states = await mainEvents.invoke('integration-doctor', false);
+    const broken = Object.entries(states).filter(([, state]) => state.error || state.problems.length > 0);
+
+    if (broken.length === 0) {
+      return {
+        passed:      true,
+        description: 'The integrated WSL distributions are set up correctly.',
+        fixes:       [],
+      };
+    }
+
+    const details = broken.flatMap(([distro, state]) => {
+      if (state.error) {
+        return [`- \`${ distro }\`: could not be checked: ${ state.error }`];
+      }
+
+      return state.problems.map(problem => `- \`${ distro }\`: ${ problem.description }`);
+    });
+    const fixes = broken
+      .flatMap(([, state]) => state.problems)
+      .map(problem => problem.fix)
+      .filter((fix): fix is string => !!fix);
+
+    return {
+      passed:      false,
+      description: `Some integrated WSL distributions have problems:\n${ details.join('\n') }`,
+      fixes:       [
+        { description: 'Run `rdctl doctor --repair` to set up the integration in those distributions again.' },
+        ...Array.from(new Set(fixes)).map(description => ({ description })),
+      ],
+    };
+  }
+
+  async repair() {
+    const states = await mainEvents.invoke('integration-doctor', true);
+    const failures = Object.entries(states).filter(([, state]) => state.error);
+
+    if (failures.length > 0) {
+      throw new Error(failures.map(([distro, state]) => `${ distro }: ${ state.error }`).join('; '));
+    }
+  }
+}
+
+export default new CheckWSLIntegration();
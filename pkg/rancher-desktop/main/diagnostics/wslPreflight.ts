@@ -0,0 +1,42 @@
+import { DiagnosticsCategory, DiagnosticsChecker } from './types';
+
+import { getWSLPreflight } from '@pkg/utils/wslVersion';
+
+/**
+ * Check that the installed WSL can run Rancher Desktop: the Windows features
+ * it needs are enabled, and the WSL release and kernel are not known to be
+ * broken.
+ */
+class CheckWSLPreflight implements DiagnosticsChecker {
+  readonly id = 'WSL_PREFLIGHT';
+
+  category = DiagnosticsCategory.ContainerEngine;
+  applicable(): Promise<boolean> {
+    return Promise.resolve(process.platform === 'win32');
+  }
+
+  async check() {
+    const report = await getWSLPreflight();
+
+    if (report.problems.length === 0) {
+      return {
+        passed:      true,
+        description: 'WSL meets the requirements for Rancher Desktop.',
+        fixes:       [],
+      };
+    }
+
+    const details = report.problems.map(problem => `- ${ problem.description }`);
+    const fixes = report.problems
+      .map(problem => problem.fix)
+      .filter((fix): fix is string => !!fix);
+
+    return {
+      passed:      false,
+      description: `WSL has problems that may affect Rancher Desktop:\n${ details.join('\n') }`,
+      fixes:       Array.from(new Set(fixes)).map(description => ({ description })),
+    };
+  }
+}
+
+export default new CheckWSLPreflight();
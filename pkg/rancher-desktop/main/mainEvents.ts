@@ -6,8 +6,10 @@
 import { EventEmitter } from 'events';
 
 import type { VMBackend } from '@pkg/backend/backend';
+import type { WSLGPUState } from '@pkg/backend/wsl';
 import type { Settings } from '@pkg/config/settings';
 import type { TransientSettings } from '@pkg/config/transientSettings';
+import type { DistroDNSState, DistroIntegrationState } from '@pkg/integrations/windowsIntegrationManager';
 import { DiagnosticsCheckerResult } from '@pkg/main/diagnostics/types';
 import { RecursivePartial, RecursiveReadonly } from '@pkg/utils/typeUtils';
 
@@ -100,6 +102,26 @@ interface MainEventNames {
    */
   'diagnostics-trigger'(id: string): DiagnosticsCheckerResult | undefined;
 
+  /**
+   * Check name resolution in the integrated WSL distributions.
+   * @param repair Whether to pin the name servers of the distributions where
+   * it is broken to ones that work.
+   * @returns The state of each integrated distribution, by name.
+   */
+  'integration-dns'(repair: boolean): Record<string, DistroDNSState>;
+
+  /**
+   * Check the integration of the integrated WSL distributions.
+   * @param repair Whether to repair the distributions with problems.
+   * @returns The state of each integrated distribution, by name.
+   */
+  'integration-doctor'(repair: boolean): Record<string, DistroIntegrationState>;
+
+  /**
+   * Emitted when the WSL backend has checked for GPU support on start.
+   */
+  'wsl-gpu-state'(state: WSLGPUState): void;
+
   /**
    * Emitted when an extension is uninstalled via the extension manager.
    * @param id The ID of the extension that was uninstalled.
@@ -12,6 +12,7 @@ import getMacCertificates from './mac-ca';
 import ElectronProxyAgent from './proxy';
 import getWinCertificates from './win-ca';
 
+import { isServerCertificate } from '@pkg/main/commandServer/certificate';
 import mainEvents from '@pkg/main/mainEvents';
 import Logging from '@pkg/utils/logging';
 import { windowMapping } from '@pkg/window';
@@ -71,6 +72,14 @@ export default async function setupNetworking() {
       return;
     }
 
+    if (isServerCertificate(url, certificate.data)) {
+      event.preventDefault();
+      // eslint-disable-next-line node/no-callback-literal
+      callback(true);
+
+      return;
+    }
+
     if (dashboardUrls.some(x => url.startsWith(x)) && 'dashboard' in windowMapping) {
       event.preventDefault();
       // eslint-disable-next-line node/no-callback-literal
@@ -10,6 +10,7 @@ import * as childProcess from '@pkg/utils/childProcess';
 import Logging from '@pkg/utils/logging';
 import paths from '@pkg/utils/paths';
 
+const console = Logging.networking;
 const vtunnelConfig = 'vtunnel-config.yaml';
 
 /**
@@ -31,17 +32,26 @@ let instance: VTunnel | undefined;
  */
 class VTunnel {
   private _vtunnelConfig: VtunnelConfig[] = [];
+  private hostProcess: childProcess.ChildProcess | undefined;
+  private reloadPeer: () => Promise<void> = () => Promise.resolve();
   private vsockProxy = new BackgroundProcess('Vtunnel Host Process', {
     spawn: async() => {
       const executable = path.join(paths.resources, 'win32', 'internal', 'vtunnel.exe');
       const stream = await Logging['vtunnel-host'].fdStream;
 
-      return childProcess.spawn(executable,
+      // Standard input is used to ask the host process to reload its
+      // configuration; see reload().
+      this.hostProcess = childProcess.spawn(executable,
         ['host',
           '--config-path', getVtunnelConfigPath()], {
-          stdio:       ['ignore', stream, stream],
+          stdio:       ['pipe', stream, stream],
           windowsHide: true,
         });
+      this.hostProcess.stdin?.on('error', (error) => {
+        console.debug(`Failed to write to vtunnel host process: ${ error }`);
+      });
+
+      return this.hostProcess;
     },
   });
 
@@ -69,6 +79,47 @@ class VTunnel {
     this._vtunnelConfig.push(config);
   }
 
+  /**
+   * removeTunnel removes the configuration with the given name.
+   */
+  removeTunnel(name: string) {
+    this._vtunnelConfig = this._vtunnelConfig.filter(c => c.name !== name);
+  }
+
+  /**
+   * setPeerReloader sets the function used to ask the peer process, running
+   * in the VM, to reload its configuration.
+   */
+  setPeerReloader(reloadPeer: () => Promise<void>) {
+    this.reloadPeer = reloadPeer;
+  }
+
+  /**
+   * reload rewrites the configuration after tunnels have been added or
+   * removed, and has the running peer and host processes pick it up without
+   * restarting.  The peer is reloaded first, as the host needs to handshake
+   * with it for any new tunnel.
+   */
+  async reload() {
+    try {
+      await this.generateConfig();
+    } catch (error) {
+      console.error(`Failed to generate vtunnel configuration: ${ error }`);
+
+      return;
+    }
+    try {
+      await this.reloadPeer();
+    } catch (error) {
+      console.error(`Failed to reload vtunnel peer process: ${ error }`);
+    }
+    const stdin = this.hostProcess?.stdin;
+
+    if (this.hostProcess?.exitCode === null && stdin?.writable) {
+      stdin.write('reload\n');
+    }
+  }
+
   /**
    * start generates the final configuration yaml file and starts the
    * Vtunnel Host process.
@@ -89,6 +140,7 @@ class VTunnel {
   */
   async stop() {
     await this.vsockProxy.stop();
+    this.hostProcess = undefined;
   }
 }
 
@@ -33,11 +33,11 @@ class SnapshotsError {
 }
 
 class SnapshotsImpl {
-  private async rdctl(commandArgs: string[]): Promise<SpawnResult> {
+  private async rdctl(commandArgs: string[], signal?: AbortSignal): Promise<SpawnResult> {
     try {
       const rdctlPath = getRdctlPath();
 
-      return await spawnFile(rdctlPath || '', commandArgs, { stdio: ['ignore', 'pipe', 'pipe'] });
+      return await spawnFile(rdctlPath || '', commandArgs, { stdio: ['ignore', 'pipe', 'pipe'], signal });
     } catch (err: any) {
       return {
         stdout: err?.stdout ?? '', stderr: err?.stderr ?? '', error: err,
@@ -57,7 +57,13 @@ class SnapshotsImpl {
     return data.map(line => JSON.parse(line));
   }
 
-  async create(snapshot: Snapshot) : Promise<void> {
+  /**
+   * Create a snapshot.
+   * @param signal Aborting this interrupts `rdctl`, which then removes the
+   *        partial snapshot.  This is ignored on Windows, where the process
+   *        would be terminated without a chance to clean up.
+   */
+  async create(snapshot: Snapshot, signal?: AbortSignal) : Promise<void> {
     const args = [
       'snapshot',
       'create',
@@ -69,7 +75,7 @@ class SnapshotsImpl {
       args.push('--description', snapshot.description);
     }
 
-    const response = await this.rdctl(args);
+    const response = await this.rdctl(args, process.platform === 'win32' ? undefined : signal);
 
     if (response.error) {
       throw new SnapshotsError(args, response);
@@ -77,7 +83,7 @@ class SnapshotsImpl {
   }
 
   async restore(name: string) : Promise<void> {
-    const args = ['snapshot', 'restore', name, '--json'];
+    const args = ['snapshot', 'restore', name, '--json', '--yes'];
     const response = await this.rdctl(args);
 
     if (response.error) {
@@ -86,7 +92,7 @@ class SnapshotsImpl {
   }
 
   async delete(name: string) : Promise<void> {
-    const args = ['snapshot', 'delete', name, '--json'];
+    const args = ['snapshot', 'delete', name, '--json', '--yes'];
     const response = await this.rdctl(args);
 
     if (response.error) {
@@ -17,7 +17,7 @@ export async function fetchAPI(api: string, rootState: any, init?: RequestInit)
   await hasCredentials;
 
   const { port, user, password } = rootState.credentials.credentials as Credentials;
-  const url = new URL(api, `http://localhost:${ port }/`);
+  const url = new URL(api, `https://localhost:${ port }/`);
   const headers = new Headers(init?.headers);
 
   headers.set('Authorization', `Basic ${ window.btoa(`${ user }:${ password }`) }`);
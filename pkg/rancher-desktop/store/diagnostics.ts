@@ -18,7 +18,7 @@ interface DiagnosticsState {
 
 type Credentials = Omit<ServerState, 'pid'>;
 
-const uri = (port: number, pathRemainder: string) => `http://localhost:${ port }/v1/${ pathRemainder }`;
+const uri = (port: number, pathRemainder: string) => `https://localhost:${ port }/v1/${ pathRemainder }`;
 
 /**
  * Updates the muted property for diagnostic results.
@@ -33,7 +33,7 @@ interface CommitArgs extends Credentials {
   payload?: RecursivePartial<Settings>;
 }
 
-const uri = (port: number, path: string) => `http://localhost:${ port }/v1/${ path }`;
+const uri = (port: number, path: string) => `https://localhost:${ port }/v1/${ path }`;
 
 const proposedSettings = (port: number) => uri(port, 'propose_settings');
 
@@ -3,6 +3,7 @@ import { GetterTree } from 'vuex';
 import { fetchAPI } from './credentials';
 import { ActionContext, MutationsType } from './ts-helpers';
 
+import type { JobInfo, JobKind } from '@pkg/main/commandServer/jobs';
 import { Snapshot } from '@pkg/main/snapshots/types';
 
 interface SnapshotsState {
@@ -19,6 +20,35 @@ export const mutations: MutationsType<SnapshotsState> = {
 
 type SnapshotsActionContext = ActionContext<SnapshotsState>;
 
+/** How often to check on a running snapshot job, in milliseconds. */
+const JOB_POLL_INTERVAL = 500;
+
+/**
+ * Run a snapshot operation as an API job and wait for it to finish, rather
+ * than holding a request open for as long as the operation takes.
+ * @returns The error message if the job could not be started or failed.
+ */
+async function runJob(rootState: any, request: { kind: JobKind, name: string, description?: string }): Promise<string | undefined> {
+  const response = await fetchAPI('/v1/jobs', rootState, { method: 'POST', body: JSON.stringify(request) });
+
+  if (!response.ok) {
+    return await response.text();
+  }
+  let job: JobInfo = await response.json();
+
+  while (job.state === 'running') {
+    await new Promise(resolve => setTimeout(resolve, JOB_POLL_INTERVAL));
+    const jobResponse = await fetchAPI(`/v1/job?id=${ encodeURIComponent(job.id) }`, rootState);
+
+    if (!jobResponse.ok) {
+      return await jobResponse.text();
+    }
+    job = await jobResponse.json();
+  }
+
+  return job.state === 'succeeded' ? undefined : job.error;
+}
+
 export const actions = {
   async fetch({ commit, rootState }: SnapshotsActionContext) {
     const response = await fetchAPI('/v1/snapshots', rootState);
@@ -36,14 +66,10 @@ export const actions = {
   },
 
   async create({ rootState, dispatch }: SnapshotsActionContext, snapshot: Snapshot) {
-    const body = JSON.stringify(snapshot ?? {});
-
-    const response = await fetchAPI('/v1/snapshots', rootState, { method: 'POST', body });
+    const error = await runJob(rootState, { ...snapshot, kind: 'snapshot-create' });
 
-    if (!response.ok) {
-      console.log(`createSnapshot: failed: status: ${ response.status }:${ response.statusText }`);
-
-      const error = await response.text();
+    if (error) {
+      console.log(`createSnapshot: failed: ${ error }`);
 
       return error;
     }
@@ -52,12 +78,10 @@ export const actions = {
   },
 
   async delete({ rootState, dispatch }: SnapshotsActionContext, name: string) {
-    const response = await fetchAPI(`/v1/snapshots?name=${ encodeURIComponent(name) }`, rootState, { method: 'DELETE' });
+    const error = await runJob(rootState, { kind: 'snapshot-delete', name });
 
-    if (!response.ok) {
-      console.log(`deleteSnapshot: failed: status: ${ response.status }:${ response.statusText }`);
-
-      const error = await response.text();
+    if (error) {
+      console.log(`deleteSnapshot: failed: ${ error }`);
 
       return error;
     }
@@ -66,12 +90,10 @@ export const actions = {
   },
 
   async restore({ rootState }: SnapshotsActionContext, name: string) {
-    const response = await fetchAPI(`/v1/snapshot/restore?name=${ encodeURIComponent(name) }`, rootState, { method: 'POST' });
-
-    if (!response.ok) {
-      console.log(`restoreSnapshot: failed: status: ${ response.status }:${ response.statusText }`);
+    const error = await runJob(rootState, { kind: 'snapshot-restore', name });
 
-      const error = await response.text();
+    if (error) {
+      console.log(`restoreSnapshot: failed: ${ error }`);
 
       return error;
     }
@@ -20,7 +20,7 @@ type ExtendedTransientSettings = TransientSettings & {
   isArm?: boolean;
 };
 
-const uri = (port: number) => `http://localhost:${ port }/v1/transient_settings`;
+const uri = (port: number) => `https://localhost:${ port }/v1/transient_settings`;
 
 export const state: () => ExtendedTransientSettings = () => _.cloneDeep(defaultTransientSettings);
 
@@ -321,6 +321,39 @@ describe('DockerDirManager', () => {
     });
   });
 
+  describe('setNoneCredHelperEncryption', () => {
+    /** Path to the docker-credential-none config file (in workdir). */
+    let configPath: string;
+
+    beforeEach(() => {
+      configPath = path.join(workdir, '.docker', 'plaintext-credentials.config.json');
+    });
+
+    it('should enable encryption and keep existing credentials', async() => {
+      const auths = { 'registry.example.com': { auth: 'dXNlcjpwYXNz' } };
+
+      await fs.promises.mkdir(path.dirname(configPath), { recursive: true });
+      await fs.promises.writeFile(configPath, JSON.stringify({ auths }));
+      await subj.setNoneCredHelperEncryption(true);
+      const newConfig = JSON.parse(await fs.promises.readFile(configPath, 'utf-8'));
+
+      expect(newConfig).toEqual({ auths, encryption: 'keychain' });
+    });
+
+    it('should disable encryption', async() => {
+      await subj.setNoneCredHelperEncryption(true);
+      await subj.setNoneCredHelperEncryption(false);
+      const newConfig = JSON.parse(await fs.promises.readFile(configPath, 'utf-8'));
+
+      expect(newConfig).not.toHaveProperty('encryption');
+    });
+
+    it('should not create the config file when disabled', async() => {
+      await subj.setNoneCredHelperEncryption(false);
+      await expect(fs.promises.access(configPath)).rejects.toThrow('ENOENT');
+    });
+  });
+
   describe('clearDockerContext', () => {
     /** Path to the docker config file (in workdir). */
     let configPath: string;
@@ -0,0 +1,41 @@
+import { instancePort, instanceSuffix, wslDistroNames } from '../instance';
+
+describe('instance', () => {
+  describe('default instance', () => {
+    const env = {};
+
+    test('keeps the usual names', () => {
+      expect(instanceSuffix(env)).toEqual('');
+      expect(wslDistroNames(env)).toEqual({ main: 'rancher-desktop', data: 'rancher-desktop-data' });
+    });
+
+    test('keeps the usual ports', () => {
+      expect(instancePort(6107, env)).toEqual(6107);
+      expect(instancePort(6109, env)).toEqual(6109);
+    });
+  });
+
+  describe('named instance', () => {
+    const env = { RD_INSTANCE: 'lab' };
+
+    test('gets its own distributions', () => {
+      expect(instanceSuffix(env)).toEqual('-lab');
+      expect(wslDistroNames(env)).toEqual({ main: 'rancher-desktop-lab', data: 'rancher-desktop-data-lab' });
+    });
+
+    test('gets its own ports', () => {
+      const port = instancePort(6107, env);
+
+      expect(port).not.toEqual(6107);
+      expect(port).toBeLessThan(65536);
+      // Ports are derived from the name, so they are the same every time.
+      expect(instancePort(6107, env)).toEqual(port);
+      // All ports are shifted by the same offset.
+      expect(instancePort(6109, env)).toEqual(port + 2);
+    });
+
+    test('different instances get different ports', () => {
+      expect(instancePort(6107, env)).not.toEqual(instancePort(6107, { RD_INSTANCE: 'dev' }));
+    });
+  });
+});
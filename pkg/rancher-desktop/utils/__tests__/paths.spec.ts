@@ -7,6 +7,8 @@ const RESOURCES_PATH = path.join(process.cwd(), 'resources');
 
 type Platform = 'darwin' | 'linux' | 'win32';
 type expectedData = Record<Platform, string | Error>;
+/** The properties holding a single path. */
+type PathProperty = Exclude<keyof Paths, 'staleData'>;
 
 jest.mock('electron', () => {
   return {
@@ -21,7 +23,7 @@ jest.mock('electron', () => {
 });
 
 describe('paths', () => {
-  const cases: Record<keyof Paths, expectedData> = {
+  const cases: Record<PathProperty, expectedData> = {
     appHome: {
       win32:  '%LOCALAPPDATA%/rancher-desktop/',
       linux:  '%HOME%/.local/share/rancher-desktop/',
@@ -107,7 +109,7 @@ describe('paths', () => {
   });
 
   test.each(table)('.%s (%s)', (prop, _, expected) => {
-    const propName = prop as keyof Paths;
+    const propName = prop as PathProperty;
 
     if (expected instanceof Error) {
       expect(() => paths[propName]).toThrow();
@@ -128,4 +130,9 @@ describe('paths', () => {
       expect(actual).toEqual(cleaned);
     }
   });
+
+  test('.staleData', () => {
+    // The home directory isn't on a network file system, so nothing moved.
+    expect(paths.staleData).toEqual([]);
+  });
 });
@@ -3,9 +3,16 @@ import util from 'util';
 
 import * as childProcess from '@pkg/utils/childProcess';
 import Logging from '@pkg/utils/logging';
+import paths from '@pkg/utils/paths';
 
 const console = Logging.background;
 
+/**
+ * The exit status of a Go helper that crashed after writing a crash report
+ * (logging.CrashExitCode in src/go/logging).
+ */
+const CRASH_EXIT_CODE = 70;
+
 type BackgroundProcessConstructorOptions = {
   /** A function to create the underlying child process. */
   spawn: () => Promise<childProcess.ChildProcess>;
@@ -126,6 +133,8 @@ export default class BackgroundProcess {
     process.on('exit', (status, signal) => {
       if ([0, null].includes(status) && ['SIGTERM', null].includes(signal)) {
         console.log(`Background process ${ this.name } (pid ${ process.pid }) exited gracefully.`);
+      } else if (status === CRASH_EXIT_CODE) {
+        console.error(`Background process ${ this.name } (pid ${ process.pid }) crashed; the crash report is in ${ paths.logs }`);
       } else {
         console.log(`Background process ${ this.name } (pid ${ process.pid }) exited with status ${ status } signal ${ signal }`);
       }
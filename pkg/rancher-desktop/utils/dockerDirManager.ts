@@ -50,6 +50,8 @@ export default class DockerDirManager {
    */
   protected readonly dockerContextPath: string;
   protected readonly dockerConfigPath: string;
+  /** Path to the config file of docker-credential-none, which holds its credentials. */
+  protected readonly noneCredHelperConfigPath: string;
   protected readonly defaultDockerSockPath = '/var/run/docker.sock';
   protected readonly contextName = 'rancher-desktop';
 
@@ -62,6 +64,7 @@ export default class DockerDirManager {
     this.dockerContextPath = path.join(this.dockerContextDirPath,
       'b547d66a5de60e5f0843aba28283a8875c2ad72e99ba076060ef9ec7c09917c8', 'meta.json');
     this.dockerConfigPath = path.join(this.dockerDirPath, 'config.json');
+    this.noneCredHelperConfigPath = path.join(this.dockerDirPath, 'plaintext-credentials.config.json');
     console.debug(`Created new DockerDirManager to manage dir: ${ this.dockerDirPath }`);
   }
 
@@ -374,6 +377,35 @@ export default class DockerDirManager {
     }
   }
 
+  /**
+   * Sets whether docker-credential-none encrypts the credentials it stores.
+   * The helper reads this from its own config file; existing credentials are
+   * left as they are, and can still be read.
+   * @param encrypt Whether new credentials should be encrypted.
+   */
+  async setNoneCredHelperEncryption(encrypt: boolean): Promise<void> {
+    let config: Record<string, any> = {};
+
+    try {
+      config = JSON.parse(await fs.promises.readFile(this.noneCredHelperConfigPath, 'utf-8'));
+    } catch (ex: any) {
+      if (ex.code !== 'ENOENT') {
+        throw new Error(`Failed to parse docker-credential-none config file '${ this.noneCredHelperConfigPath }': ${ ex.message }`);
+      }
+    }
+    if ((config.encryption === 'keychain') === encrypt) {
+      return;
+    }
+    if (encrypt) {
+      config.encryption = 'keychain';
+    } else {
+      delete config.encryption;
+    }
+    await fs.promises.mkdir(this.dockerDirPath, { recursive: true });
+    await fs.promises.writeFile(this.noneCredHelperConfigPath, jsonStringifyWithWhiteSpace(config), { encoding: 'utf-8', mode: 0o600 });
+    console.log(`${ encrypt ? 'Enabled' : 'Disabled' } docker-credential-none encryption`);
+  }
+
   /**
    * Ensures that the docker config file is configured with a valid credential helper.
    */
@@ -0,0 +1,64 @@
+/**
+ * This module describes the Rancher Desktop instance selected by the
+ * RD_INSTANCE environment variable (as set by `rdctl --instance`).  Each named
+ * instance gets its own directories (see `rdctl paths`), and also its own WSL
+ * distributions and local ports, so that several instances can run side by
+ * side; the default instance keeps the usual names and ports.
+ */
+
+/**
+ * The name of the current instance, or an empty string for the default one.
+ */
+export function instanceName(env: NodeJS.ProcessEnv = process.env): string {
+  return env.RD_INSTANCE ?? '';
+}
+
+/**
+ * The suffix added to per-instance names; empty for the default instance.
+ * This must match `paths.InstanceSuffix` in rdctl.
+ */
+export function instanceSuffix(env: NodeJS.ProcessEnv = process.env): string {
+  const name = instanceName(env);
+
+  return name ? `-${ name }` : '';
+}
+
+/**
+ * The names of the WSL distributions of the current instance: the one Rancher
+ * Desktop runs in, and the one holding its data.  These must match
+ * `paths.WSLDistroNames` in rdctl.
+ */
+export function wslDistroNames(env: NodeJS.ProcessEnv = process.env) {
+  const suffix = instanceSuffix(env);
+
+  return { main: `rancher-desktop${ suffix }`, data: `rancher-desktop-data${ suffix }` };
+}
+
+/**
+ * Named instances shift all of their ports by the same offset, a multiple of
+ * ten derived from the name, so that ports that are close together stay
+ * distinct.  Two named instances may (rarely) get the same offset, in which
+ * case the second one fails to listen, as it would have before.
+ */
+const portOffsetStep = 10;
+const portOffsetCount = 999;
+
+/**
+ * Returns the port to use in the current instance in place of the given one.
+ */
+export function instancePort(port: number, env: NodeJS.ProcessEnv = process.env): number {
+  const name = instanceName(env);
+
+  if (!name) {
+    return port;
+  }
+  // FNV-1a, for a stable hash of the name.
+  let hash = 0x811C9DC5;
+
+  for (const char of name) {
+    hash ^= char.charCodeAt(0);
+    hash = Math.imul(hash, 0x01000193) >>> 0;
+  }
+
+  return port + portOffsetStep * (1 + hash % portOffsetCount);
+}
@@ -37,6 +37,11 @@ export interface Paths {
   wslDistroData: string;
   /** Directory that holds snapshots. */
   snapshots: string;
+  /**
+   * Directories in the usual locations that still hold VM disks or cache
+   * files after those moved to a local disk; they are no longer used.
+   */
+  staleData: string[];
 }
 
 export class UnixPaths implements Paths {
@@ -52,6 +57,7 @@ export class UnixPaths implements Paths {
   deploymentProfileUser = '';
   extensionRoot = '';
   snapshots = '';
+  staleData: string[] = [];
 
   constructor(pathsData: Record<string, unknown>) {
     Object.assign(this, pathsData);
@@ -77,6 +83,7 @@ export class WindowsPaths implements Paths {
   wslDistro = '';
   wslDistroData = '';
   snapshots = '';
+  staleData: string[] = [];
 
   constructor(pathsData: Record<string, unknown>) {
     Object.assign(this, pathsData);
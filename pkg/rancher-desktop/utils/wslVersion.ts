@@ -1,6 +1,6 @@
 /**
- * This exports a single function to ask wsl-helper about the current WSL
- * version.
+ * This exports functions to ask wsl-helper about the current WSL version, and
+ * whether it can run Rancher Desktop.
  */
 
 import path from 'path';
@@ -22,6 +22,24 @@ type WSLVersionInfo = {
     };
 };
 
+/**
+ * A problem with the WSL installation, as found by `wsl-helper wsl preflight`.
+ */
+export type WSLPreflightProblem = {
+  id: string;
+  description: string;
+  fix?: string;
+  /** If set, Rancher Desktop can't start because of this problem. */
+  fatal: boolean;
+};
+
+export type WSLPreflightReport = {
+  passed: boolean;
+  problems: WSLPreflightProblem[];
+  wsl: WSLVersionInfo;
+  kernelVersion?: string;
+};
+
 const console = logging['wsl-version'];
 
 /**
@@ -33,3 +51,14 @@ export default async function getWSLVersion(): Promise<WSLVersionInfo> {
 
   return JSON.parse(stdout);
 }
+
+/**
+ * Check that the installed WSL can run Rancher Desktop: the required Windows
+ * features are enabled, and the WSL and kernel versions are usable.
+ */
+export async function getWSLPreflight(): Promise<WSLPreflightReport> {
+  const wslHelper = path.join(paths.resources, 'win32', 'wsl-helper.exe');
+  const { stdout } = await spawnFile(wslHelper, ['wsl', 'preflight'], { stdio: ['ignore', 'pipe', console] });
+
+  return JSON.parse(stdout);
+}
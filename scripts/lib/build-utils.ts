@@ -263,6 +263,41 @@ export default {
     return process.env.M1 ? 'arm64' : process.arch;
   },
 
+  /**
+   * Whether to build the Go components for FIPS 140 compliance.
+   */
+  get fipsBuild(): boolean {
+    return !!process.env.RD_FIPS_BUILD;
+  },
+
+  /**
+   * Get the arguments and environment for `go build`.  FIPS builds link in
+   * BoringCrypto, which needs cgo and is only supported on Linux; the `fips`
+   * build tag makes the programs check that it's in use when they start.  They
+   * are still linked statically, so that they run in any distribution.
+   * @param goos The operating system to build for.
+   * @param env Environment variables for the build.
+   * @param ldflags Additional flags for the linker.
+   */
+  goBuildOptions(goos: string, env: Record<string, string> = {}, ldflags: string[] = []): { args: string[], env: NodeJS.ProcessEnv } {
+    if (!this.fipsBuild) {
+      return { args: ['-ldflags', ['-s', '-w', ...ldflags].join(' ')], env: { ...process.env, ...env } };
+    }
+    if (goos !== 'linux') {
+      throw new Error(`FIPS builds are not supported for ${ goos }`);
+    }
+
+    return {
+      args: ['-tags', 'fips,osusergo,netgo', '-ldflags', ['-s', '-w', ...ldflags, '-linkmode', 'external', '-extldflags', '"-static"'].join(' ')],
+      env:  {
+        ...process.env,
+        ...env,
+        CGO_ENABLED:  '1',
+        GOEXPERIMENT: 'boringcrypto',
+      },
+    };
+  },
+
   /**
    * Build the WSL helper application for Windows.
    */
@@ -274,14 +309,12 @@ export default {
     const buildPlatform = async(platform: 'linux' | 'win32') => {
       const exeName = platform === 'win32' ? 'wsl-helper.exe' : 'wsl-helper';
       const outFile = path.join(this.rootDir, 'resources', platform, exeName);
+      const goos = this.mapPlatformToGoOS(platform);
+      const { args, env } = this.goBuildOptions(goos, { GOOS: goos, CGO_ENABLED: '0' });
 
-      await this.spawn('go', 'build', '-ldflags', '-s -w', '-o', outFile, '.', {
+      await this.spawn('go', 'build', ...args, '-o', outFile, '.', {
         cwd: path.join(this.rootDir, 'src', 'go', 'wsl-helper'),
-        env: {
-          ...process.env,
-          GOOS:        this.mapPlatformToGoOS(platform),
-          CGO_ENABLED: '0',
-        },
+        env,
       });
     };
 
@@ -309,13 +342,12 @@ export default {
       // easier to handle permissions for Linux-in-WSL.
       outFile = path.join(parentDir, 'nerdctl-stub');
     }
+    const { args, env } = this.goBuildOptions(os, { GOOS: os });
+
     // The linux build produces both nerdctl-stub and nerdctl
-    await this.spawn('go', 'build', '-ldflags', '-s -w', '-o', outFile, '.', {
+    await this.spawn('go', 'build', ...args, '-o', outFile, '.', {
       cwd: path.join(this.rootDir, 'src', 'go', 'nerdctl-stub'),
-      env: {
-        ...process.env,
-        GOOS: os,
-      },
+      env,
     });
   },
 
@@ -433,21 +465,51 @@ export default {
    * @param name basename of the executable to build
    * @param platform 'linux', 'windows', or 'darwin'
    * @param childDir final folder destination either 'internal' or 'bin'
+   * @param ldflags Additional flags for the linker.
    */
-  async buildUtility(name: string, platform: NodeJS.Platform, childDir: string): Promise<void> {
+  async buildUtility(name: string, platform: NodeJS.Platform, childDir: string, ldflags: string[] = []): Promise<void> {
     const target = platform === 'win32' ? `${ name }.exe` : name;
     const parentDir = path.join(this.rootDir, 'resources', platform, childDir);
     const outFile = path.join(parentDir, target);
+    const goos = this.mapPlatformToGoOS(platform);
+    const { args, env } = this.goBuildOptions(goos, { GOOS: goos }, ldflags);
 
-    await this.spawn('go', 'build', '-ldflags', '-s -w', '-o', outFile, '.', {
+    await this.spawn('go', 'build', ...args, '-o', outFile, '.', {
       cwd: path.join(this.rootDir, 'src', 'go', name),
-      env: {
-        ...process.env,
-        GOOS: this.mapPlatformToGoOS(platform),
-      },
+      env,
     });
   },
 
+  /**
+   * Identify the machine or CI job making the build, for the provenance
+   * manifest.
+   */
+  get builder(): string {
+    const { GITHUB_SERVER_URL, GITHUB_REPOSITORY, GITHUB_RUN_ID } = process.env;
+
+    if (GITHUB_SERVER_URL && GITHUB_REPOSITORY && GITHUB_RUN_ID) {
+      return `${ GITHUB_SERVER_URL }/${ GITHUB_REPOSITORY }/actions/runs/${ GITHUB_RUN_ID }`;
+    }
+
+    return os.hostname();
+  },
+
+  /**
+   * Build rdctl, embedding the provenance manifest of the other helpers for
+   * the platform so that `rdctl verify-install` can check them; it must be
+   * built after they have all been built or downloaded.
+   * @param platform 'linux', 'windows', or 'darwin'
+   */
+  async buildRdctl(platform: NodeJS.Platform): Promise<void> {
+    const { stdout: manifest } = await util.promisify(childProcess.execFile)('go',
+      ['run', './pkg/provenance/generate', path.join(this.rootDir, 'resources'), platform, this.builder],
+      { cwd: path.join(this.rootDir, 'src', 'go', 'rdctl') });
+
+    await this.buildUtility('rdctl', platform, 'bin', [
+      '-X', `github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/provenance.encodedManifest=${ manifest }`,
+    ]);
+  },
+
   /**
    * Build the preload script.
    */
@@ -474,14 +536,21 @@ export default {
       tasks.push(() => this.buildNerdctlStub('linux'));
       tasks.push(() => this.buildUtility('vtunnel', 'linux', 'internal'));
       tasks.push(() => this.buildUtility('vtunnel', 'win32', 'internal'));
-      tasks.push(() => this.buildUtility('rdctl', 'linux', 'bin'));
       tasks.push(() => this.buildUtility('privileged-service', 'win32', 'internal'));
     }
-    tasks.push(() => this.buildUtility('rdctl', os.platform(), 'bin'));
     tasks.push(() => this.buildUtility('docker-credential-none', os.platform(), 'bin'));
     tasks.push(() => this.buildExtensionProxyImage());
 
-    return await this.wait(...tasks);
+    await this.wait(...tasks);
+
+    // rdctl records the other helpers, so it is built last.
+    const rdctlTasks = [() => this.buildRdctl(os.platform())];
+
+    if (os.platform().startsWith('win')) {
+      rdctlTasks.push(() => this.buildRdctl('linux'));
+    }
+
+    return await this.wait(...rdctlTasks);
   },
 
 };
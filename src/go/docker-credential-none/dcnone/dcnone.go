@@ -3,6 +3,7 @@
 // ~/.docker/plaintext-credentials.config.json
 // in the `auths` section
 // as `ServerURL: auth : base64Encode(Username + ":" + Secret)`
+// unless encryption is enabled (see encryption.go).
 
 package dcnone
 
@@ -53,8 +54,16 @@ func (p DCNone) Add(creds *credentials.Credentials) error {
 		config["auths"] = auths
 	}
 	payload := fmt.Sprintf("%s:%s", creds.Username, creds.Secret)
-	encoded := base64.URLEncoding.EncodeToString([]byte(payload))
-	auths[creds.ServerURL] = map[string]string{"auth": encoded}
+	if useEncryption(config) {
+		encrypted, err := encryptCredentials(payload)
+		if err != nil {
+			return err
+		}
+		auths[creds.ServerURL] = map[string]string{encryptedField: encrypted}
+	} else {
+		encoded := base64.StdEncoding.EncodeToString([]byte(payload))
+		auths[creds.ServerURL] = map[string]string{"auth": encoded}
+	}
 	return saveParsedConfig(&config)
 }
 
@@ -68,22 +77,19 @@ func (p DCNone) Delete(serverURL string) error {
 		return err
 	}
 
-	authsInterface, ok := config["auths"]
-	if !ok {
-		// Not an error if there's no URL (or auths)
-		return nil
-	}
-	auths, ok := authsInterface.(map[string]interface{})
-	if !ok {
-		// Same as above -- if we can't get the hash we don't have a URL entry to remove
+	auths, err := getAuths(config)
+	if err != nil {
+		// If we can't get the hash we don't have a URL entry to remove
 		return nil
 	}
-	_, ok = auths[serverURL]
-	if !ok {
+	matches := findServerURLs(auths, serverURL)
+	if len(matches) == 0 {
 		// Not an error if there's no URL (or auths)
 		return nil
 	}
-	delete(auths, serverURL)
+	for _, match := range matches {
+		delete(auths, match)
+	}
 	return saveParsedConfig(&config)
 }
 
@@ -103,24 +109,25 @@ func (p DCNone) Get(serverURL string) (string, string, error) {
 	return username, secret, nil
 }
 
-// List returns the stored URLs and corresponding usernames for a given credentials label
+// List returns the stored URLs and corresponding usernames for a given credentials label.
+// Credentials that can't be read (e.g. if the encryption key is gone) are
+// listed without a username, so that they can still be found and erased.
 func (p DCNone) List() (map[string]string, error) {
 	entries := make(map[string]string)
 	config, err := getParsedConfig()
 	if err != nil {
 		return entries, err
 	}
-	authsInterface, ok := config["auths"]
-	if ok {
-		auths, ok := authsInterface.(map[string]interface{})
-		if !ok {
-			return entries, fmt.Errorf("Unexpected data: %v: not a hash\n", authsInterface)
-		}
-		for url := range auths {
-			username, _, err := getRecordForServerURL(&config, url)
-			if username != "" && err == nil {
-				entries[url] = username
-			}
+	auths, err := getAuths(config)
+	if err != nil {
+		return entries, err
+	}
+	for url, entry := range auths {
+		username, _, err := parseAuthEntry(entry, url)
+		if err == nil {
+			entries[url] = username
+		} else if !credentials.IsErrCredentialsNotFound(err) && !credentials.IsCredentialsMissingUsername(err) {
+			entries[url] = ""
 		}
 	}
 	return entries, nil
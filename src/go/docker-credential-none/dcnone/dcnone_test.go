@@ -1,13 +1,27 @@
 package dcnone
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 )
 
+// useTempConfig points the helper at a config file in a temporary directory
+// for the duration of the test.
+func useTempConfig(t *testing.T) {
+	oldConfigFile := configFile
+	t.Cleanup(func() { configFile = oldConfigFile })
+	configFile = filepath.Join(t.TempDir(), configFileName)
+}
+
 func TestDCNoneHelper(t *testing.T) {
+	useTempConfig(t)
 	helper := DCNone{}
 
 	const server1 = "https://foobar.docker.io:2376/v1"
@@ -91,3 +105,71 @@ func TestDCNoneHelper(t *testing.T) {
 		}
 	}
 }
+
+func TestDCNoneHelperEncrypted(t *testing.T) {
+	useTempConfig(t)
+	key, err := newEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldEncryptionKey := encryptionKey
+	t.Cleanup(func() { encryptionKey = oldEncryptionKey })
+	encryptionKey = func(bool) ([]byte, error) { return key, nil }
+
+	helper := DCNone{}
+	const plainServer = "https://plain.example.com"
+	const encryptedServer = "https://encrypted.example.com"
+	const secret = "isthebestmeshuggahalbum"
+	if err := helper.Add(&credentials.Credentials{ServerURL: plainServer, Username: "plain", Secret: secret}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveParsedConfig(&dockerConfigType{
+		encryptionField: encryptionKeychain,
+		"auths":         mustParseConfig(t)["auths"],
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Add(&credentials.Credentials{ServerURL: encryptedServer, Username: "encrypted", Secret: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := base64.URLEncoding.EncodeToString([]byte("encrypted:" + secret))
+	if strings.Contains(string(contents), plaintext) || strings.Contains(string(contents), secret) {
+		t.Fatalf("encrypted credentials stored in plain text: %s", contents)
+	}
+	entry := mustParseConfig(t)["auths"].(map[string]interface{})[encryptedServer].(map[string]interface{})
+	if _, ok := entry[encryptedField]; !ok {
+		t.Fatalf("expected encrypted credentials, got %v", entry)
+	}
+
+	for server, username := range map[string]string{plainServer: "plain", encryptedServer: "encrypted"} {
+		u, s, err := helper.Get(server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u != username || s != secret {
+			t.Fatalf("invalid credentials for %s: %s %s", server, u, s)
+		}
+	}
+
+	encryptionKey = func(bool) ([]byte, error) { return newEncryptionKey() }
+	if _, _, err := helper.Get(encryptedServer); err == nil {
+		t.Fatal("expected an error decrypting with the wrong key")
+	}
+}
+
+func mustParseConfig(t *testing.T) dockerConfigType {
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config dockerConfigType
+	if err := json.Unmarshal(contents, &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
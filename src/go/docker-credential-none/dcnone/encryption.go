@@ -0,0 +1,98 @@
+package dcnone
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// When the config file has `"encryption": "keychain"` at the top level, new
+// credentials are stored as `ServerURL: encrypted : base64(nonce + AES-GCM(Username + ":" + Secret))`,
+// with the 256-bit key held by the OS (the Keychain on macOS, the Secret
+// Service on Linux, and DPAPI on Windows) rather than in the file.  Entries
+// that only have an `auth` field can always be read.
+
+const (
+	encryptionField    = "encryption"
+	encryptionKeychain = "keychain"
+	encryptedField     = "encrypted"
+	keychainService    = "rancher-desktop-docker-credential-none"
+	keychainAccount    = "encryption-key"
+	encryptionKeySize  = 32
+)
+
+// encryptionKey returns the key used to encrypt credentials; if there is none
+// yet, it is created if create is set, or errNoEncryptionKey is returned.
+// This is a variable for testing.
+var encryptionKey = getKeychainKey
+
+// errNoEncryptionKey is returned by encryptionKey if no key has been stored.
+var errNoEncryptionKey = errors.New("no encryption key found")
+
+// useEncryption returns whether new credentials should be encrypted.
+func useEncryption(config dockerConfigType) bool {
+	mode, _ := config[encryptionField].(string)
+	return mode == encryptionKeychain
+}
+
+func newEncryptionKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating encryption key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptCredentials encrypts the payload, returning it base64-encoded.
+func encryptCredentials(payload string) (string, error) {
+	key, err := encryptionKey(true)
+	if err != nil {
+		return "", fmt.Errorf("getting encryption key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(payload), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptCredentials reverses encryptCredentials.
+func decryptCredentials(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("base64-decoding encrypted credentials: %w", err)
+	}
+	key, err := encryptionKey(false)
+	if err != nil {
+		return "", fmt.Errorf("getting encryption key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted credentials are truncated")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	payload, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting credentials: %w", err)
+	}
+	return string(payload), nil
+}
@@ -6,18 +6,31 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
-	dockerconfig "github.com/docker/cli/cli/config"
+	dockercredentials "github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/docker-credential-helpers/credentials"
 )
 
 type dockerConfigType map[string]interface{}
 
+// plaintextFields are the fields of an `auths` entry that hold unencrypted
+// secrets; `auth` is base64(Username + ":" + Secret).
+var plaintextFields = []string{"auth", "password", "identitytoken"}
+
 func getParsedConfig() (dockerConfigType, error) {
+	return readConfigFile(configFile)
+}
+
+func saveParsedConfig(config *dockerConfigType) error {
+	return writeConfigFile(configFile, config)
+}
+
+func readConfigFile(path string) (dockerConfigType, error) {
 	dockerConfig := make(dockerConfigType)
-	contents, err := os.ReadFile(configFile)
+	contents, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, syscall.ENOENT) {
 			// Time to create a new config (or return no data)
@@ -27,17 +40,17 @@ func getParsedConfig() (dockerConfigType, error) {
 	}
 	err = json.Unmarshal(contents, &dockerConfig)
 	if err != nil {
-		return dockerConfig, fmt.Errorf("reading config file %s: %s", configFile, err)
+		return dockerConfig, fmt.Errorf("reading config file %s: %s", path, err)
 	}
 	return dockerConfig, nil
 }
 
-func saveParsedConfig(config *dockerConfigType) error {
+func writeConfigFile(path string, config *dockerConfigType) error {
 	contents, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
-	scratchFile, err := os.CreateTemp(dockerconfig.Dir(), "tmpconfig.json")
+	scratchFile, err := os.CreateTemp(filepath.Dir(path), "tmpconfig.json")
 	if err != nil {
 		return err
 	}
@@ -46,36 +59,132 @@ func saveParsedConfig(config *dockerConfigType) error {
 	if err != nil {
 		return err
 	}
-	return os.Rename(scratchFile.Name(), configFile)
+	return os.Rename(scratchFile.Name(), path)
 }
 
 /**
- * Returns the Username and Secret associated with `urlArg`, or an error if there was a problem.
+ * Returns the `auths` section of the config, or nil if there isn't one.
  */
-func getRecordForServerURL(config *dockerConfigType, urlArg string) (string, string, error) {
-	authsInterface, ok := (*config)["auths"]
+func getAuths(config dockerConfigType) (map[string]interface{}, error) {
+	authsInterface, ok := config["auths"]
 	if !ok {
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return nil, nil
 	}
-	auths := authsInterface.(map[string]interface{})
-	authDataForUrl, ok := auths[urlArg]
+	auths, ok := authsInterface.(map[string]interface{})
 	if !ok {
+		return nil, fmt.Errorf("Unexpected data: %v: not a hash\n", authsInterface)
+	}
+	return auths, nil
+}
+
+/**
+ * Returns the keys in `auths` that refer to `urlArg`: the exact URL if it is
+ * there, or else every URL with the same host name (as docker does, so that
+ * e.g. `https://index.docker.io/v1/` and `index.docker.io` match).
+ */
+func findServerURLs(auths map[string]interface{}, urlArg string) []string {
+	if _, ok := auths[urlArg]; ok {
+		return []string{urlArg}
+	}
+	var matches []string
+	hostname := dockercredentials.ConvertToHostname(urlArg)
+	for serverURL := range auths {
+		if dockercredentials.ConvertToHostname(serverURL) == hostname {
+			matches = append(matches, serverURL)
+		}
+	}
+	return matches
+}
+
+/**
+ * Returns the Username and Secret associated with `urlArg`, or an error if there was a problem.
+ */
+func getRecordForServerURL(config *dockerConfigType, urlArg string) (string, string, error) {
+	auths, err := getAuths(*config)
+	if err != nil {
+		return "", "", err
+	}
+	matches := findServerURLs(auths, urlArg)
+	if len(matches) == 0 {
 		return "", "", credentials.NewErrCredentialsNotFound()
 	}
-	authData, ok := authDataForUrl.(map[string]interface{})["auth"]
+	return parseAuthEntry(auths[matches[0]], urlArg)
+}
+
+/**
+ * Returns the Username and Secret stored in an `auths` entry, which may be
+ * encrypted, base64-encoded in `auth`, or in separate fields.
+ */
+func parseAuthEntry(entry interface{}, urlArg string) (string, string, error) {
+	fields, ok := entry.(map[string]interface{})
 	if !ok {
 		return "", "", credentials.NewErrCredentialsNotFound()
 	}
-	credentialPair, err := base64.StdEncoding.DecodeString(authData.(string))
-	if err != nil {
-		return "", "", fmt.Errorf("base64-decoding authdata for URL %s: %s", urlArg, err)
+	field := func(name string) string {
+		value, _ := fields[name].(string)
+		return value
 	}
-	parts := strings.SplitN(string(credentialPair), ":", 2)
+	var credentialPair string
+	switch {
+	case field(encryptedField) != "":
+		decrypted, err := decryptCredentials(field(encryptedField))
+		if err != nil {
+			return "", "", fmt.Errorf("reading credentials for URL %s: %w", urlArg, err)
+		}
+		credentialPair = decrypted
+	case field("auth") != "":
+		// Older versions of this helper wrote URL-safe base64.
+		decoded, err := base64.StdEncoding.DecodeString(field("auth"))
+		if err != nil {
+			decoded, err = base64.URLEncoding.DecodeString(field("auth"))
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("base64-decoding authdata for URL %s: %s", urlArg, err)
+		}
+		credentialPair = string(decoded)
+	case field("identitytoken") != "":
+		// Docker stores identity tokens with this special user name.
+		return "<token>", field("identitytoken"), nil
+	case field("password") != "":
+		credentialPair = field("username") + ":" + field("password")
+	default:
+		return "", "", credentials.NewErrCredentialsNotFound()
+	}
+	parts := strings.SplitN(credentialPair, ":", 2)
 	if len(parts) == 1 {
-		return "", "", fmt.Errorf("not a valid base64-encoded pair: <%s>", authData.(string))
+		return "", "", fmt.Errorf("not a valid credential pair for URL %s", urlArg)
 	}
 	if parts[0] == "" {
 		return "", "", credentials.NewErrCredentialsMissingUsername()
 	}
 	return parts[0], parts[1], nil
 }
+
+/**
+ * Returns whether an `auths` entry holds secrets that aren't encrypted.
+ */
+func isPlaintextEntry(entry interface{}) bool {
+	fields, ok := entry.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, name := range plaintextFields {
+		if value, _ := fields[name].(string); value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Removes the secrets from an `auths` entry, leaving any other fields.
+ */
+func removeSecrets(entry interface{}) {
+	if fields, ok := entry.(map[string]interface{}); ok {
+		for _, name := range plaintextFields {
+			delete(fields, name)
+		}
+		delete(fields, "username")
+		delete(fields, encryptedField)
+	}
+}
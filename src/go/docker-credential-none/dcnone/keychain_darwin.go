@@ -0,0 +1,60 @@
+package dcnone
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errItemNotFound is the exit code of `security` when there is no such item.
+const errItemNotFound = 44
+
+// getKeychainKey gets the encryption key from the login keychain.
+func getKeychainKey(create bool) ([]byte, error) {
+	key, err := findKeychainKey()
+	if !errors.Is(err, errNoEncryptionKey) || !create {
+		return key, err
+	}
+	key, err = newEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	// `security add-generic-password` only reads a password from the terminal,
+	// not stdin, so the key would have to be an argument, visible to other
+	// processes.  In interactive mode, `security` reads the whole command from
+	// stdin instead.
+	cmd := exec.Command("/usr/bin/security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %s -a %s -w %s\n",
+		keychainService, keychainAccount, base64.StdEncoding.EncodeToString(key)))
+	out, addErr := cmd.CombinedOutput()
+	// Without -U, the item isn't replaced if another process stored a key
+	// first, so use whatever key the keychain holds now.  This also catches
+	// failures, which interactive mode doesn't reliably report in its exit
+	// code.
+	stored, err := findKeychainKey()
+	if errors.Is(err, errNoEncryptionKey) {
+		if addErr == nil {
+			addErr = errors.New("no key was stored")
+		}
+		return nil, fmt.Errorf("writing keychain: %w: %s", addErr, bytes.TrimSpace(out))
+	}
+	return stored, err
+}
+
+// findKeychainKey reads the encryption key from the login keychain, returning
+// errNoEncryptionKey if there is none.
+func findKeychainKey() ([]byte, error) {
+	out, err := exec.Command("/usr/bin/security", "find-generic-password",
+		"-s", keychainService, "-a", keychainAccount, "-w").Output()
+	if err == nil {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound {
+		return nil, errNoEncryptionKey
+	}
+	return nil, fmt.Errorf("reading keychain: %w", err)
+}
@@ -0,0 +1,95 @@
+package dcnone
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// keychainAttributes identify the encryption key in the Secret Service.
+var keychainAttributes = []string{"service", keychainService, "account", keychainAccount}
+
+// keyLockFileName is the file, next to the config file, that is locked while
+// the encryption key is created.
+const keyLockFileName = "plaintext-credentials.key.lock"
+
+// getKeychainKey gets the encryption key from the Secret Service (e.g. GNOME
+// Keyring or KWallet) via libsecret.
+func getKeychainKey(create bool) ([]byte, error) {
+	key, err := lookupSecret()
+	if !errors.Is(err, errNoEncryptionKey) || !create {
+		return key, err
+	}
+	// secret-tool replaces an item with the same attributes, so if two
+	// processes created the key at once, one of them would lose its key along
+	// with anything it encrypted.  Create it under a lock instead, using the
+	// key of a process that got there first.
+	unlock, err := lockKeyCreation()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	key, err = lookupSecret()
+	if !errors.Is(err, errNoEncryptionKey) {
+		return key, err
+	}
+	key, err = newEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("secret-tool", append([]string{"store", "--label=Rancher Desktop credential encryption key"}, keychainAttributes...)...)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(key))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("writing secret service: %w: %s", err, out)
+	}
+	return key, nil
+}
+
+// lockKeyCreation takes the lock for creating the encryption key, waiting for
+// any other process holding it; call the returned function to release it.
+func lockKeyCreation() (func(), error) {
+	dir := filepath.Dir(configFile)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("locking encryption key: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, keyLockFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("locking encryption key: %w", err)
+	}
+	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("locking encryption key: %w", err)
+	}
+	// Closing the file releases the lock.
+	return func() { _ = file.Close() }, nil
+}
+
+// lookupSecret reads the encryption key from the Secret Service, returning
+// errNoEncryptionKey only if the service has no such item.
+func lookupSecret() ([]byte, error) {
+	cmd := exec.Command("secret-tool", append([]string{"lookup"}, keychainAttributes...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil && stdout.Len() > 0 {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+	}
+	// secret-tool exits with 1, printing nothing, if there is no item; it
+	// also exits with 1 if it can't reach the service, but explains why.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stdout.Len() == 0 && stderr.Len() == 0 {
+		return nil, errNoEncryptionKey
+	}
+	if err == nil {
+		err = errors.New("no output")
+	}
+	return nil, fmt.Errorf("reading secret service: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+}
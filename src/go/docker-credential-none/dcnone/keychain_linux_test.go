@@ -0,0 +1,99 @@
+package dcnone
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeSecretTool puts a secret-tool in PATH that keeps its secret in a file,
+// and fails lookups with the given message if there is one.  It returns the
+// path of the file holding the secret.
+func fakeSecretTool(t *testing.T, lookupError string) string {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	script := `#!/bin/sh
+case "$1" in
+lookup)
+	if [ -n "$LOOKUP_ERROR" ]; then echo "$LOOKUP_ERROR" >&2; exit 1; fi
+	[ -f "$SECRET_FILE" ] || exit 1
+	cat "$SECRET_FILE"
+	;;
+store)
+	cat > "$SECRET_FILE"
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SECRET_FILE", secretFile)
+	t.Setenv("LOOKUP_ERROR", lookupError)
+	oldConfigFile := configFile
+	configFile = filepath.Join(dir, configFileName)
+	t.Cleanup(func() { configFile = oldConfigFile })
+	return secretFile
+}
+
+func TestGetKeychainKey(t *testing.T) {
+	t.Run("creates a key only if asked to", func(t *testing.T) {
+		fakeSecretTool(t, "")
+		if _, err := getKeychainKey(false); !errors.Is(err, errNoEncryptionKey) {
+			t.Fatalf("expected errNoEncryptionKey, got %v", err)
+		}
+		key, err := getKeychainKey(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(key) != encryptionKeySize {
+			t.Errorf("expected a %d byte key, got %d bytes", encryptionKeySize, len(key))
+		}
+		again, err := getKeychainKey(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(key, again) {
+			t.Error("expected the stored key to be reused")
+		}
+	})
+
+	t.Run("doesn't replace the key if the service fails", func(t *testing.T) {
+		secretFile := fakeSecretTool(t, "secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY")
+		_, err := getKeychainKey(true)
+		if err == nil || errors.Is(err, errNoEncryptionKey) {
+			t.Fatalf("expected the lookup failure, got %v", err)
+		}
+		if _, err := os.Stat(secretFile); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected no key to be stored, got %v", err)
+		}
+	})
+	t.Run("processes creating the key at once end up with the same key", func(t *testing.T) {
+		secretFile := fakeSecretTool(t, "")
+		keys := make([][]byte, 8)
+		errs := make([]error, len(keys))
+		var wg sync.WaitGroup
+		for i := range keys {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				keys[i], errs[i] = getKeychainKey(true)
+			}(i)
+		}
+		wg.Wait()
+		stored, err := getKeychainKey(false)
+		if err != nil {
+			t.Fatalf("failed to read the key from %s: %v", secretFile, err)
+		}
+		for i, key := range keys {
+			if errs[i] != nil {
+				t.Errorf("unexpected error: %v", errs[i])
+			} else if !bytes.Equal(key, stored) {
+				t.Error("expected every process to use the stored key")
+			}
+		}
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !linux && !windows
+
+package dcnone
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func getKeychainKey(create bool) ([]byte, error) {
+	return nil, fmt.Errorf("encrypted credentials are not supported on %s", runtime.GOOS)
+}
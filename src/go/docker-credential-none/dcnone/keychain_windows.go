@@ -0,0 +1,77 @@
+package dcnone
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// keyFileName is the file, next to the config file, holding the encryption
+// key protected with DPAPI for the current user.
+const keyFileName = "plaintext-credentials.key"
+
+// getKeychainKey gets the encryption key, protected by DPAPI.
+func getKeychainKey(create bool) ([]byte, error) {
+	keyFile := filepath.Join(filepath.Dir(configFile), keyFileName)
+	protected, err := os.ReadFile(keyFile)
+	if err == nil {
+		return dpapi(protected, windows.CryptUnprotectData)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading encryption key: %w", err)
+	}
+	if !create {
+		return nil, errNoEncryptionKey
+	}
+	key, err := newEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	protected, err = dpapi(key, func(in *windows.DataBlob, _ **uint16, entropy *windows.DataBlob, reserved uintptr, prompt *windows.CryptProtectPromptStruct, flags uint32, out *windows.DataBlob) error {
+		return windows.CryptProtectData(in, nil, entropy, reserved, prompt, flags, out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Another process may be creating or reading the key at the same time.
+	// Write it to a temporary file first, so the key file never appears
+	// partly written, and link it into place, which only one of them can do;
+	// the others use that key instead.
+	file, err := os.CreateTemp(filepath.Dir(keyFile), keyFileName+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("writing encryption key: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(protected)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing encryption key: %w", err)
+	}
+	if err := os.Link(file.Name(), keyFile); errors.Is(err, fs.ErrExist) {
+		return getKeychainKey(false)
+	} else if err != nil {
+		return nil, fmt.Errorf("writing encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// dpapi runs the DPAPI function on the data, returning the result.
+func dpapi(data []byte, fn func(*windows.DataBlob, **uint16, *windows.DataBlob, uintptr, *windows.CryptProtectPromptStruct, uint32, *windows.DataBlob) error) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data to protect")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := fn(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("running DPAPI: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
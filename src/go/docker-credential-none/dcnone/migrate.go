@@ -0,0 +1,173 @@
+package dcnone
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// PlaintextCredentials describes credentials that are stored unencrypted.
+type PlaintextCredentials struct {
+	ServerURL string
+	// File is the config file the credentials are stored in.
+	File string
+}
+
+// dockerConfigFile is the docker CLI config file, which may hold credentials
+// in its `auths` section when no credential helper is in use.
+var dockerConfigFile string
+
+// storeWithHelper stores the credentials with the given credential helper.
+// This is a variable for testing.
+var storeWithHelper = func(helper string, creds *credentials.Credentials) error {
+	return client.Store(client.NewShellProgramFunc("docker-credential-"+helper), creds)
+}
+
+func init() {
+	dockerConfigFile = filepath.Join(dockerconfig.Dir(), dockerconfig.ConfigFileName)
+}
+
+// Audit returns the credentials stored in plain text, either in the docker CLI
+// config file or by this helper without encryption.
+func Audit() ([]PlaintextCredentials, error) {
+	var result []PlaintextCredentials
+	for _, file := range []string{dockerConfigFile, configFile} {
+		config, err := readConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		auths, err := getAuths(config)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file %s: %w", file, err)
+		}
+		for _, serverURL := range sortedKeys(auths) {
+			if isPlaintextEntry(auths[serverURL]) {
+				result = append(result, PlaintextCredentials{ServerURL: serverURL, File: file})
+			}
+		}
+	}
+	return result, nil
+}
+
+// Migrate moves the credentials stored in plain text (as listed by Audit) to
+// the credential helper `docker-credential-<helper>`, returning the ones that
+// were moved.  If helper is empty, the `credsStore` of the docker CLI config
+// is used.  Credentials that fail to be moved are left in place.
+func Migrate(helper string) ([]PlaintextCredentials, error) {
+	dockerConfig, err := readConfigFile(dockerConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if helper == "" {
+		helper, _ = dockerConfig["credsStore"].(string)
+		if helper == "" {
+			return nil, errors.New("no credential helper is configured; specify one to migrate to")
+		}
+	}
+	if helper == "none" {
+		config, err := getParsedConfig()
+		if err != nil {
+			return nil, err
+		}
+		if !useEncryption(config) {
+			return nil, errors.New("docker-credential-none would store the credentials in plain text; enable encryption first")
+		}
+	}
+
+	var migrated []PlaintextCredentials
+	var errs []error
+	// migrateFile moves the plaintext credentials in the config, returning
+	// whether it changed.
+	migrateFile := func(file string, config dockerConfigType, store func(*credentials.Credentials) error) (bool, error) {
+		auths, err := getAuths(config)
+		if err != nil {
+			return false, fmt.Errorf("reading config file %s: %w", file, err)
+		}
+		changed := false
+		for _, serverURL := range sortedKeys(auths) {
+			if !isPlaintextEntry(auths[serverURL]) {
+				continue
+			}
+			username, secret, err := parseAuthEntry(auths[serverURL], serverURL)
+			if err == nil {
+				err = store(&credentials.Credentials{ServerURL: serverURL, Username: username, Secret: secret})
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("migrating credentials for %s from %s: %w", serverURL, file, err))
+				continue
+			}
+			migrated = append(migrated, PlaintextCredentials{ServerURL: serverURL, File: file})
+			changed = true
+		}
+		return changed, nil
+	}
+
+	// The docker CLI keeps an empty entry for each registry with credentials
+	// in a helper, so that tools (e.g. nerdctl) know to ask for them.
+	changed, err := migrateFile(dockerConfigFile, dockerConfig, func(creds *credentials.Credentials) error {
+		if helper == "none" {
+			if err := (DCNone{}).Add(creds); err != nil {
+				return err
+			}
+		} else if err := storeWithHelper(helper, creds); err != nil {
+			return err
+		}
+		auths, _ := getAuths(dockerConfig)
+		removeSecrets(auths[creds.ServerURL])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := writeConfigFile(dockerConfigFile, &dockerConfig); err != nil {
+			return migrated, err
+		}
+	}
+
+	// Credentials in our own store are re-encrypted in place, or moved out of
+	// it entirely.
+	config, err := getParsedConfig()
+	if err != nil {
+		return migrated, err
+	}
+	changed, err = migrateFile(configFile, config, func(creds *credentials.Credentials) error {
+		auths, _ := getAuths(config)
+		if helper != "none" {
+			if err := storeWithHelper(helper, creds); err != nil {
+				return err
+			}
+			delete(auths, creds.ServerURL)
+			return nil
+		}
+		encrypted, err := encryptCredentials(creds.Username + ":" + creds.Secret)
+		if err != nil {
+			return err
+		}
+		auths[creds.ServerURL] = map[string]string{encryptedField: encrypted}
+		return nil
+	})
+	if err != nil {
+		return migrated, err
+	}
+	if changed {
+		if err := saveParsedConfig(&config); err != nil {
+			return migrated, err
+		}
+	}
+	return migrated, errors.Join(errs...)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,121 @@
+package dcnone
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// useTempDockerConfig points the helper at a docker CLI config file with the
+// given contents in a temporary directory for the duration of the test.
+func useTempDockerConfig(t *testing.T, config dockerConfigType) {
+	oldDockerConfigFile := dockerConfigFile
+	t.Cleanup(func() { dockerConfigFile = oldDockerConfigFile })
+	dockerConfigFile = filepath.Join(t.TempDir(), "config.json")
+	if err := writeConfigFile(dockerConfigFile, &config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func basicAuth(username, secret string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + secret))
+}
+
+func TestDCNoneHelperMatchesHostname(t *testing.T) {
+	useTempConfig(t)
+	helper := DCNone{}
+	if err := helper.Add(&credentials.Credentials{ServerURL: "https://index.docker.io/v1/", Username: "user", Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	username, secret, err := helper.Get("index.docker.io")
+	if err != nil || username != "user" || secret != "secret" {
+		t.Fatalf("expected credentials by hostname, got %q %q %v", username, secret, err)
+	}
+	if err := helper.Delete("index.docker.io"); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := helper.List(); err != nil || len(list) != 0 {
+		t.Fatalf("expected no credentials after erase, got %v %v", list, err)
+	}
+}
+
+func TestAuditAndMigrate(t *testing.T) {
+	useTempConfig(t)
+	useTempDockerConfig(t, dockerConfigType{
+		"credsStore": "secure",
+		"auths": map[string]interface{}{
+			"docker.example.com": map[string]interface{}{"auth": basicAuth("docker", "s1")},
+			"token.example.com":  map[string]interface{}{"identitytoken": "t1"},
+			"empty.example.com":  map[string]interface{}{},
+		},
+	})
+	if err := (DCNone{}).Add(&credentials.Credentials{ServerURL: "none.example.com", Username: "none", Secret: "s2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PlaintextCredentials{
+		{ServerURL: "docker.example.com", File: dockerConfigFile},
+		{ServerURL: "token.example.com", File: dockerConfigFile},
+		{ServerURL: "none.example.com", File: configFile},
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Fatalf("expected %v, got %v", expected, found)
+	}
+
+	stored := make(map[string]credentials.Credentials)
+	oldStoreWithHelper := storeWithHelper
+	t.Cleanup(func() { storeWithHelper = oldStoreWithHelper })
+	storeWithHelper = func(helper string, creds *credentials.Credentials) error {
+		if helper != "secure" {
+			t.Errorf("unexpected helper %s", helper)
+		}
+		if creds.ServerURL == "token.example.com" {
+			return errors.New("store failed")
+		}
+		stored[creds.ServerURL] = *creds
+		return nil
+	}
+
+	migrated, err := Migrate("")
+	if err == nil {
+		t.Error("expected the failure to be reported")
+	}
+	if !reflect.DeepEqual(migrated, []PlaintextCredentials{expected[0], expected[2]}) {
+		t.Errorf("unexpected migrated credentials %v", migrated)
+	}
+	if stored["docker.example.com"].Secret != "s1" || stored["none.example.com"].Secret != "s2" {
+		t.Errorf("unexpected stored credentials %v", stored)
+	}
+
+	found, err = Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []PlaintextCredentials{expected[1]}) {
+		t.Errorf("expected only the failed credentials to remain, got %v", found)
+	}
+	dockerConfig, err := readConfigFile(dockerConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auths, _ := getAuths(dockerConfig)
+	if _, ok := auths["docker.example.com"]; !ok {
+		t.Errorf("expected an empty entry to be kept for the migrated registry, got %v", auths)
+	}
+}
+
+func TestMigrateToNoneRequiresEncryption(t *testing.T) {
+	useTempConfig(t)
+	useTempDockerConfig(t, dockerConfigType{})
+	if _, err := Migrate("none"); err == nil {
+		t.Error("expected migrating to unencrypted storage to fail")
+	}
+}
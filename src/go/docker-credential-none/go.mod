@@ -5,12 +5,19 @@ go 1.21
 require (
 	github.com/docker/cli v24.0.7+incompatible
 	github.com/docker/docker-credential-helpers v0.8.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sys v0.8.0
 )
 
 require (
 	github.com/docker/docker v23.0.6+incompatible // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/sirupsen/logrus v1.9.3 // indirect
-	golang.org/x/sys v0.8.0 // indirect
 	gotest.tools/v3 v3.5.0 // indirect
 )
+
+replace (
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips => ../fips
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging => ../logging
+)
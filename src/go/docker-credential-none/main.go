@@ -1,10 +1,70 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/docker/docker-credential-helpers/credentials"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/docker-credential-none/dcnone"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/fips"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/sirupsen/logrus"
 )
 
+// exit closes the log before exiting.
+func exit(logCloser io.Closer, code int) {
+	_ = logCloser.Close()
+	os.Exit(code)
+}
+
 func main() {
+	defer logging.HandlePanic()
+	// Standard output is reserved for the credential helper protocol.
+	logCloser := logging.Init("docker-credential-none")
+	if err := fips.Verify(); err != nil {
+		logrus.Error(err)
+		exit(logCloser, 1)
+	}
+	// In addition to the standard commands, support:
+	//   audit: list the credentials stored in plain text, failing if there are any.
+	//   migrate [helper]: move those credentials into the given (or configured) helper.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			found, err := dcnone.Audit()
+			if !printResult(found, err) || len(found) > 0 {
+				exit(logCloser, 1)
+			}
+			exit(logCloser, 0)
+		case "migrate":
+			helper := ""
+			if len(os.Args) > 2 {
+				helper = os.Args[2]
+			}
+			if !printResult(dcnone.Migrate(helper)) {
+				exit(logCloser, 1)
+			}
+			exit(logCloser, 0)
+		}
+	}
 	credentials.Serve(dcnone.DCNone{})
+	exit(logCloser, 0)
+}
+
+// printResult prints the result as JSON, and logs the error, if any; it returns
+// whether it succeeded.
+func printResult(result []dcnone.PlaintextCredentials, err error) bool {
+	if result == nil {
+		result = []dcnone.PlaintextCredentials{}
+	}
+	if output, jsonErr := json.Marshal(result); jsonErr == nil {
+		fmt.Fprintln(os.Stdout, string(output))
+	}
+	if err != nil {
+		logrus.Error(err)
+		return false
+	}
+	return true
 }
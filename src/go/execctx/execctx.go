@@ -0,0 +1,138 @@
+// Package execctx runs the external programs that the Rancher Desktop helpers
+// depend on (limactl, wsl.exe, netsh, and so on) with a bounded timeout and
+// consistent errors, so that a single hung subprocess can't wedge shutdown or
+// factory-reset indefinitely.
+//
+// It is meant for commands that are expected to finish; interactive commands,
+// and daemons that run for the lifetime of their parent, should keep using
+// os/exec directly.
+package execctx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is used when a command is given no timeout; it suits
+	// commands that query or change state without doing much work.
+	DefaultTimeout = time.Minute
+	// waitDelay is how long to wait for the output of a command once it has
+	// been killed: a process it started may hold its output open, which
+	// would otherwise block forever.
+	waitDelay = 5 * time.Second
+	// maxDescription and maxStderr limit the length of error messages, as
+	// arguments can be scripts, and programs can be verbose.
+	maxDescription = 120
+	maxStderr      = 1024
+)
+
+// ErrTimeout is wrapped by the errors of commands that ran out of time.
+var ErrTimeout = errors.New("timed out")
+
+// Cmd is an exec.Cmd that is killed if it runs past its timeout.  The timeout
+// starts when the command is made, so it should be run right away.
+type Cmd struct {
+	*exec.Cmd
+	parent  context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+// Command returns a command that runs the named program, and is killed when
+// the context is done or the timeout expires; a zero timeout means
+// DefaultTimeout.
+func Command(ctx context.Context, timeout time.Duration, name string, args ...string) *Cmd {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	cmd.WaitDelay = waitDelay
+	return &Cmd{Cmd: cmd, parent: ctx, ctx: cmdCtx, cancel: cancel, timeout: timeout}
+}
+
+// Run runs the command and waits for it to finish.  Unless the caller sets
+// Stderr, the error includes what the command wrote to it.
+func (c *Cmd) Run() error {
+	defer c.cancel()
+	var stderr *bytes.Buffer
+	if c.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		c.Stderr = stderr
+	}
+	err := c.Cmd.Run()
+	if stderr != nil {
+		return c.wrap(err, stderr.Bytes())
+	}
+	return c.wrap(err, nil)
+}
+
+// Output runs the command and returns its standard output.  Unless the caller
+// sets Stderr, the error includes what the command wrote to it.
+func (c *Cmd) Output() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.Output()
+	var stderr []byte
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr = exitErr.Stderr
+	}
+	return output, c.wrap(err, stderr)
+}
+
+// CombinedOutput runs the command and returns its standard output and
+// standard error.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.CombinedOutput()
+	return output, c.wrap(err, nil)
+}
+
+// Wait waits for a command started with Start to finish.
+func (c *Cmd) Wait() error {
+	defer c.cancel()
+	return c.wrap(c.Cmd.Wait(), nil)
+}
+
+// wrap describes why the command failed.  Errors from the program itself
+// (such as *exec.ExitError) are wrapped, so callers can still check its exit
+// code.
+func (c *Cmd) wrap(err error, stderr []byte) error {
+	if err == nil {
+		return nil
+	}
+	description := c.description()
+	if c.parent.Err() != nil {
+		return fmt.Errorf("%s was interrupted: %w", description, c.parent.Err())
+	}
+	if errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s %w after %s", description, ErrTimeout, c.timeout)
+	}
+	message := strings.TrimSpace(string(stderr))
+	if len(message) > maxStderr {
+		message = "…" + message[len(message)-maxStderr:]
+	}
+	if message != "" {
+		return fmt.Errorf("failed to run %s: %w: %s", description, err, message)
+	}
+	return fmt.Errorf("failed to run %s: %w", description, err)
+}
+
+// description names the command in errors: the program, without its
+// directory, and its arguments.
+func (c *Cmd) description() string {
+	words := append([]string{strings.TrimSuffix(filepath.Base(c.Path), ".exe")}, c.Args[1:]...)
+	description := strings.Join(words, " ")
+	if len(description) > maxDescription {
+		description = description[:maxDescription] + "…"
+	}
+	return description
+}
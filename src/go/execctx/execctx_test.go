@@ -0,0 +1,73 @@
+package execctx
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the tests run sh")
+	}
+}
+
+func TestOutput(t *testing.T) {
+	skipOnWindows(t)
+	output, err := Command(context.Background(), 0, "sh", "-c", "echo hello").Output()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(output) != "hello\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+func TestFailure(t *testing.T) {
+	skipOnWindows(t)
+	for name, run := range map[string]func(*Cmd) error{
+		"Run": (*Cmd).Run,
+		"Output": func(c *Cmd) error {
+			_, err := c.Output()
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := run(Command(context.Background(), 0, "sh", "-c", "echo oops >&2; exit 3"))
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+				t.Fatalf("Expected exit code 3, got %v", err)
+			}
+			if !strings.HasPrefix(err.Error(), "failed to run sh -c") || !strings.HasSuffix(err.Error(), ": oops") {
+				t.Errorf("Unexpected message %q", err)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	skipOnWindows(t)
+	start := time.Now()
+	// The child keeps the output open after sh is killed.
+	_, err := Command(context.Background(), 100*time.Millisecond, "sh", "-c", "sleep 30 & sleep 30").Output()
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("The command took %s to time out", elapsed)
+	}
+}
+
+func TestInterrupted(t *testing.T) {
+	skipOnWindows(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Command(ctx, time.Minute, "sh", "-c", "sleep 30").Run()
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected the command to be interrupted, got %v", err)
+	}
+}
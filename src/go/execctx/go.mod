@@ -0,0 +1,3 @@
+module github.com/rancher-sandbox/rancher-desktop/src/go/execctx
+
+go 1.21
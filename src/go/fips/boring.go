@@ -0,0 +1,11 @@
+//go:build boringcrypto
+
+package fips
+
+import "crypto/boring"
+
+const backendName = "BoringCrypto"
+
+func backendEnabled() bool {
+	return boring.Enabled()
+}
@@ -0,0 +1,9 @@
+//go:build !boringcrypto
+
+package fips
+
+const backendName = ""
+
+func backendEnabled() bool {
+	return false
+}
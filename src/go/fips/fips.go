@@ -0,0 +1,40 @@
+// Package fips checks that the Rancher Desktop Go programs use FIPS 140
+// validated cryptography when they have been built to.
+//
+// A FIPS build uses `GOEXPERIMENT=boringcrypto` and the `fips` build tag; this
+// needs cgo, and is only supported on Linux.  Such builds link in BoringCrypto
+// and restrict TLS to FIPS-approved settings, and the programs call Verify on
+// startup to refuse to run if BoringCrypto isn't actually in use.  Building
+// with the tag but without the experiment fails, as crypto/tls/fipsonly is
+// then unavailable.
+package fips
+
+import "errors"
+
+// Required returns whether this is a FIPS build.
+func Required() bool {
+	return required
+}
+
+// Enabled returns whether cryptography is provided by a FIPS 140 validated
+// module.
+func Enabled() bool {
+	return backendEnabled()
+}
+
+// Backend names the module providing the cryptography.
+func Backend() string {
+	if backendEnabled() {
+		return backendName
+	}
+	return "Go"
+}
+
+// Verify returns an error if this is a FIPS build, but the validated module is
+// not in use.
+func Verify() error {
+	if required && !backendEnabled() {
+		return errors.New("this program was built for FIPS mode, but FIPS 140 validated cryptography is not available")
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package fips
+
+import "testing"
+
+func TestVerify(t *testing.T) {
+	err := Verify()
+	if required && !Enabled() {
+		if err == nil {
+			t.Error("Verify must fail in a FIPS build without a validated module")
+		}
+	} else if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !Enabled() && Backend() != "Go" {
+		t.Errorf("Unexpected backend %q", Backend())
+	}
+}
@@ -0,0 +1,8 @@
+//go:build fips
+
+package fips
+
+// Only allow FIPS-approved TLS versions, cipher suites, and certificates.
+import _ "crypto/tls/fipsonly"
+
+const required = true
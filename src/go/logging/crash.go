@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CrashDirEnvVar sets the directory crash reports are written to; the
+	// application sets it to its logs directory.
+	CrashDirEnvVar = "RD_CRASH_DIR"
+	// CrashExitCode is the exit status of a program that crashed after
+	// writing a crash report (EX_SOFTWARE), so that the application can tell
+	// crashes apart from other failures.
+	CrashExitCode = 70
+	// crashReportSuffix is the extension of crash reports; the application
+	// only cleans up *.log files, so they are kept across restarts.
+	crashReportSuffix = ".crash"
+)
+
+// redacted replaces sensitive values in crash reports.
+const redacted = "REDACTED"
+
+// sensitiveAssignment matches values given to sensitive names, as in
+// "password=hunter2", "token: abc" or "Authorization: Bearer abc".
+var sensitiveAssignment = regexp.MustCompile(`(?i)((?:password|secret|token|credential|authorization|privatekey)[^\s=:]*\s*[=:]\s*)(?:bearer\s+|basic\s+)?\S+`)
+
+// crashComponent is the name given to Init, for crash reports.
+var crashComponent string
+
+// HandlePanic must be deferred at the start of main (and of any goroutine
+// whose panics should be reported): if the program panics, it writes a crash
+// report and exits with CrashExitCode instead of dying with only a stack
+// trace on standard error, which nobody may be watching.
+func HandlePanic() {
+	value := recover()
+	if value == nil {
+		return
+	}
+	name := crashComponent
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+	stack := debug.Stack()
+	path, err := WriteCrashReport(crashDir(), name, value, stack)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s crashed: %s", name, redact(fmt.Sprintf("%v\n%s", value, stack)))
+	} else {
+		logrus.Errorf("%s crashed: %s; the crash report is in %s", name, redact(fmt.Sprint(value)), path)
+	}
+	os.Exit(CrashExitCode)
+}
+
+// crashDir returns where crash reports go: CrashDirEnvVar, or else next to
+// the log file, or else the temporary directory.
+func crashDir() string {
+	if dir := os.Getenv(CrashDirEnvVar); dir != "" {
+		return dir
+	}
+	if file := os.Getenv(FileEnvVar); file != "" {
+		return filepath.Dir(file)
+	}
+	return os.TempDir()
+}
+
+// WriteCrashReport writes a crash report for the named component into dir,
+// returning its path.  Secrets and the home directory are redacted.
+func WriteCrashReport(dir, name string, value any, stack []byte) (string, error) {
+	now := time.Now()
+	var report strings.Builder
+	fmt.Fprintf(&report, "Component: %s\n", name)
+	fmt.Fprintf(&report, "Version: %s\n", buildVersion())
+	fmt.Fprintf(&report, "Go: %s\n", runtime.Version())
+	fmt.Fprintf(&report, "Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&report, "PID: %d\n", os.Getpid())
+	fmt.Fprintf(&report, "Time: %s\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&report, "Panic: %v\n\n%s", value, stack)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-%d%s", name, now.Format("20060102-150405"), os.Getpid(), crashReportSuffix))
+	if err := os.WriteFile(path, []byte(redact(report.String())), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// buildVersion describes the build, from the information recorded by the Go
+// toolchain.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			version += " (" + setting.Value + ")"
+		}
+	}
+	return version
+}
+
+// redact removes secrets, and the user's home directory (which includes their
+// name), from the report.
+func redact(report string) string {
+	report = sensitiveAssignment.ReplaceAllString(report, "${1}"+redacted)
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		report = strings.ReplaceAll(report, home, "~")
+	}
+	return report
+}
@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCrashReport(t *testing.T) {
+	dir := t.TempDir()
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	value := "failed to connect with password=hunter2 from " + filepath.Join(home, "project")
+	stack := []byte("goroutine 1 [running]:\nmain.main()\n\tAuthorization: Bearer abc123\n")
+
+	path, err := WriteCrashReport(dir, "wsl-helper", value, stack)
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(path))
+	assert.True(t, strings.HasPrefix(filepath.Base(path), "wsl-helper-"))
+	assert.True(t, strings.HasSuffix(path, crashReportSuffix))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	report := string(contents)
+	assert.Contains(t, report, "Component: wsl-helper\n")
+	assert.Contains(t, report, "Platform: ")
+	assert.Contains(t, report, "password=REDACTED")
+	assert.Contains(t, report, "Authorization: REDACTED")
+	assert.Contains(t, report, filepath.Join("~", "project"))
+	assert.NotContains(t, report, "hunter2")
+	assert.NotContains(t, report, "abc123")
+	if len(home) > 1 {
+		assert.NotContains(t, report, home)
+	}
+}
+
+// TestHandlePanic runs itself in a child process that panics, as HandlePanic
+// exits.
+func TestHandlePanic(t *testing.T) {
+	if os.Getenv("TEST_HANDLE_PANIC") == "1" {
+		defer HandlePanic()
+		panic("token=s3cr3t")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHandlePanic$")
+	cmd.Env = append(os.Environ(), "TEST_HANDLE_PANIC=1", CrashDirEnvVar+"="+dir, FileEnvVar+"=")
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, string(output))
+	assert.Equal(t, CrashExitCode, exitErr.ExitCode())
+	assert.NotContains(t, string(output), "s3cr3t")
+
+	reports, err := filepath.Glob(filepath.Join(dir, "*"+crashReportSuffix))
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	contents, err := os.ReadFile(reports[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "Panic: token=REDACTED")
+}
@@ -0,0 +1,15 @@
+module github.com/rancher-sandbox/rancher-desktop/src/go/logging
+
+go 1.21
+
+require (
+	github.com/sirupsen/logrus v1.7.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,157 @@
+// Package logging configures logrus the same way for all of the Rancher
+// Desktop helper programs, so that their logs can be collected (e.g. into a
+// support bundle) and read together.  Each program calls Setup once at
+// startup; the defaults can be overridden with environment variables, which
+// are inherited by any helpers a program launches.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LevelEnvVar sets the logging level (e.g. "debug").
+	LevelEnvVar = "RD_LOG_LEVEL"
+	// FormatEnvVar sets the output format, either "text" or "json".
+	FormatEnvVar = "RD_LOG_FORMAT"
+	// FileEnvVar sets the file to log to, instead of standard error.
+	FileEnvVar = "RD_LOG_FILE"
+)
+
+// Format is the format log entries are written in.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+const (
+	// DefaultMaxSize is the size, in bytes, at which log files are rotated.
+	DefaultMaxSize = 10 * 1024 * 1024
+	// DefaultMaxBackups is the number of rotated log files that are kept.
+	DefaultMaxBackups = 3
+)
+
+// ComponentField is the field naming the program that wrote a log entry.
+const ComponentField = "component"
+
+// Options describes how to log.
+type Options struct {
+	// Component is the name of the program; it is added to every entry
+	// written to a file or as JSON.
+	Component string
+	Level     logrus.Level
+	Format    Format
+	// File is the path of the log file; if empty, log to standard error.
+	File string
+	// MaxSize is the size, in bytes, at which the log file is rotated.
+	MaxSize int64
+	// MaxBackups is the number of rotated log files to keep.
+	MaxBackups int
+}
+
+// OptionsFromEnvironment returns the options for the given component, taking
+// any overrides from the environment.  Invalid overrides are reported, but the
+// returned options are still usable, with the defaults in their place.
+func OptionsFromEnvironment(component string) (Options, error) {
+	var errs []error
+	options := Options{
+		Component:  component,
+		Level:      logrus.InfoLevel,
+		Format:     FormatText,
+		File:       os.Getenv(FileEnvVar),
+		MaxSize:    DefaultMaxSize,
+		MaxBackups: DefaultMaxBackups,
+	}
+	if value := os.Getenv(LevelEnvVar); value != "" {
+		level, err := logrus.ParseLevel(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid $%s: %w", LevelEnvVar, err))
+		} else {
+			options.Level = level
+		}
+	}
+	if value := os.Getenv(FormatEnvVar); value != "" {
+		switch format := Format(strings.ToLower(value)); format {
+		case FormatText, FormatJSON:
+			options.Format = format
+		default:
+			errs = append(errs, fmt.Errorf("invalid $%s %q: must be %q or %q", FormatEnvVar, value, FormatText, FormatJSON))
+		}
+	}
+	return options, errors.Join(errs...)
+}
+
+// Setup configures the standard logrus logger.  The returned closer must be
+// called before exiting to close the log file, if any.
+func Setup(options Options) (io.Closer, error) {
+	logger := logrus.StandardLogger()
+	var output io.WriteCloser = nopCloser{os.Stderr}
+	if options.File != "" {
+		file, err := openRotatingFile(options.File, options.MaxSize, options.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		output = file
+	}
+	logger.SetOutput(output)
+	logger.SetLevel(options.Level)
+	switch options.Format {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: options.File != ""})
+	}
+	// Tagging entries shown on a terminal would only add clutter.
+	if options.Component != "" && (options.File != "" || options.Format == FormatJSON) {
+		logger.AddHook(componentHook(options.Component))
+	}
+	return output, nil
+}
+
+// Init sets up logging for the given component from the environment, as
+// programs usually do on startup.  Logging can't fail: problems with the
+// settings are logged, and the defaults used instead.
+func Init(component string) io.Closer {
+	crashComponent = component
+	options, err := OptionsFromEnvironment(component)
+	if err != nil {
+		logrus.Warnf("Ignoring invalid logging settings: %s", err)
+	}
+	closer, err := Setup(options)
+	if err != nil {
+		options.File = ""
+		closer, _ = Setup(options)
+		logrus.Warnf("Logging to standard error instead: %s", err)
+	}
+	return closer
+}
+
+// componentHook adds the name of the component to each log entry.
+type componentHook string
+
+func (h componentHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h componentHook) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data[ComponentField]; !ok {
+		entry.Data[ComponentField] = string(h)
+	}
+	return nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsFromEnvironment(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "")
+		t.Setenv(FormatEnvVar, "")
+		t.Setenv(FileEnvVar, "")
+		options, err := OptionsFromEnvironment("test")
+		require.NoError(t, err)
+		assert.Equal(t, Options{
+			Component:  "test",
+			Level:      logrus.InfoLevel,
+			Format:     FormatText,
+			MaxSize:    DefaultMaxSize,
+			MaxBackups: DefaultMaxBackups,
+		}, options)
+	})
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "debug")
+		t.Setenv(FormatEnvVar, "JSON")
+		t.Setenv(FileEnvVar, "/tmp/test.log")
+		options, err := OptionsFromEnvironment("test")
+		require.NoError(t, err)
+		assert.Equal(t, logrus.DebugLevel, options.Level)
+		assert.Equal(t, FormatJSON, options.Format)
+		assert.Equal(t, "/tmp/test.log", options.File)
+	})
+	t.Run("invalid level", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "loud")
+		t.Setenv(FormatEnvVar, "json")
+		options, err := OptionsFromEnvironment("test")
+		assert.ErrorContains(t, err, LevelEnvVar)
+		assert.Equal(t, logrus.InfoLevel, options.Level)
+		assert.Equal(t, FormatJSON, options.Format)
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		t.Setenv(LevelEnvVar, "")
+		t.Setenv(FormatEnvVar, "xml")
+		_, err := OptionsFromEnvironment("test")
+		assert.ErrorContains(t, err, FormatEnvVar)
+	})
+}
+
+func TestSetup(t *testing.T) {
+	logger := logrus.StandardLogger()
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stderr)
+		logger.SetLevel(logrus.InfoLevel)
+		logger.SetFormatter(&logrus.TextFormatter{})
+		logger.ReplaceHooks(make(logrus.LevelHooks))
+	})
+	path := filepath.Join(t.TempDir(), "logs", "test.log")
+	closer, err := Setup(Options{Component: "test", Level: logrus.WarnLevel, Format: FormatJSON, File: path})
+	require.NoError(t, err)
+	logrus.Info("hidden")
+	logrus.WithField("key", "value").Warn("shown")
+	require.NoError(t, closer.Close())
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	require.Len(t, lines, 1)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "shown", entry["msg"])
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "test", entry[ComponentField])
+	assert.Equal(t, "value", entry["key"])
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	file, err := openRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	for name, expected := range map[string]string{
+		"test.log":   "fourth\n",
+		"test.log.1": "third\n",
+		"test.log.2": "second\n",
+	} {
+		contents, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, expected, string(contents), name)
+		}
+	}
+	assert.NoFileExists(t, path+".3")
+
+	// Reopening the file appends to it.
+	file, err = openRotatingFile(path, 100, 2)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("fifth\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\nfifth\n", string(contents))
+}
@@ -12,27 +12,44 @@ import (
 
 // imageBuildHandler handles `nerdctl image build`
 func imageBuildHandler(c *commandDefinition, args []string, argHandlers argHandlersType) (*parsedArgs, error) {
-	// The first argument is the directory to build; the rest are ignored.
+	// The first argument is the directory to build; any options after it are
+	// parsed as usual.
 	if len(args) < 1 {
 		// This will return an error
 		return &parsedArgs{args: args}, nil
 	}
 	input := args[0]
+	result := &parsedArgs{args: []string{input}}
 	if input == "-" {
-		return &parsedArgs{args: args}, nil
-	}
-	if match, _ := regexp.MatchString(`^[^:/]*://`, input); match {
+		// Build context from stdin
+	} else if match, _ := regexp.MatchString(`^[^:/]*://`, input); match {
 		// input is a URL
-		return &parsedArgs{args: args}, nil
+	} else if strings.HasPrefix(input, "git@") {
+		// input is a git repository (`git@host:path`)
+	} else {
+		newPath, cleanups, err := argHandlers.filePathArgHandler(input)
+		if err != nil {
+			if cleanupErr := runCleanups(cleanups); cleanupErr != nil {
+				err = multierror.Append(err, cleanupErr)
+			}
+			return nil, err
+		}
+		result = &parsedArgs{args: []string{newPath}, cleanup: cleanups}
 	}
-	newPath, cleanups, err := argHandlers.filePathArgHandler(args[0])
-	if err != nil {
-		if cleanupErr := runCleanups(cleanups); cleanupErr != nil {
-			err = multierror.Append(err, cleanupErr)
+	if len(args) > 1 && c != nil {
+		restResult, err := c.parse(args[1:])
+		if err != nil {
+			if cleanupErr := runCleanups(result.cleanup); cleanupErr != nil {
+				err = multierror.Append(err, cleanupErr)
+			}
+			return nil, err
 		}
-		return nil, err
+		result.args = append(result.args, restResult.args...)
+		result.cleanup = append(result.cleanup, restResult.cleanup...)
+	} else {
+		result.args = append(result.args, args[1:]...)
 	}
-	return &parsedArgs{args: append([]string{newPath}, args[1:]...), cleanup: cleanups}, nil
+	return result, nil
 }
 
 // hostPathResult is the return value of a hostPathDeterminerFunc that is used
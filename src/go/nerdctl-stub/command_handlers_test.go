@@ -20,6 +20,36 @@ func TestImageBuildHandler(t *testing.T) {
 		assert.EqualValues(t, []string{"<<path>>"}, parsed.args)
 		assert.Nil(t, parsed.cleanup)
 	})
+	t.Run("parses options after the image directory", func(t *testing.T) {
+		handlers := argHandlersType{
+			filePathArgHandler: func(s string) (string, []cleanupFunc, error) {
+				return "<<" + s + ">>", nil, nil
+			},
+		}
+		c := &commandDefinition{
+			commandPath: "build",
+			options: map[string]argHandler{
+				"-f": handlers.filePathArgHandler,
+				"-t": ignoredArgHandler,
+			},
+		}
+		parsed, err := imageBuildHandler(c, []string{`C:\src`, "-f", `C:\src\Dockerfile`, "-t", "tag"}, handlers)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{`<<C:\src>>`, "-f", `<<C:\src\Dockerfile>>`, "-t", "tag"}, parsed.args)
+	})
+	t.Run("does not munge remote contexts", func(t *testing.T) {
+		handlers := argHandlersType{
+			filePathArgHandler: func(s string) (string, []cleanupFunc, error) {
+				t.Errorf("should not have munged %q", s)
+				return s, nil, nil
+			},
+		}
+		for _, input := range []string{"-", "https://github.com/foo/bar.git", "git@github.com:foo/bar.git"} {
+			parsed, err := imageBuildHandler(nil, []string{input}, handlers)
+			assert.NoError(t, err)
+			assert.EqualValues(t, []string{input}, parsed.args)
+		}
+	})
 	t.Run("handles errors from munging", func(t *testing.T) {
 		handlerError := fmt.Errorf("some handler error")
 		cleanupError := fmt.Errorf("some cleanup error")
@@ -3,6 +3,7 @@ module github.com/rancher-sandbox/rancher-desktop/src/go/nerdctl-stub
 go 1.21
 
 require (
+	github.com/Microsoft/go-winio v0.6.2
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/sys v0.14.0
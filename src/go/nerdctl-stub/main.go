@@ -16,14 +16,23 @@ type spawnOptions struct {
 	args *parsedArgs
 }
 
+// wslDistro returns the name of the WSL distribution for rancher-desktop.
+func wslDistro() string {
+	if distro := os.Getenv("RD_WSL_DISTRO"); distro != "" {
+		return distro
+	}
+	// Named instances (see `rdctl --instance`) have their own distribution.
+	if instance := os.Getenv("RD_INSTANCE"); instance != "" {
+		return "rancher-desktop-" + instance
+	}
+	return "rancher-desktop"
+}
+
 func main() {
 	opts := spawnOptions{
-		distro:  os.Getenv("RD_WSL_DISTRO"),
+		distro:  wslDistro(),
 		nerdctl: os.Getenv("RD_NERDCTL"),
 	}
-	if opts.distro == "" {
-		opts.distro = "rancher-desktop"
-	}
 	if opts.nerdctl == "" {
 		opts.nerdctl = "/usr/local/bin/nerdctl"
 	}
@@ -232,6 +232,26 @@ func builderCacheArgHandler(arg string) (string, []cleanupFunc, error) {
 	return builderCacheProcessor(arg, filePathArgHandler, outputPathArgHandler)
 }
 
+// secretArgHandler handles arguments for `nerdctl build --secret=`
+func secretArgHandler(arg string) (string, []cleanupFunc, error) {
+	return secretArgProcessor(arg, filePathArgHandler)
+}
+
+// sshArgHandler handles arguments for `nerdctl build --ssh=`
+func sshArgHandler(arg string) (string, []cleanupFunc, error) {
+	return sshArgProcessor(arg, filePathArgHandler, forwardSSHAgent)
+}
+
+// forwardSSHAgent makes the SSH agent of the user (from $SSH_AUTH_SOCK)
+// available to nerdctl, returning the path to its socket.
+func forwardSSHAgent() (string, []cleanupFunc, error) {
+	agentSocket := os.Getenv("SSH_AUTH_SOCK")
+	if agentSocket == "" {
+		return "", nil, nil
+	}
+	return filePathArgHandler(agentSocket)
+}
+
 // argHandlers is the table of argument handlers.
 var argHandlers = argHandlersType{
 	volumeArgHandler:       volumeArgHandler,
@@ -239,4 +259,6 @@ var argHandlers = argHandlersType{
 	outputPathArgHandler:   outputPathArgHandler,
 	mountArgHandler:        mountArgHandler,
 	builderCacheArgHandler: builderCacheArgHandler,
+	secretArgHandler:       secretArgHandler,
+	sshArgHandler:          sshArgHandler,
 }
@@ -20,6 +20,76 @@ func runCleanups(cleanups []cleanupFunc) error {
 	return errors.ErrorOrNil()
 }
 
+// consistencyOptions are the volume options Docker Desktop accepts on macOS to
+// tune file sharing; they are meaningless (and unsupported) in nerdctl.
+var consistencyOptions = map[string]struct{}{
+	"cached":     {},
+	"consistent": {},
+	"default":    {},
+	"delegated":  {},
+}
+
+// volumeArgProcessor implements the details for handling the argument for
+// `nerdctl run --volume=...` when the host may use Windows paths.  The argument
+// is of the form `[<host path or volume name>:]<container path>[:<options>]`,
+// where the host path may start with a drive letter, and the options are a
+// comma-separated list (e.g. `ro,z`).  Named and anonymous volumes are left
+// alone.
+func volumeArgProcessor(arg string, mounter func(string) (string, error)) (string, error) {
+	searchFrom := 0
+	if isDrivePath(arg) {
+		searchFrom = 2
+	}
+	colonIndex := strings.Index(arg[searchFrom:], ":")
+	if colonIndex < 0 {
+		// Anonymous volume: only the container path is given.
+		return arg, nil
+	}
+	hostPath := arg[:searchFrom+colonIndex]
+	containerPath, options, hasOptions := strings.Cut(arg[searchFrom+colonIndex+1:], ":")
+	if containerPath == "" {
+		return "", fmt.Errorf("Invalid volume mount: %s does not have a container path", arg)
+	}
+
+	if isHostPath(hostPath) {
+		newPath, err := mounter(hostPath)
+		if err != nil {
+			return "", fmt.Errorf("Could not get volume host path for %s: %w", arg, err)
+		}
+		hostPath = newPath
+	}
+	result := hostPath + ":" + containerPath
+	if hasOptions {
+		var keptOptions []string
+		for _, option := range strings.Split(options, ",") {
+			if _, ok := consistencyOptions[option]; !ok && option != "" {
+				keptOptions = append(keptOptions, option)
+			}
+		}
+		if len(keptOptions) > 0 {
+			result += ":" + strings.Join(keptOptions, ",")
+		}
+	}
+	return result, nil
+}
+
+// isDrivePath returns whether the path starts with a drive letter (`C:`).
+func isDrivePath(path string) bool {
+	if len(path) < 2 || path[1] != ':' {
+		return false
+	}
+	letter := path[0] | 0x20 // lower case
+	return letter >= 'a' && letter <= 'z'
+}
+
+// isHostPath returns whether the host part of a volume specification is a
+// path, rather than the name of a volume.
+func isHostPath(hostPath string) bool {
+	return isDrivePath(hostPath) ||
+		strings.ContainsAny(hostPath, `/\`) ||
+		hostPath == "." || hostPath == ".."
+}
+
 // mountArgProcessor implements the details for handling the argument for
 // `nerdctl run --mount=...`
 func mountArgProcessor(arg string, mounter func(string) (string, error)) (string, []cleanupFunc, error) {
@@ -27,6 +97,10 @@ func mountArgProcessor(arg string, mounter func(string) (string, error)) (string
 	isBind := false
 	for _, chunk := range strings.Split(arg, ",") {
 		parts := strings.SplitN(chunk, "=", 2)
+		if parts[0] == "consistency" {
+			// Docker Desktop (macOS) specific option that nerdctl rejects.
+			continue
+		}
 		if len(parts) != 2 {
 			// Got something with no value, e.g. --mount=...,readonly,...
 			chunks = append(chunks, []string{chunk})
@@ -61,6 +135,67 @@ func mountArgProcessor(arg string, mounter func(string) (string, error)) (string
 	return result[1:], nil, nil // Skip the initial "," we added
 }
 
+// secretArgProcessor implements the details for handling the argument for
+// `nerdctl build --secret=...`, which is of the form `id=<id>,src=<path>`
+// (or `source=<path>`); secrets from the environment (`env=`) have no path.
+func secretArgProcessor(arg string, inputMounter func(string) (string, []cleanupFunc, error)) (string, []cleanupFunc, error) {
+	var cleanups []cleanupFunc
+	var parts []string
+	for _, part := range strings.Split(arg, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if ok && (key == "src" || key == "source") {
+			fixedPath, newCleanups, err := inputMounter(value)
+			cleanups = append(cleanups, newCleanups...)
+			if err != nil {
+				if cleanupErr := runCleanups(cleanups); cleanupErr != nil {
+					return "", nil, multierror.Append(err, cleanupErr)
+				}
+				return "", nil, err
+			}
+			part = key + "=" + fixedPath
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ","), cleanups, nil
+}
+
+// sshArgProcessor implements the details for handling the argument for
+// `nerdctl build --ssh=...`, which is of the form
+// `default|<id>[=<socket>|<key>[,<key>]]`.  Any paths are handled with the
+// inputMounter; if there are none, the SSH agent of the user is forwarded
+// with agentForwarder, which returns the path to the forwarded socket.
+func sshArgProcessor(arg string, inputMounter func(string) (string, []cleanupFunc, error), agentForwarder func() (string, []cleanupFunc, error)) (string, []cleanupFunc, error) {
+	id, paths, hasPaths := strings.Cut(arg, "=")
+	if !hasPaths {
+		socketPath, cleanups, err := agentForwarder()
+		if err != nil {
+			if cleanupErr := runCleanups(cleanups); cleanupErr != nil {
+				return "", nil, multierror.Append(err, cleanupErr)
+			}
+			return "", nil, err
+		}
+		if socketPath == "" {
+			// No agent to forward; let nerdctl report it.
+			return arg, cleanups, nil
+		}
+		return id + "=" + socketPath, cleanups, nil
+	}
+	var cleanups []cleanupFunc
+	var fixedPaths []string
+	for _, path := range strings.Split(paths, ",") {
+		fixedPath, newCleanups, err := inputMounter(path)
+		cleanups = append(cleanups, newCleanups...)
+		if err != nil {
+			if cleanupErr := runCleanups(cleanups); cleanupErr != nil {
+				return "", nil, multierror.Append(err, cleanupErr)
+			}
+			return "", nil, err
+		}
+		fixedPaths = append(fixedPaths, fixedPath)
+	}
+	return id + "=" + strings.Join(fixedPaths, ","), cleanups, nil
+}
+
 // builderCacheProcessor implements the details for handling the argument for
 // `nerdctl builder build --cache-from=...` and
 // `nerdctl builder builder --cache-to=...`
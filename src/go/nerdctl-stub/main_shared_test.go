@@ -68,3 +68,126 @@ func TestBuilderCacheProcessor(t *testing.T) {
 		assert.True(t, cleanupDone, "cleanup function did not run")
 	})
 }
+
+func TestVolumeArgProcessor(t *testing.T) {
+	mounter := func(s string) (string, error) {
+		return "<" + s + ">", nil
+	}
+	testCases := map[string]string{
+		`C:\src:/src`:                      `<C:\src>:/src`,
+		`c:/src:/src:ro`:                   `<c:/src>:/src:ro`,
+		`C:\src:/src:ro,z`:                 `<C:\src>:/src:ro,z`,
+		`C:\src:/src:cached`:               `<C:\src>:/src`,
+		`C:\src:/src:delegated,ro`:         `<C:\src>:/src:ro`,
+		`.\src:/src:rw`:                    `<.\src>:/src:rw`,
+		`.:/src`:                           `<.>:/src`,
+		`\\server\share:/src`:              `<\\server\share>:/src`,
+		`named-volume:/data`:               `named-volume:/data`,
+		`named-volume:/data:ro,consistent`: `named-volume:/data:ro`,
+		`/data`:                            `/data`,
+	}
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			result, err := volumeArgProcessor(input, mounter)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+	t.Run("missing container path", func(t *testing.T) {
+		_, err := volumeArgProcessor(`C:\src:`, mounter)
+		assert.Error(t, err)
+	})
+	t.Run("mounter errors", func(t *testing.T) {
+		_, err := volumeArgProcessor(`C:\src:/src`, func(string) (string, error) {
+			return "", fmt.Errorf("some error")
+		})
+		assert.ErrorContains(t, err, "some error")
+	})
+}
+
+func TestMountArgProcessor(t *testing.T) {
+	mounter := func(s string) (string, error) {
+		return "<" + s + ">", nil
+	}
+	t.Run("translates bind mount sources", func(t *testing.T) {
+		result, _, err := mountArgProcessor(`type=bind,source=C:\src,target=/src,readonly,consistency=cached`, mounter)
+		assert.NoError(t, err)
+		assert.Equal(t, `type=bind,source=<C:\src>,target=/src,readonly`, result)
+	})
+	t.Run("ignores volumes", func(t *testing.T) {
+		input := "type=volume,source=data,target=/data"
+		result, _, err := mountArgProcessor(input, mounter)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+	})
+}
+
+func TestSecretArgProcessor(t *testing.T) {
+	mounter := func(s string) (string, []cleanupFunc, error) {
+		return "<" + s + ">", []cleanupFunc{func() error { return nil }}, nil
+	}
+	t.Run("translates sources", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`id=npmrc,src=C:\npmrc`:              `id=npmrc,src=<C:\npmrc>`,
+			`id=npmrc,source=C:\npmrc,type=file`: `id=npmrc,source=<C:\npmrc>,type=file`,
+		} {
+			result, cleanups, err := secretArgProcessor(input, mounter)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+			assert.Len(t, cleanups, 1)
+		}
+	})
+	t.Run("ignores environment secrets", func(t *testing.T) {
+		input := "id=token,env=TOKEN"
+		result, cleanups, err := secretArgProcessor(input, mounter)
+		assert.NoError(t, err)
+		assert.Equal(t, input, result)
+		assert.Empty(t, cleanups)
+	})
+}
+
+func TestSSHArgProcessor(t *testing.T) {
+	mounter := func(s string) (string, []cleanupFunc, error) {
+		return "<" + s + ">", nil, nil
+	}
+	noForwarder := func() (string, []cleanupFunc, error) {
+		t.Error("should not have forwarded the agent")
+		return "", nil, fmt.Errorf("test failed")
+	}
+	t.Run("translates key paths", func(t *testing.T) {
+		result, _, err := sshArgProcessor(`github=C:\keys\a,C:\keys\b`, mounter, noForwarder)
+		assert.NoError(t, err)
+		assert.Equal(t, `github=<C:\keys\a>,<C:\keys\b>`, result)
+	})
+	t.Run("forwards the agent", func(t *testing.T) {
+		cleanupDone := false
+		result, cleanups, err := sshArgProcessor("default", mounter, func() (string, []cleanupFunc, error) {
+			return "/tmp/agent.sock", []cleanupFunc{func() error {
+				cleanupDone = true
+				return nil
+			}}, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "default=/tmp/agent.sock", result)
+		assert.NoError(t, runCleanups(cleanups))
+		assert.True(t, cleanupDone, "cleanup function did not run")
+	})
+	t.Run("passes through without an agent", func(t *testing.T) {
+		result, _, err := sshArgProcessor("default", mounter, func() (string, []cleanupFunc, error) {
+			return "", nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "default", result)
+	})
+	t.Run("cleans up on errors", func(t *testing.T) {
+		cleanupDone := false
+		_, _, err := sshArgProcessor("default", mounter, func() (string, []cleanupFunc, error) {
+			return "", []cleanupFunc{func() error {
+				cleanupDone = true
+				return nil
+			}}, fmt.Errorf("no agent")
+		})
+		assert.ErrorContains(t, err, "no agent")
+		assert.True(t, cleanupDone, "cleanup function did not run")
+	})
+}
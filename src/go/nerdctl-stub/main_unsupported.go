@@ -17,6 +17,8 @@ var argHandlers = argHandlersType{
 	outputPathArgHandler:   unhandledArgHandler,
 	mountArgHandler:        unhandledArgHandler,
 	builderCacheArgHandler: unhandledArgHandler,
+	secretArgHandler:       unhandledArgHandler,
+	sshArgHandler:          unhandledArgHandler,
 }
 
 func spawn(opts spawnOptions) error {
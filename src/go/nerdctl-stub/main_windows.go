@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"os/exec"
@@ -67,31 +66,13 @@ func pathToWSL(arg string) (string, error) {
 
 // volumeArgHandler handles the argument for `nerdctl run --volume=...`
 func volumeArgHandler(arg string) (string, []cleanupFunc, error) {
-	// Valid arguments are:
-	// <host path>:<container path>
-	// <host path>:<container path>:rw
-	// <host path>:<container path>:ro
-	// Because we only have Linux containers, and this is for Windows, we don't
-	// need to worry about just `<path>` (where the host and container have the
-	// same path).
-	cleanArg := arg
-	readWrite := ""
-	if strings.HasSuffix(arg, ":ro") || strings.HasSuffix(arg, ":rw") {
-		readWrite = arg[len(arg)-3:]
-		cleanArg = arg[:len(arg)-3]
-	}
-	// For now, assume the container path doesn't contain colons.
-	colonIndex := strings.LastIndex(cleanArg, ":")
-	if colonIndex < 0 {
-		return "", nil, fmt.Errorf("Invalid volume mount: %s does not contain : separator", arg)
-	}
-	hostPath := cleanArg[:colonIndex]
-	containerPath := cleanArg[colonIndex+1:]
-	wslHostPath, err := pathToWSL(hostPath)
+	// Because we only have Linux containers, and this is for Windows, a path
+	// without a colon is an anonymous volume rather than a bind mount.
+	result, err := volumeArgProcessor(arg, pathToWSL)
 	if err != nil {
-		return "", nil, fmt.Errorf("Could not get volume host path for %s: %w", arg, err)
+		return "", nil, err
 	}
-	return wslHostPath + ":" + containerPath + readWrite, nil, nil
+	return result, nil, nil
 }
 
 // mountArgHandler handles the argument for `nerdctl run --mount=...`
@@ -124,6 +105,16 @@ func builderCacheArgHandler(arg string) (string, []cleanupFunc, error) {
 	return builderCacheProcessor(arg, filePathArgHandler, outputPathArgHandler)
 }
 
+// secretArgHandler handles arguments for `nerdctl build --secret=`
+func secretArgHandler(arg string) (string, []cleanupFunc, error) {
+	return secretArgProcessor(arg, filePathArgHandler)
+}
+
+// sshArgHandler handles arguments for `nerdctl build --ssh=`
+func sshArgHandler(arg string) (string, []cleanupFunc, error) {
+	return sshArgProcessor(arg, filePathArgHandler, forwardSSHAgent)
+}
+
 // argHandlers is the table of argument handlers.
 var argHandlers = argHandlersType{
 	volumeArgHandler:       volumeArgHandler,
@@ -131,4 +122,6 @@ var argHandlers = argHandlersType{
 	outputPathArgHandler:   outputPathArgHandler,
 	mountArgHandler:        mountArgHandler,
 	builderCacheArgHandler: builderCacheArgHandler,
+	secretArgHandler:       secretArgHandler,
+	sshArgHandler:          sshArgHandler,
 }
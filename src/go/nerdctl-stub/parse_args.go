@@ -27,6 +27,8 @@ type argHandlersType struct {
 	outputPathArgHandler   argHandler
 	mountArgHandler        argHandler
 	builderCacheArgHandler argHandler
+	secretArgHandler       argHandler
+	sshArgHandler          argHandler
 }
 
 // commandHandlerType is the type of commandDefinition.handler, which is used
@@ -291,7 +293,10 @@ func init() {
 	registerArgHandler("builder build", "--cache-from", argHandlers.builderCacheArgHandler)
 	registerArgHandler("builder build", "--cache-to", argHandlers.builderCacheArgHandler)
 	registerArgHandler("builder build", "--file", argHandlers.filePathArgHandler)
+	registerArgHandler("builder build", "-f", argHandlers.filePathArgHandler)
 	registerArgHandler("builder build", "--iidfile", argHandlers.outputPathArgHandler)
+	registerArgHandler("builder build", "--secret", argHandlers.secretArgHandler)
+	registerArgHandler("builder build", "--ssh", argHandlers.sshArgHandler)
 	registerArgHandler("builder debug", "--file", argHandlers.filePathArgHandler)
 	registerArgHandler("builder debug", "-f", argHandlers.filePathArgHandler)
 	registerArgHandler("compose", "--file", argHandlers.filePathArgHandler)
@@ -308,6 +313,7 @@ func init() {
 	registerArgHandler("container create", "--pidfile", argHandlers.outputPathArgHandler)
 	registerArgHandler("container create", "--volume", argHandlers.volumeArgHandler)
 	registerArgHandler("container create", "-v", argHandlers.volumeArgHandler)
+	registerArgHandler("container exec", "--env-file", argHandlers.filePathArgHandler)
 	registerArgHandler("container run", "--cidfile", argHandlers.outputPathArgHandler)
 	registerArgHandler("container run", "--cosign-key", argHandlers.filePathArgHandler)
 	registerArgHandler("container run", "--env-file", argHandlers.filePathArgHandler)
@@ -316,13 +322,19 @@ func init() {
 	registerArgHandler("container run", "--pidfile", argHandlers.outputPathArgHandler)
 	registerArgHandler("container run", "--volume", argHandlers.volumeArgHandler)
 	registerArgHandler("container run", "-v", argHandlers.volumeArgHandler)
+	registerArgHandler("image build", "--cache-from", argHandlers.builderCacheArgHandler)
+	registerArgHandler("image build", "--cache-to", argHandlers.builderCacheArgHandler)
 	registerArgHandler("image build", "--file", argHandlers.filePathArgHandler)
 	registerArgHandler("image build", "-f", argHandlers.filePathArgHandler)
+	registerArgHandler("image build", "--iidfile", argHandlers.outputPathArgHandler)
+	registerArgHandler("image build", "--secret", argHandlers.secretArgHandler)
+	registerArgHandler("image build", "--ssh", argHandlers.sshArgHandler)
 	registerArgHandler("image convert", "--estargz-record-in", argHandlers.filePathArgHandler)
 	registerArgHandler("image load", "--input", argHandlers.filePathArgHandler)
 	registerArgHandler("image save", "--output", argHandlers.outputPathArgHandler)
 
 	// Set up command handlers
+	registerCommandHandler("builder build", imageBuildHandler)
 	registerCommandHandler("image build", imageBuildHandler)
 	registerCommandHandler("container cp", containerCopyHandler)
 
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// sshAgentPipe is the named pipe of the Windows OpenSSH agent.
+const sshAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// wslHelperPath returns the path, inside WSL, to the Linux wsl-helper
+// executable; this executable is in resources/win32/bin, and the helper is in
+// resources/linux.
+func wslHelperPath() (string, error) {
+	if helper := os.Getenv("RD_WSL_HELPER"); helper != "" {
+		return helper, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return pathToWSL(filepath.Join(filepath.Dir(exe), "..", "..", "linux", "wsl-helper"))
+}
+
+// forwardSSHAgent makes the Windows OpenSSH agent available to nerdctl,
+// returning the path to its socket inside WSL.  As the agent is a named pipe,
+// `wsl-helper ssh-agent-relay` listens on the socket, and asks for a
+// connection to the agent for each connection it gets; the returned cleanup
+// function stops it (removing the socket).
+func forwardSSHAgent() (string, []cleanupFunc, error) {
+	// Make sure the agent is running, so that we can give a useful error.
+	probe, err := winio.DialPipe(sshAgentPipe, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not connect to the OpenSSH agent at %s (is the ssh-agent service running?): %w", sshAgentPipe, err)
+	}
+	probe.Close()
+
+	wslHelper, err := wslHelperPath()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not find wsl-helper: %w", err)
+	}
+	socketPath := fmt.Sprintf("/tmp/rancher-desktop-ssh-agent.%d.sock", os.Getpid())
+	cmd := exec.Command("wsl.exe", "--distribution", wslDistro(), "--exec", wslHelper,
+		"ssh-agent-relay", "--socket", socketPath)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("could not start SSH agent relay: %w", err)
+	}
+	cleanup := func() error {
+		// Closing standard input stops the relay.
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("SSH agent relay failed: %w", err)
+		}
+		return nil
+	}
+
+	// The first line is written once the relay is listening; each line after
+	// that is a socket to connect to the agent for one connection.
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() {
+		return "", []cleanupFunc{cleanup}, fmt.Errorf("SSH agent relay failed to start")
+	}
+	go func() {
+		for lines.Scan() {
+			go connectSSHAgent(wslHelper, lines.Text())
+		}
+	}()
+	return socketPath, []cleanupFunc{cleanup}, nil
+}
+
+// connectSSHAgent connects the Windows OpenSSH agent to the given socket
+// inside WSL, for a single connection.
+func connectSSHAgent(wslHelper, socketPath string) {
+	pipe, err := winio.DialPipe(sshAgentPipe, nil)
+	if err != nil {
+		log.Printf("Error connecting to the SSH agent: %s", err)
+		return
+	}
+	defer pipe.Close()
+	cmd := exec.Command("wsl.exe", "--distribution", wslDistro(), "--exec", wslHelper,
+		"dial-stdio", "--socket", socketPath)
+	cmd.Stdin = pipe
+	cmd.Stdout = pipe
+	cmd.Stderr = os.Stderr
+	// Copying from the agent doesn't stop when wsl.exe exits; don't wait for
+	// it, as closing the pipe stops it.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		log.Printf("Error relaying the SSH agent: %s", err)
+	}
+}
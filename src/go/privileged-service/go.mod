@@ -7,12 +7,21 @@ require (
 	github.com/docker/go-connections v0.4.0
 	github.com/pkg/errors v0.9.1
 	github.com/rancher-sandbox/rancher-desktop-agent v0.2.1-0.20220914185110-0a48c21fc77b
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry v0.0.0
 	github.com/spf13/cobra v1.5.0
 	golang.org/x/sys v0.0.0-20220818161305-2296e01440c6
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.8.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
+)
+
+replace (
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx => ../execctx
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging => ../logging
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry => ../retry
 )
@@ -16,8 +16,12 @@ limitations under the License.
 
 package main
 
-import "github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service/cmd"
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service/cmd"
+)
 
 func main() {
+	defer logging.HandlePanic()
 	cmd.Execute()
 }
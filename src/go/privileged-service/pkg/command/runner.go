@@ -19,16 +19,32 @@ limitations under the License.
 package command
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 )
 
+// timeout bounds each command; a netsh script with many port proxies can take
+// a while, but a hung one must not block the service forever.
+const timeout = 5 * time.Minute
+
 // Exec wraps exec.Command, it allows caller to define
 // the underlying command e.g netsh ...
 func Exec(cmd string, args []string) error {
-	out, err := exec.Command(cmd, args...).CombinedOutput()
+	out, err := execctx.Command(context.Background(), timeout, cmd, args...).CombinedOutput()
 	if err == nil {
 		return nil
 	}
 	return fmt.Errorf("execute command error: %w: %s", err, out)
 }
+
+// Output is like Exec, but returns the output of the command.
+func Output(cmd string, args []string) (string, error) {
+	out, err := execctx.Command(context.Background(), timeout, cmd, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("execute command error: %w: %s", err, out)
+	}
+	return string(out), nil
+}
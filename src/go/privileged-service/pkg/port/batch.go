@@ -0,0 +1,205 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service/pkg/command"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/retry"
+)
+
+var (
+	// batchDelay is how long netsh commands are collected before they are
+	// run; many ports change at once on e.g. compose up, and starting netsh
+	// for each of them takes seconds.
+	batchDelay = 100 * time.Millisecond
+	// netshRetries is how many more times a failed netsh command is tried,
+	// as netsh fails transiently while WSL reconfigures the network.
+	netshRetries    = 2
+	netshRetryDelay = 250 * time.Millisecond
+	// runNetsh runs a single netsh command; runNetshScript runs many in a
+	// single netsh process.  These are variables for testing.
+	runNetsh       = func(args []string) error { return command.Exec(netsh, args) }
+	runNetshScript = execNetshScript
+)
+
+// batch is a set of netsh commands to be run together.
+type batch struct {
+	commands [][]string
+	// errs holds the error of each command, once done is closed.
+	errs []error
+	done chan struct{}
+}
+
+// batcher collects the netsh commands submitted concurrently into batches.
+type batcher struct {
+	mutex   sync.Mutex
+	pending *batch
+}
+
+// submit queues the commands to be run in the next batch, in order, and waits
+// for them to finish.
+func (b *batcher) submit(commands [][]string) error {
+	return errors.Join(b.submitEach(commands)...)
+}
+
+// submitEach is like submit, but returns the error of each command.
+func (b *batcher) submitEach(commands [][]string) []error {
+	if len(commands) == 0 {
+		return nil
+	}
+	b.mutex.Lock()
+	if b.pending == nil {
+		b.pending = &batch{done: make(chan struct{})}
+		time.AfterFunc(batchDelay, b.flush)
+	}
+	current := b.pending
+	start := len(current.commands)
+	current.commands = append(current.commands, commands...)
+	b.mutex.Unlock()
+
+	<-current.done
+	return current.errs[start : start+len(commands)]
+}
+
+// flush runs the pending batch.
+func (b *batcher) flush() {
+	b.mutex.Lock()
+	current := b.pending
+	b.pending = nil
+	b.mutex.Unlock()
+
+	current.errs = runNetshCommands(current.commands)
+	close(current.done)
+}
+
+// runNetshCommands runs the netsh commands in a single pass if possible,
+// returning the error of each command.  If that fails, the commands are run
+// one at a time (with retries) so that failures are attributed to the right
+// command, and don't prevent the rest from being applied.  The script may
+// have applied some of the commands before failing, so in that case each
+// command is run such that repeating it has no further effect.
+func runNetshCommands(commands [][]string) []error {
+	errs := make([]error, len(commands))
+	repeated := false
+	if len(commands) > 1 {
+		if err := runNetshScript(commands); err == nil {
+			return errs
+		}
+		repeated = true
+	}
+	policy := retry.Policy{InitialDelay: netshRetryDelay, Jitter: 0.2, MaxAttempts: netshRetries + 1}
+	for i, args := range commands {
+		errs[i] = retry.Do(context.Background(), policy, func(context.Context) error {
+			return runNetshAgain(args, repeated)
+		})
+	}
+	return errs
+}
+
+// runNetshAgain runs a netsh command that may already have been applied.  A
+// firewall rule is replaced rather than added again, as rules with the same
+// name would pile up; and a delete succeeds if there is nothing left to
+// delete, whether or not it was already run.
+func runNetshAgain(args []string, repeated bool) error {
+	if repeated && isNetshCommand(args, "advfirewall", "firewall", "add", "rule") {
+		if name := netshArg(args, "name"); name != "" {
+			// Fails if there is no such rule, which is the usual case.
+			_ = runNetsh([]string{"advfirewall", "firewall", "delete", "rule", "name=" + name})
+		}
+	}
+	err := runNetsh(args)
+	if err != nil && netshDeleted(args) {
+		return nil
+	}
+	return err
+}
+
+// netshDeleted returns whether the command is a delete whose target no
+// longer exists.  The messages netsh gives for that are localized, so this
+// looks for the target instead.
+func netshDeleted(args []string) bool {
+	switch {
+	case isNetshCommand(args, "advfirewall", "firewall", "delete", "rule"):
+		// Showing a rule fails if there is no rule with that name.
+		_, err := runNetshOutput([]string{"advfirewall", "firewall", "show", "rule", "name=" + netshArg(args, "name")})
+		return err != nil
+	case isNetshCommand(args, "interface", "portproxy", "delete") && len(args) > 3:
+		output, err := runNetshOutput([]string{"interface", "portproxy", "show", args[3]})
+		if err != nil {
+			return false
+		}
+		listenAddr, listenPort := netshArg(args, "listenaddress"), netshArg(args, "listenport")
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == listenAddr && fields[1] == listenPort {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isNetshCommand returns whether the arguments start with the given words.
+func isNetshCommand(args []string, words ...string) bool {
+	if len(args) < len(words) {
+		return false
+	}
+	for i, word := range words {
+		if !strings.EqualFold(args[i], word) {
+			return false
+		}
+	}
+	return true
+}
+
+// netshArg returns the value of a key=value argument, or an empty string.
+func netshArg(args []string, key string) string {
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.EqualFold(name, key) {
+			return value
+		}
+	}
+	return ""
+}
+
+// execNetshScript runs the commands as a netsh script.
+func execNetshScript(commands [][]string) error {
+	script, err := os.CreateTemp("", "rd-portproxy-*.netsh")
+	if err != nil {
+		return fmt.Errorf("failed to create netsh script: %w", err)
+	}
+	defer os.Remove(script.Name())
+	for _, args := range commands {
+		if _, err := fmt.Fprintln(script, strings.Join(args, " ")); err != nil {
+			script.Close()
+			return fmt.Errorf("failed to write netsh script: %w", err)
+		}
+	}
+	if err := script.Close(); err != nil {
+		return fmt.Errorf("failed to write netsh script: %w", err)
+	}
+	return command.Exec(netsh, []string{"-f", script.Name()})
+}
@@ -0,0 +1,277 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNetsh replaces the netsh runners for the duration of the test.
+type fakeNetsh struct {
+	mutex   sync.Mutex
+	scripts [][][]string
+	single  [][]string
+	// fail returns the error for a single command.
+	fail func(args []string) error
+}
+
+func newFakeNetsh(t *testing.T) *fakeNetsh {
+	fake := &fakeNetsh{fail: func([]string) error { return nil }}
+	oldNetsh, oldScript, oldOutput := runNetsh, runNetshScript, runNetshOutput
+	oldDelay, oldRetryDelay := batchDelay, netshRetryDelay
+	t.Cleanup(func() {
+		runNetsh, runNetshScript, runNetshOutput = oldNetsh, oldScript, oldOutput
+		batchDelay, netshRetryDelay = oldDelay, oldRetryDelay
+	})
+	runNetshOutput = func([]string) (string, error) { return "", nil }
+	batchDelay = 20 * time.Millisecond
+	netshRetryDelay = time.Millisecond
+	runNetsh = func(args []string) error {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		fake.single = append(fake.single, args)
+		return fake.fail(args)
+	}
+	runNetshScript = func(commands [][]string) error {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		fake.scripts = append(fake.scripts, commands)
+		for _, args := range commands {
+			if err := fake.fail(args); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fake
+}
+
+func TestBatcherCombinesCommands(t *testing.T) {
+	fake := newFakeNetsh(t)
+	var b batcher
+	var wg sync.WaitGroup
+	for _, port := range []string{"80", "443", "8080"} {
+		wg.Add(1)
+		go func(port string) {
+			defer wg.Done()
+			if err := b.submit([][]string{{"add", port}}); err != nil {
+				t.Errorf("unexpected error for port %s: %v", port, err)
+			}
+		}(port)
+	}
+	wg.Wait()
+	if len(fake.scripts) != 1 || len(fake.scripts[0]) != 3 {
+		t.Fatalf("expected a single script with 3 commands, got %v", fake.scripts)
+	}
+	if len(fake.single) != 0 {
+		t.Errorf("expected no individual commands, got %v", fake.single)
+	}
+}
+
+func TestBatcherSingleCommand(t *testing.T) {
+	fake := newFakeNetsh(t)
+	var b batcher
+	if err := b.submit([][]string{{"add", "80"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.scripts) != 0 || !reflect.DeepEqual(fake.single, [][]string{{"add", "80"}}) {
+		t.Errorf("expected the command to be run directly, got scripts %v and commands %v", fake.scripts, fake.single)
+	}
+}
+
+func TestBatcherAttributesFailures(t *testing.T) {
+	fake := newFakeNetsh(t)
+	fake.fail = func(args []string) error {
+		if args[1] == "443" {
+			return errors.New("permanent failure")
+		}
+		return nil
+	}
+	var b batcher
+	results := make(map[string]error)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, port := range []string{"80", "443"} {
+		wg.Add(1)
+		go func(port string) {
+			defer wg.Done()
+			err := b.submit([][]string{{"add", port}})
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[port] = err
+		}(port)
+	}
+	wg.Wait()
+	if results["80"] != nil {
+		t.Errorf("unexpected error for port 80: %v", results["80"])
+	}
+	if results["443"] == nil || !strings.Contains(results["443"].Error(), "permanent failure") {
+		t.Errorf("expected failure for port 443, got %v", results["443"])
+	}
+	var attempts int
+	for _, args := range fake.single {
+		if args[1] == "443" {
+			attempts++
+		}
+	}
+	if attempts != netshRetries+1 {
+		t.Errorf("expected %d attempts for port 443, got %d", netshRetries+1, attempts)
+	}
+}
+
+func TestBatcherRetriesTransientFailures(t *testing.T) {
+	fake := newFakeNetsh(t)
+	failures := 1
+	fake.fail = func([]string) error {
+		if failures > 0 {
+			failures--
+			return errors.New("transient failure")
+		}
+		return nil
+	}
+	var b batcher
+	if err := b.submit([][]string{{"delete", "80"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.single) != 2 {
+		t.Errorf("expected 2 attempts, got %v", fake.single)
+	}
+}
+
+// netshState simulates the port proxies and firewall rules netsh manages.
+type netshState struct {
+	mutex   sync.Mutex
+	proxies map[string]bool
+	rules   map[string]int
+}
+
+// apply runs a port proxy or firewall rule command against the state.
+func (s *netshState) apply(args []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	proxy := netshArg(args, "listenaddress") + " " + netshArg(args, "listenport")
+	switch {
+	case isNetshCommand(args, "interface", "portproxy", "add"):
+		s.proxies[proxy] = true
+	case isNetshCommand(args, "interface", "portproxy", "delete"):
+		if !s.proxies[proxy] {
+			return errors.New("The system cannot find the file specified.")
+		}
+		delete(s.proxies, proxy)
+	case isNetshCommand(args, "advfirewall", "firewall", "add", "rule"):
+		s.rules[netshArg(args, "name")]++
+	case isNetshCommand(args, "advfirewall", "firewall", "delete", "rule"):
+		if s.rules[netshArg(args, "name")] == 0 {
+			return errors.New("No rules match the specified criteria.")
+		}
+		delete(s.rules, netshArg(args, "name"))
+	default:
+		return errors.New("unexpected command")
+	}
+	return nil
+}
+
+// show answers the queries netshDeleted makes.
+func (s *netshState) show(args []string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	switch {
+	case isNetshCommand(args, "advfirewall", "firewall", "show", "rule"):
+		if s.rules[netshArg(args, "name")] == 0 {
+			return "", errors.New("No rules match the specified criteria.")
+		}
+		return "Rule Name: " + netshArg(args, "name"), nil
+	case isNetshCommand(args, "interface", "portproxy", "show"):
+		var output strings.Builder
+		for proxy := range s.proxies {
+			output.WriteString(proxy + " 172.17.0.2 80\n")
+		}
+		return output.String(), nil
+	}
+	return "", errors.New("unexpected command")
+}
+
+func TestBatcherRepeatsScriptCommandsSafely(t *testing.T) {
+	fake := newFakeNetsh(t)
+	state := &netshState{
+		proxies: map[string]bool{"0.0.0.0 443": true},
+		rules:   map[string]int{firewallRuleName("443", "0.0.0.0"): 1},
+	}
+	runNetsh = func(args []string) error {
+		fake.mutex.Lock()
+		fake.single = append(fake.single, args)
+		fake.mutex.Unlock()
+		return state.apply(args)
+	}
+	runNetshOutput = state.show
+	addArgs, err := portProxyAddArgs("80", "0.0.0.0", "172.17.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleteArgs, err := portProxyDeleteArgs("443", "0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := [][]string{
+		addArgs,
+		firewallRuleAddArgs("80", "0.0.0.0"),
+		deleteArgs,
+		firewallRuleDeleteArgs("443", "0.0.0.0"),
+	}
+	// The script applies every command, but still reports a failure.
+	runNetshScript = func(commands [][]string) error {
+		for _, args := range commands {
+			if err := state.apply(args); err != nil {
+				return err
+			}
+		}
+		return errors.New("netsh script failed")
+	}
+
+	for i, err := range runNetshCommands(commands) {
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", commands[i], err)
+		}
+	}
+	if !reflect.DeepEqual(state.proxies, map[string]bool{"0.0.0.0 80": true}) {
+		t.Errorf("unexpected port proxies %v", state.proxies)
+	}
+	if !reflect.DeepEqual(state.rules, map[string]int{firewallRuleName("80", "0.0.0.0"): 1}) {
+		t.Errorf("expected a single firewall rule for port 80, got %v", state.rules)
+	}
+}
+
+func TestBatcherReportsFailedDeletes(t *testing.T) {
+	fake := newFakeNetsh(t)
+	fake.fail = func([]string) error { return errors.New("access denied") }
+	runNetshOutput = func(args []string) (string, error) {
+		return "0.0.0.0 443 172.17.0.2 443\n", nil
+	}
+	deleteArgs, err := portProxyDeleteArgs("443", "0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b batcher
+	if err := b.submit([][]string{deleteArgs}); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("expected the delete to fail while the port proxy remains, got %v", err)
+	}
+}
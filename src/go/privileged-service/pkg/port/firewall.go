@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"fmt"
+	"net"
+)
+
+// Port proxies are served by the IP Helper service; the firewall rules only
+// allow inbound connections to it, on the forwarded port and address.  The
+// rules don't apply on public networks, and only admit peers on the local
+// subnet, so that publishing a port doesn't expose it beyond the LAN.
+const (
+	firewallRulePrefix   = "RancherDesktop-PortProxy"
+	firewallRuleProgram  = `%SystemRoot%\system32\svchost.exe`
+	firewallRuleService  = "iphlpsvc"
+	firewallRuleProfiles = "private,domain"
+	firewallRuleRemoteIP = "localsubnet"
+)
+
+// firewallRuleName returns the name of the firewall rule for the port proxy
+// listening on the given port and address.  Names must not contain spaces,
+// as netsh scripts are not quoted.
+func firewallRuleName(listenPort, listenAddr string) string {
+	return fmt.Sprintf("%s-%s-%s", firewallRulePrefix, listenAddr, listenPort)
+}
+
+// firewallRuleLocalIP returns the address to restrict the firewall rule to,
+// or an empty string if no rule is needed (as loopback connections are not
+// filtered).
+func firewallRuleLocalIP(listenAddr string) string {
+	ip := net.ParseIP(listenAddr)
+	switch {
+	case ip == nil, ip.IsLoopback():
+		return ""
+	case ip.IsUnspecified():
+		return "any"
+	}
+	return ip.String()
+}
+
+// firewallRuleAddArgs returns the netsh arguments to allow inbound
+// connections to the port proxy, or nil if no rule is needed.
+func firewallRuleAddArgs(listenPort, listenAddr string) []string {
+	localIP := firewallRuleLocalIP(listenAddr)
+	if localIP == "" {
+		return nil
+	}
+	return []string{
+		"advfirewall",
+		"firewall",
+		"add",
+		"rule",
+		fmt.Sprintf("name=%s", firewallRuleName(listenPort, listenAddr)),
+		"dir=in",
+		"action=allow",
+		"protocol=TCP",
+		fmt.Sprintf("localport=%s", listenPort),
+		fmt.Sprintf("localip=%s", localIP),
+		fmt.Sprintf("remoteip=%s", firewallRuleRemoteIP),
+		fmt.Sprintf("profile=%s", firewallRuleProfiles),
+		fmt.Sprintf("program=%s", firewallRuleProgram),
+		fmt.Sprintf("service=%s", firewallRuleService),
+	}
+}
+
+// firewallRuleDeleteArgs returns the netsh arguments to remove the rules
+// added by firewallRuleAddArgs, or nil if no rule is needed.
+func firewallRuleDeleteArgs(listenPort, listenAddr string) []string {
+	if firewallRuleLocalIP(listenAddr) == "" {
+		return nil
+	}
+	return []string{
+		"advfirewall",
+		"firewall",
+		"delete",
+		"rule",
+		fmt.Sprintf("name=%s", firewallRuleName(listenPort, listenAddr)),
+	}
+}
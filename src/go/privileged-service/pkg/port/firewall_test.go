@@ -0,0 +1,107 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/rancher-sandbox/rancher-desktop-agent/pkg/types"
+)
+
+func TestFirewallRuleArgs(t *testing.T) {
+	if args := firewallRuleAddArgs("80", "127.0.0.1"); args != nil {
+		t.Errorf("expected no rule for a loopback address, got %v", args)
+	}
+	if args := firewallRuleDeleteArgs("80", "::1"); args != nil {
+		t.Errorf("expected no rule for a loopback address, got %v", args)
+	}
+	args := firewallRuleAddArgs("8080", "0.0.0.0")
+	if !reflect.DeepEqual(args[:5], []string{"advfirewall", "firewall", "add", "rule", "name=RancherDesktop-PortProxy-0.0.0.0-8080"}) {
+		t.Errorf("unexpected rule: %v", args)
+	}
+	for _, expected := range []string{"localport=8080", "localip=any", "service=iphlpsvc", "remoteip=localsubnet", "profile=private,domain"} {
+		if !strings.Contains(strings.Join(args, " "), expected) {
+			t.Errorf("expected %q in rule %v", expected, args)
+		}
+	}
+	args = firewallRuleAddArgs("443", "192.168.1.10")
+	if !strings.Contains(strings.Join(args, " "), "localip=192.168.1.10") {
+		t.Errorf("expected the rule to be limited to the listen address, got %v", args)
+	}
+}
+
+func TestProxyManagesFirewallRules(t *testing.T) {
+	fake := newFakeNetsh(t)
+	p := newProxy()
+	port := portProxy{
+		PortMap: nat.PortMap{
+			"80/tcp": []nat.PortBinding{
+				{HostIP: "0.0.0.0", HostPort: "80"},
+				{HostIP: "127.0.0.1", HostPort: "80"},
+			},
+		},
+		ConnectAddrs: []types.ConnectAddrs{{Network: "tcp", Addr: "192.168.0.1/24"}},
+	}
+	countRules := func(verb string) int {
+		count := 0
+		for _, script := range fake.scripts {
+			for _, args := range script {
+				if args[0] == "advfirewall" && args[2] == verb {
+					count++
+				}
+			}
+		}
+		return count
+	}
+
+	if err := p.add(port); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := countRules("add"); count != 1 {
+		t.Errorf("expected 1 firewall rule to be added, got %d", count)
+	}
+	if err := p.removeAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := countRules("delete"); count != 1 {
+		t.Errorf("expected 1 firewall rule to be deleted, got %d", count)
+	}
+}
+
+func TestProxyTracksPortsWhenFirewallFails(t *testing.T) {
+	fake := newFakeNetsh(t)
+	fake.fail = func(args []string) error {
+		if args[0] == "advfirewall" {
+			return ErrPortProxy
+		}
+		return nil
+	}
+	p := newProxy()
+	port := portProxy{
+		PortMap:      nat.PortMap{"80/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "80"}}},
+		ConnectAddrs: []types.ConnectAddrs{{Network: "tcp", Addr: "192.168.0.1/24"}},
+	}
+	if err := p.add(port); err == nil {
+		t.Error("expected the firewall failure to be reported")
+	}
+	if len(p.portMappings) != 1 {
+		t.Errorf("expected the port proxy to be tracked, got %v", p.portMappings)
+	}
+}
@@ -22,12 +22,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"sort"
 	"sync"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/rancher-sandbox/rancher-desktop-agent/pkg/types"
-	"github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service/pkg/command"
 )
 
 const netsh = "netsh"
@@ -39,14 +39,27 @@ type portProxy struct {
 	ConnectAddrs []types.ConnectAddrs
 }
 
+// listener is the address and port a port proxy listens on.
+type listener struct {
+	port, addr string
+}
+
 type proxy struct {
-	portMappings map[string]portProxy
+	// portMappings holds the listeners add created port proxies for, by the
+	// hash of their port mapping.  Reserved ports are skipped, and which
+	// ports are reserved changes over time, so delete must not work that out
+	// again.
+	portMappings map[string][]listener
 	mutex        sync.Mutex
+	// batcher applies the netsh changes for concurrent port events together.
+	batcher batcher
+	// reserved tracks the ports Windows won't let the port proxy listen on.
+	reserved reservedPortsCache
 }
 
 func newProxy() *proxy {
 	return &proxy{
-		portMappings: make(map[string]portProxy),
+		portMappings: make(map[string][]listener),
 	}
 }
 
@@ -62,8 +75,21 @@ func (p *proxy) exec(portMapping types.PortMapping) error {
 }
 
 func (p *proxy) add(port portProxy) error {
+	hash, err := getHash(port)
+	if err != nil {
+		return err
+	}
+	var commands, ruleCommands [][]string
+	var listeners []listener
+	var reservedErrs []error
 	for _, v := range port.PortMap {
 		for _, addr := range v {
+			// Listening on a reserved port fails silently in the port proxy,
+			// so report it instead; the other ports are still forwarded.
+			if err := p.reserved.check(addr.HostPort, addr.HostIP); err != nil {
+				reservedErrs = append(reservedErrs, err)
+				continue
+			}
 			wslIP, err := getConnectAddr(addr.HostIP, port.ConnectAddrs)
 			if err != nil {
 				return err
@@ -72,16 +98,17 @@ func (p *proxy) add(port portProxy) error {
 			if err != nil {
 				return err
 			}
-			err = command.Exec(netsh, args)
-			if err != nil {
-				return err
+			commands = append(commands, args)
+			listeners = append(listeners, listener{port: addr.HostPort, addr: addr.HostIP})
+			if args := firewallRuleAddArgs(addr.HostPort, addr.HostIP); args != nil {
+				ruleCommands = append(ruleCommands, args)
 			}
 		}
 	}
-	hash, err := getHash(port)
-	if err != nil {
-		return err
-	}
+	// The firewall rules are added in the same batch, but failing to add them
+	// (e.g. if a third party firewall replaced Windows Firewall) doesn't stop
+	// the port proxy from being tracked.
+	errs := p.batcher.submitEach(append(commands, ruleCommands...))
 	// Ideally we would want to have the mutex lock around the entire add
 	// function to create an atomic operation for adding netsh and adding
 	// the portMappings cache. However, we don't want the netsh operation
@@ -90,20 +117,50 @@ func (p *proxy) add(port portProxy) error {
 	// attempts to remove an entry that does not exist or double remove an entry
 	// we would ignore the error and move on.
 	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.portMappings[hash] = port
-	return nil
+	created := p.portMappings[hash]
+	for i, l := range listeners {
+		if errs[i] == nil && !slices.Contains(created, l) {
+			created = append(created, l)
+		}
+	}
+	// Track the mapping even if nothing was created, so delete knows that.
+	p.portMappings[hash] = created
+	p.mutex.Unlock()
+	if err := errors.Join(errs[:len(commands)]...); err != nil {
+		return err
+	}
+	if err := errors.Join(errs[len(commands):]...); err != nil {
+		reservedErrs = append(reservedErrs, fmt.Errorf("failed to add firewall rules: %w", err))
+	}
+	return errors.Join(reservedErrs...)
 }
 
 func (p *proxy) delete(port portProxy) error {
-	if err := execNetshDelete(port); err != nil {
+	hash, err := getHash(port)
+	if err != nil {
 		return err
 	}
-
-	hash, err := getHash(port)
+	p.mutex.Lock()
+	listeners, ok := p.portMappings[hash]
+	p.mutex.Unlock()
+	if !ok {
+		// The mapping isn't tracked (e.g. the service restarted since it was
+		// added), so remove any port proxy that may have been created for it;
+		// deleting one that doesn't exist succeeds.
+		for _, v := range port.PortMap {
+			for _, addr := range v {
+				listeners = append(listeners, listener{port: addr.HostPort, addr: addr.HostIP})
+			}
+		}
+	}
+	commands, err := deleteCommands(listeners)
 	if err != nil {
 		return err
 	}
+	if err := p.batcher.submit(commands); err != nil {
+		return err
+	}
+
 	// Ideally we would want to have the mutex lock around the entire delete
 	// function to create an atomic operation for deleting netsh and removing
 	// the portMappings cache. However, we don't want the netsh operation
@@ -119,12 +176,19 @@ func (p *proxy) delete(port portProxy) error {
 
 func (p *proxy) removeAll() error {
 	errs := make([]error, 0)
+	var commands [][]string
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	for _, proxy := range p.portMappings {
-		if err := execNetshDelete(proxy); err != nil {
-			errs = append(errs, fmt.Errorf("deleting portproxy: %+v failed: %w", proxy, err))
+	for _, listeners := range p.portMappings {
+		proxyCommands, err := deleteCommands(listeners)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deleting portproxy: %+v failed: %w", listeners, err))
+			continue
 		}
+		commands = append(commands, proxyCommands...)
+	}
+	if err := p.batcher.submit(commands); err != nil {
+		errs = append(errs, fmt.Errorf("deleting portproxies failed: %w", err))
 	}
 	if len(errs) == 0 {
 		return nil
@@ -132,20 +196,21 @@ func (p *proxy) removeAll() error {
 	return fmt.Errorf("%w: %+v", ErrPortProxy, errs)
 }
 
-func execNetshDelete(port portProxy) error {
-	for _, v := range port.PortMap {
-		for _, addr := range v {
-			args, err := portProxyDeleteArgs(addr.HostPort, addr.HostIP)
-			if err != nil {
-				return err
-			}
-			err = command.Exec(netsh, args)
-			if err != nil {
-				return err
-			}
+// deleteCommands returns the netsh commands to remove the port proxies for
+// the listeners, along with their firewall rules.
+func deleteCommands(listeners []listener) ([][]string, error) {
+	var commands [][]string
+	for _, l := range listeners {
+		args, err := portProxyDeleteArgs(l.port, l.addr)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, args)
+		if args := firewallRuleDeleteArgs(l.port, l.addr); args != nil {
+			commands = append(commands, args)
 		}
 	}
-	return nil
+	return commands, nil
 }
 
 // getConnectedAddr selects an IP address from connectAddrs that is the same
@@ -0,0 +1,179 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service/pkg/command"
+)
+
+// ErrPortReserved is returned when a port can't be forwarded because Windows
+// reserves it.
+var ErrPortReserved = errors.New("port is reserved by Windows")
+
+var (
+	// reservedRangesTTL is how long the reserved port ranges are cached; they
+	// only change at boot, or when Hyper-V or WSL start.
+	reservedRangesTTL = 30 * time.Second
+	// runNetshOutput runs netsh and returns its output; this is a variable
+	// for testing.
+	runNetshOutput = func(args []string) (string, error) { return command.Output(netsh, args) }
+)
+
+// portRange is an inclusive range of ports.
+type portRange struct {
+	start, end int
+}
+
+func (r portRange) contains(port int) bool {
+	return r.start <= port && port <= r.end
+}
+
+func (r portRange) String() string {
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+// reservedPorts describes the TCP ports Windows reserves for an IP version.
+type reservedPorts struct {
+	// excluded are the ranges no other program may bind to; Hyper-V and WSL
+	// reserve these (from the dynamic range) at boot.
+	excluded []portRange
+	// dynamic is the range ephemeral ports are allocated from, if known.
+	dynamic *portRange
+}
+
+// reservedPortsCache caches the reserved ports for each IP version.
+type reservedPortsCache struct {
+	mutex     sync.Mutex
+	ports     map[bool]*reservedPorts
+	fetchedAt map[bool]time.Time
+}
+
+// get returns the reserved ports for the IP version, querying netsh if the
+// cached information is too old.
+func (c *reservedPortsCache) get(ipv4 bool) (*reservedPorts, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ports == nil {
+		c.ports = make(map[bool]*reservedPorts)
+		c.fetchedAt = make(map[bool]time.Time)
+	}
+	if ports, ok := c.ports[ipv4]; ok && time.Since(c.fetchedAt[ipv4]) < reservedRangesTTL {
+		return ports, nil
+	}
+	ports, err := queryReservedPorts(ipv4)
+	if err != nil {
+		return nil, err
+	}
+	c.ports[ipv4] = ports
+	c.fetchedAt[ipv4] = time.Now()
+	return ports, nil
+}
+
+// check returns an error wrapping ErrPortReserved if the port on the given
+// listen address can't be used.  Failing to find out is not an error, as the
+// port may well be usable.
+func (c *reservedPortsCache) check(listenPort, listenAddr string) error {
+	port, err := strconv.Atoi(listenPort)
+	if err != nil {
+		return nil
+	}
+	ipv4, err := isIPv4(listenAddr)
+	if err != nil {
+		return nil
+	}
+	ports, err := c.get(ipv4)
+	if err != nil {
+		return nil
+	}
+	for _, excluded := range ports.excluded {
+		if excluded.contains(port) {
+			message := fmt.Sprintf("%s:%d is in the excluded port range %s", listenAddr, port, excluded)
+			if ports.dynamic != nil {
+				message += fmt.Sprintf(" (Hyper-V and WSL reserve ranges from the dynamic ports %s at boot, so these change on restart)", ports.dynamic)
+			}
+			return fmt.Errorf("%w: %s; use a port outside of it", ErrPortReserved, message)
+		}
+	}
+	return nil
+}
+
+// queryReservedPorts asks netsh for the reserved TCP ports.
+func queryReservedPorts(ipv4 bool) (*reservedPorts, error) {
+	version := "ipv6"
+	if ipv4 {
+		version = "ipv4"
+	}
+	output, err := runNetshOutput([]string{"interface", version, "show", "excludedportrange", "protocol=tcp"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded port ranges: %w", err)
+	}
+	result := &reservedPorts{excluded: parseExcludedPortRanges(output)}
+	// The dynamic range is only used to explain the exclusions.
+	if output, err := runNetshOutput([]string{"interface", version, "show", "dynamicportrange", "protocol=tcp"}); err == nil {
+		result.dynamic = parseDynamicPortRange(output)
+	}
+	return result, nil
+}
+
+// excludedRangePattern matches a line of the table of excluded port ranges;
+// the headings are localized, so only the numbers are matched.
+var excludedRangePattern = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s*\*?\s*$`)
+
+// parseExcludedPortRanges parses the output of
+// `netsh interface ipv4 show excludedportrange`.
+func parseExcludedPortRanges(output string) []portRange {
+	var ranges []portRange
+	for _, line := range regexp.MustCompile(`\r?\n`).Split(output, -1) {
+		match := excludedRangePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		start, err1 := strconv.Atoi(match[1])
+		end, err2 := strconv.Atoi(match[2])
+		if err1 == nil && err2 == nil && start <= end {
+			ranges = append(ranges, portRange{start: start, end: end})
+		}
+	}
+	return ranges
+}
+
+// dynamicRangePattern matches the values of the output of
+// `netsh interface ipv4 show dynamicportrange`, which are the start port and
+// the number of ports, in that order.
+var dynamicRangePattern = regexp.MustCompile(`:\s*(\d+)`)
+
+// parseDynamicPortRange parses the output of
+// `netsh interface ipv4 show dynamicportrange`.
+func parseDynamicPortRange(output string) *portRange {
+	matches := dynamicRangePattern.FindAllStringSubmatch(output, -1)
+	if len(matches) < 2 {
+		return nil
+	}
+	start, err1 := strconv.Atoi(matches[0][1])
+	count, err2 := strconv.Atoi(matches[1][1])
+	if err1 != nil || err2 != nil || count < 1 {
+		return nil
+	}
+	return &portRange{start: start, end: start + count - 1}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package port
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/rancher-sandbox/rancher-desktop-agent/pkg/types"
+)
+
+const excludedPortRangeOutput = "\r\n" +
+	"Protocol tcp Port Exclusion Ranges\r\n" +
+	"\r\n" +
+	"Start Port    End Port\r\n" +
+	"----------    --------\r\n" +
+	"      5357        5357\r\n" +
+	"     50000       50059     *\r\n" +
+	"     50300       50399\r\n" +
+	"\r\n" +
+	"* - Administered port exclusions.\r\n"
+
+const dynamicPortRangeOutput = "\r\n" +
+	"Protocol tcp Dynamic Port Range\r\n" +
+	"---------------------------------\r\n" +
+	"Start Port      : 49152\r\n" +
+	"Number of Ports : 16384\r\n"
+
+func TestParseExcludedPortRanges(t *testing.T) {
+	expected := []portRange{{5357, 5357}, {50000, 50059}, {50300, 50399}}
+	if actual := parseExcludedPortRanges(excludedPortRangeOutput); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := parseExcludedPortRanges(""); len(actual) != 0 {
+		t.Errorf("expected no ranges, got %v", actual)
+	}
+}
+
+func TestParseDynamicPortRange(t *testing.T) {
+	actual := parseDynamicPortRange(dynamicPortRangeOutput)
+	if actual == nil || *actual != (portRange{49152, 65535}) {
+		t.Errorf("expected 49152-65535, got %v", actual)
+	}
+	if actual := parseDynamicPortRange("garbage"); actual != nil {
+		t.Errorf("expected no range, got %v", actual)
+	}
+}
+
+func TestProxySkipsReservedPorts(t *testing.T) {
+	fake := newFakeNetsh(t)
+	runNetshOutput = func(args []string) (string, error) {
+		if strings.Contains(strings.Join(args, " "), "dynamicportrange") {
+			return dynamicPortRangeOutput, nil
+		}
+		return excludedPortRangeOutput, nil
+	}
+	p := newProxy()
+	port := portProxy{
+		PortMap: nat.PortMap{
+			"80/tcp":    []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "80"}},
+			"50001/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "50001"}},
+		},
+		ConnectAddrs: []types.ConnectAddrs{{Network: "tcp", Addr: "192.168.0.1/24"}},
+	}
+	err := p.add(port)
+	if !errors.Is(err, ErrPortReserved) {
+		t.Fatalf("expected a reserved port error, got %v", err)
+	}
+	for _, expected := range []string{"127.0.0.1:50001", "50000-50059", "49152-65535"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to mention %q, got %v", expected, err)
+		}
+	}
+	commands := fake.single
+	for _, script := range fake.scripts {
+		commands = append(commands, script...)
+	}
+	for _, args := range commands {
+		if strings.Contains(strings.Join(args, " "), "50001") {
+			t.Errorf("unexpected command for reserved port: %v", args)
+		}
+	}
+	if len(commands) == 0 {
+		t.Error("expected the unreserved port to be forwarded")
+	}
+	if len(p.portMappings) != 1 {
+		t.Errorf("expected the port proxy to be tracked, got %v", p.portMappings)
+	}
+	if err := p.delete(port); err != nil {
+		t.Errorf("unexpected error deleting: %v", err)
+	}
+}
+
+func TestReservedPortsCheckIgnoresFailures(t *testing.T) {
+	newFakeNetsh(t)
+	runNetshOutput = func([]string) (string, error) { return "", errors.New("netsh failed") }
+	var c reservedPortsCache
+	if err := c.check("50001", "127.0.0.1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProxyDeletesTheProxiesItCreated(t *testing.T) {
+	fake := newFakeNetsh(t)
+	reserved := "50001"
+	runNetshOutput = func(args []string) (string, error) {
+		if strings.Contains(strings.Join(args, " "), "dynamicportrange") {
+			return dynamicPortRangeOutput, nil
+		}
+		return "Start Port    End Port\r\n" +
+			"----------    --------\r\n" +
+			"     " + reserved + "       " + reserved + "\r\n", nil
+	}
+	p := newProxy()
+	port := portProxy{
+		PortMap: nat.PortMap{
+			"50001/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "50001"}},
+			"50002/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "50002"}},
+		},
+		ConnectAddrs: []types.ConnectAddrs{{Network: "tcp", Addr: "192.168.0.1/24"}},
+	}
+	if err := p.add(port); !errors.Is(err, ErrPortReserved) {
+		t.Fatalf("expected a reserved port error, got %v", err)
+	}
+
+	// Which ports are reserved changes (e.g. when WSL starts); the port
+	// proxies to delete are those that were created.
+	reserved = "50002"
+	p.reserved.ports = nil
+	fake.scripts, fake.single = nil, nil
+	if err := p.delete(port); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+	commands := fake.single
+	for _, script := range fake.scripts {
+		commands = append(commands, script...)
+	}
+	var deleted []string
+	for _, args := range commands {
+		deleted = append(deleted, strings.Join(args, " "))
+	}
+	if len(deleted) != 2 || !strings.Contains(deleted[0], "listenport=50002") || !strings.Contains(deleted[1], "0.0.0.0-50002") {
+		t.Errorf("expected the port proxy and firewall rule for port 50002 to be deleted, got %v", deleted)
+	}
+	if len(p.portMappings) != 0 {
+		t.Errorf("expected the port proxy to be forgotten, got %v", p.portMappings)
+	}
+}
@@ -0,0 +1,191 @@
+// Package profiling lets the long-running Rancher Desktop helper programs
+// serve runtime profiles (net/http/pprof), so that performance problems can be
+// diagnosed on the machines where they happen, e.g. with
+// `rdctl debug profile`.
+//
+// It is off unless DirEnvVar is set; the variable is inherited by the helpers
+// a program launches.  Each program then listens on a Unix domain socket named
+// after it and its process ID in that directory, which only the user can
+// reach; nothing is ever served over the network.
+package profiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// DirEnvVar names the directory holding the profiling sockets.
+const DirEnvVar = "RD_PPROF_DIR"
+
+const socketSuffix = ".sock"
+
+// Profiles that can be fetched; "profile" is the CPU profile, and "trace" the
+// execution trace.
+var Profiles = []string{"profile", "heap", "allocs", "goroutine", "block", "mutex", "threadcreate", "trace"}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// dialTimeout bounds how long to wait for a component to accept a connection
+// when checking that it's still running.
+const dialTimeout = time.Second
+
+// SocketPath returns the socket that the component, as returned by Components,
+// serves profiles on.
+func SocketPath(dir, component string) string {
+	return filepath.Join(dir, component+socketSuffix)
+}
+
+// componentName returns the name of the component for the given process of a
+// program.  The program name may contain spaces, e.g. a command path.  The
+// process ID tells apart several instances of the same program, such as the
+// docker proxies of different WSL distributions.
+func componentName(program string, pid int) string {
+	return fmt.Sprintf("%s.%d", strings.Join(strings.Fields(program), "-"), pid)
+}
+
+// Start serves profiles for the program if DirEnvVar is set.  Closing the
+// result stops serving.
+func Start(program string) (io.Closer, error) {
+	dir := os.Getenv(DirEnvVar)
+	if dir == "" {
+		return nopCloser{}, nil
+	}
+	return Serve(dir, program)
+}
+
+// Serve serves profiles for the current process of the program on its socket
+// in dir.
+func Serve(dir, program string) (io.Closer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create profiling directory: %w", err)
+	}
+	socket := SocketPath(dir, componentName(program, os.Getpid()))
+	// Remove the socket left behind by an earlier process with the same ID that
+	// didn't exit cleanly.
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale profiling socket: %w", err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for profiling requests: %w", err)
+	}
+	if err := os.Chmod(socket, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to restrict access to the profiling socket: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return server, nil
+}
+
+// Components returns the names of the components serving profiles in dir, in
+// the form <program>.<pid>.  Sockets left behind by processes that didn't exit
+// cleanly are removed.
+func Components(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiling sockets: %w", err)
+	}
+	var components []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), socketSuffix)
+		if !ok {
+			continue
+		}
+		conn, err := net.DialTimeout("unix", filepath.Join(dir, entry.Name()), dialTimeout)
+		if err != nil {
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				_ = os.Remove(filepath.Join(dir, entry.Name()))
+			}
+			continue
+		}
+		_ = conn.Close()
+		components = append(components, name)
+	}
+	sort.Strings(components)
+	return components, nil
+}
+
+// Resolve returns the component that name refers to: either a component as
+// returned by Components, or a program with a single process serving profiles.
+func Resolve(dir, name string) (string, error) {
+	components, err := Components(dir)
+	if err != nil {
+		return "", err
+	}
+	program := strings.Join(strings.Fields(name), "-")
+	var matches []string
+	for _, component := range components {
+		if component == name {
+			return component, nil
+		}
+		if strings.TrimRightFunc(component, unicode.IsDigit) == program+"." {
+			matches = append(matches, component)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s is not serving profiles (is it running with $%s set?)", name, DirEnvVar)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%s has several processes serving profiles; pick one of %s", name, strings.Join(matches, ", "))
+}
+
+// Fetch writes the named profile of the component to w.  The CPU profile and
+// the execution trace are collected for the given duration; the others are
+// snapshots.
+func Fetch(ctx context.Context, dir, component, profile string, duration time.Duration, w io.Writer) error {
+	socket := SocketPath(dir, component)
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}}
+	query := url.Values{}
+	if profile == "profile" || profile == "trace" {
+		query.Set("seconds", fmt.Sprint(int(duration.Seconds())))
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/debug/pprof/"+url.PathEscape(profile)+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s (is it running with $%s set?): %w", component, DirEnvVar, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("failed to get %s profile of %s: %s: %s", profile, component, response.Status, strings.TrimSpace(string(message)))
+	}
+	_, err = io.Copy(w, response.Body)
+	return err
+}
@@ -0,0 +1,114 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	closer, err := Serve(dir, "wsl-helper docker-proxy serve")
+	if err != nil {
+		t.Fatalf("Failed to serve profiles: %s", err)
+	}
+
+	components, err := Components(dir)
+	if err != nil {
+		t.Fatalf("Failed to list components: %s", err)
+	}
+	if expected := []string{fmt.Sprintf("wsl-helper-docker-proxy-serve.%d", os.Getpid())}; !reflect.DeepEqual(components, expected) {
+		t.Errorf("Expected components %v, got %v", expected, components)
+	}
+
+	var buf bytes.Buffer
+	if err := Fetch(context.Background(), dir, components[0], "heap", time.Second, &buf); err != nil {
+		t.Errorf("Failed to fetch the heap profile: %s", err)
+	} else if buf.Len() == 0 {
+		t.Error("The heap profile is empty")
+	}
+	if err := Fetch(context.Background(), dir, components[0], "no-such-profile", time.Second, &buf); err == nil {
+		t.Error("Fetching an unknown profile must fail")
+	}
+
+	if err := closer.Close(); err != nil {
+		t.Errorf("Failed to stop serving profiles: %s", err)
+	}
+	if _, err := os.Stat(SocketPath(dir, components[0])); !os.IsNotExist(err) {
+		t.Errorf("The socket must be removed when serving stops: %v", err)
+	}
+}
+
+// listen pretends that a process is serving profiles on the socket of the
+// component.
+func listen(t *testing.T, dir, component string) *net.UnixListener {
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: SocketPath(dir, component), Net: "unix"})
+	if err != nil {
+		t.Fatalf("Failed to listen for %s: %s", component, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+func TestComponents(t *testing.T) {
+	dir := t.TempDir()
+	listen(t, dir, "vtunnel-peer.10")
+	listen(t, dir, "wsl-helper-docker-proxy-serve.20")
+	listen(t, dir, "wsl-helper-docker-proxy-serve.21")
+	// A process that exited without removing its socket.
+	stale := listen(t, dir, "rdctl-engine-proxy.30")
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	components, err := Components(dir)
+	if err != nil {
+		t.Fatalf("Failed to list components: %s", err)
+	}
+	expected := []string{"vtunnel-peer.10", "wsl-helper-docker-proxy-serve.20", "wsl-helper-docker-proxy-serve.21"}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("Expected components %v, got %v", expected, components)
+	}
+	if _, err := os.Stat(SocketPath(dir, "rdctl-engine-proxy.30")); !os.IsNotExist(err) {
+		t.Errorf("The stale socket must be removed: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		component string
+		err       string
+	}{
+		{name: "vtunnel-peer.10", component: "vtunnel-peer.10"},
+		{name: "vtunnel peer", component: "vtunnel-peer.10"},
+		{name: "wsl-helper-docker-proxy-serve.21", component: "wsl-helper-docker-proxy-serve.21"},
+		{name: "wsl-helper-docker-proxy-serve", err: "several processes"},
+		{name: "rdctl-engine-proxy", err: "not serving profiles"},
+		{name: "vtunnel", err: "not serving profiles"},
+	}
+	for _, testCase := range testCases {
+		component, err := Resolve(dir, testCase.name)
+		if testCase.err == "" {
+			if err != nil || component != testCase.component {
+				t.Errorf("Expected %q to resolve to %s, got %q (%v)", testCase.name, testCase.component, component, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), testCase.err) {
+			t.Errorf("Expected %q to fail with %q, got %q (%v)", testCase.name, testCase.err, component, err)
+		}
+	}
+}
+
+func TestStartDisabled(t *testing.T) {
+	t.Setenv(DirEnvVar, "")
+	closer, err := Start("test")
+	if err != nil {
+		t.Fatalf("Start must not fail when profiling is off: %s", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
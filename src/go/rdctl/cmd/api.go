@@ -26,6 +26,7 @@ import (
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +39,7 @@ var apiSettings struct {
 // apiCmd represents the api command
 var apiCmd = &cobra.Command{
 	Use:   "api",
-	Short: "Run API endpoints directly",
+	Short: i18n.T("commands.api.short"),
 	Long: `Runs API endpoints directly.
 Default method is PUT if a body or input file is specified, GET otherwise.
 
@@ -101,19 +102,19 @@ func doAPICommand(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		response, err := rdClient.DoRequestWithPayload(apiSettings.Method, endpoint, bytes.NewBuffer(contents))
+		response, err := rdClient.DoRequestWithPayload(cmd.Context(), apiSettings.Method, endpoint, bytes.NewBuffer(contents))
 		result, errorPacket, err = client.ProcessRequestForAPI(response, err)
 	} else if apiSettings.Body != "" {
 		if apiSettings.Method == "" {
 			apiSettings.Method = "PUT"
 		}
-		response, err := rdClient.DoRequestWithPayload(apiSettings.Method, endpoint, bytes.NewBufferString(apiSettings.Body))
+		response, err := rdClient.DoRequestWithPayload(cmd.Context(), apiSettings.Method, endpoint, bytes.NewBufferString(apiSettings.Body))
 		result, errorPacket, err = client.ProcessRequestForAPI(response, err)
 	} else {
 		if apiSettings.Method == "" {
 			apiSettings.Method = "GET"
 		}
-		result, errorPacket, err = client.ProcessRequestForAPI(rdClient.DoRequest(apiSettings.Method, endpoint))
+		result, errorPacket, err = client.ProcessRequestForAPI(rdClient.DoRequest(cmd.Context(), apiSettings.Method, endpoint))
 	}
 	return displayAPICallResult(result, errorPacket, err)
 }
@@ -0,0 +1,176 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/bootstrap"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var bootstrapSettings struct {
+	SettingsFile string
+	Wait         bool
+	Timeout      time.Duration
+}
+
+// bootstrapPollInterval is how often to check whether the application is
+// answering API requests after launching it.
+const bootstrapPollInterval = time.Second
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: i18n.T("commands.bootstrap.short"),
+	Long: `Set up and start Rancher Desktop without any user interaction, for use in
+scripts and CI jobs.
+
+If Rancher Desktop isn't running, it is started without any dialog boxes; on a
+first run this accepts the default PATH and kubeconfig integrations.  The
+settings from the --settings file (in the same format as 'rdctl list-settings')
+and any setting flags are then applied, with the flags taking precedence.  With
+--wait, this only returns once the container engine, and Kubernetes if it is
+enabled, are ready.
+
+Settings given as flags are applied as the application starts; settings that
+are only in the file may restart the backend once it's up.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.NoArgs(cmd, args); err != nil {
+			return err
+		}
+		return doBootstrapCommand(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+	options.UpdateCommonStartAndSetCommands(bootstrapCmd)
+	bootstrapCmd.Flags().StringVar(&bootstrapSettings.SettingsFile, "settings", "", "JSON file with the settings to apply")
+	bootstrapCmd.Flags().BoolVar(&bootstrapSettings.Wait, "wait", false, "wait for the container engine and Kubernetes to be ready")
+	bootstrapCmd.Flags().DurationVar(&bootstrapSettings.Timeout, "timeout", 0, "give up after this long (0 to wait forever)")
+	bootstrapCmd.Flags().StringVarP(&applicationPath, "path", "p", "", "path to main executable")
+}
+
+func doBootstrapCommand(cmd *cobra.Command) error {
+	fileSettings, err := readBootstrapSettings(bootstrapSettings.SettingsFile)
+	if err != nil {
+		return err
+	}
+	flagSettings, err := options.UpdateFieldsForJSON(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	ctx := cmd.Context()
+	if bootstrapSettings.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, bootstrapSettings.Timeout)
+		defer cancel()
+	}
+
+	if _, err := getListSettings(ctx); err != nil {
+		noModalDialogs = true
+		if err := doStartCommand(cmd); err != nil {
+			return err
+		}
+	} else if applicationPath != "" {
+		return fmt.Errorf("--path %q specified but Rancher Desktop is already running", applicationPath)
+	}
+	rdClient, currentSettings, err := waitForServer(ctx)
+	if err != nil {
+		return err
+	}
+
+	restarting := false
+	if flagSettings != nil {
+		if err := bootstrap.MergeSettings(fileSettings, flagSettings); err != nil {
+			return err
+		}
+	}
+	if len(fileSettings) > 0 {
+		if _, ok := fileSettings["version"]; !ok {
+			// The API requires a version; use that of the current settings.
+			var current struct {
+				Version json.RawMessage `json:"version"`
+			}
+			if err := json.Unmarshal(currentSettings, &current); err != nil {
+				return fmt.Errorf("failed to parse current settings: %w", err)
+			}
+			fileSettings["version"] = current.Version
+		}
+		var message string
+		message, restarting, err = bootstrap.ApplySettings(ctx, rdClient, fileSettings)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Status: %s.\n", message)
+	}
+
+	if !bootstrapSettings.Wait {
+		return nil
+	}
+	return bootstrap.WaitForBackend(ctx, rdClient, restarting)
+}
+
+// readBootstrapSettings reads the settings file, if one was given.
+func readBootstrapSettings(path string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if path == "" {
+		return settings, nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if err := json.Unmarshal(contents, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// waitForServer waits for a newly launched application to answer API
+// requests, returning a client along with the current settings.
+func waitForServer(ctx context.Context) (*client.RDClientImpl, []byte, error) {
+	for {
+		connectionInfo, err := config.GetConnectionInfo(false)
+		if err == nil {
+			rdClient := client.NewRDClient(connectionInfo)
+			response, requestErr := rdClient.DoRequest(ctx, "GET", client.VersionCommand("", "settings"))
+			var settings []byte
+			settings, err = client.ProcessRequestForUtility(response, requestErr)
+			if err == nil {
+				return rdClient, settings, nil
+			}
+		}
+		logrus.Debugf("Waiting for Rancher Desktop to respond: %s", err)
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("Rancher Desktop did not respond: %w", ctx.Err())
+		case <-time.After(bootstrapPollInterval):
+		}
+	}
+}
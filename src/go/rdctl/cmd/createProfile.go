@@ -17,13 +17,18 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/plist"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/reg"
 	"github.com/spf13/cobra"
@@ -31,12 +36,11 @@ import (
 
 const plistFormat = "plist"
 const regFormat = "reg"
-const defaultsType = "defaults"
-const lockedType = "locked"
 
 // The distinction between 'system' and 'user' is only needed for registry output
 // because it gets written into the generated .reg data, while on macOS the distinction
 // is based on which directory the generated file is placed in (and what name it's given).
+// These are accepted as aliases for "hklm" and "hkcu" respectively.
 const systemHive = "system"
 const userHive = "user"
 
@@ -48,21 +52,23 @@ var outputSettingsFlags struct {
 var InputFile string
 var JSONBody string
 var UseCurrentSettings bool
+var WriteProfile bool
 
 // createProfileCmd represents the createProfile command
 var createProfileCmd = &cobra.Command{
 	Use:   "create-profile",
-	Short: "Generate a deployment profile in either macOS plist or Windows registry format",
+	Short: i18n.T("commands.createProfile.short"),
 	Long: `Use this to generate deployment profiles for Rancher Desktop settings.
 You can either convert the current listings in operation, or
 specify a JSON snippet, and convert that to the desired target.
-macOS plist files can be placed in the appropriate directory, while ".reg" files
-can be imported into the Windows registry using the "eg import FILE" command.`,
+macOS plist files can be placed in the appropriate directory (or written there
+directly with "--write"), while ".reg" files can be imported into the Windows
+registry using the "reg import FILE" command.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.NoArgs(cmd, args); err != nil {
 			return err
 		}
-		result, err := createProfile()
+		result, err := createProfile(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -74,14 +80,15 @@ can be imported into the Windows registry using the "eg import FILE" command.`,
 func init() {
 	rootCmd.AddCommand(createProfileCmd)
 	createProfileCmd.Flags().StringVar(&outputSettingsFlags.Format, "output", "", fmt.Sprintf("output format: %s|%s", plistFormat, regFormat))
-	createProfileCmd.Flags().StringVar(&outputSettingsFlags.RegistryHive, "hive", "", fmt.Sprintf(`registry hive: %s|%s (default "%s")`, reg.HklmRegistryHive, reg.HkcuRegistryHive, reg.HklmRegistryHive))
-	createProfileCmd.Flags().StringVar(&outputSettingsFlags.RegistryProfileType, "type", "", fmt.Sprintf(`registry section: %s|%s (default "%s")`, defaultsType, lockedType, defaultsType))
+	createProfileCmd.Flags().StringVar(&outputSettingsFlags.RegistryHive, "hive", "", fmt.Sprintf(`registry hive: %s (or %s)|%s (or %s) (default "%s")`, reg.HklmRegistryHive, systemHive, reg.HkcuRegistryHive, userHive, reg.HklmRegistryHive))
+	createProfileCmd.Flags().StringVar(&outputSettingsFlags.RegistryProfileType, "type", "", fmt.Sprintf(`registry section: %s|%s|%s (default "%s")`, reg.DefaultsProfileType, reg.LockedProfileType, reg.BothProfileTypes, reg.DefaultsProfileType))
 	createProfileCmd.Flags().StringVar(&InputFile, "input", "", "File containing a JSON document (- for standard input)")
 	createProfileCmd.Flags().StringVarP(&JSONBody, "body", "b", "", "Command-line option containing a JSON document")
 	createProfileCmd.Flags().BoolVar(&UseCurrentSettings, "from-settings", false, "Use current settings")
+	createProfileCmd.Flags().BoolVar(&WriteProfile, "write", false, fmt.Sprintf(`Write a plist profile into the system or user profile directory selected by "--hive %s|%s"`, systemHive, userHive))
 }
 
-func createProfile() (string, error) {
+func createProfile(ctx context.Context) (string, error) {
 	err := validateProfileFormatFlags()
 	if err != nil {
 		return "", err
@@ -106,7 +113,7 @@ func createProfile() (string, error) {
 			return "", fmt.Errorf("failed to get connection info: %w", err)
 		}
 		rdClient := client.NewRDClient(connectionInfo)
-		response, err := rdClient.DoRequest("GET", client.VersionCommand("", "settings"))
+		response, err := rdClient.DoRequest(ctx, "GET", client.VersionCommand("", "settings"))
 		output, err = client.ProcessRequestForUtility(response, err)
 	}
 	if err != nil {
@@ -119,7 +126,11 @@ func createProfile() (string, error) {
 		}
 		return strings.Join(lines, "\n"), nil
 	} else if outputSettingsFlags.Format == plistFormat {
-		return plist.JsonToPlist(string(output))
+		result, err := plist.JsonToPlist(string(output))
+		if err != nil || !WriteProfile {
+			return result, err
+		}
+		return writePlistProfile(result)
 	}
 	return "", fmt.Errorf(`internal error: expecting an output format of %q or %q, got %q`, regFormat, plistFormat, outputSettingsFlags.Format)
 }
@@ -139,27 +150,75 @@ func validateProfileFormatFlags() error {
 	}
 
 	if outputSettingsFlags.Format == plistFormat {
-		if outputSettingsFlags.RegistryHive != "" || outputSettingsFlags.RegistryProfileType != "" {
-			return fmt.Errorf(`registry hive and type can't be specified with "plist"`)
+		if !WriteProfile {
+			if outputSettingsFlags.RegistryHive != "" || outputSettingsFlags.RegistryProfileType != "" {
+				return fmt.Errorf(`registry hive and type can't be specified with "plist" unless "--write" is specified`)
+			}
+			return nil
+		}
+		if runtime.GOOS != "darwin" {
+			return fmt.Errorf(`"--write" is only supported on macOS`)
+		}
+		// When writing plist files, the hive selects the profile directory.
+		switch strings.ToLower(outputSettingsFlags.RegistryHive) {
+		case systemHive, "":
+			outputSettingsFlags.RegistryHive = systemHive
+		case userHive:
+			outputSettingsFlags.RegistryHive = userHive
+		default:
+			return fmt.Errorf("invalid profile hive of %q specified, must be %q or %q", outputSettingsFlags.RegistryHive, systemHive, userHive)
+		}
+	} else {
+		if WriteProfile {
+			return fmt.Errorf(`"--write" can only be specified with "plist"`)
+		}
+		switch strings.ToLower(outputSettingsFlags.RegistryHive) {
+		case reg.HklmRegistryHive, reg.HkcuRegistryHive:
+			outputSettingsFlags.RegistryHive = strings.ToLower(outputSettingsFlags.RegistryHive)
+		case systemHive, "":
+			outputSettingsFlags.RegistryHive = reg.HklmRegistryHive
+		case userHive:
+			outputSettingsFlags.RegistryHive = reg.HkcuRegistryHive
+		default:
+			return fmt.Errorf("invalid registry hive of %q specified, must be %q (or %q) or %q (or %q)", outputSettingsFlags.RegistryHive, reg.HklmRegistryHive, systemHive, reg.HkcuRegistryHive, userHive)
 		}
-		return nil
-	}
-
-	switch strings.ToLower(outputSettingsFlags.RegistryHive) {
-	case reg.HklmRegistryHive, reg.HkcuRegistryHive:
-		outputSettingsFlags.RegistryHive = strings.ToLower(outputSettingsFlags.RegistryHive)
-	case "":
-		outputSettingsFlags.RegistryHive = reg.HklmRegistryHive
-	default:
-		return fmt.Errorf("invalid registry hive of %q specified, must be %q or %q", outputSettingsFlags.RegistryHive, systemHive, userHive)
 	}
 	switch strings.ToLower(outputSettingsFlags.RegistryProfileType) {
-	case defaultsType, lockedType:
+	case reg.DefaultsProfileType, reg.LockedProfileType, reg.BothProfileTypes:
 		outputSettingsFlags.RegistryProfileType = strings.ToLower(outputSettingsFlags.RegistryProfileType)
 	case "":
-		outputSettingsFlags.RegistryProfileType = defaultsType
+		outputSettingsFlags.RegistryProfileType = reg.DefaultsProfileType
 	default:
-		return fmt.Errorf("invalid registry type of %q specified, must be %q or %q", outputSettingsFlags.RegistryProfileType, defaultsType, lockedType)
+		return fmt.Errorf("invalid registry type of %q specified, must be %q, %q, or %q", outputSettingsFlags.RegistryProfileType, reg.DefaultsProfileType, reg.LockedProfileType, reg.BothProfileTypes)
 	}
 	return nil
 }
+
+// writePlistProfile writes the generated plist into the deployment profile
+// directory selected by the hive, under the name(s) for the selected type.
+func writePlistProfile(contents string) (string, error) {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return "", fmt.Errorf("failed to get paths: %w", err)
+	}
+	profileDir := appPaths.DeploymentProfileSystem
+	if outputSettingsFlags.RegistryHive == userHive {
+		profileDir = appPaths.DeploymentProfileUser
+	}
+	profileTypes := []string{outputSettingsFlags.RegistryProfileType}
+	if outputSettingsFlags.RegistryProfileType == reg.BothProfileTypes {
+		profileTypes = []string{reg.DefaultsProfileType, reg.LockedProfileType}
+	}
+	if err := os.MkdirAll(profileDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create profile directory %q: %w", profileDir, err)
+	}
+	var messages []string
+	for _, profileType := range profileTypes {
+		profilePath := filepath.Join(profileDir, plist.ProfileFileName(profileType))
+		if err := os.WriteFile(profilePath, []byte(contents), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write profile %q: %w", profilePath, err)
+		}
+		messages = append(messages, fmt.Sprintf("Wrote %s", profilePath))
+	}
+	return strings.Join(messages, "\n"), nil
+}
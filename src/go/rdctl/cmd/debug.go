@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: i18n.T("commands.debug.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/profiling"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var debugProfileSettings struct {
+	dir      string
+	profile  string
+	duration time.Duration
+	output   string
+}
+
+var debugProfileCmd = &cobra.Command{
+	Use:   "profile [<component>]",
+	Short: i18n.T("commands.debug.profile.short"),
+	Long: fmt.Sprintf(`Capture a runtime profile of a running Rancher Desktop helper, to diagnose
+performance problems.  Helpers only serve profiles when $%s names a
+directory for their sockets, so set it before starting Rancher Desktop.  Without
+a component, list the components that serve profiles; each is named after the
+program and its process ID, and the process ID may be left out if the program
+only has one process serving profiles.
+
+The result can be examined with "go tool pprof", or "go tool trace" for
+--type trace.`, profiling.DirEnvVar),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(profiling.Profiles, debugProfileSettings.profile) {
+			return fmt.Errorf("invalid --type %q: must be one of %s", debugProfileSettings.profile, strings.Join(profiling.Profiles, ", "))
+		}
+		if debugProfileSettings.dir == "" {
+			debugProfileSettings.dir = os.Getenv(profiling.DirEnvVar)
+		}
+		if debugProfileSettings.dir == "" {
+			return fmt.Errorf("no profiling directory; specify one with --dir or $%s", profiling.DirEnvVar)
+		}
+		cmd.SilenceUsage = true
+		if len(args) == 0 {
+			return listProfilingComponents()
+		}
+		return captureProfile(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	debugCmd.AddCommand(debugProfileCmd)
+	debugProfileCmd.Flags().StringVar(&debugProfileSettings.dir, "dir", "", fmt.Sprintf("directory holding the profiling sockets (default: $%s)", profiling.DirEnvVar))
+	debugProfileCmd.Flags().StringVar(&debugProfileSettings.profile, "type", "profile", fmt.Sprintf("profile to capture, one of %s; \"profile\" is the CPU profile", strings.Join(profiling.Profiles, ", ")))
+	debugProfileCmd.Flags().DurationVar(&debugProfileSettings.duration, "duration", 30*time.Second, "how long to collect the CPU profile or the trace for")
+	debugProfileCmd.Flags().StringVarP(&debugProfileSettings.output, "output", "o", "", "file to write the profile to (default: <component>-<type>.pprof, or .trace for a trace)")
+}
+
+func listProfilingComponents() error {
+	components, err := profiling.Components(debugProfileSettings.dir)
+	if err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		fmt.Println(i18n.T("debug.noComponents"))
+	}
+	for _, component := range components {
+		fmt.Println(component)
+	}
+	return nil
+}
+
+func captureProfile(ctx context.Context, name string) (err error) {
+	component, err := profiling.Resolve(debugProfileSettings.dir, name)
+	if err != nil {
+		return err
+	}
+	output := debugProfileSettings.output
+	if output == "" {
+		extension := "pprof"
+		if debugProfileSettings.profile == "trace" {
+			extension = "trace"
+		}
+		output = fmt.Sprintf("%s-%s.%s", component, debugProfileSettings.profile, extension)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+		if err != nil {
+			_ = os.Remove(output)
+		}
+	}()
+	if debugProfileSettings.profile == "profile" || debugProfileSettings.profile == "trace" {
+		fmt.Fprintln(os.Stderr, i18n.T("debug.collecting", i18n.Args{"component": component, "duration": debugProfileSettings.duration}))
+	}
+	if err := profiling.Fetch(ctx, debugProfileSettings.dir, component, debugProfileSettings.profile, debugProfileSettings.duration, file); err != nil {
+		return err
+	}
+	fmt.Println(i18n.T("debug.wrote", i18n.Args{"file": output}))
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var diagnosticsJSON bool
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: i18n.T("commands.diagnostics.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(diagnosticsCmd)
+}
+
+func newDiagnosticsClient() (*client.RDClientImpl, error) {
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	return client.NewRDClient(connectionInfo), nil
+}
+
+// printDiagnostics shows the results of the checks, as a single JSON document
+// with --json, or as a table otherwise.
+func printDiagnostics(results *client.DiagnosticsResults) error {
+	if diagnosticsJSON {
+		jsonBuffer, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonBuffer))
+		return nil
+	}
+	if len(results.Checks) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("diagnostics.noResults"))
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+	fmt.Fprintf(writer, "ID\tCATEGORY\tSTATUS\tDESCRIPTION\n")
+	for _, check := range results.Checks {
+		status := "passed"
+		if !check.Passed {
+			status = "FAILED"
+		}
+		if check.Mute {
+			status += " (muted)"
+		}
+		description, _, _ := strings.Cut(check.Description, "\n")
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", check.ID, check.Category, status, description)
+	}
+	return writer.Flush()
+}
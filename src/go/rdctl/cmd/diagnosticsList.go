@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var diagnosticsListSettings struct {
+	client.DiagnosticsQuery
+	Failed bool
+}
+
+var diagnosticsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   i18n.T("commands.diagnostics.list.short"),
+	Long: `Show the results of the last time the diagnostics checks were run, without
+running them again.  Use --json for machine-readable output.
+
+The checks are listed in the order they are run unless --sort is given; use
+--limit and --offset to page through them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return listDiagnostics(cmd.Context())
+	},
+}
+
+func init() {
+	diagnosticsCmd.AddCommand(diagnosticsListCmd)
+	diagnosticsListCmd.Flags().StringVar(&diagnosticsListSettings.Category, "category", "", "only show checks in this category")
+	diagnosticsListCmd.Flags().StringVar(&diagnosticsListSettings.ID, "id", "", "only show the check with this ID")
+	diagnosticsListCmd.Flags().BoolVar(&diagnosticsListSettings.Failed, "failed", false, "only show the checks that failed")
+	diagnosticsListCmd.Flags().StringVar(&diagnosticsListSettings.Filter, "filter", "", "only show checks with an ID or description containing this text")
+	diagnosticsListCmd.Flags().StringVar(&diagnosticsListSettings.Sort, "sort", "", `sort by "id", "category" or "passed" (prefix with "-" to reverse)`)
+	diagnosticsListCmd.Flags().IntVar(&diagnosticsListSettings.Offset, "offset", 0, "skip this many checks")
+	diagnosticsListCmd.Flags().IntVar(&diagnosticsListSettings.Limit, "limit", 0, "show at most this many checks")
+	diagnosticsListCmd.Flags().BoolVar(&diagnosticsJSON, "json", false, "output json format")
+}
+
+func listDiagnostics(ctx context.Context) error {
+	rdClient, err := newDiagnosticsClient()
+	if err != nil {
+		return err
+	}
+	query := diagnosticsListSettings.DiagnosticsQuery
+	if diagnosticsListSettings.Failed {
+		passed := false
+		query.Passed = &passed
+	}
+	results, err := rdClient.DiagnosticChecks(ctx, query)
+	if err != nil {
+		return err
+	}
+	if err := printDiagnostics(results); err != nil {
+		return err
+	}
+	if shown := query.Offset + len(results.Checks); !diagnosticsJSON && len(results.Checks) > 0 && shown < results.Total {
+		fmt.Fprintln(os.Stderr, i18n.T("diagnostics.partialList", i18n.Args{"shown": len(results.Checks), "total": results.Total, "offset": shown}))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var diagnosticsMuteCmd = &cobra.Command{
+	Use:   "mute <id>...",
+	Short: i18n.T("commands.diagnostics.mute.short"),
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return muteDiagnostics(cmd.Context(), args, true)
+	},
+}
+
+var diagnosticsUnmuteCmd = &cobra.Command{
+	Use:   "unmute <id>...",
+	Short: i18n.T("commands.diagnostics.unmute.short"),
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return muteDiagnostics(cmd.Context(), args, false)
+	},
+}
+
+func init() {
+	diagnosticsCmd.AddCommand(diagnosticsMuteCmd)
+	diagnosticsCmd.AddCommand(diagnosticsUnmuteCmd)
+}
+
+func muteDiagnostics(ctx context.Context, ids []string, mute bool) error {
+	rdClient, err := newDiagnosticsClient()
+	if err != nil {
+		return err
+	}
+	categories, err := rdClient.DiagnosticCategories(ctx)
+	if err != nil {
+		return err
+	}
+	var knownIDs []string
+	for _, category := range categories {
+		categoryIDs, err := rdClient.DiagnosticIDs(ctx, category)
+		if err != nil {
+			return err
+		}
+		knownIDs = append(knownIDs, categoryIDs...)
+	}
+	for _, id := range ids {
+		if !slices.Contains(knownIDs, id) {
+			return fmt.Errorf("unknown diagnostics check %q", id)
+		}
+	}
+	for _, id := range ids {
+		if err := rdClient.MuteDiagnostic(ctx, id, mute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
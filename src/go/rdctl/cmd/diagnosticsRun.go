@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var diagnosticsRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: i18n.T("commands.diagnostics.run.short"),
+	Long: `Run all the diagnostics checks and show their results.  Use --json for
+machine-readable output.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runDiagnostics(cmd.Context())
+	},
+}
+
+func init() {
+	diagnosticsCmd.AddCommand(diagnosticsRunCmd)
+	diagnosticsRunCmd.Flags().BoolVar(&diagnosticsJSON, "json", false, "output json format")
+}
+
+func runDiagnostics(ctx context.Context) error {
+	rdClient, err := newDiagnosticsClient()
+	if err != nil {
+		return err
+	}
+	results, err := rdClient.RunDiagnostics(ctx)
+	if err != nil {
+		return err
+	}
+	return printDiagnostics(results)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var dnsShowJSON bool
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: i18n.T("commands.dns.short"),
+	Long: `Configure where the VM (on Windows) sends DNS queries: by default, to the DNS
+servers of the host.  Upstream servers replace those; per-domain servers take
+precedence for names in their domain (and its subdomains), e.g. for a
+corporate VPN.  To skip the DNS servers pushed by a VPN client instead, use
+"rdctl set --virtual-machine.dns.ignore-vpn".  Changes restart the backend.`,
+}
+
+var dnsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: i18n.T("commands.dns.show.short"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		rdClient, err := newDNSClient()
+		if err != nil {
+			return err
+		}
+		dns, err := getDNSSettings(cmd.Context(), rdClient)
+		if err != nil {
+			return err
+		}
+		if dnsShowJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(dns)
+		}
+		upstream := "(the host's)"
+		if len(dns.UpstreamServers) > 0 {
+			upstream = strings.Join(dns.UpstreamServers, ", ")
+		}
+		fmt.Printf("Upstream servers: %s\n", upstream)
+		fmt.Printf("Ignore VPN servers: %t\n", dns.IgnoreVPN != nil && *dns.IgnoreVPN)
+		fmt.Println("Per-domain servers:")
+		for _, rule := range dns.Domains {
+			domain, server, _ := strings.Cut(rule, "=")
+			fmt.Printf("  %s: %s\n", domain, server)
+		}
+		return nil
+	},
+}
+
+var dnsUpstreamCmd = &cobra.Command{
+	Use:   "upstream [server...]",
+	Short: i18n.T("commands.dns.upstream.short"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, server := range args {
+			if net.ParseIP(server) == nil {
+				return fmt.Errorf("%q is not an IP address", server)
+			}
+		}
+		cmd.SilenceUsage = true
+		return updateDNSSettings(cmd.Context(), func(dns *client.SettingsVirtualMachineDns) error {
+			dns.UpstreamServers = args
+			return nil
+		})
+	},
+}
+
+var dnsAddDomainCmd = &cobra.Command{
+	Use:   "add-domain <domain> <server>",
+	Short: i18n.T("commands.dns.addDomain.short"),
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domain, server := strings.TrimSuffix(args[0], "."), args[1]
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("%q is not an IP address", server)
+		}
+		cmd.SilenceUsage = true
+		return updateDNSSettings(cmd.Context(), func(dns *client.SettingsVirtualMachineDns) error {
+			rule := fmt.Sprintf("%s=%s", domain, server)
+			if slices.Contains(dns.Domains, rule) {
+				return fmt.Errorf("%s is already a DNS server for %s", server, domain)
+			}
+			dns.Domains = append(dns.Domains, rule)
+			return nil
+		})
+	},
+}
+
+var dnsRemoveDomainCmd = &cobra.Command{
+	Use:   "remove-domain <domain>",
+	Short: i18n.T("commands.dns.removeDomain.short"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domain := strings.TrimSuffix(args[0], ".")
+		cmd.SilenceUsage = true
+		return updateDNSSettings(cmd.Context(), func(dns *client.SettingsVirtualMachineDns) error {
+			remaining := slices.DeleteFunc(slices.Clone(dns.Domains), func(rule string) bool {
+				ruleDomain, _, _ := strings.Cut(rule, "=")
+				return strings.EqualFold(ruleDomain, domain)
+			})
+			if len(remaining) == len(dns.Domains) {
+				return fmt.Errorf("no DNS servers are set for %s", domain)
+			}
+			dns.Domains = remaining
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dnsCmd)
+	dnsShowCmd.Flags().BoolVar(&dnsShowJSON, "json", false, "output the settings as JSON")
+	dnsCmd.AddCommand(dnsShowCmd)
+	dnsCmd.AddCommand(dnsUpstreamCmd)
+	dnsCmd.AddCommand(dnsAddDomainCmd)
+	dnsCmd.AddCommand(dnsRemoveDomainCmd)
+}
+
+func newDNSClient() (*client.RDClientImpl, error) {
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	return client.NewRDClient(connectionInfo), nil
+}
+
+// getDNSSettings returns the current DNS settings of the VM.
+func getDNSSettings(ctx context.Context, rdClient *client.RDClientImpl) (*client.SettingsVirtualMachineDns, error) {
+	settings, err := rdClient.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.VirtualMachine == nil || settings.VirtualMachine.Dns == nil {
+		return nil, fmt.Errorf("DNS settings are not supported by this version of Rancher Desktop")
+	}
+	return settings.VirtualMachine.Dns, nil
+}
+
+// updateDNSSettings applies the change to the current DNS servers.
+func updateDNSSettings(ctx context.Context, change func(*client.SettingsVirtualMachineDns) error) error {
+	rdClient, err := newDNSClient()
+	if err != nil {
+		return err
+	}
+	dns, err := getDNSSettings(ctx, rdClient)
+	if err != nil {
+		return err
+	}
+	if err := change(dns); err != nil {
+		return err
+	}
+	result, err := rdClient.SetDNSServers(ctx, dns.UpstreamServers, dns.Domains)
+	if err != nil {
+		return err
+	}
+	if result != "" {
+		fmt.Printf("Status: %s.\n", result)
+	}
+	return nil
+}
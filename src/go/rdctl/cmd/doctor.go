@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var doctorRepair bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: i18n.T("commands.doctor.short"),
+	Long: `Run the diagnostics checks and explain the ones that failed (apart from the
+muted ones), along with possible fixes.  With --repair, also attempt to fix
+the problems that can be repaired automatically.
+
+The command fails if any problems remain.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runDoctor(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorRepair, "repair", false, "attempt to fix the problems that can be repaired automatically")
+}
+
+func runDoctor(ctx context.Context) error {
+	rdClient, err := newDiagnosticsClient()
+	if err != nil {
+		return err
+	}
+	results, err := rdClient.RunDiagnostics(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, check := range results.Checks {
+		if check.Passed || check.Mute {
+			continue
+		}
+		if doctorRepair && check.Repairable {
+			fmt.Printf("Repairing %s...\n", check.ID)
+			repaired, err := rdClient.RepairDiagnostic(ctx, check.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			} else if repaired.Passed {
+				fmt.Printf("Repaired %s.\n\n", check.ID)
+				continue
+			} else {
+				check = *repaired
+			}
+		}
+		failed++
+		printDoctorProblem(check)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("found %d problem(s)", failed)
+	}
+	fmt.Println("No problems found.")
+	return nil
+}
+
+// printDoctorProblem explains a failed check and the ways to fix it.
+func printDoctorProblem(check client.DiagnosticsCheck) {
+	fmt.Printf("%s (%s):\n", check.ID, check.Category)
+	for _, line := range strings.Split(check.Description, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	for _, fix := range check.Fixes {
+		fmt.Printf("  Fix: %s\n", fix.Description)
+	}
+	if check.Repairable && !doctorRepair {
+		fmt.Println("  This can be repaired automatically with `rdctl doctor --repair`.")
+	}
+	if check.Documentation != "" {
+		fmt.Printf("  See %s\n", check.Documentation)
+	}
+	fmt.Println()
+}
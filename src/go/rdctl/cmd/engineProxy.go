@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/profiling"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var engineProxySocket string
+
+var engineProxyCmd = &cobra.Command{
+	Use:   "engine-proxy",
+	Short: i18n.T("commands.engineProxy.short"),
+	Long: `Listen on a local socket (a named pipe on Windows), relaying every
+connection to the container engine through the Rancher Desktop API.  This lets
+tools reach the engine when its socket isn't forwarded to the host, e.g. from
+another machine with --server.  The proxy runs until interrupted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		socket := engineProxySocket
+		if socket == "" {
+			var err error
+			if socket, err = defaultEngineProxySocket(); err != nil {
+				return err
+			}
+		}
+		connectionInfo, err := config.GetConnectionInfo(false)
+		if err != nil {
+			return fmt.Errorf("failed to get connection info: %w", err)
+		}
+		listener, err := client.ListenSocket(socket)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", socket, err)
+		}
+		defer listener.Close()
+		if profilingCloser, err := profiling.Start(cmd.CommandPath()); err != nil {
+			logrus.WithError(err).Warn("Failed to serve profiles")
+		} else {
+			defer profilingCloser.Close()
+		}
+		scheme := "unix://"
+		if runtime.GOOS == "windows" {
+			scheme = "npipe://"
+		}
+		fmt.Fprintln(os.Stderr, i18n.T("engineProxy.listening", i18n.Args{"host": scheme + filepath.ToSlash(socket)}))
+		return client.NewRDClient(connectionInfo).ServeEngineProxy(cmd.Context(), listener)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(engineProxyCmd)
+	engineProxyCmd.Flags().StringVar(&engineProxySocket, "socket", "", "path of the socket (or named pipe) to listen on")
+}
+
+// defaultEngineProxySocket returns where the proxy listens unless told
+// otherwise: a named pipe on Windows, and a socket next to docker.sock
+// elsewhere.
+func defaultEngineProxySocket() (string, error) {
+	if runtime.GOOS == "windows" {
+		return `\\.\pipe\rancher_desktop_engine_proxy`, nil
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return "", fmt.Errorf("failed to get paths: %w", err)
+	}
+	return filepath.Join(appPaths.AltAppHome, "engine-proxy.sock"), nil
+}
@@ -18,19 +18,23 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
+	"errors"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 // extensionCmd represents the extension command
 var extensionCmd = &cobra.Command{
-	Short: "Manage extensions",
+	Short: i18n.T("commands.extension.short"),
 	Long: `rdctl extension - manage installed extensions
 `,
 	Use: "extension [install | uninstall | list] [options...]",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return unknownSubcommand(cmd, args[0])
+		}
 		cmd.SilenceUsage = true
-		return fmt.Errorf("No subcommand given.\n\nUsage: rdctl %s", cmd.Use)
+		return errors.New(i18n.T("extension.noSubcommand", i18n.Args{"usage": cmd.Use}))
 	},
 }
 
@@ -18,23 +18,25 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 // installCmd represents the 'rdctl extensions install' command
 var installCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Install an RDX extension",
+	Short: i18n.T("commands.extension.install.short"),
 	Long: `rdctl extension install [--force] <image-id>
 --force: avoid any interactivity.
 The <image-id> is an image reference, e.g. splatform/epinio-docker-desktop:latest (the tag is optional).`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return installExtension(args)
+		return installExtension(cmd.Context(), args)
 	},
 }
 
@@ -42,7 +44,7 @@ func init() {
 	extensionCmd.AddCommand(installCmd)
 }
 
-func installExtension(args []string) error {
+func installExtension(ctx context.Context, args []string) error {
 	connectionInfo, err := config.GetConnectionInfo(false)
 	if err != nil {
 		return fmt.Errorf("failed to get connection info: %w", err)
@@ -53,7 +55,7 @@ func installExtension(args []string) error {
 	// https://stackoverflow.com/questions/20847357/golang-http-client-always-escaped-the-url
 	// Looks like http.NewRequest(method, url) escapes the URL
 
-	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest("POST", endpoint))
+	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest(ctx, "POST", endpoint))
 	if errorPacket != nil || err != nil {
 		return displayAPICallResult(result, errorPacket, err)
 	}
@@ -19,13 +19,14 @@ limitations under the License.
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
@@ -33,44 +34,43 @@ import (
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List currently installed images",
+	Short:   i18n.T("commands.extension.list.short"),
 	Long:    `List currently installed images.`,
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return listExtensions()
+		return listExtensions(cmd.Context())
 	},
 }
 
+var extensionListFilter string
+
 func init() {
 	extensionCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&extensionListFilter, "filter", "", "only list extensions with an ID containing this text")
 }
 
-func listExtensions() error {
+func listExtensions(ctx context.Context) error {
 	connectionInfo, err := config.GetConnectionInfo(false)
 	if err != nil {
 		return fmt.Errorf("failed to get connection info: %w", err)
 	}
 	rdClient := client.NewRDClient(connectionInfo)
-	endpoint := fmt.Sprintf("/%s/extensions", client.ApiVersion)
-	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest("GET", endpoint))
-	if errorPacket != nil || err != nil {
-		return displayAPICallResult([]byte{}, errorPacket, err)
-	}
-	extensionList := map[string]struct {
-		Version string `json:"version"`
-	}{}
-	err = json.Unmarshal(result, &extensionList)
+	extensionList, _, err := rdClient.ListExtensions(ctx, client.ListOptions{Filter: extensionListFilter})
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal extension list API response: %w", err)
+		return err
 	}
 	if len(extensionList) == 0 {
-		fmt.Println("No extensions are installed.")
+		if extensionListFilter != "" {
+			fmt.Println("No matching extensions are installed.")
+		} else {
+			fmt.Println("No extensions are installed.")
+		}
 		return nil
 	}
 	extensionIDs := make([]string, 0, len(extensionList))
-	for id, info := range extensionList {
-		extensionIDs = append(extensionIDs, fmt.Sprintf("%s:%s", id, info.Version))
+	for _, extension := range extensionList {
+		extensionIDs = append(extensionIDs, fmt.Sprintf("%s:%s", extension.ID, extension.Version))
 	}
 	sort.Slice(extensionIDs, func(i, j int) bool { return strings.ToLower(extensionIDs[i]) < strings.ToLower(extensionIDs[j]) })
 
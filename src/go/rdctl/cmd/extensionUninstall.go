@@ -19,22 +19,24 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var uninstallCmd = &cobra.Command{
 	Use:   "uninstall",
-	Short: "Uninstall an RDX extension",
+	Short: i18n.T("commands.extension.uninstall.short"),
 	Long: `rdctl extension uninstall <image-id>
 The <image-id> is an image reference, e.g. splatform/epinio-docker-desktop:latest (the tag is optional).`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return uninstallExtension(args)
+		return uninstallExtension(cmd.Context(), args)
 	},
 }
 
@@ -42,7 +44,7 @@ func init() {
 	extensionCmd.AddCommand(uninstallCmd)
 }
 
-func uninstallExtension(args []string) error {
+func uninstallExtension(ctx context.Context, args []string) error {
 	connectionInfo, err := config.GetConnectionInfo(false)
 	if err != nil {
 		return fmt.Errorf("failed to get connection info: %w", err)
@@ -50,7 +52,7 @@ func uninstallExtension(args []string) error {
 	rdClient := client.NewRDClient(connectionInfo)
 	imageID := args[0]
 	endpoint := fmt.Sprintf("/%s/extensions/uninstall?id=%s", client.ApiVersion, imageID)
-	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest("POST", endpoint))
+	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest(ctx, "POST", endpoint))
 	if errorPacket != nil || err != nil {
 		return displayAPICallResult(result, errorPacket, err)
 	}
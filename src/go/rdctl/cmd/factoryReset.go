@@ -17,16 +17,45 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/factoryreset"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shutdown"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/terminal"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-var removeKubernetesCache bool
+var factoryResetOptions factoryreset.Options
+var factoryResetDryRun bool
+var factoryResetOutput string
+var factoryResetYes bool
+
+// Output formats for factory-reset.
+const (
+	factoryResetTextOutput = "text"
+	factoryResetJSONOutput = "json"
+)
+
+// factoryResetSummary is the JSON output of factory-reset.
+type factoryResetSummary struct {
+	DryRun bool                  `json:"dryRun"`
+	Items  []factoryreset.Result `json:"items"`
+	// BytesFreed is the disk space freed (or, for a dry run, to be freed).
+	BytesFreed int64 `json:"bytesFreed"`
+	Errors     int   `json:"errors"`
+}
 
 // Note that this command supports a `--remove-kubernetes-cache` flag,
 // but the server takes an optional flag meaning the opposite (as per issues
@@ -35,32 +64,215 @@ var removeKubernetesCache bool
 
 var factoryResetCmd = &cobra.Command{
 	Use:   "factory-reset",
-	Short: "Clear all the Rancher Desktop state and shut it down.",
+	Short: i18n.T("commands.factoryReset.short"),
 	Long: `Clear all the Rancher Desktop state and shut it down.
-Use the --remove-kubernetes-cache=BOOLEAN flag to also remove the cached Kubernetes images.`,
+Use the --remove-kubernetes-cache=BOOLEAN flag to also remove the cached Kubernetes images.
+Use the --keep-images flag to keep the container images (and the cached Kubernetes images);
+containers, volumes, networks and the Kubernetes state are still removed, so
+Rancher Desktop must be running.
+Use the --keep-settings flag to keep the application settings.
+The Rancher Desktop kubeconfig context and any snapshots are kept unless
+--keep-kubeconfig=false or --keep-snapshots=false is given.
+On Windows, use the --keep-wsl-distro flag to keep the named WSL distribution
+("rancher-desktop" or "rancher-desktop-data"), and the --keep-integrated-wsl-distro
+flag to keep the "rancher-desktop" distribution if WSL integration is enabled.
+Use the --dry-run flag to list what would be removed without removing anything.
+Use --output=json to get a summary of what was (or would be) removed as JSON.
+Unless --yes is given, asks for confirmation first; when not run interactively
+(or in CI), --yes is required.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.NoArgs(cmd, args); err != nil {
 			return err
 		}
+		if factoryResetOptions.KeepImages && factoryResetOptions.RemoveKubernetesCache {
+			return errors.New(i18n.T("factoryReset.conflictingFlags"))
+		}
+		if err := validateKeptDistros(cmd); err != nil {
+			return err
+		}
+		if err := commonShutdownSettings.Validate(); err != nil {
+			return err
+		}
+		if factoryResetOutput != factoryResetTextOutput && factoryResetOutput != factoryResetJSONOutput {
+			return fmt.Errorf("invalid output format %q; must be %q or %q", factoryResetOutput, factoryResetTextOutput, factoryResetJSONOutput)
+		}
 		if commonShutdownSettings.Verbose {
 			logrus.SetLevel(logrus.TraceLevel)
 		}
 		cmd.SilenceUsage = true
-		commonShutdownSettings.WaitForShutdown = false
-		_, err := doShutdown(&commonShutdownSettings, shutdown.FactoryReset)
-		if err != nil {
-			return err
+		if factoryResetDryRun {
+			return showFactoryResetPlan()
 		}
-		paths, err := paths.GetPaths()
-		if err != nil {
-			return fmt.Errorf("failed to get paths: %w", err)
+		if err := terminal.Confirm(i18n.T("factoryReset.confirm"), factoryResetYes); err != nil {
+			return err
 		}
-		return factoryreset.DeleteData(paths, removeKubernetesCache)
+		return doFactoryReset(cmd.Context())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(factoryResetCmd)
-	factoryResetCmd.Flags().BoolVar(&removeKubernetesCache, "remove-kubernetes-cache", false, "If specified, also removes the cached Kubernetes images.")
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.RemoveKubernetesCache, "remove-kubernetes-cache", false, "If specified, also removes the cached Kubernetes images.")
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.KeepImages, "keep-images", false, "If specified, keeps the container image store and the cached Kubernetes images, removing everything else.")
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.KeepSettings, "keep-settings", false, "If specified, keeps the application settings.")
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.KeepKubeconfig, "keep-kubeconfig", true, "Keeps the Rancher Desktop context in the kubeconfig file; use --keep-kubeconfig=false to remove it.")
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.KeepSnapshots, "keep-snapshots", true, "Keeps any snapshots; use --keep-snapshots=false to delete them.")
+	factoryResetCmd.Flags().StringSliceVar(&factoryResetOptions.KeepDistros, "keep-wsl-distro", nil,
+		fmt.Sprintf("Windows only: keeps the given WSL distribution (%q or %q); may be repeated.", paths.MainDistro(), paths.DataDistro()))
+	factoryResetCmd.Flags().BoolVar(&factoryResetOptions.KeepIntegratedDistro, "keep-integrated-wsl-distro", false,
+		fmt.Sprintf("Windows only: keeps the %q WSL distribution if WSL integration is enabled for any distribution.", paths.MainDistro()))
+	factoryResetCmd.Flags().BoolVar(&factoryResetDryRun, "dry-run", false, "List what would be removed, without shutting down or removing anything.")
+	factoryResetCmd.Flags().StringVar(&factoryResetOutput, "output", factoryResetTextOutput, fmt.Sprintf("Output format: %s|%s", factoryResetTextOutput, factoryResetJSONOutput))
 	factoryResetCmd.Flags().BoolVar(&commonShutdownSettings.Verbose, "verbose", false, "Be verbose")
+	factoryResetCmd.Flags().BoolVarP(&factoryResetYes, "yes", "y", false, "Don't ask for confirmation.")
+	addShutdownTimeoutFlags(factoryResetCmd)
+}
+
+func validateKeptDistros(cmd *cobra.Command) error {
+	if runtime.GOOS != "windows" {
+		for _, flag := range []string{"keep-wsl-distro", "keep-integrated-wsl-distro"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf(`"--%s" is only supported on Windows`, flag)
+			}
+		}
+		return nil
+	}
+	for _, distro := range factoryResetOptions.KeepDistros {
+		if distro != paths.MainDistro() && distro != paths.DataDistro() {
+			return fmt.Errorf(`invalid value for "--keep-wsl-distro": %q; must be %q or %q`, distro, paths.MainDistro(), paths.DataDistro())
+		}
+	}
+	return nil
+}
+
+// doFactoryReset shuts down Rancher Desktop and removes its data, reporting
+// each item as it is removed.
+func doFactoryReset(ctx context.Context) error {
+	jsonOutput := factoryResetOutput == factoryResetJSONOutput
+	summary := factoryResetSummary{Items: []factoryreset.Result{}}
+	factoryResetOptions.Progress = func(result factoryreset.Result) {
+		summary.Items = append(summary.Items, result)
+		if result.Error != "" {
+			summary.Errors++
+		} else {
+			summary.BytesFreed += result.Size
+		}
+		if jsonOutput {
+			return
+		}
+		description := fmt.Sprintf("%s %s", result.Kind, result.Location)
+		if result.Size > 0 {
+			description += fmt.Sprintf(" (%s)", utils.FormatSize(result.Size))
+		}
+		if result.Error != "" {
+			fmt.Println(i18n.T("factoryReset.failedToRemove", i18n.Args{"item": description, "error": result.Error}))
+		} else {
+			fmt.Println(i18n.T("factoryReset.removed", i18n.Args{"item": description}))
+		}
+	}
+	if !jsonOutput {
+		fmt.Println(i18n.T("factoryReset.shuttingDown"))
+	}
+	if factoryResetOptions.KeepImages {
+		// This needs the VM, so it must happen before shutting down.
+		if err := removeContainerState(ctx); err != nil {
+			return err
+		}
+	}
+	commonShutdownSettings.WaitForShutdown = false
+	commonShutdownSettings.StopContainers = false
+	_, err := doShutdown(ctx, &commonShutdownSettings, shutdown.FactoryReset)
+	if err != nil {
+		return err
+	}
+	paths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	if !jsonOutput {
+		fmt.Println(i18n.T("factoryReset.removingData"))
+	}
+	deleteErr := factoryreset.DeleteData(paths, factoryResetOptions)
+	if jsonOutput {
+		if err := printFactoryResetSummary(summary); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(i18n.T("factoryReset.freed", i18n.Args{"size": utils.FormatSize(summary.BytesFreed)}))
+		if summary.Errors > 0 {
+			fmt.Println(i18n.T("factoryReset.failedCount", i18n.Args{"count": summary.Errors}))
+		}
+	}
+	return deleteErr
+}
+
+// removeContainerState removes everything but the images from the VM, for
+// --keep-images.  Nothing has been removed yet if this fails.
+func removeContainerState(ctx context.Context) error {
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return fmt.Errorf("%s: %w", i18n.T("factoryReset.keepImagesNeedsBackend"), err)
+	}
+	settings, err := client.NewRDClient(connectionInfo).GetSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", i18n.T("factoryReset.keepImagesNeedsBackend"), err)
+	}
+	if settings.ContainerEngine == nil || settings.ContainerEngine.Name == nil {
+		return errors.New("the current settings have no container engine")
+	}
+	err = shutdown.RemoveContainerState(*settings.ContainerEngine.Name)
+	result := factoryreset.Result{Item: factoryreset.Item{Kind: factoryreset.ItemContainerState, Location: *settings.ContainerEngine.Name}}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	factoryResetOptions.Progress(result)
+	return err
+}
+
+func printFactoryResetSummary(summary factoryResetSummary) error {
+	jsonBuffer, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBuffer))
+	return nil
+}
+
+func showFactoryResetPlan() error {
+	paths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	items, err := factoryreset.Plan(paths, factoryResetOptions)
+	if err != nil {
+		return err
+	}
+	if factoryResetOutput == factoryResetJSONOutput {
+		summary := factoryResetSummary{DryRun: true, Items: make([]factoryreset.Result, 0, len(items))}
+		for _, item := range items {
+			summary.Items = append(summary.Items, factoryreset.Result{Item: item})
+			summary.BytesFreed += item.Size
+		}
+		return printFactoryResetSummary(summary)
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("factoryReset.nothingToRemove"))
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+	fmt.Fprintf(writer, "KIND\tSIZE\tLOCATION\n")
+	var total int64
+	for _, item := range items {
+		size := ""
+		if item.Kind == factoryreset.ItemDirectory || item.Kind == factoryreset.ItemFile {
+			size = utils.FormatSize(item.Size)
+		}
+		total += item.Size
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", item.Kind, size, item.Location)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", i18n.T("factoryReset.totalToFree", i18n.Args{"size": utils.FormatSize(total)}))
+	return nil
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var infoSettings struct {
+	JSON bool
+}
+
+// infoOutput is the JSON output of `rdctl info`.
+type infoOutput struct {
+	Version   string                 `json:"version"`
+	Instance  string                 `json:"instance,omitempty"`
+	DiskUsage []paths.DirectoryUsage `json:"diskUsage"`
+	// TotalAllocated is the disk space used by all the directories.
+	TotalAllocated int64 `json:"totalAllocated"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: i18n.T("commands.info.short"),
+	Long: `Show information about the Rancher Desktop installation, including the disk
+space used by each of its data directories.  This works whether or not the
+application is running.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return showInfo()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().BoolVar(&infoSettings.JSON, "json", false, "output json format")
+}
+
+func showInfo() error {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	usage, err := appPaths.DiskUsage()
+	if err != nil {
+		return fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	output := infoOutput{
+		Version:   client.Version,
+		Instance:  os.Getenv(paths.InstanceEnvVar),
+		DiskUsage: usage,
+	}
+	if output.DiskUsage == nil {
+		output.DiskUsage = []paths.DirectoryUsage{}
+	}
+	for _, directory := range usage {
+		output.TotalAllocated += directory.Allocated
+	}
+	if infoSettings.JSON {
+		jsonBuffer, err := json.Marshal(output)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonBuffer))
+		return nil
+	}
+	fmt.Printf("rdctl version: %s\n", output.Version)
+	if output.Instance != "" {
+		fmt.Printf("Instance: %s\n", output.Instance)
+	}
+	fmt.Println()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+	fmt.Fprintf(writer, "DIRECTORY\tUSED\tAPPARENT SIZE\tPATH\n")
+	for _, directory := range usage {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", directory.Name, utils.FormatSize(directory.Allocated), utils.FormatSize(directory.Size), directory.Path)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nTotal disk space used: %s\n", utils.FormatSize(output.TotalAllocated))
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/terminal"
+	"github.com/spf13/cobra"
+)
+
+var kubernetesResetWipe bool
+var kubernetesResetYes bool
+
+var kubernetesCmd = &cobra.Command{
+	Use:   "kubernetes",
+	Short: i18n.T("commands.kubernetes.short"),
+}
+
+var kubernetesResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: i18n.T("commands.kubernetes.reset.short"),
+	Long: `Reset Kubernetes, deleting all workloads and configuration, and wait for it
+to start again.  Use the --wipe flag to delete the whole VM instead, including
+the container images.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		question := i18n.T("kubernetes.confirmReset")
+		if kubernetesResetWipe {
+			question = i18n.T("kubernetes.confirmWipe")
+		}
+		if err := terminal.Confirm(question, kubernetesResetYes); err != nil {
+			return err
+		}
+		return resetKubernetes(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kubernetesCmd)
+	kubernetesCmd.AddCommand(kubernetesResetCmd)
+	kubernetesResetCmd.Flags().BoolVar(&kubernetesResetWipe, "wipe", false, "delete the VM, including the container images")
+	kubernetesResetCmd.Flags().BoolVarP(&kubernetesResetYes, "yes", "y", false, "don't ask for confirmation (required when not run interactively)")
+}
+
+// resetKubernetes runs the reset as a job, so that it doesn't depend on a
+// single request staying open until Kubernetes is back up.
+func resetKubernetes(ctx context.Context) error {
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	request := client.JobRequest{Kind: client.JobKindKubernetesReset, Mode: "fast"}
+	if kubernetesResetWipe {
+		request.Mode = "wipe"
+	}
+	job, err := client.NewRDClient(connectionInfo).RunJob(ctx, request, printJobProgress())
+	if err != nil {
+		return err
+	}
+	fmt.Println(job.Result)
+	return nil
+}
+
+// printJobProgress returns a progress callback for RunJob that reports each
+// change in the progress of the job on stderr.
+func printJobProgress() func(*client.Job) {
+	var last client.JobProgress
+	return func(job *client.Job) {
+		if job.Progress == nil || *job.Progress == last {
+			return
+		}
+		last = *job.Progress
+		if last.Max > 0 {
+			fmt.Fprintf(os.Stderr, "%s (%d/%d)\n", last.Description, last.Current, last.Max)
+		} else if last.Description != "" {
+			fmt.Fprintln(os.Stderr, last.Description)
+		}
+	}
+}
@@ -17,23 +17,25 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 // listSettingsCmd represents the listSettings command
 var listSettingsCmd = &cobra.Command{
 	Use:   "list-settings",
-	Short: "Lists the current settings.",
+	Short: i18n.T("commands.listSettings.short"),
 	Long:  `Lists the current settings in JSON format.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.NoArgs(cmd, args); err != nil {
 			return err
 		}
 		cmd.SilenceUsage = true
-		result, err := getListSettings()
+		result, err := getListSettings(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -46,12 +48,12 @@ func init() {
 	rootCmd.AddCommand(listSettingsCmd)
 }
 
-func getListSettings() ([]byte, error) {
+func getListSettings(ctx context.Context) ([]byte, error) {
 	connectionInfo, err := config.GetConnectionInfo(false)
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to get connection info: %w", err)
 	}
 	rdClient := client.NewRDClient(connectionInfo)
-	response, err := rdClient.DoRequest("GET", client.VersionCommand("", "settings"))
+	response, err := rdClient.DoRequest(ctx, "GET", client.VersionCommand("", "settings"))
 	return client.ProcessRequestForUtility(response, err)
 }
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: i18n.T("commands.lock.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+}
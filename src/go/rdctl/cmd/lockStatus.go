@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/lock"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+var lockStatusForceUnlock bool
+
+var lockStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: i18n.T("commands.lock.status.short"),
+	Long: `Show which operation and process hold the backend lock, and for how long.
+A lock is stale if the process holding it has exited (for example, after a crash);
+use --force-unlock to remove it.  Locks held by running processes are never removed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return exitWithJsonOrErrorCondition(showLockStatus())
+	},
+}
+
+type lockStatusPayload struct {
+	Locked  bool      `json:"locked"`
+	Action  string    `json:"action,omitempty"`
+	PID     int       `json:"pid,omitempty"`
+	Created time.Time `json:"created"`
+	Stale   bool      `json:"stale"`
+	Removed bool      `json:"removed"`
+}
+
+func init() {
+	lockCmd.AddCommand(lockStatusCmd)
+	lockStatusCmd.Flags().BoolVar(&lockStatusForceUnlock, "force-unlock", false, "remove the lock if its holder is no longer running")
+	lockStatusCmd.Flags().BoolVar(&outputJsonFormat, "json", false, "output json format")
+}
+
+func showLockStatus() error {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	info, err := lock.Status(appPaths)
+	if err != nil {
+		return err
+	}
+	payload := lockStatusPayload{Locked: info != nil}
+	if info != nil {
+		payload.Action = info.Action
+		payload.PID = info.PID
+		payload.Created = info.Created
+		payload.Stale = info.IsStale()
+		if lockStatusForceUnlock {
+			if _, err := lock.ForceUnlock(appPaths); err != nil {
+				return err
+			}
+			payload.Removed = true
+		}
+	}
+	if outputJsonFormat {
+		jsonBuffer, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonBuffer))
+		return nil
+	}
+	if info == nil {
+		fmt.Println(i18n.T("lock.notLocked"))
+		return nil
+	}
+	action, holder := i18n.T("lock.unknownAction"), i18n.T("lock.unknownProcess")
+	if info.Action != "" {
+		action = "snapshot-" + info.Action
+	}
+	if info.PID != 0 {
+		holder = i18n.T("lock.process", i18n.Args{"pid": info.PID})
+	}
+	fmt.Println(i18n.T("lock.heldBy", i18n.Args{"holder": holder, "action": action, "age": info.Age().Round(time.Second)}))
+	if payload.Stale {
+		fmt.Println(i18n.T("lock.stale"))
+	}
+	if payload.Removed {
+		fmt.Println(i18n.T("lock.removed"))
+	} else if payload.Stale || info.PID == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("lock.forceUnlockHint"))
+	}
+	return nil
+}
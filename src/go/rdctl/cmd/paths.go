@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/spf13/cobra"
 	"os"
@@ -11,7 +12,7 @@ import (
 var pathsCmd = &cobra.Command{
 	Hidden: true,
 	Use:    "paths",
-	Short:  "Print the paths to directories that Rancher Desktop uses",
+	Short:  i18n.T("commands.paths.short"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		paths, err := p.GetPaths()
 		if err != nil {
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: i18n.T("commands.profile.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/reg"
+	"github.com/spf13/cobra"
+)
+
+var profileImportSettings struct {
+	RegistryHive string
+	ProfileType  string
+}
+
+var profileImportCmd = &cobra.Command{
+	Use:   "import [file.reg]",
+	Short: i18n.T("commands.profile.import.short"),
+	Long: `Read a deployment profile from a .reg file, or from the registry when no file
+is given (Windows only), and print one of its sections as settings JSON, as
+accepted by "rdctl create-profile".`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return importProfile(args)
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileImportCmd)
+	profileImportCmd.Flags().StringVar(&profileImportSettings.RegistryHive, "hive", reg.HklmRegistryHive,
+		fmt.Sprintf("registry hive to read when no file is given: %s (or %s)|%s (or %s)", reg.HklmRegistryHive, systemHive, reg.HkcuRegistryHive, userHive))
+	profileImportCmd.Flags().StringVar(&profileImportSettings.ProfileType, "type", reg.DefaultsProfileType,
+		fmt.Sprintf("registry section: %s|%s", reg.DefaultsProfileType, reg.LockedProfileType))
+}
+
+func importProfile(args []string) error {
+	switch profileImportSettings.ProfileType {
+	case reg.DefaultsProfileType, reg.LockedProfileType:
+	default:
+		return fmt.Errorf("invalid registry section of %q specified, must be %q or %q", profileImportSettings.ProfileType, reg.DefaultsProfileType, reg.LockedProfileType)
+	}
+	var sections map[string]map[string]interface{}
+	var source string
+	if len(args) > 0 {
+		source = args[0]
+		contents, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		if sections, err = reg.ParseReg(string(contents)); err != nil {
+			return fmt.Errorf("error parsing %q: %w", source, err)
+		}
+	} else {
+		hive := map[string]string{systemHive: reg.HklmRegistryHive, userHive: reg.HkcuRegistryHive}[profileImportSettings.RegistryHive]
+		if hive == "" {
+			hive = profileImportSettings.RegistryHive
+		}
+		source = fmt.Sprintf("the %s registry hive", hive)
+		var err error
+		if sections, err = reg.ReadRegistry(hive); err != nil {
+			return err
+		}
+	}
+	values, ok := sections[profileImportSettings.ProfileType]
+	if !ok {
+		return fmt.Errorf("no %s settings found in %s", profileImportSettings.ProfileType, source)
+	}
+	settings, err := reg.RegToJson(values)
+	if err != nil {
+		return fmt.Errorf("error converting %s settings from %s: %w", profileImportSettings.ProfileType, source, err)
+	}
+	fmt.Println(settings)
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/profile"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/reg"
+	"github.com/spf13/cobra"
+)
+
+var profileValidateSettings struct {
+	ProfileType string
+	JSON        bool
+}
+
+var profileValidateCmd = &cobra.Command{
+	Use:   "validate <file.reg|file.plist|file.json>",
+	Short: i18n.T("commands.profile.validate.short"),
+	Long: `Parse a deployment profile, report any unknown or mistyped entries, and
+show the defaults and locked settings it would produce.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return validateProfile(args[0])
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileValidateCmd)
+	profileValidateCmd.Flags().StringVar(&profileValidateSettings.ProfileType, "type", "",
+		fmt.Sprintf("profile type of plist and JSON files: %s|%s (default based on the file name)", reg.DefaultsProfileType, reg.LockedProfileType))
+	profileValidateCmd.Flags().BoolVar(&profileValidateSettings.JSON, "json", false, "output json format")
+}
+
+func validateProfile(path string) error {
+	switch profileValidateSettings.ProfileType {
+	case "", reg.DefaultsProfileType, reg.LockedProfileType:
+	default:
+		return fmt.Errorf("invalid profile type of %q specified, must be %q or %q", profileValidateSettings.ProfileType, reg.DefaultsProfileType, reg.LockedProfileType)
+	}
+	deploymentProfile, err := profile.Load(path, profileValidateSettings.ProfileType)
+	if err != nil {
+		return err
+	}
+	result := deploymentProfile.Validate()
+	if profileValidateSettings.JSON {
+		jsonBuffer, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonBuffer))
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, section := range []string{reg.DefaultsProfileType, reg.LockedProfileType} {
+			settings, ok := result.Settings[section]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(writer, "%s:\n", section)
+			if len(settings) == 0 {
+				fmt.Fprintln(writer, "  (none)")
+			}
+			for _, setting := range settings {
+				value, err := json.Marshal(setting.Value)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(writer, "  %s\t%s\n", setting.Path, value)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		for _, problem := range result.Problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+	}
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in %s", len(result.Problems), path)
+	}
+	return nil
+}
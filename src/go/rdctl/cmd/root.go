@@ -18,27 +18,198 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/fips"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/lock"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/telemetry"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/terminal"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/tracing"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"os"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracingShutdownTimeout is how long to wait for the remaining spans to be
+// exported before exiting.
+const tracingShutdownTimeout = 5 * time.Second
+
+var instanceName string
+var logLevel string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "rdctl",
-	Short: "A CLI for Rancher Desktop",
+	Short: i18n.T("commands.root.short"),
 	Long:  `The eventual goal of this CLI is to enable any UI-based operation to be done from the command-line as well.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		trace.SpanFromContext(cmd.Context()).SetName(cmd.CommandPath())
+		if cmd.Flags().Changed("log-level") {
+			level, err := logrus.ParseLevel(logLevel)
+			if err != nil {
+				return fmt.Errorf("%s: %w", i18n.T("root.invalidLogLevel"), err)
+			}
+			logrus.SetLevel(level)
+		}
+		if !cmd.Flags().Changed("instance") {
+			// Check an instance selected through the environment, too.
+			_, err := paths.InstanceSuffix()
+			return err
+		}
+		if err := paths.ValidateInstanceName(instanceName); err != nil {
+			return err
+		}
+		// Use the environment so the setting is inherited by anything we launch.
+		return os.Setenv(paths.InstanceEnvVar, instanceName)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The first interrupt cancels the command's context, aborting any API request
+// in flight; a second one terminates rdctl straight away.
+// When tracing is enabled, the whole command is recorded as a single span.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	logCloser := logging.Init("rdctl")
+	terminal.DisableColors()
+	if err := fips.Verify(); err != nil {
+		logrus.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		logrus.Warnf("Tracing is disabled: %s", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+	ctx, span := tracing.Start(ctx, "rdctl")
+	started := time.Now()
+	suggestSubcommands(rootCmd)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	tracing.End(span, err)
+	stop()
+	reportTelemetry(cmd, time.Since(started), err)
+	flushCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	if shutdownErr := shutdownTracing(flushCtx); shutdownErr != nil {
+		logrus.Warnf("Failed to export traces: %s", shutdownErr)
+	}
+	cancel()
+	_ = logCloser.Close()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// reportTelemetry reports how the command went, if the user has enabled
+// telemetry; failures to report are not the user's concern.
+func reportTelemetry(cmd *cobra.Command, duration time.Duration, err error) {
+	appPaths, pathsErr := paths.GetPaths()
+	if pathsErr != nil {
+		return
+	}
+	event := telemetry.Event{
+		Version:    client.Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Command:    cmd.CommandPath(),
+		DurationMS: duration.Milliseconds(),
+		Result:     telemetry.Classify(err),
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		event.Flags = append(event.Flags, flag.Name)
+	})
+	if reportErr := telemetry.Report(context.Background(), appPaths, event); reportErr != nil {
+		logrus.Debugf("Failed to report telemetry: %s", reportErr)
+	}
+}
+
+// suggestSubcommands makes the commands that only group subcommands (such as
+// "rdctl snapshot") reject unknown subcommands with suggestions, as cobra does
+// for the root command, instead of silently printing their help.
+func suggestSubcommands(cmd *cobra.Command) {
+	for _, child := range cmd.Commands() {
+		if child.HasSubCommands() && !child.Runnable() {
+			child.Args = cobra.ArbitraryArgs
+			child.RunE = func(cmd *cobra.Command, args []string) error {
+				if len(args) == 0 {
+					return cmd.Help()
+				}
+				return unknownSubcommand(cmd, args[0])
+			}
+		}
+		suggestSubcommands(child)
+	}
+}
+
+// unknownSubcommand reports an unknown subcommand the way cobra reports
+// unknown commands, suggesting those with similar names.
+func unknownSubcommand(cmd *cobra.Command, name string) error {
+	cmd.SilenceUsage = true
+	if cmd.SuggestionsMinimumDistance <= 0 {
+		cmd.SuggestionsMinimumDistance = 2
+	}
+	message := fmt.Sprintf("unknown command %q for %q\n", name, cmd.CommandPath())
+	if suggestions := cmd.SuggestionsFor(name); len(suggestions) > 0 {
+		message += "\nDid you mean this?\n\t" + strings.Join(suggestions, "\n\t") + "\n"
+	}
+	message += fmt.Sprintf("Run '%s --help' for usage.", cmd.CommandPath())
+	return telemetry.NewUsageError(errors.New(message))
+}
+
+// suggestFlag adds the closest known flag to errors about unknown flags.
+func suggestFlag(cmd *cobra.Command, err error) error {
+	name, ok := strings.CutPrefix(err.Error(), "unknown flag: --")
+	if !ok {
+		return err
+	}
+	var names []string
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Hidden {
+			names = append(names, flag.Name)
+		}
+	})
+	if suggestion := utils.Suggest(name, names); suggestion != "" {
+		return fmt.Errorf("%w\n\nDid you mean this?\n\t--%s\n", err, suggestion)
+	}
+	return err
+}
+
+// backendLocked checks whether another live process holds the backend lock,
+// for client.BackendLocked.  While rdctl holds the lock itself, nobody else
+// is going to restart the backend.
+func backendLocked() bool {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return false
+	}
+	info, err := lock.Status(appPaths)
+	return err == nil && info != nil && info.PID != os.Getpid() && !info.IsStale()
+}
+
 func init() {
+	client.BackendLocked = backendLocked
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return telemetry.NewUsageError(suggestFlag(cmd, err))
+	})
+	rootCmd.PersistentFlags().StringVar(&instanceName, "instance", "",
+		fmt.Sprintf("name of the Rancher Desktop instance to use (default from $%s, or the default instance)", paths.InstanceEnvVar))
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", logrus.InfoLevel.String(),
+		"logging level (debug also traces API requests and responses, with secrets redacted)")
 	if len(os.Args) > 1 {
 		mainCommand := os.Args[1]
 		if mainCommand == "-h" || mainCommand == "help" || mainCommand == "--help" {
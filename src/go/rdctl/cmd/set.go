@@ -20,23 +20,31 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
 	"github.com/spf13/cobra"
 )
 
 // setCmd represents the set command
 var setCmd = &cobra.Command{
-	Use:   "set",
-	Short: "Update selected fields in the Rancher Desktop UI and restart the backend.",
-	Long:  `Update selected fields in the Rancher Desktop UI and restart the backend.`,
+	Use:   "set [<setting>=<value>...]",
+	Short: i18n.T("commands.set.short"),
+	Long: `Update selected fields in the Rancher Desktop UI and restart the backend.
+
+Settings can be given with the flags below, or by their dotted names as in the
+output of "rdctl list-settings", which also reaches settings without a flag:
+
+  rdctl set kubernetes.version=1.29.3 containerEngine.allowedImages.patterns=docker.io,ghcr.io
+
+Values are converted to the type of the setting; lists are comma-separated (or
+JSON arrays), and groups of free-form settings are JSON objects.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := cobra.NoArgs(cmd, args); err != nil {
-			return err
-		}
-		return doSetCommand(cmd)
+		return doSetCommand(cmd, args)
 	},
 }
 
@@ -45,27 +53,30 @@ func init() {
 	options.UpdateCommonStartAndSetCommands(setCmd)
 }
 
-func doSetCommand(cmd *cobra.Command) error {
-	connectionInfo, err := config.GetConnectionInfo(false)
-	if err != nil {
-		return fmt.Errorf("failed to get connection info: %w", err)
-	}
-	rdClient := client.NewRDClient(connectionInfo)
-
+func doSetCommand(cmd *cobra.Command, args []string) error {
 	changedSettings, err := options.UpdateFieldsForJSON(cmd.Flags())
 	if err != nil {
 		cmd.SilenceUsage = true
 		return err
-	} else if changedSettings == nil {
+	} else if changedSettings == nil && len(args) == 0 {
 		return fmt.Errorf("%s command: no settings to change were given", cmd.Name())
 	}
+	settings, err := settingsFromArgs(changedSettings, args)
+	if err != nil {
+		return err
+	}
 	cmd.SilenceUsage = true
-	jsonBuffer, err := json.Marshal(changedSettings)
+	jsonBuffer, err := json.Marshal(settings)
 	if err != nil {
 		return err
 	}
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
 
-	response, err := rdClient.DoRequestWithPayload("PUT", client.VersionCommand("", "settings"), bytes.NewBuffer(jsonBuffer))
+	response, err := rdClient.DoRequestWithPayload(cmd.Context(), "PUT", client.VersionCommand("", "settings"), bytes.NewBuffer(jsonBuffer))
 	result, err := client.ProcessRequestForUtility(response, err)
 	if err != nil {
 		return err
@@ -77,3 +88,28 @@ func doSetCommand(cmd *cobra.Command) error {
 	}
 	return nil
 }
+
+// settingsFromArgs adds the settings given as <setting>=<value> arguments to
+// those given as flags.
+func settingsFromArgs(changedSettings *options.ServerSettingsForJSON, args []string) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+	if changedSettings != nil {
+		jsonBuffer, err := json.Marshal(changedSettings)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(jsonBuffer, &settings); err != nil {
+			return nil, err
+		}
+	}
+	for _, arg := range args {
+		path, value, ok := strings.Cut(arg, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid argument %q: expected <setting>=<value>", arg)
+		}
+		if err := jsonpath.Set(client.SettingsSchema, settings, path, value, jsonpath.Options{Strings: true}); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
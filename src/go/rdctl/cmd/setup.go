@@ -2,22 +2,49 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/spf13/cobra"
 )
 
 var setupSettings struct {
-	AutoStart bool
+	AutoStart        bool
+	AutoStartOptions autostart.Options
+}
+
+// autoStartOptionFlags are the flags that modify the recorded autostart options.
+var autoStartOptionFlags = []string{
+	"auto-start-method",
+	"auto-start-delay",
+	"auto-start-highest-privileges",
+	"auto-start-in-background",
+	"auto-start-without-kubernetes",
 }
 
 var setupCmd = &cobra.Command{
 	Hidden: true,
 	Use:    "setup",
-	Short:  "Configure the system without modifying settings",
+	Short:  i18n.T("commands.setup.short"),
+	Long: `Configure the system without modifying settings.
+The autostart options are recorded, so that they are kept when the application
+later changes the --auto-start setting.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("auto-start") {
-			return autostart.EnsureAutostart(setupSettings.AutoStart)
+			options, err := updateAutoStartOptions(cmd)
+			if err != nil {
+				return err
+			}
+			return autostart.EnsureAutostart(setupSettings.AutoStart, options)
+		}
+		for _, flag := range autoStartOptionFlags {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf(`"--%s" requires "--auto-start"`, flag)
+			}
 		}
 		return errors.New("no changes were specified")
 	},
@@ -26,4 +53,50 @@ var setupCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(setupCmd)
 	setupCmd.Flags().BoolVar(&setupSettings.AutoStart, "auto-start", false, "Whether to start Rancher Desktop at login")
+	setupCmd.Flags().StringVar(&setupSettings.AutoStartOptions.Method, "auto-start-method", autostart.MethodDefault,
+		fmt.Sprintf("How to start Rancher Desktop at login (%s); defaults to the current method", strings.Join(autostart.SupportedMethods(), ", ")))
+	setupCmd.Flags().DurationVar(&setupSettings.AutoStartOptions.Delay, "auto-start-delay", 0, "How long to wait after login before starting Rancher Desktop")
+	setupCmd.Flags().BoolVar(&setupSettings.AutoStartOptions.HighestPrivileges, "auto-start-highest-privileges", false, "Start Rancher Desktop with the highest privileges available, for use with the privileged service (task-scheduler method only)")
+	setupCmd.Flags().BoolVar(&setupSettings.AutoStartOptions.StartInBackground, "auto-start-in-background", false, "Start Rancher Desktop minimized to the tray at login")
+	setupCmd.Flags().BoolVar(&setupSettings.AutoStartOptions.DisableKubernetes, "auto-start-without-kubernetes", false, "Start Rancher Desktop with Kubernetes disabled at login")
+}
+
+// updateAutoStartOptions merges the autostart options given on the command line
+// into the recorded ones, and records the result.
+func updateAutoStartOptions(cmd *cobra.Command) (autostart.Options, error) {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return autostart.Options{}, fmt.Errorf("failed to get paths: %w", err)
+	}
+	optionsPath := filepath.Join(appPaths.Config, autostart.OptionsFileName)
+	options, err := autostart.LoadOptions(optionsPath)
+	if err != nil {
+		return options, err
+	}
+	flags := cmd.Flags()
+	given := setupSettings.AutoStartOptions
+	if flags.Changed("auto-start-method") {
+		options.Method = given.Method
+	}
+	if flags.Changed("auto-start-delay") {
+		options.Delay = given.Delay
+	}
+	if flags.Changed("auto-start-highest-privileges") {
+		options.HighestPrivileges = given.HighestPrivileges
+	}
+	if flags.Changed("auto-start-in-background") {
+		options.StartInBackground = given.StartInBackground
+	}
+	if flags.Changed("auto-start-without-kubernetes") {
+		options.DisableKubernetes = given.DisableKubernetes
+	}
+	if err := options.Validate(); err != nil {
+		return options, err
+	}
+	for _, flag := range autoStartOptionFlags {
+		if flags.Changed(flag) {
+			return options, autostart.SaveOptions(optionsPath, options)
+		}
+	}
+	return options, nil
 }
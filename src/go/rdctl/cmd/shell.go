@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -25,17 +26,19 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/encoding/unicode"
 )
 
 // shellCmd represents the shell command
 var shellCmd = &cobra.Command{
 	Use:   "shell",
-	Short: "Run an interactive shell or a command in a Rancher Desktop-managed VM",
+	Short: i18n.T("commands.shell.short"),
 	Long: `Run an interactive shell or a command in a Rancher Desktop-managed VM. For example:
 
 > rdctl shell
@@ -64,7 +67,7 @@ func doShellCommand(cmd *cobra.Command, args []string) error {
 	var commandName string
 	if runtime.GOOS == "windows" {
 		commandName = "wsl"
-		distroName := "rancher-desktop"
+		distroName := p.MainDistro()
 		if !checkWSLIsRunning(distroName) {
 			// No further output wanted, so just exit with the desired status.
 			os.Exit(1)
@@ -78,7 +81,7 @@ func doShellCommand(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		if err = directories.SetupLimaHome(paths.AppHome); err != nil {
+		if err = directories.SetupLimaHome(paths.Lima); err != nil {
 			return err
 		}
 		commandName, err = directories.GetLimactlPath()
@@ -104,11 +107,11 @@ func checkLimaIsRunning(commandName string) bool {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 
-	cmd := exec.Command(commandName, "ls", "0", "--format", "{{.Status}}")
+	cmd := execctx.Command(context.Background(), 0, commandName, "ls", "0", "--format", "{{.Status}}")
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		logrus.Errorf("Failed to run %q: %s\n", cmd, err)
+		logrus.Error(err)
 		return false
 	}
 	limaState := strings.TrimRight(stdout.String(), "\n")
@@ -134,20 +137,14 @@ func checkLimaIsRunning(commandName string) bool {
 
 func checkWSLIsRunning(distroName string) bool {
 	// Ignore error messages; none are expected here
-	rawOutput, err := exec.Command("wsl", "--list", "--verbose").CombinedOutput()
+	output, err := wslexe.Run(context.Background(), "--list", "--verbose")
 	if err != nil {
 		logrus.Errorf("Failed to run 'wsl --list --verbose': %s\n", err)
 		return false
 	}
-	decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
-	output, err := decoder.Bytes(rawOutput)
-	if err != nil {
-		logrus.Errorf("Failed to read WSL output ([% q]...); error: %s\n", rawOutput[:12], err)
-		return false
-	}
 	isListed := false
 	targetState := ""
-	for _, line := range regexp.MustCompile(`\r?\n`).Split(string(output), -1) {
+	for _, line := range strings.Split(output, "\n") {
 		fields := regexp.MustCompile(`\s+`).Split(strings.TrimLeft(line, " \t"), -1)
 		if fields[0] == "*" {
 			fields = fields[1:]
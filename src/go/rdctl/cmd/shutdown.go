@@ -17,18 +17,22 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shutdown"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 type shutdownSettingsStruct struct {
-	Verbose         bool
-	WaitForShutdown bool
+	Verbose        bool
+	StopContainers bool
+	CleanupOrphans bool
+	shutdown.Options
 }
 
 var commonShutdownSettings shutdownSettingsStruct
@@ -36,8 +40,14 @@ var commonShutdownSettings shutdownSettingsStruct
 // shutdownCmd represents the shutdown command
 var shutdownCmd = &cobra.Command{
 	Use:   "shutdown",
-	Short: "Shuts down the running Rancher Desktop application",
-	Long:  `Shuts down the running Rancher Desktop application.`,
+	Short: i18n.T("commands.shutdown.short"),
+	Long: `Shuts down the running Rancher Desktop application.
+Running containers are stopped first (using each container's stop timeout),
+unless --stop-containers=false is specified.
+Components that don't stop within the given timeouts are sent SIGTERM and then,
+after --term-timeout, SIGKILL.
+Use --cleanup-orphans to also stop VM processes (qemu, virtiofsd, ssh port
+forwarding, and the lima host agent) left behind by a previous crashed session.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.NoArgs(cmd, args); err != nil {
 			return err
@@ -45,14 +55,20 @@ var shutdownCmd = &cobra.Command{
 		if commonShutdownSettings.Verbose {
 			logrus.SetLevel(logrus.TraceLevel)
 		}
+		if err := commonShutdownSettings.Validate(); err != nil {
+			return err
+		}
 		cmd.SilenceUsage = true
-		result, err := doShutdown(&commonShutdownSettings, shutdown.Shutdown)
+		result, err := doShutdown(cmd.Context(), &commonShutdownSettings, shutdown.Shutdown)
 		if err != nil {
 			return err
 		}
 		if result != nil {
 			fmt.Println(string(result))
 		}
+		if commonShutdownSettings.CleanupOrphans {
+			return cleanupOrphans()
+		}
 		return nil
 	},
 }
@@ -61,16 +77,67 @@ func init() {
 	rootCmd.AddCommand(shutdownCmd)
 	shutdownCmd.Flags().BoolVar(&commonShutdownSettings.Verbose, "verbose", false, "be verbose")
 	shutdownCmd.Flags().BoolVar(&commonShutdownSettings.WaitForShutdown, "wait", true, "wait for shutdown to be confirmed")
+	shutdownCmd.Flags().BoolVar(&commonShutdownSettings.StopContainers, "stop-containers", true, "stop running containers before shutting down the VM")
+	shutdownCmd.Flags().BoolVar(&commonShutdownSettings.CleanupOrphans, "cleanup-orphans", false, "also stop VM processes left behind by a previous session (macOS and Linux only)")
+	addShutdownTimeoutFlags(shutdownCmd)
+}
+
+// addShutdownTimeoutFlags adds the flags controlling how long to wait for the
+// components to stop; they are shared by `shutdown` and `factory-reset`.
+func addShutdownTimeoutFlags(cmd *cobra.Command) {
+	defaults := shutdown.DefaultOptions()
+	cmd.Flags().DurationVar(&commonShutdownSettings.VMTimeout, "vm-timeout", defaults.VMTimeout, "how long to wait for the VM to stop before killing it")
+	cmd.Flags().DurationVar(&commonShutdownSettings.AppTimeout, "app-timeout", defaults.AppTimeout, "how long to wait for the application to exit before killing it")
+	cmd.Flags().DurationVar(&commonShutdownSettings.PollInterval, "poll-interval", defaults.PollInterval, "how often to check whether a component has stopped")
+	cmd.Flags().DurationVar(&commonShutdownSettings.TermTimeout, "term-timeout", defaults.TermTimeout, "how long to wait after SIGTERM before sending SIGKILL (0 to send SIGKILL right away)")
 }
 
-func doShutdown(shutdownSettings *shutdownSettingsStruct, initiatingCommand shutdown.InitiatingCommand) ([]byte, error) {
+func doShutdown(ctx context.Context, shutdownSettings *shutdownSettingsStruct, initiatingCommand shutdown.InitiatingCommand) ([]byte, error) {
 	var output []byte
 	connectionInfo, err := config.GetConnectionInfo(true)
 	if err == nil && connectionInfo != nil {
 		rdClient := client.NewRDClient(connectionInfo)
-		request, err := rdClient.DoRequest("PUT", client.VersionCommand("", "shutdown"))
+		if shutdownSettings.StopContainers {
+			stopContainers(ctx, rdClient)
+		}
+		request, err := rdClient.DoRequest(ctx, "PUT", client.VersionCommand("", "shutdown"))
 		output, _ = client.ProcessRequestForUtility(request, err)
 	}
-	err = shutdown.FinishShutdown(shutdownSettings.WaitForShutdown, initiatingCommand)
+	err = shutdown.FinishShutdown(ctx, shutdownSettings.Options, initiatingCommand)
 	return output, err
 }
+
+// stopContainers stops the running containers of the current container engine.
+// Failures are logged but don't prevent the shutdown.
+func stopContainers(ctx context.Context, rdClient client.RDClient) {
+	settings, err := rdClient.GetSettings(ctx)
+	if err != nil {
+		logrus.Errorf("Not stopping containers: %s", err)
+		return
+	}
+	if settings.ContainerEngine == nil || settings.ContainerEngine.Name == nil {
+		logrus.Errorf("Not stopping containers: the current settings have no container engine")
+		return
+	}
+	if err = shutdown.StopContainers(*settings.ContainerEngine.Name); err != nil {
+		logrus.Errorf("Ignoring error trying to stop containers: %s", err)
+	}
+}
+
+func cleanupOrphans() error {
+	orphans, err := shutdown.FindOrphans()
+	if err != nil {
+		return err
+	}
+	stopped, err := shutdown.CleanupOrphans(orphans, commonShutdownSettings.Options)
+	for _, orphan := range stopped {
+		fmt.Printf("Stopped orphaned %s process %d: %s\n", orphan.Kind, orphan.PID, orphan.Command)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stop orphaned processes: %w", err)
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned processes found.")
+	}
+	return nil
+}
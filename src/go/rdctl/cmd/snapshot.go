@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +17,7 @@ var outputJsonFormat bool
 
 var snapshotCmd = &cobra.Command{
 	Use:   "snapshot",
-	Short: "Manage Rancher Desktop snapshots",
+	Short: i18n.T("commands.snapshot.short"),
 }
 
 func init() {
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"os/exec"
 	"runtime"
 )
 
@@ -13,11 +15,11 @@ var snapshotDescription string
 
 var snapshotCreateCmd = &cobra.Command{
 	Use:   "create <name>",
-	Short: "Create a snapshot",
+	Short: i18n.T("commands.snapshot.create.short"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return exitWithJsonOrErrorCondition(createSnapshot(args))
+		return exitWithJsonOrErrorCondition(createSnapshot(cmd.Context(), args))
 	},
 }
 
@@ -27,7 +29,7 @@ func init() {
 	snapshotCreateCmd.Flags().StringVar(&snapshotDescription, "description", "", "snapshot description")
 }
 
-func createSnapshot(args []string) error {
+func createSnapshot(ctx context.Context, args []string) error {
 	name := args[0]
 	manager, err := snapshot.NewManager()
 	if err != nil {
@@ -38,7 +40,7 @@ func createSnapshot(args []string) error {
 		return nil
 	}
 
-	if _, err := manager.Create(name, snapshotDescription); err != nil {
+	if _, err := manager.Create(ctx, name, snapshotDescription); err != nil {
 		return fmt.Errorf("failed to create snapshot: %w", err)
 	}
 
@@ -46,7 +48,7 @@ func createSnapshot(args []string) error {
 	if runtime.GOOS != "darwin" {
 		return nil
 	}
-	execCmd := exec.Command("tmutil", "addexclusion", manager.Paths.Snapshots)
+	execCmd := execctx.Command(ctx, 0, "tmutil", "addexclusion", manager.Paths.Snapshots)
 	output, err := execCmd.CombinedOutput()
 	if err != nil {
 		msg := fmt.Errorf("`tmutil addexclusion` failed to add exclusion to TimeMachine: %w: %s", err, output)
@@ -3,13 +3,15 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/terminal"
 	"github.com/spf13/cobra"
 )
 
 var snapshotDeleteCmd = &cobra.Command{
 	Use:   "delete <id>",
-	Short: "Delete a snapshot",
+	Short: i18n.T("commands.snapshot.delete.short"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -18,9 +20,12 @@ var snapshotDeleteCmd = &cobra.Command{
 	},
 }
 
+var snapshotDeleteYes bool
+
 func init() {
 	snapshotCmd.AddCommand(snapshotDeleteCmd)
 	snapshotDeleteCmd.Flags().BoolVarP(&outputJsonFormat, "json", "", false, "output json format")
+	snapshotDeleteCmd.Flags().BoolVarP(&snapshotDeleteYes, "yes", "y", false, "don't ask for confirmation (required when not run interactively)")
 }
 
 func deleteSnapshot(_ *cobra.Command, args []string) error {
@@ -28,6 +33,13 @@ func deleteSnapshot(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create snapshot manager: %w", err)
 	}
+	// Don't ask about a snapshot that doesn't exist.
+	if _, err = manager.Snapshot(args[0]); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if err := terminal.Confirm(i18n.T("snapshot.confirmDelete", i18n.Args{"name": args[0]}), snapshotDeleteYes); err != nil {
+		return err
+	}
 	if err = manager.Delete(args[0]); err != nil {
 		return fmt.Errorf("failed to delete snapshot: %w", err)
 	}
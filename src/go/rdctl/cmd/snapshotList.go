@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
 	"github.com/spf13/cobra"
 )
@@ -33,11 +35,11 @@ func (snapshots SortableSnapshots) Swap(i, j int) {
 var snapshotListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List snapshots",
+	Short:   i18n.T("commands.snapshot.list.short"),
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return exitWithJsonOrErrorCondition(listSnapshot())
+		return exitWithJsonOrErrorCondition(listSnapshot(cmd.Context()))
 	},
 }
 
@@ -46,11 +48,16 @@ func init() {
 	snapshotListCmd.Flags().BoolVar(&outputJsonFormat, "json", false, "output json format")
 }
 
-func listSnapshot() error {
+func listSnapshot(ctx context.Context) error {
 	manager, err := snapshot.NewManager()
 	if err != nil {
 		return fmt.Errorf("failed to create snapshot manager: %w", err)
 	}
+	unlock, err := manager.RLock(ctx, manager.Paths)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	snapshots, err := manager.List(false)
 	if err != nil {
 		return fmt.Errorf("failed to list snapshots: %w", err)
@@ -76,7 +83,7 @@ func jsonOutput(snapshots []snapshot.Snapshot) error {
 
 func tabularOutput(snapshots []snapshot.Snapshot) error {
 	if len(snapshots) == 0 {
-		fmt.Fprintln(os.Stderr, "No snapshots present.")
+		fmt.Fprintln(os.Stderr, i18n.T("snapshot.noSnapshots"))
 		return nil
 	}
 	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
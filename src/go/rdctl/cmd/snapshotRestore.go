@@ -2,14 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/terminal"
 
 	"github.com/spf13/cobra"
 )
 
 var snapshotRestoreCmd = &cobra.Command{
 	Use:   "restore <id>",
-	Short: "Restore a snapshot",
+	Short: i18n.T("commands.snapshot.restore.short"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -17,9 +19,12 @@ var snapshotRestoreCmd = &cobra.Command{
 	},
 }
 
+var snapshotRestoreYes bool
+
 func init() {
 	snapshotCmd.AddCommand(snapshotRestoreCmd)
 	snapshotRestoreCmd.Flags().BoolVarP(&outputJsonFormat, "json", "", false, "output json format")
+	snapshotRestoreCmd.Flags().BoolVarP(&snapshotRestoreYes, "yes", "y", false, "don't ask for confirmation (required when not run interactively)")
 }
 
 func restoreSnapshot(cmd *cobra.Command, args []string) error {
@@ -27,7 +32,14 @@ func restoreSnapshot(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create snapshot manager: %w", err)
 	}
-	if err := manager.Restore(args[0]); err != nil {
+	// Don't ask about a snapshot that doesn't exist.
+	if _, err := manager.Snapshot(args[0]); err != nil {
+		return fmt.Errorf("failed to restore snapshot %q: %w", args[0], err)
+	}
+	if err := terminal.Confirm(i18n.T("snapshot.confirmRestore", i18n.Args{"name": args[0]}), snapshotRestoreYes); err != nil {
+		return err
+	}
+	if err := manager.Restore(cmd.Context(), args[0]); err != nil {
 		return fmt.Errorf("failed to restore snapshot %q: %w", args[0], err)
 	}
 	return nil
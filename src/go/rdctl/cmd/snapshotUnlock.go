@@ -2,13 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
 	"github.com/spf13/cobra"
 )
 
 var snapshotUnlockCmd = &cobra.Command{
 	Use:   "unlock",
-	Short: "Remove snapshot lock",
+	Short: i18n.T("commands.snapshot.unlock.short"),
 	Long: `If an error occurs while doing a snapshot operation, the filesystem
 lock that is used to prevent simultaneous snapshot operations can be
 left behind. It then becomes impossible to run any snapshot operations.
@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -32,7 +35,7 @@ import (
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
-	Short: "Start up Rancher Desktop, or update its settings.",
+	Short: i18n.T("commands.start.short"),
 	Long: `Starts up Rancher Desktop with the specified settings.
 If it's running, behaves the same as 'rdctl set ...'.
 `,
@@ -58,7 +61,7 @@ func init() {
  * If Rancher Desktop is currently running, treat this like a `set` command, and pass all the args to that.
  */
 func doStartOrSetCommand(cmd *cobra.Command) error {
-	_, err := getListSettings()
+	_, err := getListSettings(cmd.Context())
 	if err == nil {
 		// Unavoidable race condition here.
 		// There's no system-wide mutex that will let us guarantee that if rancher desktop is running when
@@ -68,7 +71,7 @@ func doStartOrSetCommand(cmd *cobra.Command) error {
 			// `--path | -p` is not a valid option for `rdctl set...`
 			return fmt.Errorf("--path %q specified but Rancher Desktop is already running", applicationPath)
 		}
-		return doSetCommand(cmd)
+		return doSetCommand(cmd, nil)
 	}
 	cmd.SilenceUsage = true
 	return doStartCommand(cmd)
@@ -88,9 +91,24 @@ func doStartCommand(cmd *cobra.Command) error {
 	if noModalDialogs {
 		commandLineArgs = append(commandLineArgs, "--no-modal-dialogs")
 	}
+	warnAboutStaleData()
 	return launchApp(applicationPath, commandLineArgs)
 }
 
+// warnAboutStaleData points out the VM disks and cache files left in the home
+// directory after they moved to a local disk, as they can take a lot of space.
+func warnAboutStaleData() {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		// The application will report this when it starts.
+		return
+	}
+	for _, dir := range appPaths.StaleData {
+		logrus.Warnf("%s is no longer used: the home directory is on a network file system, so the VM disks and cache are kept in %s instead. "+
+			"Move its contents there while Rancher Desktop is shut down to keep them, or remove it to free up space.", dir, filepath.Dir(appPaths.Cache))
+	}
+}
+
 func launchApp(applicationPath string, commandLineArgs []string) error {
 	var commandName string
 	var args []string
@@ -98,6 +116,11 @@ func launchApp(applicationPath string, commandLineArgs []string) error {
 	if runtime.GOOS == "darwin" {
 		commandName = "/usr/bin/open"
 		args = []string{"-a", applicationPath}
+		if instance := os.Getenv(paths.InstanceEnvVar); instance != "" {
+			// `open` doesn't pass our environment on, and would otherwise just
+			// activate an already-running default instance.
+			args = append(args, "-n", "--env", fmt.Sprintf("%s=%s", paths.InstanceEnvVar, instance))
+		}
 		if len(commandLineArgs) > 0 {
 			args = append(args, "--args")
 			args = append(args, commandLineArgs...)
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var telemetryEndpoint string
+var telemetryStatusJSON bool
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: i18n.T("commands.telemetry.short"),
+	Long: fmt.Sprintf(`Control whether rdctl reports how its commands are used.  This is off unless
+you enable it.  Once enabled, each command sends an anonymous report to the
+configured endpoint: a random ID, the rdctl version, the OS and architecture,
+the command and the names of the flags given, how long it took, and whether it
+failed (and how, e.g. "connection" or "timeout").  Arguments, flag values,
+paths and error messages are never sent.  Setting $%s overrides the endpoint;
+setting $%s turns reporting off regardless.`, telemetry.EndpointEnvVar, telemetry.DoNotTrackEnvVar),
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: i18n.T("commands.telemetry.status.short"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		appPaths, err := paths.GetPaths()
+		if err != nil {
+			return fmt.Errorf("failed to get paths: %w", err)
+		}
+		config, err := telemetry.Load(appPaths)
+		if err != nil {
+			return err
+		}
+		if telemetryStatusJSON {
+			return json.NewEncoder(os.Stdout).Encode(struct {
+				telemetry.Config
+				Endpoint   string `json:"endpoint,omitempty"`
+				DoNotTrack bool   `json:"doNotTrack"`
+				Active     bool   `json:"active"`
+			}{config, config.EffectiveEndpoint(), telemetry.DoNotTrack(), config.Active()})
+		}
+		switch {
+		case !config.Enabled:
+			fmt.Println(i18n.T("telemetry.disabled"))
+		case config.Active():
+			fmt.Println(i18n.T("telemetry.enabled", i18n.Args{"endpoint": config.EffectiveEndpoint()}))
+		case telemetry.DoNotTrack():
+			fmt.Println(i18n.T("telemetry.doNotTrack", i18n.Args{"variable": telemetry.DoNotTrackEnvVar}))
+		default:
+			fmt.Println(i18n.T("telemetry.noEndpoint"))
+		}
+		return nil
+	},
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: i18n.T("commands.telemetry.enable.short"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if telemetryEndpoint != "" {
+			if u, err := url.Parse(telemetryEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid --endpoint %q: must be an http or https URL", telemetryEndpoint)
+			}
+		}
+		cmd.SilenceUsage = true
+		appPaths, err := paths.GetPaths()
+		if err != nil {
+			return fmt.Errorf("failed to get paths: %w", err)
+		}
+		config, err := telemetry.Enable(appPaths, telemetryEndpoint)
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("telemetry.enabled", i18n.Args{"endpoint": config.EffectiveEndpoint()}))
+		return nil
+	},
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: i18n.T("commands.telemetry.disable.short"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		appPaths, err := paths.GetPaths()
+		if err != nil {
+			return fmt.Errorf("failed to get paths: %w", err)
+		}
+		if err := telemetry.Disable(appPaths); err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("telemetry.disabled"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd, telemetryEnableCmd, telemetryDisableCmd)
+	telemetryStatusCmd.Flags().BoolVar(&telemetryStatusJSON, "json", false, "output json format")
+	telemetryEnableCmd.Flags().StringVar(&telemetryEndpoint, "endpoint", "", "URL to send the reports to (default: the previously configured one)")
+}
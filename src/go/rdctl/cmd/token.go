@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: i18n.T("commands.token.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: i18n.T("commands.update.short"),
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+}
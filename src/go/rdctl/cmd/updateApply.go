@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/signature"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// updatePublicKeyEnvVar names the public key used to verify updates when
+// --public-key isn't given.
+const updatePublicKeyEnvVar = "RD_UPDATE_PUBLIC_KEY"
+
+var updateApplySpecs struct {
+	verify        bool
+	publicKey     string
+	signaturePath string
+}
+
+var updateApplyCmd = &cobra.Command{
+	Use:   "apply <artifact>",
+	Short: i18n.T("commands.update.apply.short"),
+	Long: fmt.Sprintf(`Install a downloaded Rancher Desktop update, after checking its signature.
+
+The signature can be made with minisign, or with "cosign sign-blob --key"; the
+format is chosen by the public key, which is either a minisign public key or a
+PEM-encoded one.  Unless --signature is given, it is read from <artifact>.minisig
+or <artifact>.sig respectively.
+
+Verification is on by default when a public key is configured, with --public-key
+or $%s, and can be turned off with --verify=false.
+
+Only updates installed with this command are checked this way; the updater
+built into the application relies on its own checks.`, updatePublicKeyEnvVar),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if updateApplySpecs.publicKey == "" {
+			updateApplySpecs.publicKey = os.Getenv(updatePublicKeyEnvVar)
+		}
+		if !cmd.Flags().Changed("verify") {
+			updateApplySpecs.verify = updateApplySpecs.publicKey != ""
+		} else if updateApplySpecs.verify && updateApplySpecs.publicKey == "" {
+			return fmt.Errorf("--verify requires a public key; specify one with --public-key or $%s", updatePublicKeyEnvVar)
+		}
+		cmd.SilenceUsage = true
+		return applyUpdate(args[0])
+	},
+}
+
+func init() {
+	updateCmd.AddCommand(updateApplyCmd)
+	updateApplyCmd.Flags().BoolVar(&updateApplySpecs.verify, "verify", false, "check the signature of the artifact before installing it (default: true if a public key is configured)")
+	updateApplyCmd.Flags().StringVar(&updateApplySpecs.publicKey, "public-key", "", fmt.Sprintf("path to the minisign or cosign public key (default: $%s)", updatePublicKeyEnvVar))
+	updateApplyCmd.Flags().StringVar(&updateApplySpecs.signaturePath, "signature", "", "path to the signature (default: next to the artifact)")
+}
+
+func applyUpdate(artifactPath string) error {
+	stagingDir, err := os.MkdirTemp("", "rd-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	if removeStagedUpdate {
+		defer os.RemoveAll(stagingDir)
+	}
+	staged, err := stageUpdate(artifactPath, stagingDir)
+	if err != nil {
+		return err
+	}
+	defer staged.Close()
+	if updateApplySpecs.verify {
+		key, err := signature.ReadPublicKey(updateApplySpecs.publicKey)
+		if err != nil {
+			return err
+		}
+		signaturePath := updateApplySpecs.signaturePath
+		if signaturePath == "" {
+			signaturePath = signature.DefaultSignaturePath(key, artifactPath)
+		}
+		if err := signature.VerifyOpenFile(key, staged, signaturePath); err != nil {
+			if errors.Is(err, signature.ErrVerificationFailed) {
+				return fmt.Errorf("refusing to install the update: %w", err)
+			}
+			return err
+		}
+		fmt.Println(i18n.T("update.verified", i18n.Args{"artifact": artifactPath, "format": key.Format()}))
+	} else {
+		logrus.Warn(i18n.T("update.notVerified", i18n.Args{"artifact": artifactPath}))
+	}
+	return runInstaller(staged.Name())
+}
+
+// stageUpdate copies the update into the staging directory, which only the
+// current user can write to, and returns the copy, open at the start.  The copy
+// is what gets verified and installed, so that the update can't be replaced
+// in between.
+func stageUpdate(artifactPath, stagingDir string) (*os.File, error) {
+	artifact, err := os.Open(artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find update: %w", err)
+	}
+	defer artifact.Close()
+	staged, err := os.OpenFile(filepath.Join(stagingDir, filepath.Base(artifactPath)), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage update: %w", err)
+	}
+	if _, err := io.Copy(staged, artifact); err != nil {
+		staged.Close()
+		return nil, fmt.Errorf("failed to stage update: %w", err)
+	}
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		staged.Close()
+		return nil, fmt.Errorf("failed to stage update: %w", err)
+	}
+	return staged, nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// removeStagedUpdate is set if the staged copy of the update can be removed
+// once runInstaller returns.  The disk image is opened in the background, so
+// the copy is left for the system to clean up with the other temporary files.
+const removeStagedUpdate = false
+
+// runInstaller opens the disk image, so that the application can be dragged to
+// the Applications folder.
+func runInstaller(artifactPath string) error {
+	if !strings.EqualFold(filepath.Ext(artifactPath), ".dmg") {
+		return fmt.Errorf("%s is not a disk image (.dmg) file", artifactPath)
+	}
+	if output, err := exec.Command("/usr/bin/open", artifactPath).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open %s: %w: %s", artifactPath, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// removeStagedUpdate is set if the staged copy of the update can be removed
+// once runInstaller returns.
+const removeStagedUpdate = true
+
+// runInstaller fails: on Linux, Rancher Desktop is installed by the system
+// package manager, or as an AppImage that updates itself.
+func runInstaller(artifactPath string) error {
+	return fmt.Errorf("installing updates is not supported on Linux; install %s with the system package manager", filepath.Base(artifactPath))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// removeStagedUpdate is set if the staged copy of the update can be removed
+// once runInstaller returns.
+const removeStagedUpdate = true
+
+// runInstaller runs the MSI the same way the application does when it applies
+// an update itself.
+func runInstaller(artifactPath string) error {
+	if !strings.EqualFold(filepath.Ext(artifactPath), ".msi") {
+		return fmt.Errorf("%s is not a Windows installer (.msi) file", artifactPath)
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	systemRoot := os.Getenv("SystemRoot")
+	if systemRoot == "" {
+		systemRoot = `C:\Windows`
+	}
+	cmd := exec.Command(filepath.Join(systemRoot, "system32", "msiexec.exe"),
+		"/norestart", "/lv*", filepath.Join(appPaths.Logs, "msiexec.log"),
+		"/i", artifactPath, "/passive")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run the installer (see %s): %w", filepath.Join(appPaths.Logs, "msiexec.log"), err)
+	}
+	return nil
+}
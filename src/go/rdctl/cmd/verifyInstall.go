@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/provenance"
+	"github.com/spf13/cobra"
+)
+
+var verifyInstallJSON bool
+
+var verifyInstallCmd = &cobra.Command{
+	Use:   "verify-install",
+	Short: i18n.T("commands.verifyInstall.short"),
+	Long: `Check the helper executables of this Rancher Desktop installation against the
+manifest recorded in rdctl when it was built.  A helper that differs from the
+build was tampered with or left over from a partial upgrade; one that isn't in
+the manifest is likely left over from another version.
+
+Code signatures are not part of the check, so re-signed helpers still match.
+Development builds have no manifest.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return verifyInstall(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyInstallCmd)
+	verifyInstallCmd.Flags().BoolVar(&verifyInstallJSON, "json", false, "output json format")
+}
+
+func verifyInstall(ctx context.Context) error {
+	manifest, err := provenance.Embedded()
+	if err != nil {
+		return err
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	results, err := manifest.Verify(ctx, appPaths.Resources)
+	if err != nil {
+		return err
+	}
+	commit, modified := provenance.Commit()
+	failed := 0
+	for _, result := range results {
+		if result.Status != provenance.StatusOK {
+			failed++
+		}
+	}
+
+	if verifyInstallJSON {
+		err = json.NewEncoder(os.Stdout).Encode(struct {
+			Commit   string              `json:"commit,omitempty"`
+			Modified bool                `json:"modified,omitempty"`
+			Builder  string              `json:"builder"`
+			Files    []provenance.Result `json:"files"`
+		}{commit, modified, manifest.Builder, results})
+		if err != nil {
+			return err
+		}
+	} else {
+		if commit == "" {
+			commit = "unknown"
+		} else if modified {
+			commit += "-dirty"
+		}
+		fmt.Println(i18n.T("verifyInstall.build", i18n.Args{"commit": commit, "builder": manifest.Builder}))
+		for _, result := range results {
+			if result.Status != provenance.StatusOK {
+				fmt.Println(i18n.T("verifyInstall."+result.Status, i18n.Args{"path": result.Path}))
+			}
+		}
+		if failed == 0 {
+			fmt.Println(i18n.T("verifyInstall.ok", i18n.Args{"count": len(results)}))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d helper executables do not match the build", failed, len(results))
+	}
+	return nil
+}
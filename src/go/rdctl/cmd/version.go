@@ -18,17 +18,22 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/fips"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 // showVersionCmd represents the showVersion command
 var showVersionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Shows the CLI version.",
+	Short: i18n.T("commands.version.short"),
 	Long:  `Shows the CLI version.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		_, err := fmt.Printf("rdctl client version: %s, targeting server version: %s\n", client.Version, client.ApiVersion)
+		if err == nil && fips.Required() {
+			_, err = fmt.Printf("FIPS mode: cryptography provided by %s\n", fips.Backend())
+		}
 		return err
 	},
 }
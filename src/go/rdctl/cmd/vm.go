@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var vmWait bool
+
+var vmCmd = &cobra.Command{
+	Use:   "vm",
+	Short: i18n.T("commands.vm.short"),
+	Long: `Start, stop, restart or pause the VM, leaving the application itself
+running.  The commands return once the action has started; use --wait to wait
+for it to finish.  On Windows, compact-disk shrinks the data disk.`,
+}
+
+// vmAction describes one of the `rdctl vm` subcommands.
+type vmAction struct {
+	name  string
+	short string
+	// change asks the backend to carry out the action.
+	change func(*client.RDClientImpl, context.Context) (string, error)
+	// transient lists the states the VM goes through before reaching one of
+	// the final states, for actions that start out in a final state; --wait
+	// waits for one of these first.
+	transient []string
+	final     []string
+}
+
+var vmActions = []vmAction{
+	{
+		name:   "start",
+		short:  i18n.T("commands.vm.start.short"),
+		change: (*client.RDClientImpl).StartVM,
+		final:  []string{"STARTED", "DISABLED"},
+	},
+	{
+		name:   "stop",
+		short:  i18n.T("commands.vm.stop.short"),
+		change: (*client.RDClientImpl).StopVM,
+		final:  []string{"STOPPED"},
+	},
+	{
+		name:      "restart",
+		short:     i18n.T("commands.vm.restart.short"),
+		change:    (*client.RDClientImpl).RestartVM,
+		transient: []string{"STOPPING", "STOPPED", "STARTING"},
+		final:     []string{"STARTED", "DISABLED"},
+	},
+	{
+		name:   "pause",
+		short:  i18n.T("commands.vm.pause.short"),
+		change: (*client.RDClientImpl).PauseVM,
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vmCmd)
+	for _, action := range vmActions {
+		action := action
+		actionCmd := &cobra.Command{
+			Use:   action.name,
+			Short: action.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cmd.SilenceUsage = true
+				return changeVMState(cmd.Context(), action)
+			},
+		}
+		if len(action.final) > 0 {
+			actionCmd.Flags().BoolVar(&vmWait, "wait", false, "wait for the action to finish")
+		}
+		vmCmd.AddCommand(actionCmd)
+	}
+}
+
+func changeVMState(ctx context.Context, action vmAction) error {
+	connectionInfo, err := config.GetConnectionInfo(false)
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	message, err := action.change(rdClient, ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(strings.TrimSpace(message))
+	if !vmWait || len(action.final) == 0 {
+		return nil
+	}
+	if len(action.transient) > 0 {
+		if err := rdClient.WaitForVMState(ctx, action.transient...); err != nil {
+			return err
+		}
+	}
+	return rdClient.WaitForVMState(ctx, action.final...)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/spf13/cobra"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/lock"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/wsl"
+)
+
+var vmCompactDiskCmd = &cobra.Command{
+	Use:   "compact-disk",
+	Short: i18n.T("commands.vm.compactDisk.short"),
+	Long: `WSL grows the virtual disk holding images and containers as needed, but
+never shrinks it when they are deleted.  This stops the VM (if Rancher Desktop
+is running), compacts the disk, and starts the VM again.  This must be run as
+an administrator.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return compactDisk(cmd.Context())
+	},
+}
+
+func init() {
+	vmCmd.AddCommand(vmCompactDiskCmd)
+}
+
+func compactDisk(ctx context.Context) (err error) {
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	diskPath := filepath.Join(appPaths.WslDistroData, "ext4.vhdx")
+	before, err := os.Stat(diskPath)
+	if err != nil {
+		return fmt.Errorf("failed to find the data disk: %w", err)
+	}
+
+	locker := &lock.BackendLock{}
+	if err := locker.Lock(ctx, appPaths, "compact-disk"); err != nil {
+		return err
+	}
+	defer func() {
+		// The disk is left intact if compaction fails, so restart either way.
+		if unlockErr := locker.Unlock(appPaths, true); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	// Stopping the backend doesn't release the disk; the distributions must
+	// not be running for it to be compacted.
+	wslImpl := wsl.WSLImpl{}
+	for _, distro := range []string{paths.MainDistro(), paths.DataDistro()} {
+		if err := wslImpl.TerminateDistro(distro); err != nil {
+			return err
+		}
+	}
+	if err := wsl.CompactVHDX(ctx, diskPath); err != nil {
+		return err
+	}
+
+	after, err := os.Stat(diskPath)
+	if err != nil {
+		return fmt.Errorf("failed to find the data disk: %w", err)
+	}
+	fmt.Printf("Compacted %s from %s to %s.\n", diskPath, formatGiB(before.Size()), formatGiB(after.Size()))
+	return nil
+}
+
+func formatGiB(size int64) string {
+	return fmt.Sprintf("%.2f GiB", float64(size)/(1<<30))
+}
@@ -3,21 +3,55 @@ module github.com/rancher-sandbox/rancher-desktop/src/go/rdctl
 go 1.21
 
 require (
+	github.com/Microsoft/go-winio v0.5.2
 	github.com/adrg/xdg v0.4.0
 	github.com/docker/docker v20.10.22+incompatible
-	github.com/google/uuid v1.3.1
+	github.com/google/uuid v1.6.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging v0.0.0
 	github.com/rancher-sandbox/rancher-desktop/src/go/privileged-service v0.0.0-20221207202230-8eef0a706010
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/wslexe v0.0.0
 	github.com/sirupsen/logrus v1.9.0
 	github.com/spf13/cobra v1.6.1
 	github.com/spf13/pflag v1.0.5
-	github.com/stretchr/testify v1.8.1
-	golang.org/x/sys v0.10.0
-	golang.org/x/text v0.6.0
+	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/sys v0.21.0
+	golang.org/x/term v0.21.0
+	golang.org/x/text v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.20.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.28.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	go.opentelemetry.io/proto/otlp v1.3.1 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20240701130421-f6361c86f094 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240701130421-f6361c86f094 // indirect
+	google.golang.org/grpc v1.64.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+replace (
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx => ../execctx
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips => ../fips
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging => ../logging
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling => ../profiling
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry => ../retry
+	github.com/rancher-sandbox/rancher-desktop/src/go/wslexe => ../wslexe
 )
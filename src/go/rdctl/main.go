@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/cmd"
 )
 
 func main() {
+	defer logging.HandlePanic()
 	cmd.Execute()
 }
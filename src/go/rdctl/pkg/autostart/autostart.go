@@ -0,0 +1,123 @@
+package autostart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Autostart mechanisms.  Which ones are available depends on the platform.
+const (
+	// MethodDefault keeps the currently configured mechanism, or uses the
+	// platform default if autostart isn't configured yet.
+	MethodDefault = ""
+	// MethodXDG uses an XDG autostart .desktop file (Linux).
+	MethodXDG = "xdg"
+	// MethodSystemd uses a systemd user unit (Linux).
+	MethodSystemd = "systemd"
+	// MethodLaunchAgent uses a LaunchAgent (macOS).
+	MethodLaunchAgent = "launch-agent"
+	// MethodRegistry uses the Run registry key (Windows).
+	MethodRegistry = "registry"
+	// MethodTaskScheduler uses a scheduled task (Windows).
+	MethodTaskScheduler = "task-scheduler"
+)
+
+// Options controls how Rancher Desktop is started at login.
+type Options struct {
+	// Method selects the autostart mechanism.  When autostart is enabled with
+	// one mechanism, any others are removed.
+	Method string `json:"method,omitempty"`
+	// Delay postpones the start after login.  Not supported by the registry
+	// method; the XDG method relies on the desktop environment honouring
+	// X-GNOME-Autostart-Delay.
+	Delay time.Duration `json:"delay,omitempty"`
+	// HighestPrivileges runs the application with the highest privileges
+	// available to the user, for use with the privileged service; only
+	// supported by the Task Scheduler method.
+	HighestPrivileges bool `json:"highestPrivileges,omitempty"`
+	// StartInBackground starts the application minimized to the tray.
+	StartInBackground bool `json:"startInBackground,omitempty"`
+	// DisableKubernetes starts the application with Kubernetes disabled.
+	DisableKubernetes bool `json:"disableKubernetes,omitempty"`
+}
+
+// OptionsFileName is the name of the file (in the configuration directory)
+// recording the autostart options, so that they are kept when the application
+// re-applies the autostart setting.
+const OptionsFileName = "autostart.json"
+
+// LoadOptions reads the autostart options recorded in the given file; a
+// missing file yields the default options.
+func LoadOptions(path string) (Options, error) {
+	var options Options
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return options, nil
+	} else if err != nil {
+		return options, fmt.Errorf("failed to read autostart options: %w", err)
+	}
+	if err := json.Unmarshal(contents, &options); err != nil {
+		return options, fmt.Errorf("failed to parse autostart options %q: %w", path, err)
+	}
+	return options, nil
+}
+
+// SaveOptions records the autostart options in the given file.
+func SaveOptions(path string, options Options) error {
+	contents, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for autostart options: %w", err)
+	}
+	return os.WriteFile(path, append(contents, '\n'), 0o644)
+}
+
+// launchArguments returns the command-line arguments to pass to the
+// application.  The application applies `--autostart` overrides to that run
+// only, without changing the saved settings.
+func (options Options) launchArguments() []string {
+	var overrides []string
+	if options.StartInBackground {
+		overrides = append(overrides, "background")
+	}
+	if options.DisableKubernetes {
+		overrides = append(overrides, "no-kubernetes")
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+	return []string{"--autostart=" + strings.Join(overrides, ",")}
+}
+
+// delaySeconds returns the delay in whole seconds, rounding up.
+func (options Options) delaySeconds() int64 {
+	return int64((options.Delay + time.Second - 1) / time.Second)
+}
+
+// SupportedMethods returns the autostart mechanisms available on this platform.
+func SupportedMethods() []string {
+	return append([]string{}, supportedMethods...)
+}
+
+// Validate checks that the options are supported on this platform.
+func (options Options) Validate() error {
+	if options.Delay < 0 {
+		return fmt.Errorf("the autostart delay can't be negative (got %s)", options.Delay)
+	}
+	if options.Method == MethodDefault {
+		return nil
+	}
+	for _, method := range supportedMethods {
+		if options.Method == method {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported autostart method %q; must be one of: %s", options.Method, strings.Join(supportedMethods, ", "))
+}
@@ -2,6 +2,7 @@ package autostart
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -20,9 +21,9 @@ const launchAgentFileTemplateContents = `<?xml version="1.0" encoding="UTF-8"?>
     <string>io.rancherdesktop.autostart</string>
     <key>ProgramArguments</key>
     <array>
-        <string>/usr/bin/open</string>
-        <string>-a</string>
-        <string>{{ .RancherDesktopPath }}</string>
+{{- range .ProgramArguments }}
+        <string>{{ xml . }}</string>
+{{- end }}
     </array>
     <key>ProcessType</key>
     <string>Interactive</string>
@@ -34,17 +35,65 @@ const launchAgentFileTemplateContents = `<?xml version="1.0" encoding="UTF-8"?>
 </plist>
 `
 
+var supportedMethods = []string{MethodLaunchAgent}
+
 type launchAgentFileData struct {
-	RancherDesktopPath string
+	ProgramArguments []string
 }
 
-func EnsureAutostart(autostartDesired bool) error {
-	// get path to LaunchAgent file
+// getProgramArguments returns the command line the LaunchAgent runs.
+// launchd can't delay a job, so a delayed start goes through the shell.
+func getProgramArguments(rancherDesktopPath string, options Options) []string {
+	args := []string{"/usr/bin/open", "-a", rancherDesktopPath}
+	if launchArgs := options.launchArguments(); len(launchArgs) > 0 {
+		args = append(append(args, "--args"), launchArgs...)
+	}
+	if delay := options.delaySeconds(); delay > 0 {
+		script := fmt.Sprintf(`sleep %d && exec "$0" "$@"`, delay)
+		args = append([]string{"/bin/sh", "-c", script}, args...)
+	}
+	return args
+}
+
+func xmlEscape(value string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(value)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func getLaunchAgentFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to find home directory: %w", err)
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(homeDir, "Library", "LaunchAgents", "io.rancherdesktop.autostart.plist"), nil
+}
+
+// GetAutostartLocation returns the path of the LaunchAgent file, and whether it exists.
+func GetAutostartLocation() (string, bool, error) {
+	launchAgentFilePath, err := getLaunchAgentFilePath()
+	if err != nil {
+		return "", false, err
+	}
+	_, err = os.Stat(launchAgentFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return launchAgentFilePath, false, nil
+	}
+	return launchAgentFilePath, err == nil, err
+}
+
+// EnsureAutostart enables or disables starting Rancher Desktop at login.
+func EnsureAutostart(autostartDesired bool, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	// get path to LaunchAgent file
+	launchAgentFilePath, err := getLaunchAgentFilePath()
+	if err != nil {
+		return err
 	}
-	launchAgentFilePath := filepath.Join(homeDir, "Library", "LaunchAgents", "io.rancherdesktop.autostart.plist")
 
 	if autostartDesired {
 		// ensure LaunchAgent directory is created
@@ -61,7 +110,7 @@ func EnsureAutostart(autostartDesired bool) error {
 		}
 
 		// get desired contents of LaunchAgent file
-		desiredContents, err := getDesiredLaunchAgentFileContents()
+		desiredContents, err := getDesiredLaunchAgentFileContents(options)
 		if err != nil {
 			return fmt.Errorf("failed to get desired LaunchAgent file contents: %w", err)
 		}
@@ -83,20 +132,20 @@ func EnsureAutostart(autostartDesired bool) error {
 	return nil
 }
 
-func getDesiredLaunchAgentFileContents() ([]byte, error) {
+func getDesiredLaunchAgentFileContents(options Options) ([]byte, error) {
 	rancherDesktopPath, err := utils.GetRDPath()
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to get path to main Rancher Desktop executable: %w", err)
 	}
 
 	// get desired contents of LaunchAgent file
-	launchAgentFileTemplate, err := template.New("launchAgentFile").Parse(launchAgentFileTemplateContents)
+	launchAgentFileTemplate, err := template.New("launchAgentFile").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(launchAgentFileTemplateContents)
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to parse LaunchAgent file template: %w", err)
 	}
 	desiredContentsBuffer := &bytes.Buffer{}
 	templateData := launchAgentFileData{
-		RancherDesktopPath: rancherDesktopPath,
+		ProgramArguments: getProgramArguments(rancherDesktopPath, options),
 	}
 	err = launchAgentFileTemplate.ExecuteTemplate(desiredContentsBuffer, "launchAgentFile", templateData)
 	if err != nil {
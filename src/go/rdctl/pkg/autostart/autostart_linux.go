@@ -8,12 +8,16 @@ package autostart
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/adrg/xdg"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"text/template"
 )
 
@@ -27,8 +31,37 @@ StartupWMClass=Rancher Desktop
 Categories=Development;
 `
 
+// The systemd user unit is an alternative to the autostart .desktop file that
+// also works without a desktop environment that implements XDG autostart,
+// and restarts the application if it crashes.  It is tied to the graphical
+// session, so that it only starts once there is a display to connect to, and
+// is stopped (rather than restarted in a loop) when the session ends.
+const systemdUnitTemplateContents = `[Unit]
+Description=Rancher Desktop
+PartOf=graphical-session.target
+After=graphical-session.target
+
+[Service]
+{{- if .Delay }}
+ExecStartPre=/bin/sleep {{ .Delay }}
+{{- end }}
+ExecStart={{ .Exec }}
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=graphical-session.target
+`
+
+const systemdUnitName = "rancher-desktop.service"
+
+var supportedMethods = []string{MethodXDG, MethodSystemd}
+
 type autostartFileData struct {
 	Exec string
+	// Delay is the number of seconds to wait before starting; only used by
+	// the systemd user unit.
+	Delay int64
 }
 
 var autostartDirPath string
@@ -36,6 +69,32 @@ var autostartFilePath string
 var errApplicationFileNotFound = errors.New("failed to find application .desktop file")
 var applicationFileNameRegex *regexp.Regexp
 var autostartFileTemplate *template.Template
+var systemdUnitPath string
+var systemdUnitTemplate *template.Template
+
+// systemctl runs `systemctl --user` with the given arguments.
+var systemctl = func(args ...string) error {
+	output, err := execctx.Command(context.Background(), 0, "systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run systemctl --user %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// getSystemdExec returns the command line used to start the application from
+// the systemd user unit.
+var getSystemdExec = func() (string, error) {
+	rancherDesktopPath, err := utils.GetRDPath()
+	if err != nil {
+		// Fall back to the AppImage.
+		data, appImageErr := getAutostartFileData()
+		if appImageErr != nil {
+			return "", fmt.Errorf("failed to get path to Rancher Desktop: %w", err)
+		}
+		rancherDesktopPath = data.Exec
+	}
+	return fmt.Sprintf("%q", rancherDesktopPath), nil
+}
 
 func init() {
 	autostartDirPath = filepath.Join(xdg.ConfigHome, "autostart")
@@ -45,9 +104,52 @@ func init() {
 	// - appimagekit_f8f0a5bb1016c0e50d21af6c04672f3e-Rancher_Desktop.desktop
 	applicationFileNameRegex = regexp.MustCompile(`^.*[rR]ancher[-_][dD]esktop\.desktop$`)
 	autostartFileTemplate = template.Must(template.New("autostartDesktopFile").Parse(autostartFileTemplateContents))
+	systemdUnitPath = filepath.Join(xdg.ConfigHome, "systemd", "user", systemdUnitName)
+	systemdUnitTemplate = template.Must(template.New("systemdUnit").Parse(systemdUnitTemplateContents))
 }
 
-func EnsureAutostart(autostartDesired bool) error {
+// GetAutostartLocation returns the path of the systemd user unit if it exists,
+// or else the path of the autostart .desktop file, and whether it exists.
+func GetAutostartLocation() (string, bool, error) {
+	for _, path := range []string{systemdUnitPath, autostartFilePath} {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, true, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return path, false, err
+		}
+	}
+	return autostartFilePath, false, nil
+}
+
+// EnsureAutostart enables or disables starting Rancher Desktop at login.
+func EnsureAutostart(autostartDesired bool, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if !autostartDesired {
+		return errors.Join(ensureSystemdAutostart(false, options), ensureXDGAutostart(false, options))
+	}
+	method := options.Method
+	if method == MethodDefault {
+		method = MethodXDG
+		if _, err := os.Stat(systemdUnitPath); err == nil {
+			method = MethodSystemd
+		}
+	}
+	if method == MethodSystemd {
+		if err := ensureSystemdAutostart(true, options); err != nil {
+			return err
+		}
+		return ensureXDGAutostart(false, options)
+	}
+	if err := ensureXDGAutostart(true, options); err != nil {
+		return err
+	}
+	return ensureSystemdAutostart(false, options)
+}
+
+func ensureXDGAutostart(autostartDesired bool, options Options) error {
 	os.MkdirAll(autostartDirPath, 0755)
 
 	if autostartDesired {
@@ -55,7 +157,7 @@ func EnsureAutostart(autostartDesired bool) error {
 		if err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("failed to read current autostart .desktop file: %w", err)
 		}
-		desiredContents, err := getDesiredAutostartFileContents()
+		desiredContents, err := getDesiredAutostartFileContents(options)
 		if err != nil {
 			return fmt.Errorf("failed to get desired contents of autostart .desktop file: %w", err)
 		}
@@ -74,7 +176,58 @@ func EnsureAutostart(autostartDesired bool) error {
 	return nil
 }
 
-func getDesiredAutostartFileContents() ([]byte, error) {
+func ensureSystemdAutostart(autostartDesired bool, options Options) error {
+	if !autostartDesired {
+		if _, err := os.Stat(systemdUnitPath); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		// Disabling may fail if there is no user session manager; remove the
+		// unit file regardless.
+		disableErr := systemctl("disable", systemdUnitName)
+		if err := os.RemoveAll(systemdUnitPath); err != nil {
+			return fmt.Errorf("failed to remove systemd user unit: %w", err)
+		}
+		if disableErr != nil {
+			return disableErr
+		}
+		return systemctl("daemon-reload")
+	}
+
+	currentContents, err := os.ReadFile(systemdUnitPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read current systemd user unit: %w", err)
+	}
+	execCommand, err := getSystemdExec()
+	if err != nil {
+		return err
+	}
+	desiredContents := bytes.Buffer{}
+	data := autostartFileData{
+		Exec:  strings.Join(append([]string{execCommand}, options.launchArguments()...), " "),
+		Delay: options.delaySeconds(),
+	}
+	if err := systemdUnitTemplate.Execute(&desiredContents, data); err != nil {
+		return fmt.Errorf("failed to fill systemd user unit template: %w", err)
+	}
+	enableCommand := "enable"
+	if !bytes.Equal(currentContents, desiredContents.Bytes()) {
+		// Re-enable the unit, to replace any links made for a previous
+		// [Install] section (older versions used default.target).
+		enableCommand = "reenable"
+		if err := os.MkdirAll(filepath.Dir(systemdUnitPath), 0755); err != nil {
+			return fmt.Errorf("failed to create systemd user unit directory: %w", err)
+		}
+		if err := os.WriteFile(systemdUnitPath, desiredContents.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write systemd user unit: %w", err)
+		}
+		if err := systemctl("daemon-reload"); err != nil {
+			return err
+		}
+	}
+	return systemctl(enableCommand, systemdUnitName)
+}
+
+func getDesiredAutostartFileContents(options Options) ([]byte, error) {
 	// Look for existing application .desktop files in expected locations.
 	// This part applies to rpm, deb and AppImageLauncher installs.
 	// We use existing application .desktop files so that there is no
@@ -85,7 +238,7 @@ func getDesiredAutostartFileContents() ([]byte, error) {
 		if err != nil {
 			return []byte{}, fmt.Errorf("failed to read contents of application .desktop file %s: %w", applicationFilePath, err)
 		}
-		return contents, nil
+		return customizeDesktopEntry(contents, options), nil
 	} else if !errors.Is(err, errApplicationFileNotFound) {
 		return []byte{}, err
 	}
@@ -103,7 +256,46 @@ func getDesiredAutostartFileContents() ([]byte, error) {
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to fill autostart file template: %w", err)
 	}
-	return contents.Bytes(), nil
+	return customizeDesktopEntry(contents.Bytes(), options), nil
+}
+
+// customizeDesktopEntry adds the launch arguments to the command of the main
+// group of a .desktop file, and the delay as X-GNOME-Autostart-Delay.  The
+// arguments don't contain any characters that need quoting.
+func customizeDesktopEntry(contents []byte, options Options) []byte {
+	args := options.launchArguments()
+	delay := options.delaySeconds()
+	if len(args) == 0 && delay == 0 {
+		return contents
+	}
+	var result []string
+	inMainGroup := false
+	addDelay := func() {
+		if !inMainGroup || delay == 0 {
+			return
+		}
+		// Keep any blank lines separating the groups after the new entry.
+		end := len(result)
+		for end > 0 && strings.TrimSpace(result[end-1]) == "" {
+			end--
+		}
+		entry := fmt.Sprintf("X-GNOME-Autostart-Delay=%d", delay)
+		result = append(result[:end], append([]string{entry}, result[end:]...)...)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "[") {
+			addDelay()
+			inMainGroup = strings.TrimSpace(line) == "[Desktop Entry]"
+		} else if inMainGroup && strings.HasPrefix(line, "X-GNOME-Autostart-Delay=") {
+			continue
+		} else if inMainGroup && strings.HasPrefix(line, "Exec=") && len(args) > 0 {
+			line = strings.Join(append([]string{strings.TrimRight(line, " ")}, args...), " ")
+		}
+		result = append(result, line)
+	}
+	addDelay()
+	return []byte(strings.Join(result, "\n") + "\n")
 }
 
 // Searches the system for a valid application .desktop file,
@@ -0,0 +1,86 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureAutostartSystemd(t *testing.T) {
+	savedAutostartDirPath, savedAutostartFilePath, savedSystemdUnitPath := autostartDirPath, autostartFilePath, systemdUnitPath
+	savedSystemctl, savedGetSystemdExec := systemctl, getSystemdExec
+	t.Cleanup(func() {
+		autostartDirPath, autostartFilePath, systemdUnitPath = savedAutostartDirPath, savedAutostartFilePath, savedSystemdUnitPath
+		systemctl, getSystemdExec = savedSystemctl, savedGetSystemdExec
+	})
+	configHome := t.TempDir()
+	autostartDirPath = filepath.Join(configHome, "autostart")
+	autostartFilePath = filepath.Join(autostartDirPath, "rancher-desktop.desktop")
+	systemdUnitPath = filepath.Join(configHome, "systemd", "user", systemdUnitName)
+	var calls [][]string
+	systemctl = func(args ...string) error {
+		calls = append(calls, args)
+		return nil
+	}
+	getSystemdExec = func() (string, error) {
+		return `"/opt/rancher-desktop/rancher-desktop"`, nil
+	}
+	require.NoError(t, os.MkdirAll(autostartDirPath, 0o755))
+	require.NoError(t, os.WriteFile(autostartFilePath, []byte("[Desktop Entry]\n"), 0o644))
+
+	require.NoError(t, EnsureAutostart(true, Options{Method: MethodSystemd}))
+	contents, err := os.ReadFile(systemdUnitPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "ExecStart=\"/opt/rancher-desktop/rancher-desktop\"\n")
+	assert.Contains(t, string(contents), "Restart=on-failure\n")
+	assert.Contains(t, string(contents), "PartOf=graphical-session.target\n")
+	assert.Contains(t, string(contents), "WantedBy=graphical-session.target\n")
+	assert.NotContains(t, string(contents), "default.target")
+	assert.NoFileExists(t, autostartFilePath, "the XDG autostart file should be replaced")
+	assert.Equal(t, [][]string{{"daemon-reload"}, {"reenable", systemdUnitName}}, calls)
+
+	// The default method keeps using systemd.
+	calls = nil
+	require.NoError(t, EnsureAutostart(true, Options{}))
+	assert.Equal(t, [][]string{{"enable", systemdUnitName}}, calls)
+	location, exists, err := GetAutostartLocation()
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, systemdUnitPath, location)
+
+	calls = nil
+	require.NoError(t, EnsureAutostart(false, Options{}))
+	assert.NoFileExists(t, systemdUnitPath)
+	assert.Equal(t, [][]string{{"disable", systemdUnitName}, {"daemon-reload"}}, calls)
+
+	assert.ErrorContains(t, EnsureAutostart(true, Options{Method: MethodRegistry}), `unsupported autostart method "registry"`)
+}
+
+func TestCustomizeDesktopEntry(t *testing.T) {
+	contents := []byte(`[Desktop Entry]
+Name=Rancher Desktop
+Exec=rancher-desktop %U
+X-GNOME-Autostart-Delay=5
+
+[Desktop Action New]
+Exec=rancher-desktop --new
+`)
+	t.Run("leaves the file alone without options", func(t *testing.T) {
+		assert.Equal(t, contents, customizeDesktopEntry(contents, Options{}))
+	})
+	t.Run("adds arguments and delay to the main group", func(t *testing.T) {
+		options := Options{Delay: 30 * time.Second, StartInBackground: true, DisableKubernetes: true}
+		assert.Equal(t, `[Desktop Entry]
+Name=Rancher Desktop
+Exec=rancher-desktop %U --autostart=background,no-kubernetes
+X-GNOME-Autostart-Delay=30
+
+[Desktop Action New]
+Exec=rancher-desktop --new
+`, string(customizeDesktopEntry(contents, options)))
+	})
+}
@@ -3,6 +3,7 @@ package autostart
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"golang.org/x/sys/windows/registry"
@@ -13,11 +14,65 @@ const nameValue = "RancherDesktop"
 
 var absoluteKey string
 
+var supportedMethods = []string{MethodRegistry, MethodTaskScheduler}
+
 func init() {
 	absoluteKey = fmt.Sprintf(`%s\%s`, "HKCU", relativeKey)
 }
 
-func EnsureAutostart(autostartDesired bool) error {
+// GetAutostartLocation returns the scheduled task used for autostart if it
+// exists, or else the registry value, and whether it exists.
+func GetAutostartLocation() (string, bool, error) {
+	if taskExists() {
+		return fmt.Sprintf(`Task Scheduler\%s`, taskName), true, nil
+	}
+	location := fmt.Sprintf(`%s\%s`, absoluteKey, nameValue)
+	autostartKey, err := registry.OpenKey(registry.CURRENT_USER, relativeKey, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return location, false, nil
+		}
+		return location, false, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer autostartKey.Close()
+	_, _, err = autostartKey.GetStringValue(nameValue)
+	if errors.Is(err, registry.ErrNotExist) {
+		return location, false, nil
+	}
+	return location, err == nil, err
+}
+
+// EnsureAutostart enables or disables starting Rancher Desktop at login.
+func EnsureAutostart(autostartDesired bool, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if !autostartDesired {
+		return errors.Join(ensureTaskAutostart(false, options), ensureRegistryAutostart(false, options))
+	}
+	method := options.Method
+	if method == MethodDefault {
+		method = MethodRegistry
+		if taskExists() {
+			method = MethodTaskScheduler
+		}
+	}
+	if method == MethodTaskScheduler {
+		if err := ensureTaskAutostart(true, options); err != nil {
+			return err
+		}
+		return ensureRegistryAutostart(false, options)
+	}
+	if options.Delay > 0 {
+		return fmt.Errorf("the %q autostart method doesn't support delaying the start; use %q instead", MethodRegistry, MethodTaskScheduler)
+	}
+	if err := ensureRegistryAutostart(true, options); err != nil {
+		return err
+	}
+	return ensureTaskAutostart(false, options)
+}
+
+func ensureRegistryAutostart(autostartDesired bool, options Options) error {
 	autostartKey, err := registry.OpenKey(registry.CURRENT_USER, relativeKey, registry.SET_VALUE)
 	if err != nil {
 		return fmt.Errorf("failed to open registry key: %w", err)
@@ -29,7 +84,8 @@ func EnsureAutostart(autostartDesired bool) error {
 		if err != nil {
 			return fmt.Errorf("failed to get path to Rancher Desktop.exe: %w", err)
 		}
-		err = autostartKey.SetStringValue(nameValue, rancherDesktopPath)
+		command := strings.Join(append([]string{fmt.Sprintf(`"%s"`, rancherDesktopPath)}, options.launchArguments()...), " ")
+		err = autostartKey.SetStringValue(nameValue, command)
 		if err != nil {
 			return fmt.Errorf("failed to set name value %q of registry key %q: %w", nameValue, absoluteKey, err)
 		}
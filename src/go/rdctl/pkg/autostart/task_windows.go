@@ -0,0 +1,174 @@
+package autostart
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
+	"golang.org/x/sys/windows"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// The scheduled task is an alternative to the Run registry key, which some
+// group policies block.  It also supports delaying the start, and running
+// with the highest privileges available to the user.
+const taskName = "Rancher Desktop"
+
+// taskDefinition is the subset of the Task Scheduler schema we use; see
+// https://learn.microsoft.com/en-us/windows/win32/taskschd/task-scheduler-schema
+type taskDefinition struct {
+	XMLName      xml.Name         `xml:"Task"`
+	Version      string           `xml:"version,attr"`
+	Namespace    string           `xml:"xmlns,attr"`
+	Description  string           `xml:"RegistrationInfo>Description"`
+	LogonTrigger taskLogonTrigger `xml:"Triggers>LogonTrigger"`
+	Principal    taskPrincipal    `xml:"Principals>Principal"`
+	Settings     taskSettings
+	Actions      taskActions
+}
+
+type taskLogonTrigger struct {
+	Enabled bool
+	UserID  string `xml:"UserId"`
+	Delay   string `xml:",omitempty"`
+}
+
+type taskActions struct {
+	Context          string `xml:",attr"`
+	Command          string `xml:"Exec>Command"`
+	Arguments        string `xml:"Exec>Arguments,omitempty"`
+	WorkingDirectory string `xml:"Exec>WorkingDirectory,omitempty"`
+}
+
+type taskPrincipal struct {
+	ID        string `xml:"id,attr"`
+	UserID    string `xml:"UserId"`
+	LogonType string
+	RunLevel  string
+}
+
+type taskSettings struct {
+	MultipleInstancesPolicy    string
+	DisallowStartIfOnBatteries bool
+	StopIfGoingOnBatteries     bool
+	ExecutionTimeLimit         string
+	Enabled                    bool
+}
+
+// getTaskDefinition returns the XML definition of the scheduled task, encoded
+// in UTF-16 as schtasks.exe expects.
+func getTaskDefinition(command, userID string, options Options) ([]byte, error) {
+	runLevel := "LeastPrivilege"
+	if options.HighestPrivileges {
+		runLevel = "HighestAvailable"
+	}
+	definition := taskDefinition{
+		Version:     "1.2",
+		Namespace:   "http://schemas.microsoft.com/windows/2004/02/mit/task",
+		Description: "Starts Rancher Desktop at login.",
+		LogonTrigger: taskLogonTrigger{
+			Enabled: true,
+			UserID:  userID,
+			Delay:   formatTaskDelay(options),
+		},
+		Principal: taskPrincipal{
+			ID:        "Author",
+			UserID:    userID,
+			LogonType: "InteractiveToken",
+			RunLevel:  runLevel,
+		},
+		Settings: taskSettings{
+			MultipleInstancesPolicy: "IgnoreNew",
+			ExecutionTimeLimit:      "PT0S",
+			Enabled:                 true,
+		},
+		Actions: taskActions{
+			Context:          "Author",
+			Command:          command,
+			Arguments:        strings.Join(options.launchArguments(), " "),
+			WorkingDirectory: filepath.Dir(command),
+		},
+	}
+	contents, err := xml.MarshalIndent(definition, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate scheduled task definition: %w", err)
+	}
+	contents = append([]byte(`<?xml version="1.0" encoding="UTF-16"?>`+"\n"), contents...)
+	encoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+	return encoder.Bytes(contents)
+}
+
+// formatTaskDelay formats the delay in the ISO 8601 format used by the Task
+// Scheduler (e.g. "PT30S"); no delay is omitted.
+func formatTaskDelay(options Options) string {
+	if seconds := options.delaySeconds(); seconds > 0 {
+		return fmt.Sprintf("PT%dS", seconds)
+	}
+	return ""
+}
+
+func schtasks(args ...string) ([]byte, error) {
+	cmd := execctx.Command(context.Background(), 0, "schtasks.exe", args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NO_WINDOW}
+	return cmd.CombinedOutput()
+}
+
+func taskExists() bool {
+	_, err := schtasks("/Query", "/TN", taskName)
+	return err == nil
+}
+
+func ensureTaskAutostart(autostartDesired bool, options Options) error {
+	if !autostartDesired {
+		if !taskExists() {
+			return nil
+		}
+		if output, err := schtasks("/Delete", "/TN", taskName, "/F"); err != nil {
+			return fmt.Errorf("failed to delete scheduled task %q: %w: %s", taskName, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	rancherDesktopPath, err := utils.GetRDPath()
+	if err != nil {
+		return fmt.Errorf("failed to get path to Rancher Desktop.exe: %w", err)
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+	definition, err := getTaskDefinition(rancherDesktopPath, currentUser.Username, options)
+	if err != nil {
+		return err
+	}
+	definitionFile, err := os.CreateTemp("", "rancher-desktop-task-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled task definition file: %w", err)
+	}
+	defer os.Remove(definitionFile.Name())
+	_, err = definitionFile.Write(definition)
+	if closeErr := definitionFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write scheduled task definition file: %w", err)
+	}
+	output, err := schtasks("/Create", "/TN", taskName, "/XML", definitionFile.Name(), "/F")
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if options.HighestPrivileges && bytes.Contains(bytes.ToLower(output), []byte("access is denied")) {
+			message += " (running with the highest privileges requires creating the task from an elevated prompt)"
+		}
+		return errors.Join(fmt.Errorf("failed to create scheduled task %q: %w", taskName, err), errors.New(message))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package autostart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestGetTaskDefinition(t *testing.T) {
+	encoded, err := getTaskDefinition(`C:\Program Files\Rancher Desktop\Rancher Desktop.exe`, `DOMAIN\user`,
+		Options{Delay: 30 * time.Second, HighestPrivileges: true, StartInBackground: true})
+	require.NoError(t, err)
+	decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(encoded)
+	require.NoError(t, err)
+	definition := string(decoded)
+	assert.Contains(t, definition, `<?xml version="1.0" encoding="UTF-16"?>`)
+	assert.Contains(t, definition, "<Delay>PT30S</Delay>")
+	assert.Contains(t, definition, "<RunLevel>HighestAvailable</RunLevel>")
+	assert.Contains(t, definition, `<UserId>DOMAIN\user</UserId>`)
+	assert.Contains(t, definition, `<Command>C:\Program Files\Rancher Desktop\Rancher Desktop.exe</Command>`)
+	assert.Contains(t, definition, "<Arguments>--autostart=background</Arguments>")
+}
+
+func TestFormatTaskDelay(t *testing.T) {
+	assert.Equal(t, "", formatTaskDelay(Options{}))
+	assert.Equal(t, "PT90S", formatTaskDelay(Options{Delay: 90 * time.Second}))
+	assert.Equal(t, "PT2S", formatTaskDelay(Options{Delay: 1500 * time.Millisecond}))
+}
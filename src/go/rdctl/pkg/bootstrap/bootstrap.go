@@ -0,0 +1,97 @@
+// Package bootstrap applies settings to a running Rancher Desktop and waits
+// for its backend to be ready, for `rdctl bootstrap`.
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/sirupsen/logrus"
+)
+
+// errBackendReady stops the event subscription once the backend is running.
+var errBackendReady = errors.New("the backend is ready")
+
+// MergeSettings merges the settings given as flags into those from a settings
+// file, replacing any values from the file.  The version of the file is kept,
+// so that its settings are migrated correctly.
+func MergeSettings(settings map[string]interface{}, flagSettings interface{}) error {
+	contents, err := json.Marshal(flagSettings)
+	if err != nil {
+		return err
+	}
+	var flags map[string]interface{}
+	if err := json.Unmarshal(contents, &flags); err != nil {
+		return err
+	}
+	version, hasVersion := settings["version"]
+	mergeSettingsMaps(settings, flags)
+	if hasVersion {
+		settings["version"] = version
+	}
+	return nil
+}
+
+func mergeSettingsMaps(dest, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		destMap, destIsMap := dest[key].(map[string]interface{})
+		if srcIsMap && destIsMap {
+			mergeSettingsMaps(destMap, srcMap)
+		} else {
+			dest[key] = value
+		}
+	}
+}
+
+// ApplySettings sends the settings to the backend, returning its status
+// message and whether the backend will restart to apply them.
+func ApplySettings(ctx context.Context, rdClient *client.RDClientImpl, settings map[string]interface{}) (string, bool, error) {
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return "", false, err
+	}
+	response, err := rdClient.DoRequestWithPayload(ctx, "PUT", client.VersionCommand("", "settings"), bytes.NewBuffer(body))
+	result, err := client.ProcessRequestForUtility(response, err)
+	if err != nil {
+		return "", false, err
+	}
+	restarting := response.Header.Get(client.RestartingHeader) == "true"
+	return strings.TrimSpace(string(result)), restarting, nil
+}
+
+// WaitForBackend waits for the backend to be running, which means the container
+// engine is ready and Kubernetes (if enabled) has started.  If restarting is
+// set, the backend has been asked to restart, so wait for it to stop first;
+// that may only happen once an ongoing start finishes.
+func WaitForBackend(ctx context.Context, rdClient *client.RDClientImpl, restarting bool) error {
+	err := rdClient.Subscribe(ctx, []string{client.EventBackendState}, func(event client.Event) error {
+		var state client.BackendState
+		if err := json.Unmarshal(event.Data, &state); err != nil {
+			return fmt.Errorf("failed to parse backend state: %w", err)
+		}
+		logrus.Debugf("Backend state: %s", state.VMState)
+		switch state.VMState {
+		case "STOPPING":
+			restarting = false
+		case "STARTED", "DISABLED":
+			if !restarting {
+				return errBackendReady
+			}
+		case "ERROR":
+			if !restarting {
+				return errors.New("the backend failed to start; please consult the application logs")
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBackendReady) {
+		return nil
+	}
+	return err
+}
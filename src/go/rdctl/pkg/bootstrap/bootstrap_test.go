@@ -0,0 +1,189 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient starts a server for the API (without the version prefix), and
+// returns a client for it.
+func newClient(t *testing.T, handler http.HandlerFunc) *client.RDClientImpl {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions" {
+			_ = json.NewEncoder(w).Encode(map[string][]string{"versions": {client.ApiVersion}})
+			return
+		}
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/"+client.ApiVersion)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return client.NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+}
+
+func TestMergeSettings(t *testing.T) {
+	type flagSettings struct {
+		Version    int                    `json:"version,omitempty"`
+		Kubernetes map[string]interface{} `json:"kubernetes,omitempty"`
+		Extra      string                 `json:"extra,omitempty"`
+	}
+
+	t.Run("flags replace values from the file", func(t *testing.T) {
+		settings := map[string]interface{}{
+			"kubernetes": map[string]interface{}{"enabled": true, "version": "1.27.3"},
+			"other":      "kept",
+		}
+		require.NoError(t, MergeSettings(settings, flagSettings{
+			Kubernetes: map[string]interface{}{"enabled": false},
+			Extra:      "added",
+		}))
+		assert.Equal(t, map[string]interface{}{
+			"kubernetes": map[string]interface{}{"enabled": false, "version": "1.27.3"},
+			"other":      "kept",
+			"extra":      "added",
+		}, settings)
+	})
+
+	t.Run("keeps the version of the file", func(t *testing.T) {
+		settings := map[string]interface{}{"version": float64(5)}
+		require.NoError(t, MergeSettings(settings, flagSettings{Version: 10, Extra: "added"}))
+		assert.Equal(t, map[string]interface{}{"version": float64(5), "extra": "added"}, settings)
+	})
+
+	t.Run("uses the version of the flags without one in the file", func(t *testing.T) {
+		settings := map[string]interface{}{}
+		require.NoError(t, MergeSettings(settings, flagSettings{Version: 10}))
+		assert.Equal(t, map[string]interface{}{"version": float64(10)}, settings)
+	})
+
+	t.Run("a value replaces a whole section", func(t *testing.T) {
+		settings := map[string]interface{}{"kubernetes": "invalid"}
+		require.NoError(t, MergeSettings(settings, flagSettings{
+			Kubernetes: map[string]interface{}{"enabled": false},
+		}))
+		assert.Equal(t, map[string]interface{}{"kubernetes": map[string]interface{}{"enabled": false}}, settings)
+	})
+}
+
+func TestApplySettings(t *testing.T) {
+	testCases := []struct {
+		name       string
+		header     string
+		restarting bool
+	}{
+		{"restarting", "true", true},
+		{"not restarting", "false", false},
+		{"older backend", "", false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var received string
+			rdClient := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, "/settings", r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				received = string(body)
+				if testCase.header != "" {
+					w.Header().Set(client.RestartingHeader, testCase.header)
+				}
+				w.WriteHeader(http.StatusAccepted)
+				_, _ = w.Write([]byte("some status\n"))
+			})
+			message, restarting, err := ApplySettings(context.Background(), rdClient, map[string]interface{}{"version": 10})
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"version":10}`, received)
+			assert.Equal(t, "some status", message)
+			assert.Equal(t, testCase.restarting, restarting)
+		})
+	}
+
+	t.Run("fails on invalid settings", func(t *testing.T) {
+		rdClient := newClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(client.RestartingHeader, "true")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("errors in attempt to update settings"))
+		})
+		_, _, err := ApplySettings(context.Background(), rdClient, map[string]interface{}{})
+		assert.ErrorContains(t, err, "errors in attempt to update settings")
+	})
+}
+
+// backendStates returns a handler that sends the given backend states as
+// events, then closes the stream.
+func backendStates(t *testing.T, states ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/events", r.URL.Path)
+		assert.Equal(t, client.EventBackendState, r.URL.Query().Get("types"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, state := range states {
+			fmt.Fprintf(w, "event: %s\ndata: {\"vmState\":%q}\n\n", client.EventBackendState, state)
+		}
+	}
+}
+
+func TestWaitForBackend(t *testing.T) {
+	testCases := []struct {
+		name       string
+		states     []string
+		restarting bool
+		err        string
+	}{
+		{name: "already started", states: []string{"STARTED"}},
+		{name: "Kubernetes disabled", states: []string{"DISABLED"}},
+		{name: "starting", states: []string{"STARTING", "STARTED"}},
+		{name: "failed", states: []string{"STARTING", "ERROR"}, err: "the backend failed to start"},
+		{name: "restarting", states: []string{"STARTED", "STOPPING", "STOPPED", "STARTING", "STARTED"}, restarting: true},
+		{name: "restarting after an error", states: []string{"ERROR", "STOPPING", "STARTED"}, restarting: true},
+		{name: "failed to restart", states: []string{"STARTED", "STOPPING", "ERROR"}, restarting: true, err: "the backend failed to start"},
+		{name: "never restarted", states: []string{"STARTED"}, restarting: true, err: client.ErrEventStreamClosed.Error()},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rdClient := newClient(t, backendStates(t, testCase.states...))
+			err := WaitForBackend(context.Background(), rdClient, testCase.restarting)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, testCase.err)
+			}
+		})
+	}
+
+	t.Run("fails on an invalid state", func(t *testing.T) {
+		rdClient := newClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, "event: %s\ndata: {\n\n", client.EventBackendState)
+		})
+		err := WaitForBackend(context.Background(), rdClient, false)
+		assert.ErrorContains(t, err, "failed to parse backend state")
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rdClient := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "event: %s\ndata: {\"vmState\":\"STARTING\"}\n\n", client.EventBackendState)
+			w.(http.Flusher).Flush()
+			cancel()
+			<-r.Context().Done()
+		})
+		err := WaitForBackend(ctx, rdClient, false)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
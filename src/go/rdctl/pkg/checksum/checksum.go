@@ -0,0 +1,145 @@
+// Package checksum hashes large files, and many files, as fast as the disk
+// allows.  A single SHA-256 can't be split across cores, so each file is read
+// ahead in a separate goroutine while the previous chunks are hashed, and
+// several files are hashed at once by a pool of workers.
+package checksum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+const (
+	// chunkSize is how much is read at a time.
+	chunkSize = 1024 * 1024
+	// readAhead is how many chunks may be read before they are hashed.
+	readAhead = 4
+	// maxWorkers caps the default number of files hashed at once; beyond it,
+	// the disk rather than the CPU is the bottleneck.
+	maxWorkers = 8
+)
+
+var chunkBuffers = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, chunkSize)
+		return &buffer
+	},
+}
+
+type chunk struct {
+	buffer *[]byte
+	n      int
+	err    error
+}
+
+// Copy writes everything read from src to dst, like io.Copy, but reads ahead
+// in another goroutine, so that reading overlaps with writing to dst (which is
+// usually a hash).  The goroutine has finished by the time Copy returns.
+func Copy(dst io.Writer, src io.Reader) (int64, error) {
+	queue := make(chan chunk, readAhead)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		for c := range queue {
+			chunkBuffers.Put(c.buffer)
+		}
+	}()
+	go func() {
+		defer close(queue)
+		for {
+			buffer := chunkBuffers.Get().(*[]byte)
+			n, err := io.ReadFull(src, *buffer)
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			select {
+			case queue <- chunk{buffer: buffer, n: n, err: err}:
+			case <-done:
+				chunkBuffers.Put(buffer)
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	var written int64
+	for c := range queue {
+		n, err := dst.Write((*c.buffer)[:c.n])
+		written += int64(n)
+		chunkBuffers.Put(c.buffer)
+		if err != nil {
+			return written, err
+		} else if errors.Is(c.err, io.EOF) {
+			return written, nil
+		} else if c.err != nil {
+			return written, c.err
+		}
+	}
+	return written, nil
+}
+
+// File returns the hex-encoded SHA-256 digest of the file.
+func File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Result is the digest of one file, or why it couldn't be computed.
+type Result struct {
+	Path   string
+	Digest string
+	Err    error
+}
+
+// Files computes the digests of the files with a pool of workers, returning
+// the results in the same order as the paths.  Digest computes the digest of a
+// single file; if nil, it is File.  Workers is the number of files hashed at
+// once; zero means one per CPU, up to a limit.  Files that haven't been
+// started when the context is done fail with its error.
+func Files(ctx context.Context, paths []string, workers int, digest func(path string) (string, error)) []Result {
+	if digest == nil {
+		digest = File
+	}
+	if workers <= 0 {
+		workers = min(runtime.GOMAXPROCS(0), maxWorkers)
+	}
+	results := make([]Result, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(workers, len(paths)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i].Path = paths[i]
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Digest, results[i].Err = digest(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
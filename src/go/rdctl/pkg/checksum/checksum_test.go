@@ -0,0 +1,78 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopy(t *testing.T) {
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, readAhead*chunkSize*2 + 17} {
+		contents := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(contents)
+		var output bytes.Buffer
+		written, err := Copy(&output, bytes.NewReader(contents))
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, int64(size), written, "size %d", size)
+		assert.True(t, bytes.Equal(contents, output.Bytes()), "size %d", size)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestCopyErrors(t *testing.T) {
+	readErr := errors.New("read failed")
+	reader := io.MultiReader(strings.NewReader("some data"), iotest.ErrReader(readErr))
+	_, err := Copy(io.Discard, reader)
+	assert.ErrorIs(t, err, readErr)
+
+	_, err = Copy(failingWriter{}, bytes.NewReader(make([]byte, readAhead*chunkSize*2)))
+	assert.EqualError(t, err, "disk full")
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	expected := map[string]string{}
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(dir, strings.Repeat("x", i+1))
+		contents := bytes.Repeat([]byte{byte(i)}, i*100000)
+		require.NoError(t, os.WriteFile(path, contents, 0o644))
+		sum := sha256.Sum256(contents)
+		expected[path] = hex.EncodeToString(sum[:])
+		paths = append(paths, path)
+	}
+	paths = append(paths, filepath.Join(dir, "missing"))
+
+	results := Files(context.Background(), paths, 3, nil)
+	require.Len(t, results, len(paths))
+	for i, result := range results[:len(results)-1] {
+		assert.Equal(t, paths[i], result.Path)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, expected[result.Path], result.Digest)
+	}
+	assert.ErrorIs(t, results[len(results)-1].Err, fs.ErrNotExist)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range Files(ctx, paths, 0, nil) {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
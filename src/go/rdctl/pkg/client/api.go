@@ -0,0 +1,43 @@
+package client
+
+//go:generate go run ./generate ../../../../../pkg/rancher-desktop/assets/specs/command-api.yaml api_generated.go
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// operation is an endpoint of the API, as described by the spec; the op*
+// variables in api_generated.go hold all of them.
+type operation struct {
+	method string
+	// path is relative to the API version, e.g. "settings" for /v1/settings.
+	path string
+}
+
+// command returns the versioned command for the operation, with the given
+// query parameters (if any).
+func (client *RDClientImpl) command(ctx context.Context, op operation, query url.Values) (string, error) {
+	command, err := client.versionCommand(ctx, op.path)
+	if err != nil {
+		return "", err
+	}
+	if len(query) > 0 {
+		command += "?" + query.Encode()
+	}
+	return command, nil
+}
+
+// call sends a request for the operation, with a JSON payload unless it is nil.
+func (client *RDClientImpl) call(ctx context.Context, op operation, query url.Values, payload []byte) (*http.Response, error) {
+	command, err := client.command(ctx, op, query)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return client.DoRequest(ctx, op.method, command)
+	}
+	return client.DoRequestWithPayload(ctx, op.method, command, bytes.NewReader(payload))
+}
@@ -0,0 +1,468 @@
+// Code generated by go generate; DO NOT EDIT.
+// To rebuild this file, run `go generate ./pkg/client` in src/go/rdctl.
+
+package client
+
+import "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
+
+// Settings is the preferences schema of the API.
+type Settings struct {
+	// Version is the version of the settings format.
+	Version         *int                     `json:"version,omitempty"`
+	Application     *SettingsApplication     `json:"application,omitempty"`
+	ContainerEngine *SettingsContainerEngine `json:"containerEngine,omitempty"`
+	VirtualMachine  *SettingsVirtualMachine  `json:"virtualMachine,omitempty"`
+	Kubernetes      *SettingsKubernetes      `json:"kubernetes,omitempty"`
+	Experimental    *SettingsExperimental    `json:"experimental,omitempty"`
+	// Make container engine and Kubernetes available in these WSL2 distros.
+	// Only used on win32.
+	WSL            *SettingsWSL            `json:"WSL,omitempty"`
+	PortForwarding *SettingsPortForwarding `json:"portForwarding,omitempty"`
+	Images         *SettingsImages         `json:"images,omitempty"`
+	Diagnostics    *SettingsDiagnostics    `json:"diagnostics,omitempty"`
+}
+
+// SettingsApplication holds the application settings of Settings.
+type SettingsApplication struct {
+	// Enable privileged operations.
+	// Only used on darwin, linux.
+	AdminAccess *bool `json:"adminAccess,omitempty"`
+	// Generate more verbose logging.
+	Debug      *bool                          `json:"debug,omitempty"`
+	Extensions *SettingsApplicationExtensions `json:"extensions,omitempty"`
+	// Update PATH to include ~/.rd/bin.
+	// One of: manual, rcfiles.
+	// Only used on darwin, linux.
+	PathManagementStrategy *string                       `json:"pathManagementStrategy,omitempty"`
+	Telemetry              *SettingsApplicationTelemetry `json:"telemetry,omitempty"`
+	Updater                *SettingsApplicationUpdater   `json:"updater,omitempty"`
+	// Start app when logging in.
+	AutoStart *bool `json:"autoStart,omitempty"`
+	// Start app without window.
+	StartInBackground *bool `json:"startInBackground,omitempty"`
+	// Don't show notification icon.
+	HideNotificationIcon *bool                      `json:"hideNotificationIcon,omitempty"`
+	Window               *SettingsApplicationWindow `json:"window,omitempty"`
+}
+
+// SettingsApplicationExtensions holds the extensions settings of SettingsApplication.
+type SettingsApplicationExtensions struct {
+	Allowed *SettingsApplicationExtensionsAllowed `json:"allowed,omitempty"`
+	// Installed extensions and their tag.
+	Installed map[string]string `json:"installed,omitempty"`
+}
+
+// SettingsApplicationExtensionsAllowed holds the allowed settings of SettingsApplicationExtensions.
+type SettingsApplicationExtensionsAllowed struct {
+	Enabled *bool    `json:"enabled,omitempty"`
+	List    []string `json:"list,omitempty"`
+}
+
+// SettingsApplicationTelemetry holds the telemetry settings of SettingsApplication.
+type SettingsApplicationTelemetry struct {
+	// Allow collection of anonymous statistics.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// SettingsApplicationUpdater holds the updater settings of SettingsApplication.
+type SettingsApplicationUpdater struct {
+	// Automatically update to the latest release.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// SettingsApplicationWindow holds the window settings of SettingsApplication.
+type SettingsApplicationWindow struct {
+	// Terminate app when the main window is closed.
+	QuitOnClose *bool `json:"quitOnClose,omitempty"`
+}
+
+// SettingsContainerEngine holds the containerEngine settings of Settings.
+type SettingsContainerEngine struct {
+	// Set engine.
+	// One of: containerd, docker, moby.
+	Name          *string                               `json:"name,omitempty"`
+	AllowedImages *SettingsContainerEngineAllowedImages `json:"allowedImages,omitempty"`
+	Credentials   *SettingsContainerEngineCredentials   `json:"credentials,omitempty"`
+	SocketPolicy  *SettingsContainerEngineSocketPolicy  `json:"socketPolicy,omitempty"`
+}
+
+// SettingsContainerEngineAllowedImages holds the allowedImages settings of SettingsContainerEngine.
+type SettingsContainerEngineAllowedImages struct {
+	// Only allow images to be pulled that match the allowed patterns.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Allowed image names.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// SettingsContainerEngineCredentials holds the credentials settings of SettingsContainerEngine.
+type SettingsContainerEngineCredentials struct {
+	// Encrypt credentials stored by docker-credential-none with a key held by the OS.
+	Encrypt *bool `json:"encrypt,omitempty"`
+}
+
+// SettingsContainerEngineSocketPolicy holds the socketPolicy settings of SettingsContainerEngine.
+type SettingsContainerEngineSocketPolicy struct {
+	// Restrict what clients of the docker socket on the host may do.
+	// Only used on win32.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Allow privileged containers, host namespaces and extra capabilities when the socket policy is enabled.
+	// Only used on win32.
+	AllowPrivileged *bool `json:"allowPrivileged,omitempty"`
+	// Host directories that may be bind mounted when the socket policy is enabled.
+	// Only used on win32.
+	AllowedMountRoots []string `json:"allowedMountRoots,omitempty"`
+}
+
+// SettingsVirtualMachine holds the virtualMachine settings of Settings.
+type SettingsVirtualMachine struct {
+	// Reserved RAM size.
+	// Only used on darwin, linux.
+	MemoryInGB *int `json:"memoryInGB,omitempty"`
+	// Reserved number of CPUs.
+	// Only used on darwin, linux.
+	NumberCPUs *int `json:"numberCPUs,omitempty"`
+	// Resolve DNS queries on the host and not inside the VM.
+	// Only used on win32.
+	HostResolver *bool `json:"hostResolver,omitempty"`
+	// Make the GPU available to containers (as CDI device rancherdesktop.io/gpu=all).
+	// Only used on win32.
+	Gpu *bool `json:"gpu,omitempty"`
+	// Only used on win32.
+	Dns *SettingsVirtualMachineDns `json:"dns,omitempty"`
+}
+
+// SettingsVirtualMachineDns holds the dns settings of SettingsVirtualMachine.
+type SettingsVirtualMachineDns struct {
+	// DNS servers to forward queries to, instead of those of the host.
+	UpstreamServers []string `json:"upstreamServers,omitempty"`
+	// Per-domain DNS servers, as domain=server.
+	Domains []string `json:"domains,omitempty"`
+	// Skip the DNS servers of VPN adapters on the host.
+	IgnoreVPN *bool `json:"ignoreVPN,omitempty"`
+}
+
+// SettingsKubernetes holds the kubernetes settings of Settings.
+type SettingsKubernetes struct {
+	// Choose which version of Kubernetes to run.
+	Version *string `json:"version,omitempty"`
+	// Apiserver port.
+	Port *int `json:"port,omitempty"`
+	// Run Kubernetes.
+	Enabled *bool                      `json:"enabled,omitempty"`
+	Options *SettingsKubernetesOptions `json:"options,omitempty"`
+	Ingress *SettingsKubernetesIngress `json:"ingress,omitempty"`
+}
+
+// SettingsKubernetesOptions holds the options settings of SettingsKubernetes.
+type SettingsKubernetesOptions struct {
+	// Install and run traefik.
+	Traefik *bool `json:"traefik,omitempty"`
+	// Use flannel networking; disable to install your own CNI.
+	Flannel *bool `json:"flannel,omitempty"`
+}
+
+// SettingsKubernetesIngress holds the ingress settings of SettingsKubernetes.
+type SettingsKubernetesIngress struct {
+	// Bind services to 127.0.0.1 instead of 0.0.0.0.
+	// Only used on win32.
+	LocalhostOnly *bool `json:"localhostOnly,omitempty"`
+}
+
+// SettingsExperimental holds the experimental settings of Settings.
+type SettingsExperimental struct {
+	VirtualMachine *SettingsExperimentalVirtualMachine `json:"virtualMachine,omitempty"`
+}
+
+// SettingsExperimentalVirtualMachine holds the virtualMachine settings of SettingsExperimental.
+type SettingsExperimentalVirtualMachine struct {
+	// Use socket-vmnet instead of vde-vmnet.
+	// Only used on darwin.
+	SocketVMNet *bool `json:"socketVMNet,omitempty"`
+	// Only used on darwin, linux.
+	Mount *SettingsExperimentalVirtualMachineMount `json:"mount,omitempty"`
+	// Tunnel networking so it originates from the host.
+	// Only used on win32.
+	NetworkingTunnel *bool `json:"networkingTunnel,omitempty"`
+	// One of: qemu, vz.
+	// Only used on darwin.
+	Type *string `json:"type,omitempty"`
+	// Only used on darwin.
+	UseRosetta *bool `json:"useRosetta,omitempty"`
+	// Configure proxy address.
+	// Only used on win32.
+	Proxy *SettingsExperimentalVirtualMachineProxy `json:"proxy,omitempty"`
+}
+
+// SettingsExperimentalVirtualMachineMount holds the mount settings of SettingsExperimentalVirtualMachine.
+type SettingsExperimentalVirtualMachineMount struct {
+	// How directories are shared.
+	// One of: reverse-sshfs, 9p, virtiofs.
+	Type  *string                                       `json:"type,omitempty"`
+	NineP *SettingsExperimentalVirtualMachineMountNineP `json:"9p,omitempty"`
+}
+
+// SettingsExperimentalVirtualMachineMountNineP holds the 9p settings of SettingsExperimentalVirtualMachineMount.
+type SettingsExperimentalVirtualMachineMountNineP struct {
+	// One of: passthrough, mapped-xattr, mapped-file, none.
+	SecurityModel *string `json:"securityModel,omitempty"`
+	// One of: 9p2000, 9p2000.u, 9p2000.L.
+	ProtocolVersion *string `json:"protocolVersion,omitempty"`
+	// Maximum packet size.
+	MsizeInKib *int `json:"msizeInKib,omitempty"`
+	// One of: none, loose, fscache, mmap.
+	CacheMode *string `json:"cacheMode,omitempty"`
+}
+
+// SettingsExperimentalVirtualMachineProxy holds the proxy settings of SettingsExperimentalVirtualMachine.
+type SettingsExperimentalVirtualMachineProxy struct {
+	// Redirect the traffic to the configured proxy address.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Proxy address.
+	Address *string `json:"address,omitempty"`
+	// If needed the password to connect to the proxy.
+	Password *string `json:"password,omitempty"`
+	// Proxy port.
+	Port *int `json:"port,omitempty"`
+	// If needed the username to connect to the proxy.
+	Username *string `json:"username,omitempty"`
+	// List of hostname to exclude from using the proxy.
+	Noproxy []string `json:"noproxy,omitempty"`
+}
+
+// SettingsWSL holds the WSL settings of Settings.
+type SettingsWSL struct {
+	Integrations    map[string]any              `json:"integrations,omitempty"`
+	AutoIntegration *SettingsWSLAutoIntegration `json:"autoIntegration,omitempty"`
+}
+
+// SettingsWSLAutoIntegration holds the autoIntegration settings of SettingsWSL.
+type SettingsWSLAutoIntegration struct {
+	// Integrate with new distros whose names match these patterns.
+	Include []string `json:"include,omitempty"`
+	// Never integrate automatically with distros whose names match these patterns.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// SettingsPortForwarding holds the portForwarding settings of Settings.
+type SettingsPortForwarding struct {
+	// Show Kubernetes system services on Port Forwarding page.
+	IncludeKubernetesServices *bool `json:"includeKubernetesServices,omitempty"`
+}
+
+// SettingsImages holds the images settings of Settings.
+type SettingsImages struct {
+	// Show system images on Images page.
+	ShowAll *bool `json:"showAll,omitempty"`
+	// Select only images from this namespace (containerd only).
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+// SettingsDiagnostics holds the diagnostics settings of Settings.
+type SettingsDiagnostics struct {
+	// Unhide muted diagnostics.
+	ShowMuted *bool `json:"showMuted,omitempty"`
+	// Diagnostic ids that have been muted.
+	MutedChecks map[string]any `json:"mutedChecks,omitempty"`
+}
+
+// TransientSettings is the transientSettings schema of the API.
+type TransientSettings struct {
+	NoModalDialogs *bool                         `json:"noModalDialogs,omitempty"`
+	Preferences    *TransientSettingsPreferences `json:"preferences,omitempty"`
+}
+
+// TransientSettingsPreferences holds the preferences settings of TransientSettings.
+type TransientSettingsPreferences struct {
+	NavItem *TransientSettingsPreferencesNavItem `json:"navItem,omitempty"`
+}
+
+// TransientSettingsPreferencesNavItem holds the navItem settings of TransientSettingsPreferences.
+type TransientSettingsPreferencesNavItem struct {
+	Current     *string        `json:"current,omitempty"`
+	CurrentTabs map[string]any `json:"currentTabs,omitempty"`
+}
+
+// SettingsSchema describes the settings (the preferences schema of the API),
+// for looking them up by name.
+var SettingsSchema = &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+	{Name: "version", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+	{Name: "application", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "adminAccess", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "debug", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "extensions", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "allowed", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+				{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+				{Name: "list", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+			}}},
+			{Name: "installed", Schema: &jsonpath.Schema{Kind: jsonpath.Map}},
+		}}},
+		{Name: "pathManagementStrategy", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+		{Name: "telemetry", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+		{Name: "updater", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+		{Name: "autoStart", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "startInBackground", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "hideNotificationIcon", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "window", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "quitOnClose", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+	}}},
+	{Name: "containerEngine", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "name", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+		{Name: "allowedImages", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "patterns", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+		}}},
+		{Name: "credentials", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "encrypt", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+		{Name: "socketPolicy", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "allowPrivileged", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "allowedMountRoots", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+		}}},
+	}}},
+	{Name: "virtualMachine", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "memoryInGB", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+		{Name: "numberCPUs", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+		{Name: "hostResolver", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "gpu", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "dns", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "upstreamServers", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+			{Name: "domains", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+			{Name: "ignoreVPN", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+	}}},
+	{Name: "kubernetes", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "version", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+		{Name: "port", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+		{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "options", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "traefik", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "flannel", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+		{Name: "ingress", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "localhostOnly", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		}}},
+	}}},
+	{Name: "experimental", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "virtualMachine", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "socketVMNet", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "mount", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+				{Name: "type", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+				{Name: "9p", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+					{Name: "securityModel", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+					{Name: "protocolVersion", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+					{Name: "msizeInKib", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+					{Name: "cacheMode", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+				}}},
+			}}},
+			{Name: "networkingTunnel", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "type", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+			{Name: "useRosetta", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+			{Name: "proxy", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+				{Name: "enabled", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+				{Name: "address", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+				{Name: "password", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+				{Name: "port", Schema: &jsonpath.Schema{Kind: jsonpath.Int}},
+				{Name: "username", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+				{Name: "noproxy", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+			}}},
+		}}},
+	}}},
+	{Name: "WSL", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "integrations", Schema: &jsonpath.Schema{Kind: jsonpath.Map}},
+		{Name: "autoIntegration", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+			{Name: "include", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+			{Name: "exclude", Schema: &jsonpath.Schema{Kind: jsonpath.List}},
+		}}},
+	}}},
+	{Name: "portForwarding", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "includeKubernetesServices", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+	}}},
+	{Name: "images", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "showAll", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "namespace", Schema: &jsonpath.Schema{Kind: jsonpath.String}},
+	}}},
+	{Name: "diagnostics", Schema: &jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{
+		{Name: "showMuted", Schema: &jsonpath.Schema{Kind: jsonpath.Bool}},
+		{Name: "mutedChecks", Schema: &jsonpath.Schema{Kind: jsonpath.Map}},
+	}}},
+}}
+
+// The operations of the API, named after their operationId.
+var (
+	// GET /v1/about: Returns a description of the endpoints
+	opGetAbout = operation{"GET", "about"}
+	// GET /v1/diagnostic_categories: Return a list of the category names for the Diagnostics component. Takes no parameters.
+	opDiagnosticCategories = operation{"GET", "diagnostic_categories"}
+	// GET /v1/diagnostic_checks: Return all the checks, optionally filtered by specified category and/or checkID.
+	opDiagnosticChecks = operation{"GET", "diagnostic_checks"}
+	// POST /v1/diagnostic_checks: Run all diagnostic checks, and return any results.
+	opDiagnosticRunChecks = operation{"POST", "diagnostic_checks"}
+	// POST /v1/diagnostic_repair: Attempt to fix the problem found by a diagnostic check
+	opDiagnosticRepair = operation{"POST", "diagnostic_repair"}
+	// GET /v1/diagnostic_ids: Return a list of the check IDs for the Diagnostics category, or 404 if there is no such `category`. Specifying an exiting category with no checks will return status code 200 and an empty array.
+	opDiagnosticIDsForCategory = operation{"GET", "diagnostic_ids"}
+	// GET /v1/engine_proxy: Connect to the socket of the container engine
+	opEngineProxy = operation{"GET", "engine_proxy"}
+	// GET /v1/events: Stream events as server-sent events
+	opSubscribeEvents = operation{"GET", "events"}
+	// GET /v1/extensions: List currently-installed RDX extensions.
+	opListExtensions = operation{"GET", "extensions"}
+	// POST /v1/extensions/install: Install an RDX extension
+	opInstallExtension = operation{"POST", "extensions/install"}
+	// POST /v1/extensions/uninstall: Uninstall an RDX extension
+	opUninstallExtension = operation{"POST", "extensions/uninstall"}
+	// PUT /v1/factory_reset: Factory reset Rancher Desktop, losing user data
+	opFactoryReset = operation{"PUT", "factory_reset"}
+	// GET /v1/job: Get the state of a job
+	opGetJob = operation{"GET", "job"}
+	// POST /v1/job/cancel: Cancel a running job
+	opCancelJob = operation{"POST", "job/cancel"}
+	// GET /v1/jobs: List the running jobs, and the jobs that finished in the last hour
+	opListJobs = operation{"GET", "jobs"}
+	// POST /v1/jobs: Start a long-running operation
+	opCreateJob = operation{"POST", "jobs"}
+	// GET /v1/openapi: Get the OpenAPI definition of the API
+	opGetOpenAPISpec = operation{"GET", "openapi"}
+	// PUT /v1/propose_settings: Propose some settings and determine if the backend needs to be restarted or reset (losing user data).
+	opProposeSettings = operation{"PUT", "propose_settings"}
+	// GET /v1/settings: List the current preference settings
+	opListSettings = operation{"GET", "settings"}
+	// PUT /v1/settings: Updates the specified preference settings
+	opUpdateSettings = operation{"PUT", "settings"}
+	// GET /v1/settings/locked: List the current locked settings
+	opListLockedSettings = operation{"GET", "settings/locked"}
+	// PUT /v1/shutdown: Shuts down Rancher Desktop
+	opShutdownApp = operation{"PUT", "shutdown"}
+	// GET /v1/snapshots: List the snapshots
+	opListSnapshots = operation{"GET", "snapshots"}
+	// POST /v1/snapshots: Creates a new snapshot
+	opCreateSnapshot = operation{"POST", "snapshots"}
+	// DELETE /v1/snapshots: Deletes a snapshot
+	opDeleteSnapshot = operation{"DELETE", "snapshots"}
+	// POST /v1/snapshot/restore: Restore a snapshot
+	opRestoreSnapshot = operation{"POST", "snapshot/restore"}
+	// POST /v1/tokens: Create a short-lived bearer token for accessing the API
+	opCreateToken = operation{"POST", "tokens"}
+	// GET /v1/transient_settings: List the current transient settings
+	opListTransientSettings = operation{"GET", "transient_settings"}
+	// PUT /v1/transient_settings: Updates application transient settings
+	opUpdateTransientSettings = operation{"PUT", "transient_settings"}
+	// POST /v1/vm/start: Start the VM
+	opStartVM = operation{"POST", "vm/start"}
+	// POST /v1/vm/stop: Stop the VM
+	opStopVM = operation{"POST", "vm/stop"}
+	// POST /v1/vm/restart: Restart the VM
+	opRestartVM = operation{"POST", "vm/restart"}
+	// POST /v1/vm/pause: Pause the VM
+	opPauseVM = operation{"POST", "vm/pause"}
+	// GET /v1/backend_state: Get the current backend state
+	opGetBackendState = operation{"GET", "backend_state"}
+	// PUT /v1/backend_state: Set the desired backend state
+	opSetBackendState = operation{"PUT", "backend_state"}
+)
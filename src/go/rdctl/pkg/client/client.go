@@ -1,14 +1,30 @@
+// Package client talks to the Rancher Desktop API.  Other tools can import it
+// to drive Rancher Desktop programmatically: NewRDClient with the connection
+// info from the config package gives typed access to the settings and the
+// backend state, while DoRequest covers the rest of the API.
 package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/tracing"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/retry"
 	"io"
 	"net/http"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -29,11 +45,29 @@ type APIError struct {
 	DocumentationURL *string `json:"documentation_url,omitempty"`
 }
 
+// CancelOnDisconnectHeader asks the server to abort the operation started by
+// the request if the client disconnects before the response is sent, e.g.
+// because the context of the request was cancelled.
+const CancelOnDisconnectHeader = "X-RD-Cancel-On-Disconnect"
+
+// RestartingHeader is set to "true" in the response to a settings update if
+// the backend will restart to apply the new settings.
+const RestartingHeader = "X-RD-Restarting"
+
 type RDClient interface {
-	DoRequest(method string, command string) (*http.Response, error)
-	DoRequestWithPayload(method string, command string, payload io.Reader) (*http.Response, error)
-	GetBackendState() (BackendState, error)
-	UpdateBackendState(state BackendState) error
+	DoRequest(ctx context.Context, method string, command string) (*http.Response, error)
+	DoRequestWithPayload(ctx context.Context, method string, command string, payload io.Reader) (*http.Response, error)
+	GetBackendState(ctx context.Context) (BackendState, error)
+	UpdateBackendState(ctx context.Context, state BackendState) error
+	GetSettings(ctx context.Context) (*Settings, error)
+	UpdateSettings(ctx context.Context, settings *Settings) (string, error)
+	StartVM(ctx context.Context) (string, error)
+	StopVM(ctx context.Context) (string, error)
+	RestartVM(ctx context.Context) (string, error)
+	PauseVM(ctx context.Context) (string, error)
+	WaitForVMState(ctx context.Context, states ...string) error
+	StartJob(ctx context.Context, request JobRequest) (*Job, error)
+	WaitForJob(ctx context.Context, id string, progress func(*Job)) (*Job, error)
 }
 
 func validateBackendState(state BackendState) error {
@@ -48,6 +82,16 @@ func validateBackendState(state BackendState) error {
 
 type RDClientImpl struct {
 	connectionInfo *config.ConnectionInfo
+	// session is the token the client uses, created from the user and
+	// password; it is replaced shortly before it expires.
+	session Token
+	// tokensUnsupported is set when the server is too old to issue tokens,
+	// in which case requests use the user and password directly.
+	tokensUnsupported bool
+	// apiVersion is the API version negotiated with the backend.
+	apiVersion string
+	// http is the (shared) client used to send requests, set on first use.
+	http *http.Client
 }
 
 func NewRDClient(connectionInfo *config.ConnectionInfo) *RDClientImpl {
@@ -57,46 +101,232 @@ func NewRDClient(connectionInfo *config.ConnectionInfo) *RDClientImpl {
 }
 
 func (client *RDClientImpl) makeURL(host string, port int, command string) string {
+	if server := client.connectionInfo.Server; server != "" {
+		// The server URL may have a path (e.g. behind a reverse proxy).
+		return server + "/" + strings.TrimPrefix(command, "/")
+	}
+	scheme := "http"
+	if client.usesTLS() {
+		scheme = "https"
+	}
+	if port != 0 {
+		host = fmt.Sprintf("%s:%d", host, port)
+	}
 	if strings.HasPrefix(command, "/") {
-		return fmt.Sprintf("http://%s:%d%s", host, port, command)
+		return fmt.Sprintf("%s://%s%s", scheme, host, command)
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, host, command)
+}
+
+// usesTLS checks whether requests are sent over TLS.  Sockets are protected by
+// their file permissions, so the backend serves plain HTTP on them.
+func (client *RDClientImpl) usesTLS() bool {
+	if server := client.connectionInfo.Server; server != "" {
+		return strings.HasPrefix(server, "https:")
+	}
+	return client.connectionInfo.Certificate != "" && client.connectionInfo.Socket == ""
+}
+
+// httpClient returns the client to send requests with; when a socket is
+// configured, every request goes through it regardless of the URL.  Without a
+// pinned certificate, TLS connections (to a remote server) use the system
+// trust store.  Clients for the same backend share their connections.
+func (client *RDClientImpl) httpClient() (*http.Client, error) {
+	if client.http != nil {
+		return client.http, nil
+	}
+	key := transportKey{socket: client.connectionInfo.Socket}
+	if client.usesTLS() && client.connectionInfo.Certificate != "" {
+		pinned, err := readPinnedCertificate(client.connectionInfo.Certificate)
+		if err != nil {
+			return nil, err
+		}
+		key.certificate = string(pinned)
+	}
+	client.http = sharedHTTPClient(key)
+	return client.http, nil
+}
+
+// readPinnedCertificate returns the DER bytes of the certificate the server
+// must present.
+func readPinnedCertificate(certificatePath string) ([]byte, error) {
+	contents, err := os.ReadFile(certificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server certificate: %w", err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("failed to read server certificate: no certificate found in %q", certificatePath)
+	}
+	return block.Bytes, nil
+}
+
+// pinnedTLSConfig returns a TLS configuration that only accepts a server
+// presenting the given certificate.  The certificate is self-signed, and the
+// host name may not match it (e.g. from inside WSL, or through a port
+// forward), so the usual verification doesn't apply.
+func pinnedTLSConfig(pinned []byte) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Verification is done by VerifyPeerCertificate below.
+		InsecureSkipVerify: true, //nolint:gosec // the certificate is pinned
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], pinned) {
+				return errors.New("the server did not present the expected certificate")
+			}
+			return nil
+		},
+	}
+}
+
+func (client *RDClientImpl) DoRequest(ctx context.Context, method string, command string) (*http.Response, error) {
+	return client.do(ctx, method, command, "text/plain", nil)
+}
+
+func (client *RDClientImpl) DoRequestWithPayload(ctx context.Context, method string, command string, payload io.Reader) (*http.Response, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = io.ReadAll(payload); err != nil {
+			return nil, fmt.Errorf("failed to read request payload: %w", err)
+		}
+	}
+	return client.do(ctx, method, command, "application/json", body)
+}
+
+// do sends the request, waiting for the backend to be ready first if it is
+// starting and the connection info asks for that.
+func (client *RDClientImpl) do(ctx context.Context, method, command, contentType string, body []byte) (response *http.Response, err error) {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("%s %s", method, requestPath(command)),
+		attribute.String("http.request.method", method),
+		attribute.String("url.path", requestPath(command)))
+	defer func() {
+		if response != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+		}
+		tracing.End(span, err)
+	}()
+	policy := waitPolicy()
+	policy.Notify = func(_ error, delay time.Duration) {
+		logrus.Debugf("The backend is starting; retrying %s %s in %s", method, command, delay)
+		span.AddEvent("backend starting", trace.WithAttributes(attribute.String("retry.delay", delay.String())))
+	}
+	return retry.DoValue(ctx, policy, func(ctx context.Context) (*http.Response, error) {
+		response, err := client.doRecovering(ctx, method, command, contentType, body)
+		retryAfter, starting := client.backendStarting(response, err)
+		if !starting {
+			return response, retry.Permanent(err)
+		}
+		if response != nil {
+			response.Body.Close()
+		}
+		if !client.connectionInfo.WaitForBackend {
+			if err != nil {
+				return nil, retry.Permanent(fmt.Errorf("%w: %w", ErrBackendStarting, handleConnectionRefused(err)))
+			}
+			return nil, retry.Permanent(fmt.Errorf("%w: %s", ErrBackendStarting, response.Status))
+		}
+		return nil, retry.After(ErrBackendStarting, retryAfter)
+	})
+}
+
+// doRecovering sends the request; if the connection is refused, the connection
+// info is reloaded in case the backend has restarted with different settings,
+// and the request is retried once with the new settings.
+func (client *RDClientImpl) doRecovering(ctx context.Context, method, command, contentType string, body []byte) (*http.Response, error) {
+	response, err := client.doOnce(ctx, method, command, contentType, body)
+	if err == nil && response.StatusCode == http.StatusUnauthorized && client.session.Token != "" {
+		// The backend may have restarted and forgotten our token; get a new one.
+		response.Body.Close()
+		client.forgetSession()
+		return client.doOnce(ctx, method, command, contentType, body)
 	}
-	return fmt.Sprintf("http://%s:%d/%s", host, port, command)
+	if err == nil || !errors.Is(handleConnectionRefused(err), ErrConnectionRefused) {
+		return response, err
+	}
+	connectionInfo, changed, reloadErr := config.ReloadConnectionInfo()
+	if reloadErr != nil || !changed {
+		return response, err
+	}
+	client.connectionInfo = connectionInfo
+	client.session = Token{}
+	client.http = nil
+	// The backend may have been upgraded.
+	client.apiVersion = ""
+	return client.doOnce(ctx, method, command, contentType, body)
 }
 
-func (client *RDClientImpl) DoRequest(method string, command string) (*http.Response, error) {
-	req, err := client.getRequestObject(method, command)
+func (client *RDClientImpl) doOnce(ctx context.Context, method, command, contentType string, body []byte) (*http.Response, error) {
+	if err := client.negotiateVersion(ctx); err != nil {
+		return nil, err
+	}
+	authorization, err := client.authorization(ctx, command)
 	if err != nil {
 		return nil, err
 	}
-	return http.DefaultClient.Do(req)
+	return client.send(ctx, method, command, contentType, body, authorization)
 }
 
-func (client *RDClientImpl) DoRequestWithPayload(method string, command string, payload io.Reader) (*http.Response, error) {
-	url := client.makeURL(client.connectionInfo.Host, client.connectionInfo.Port, command)
-	req, err := http.NewRequest(method, url, payload)
+// send sends a single request, using the given function to add credentials.
+func (client *RDClientImpl) send(ctx context.Context, method, command, contentType string, body []byte, authorization func(*http.Request)) (*http.Response, error) {
+	req, err := client.newRequest(ctx, method, command, contentType, body, authorization)
 	if err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
-	req.Header.Add("Content-Type", "application/json")
-	req.Close = true
-	return http.DefaultClient.Do(req)
+	return client.roundTrip(req, body)
 }
 
-func (client *RDClientImpl) getRequestObject(method string, command string) (*http.Request, error) {
-	url := client.makeURL(client.connectionInfo.Host, client.connectionInfo.Port, command)
-	req, err := http.NewRequest(method, url, nil)
+// newRequest builds a request for the given command.
+func (client *RDClientImpl) newRequest(ctx context.Context, method, command, contentType string, body []byte, authorization func(*http.Request)) (*http.Request, error) {
+	var payload io.Reader
+	if body != nil {
+		payload = bytes.NewReader(body)
+	}
+	port := client.connectionInfo.Port
+	if client.connectionInfo.Socket != "" {
+		port = 0
+	}
+	url := client.makeURL(client.connectionInfo.Host, port, command)
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
 	if err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
-	req.Header.Add("Content-Type", "text/plain")
-	req.Close = true
+	authorization(req)
+	req.Header.Add("Content-Type", contentType)
+	req.Header.Set(CancelOnDisconnectHeader, "true")
 	return req, nil
 }
 
-func (client *RDClientImpl) GetBackendState() (BackendState, error) {
-	body, err := ProcessRequestForUtility(client.DoRequest("GET", VersionCommand("", "backend_state")))
+// roundTrip sends a request built by newRequest, tracing it.
+func (client *RDClientImpl) roundTrip(req *http.Request, body []byte) (*http.Response, error) {
+	httpClient, err := client.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	_, span := tracing.Start(req.Context(), "HTTP "+req.Method,
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.path", req.URL.Path),
+		attribute.Int("http.request.body.size", len(body)))
+	traceRequest(req, body)
+	start := time.Now()
+	response, err := httpClient.Do(req)
+	traceResponse(req, response, err, start)
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+	}
+	tracing.End(span, err)
+	return response, err
+}
+
+// requestPath returns the path of a command, without any query, for naming
+// trace spans.
+func requestPath(command string) string {
+	path, _, _ := strings.Cut(command, "?")
+	return path
+}
+
+func (client *RDClientImpl) GetBackendState(ctx context.Context) (BackendState, error) {
+	body, err := ProcessRequestForUtility(client.call(ctx, opGetBackendState, nil, nil))
 	if err != nil {
 		return BackendState{}, err
 	}
@@ -110,15 +340,11 @@ func (client *RDClientImpl) GetBackendState() (BackendState, error) {
 	return state, nil
 }
 
-func (client *RDClientImpl) UpdateBackendState(state BackendState) error {
-	buf := &bytes.Buffer{}
-	encoder := json.NewEncoder(buf)
-	if err := encoder.Encode(state); err != nil {
-		return fmt.Errorf("failed to marshal backend state: %w", err)
-	}
-	_, err := ProcessRequestForUtility(client.DoRequestWithPayload("PUT", VersionCommand("", "backend_state"), buf))
+func (client *RDClientImpl) UpdateBackendState(ctx context.Context, state BackendState) error {
+	payload, err := json.Marshal(state)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal backend state: %w", err)
 	}
-	return nil
+	_, err = ProcessRequestForUtility(client.call(ctx, opSetBackendState, nil, payload))
+	return err
 }
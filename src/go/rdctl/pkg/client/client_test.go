@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withVersions wraps a fake API server handler to answer version discovery
+// requests with the given versions.
+func withVersions(handler http.Handler, versions ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions" {
+			_ = json.NewEncoder(w).Encode(map[string][]string{"versions": versions})
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newTLSServer starts a TLS server and returns the connection info for it,
+// with the server certificate written to a file.
+func newTLSServer(t *testing.T) *config.ConnectionInfo {
+	t.Helper()
+	server := httptest.NewTLSServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	certificatePath := filepath.Join(t.TempDir(), "rd-engine.crt")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(certificatePath, certificate, 0o644))
+	return &config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Certificate: certificatePath, Token: "token"}
+}
+
+func TestPinnedCertificate(t *testing.T) {
+	t.Run("accepts the pinned certificate", func(t *testing.T) {
+		body, err := ProcessRequestForUtility(NewRDClient(newTLSServer(t)).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+	})
+
+	t.Run("rejects a different certificate", func(t *testing.T) {
+		connectionInfo := newTLSServer(t)
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "other"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		other, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(connectionInfo.Certificate, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other}), 0o644))
+		_, err = NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about")
+		assert.ErrorContains(t, err, "the server did not present the expected certificate")
+	})
+}
+
+func TestRemoteServer(t *testing.T) {
+	var paths []string
+	server := httptest.NewTLSServer(http.StripPrefix("/rd", withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("ok"))
+	}), ApiVersion)))
+	t.Cleanup(server.Close)
+	certificatePath := filepath.Join(t.TempDir(), "rd-engine.crt")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(certificatePath, certificate, 0o644))
+
+	t.Run("sends requests under the server URL", func(t *testing.T) {
+		rdClient := NewRDClient(&config.ConnectionInfo{Server: server.URL + "/rd", Certificate: certificatePath, Token: "token"})
+		body, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, []string{"/v1/about"}, paths)
+	})
+
+	t.Run("verifies the server against the system trust store without a certificate", func(t *testing.T) {
+		rdClient := NewRDClient(&config.ConnectionInfo{Server: server.URL + "/rd", Token: "token"})
+		_, err := rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about")
+		assert.ErrorContains(t, err, "certificate")
+	})
+}
+
+func TestCancellation(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(CancelOnDisconnectHeader)
+		<-r.Context().Done()
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		assert.Equal(t, "true", <-received)
+		cancel()
+	}()
+	_, err = rdClient.DoRequest(ctx, http.MethodPut, "/v1/shutdown")
+	assert.ErrorIs(t, err, context.Canceled)
+}
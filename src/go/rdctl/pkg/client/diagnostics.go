@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDiagnosticNotFound is returned when repairing a check that is
+	// unknown, or does not apply to this system.
+	ErrDiagnosticNotFound = errors.New("diagnostic check not found")
+	// ErrDiagnosticNotRepairable is returned when repairing a check that
+	// can't be repaired automatically.
+	ErrDiagnosticNotRepairable = errors.New("diagnostic check can't be repaired")
+)
+
+// DiagnosticsFix describes a possible fix for a failed diagnostics check.
+type DiagnosticsFix struct {
+	Description string `json:"description"`
+}
+
+// DiagnosticsCheck is the last result of a single diagnostics check.
+type DiagnosticsCheck struct {
+	ID            string           `json:"id"`
+	Category      string           `json:"category"`
+	Description   string           `json:"description"`
+	Documentation string           `json:"documentation,omitempty"`
+	Passed        bool             `json:"passed"`
+	Mute          bool             `json:"mute"`
+	Repairable    bool             `json:"repairable"`
+	Fixes         []DiagnosticsFix `json:"fixes"`
+}
+
+// DiagnosticsResults holds the results of the diagnostics checks, as of the
+// last time they were run.
+type DiagnosticsResults struct {
+	LastUpdate time.Time          `json:"last_update"`
+	Checks     []DiagnosticsCheck `json:"checks"`
+	// Total is the number of checks matching the query, before its offset
+	// and limit were applied.
+	Total int `json:"-"`
+}
+
+// DiagnosticsQuery selects the checks returned by DiagnosticChecks; the zero
+// value matches all of them.  The checks can be sorted by "id", "category"
+// or "passed".
+type DiagnosticsQuery struct {
+	Category string
+	ID       string
+	// Passed, if set, only matches the checks that passed (or failed).
+	Passed *bool
+	ListOptions
+}
+
+// DiagnosticCategories returns the names of the categories of checks.
+func (client *RDClientImpl) DiagnosticCategories(ctx context.Context) ([]string, error) {
+	var categories []string
+	if err := client.getJSON(ctx, opDiagnosticCategories, nil, &categories); err != nil {
+		return nil, fmt.Errorf("failed to get diagnostic categories: %w", err)
+	}
+	return categories, nil
+}
+
+// DiagnosticIDs returns the IDs of the checks in a category.
+func (client *RDClientImpl) DiagnosticIDs(ctx context.Context, category string) ([]string, error) {
+	var ids []string
+	if err := client.getJSON(ctx, opDiagnosticIDsForCategory, url.Values{"category": {category}}, &ids); err != nil {
+		return nil, fmt.Errorf("failed to get diagnostic IDs for category %q: %w", category, err)
+	}
+	return ids, nil
+}
+
+// DiagnosticChecks returns the last results of the checks selected by the
+// query.
+func (client *RDClientImpl) DiagnosticChecks(ctx context.Context, diagnosticsQuery DiagnosticsQuery) (*DiagnosticsResults, error) {
+	query := url.Values{}
+	if diagnosticsQuery.Category != "" {
+		query.Set("category", diagnosticsQuery.Category)
+	}
+	if diagnosticsQuery.ID != "" {
+		query.Set("id", diagnosticsQuery.ID)
+	}
+	if diagnosticsQuery.Passed != nil {
+		query.Set("passed", strconv.FormatBool(*diagnosticsQuery.Passed))
+	}
+	diagnosticsQuery.ListOptions.addTo(query)
+	results := &DiagnosticsResults{}
+	total, err := client.getList(ctx, opDiagnosticChecks, query, results, func() int { return len(results.Checks) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostic checks: %w", err)
+	}
+	results.Total = total
+	return results, nil
+}
+
+// RunDiagnostics runs all the checks, and returns their results.
+func (client *RDClientImpl) RunDiagnostics(ctx context.Context) (*DiagnosticsResults, error) {
+	results := &DiagnosticsResults{}
+	if err := client.getJSON(ctx, opDiagnosticRunChecks, nil, results); err != nil {
+		return nil, fmt.Errorf("failed to run diagnostics: %w", err)
+	}
+	return results, nil
+}
+
+// MuteDiagnostic mutes (or unmutes) a check, so that its failures are no
+// longer reported to the user.
+func (client *RDClientImpl) MuteDiagnostic(ctx context.Context, id string, mute bool) error {
+	current, err := client.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.UpdateSettings(ctx, &Settings{
+		Version:     current.Version,
+		Diagnostics: &SettingsDiagnostics{MutedChecks: map[string]any{id: mute}},
+	})
+	return err
+}
+
+// RepairDiagnostic attempts to fix the problem found by a check, returning
+// the result of running the check again afterwards.
+func (client *RDClientImpl) RepairDiagnostic(ctx context.Context, id string) (*DiagnosticsCheck, error) {
+	response, err := client.call(ctx, opDiagnosticRepair, url.Values{"id": {id}}, nil)
+	if err == nil {
+		// The server explains these errors in the body.
+		var sentinel error
+		switch response.StatusCode {
+		case http.StatusNotFound:
+			sentinel = ErrDiagnosticNotFound
+		case http.StatusConflict:
+			sentinel = ErrDiagnosticNotRepairable
+		case http.StatusInternalServerError:
+			sentinel = errors.New("repair failed")
+		}
+		if sentinel != nil {
+			defer response.Body.Close()
+			body, _ := io.ReadAll(response.Body)
+			return nil, fmt.Errorf("failed to repair %s: %w: %s", id, sentinel, strings.TrimSpace(string(body)))
+		}
+	}
+	check := &DiagnosticsCheck{}
+	if err := decodeResponse(response, err, check); err != nil {
+		return nil, fmt.Errorf("failed to repair %s: %w", id, err)
+	}
+	return check, nil
+}
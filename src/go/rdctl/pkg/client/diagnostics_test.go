@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diagnosticsResponse = `{
+  "last_update": "2023-06-01T12:00:00.000Z",
+  "checks": [
+    {"id": "PATH_MANAGEMENT", "category": "Utilities", "description": "~/.rd/bin is not in PATH", "passed": false, "mute": false, "fixes": [{"description": "Add ~/.rd/bin to PATH"}]},
+    {"id": "DOCKER_CONTEXT", "category": "Utilities", "description": "Docker context is correct", "passed": true, "mute": true, "fixes": []}
+  ]
+}`
+
+func TestDiagnostics(t *testing.T) {
+	var lastQuery url.Values
+	var lastMethod, settingsUpdate string
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod = r.Method
+		lastQuery = r.URL.Query()
+		switch r.URL.Path {
+		case "/v1/diagnostic_checks":
+			_, _ = w.Write([]byte(diagnosticsResponse))
+		case "/v1/diagnostic_repair":
+			switch r.URL.Query().Get("id") {
+			case "WSL_INTEGRATION_DNS":
+				_, _ = w.Write([]byte(`{"id": "WSL_INTEGRATION_DNS", "category": "Networking", "description": "Name resolution works", "passed": true, "repairable": true, "fixes": []}`))
+			case "PATH_MANAGEMENT":
+				http.Error(w, "Diagnostic check PATH_MANAGEMENT can't be repaired automatically", http.StatusConflict)
+			default:
+				http.Error(w, "Unknown diagnostic check", http.StatusNotFound)
+			}
+		case "/v1/settings":
+			if r.Method == http.MethodPut {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				settingsUpdate = string(body)
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			_, _ = w.Write([]byte(`{"version": 10, "diagnostics": {"mutedChecks": {"DOCKER_CONTEXT": true}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	t.Run("lists the results of the last run", func(t *testing.T) {
+		results, err := rdClient.DiagnosticChecks(context.Background(), DiagnosticsQuery{Category: "Utilities", ID: "PATH_MANAGEMENT"})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, lastMethod)
+		assert.Equal(t, url.Values{"category": {"Utilities"}, "id": {"PATH_MANAGEMENT"}}, lastQuery)
+		assert.Equal(t, time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC), results.LastUpdate)
+		require.Len(t, results.Checks, 2)
+		assert.Equal(t, DiagnosticsCheck{
+			ID:          "PATH_MANAGEMENT",
+			Category:    "Utilities",
+			Description: "~/.rd/bin is not in PATH",
+			Fixes:       []DiagnosticsFix{{Description: "Add ~/.rd/bin to PATH"}},
+		}, results.Checks[0])
+		assert.True(t, results.Checks[1].Mute)
+		assert.Equal(t, 2, results.Total)
+	})
+
+	t.Run("passes the list options", func(t *testing.T) {
+		passed := false
+		query := DiagnosticsQuery{Passed: &passed, ListOptions: ListOptions{Filter: "path", Sort: "-id", Offset: 1, Limit: 5}}
+		_, err := rdClient.DiagnosticChecks(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, url.Values{
+			"passed": {"false"},
+			"filter": {"path"},
+			"sort":   {"-id"},
+			"offset": {"1"},
+			"limit":  {"5"},
+		}, lastQuery)
+	})
+
+	t.Run("runs the checks", func(t *testing.T) {
+		results, err := rdClient.RunDiagnostics(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, lastMethod)
+		assert.Len(t, results.Checks, 2)
+	})
+
+	t.Run("repairs a check", func(t *testing.T) {
+		check, err := rdClient.RepairDiagnostic(context.Background(), "WSL_INTEGRATION_DNS")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, lastMethod)
+		assert.True(t, check.Passed)
+		assert.True(t, check.Repairable)
+
+		_, err = rdClient.RepairDiagnostic(context.Background(), "PATH_MANAGEMENT")
+		assert.ErrorIs(t, err, ErrDiagnosticNotRepairable)
+		assert.ErrorContains(t, err, "can't be repaired automatically")
+
+		_, err = rdClient.RepairDiagnostic(context.Background(), "UNKNOWN")
+		assert.ErrorIs(t, err, ErrDiagnosticNotFound)
+	})
+
+	t.Run("mutes a check through the settings", func(t *testing.T) {
+		require.NoError(t, rdClient.MuteDiagnostic(context.Background(), "PATH_MANAGEMENT", true))
+		assert.JSONEq(t, `{"version": 10, "diagnostics": {"mutedChecks": {"PATH_MANAGEMENT": true}}}`, settingsUpdate)
+	})
+}
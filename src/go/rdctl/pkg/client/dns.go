@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// dnsSettingsUpdate is the payload to change the DNS settings of the VM.  The
+// lists are not omitted when empty (as they are in Settings), so that they can
+// be cleared.
+type dnsSettingsUpdate struct {
+	Version        *int `json:"version,omitempty"`
+	VirtualMachine struct {
+		DNS struct {
+			UpstreamServers []string `json:"upstreamServers"`
+			Domains         []string `json:"domains"`
+		} `json:"dns"`
+	} `json:"virtualMachine"`
+}
+
+// SetDNSServers replaces the upstream and per-domain DNS servers of the VM
+// (the latter as `domain=server`), returning the status message from the
+// backend.
+func (client *RDClientImpl) SetDNSServers(ctx context.Context, upstreamServers, domains []string) (string, error) {
+	current, err := client.GetSettings(ctx)
+	if err != nil {
+		return "", err
+	}
+	update := dnsSettingsUpdate{Version: current.Version}
+	update.VirtualMachine.DNS.UpstreamServers = append([]string{}, upstreamServers...)
+	update.VirtualMachine.DNS.Domains = append([]string{}, domains...)
+	result, err := client.putJSON(ctx, opUpdateSettings, update)
+	if err != nil {
+		return "", fmt.Errorf("failed to update DNS settings: %w", err)
+	}
+	return string(result), nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EngineProxyProtocol is the protocol requested to upgrade a connection to the
+// engine proxy.
+const EngineProxyProtocol = "rd-engine-proxy"
+
+// DialEngine opens a connection to the socket of the container engine inside
+// the VM, through the API.  This works wherever the API is reachable, even if
+// the socket isn't forwarded to the host.
+func (client *RDClientImpl) DialEngine(ctx context.Context) (io.ReadWriteCloser, error) {
+	conn, err := client.dialEngineOnce(ctx)
+	if errors.Is(err, errUnauthorized) && client.session.Token != "" {
+		// The backend may have restarted and forgotten our token; get a new one.
+		client.forgetSession()
+		conn, err = client.dialEngineOnce(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the container engine: %w", err)
+	}
+	return conn, nil
+}
+
+// errUnauthorized is returned by dialEngineOnce when the credentials are
+// rejected.
+var errUnauthorized = errors.New("unauthorized")
+
+func (client *RDClientImpl) dialEngineOnce(ctx context.Context) (io.ReadWriteCloser, error) {
+	command, err := client.command(ctx, opEngineProxy, nil)
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := client.authorization(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.newRequest(ctx, opEngineProxy.method, command, "text/plain", nil, authorization)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", EngineProxyProtocol)
+	response, err := client.roundTrip(req, nil)
+	if err != nil {
+		return nil, handleConnectionRefused(err)
+	}
+	switch response.StatusCode {
+	case http.StatusSwitchingProtocols:
+		conn, ok := response.Body.(io.ReadWriteCloser)
+		if !ok {
+			response.Body.Close()
+			return nil, errors.New("the server did not return a usable connection")
+		}
+		return conn, nil
+	case http.StatusUnauthorized:
+		response.Body.Close()
+		return nil, errUnauthorized
+	}
+	_, err = ProcessRequestForUtility(response, nil)
+	if err == nil {
+		err = fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return nil, err
+}
+
+// ServeEngineProxy accepts connections on the listener, relaying each one to
+// the container engine through DialEngine, until the context is cancelled.
+func (client *RDClientImpl) ServeEngineProxy(ctx context.Context, listener net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+	// DialEngine is not safe for concurrent use, as it may replace the session.
+	var dialMutex sync.Mutex
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			dialMutex.Lock()
+			engine, err := client.DialEngine(ctx)
+			dialMutex.Unlock()
+			if err != nil {
+				logrus.WithError(err).Error("failed to proxy connection")
+				return
+			}
+			defer engine.Close()
+			relay(ctx, conn, engine)
+		}()
+	}
+}
+
+// relay copies data both ways between a proxied connection and the engine,
+// until the engine is done or the context is cancelled.  Clients half-close
+// their connection once they have sent everything (e.g. `docker run -i` at the
+// end of its input), so that alone doesn't end the relay.
+func relay(ctx context.Context, conn net.Conn, engine io.ReadWriteCloser) {
+	done := make(chan struct{})
+	go func() {
+		if _, err := io.Copy(engine, conn); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Debug("failed to copy to the container engine")
+		}
+		if closer, ok := engine.(interface{ CloseWrite() error }); ok {
+			_ = closer.CloseWrite()
+		}
+	}()
+	go func() {
+		defer close(done)
+		if _, err := io.Copy(conn, engine); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Debug("failed to copy from the container engine")
+		}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	// Closing both sides unblocks the copies.
+	conn.Close()
+	engine.Close()
+}
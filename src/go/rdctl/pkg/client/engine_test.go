@@ -0,0 +1,94 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoEngine upgrades engine proxy requests, and echoes everything it reads.
+func echoEngine(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/engine_proxy" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Upgrade") != EngineProxyProtocol {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + EngineProxyProtocol + "\r\n\r\n"))
+		_, _ = io.Copy(conn, buf)
+	})
+}
+
+func newEngineTestClient(t *testing.T, handler http.Handler) *RDClientImpl {
+	server := httptest.NewServer(withVersions(handler, ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+}
+
+func TestDialEngine(t *testing.T) {
+	t.Run("returns the upgraded connection", func(t *testing.T) {
+		rdClient := newEngineTestClient(t, echoEngine(t))
+		conn, err := rdClient.DialEngine(context.Background())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("ping\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "ping\n", line)
+	})
+
+	t.Run("reports errors from the server", func(t *testing.T) {
+		rdClient := newEngineTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "The container engine is not running", http.StatusServiceUnavailable)
+		}))
+		_, err := rdClient.DialEngine(context.Background())
+		assert.ErrorContains(t, err, "503")
+	})
+}
+
+func TestServeEngineProxy(t *testing.T) {
+	rdClient := newEngineTestClient(t, echoEngine(t))
+	listener, err := ListenSocket(filepath.Join(t.TempDir(), "engine.sock"))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- rdClient.ServeEngineProxy(ctx, listener)
+	}()
+
+	conn, err := net.Dial("unix", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", line)
+	conn.Close()
+
+	cancel()
+	assert.NoError(t, <-served)
+}
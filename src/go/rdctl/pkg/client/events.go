@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Event types sent by the events endpoint.
+const (
+	// EventSettings carries the new settings whenever they change.
+	EventSettings = "settings"
+	// EventBackendState carries a BackendState whenever the state of the VM
+	// or the backend lock changes.
+	EventBackendState = "backend-state"
+)
+
+// maxEventSize is the longest line accepted in the event stream.
+const maxEventSize = 4 * 1024 * 1024
+
+// ErrEventStreamClosed is returned by Subscribe when the server ends the stream.
+var ErrEventStreamClosed = errors.New("the event stream was closed by the server")
+
+// Event is a single server-sent event.
+type Event struct {
+	ID   string
+	Type string
+	Data json.RawMessage
+}
+
+// Subscribe receives events of the given types (all of them if none are
+// given), calling the handler for each one.  The first event of each type
+// holds the current state.  This blocks until the context is cancelled, the
+// handler returns an error, or the server closes the connection (for example,
+// because the application is shutting down); ErrEventStreamClosed is returned
+// in the last case.
+func (client *RDClientImpl) Subscribe(ctx context.Context, types []string, handler func(Event) error) error {
+	var query url.Values
+	if len(types) > 0 {
+		query = url.Values{"types": {strings.Join(types, ",")}}
+	}
+	response, err := client.call(ctx, opSubscribeEvents, query, nil)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return handleConnectionRefused(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		_, err = ProcessRequestForUtility(response, nil)
+		if err == nil {
+			err = fmt.Errorf("%s (unexpected server response)", response.Status)
+		}
+		return err
+	}
+	defer response.Body.Close()
+	err = readEvents(response.Body, handler)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// readEvents parses a text/event-stream body, calling the handler for each
+// complete event.
+func readEvents(body io.Reader, handler func(Event) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if event.Type == "" {
+					event.Type = "message"
+				}
+				event.Data = json.RawMessage(strings.Join(data, "\n"))
+				if err := handler(event); err != nil {
+					return err
+				}
+			}
+			event = Event{ID: event.ID}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comments are used to keep the connection alive.
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.ID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	return ErrEventStreamClosed
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEventServer(t *testing.T, handler http.HandlerFunc) *config.ConnectionInfo {
+	t.Helper()
+	server := httptest.NewServer(withVersions(handler, ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return &config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"}
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("parses events", func(t *testing.T) {
+		connectionInfo := newEventServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/events", r.URL.Path)
+			assert.Equal(t, "settings,backend-state", r.URL.Query().Get("types"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "id: 1\nevent: backend-state\ndata: {\"vmState\":\"STARTED\"}\n\n")
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "id: 2\nevent: settings\ndata: {\"a\":\ndata: 1}\n\n")
+		})
+		var events []Event
+		err := NewRDClient(connectionInfo).Subscribe(context.Background(), []string{EventSettings, EventBackendState}, func(event Event) error {
+			events = append(events, event)
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrEventStreamClosed)
+		require.Len(t, events, 2)
+		assert.Equal(t, Event{ID: "1", Type: EventBackendState, Data: []byte(`{"vmState":"STARTED"}`)}, events[0])
+		assert.Equal(t, Event{ID: "2", Type: EventSettings, Data: []byte("{\"a\":\n1}")}, events[1])
+	})
+
+	t.Run("stops when the handler fails", func(t *testing.T) {
+		connectionInfo := newEventServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, "event: settings\ndata: {}\n\nevent: settings\ndata: {}\n\n")
+		})
+		count := 0
+		handlerErr := fmt.Errorf("handler failed")
+		err := NewRDClient(connectionInfo).Subscribe(context.Background(), nil, func(Event) error {
+			count++
+			return handlerErr
+		})
+		assert.ErrorIs(t, err, handlerErr)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		connectionInfo := newEventServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "event: settings\ndata: {}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		err := NewRDClient(connectionInfo).Subscribe(ctx, nil, func(Event) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("reports server errors", func(t *testing.T) {
+		connectionInfo := newEventServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "Unknown event types nope")
+		})
+		err := NewRDClient(connectionInfo).Subscribe(context.Background(), []string{"nope"}, func(Event) error { return nil })
+		assert.ErrorContains(t, err, "Unknown event types nope")
+	})
+}
@@ -0,0 +1,347 @@
+// Command generate writes the Go types for the settings of the API client, and
+// the operations it calls, from pkg/rancher-desktop/assets/specs/command-api.yaml.
+//
+// Every field is optional (a pointer, slice or map, omitted when empty), so
+// that the same types can describe the full settings returned by the backend
+// and the partial updates sent to it.
+//
+// The settings are also described by SettingsSchema, a jsonpath.Schema that
+// the commands reading and writing settings by name look them up in.
+//
+// Each operation becomes an `op<OperationId>` variable holding its method and
+// path; the client only calls the API through these, so that removing or
+// renaming an endpoint in the spec breaks the build of the client.
+//
+// Usage: go run ./generate <command-api.yaml> <output.go>
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// generatedTypes maps the name of each schema to generate to its Go type.
+var generatedTypes = []struct {
+	schema   string
+	typeName string
+}{
+	{"preferences", "Settings"},
+	{"transientSettings", "TransientSettings"},
+}
+
+type schema struct {
+	Type                 string
+	Enum                 []string
+	Items                *schema
+	Properties           properties
+	AdditionalProperties yaml.Node `yaml:"additionalProperties"`
+	Platforms            []string  `yaml:"x-rd-platforms"`
+	Usage                string    `yaml:"x-rd-usage"`
+}
+
+// versionedPath matches the paths of versioned endpoints, capturing the path
+// relative to the version.
+var versionedPath = regexp.MustCompile(`^/v\d+/(.+)$`)
+
+// httpMethods are the keys of a path item that describe operations.
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "patch": true, "head": true, "options": true,
+}
+
+type property struct {
+	name   string
+	schema schema
+}
+
+// properties keeps the properties of an object in the order of the spec.
+type properties []property
+
+func (p *properties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: properties must be a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		prop := property{name: node.Content[i].Value}
+		if err := node.Content[i+1].Decode(&prop.schema); err != nil {
+			return fmt.Errorf("property %q: %w", prop.name, err)
+		}
+		*p = append(*p, prop)
+	}
+	return nil
+}
+
+type generator struct {
+	output bytes.Buffer
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <command-api.yaml> <output.go>\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := run(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(inputPath, outputPath string) error {
+	contents, err := os.ReadFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read spec: %w", err)
+	}
+	var spec struct {
+		Paths      yaml.Node
+		Components struct {
+			Schemas map[string]schema
+		}
+	}
+	if err := yaml.Unmarshal(contents, &spec); err != nil {
+		return fmt.Errorf("failed to parse spec %q: %w", inputPath, err)
+	}
+	g := &generator{}
+	g.printf("// Code generated by go generate; DO NOT EDIT.\n")
+	g.printf("// To rebuild this file, run `go generate ./pkg/client` in src/go/rdctl.\n\n")
+	g.printf("package client\n\n")
+	g.printf("import %q\n", "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath")
+	for _, generated := range generatedTypes {
+		s, ok := spec.Components.Schemas[generated.schema]
+		if !ok {
+			return fmt.Errorf("schema %q not found in %q", generated.schema, inputPath)
+		}
+		comment := fmt.Sprintf("%s is the %s schema of the API.", generated.typeName, generated.schema)
+		if err := g.writeStruct(generated.typeName, comment, s, generated.schema == "preferences"); err != nil {
+			return err
+		}
+	}
+	g.printf("\n// SettingsSchema describes the settings (the preferences schema of the API),\n")
+	g.printf("// for looking them up by name.\n")
+	g.printf("var SettingsSchema = ")
+	if err := g.writeSchema(spec.Components.Schemas["preferences"], true); err != nil {
+		return err
+	}
+	g.printf("\n")
+	if err := g.writeOperations(spec.Paths); err != nil {
+		return err
+	}
+	source, err := format.Source(g.output.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return os.WriteFile(outputPath, source, 0o644)
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.output, format, args...)
+}
+
+// writeStruct writes a struct type for an object schema, followed by the types
+// of its nested objects.
+func (g *generator) writeStruct(typeName, comment string, s schema, versioned bool) error {
+	type nested struct {
+		typeName string
+		comment  string
+		schema   schema
+	}
+	var children []nested
+
+	g.printf("\n// %s\ntype %s struct {\n", comment, typeName)
+	if versioned {
+		g.printf("// Version is the version of the settings format.\n")
+		g.printf("Version *int `json:\"version,omitempty\"`\n")
+	}
+	for _, prop := range s.Properties {
+		fieldName := exportedName(prop.name)
+		for _, line := range fieldComments(prop.schema) {
+			g.printf("// %s\n", line)
+		}
+		if prop.schema.Type == "object" && len(prop.schema.Properties) > 0 {
+			childType := typeName + fieldName
+			children = append(children, nested{
+				typeName: childType,
+				comment:  fmt.Sprintf("%s holds the %s settings of %s.", childType, prop.name, typeName),
+				schema:   prop.schema,
+			})
+			g.printf("%s *%s `json:\"%s,omitempty\"`\n", fieldName, childType, prop.name)
+			continue
+		}
+		goType, err := scalarType(prop.schema)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", typeName, prop.name, err)
+		}
+		g.printf("%s %s `json:\"%s,omitempty\"`\n", fieldName, goType, prop.name)
+	}
+	g.printf("}\n")
+	for _, child := range children {
+		if err := g.writeStruct(child.typeName, child.comment, child.schema, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSchema writes a jsonpath.Schema literal for a schema; versioned adds
+// the version of the settings format, as writeStruct does.
+func (g *generator) writeSchema(s schema, versioned bool) error {
+	kind, err := schemaKind(s)
+	if err != nil {
+		return err
+	}
+	if kind != "Group" {
+		g.printf("&jsonpath.Schema{Kind: jsonpath.%s}", kind)
+		return nil
+	}
+	g.printf("&jsonpath.Schema{Kind: jsonpath.Group, Fields: []jsonpath.Field{\n")
+	if versioned {
+		g.printf("{Name: %q, Schema: &jsonpath.Schema{Kind: jsonpath.Int}},\n", "version")
+	}
+	for _, prop := range s.Properties {
+		g.printf("{Name: %q, Schema: ", prop.name)
+		if err := g.writeSchema(prop.schema, false); err != nil {
+			return fmt.Errorf("%s: %w", prop.name, err)
+		}
+		g.printf("},\n")
+	}
+	g.printf("}}")
+	return nil
+}
+
+// schemaKind returns the name of the jsonpath.Kind for a schema.
+func schemaKind(s schema) (string, error) {
+	switch s.Type {
+	case "boolean":
+		return "Bool", nil
+	case "integer":
+		return "Int", nil
+	case "number":
+		return "Number", nil
+	case "string":
+		return "String", nil
+	case "array":
+		return "List", nil
+	case "object":
+		if len(s.Properties) > 0 {
+			return "Group", nil
+		}
+		return "Map", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// writeOperations writes a variable for each operation of the versioned
+// endpoints, in the order of the spec.
+func (g *generator) writeOperations(paths yaml.Node) error {
+	if paths.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: paths must be a mapping", paths.Line)
+	}
+	g.printf("\n// The operations of the API, named after their operationId.\nvar (\n")
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path := paths.Content[i].Value
+		match := versionedPath.FindStringSubmatch(path)
+		if match == nil {
+			// Version discovery and endpoint listings aren't called directly.
+			continue
+		}
+		item := paths.Content[i+1]
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			method := item.Content[j].Value
+			if !httpMethods[method] {
+				continue
+			}
+			var op struct {
+				OperationID string `yaml:"operationId"`
+				Summary     string
+			}
+			if err := item.Content[j+1].Decode(&op); err != nil {
+				return fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if op.OperationID == "" {
+				return fmt.Errorf("%s %s has no operationId", method, path)
+			}
+			method = strings.ToUpper(method)
+			if op.Summary != "" {
+				g.printf("// %s %s: %s\n", method, path, strings.TrimSpace(op.Summary))
+			}
+			g.printf("op%s = operation{%q, %q}\n", exportedName(op.OperationID), method, match[1])
+		}
+	}
+	g.printf(")\n")
+	return nil
+}
+
+// scalarType returns the Go type for a schema that doesn't need a struct.
+func scalarType(s schema) (string, error) {
+	switch s.Type {
+	case "boolean":
+		return "*bool", nil
+	case "integer":
+		return "*int", nil
+	case "number":
+		return "*float64", nil
+	case "string":
+		return "*string", nil
+	case "array":
+		if s.Items == nil {
+			return "[]any", nil
+		}
+		itemType, err := scalarType(*s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + strings.TrimPrefix(itemType, "*"), nil
+	case "object":
+		switch s.AdditionalProperties.Kind {
+		case yaml.MappingNode:
+			var valueSchema schema
+			if err := s.AdditionalProperties.Decode(&valueSchema); err != nil {
+				return "", err
+			}
+			valueType, err := scalarType(valueSchema)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + strings.TrimPrefix(valueType, "*"), nil
+		default:
+			return "map[string]any", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// fieldComments returns the doc comment lines for a field.
+func fieldComments(s schema) []string {
+	var lines []string
+	if s.Usage != "" {
+		lines = append(lines, strings.ToUpper(s.Usage[:1])+s.Usage[1:]+".")
+	}
+	if len(s.Enum) > 0 {
+		lines = append(lines, fmt.Sprintf("One of: %s.", strings.Join(s.Enum, ", ")))
+	}
+	if len(s.Platforms) > 0 {
+		lines = append(lines, fmt.Sprintf("Only used on %s.", strings.Join(s.Platforms, ", ")))
+	}
+	return lines
+}
+
+var digitNames = map[byte]string{
+	'0': "Zero", '1': "One", '2': "Two", '3': "Three", '4': "Four",
+	'5': "Five", '6': "Six", '7': "Seven", '8': "Eight", '9': "Nine",
+}
+
+// exportedName turns a property name into an exported Go identifier; a
+// leading digit is spelled out, so that "9p" becomes "NineP".
+func exportedName(name string) string {
+	if name == "" {
+		return ""
+	}
+	if digit, ok := digitNames[name[0]]; ok {
+		return digit + exportedName(name[1:])
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
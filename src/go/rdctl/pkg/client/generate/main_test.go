@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedCodeIsCurrent(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "api_generated.go")
+	require.NoError(t, run("../../../../../../pkg/rancher-desktop/assets/specs/command-api.yaml", outputPath))
+	expected, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	actual, err := os.ReadFile("../api_generated.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual), "api_generated.go is out of date; run `go generate ./pkg/client`")
+}
+
+func TestExportedName(t *testing.T) {
+	assert.Equal(t, "Kubernetes", exportedName("kubernetes"))
+	assert.Equal(t, "WSL", exportedName("WSL"))
+	assert.Equal(t, "NineP", exportedName("9p"))
+}
+
+func TestSchemaKind(t *testing.T) {
+	group := schema{Type: "object", Properties: properties{{name: "enabled", schema: schema{Type: "boolean"}}}}
+	for expected, s := range map[string]schema{
+		"Bool":   {Type: "boolean"},
+		"Int":    {Type: "integer"},
+		"String": {Type: "string"},
+		"List":   {Type: "array", Items: &schema{Type: "string"}},
+		"Map":    {Type: "object"},
+		"Group":  group,
+	} {
+		kind, err := schemaKind(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, kind)
+	}
+	_, err := schemaKind(schema{Type: "null"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kinds of long-running operations that can be started as jobs.
+const (
+	JobKindFactoryReset    = "factory-reset"
+	JobKindKubernetesReset = "kubernetes-reset"
+	JobKindSnapshotCreate  = "snapshot-create"
+	JobKindSnapshotRestore = "snapshot-restore"
+	JobKindSnapshotDelete  = "snapshot-delete"
+)
+
+// States of a job; all but JobStateRunning are final.
+const (
+	JobStateRunning   = "running"
+	JobStateSucceeded = "succeeded"
+	JobStateFailed    = "failed"
+	JobStateCanceled  = "canceled"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown jobs, including those that
+	// finished too long ago for the server to remember them.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotCancelable is returned when canceling a job that can't be
+	// interrupted.
+	ErrJobNotCancelable = errors.New("job can't be canceled")
+)
+
+// jobPollInterval is how often WaitForJob checks the state of the job.
+var jobPollInterval = 500 * time.Millisecond
+
+// JobProgress is the progress reported by a running job.
+type JobProgress struct {
+	Current int `json:"current"`
+	// Max is less than zero if the progress is indeterminate.
+	Max         int    `json:"max"`
+	Description string `json:"description,omitempty"`
+}
+
+// Job is a long-running operation running in the application.
+type Job struct {
+	ID         string       `json:"id"`
+	Kind       string       `json:"kind"`
+	State      string       `json:"state"`
+	Cancelable bool         `json:"cancelable"`
+	Progress   *JobProgress `json:"progress,omitempty"`
+	// Result describes the outcome of a successful job.
+	Result string `json:"result,omitempty"`
+	// Error describes why the job failed or was canceled.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Finished returns whether the job has stopped running.
+func (job *Job) Finished() bool {
+	return job.State != JobStateRunning
+}
+
+// JobRequest describes the job to start; which of the fields are used
+// depends on its kind.
+type JobRequest struct {
+	Kind string `json:"kind"`
+	// KeepSystemImages is used by factory-reset.
+	KeepSystemImages bool `json:"keepSystemImages,omitempty"`
+	// Mode is used by kubernetes-reset: "fast" (the default) or "wipe".
+	Mode string `json:"mode,omitempty"`
+	// Name is used by the snapshot jobs.
+	Name string `json:"name,omitempty"`
+	// Description is used by snapshot-create.
+	Description string `json:"description,omitempty"`
+}
+
+// StartJob starts a long-running operation, returning once it has started;
+// use WaitForJob to wait for it to finish.
+func (client *RDClientImpl) StartJob(ctx context.Context, request JobRequest) (*Job, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job request: %w", err)
+	}
+	job := &Job{}
+	if err := client.jobRequest(ctx, opCreateJob, nil, payload, job); err != nil {
+		return nil, fmt.Errorf("failed to start %s job: %w", request.Kind, err)
+	}
+	return job, nil
+}
+
+// GetJob returns the current state of a job.
+func (client *RDClientImpl) GetJob(ctx context.Context, id string) (*Job, error) {
+	job := &Job{}
+	if err := client.jobRequest(ctx, opGetJob, url.Values{"id": {id}}, nil, job); err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// ListJobs returns the running jobs, and those that finished recently.
+func (client *RDClientImpl) ListJobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	if err := client.getJSON(ctx, opListJobs, nil, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// CancelJob asks a running job to stop; it returns before the job has done
+// so.
+func (client *RDClientImpl) CancelJob(ctx context.Context, id string) (*Job, error) {
+	job := &Job{}
+	if err := client.jobRequest(ctx, opCancelJob, url.Values{"id": {id}}, nil, job); err != nil {
+		return nil, fmt.Errorf("failed to cancel job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// WaitForJob polls the job until it finishes, calling progress (if not nil)
+// with its state each time.  An error is returned if the job failed or was
+// canceled, along with the final state of the job.
+func (client *RDClientImpl) WaitForJob(ctx context.Context, id string, progress func(*Job)) (*Job, error) {
+	for {
+		job, err := client.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(job)
+		}
+		if job.Finished() {
+			if job.State != JobStateSucceeded {
+				return job, fmt.Errorf("%s job %s: %s", job.Kind, job.State, job.Error)
+			}
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// RunJob starts a job and waits for it to finish; see WaitForJob.
+func (client *RDClientImpl) RunJob(ctx context.Context, request JobRequest, progress func(*Job)) (*Job, error) {
+	job, err := client.StartJob(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return client.WaitForJob(ctx, job.ID, progress)
+}
+
+// jobRequest sends a request for a job endpoint, decoding the job in the
+// response.  The server explains unknown and uncancelable jobs in the body.
+func (client *RDClientImpl) jobRequest(ctx context.Context, op operation, query url.Values, payload []byte, job *Job) error {
+	response, err := client.call(ctx, op, query, payload)
+	if err == nil {
+		switch response.StatusCode {
+		case http.StatusNotFound, http.StatusConflict:
+			defer response.Body.Close()
+			body, _ := io.ReadAll(response.Body)
+			sentinel := ErrJobNotFound
+			if response.StatusCode == http.StatusConflict {
+				sentinel = ErrJobNotCancelable
+			}
+			return fmt.Errorf("%w: %s", sentinel, strings.TrimSpace(string(body)))
+		}
+	}
+	return decodeResponse(response, err, job)
+}
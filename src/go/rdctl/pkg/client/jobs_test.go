@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	pollInterval := jobPollInterval
+	jobPollInterval = time.Millisecond
+	t.Cleanup(func() { jobPollInterval = pollInterval })
+
+	var lastRequest JobRequest
+	// states are the successive states of the job, as returned by GET /v1/job.
+	var states []string
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs":
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(body, &lastRequest))
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"id": "job-1", "kind": "` + lastRequest.Kind + `", "state": "running", "cancelable": false, "createdAt": "2023-06-01T12:00:00.000Z"}`))
+		case id != "job-1":
+			http.Error(w, `Unknown job "`+id+`"`, http.StatusNotFound)
+		case r.URL.Path == "/v1/job/cancel":
+			http.Error(w, "The kubernetes-reset job job-1 can't be canceled", http.StatusConflict)
+		case r.URL.Path == "/v1/job":
+			state := states[0]
+			states = states[1:]
+			job := map[string]any{"id": id, "kind": "kubernetes-reset", "state": state, "cancelable": false, "createdAt": "2023-06-01T12:00:00.000Z"}
+			switch state {
+			case JobStateRunning:
+				job["progress"] = map[string]any{"current": 1, "max": 3, "description": "Starting Kubernetes"}
+			case JobStateFailed:
+				job["error"] = "Cannot reset Kubernetes while the backend is STARTING"
+			default:
+				job["result"] = "Kubernetes successfully reset"
+			}
+			_ = json.NewEncoder(w).Encode(job)
+		default:
+			http.NotFound(w, r)
+		}
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	t.Run("runs a job to completion", func(t *testing.T) {
+		states = []string{JobStateRunning, JobStateRunning, JobStateSucceeded}
+		var progress []string
+		job, err := rdClient.RunJob(context.Background(), JobRequest{Kind: JobKindKubernetesReset, Mode: "wipe"}, func(job *Job) {
+			if job.Progress != nil {
+				progress = append(progress, job.Progress.Description)
+			}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, JobRequest{Kind: JobKindKubernetesReset, Mode: "wipe"}, lastRequest)
+		assert.Equal(t, "Kubernetes successfully reset", job.Result)
+		assert.Equal(t, []string{"Starting Kubernetes", "Starting Kubernetes"}, progress)
+		assert.Empty(t, states)
+	})
+
+	t.Run("reports failed jobs", func(t *testing.T) {
+		states = []string{JobStateRunning, JobStateFailed}
+		job, err := rdClient.WaitForJob(context.Background(), "job-1", nil)
+		assert.ErrorContains(t, err, "kubernetes-reset job failed: Cannot reset Kubernetes")
+		require.NotNil(t, job)
+		assert.Equal(t, JobStateFailed, job.State)
+	})
+
+	t.Run("reports unknown jobs", func(t *testing.T) {
+		_, err := rdClient.GetJob(context.Background(), "job-2")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		assert.ErrorContains(t, err, `Unknown job "job-2"`)
+	})
+
+	t.Run("reports jobs that can't be canceled", func(t *testing.T) {
+		_, err := rdClient.CancelJob(context.Background(), "job-1")
+		assert.ErrorIs(t, err, ErrJobNotCancelable)
+	})
+}
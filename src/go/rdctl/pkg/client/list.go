@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// totalCountHeader holds the number of items matching the filter of a list
+// request, before the offset and limit are applied.
+const totalCountHeader = "X-Total-Count"
+
+// ListOptions selects the items returned by the list endpoints; the zero
+// value returns all of them, in the default order.
+type ListOptions struct {
+	// Filter only keeps the items with a name or description containing the
+	// text, ignoring case.
+	Filter string
+	// Sort is the field to sort by; prefix it with "-" for descending order.
+	Sort string
+	// Offset is the number of items to skip.
+	Offset int
+	// Limit is the maximum number of items to return, if non-zero.
+	Limit int
+}
+
+// addTo sets the query parameters for the options.
+func (options ListOptions) addTo(query url.Values) {
+	if options.Filter != "" {
+		query.Set("filter", options.Filter)
+	}
+	if options.Sort != "" {
+		query.Set("sort", options.Sort)
+	}
+	if options.Offset > 0 {
+		query.Set("offset", strconv.Itoa(options.Offset))
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+}
+
+// getList decodes the response of a list request into value, returning the
+// total number of matching items.  Servers without pagination don't send the
+// total, in which case fallback is returned.
+func (client *RDClientImpl) getList(ctx context.Context, op operation, query url.Values, value any, fallback func() int) (int, error) {
+	response, err := client.call(ctx, op, query, nil)
+	if err := decodeResponse(response, err, value); err != nil {
+		return 0, err
+	}
+	return totalCount(response, fallback)
+}
+
+func totalCount(response *http.Response, fallback func() int) (int, error) {
+	header := response.Header.Get(totalCountHeader)
+	if header == "" {
+		return fallback(), nil
+	}
+	total, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s header %q: %w", totalCountHeader, header, err)
+	}
+	return total, nil
+}
+
+// Snapshot describes a snapshot, as listed by the API.
+type Snapshot struct {
+	Name        string    `json:"name"`
+	Created     time.Time `json:"created"`
+	Description string    `json:"description,omitempty"`
+}
+
+// ListSnapshots returns the snapshots selected by the options (sortable by
+// "name" or "created"), and the number of snapshots matching the filter.
+func (client *RDClientImpl) ListSnapshots(ctx context.Context, options ListOptions) ([]Snapshot, int, error) {
+	query := url.Values{}
+	options.addTo(query)
+	var snapshots []Snapshot
+	total, err := client.getList(ctx, opListSnapshots, query, &snapshots, func() int { return len(snapshots) })
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return snapshots, total, nil
+}
+
+// Extension describes an installed extension.
+type Extension struct {
+	ID       string            `json:"-"`
+	Version  string            `json:"version"`
+	Metadata map[string]any    `json:"metadata"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// extensionList decodes the object returned by the API, keeping the order
+// the server sorted the extensions in.
+type extensionList []Extension
+
+func (list *extensionList) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return fmt.Errorf("expected an object, got %v", token)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		extension := Extension{ID: token.(string)}
+		if err := decoder.Decode(&extension); err != nil {
+			return fmt.Errorf("failed to decode extension %q: %w", extension.ID, err)
+		}
+		*list = append(*list, extension)
+	}
+	return nil
+}
+
+// ListExtensions returns the installed extensions selected by the options
+// (sortable by "id" or "version"), and the number of extensions matching the
+// filter.
+func (client *RDClientImpl) ListExtensions(ctx context.Context, options ListOptions) ([]Extension, int, error) {
+	query := url.Values{}
+	options.addTo(query)
+	var extensions extensionList
+	total, err := client.getList(ctx, opListExtensions, query, &extensions, func() int { return len(extensions) })
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	return extensions, total, nil
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLists(t *testing.T) {
+	var lastQuery url.Values
+	totalCount := ""
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		if totalCount != "" {
+			w.Header().Set(totalCountHeader, totalCount)
+		}
+		switch r.URL.Path {
+		case "/v1/snapshots":
+			_, _ = w.Write([]byte(`[{"name": "before-upgrade", "created": "2023-06-01T12:00:00.000Z", "description": "k8s 1.26"}]`))
+		case "/v1/extensions":
+			_, _ = w.Write([]byte(`{
+				"zeta/ext": {"version": "1.0", "metadata": {"icon": "z.svg"}, "labels": {}},
+				"alpha/ext": {"version": "2.0", "metadata": {"icon": "a.svg"}, "labels": {"a": "b"}}
+			}`))
+		case "/v1/diagnostic_checks":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`Invalid sort field "color": must be one of id, category, passed`))
+		default:
+			http.NotFound(w, r)
+		}
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	t.Run("lists snapshots", func(t *testing.T) {
+		totalCount = "7"
+		snapshots, total, err := rdClient.ListSnapshots(context.Background(), ListOptions{Sort: "-created", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, url.Values{"sort": {"-created"}, "limit": {"1"}}, lastQuery)
+		assert.Equal(t, 7, total)
+		assert.Equal(t, []Snapshot{{
+			Name:        "before-upgrade",
+			Created:     time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+			Description: "k8s 1.26",
+		}}, snapshots)
+	})
+
+	t.Run("keeps the order of extensions", func(t *testing.T) {
+		totalCount = ""
+		extensions, total, err := rdClient.ListExtensions(context.Background(), ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, lastQuery)
+		assert.Equal(t, 2, total, "the total should default to the number of items")
+		require.Len(t, extensions, 2)
+		assert.Equal(t, "zeta/ext", extensions[0].ID)
+		assert.Equal(t, "alpha/ext", extensions[1].ID)
+		assert.Equal(t, "2.0", extensions[1].Version)
+		assert.Equal(t, map[string]string{"a": "b"}, extensions[1].Labels)
+	})
+
+	t.Run("rejects an invalid total", func(t *testing.T) {
+		totalCount = "many"
+		_, _, err := rdClient.ListSnapshots(context.Background(), ListOptions{})
+		assert.ErrorContains(t, err, totalCountHeader)
+	})
+
+	t.Run("reports invalid options", func(t *testing.T) {
+		totalCount = ""
+		_, err := rdClient.DiagnosticChecks(context.Background(), DiagnosticsQuery{ListOptions: ListOptions{Sort: "color"}})
+		assert.ErrorContains(t, err, `Invalid sort field "color"`)
+	})
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// sessionCacheFileName is the name of the file, next to the config file, that
+// holds the session tokens created by rdctl, so that later invocations can
+// reuse them instead of sending the user and password to create a new one.
+const sessionCacheFileName = "rdctl-sessions.json"
+
+// sessionCachePath returns the path of the session cache file, or an empty
+// string to only share session tokens within the process (e.g. when the
+// package is used as a library, without the rdctl flags).
+var sessionCachePath = func() string {
+	if config.DefaultConfigPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(config.DefaultConfigPath), sessionCacheFileName)
+}
+
+// sessionStore holds the session tokens of all the clients in the process,
+// keyed by server and user (see RDClientImpl.sessionKey).
+type sessionStore struct {
+	mutex  sync.Mutex
+	tokens map[string]Token
+	loaded bool
+}
+
+var sessions = newSessionStore()
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{tokens: map[string]Token{}}
+}
+
+// get returns the session token for the given key, if there is one that
+// isn't about to expire.
+func (store *sessionStore) get(key string) (Token, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.load()
+	token, ok := store.tokens[key]
+	if !ok || time.Until(token.ExpiresAt) < tokenRefreshMargin {
+		return Token{}, false
+	}
+	return token, true
+}
+
+// put records the session token for the given key.
+func (store *sessionStore) put(key string, token Token) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.load()
+	store.tokens[key] = token
+	store.save()
+}
+
+// forget removes the given session token, which the server no longer accepts.
+func (store *sessionStore) forget(key, token string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.load()
+	if store.tokens[key].Token == token {
+		delete(store.tokens, key)
+		store.save()
+	}
+}
+
+// load reads the cache file, once; it is only an optimization, so errors are
+// logged and otherwise ignored.
+func (store *sessionStore) load() {
+	if store.loaded {
+		return
+	}
+	store.loaded = true
+	path := sessionCachePath()
+	if path == "" {
+		return
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("failed to read session cache: %s", err)
+		}
+		return
+	}
+	var tokens map[string]Token
+	if err := json.Unmarshal(contents, &tokens); err != nil {
+		logrus.Debugf("ignoring invalid session cache %q: %s", path, err)
+		return
+	}
+	for key, token := range tokens {
+		if _, ok := store.tokens[key]; !ok {
+			store.tokens[key] = token
+		}
+	}
+}
+
+// save writes the unexpired session tokens to the cache file, readable only by
+// the current user.
+func (store *sessionStore) save() {
+	path := sessionCachePath()
+	if path == "" {
+		return
+	}
+	for key, token := range store.tokens {
+		if time.Now().After(token.ExpiresAt) {
+			delete(store.tokens, key)
+		}
+	}
+	contents, err := json.Marshal(store.tokens)
+	if err == nil {
+		err = writeFileAtomically(path, contents)
+	}
+	if err != nil {
+		logrus.Debugf("failed to write session cache: %s", err)
+	}
+}
+
+func writeFileAtomically(path string, contents []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), sessionCacheFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	// CreateTemp makes the file readable only by the current user.
+	_, err = file.Write(contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Ptr returns a pointer to the given value, for filling in the optional fields
+// of Settings and TransientSettings.
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+// GetSettings returns the current settings.  Settings the client doesn't know
+// about (e.g. from a newer backend) are dropped.
+func (client *RDClientImpl) GetSettings(ctx context.Context) (*Settings, error) {
+	settings := &Settings{}
+	if err := client.getJSON(ctx, opListSettings, nil, settings); err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateSettings changes the settings that are set in the given value, leaving
+// the others unchanged.  It returns the status message from the backend.
+func (client *RDClientImpl) UpdateSettings(ctx context.Context, settings *Settings) (string, error) {
+	result, err := client.putJSON(ctx, opUpdateSettings, settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to update settings: %w", err)
+	}
+	return string(result), nil
+}
+
+// GetTransientSettings returns the settings that only last until the
+// application exits.
+func (client *RDClientImpl) GetTransientSettings(ctx context.Context) (*TransientSettings, error) {
+	settings := &TransientSettings{}
+	if err := client.getJSON(ctx, opListTransientSettings, nil, settings); err != nil {
+		return nil, fmt.Errorf("failed to get transient settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateTransientSettings changes the transient settings that are set in the
+// given value, leaving the others unchanged.
+func (client *RDClientImpl) UpdateTransientSettings(ctx context.Context, settings *TransientSettings) error {
+	if _, err := client.putJSON(ctx, opUpdateTransientSettings, settings); err != nil {
+		return fmt.Errorf("failed to update transient settings: %w", err)
+	}
+	return nil
+}
+
+// getJSON decodes the response to a request (without a payload) for the given
+// operation.
+func (client *RDClientImpl) getJSON(ctx context.Context, op operation, query url.Values, value any) error {
+	response, err := client.call(ctx, op, query, nil)
+	return decodeResponse(response, err, value)
+}
+
+// decodeResponse decodes the JSON body of a successful response.
+func decodeResponse(response *http.Response, err error, value any) error {
+	body, err := ProcessRequestForUtility(response, err)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, value); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// putJSON sends the value as the payload of a request for the given
+// operation, returning the body of the response.
+func (client *RDClientImpl) putJSON(ctx context.Context, op operation, value any) ([]byte, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return ProcessRequestForUtility(client.call(ctx, op, nil, payload))
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings(t *testing.T) {
+	var received string
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"version":10,"containerEngine":{"name":"moby","unknown":true},"experimental":{"virtualMachine":{"mount":{"9p":{"msizeInKib":128}}}}}`))
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			received = string(body)
+			_, _ = w.Write([]byte("reconfiguring Rancher Desktop to apply changes (this may take a while)"))
+		}
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	t.Run("decodes the current settings", func(t *testing.T) {
+		settings, err := rdClient.GetSettings(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 10, *settings.Version)
+		assert.Equal(t, "moby", *settings.ContainerEngine.Name)
+		assert.Equal(t, 128, *settings.Experimental.VirtualMachine.Mount.NineP.MsizeInKib)
+		assert.Nil(t, settings.Kubernetes)
+	})
+
+	t.Run("only sends the settings to change", func(t *testing.T) {
+		status, err := rdClient.UpdateSettings(context.Background(), &Settings{
+			Version:    Ptr(10),
+			Kubernetes: &SettingsKubernetes{Enabled: Ptr(false)},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"version":10,"kubernetes":{"enabled":false}}`, received)
+		assert.Contains(t, status, "reconfiguring")
+	})
+
+	t.Run("can clear the DNS servers", func(t *testing.T) {
+		_, err := rdClient.SetDNSServers(context.Background(), nil, []string{"corp.example.com=10.0.0.53"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"version":10,"virtualMachine":{"dns":{"upstreamServers":[],"domains":["corp.example.com=10.0.0.53"]}}}`, received)
+	})
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+)
+
+func dialSocket(ctx context.Context, socket string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", socket)
+}
+
+// ListenSocket listens on a unix socket, replacing any stale socket left
+// behind by a previous run.
+func ListenSocket(socket string) (net.Listener, error) {
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// dialSocket connects to a named pipe, or to a Unix domain socket (which
+// Windows also supports) for any other path.
+func dialSocket(ctx context.Context, socket string) (net.Conn, error) {
+	if strings.HasPrefix(socket, `\\.\pipe\`) {
+		return winio.DialPipeContext(ctx, socket)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", socket)
+}
+
+// ListenSocket listens on a named pipe, or on a Unix domain socket for any
+// other path.
+func ListenSocket(socket string) (net.Listener, error) {
+	if strings.HasPrefix(socket, `\\.\pipe\`) {
+		return winio.ListenPipe(socket, nil)
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Scopes of API tokens: read tokens may only be used for GET requests.
+const (
+	TokenScopeRead = "read"
+	TokenScopeFull = "full"
+)
+
+const (
+	// sessionTokenTTL is the lifetime of the tokens the client creates for
+	// its own requests.
+	sessionTokenTTL = 15 * time.Minute
+	// tokenRefreshMargin is how long before its expiry a session token is
+	// replaced, to allow for clock skew and slow requests.
+	tokenRefreshMargin = time.Minute
+)
+
+// ErrTokensUnsupported is returned when the server doesn't issue API tokens.
+var ErrTokensUnsupported = errors.New("the server does not support API tokens")
+
+// Token is a short-lived bearer token for the API.
+type Token struct {
+	Token     string    `json:"token"`
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateToken asks the server for a new token with the given scope and
+// lifetime.  This requires the user and password; a token can't be used to
+// create other tokens.
+func (client *RDClientImpl) CreateToken(ctx context.Context, scope string, ttl time.Duration) (Token, error) {
+	if ttl < time.Second {
+		return Token{}, fmt.Errorf("invalid token lifetime %s: must be at least one second", ttl)
+	}
+	if client.connectionInfo.Socket == "" && (client.connectionInfo.User == "" || client.connectionInfo.Password == "") {
+		return Token{}, errors.New("creating a token requires the API user and password, or the API socket")
+	}
+	body, err := json.Marshal(map[string]interface{}{"scope": scope, "ttl": int(ttl.Seconds())})
+	if err != nil {
+		return Token{}, err
+	}
+	authorization := client.basicAuth
+	if client.connectionInfo.Socket != "" {
+		authorization = noAuth
+	}
+	response, err := client.send(ctx, opCreateToken.method, VersionCommand("", opCreateToken.path), "application/json", body, authorization)
+	if err == nil && response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return Token{}, ErrTokensUnsupported
+	}
+	result, err := ProcessRequestForUtility(response, err)
+	if err != nil {
+		return Token{}, err
+	}
+	var token Token
+	if err := json.Unmarshal(result, &token); err != nil {
+		return Token{}, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	if token.Token == "" {
+		return Token{}, errors.New("the server did not return a token")
+	}
+	return token, nil
+}
+
+func (client *RDClientImpl) basicAuth(req *http.Request) {
+	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
+}
+
+func noAuth(*http.Request) {}
+
+// authorization returns the function that adds credentials to a request for
+// the given command.  A token given in the connection info is used as is;
+// otherwise the client uses the user and password to create a session token,
+// which is shared with other clients (and later invocations; see
+// sessionStore) until shortly before it expires.  The user and password are
+// only sent to create tokens, or to servers too old to issue them.
+// Connections through a socket don't need credentials.
+func (client *RDClientImpl) authorization(ctx context.Context, command string) (func(*http.Request), error) {
+	if token := client.connectionInfo.Token; token != "" {
+		return bearerAuth(token), nil
+	}
+	if client.connectionInfo.Socket != "" {
+		return noAuth, nil
+	}
+	if client.tokensUnsupported || isTokenCommand(command) {
+		return client.basicAuth, nil
+	}
+	if client.session.Token == "" || time.Until(client.session.ExpiresAt) < tokenRefreshMargin {
+		token, ok := sessions.get(client.sessionKey())
+		if !ok {
+			var err error
+			token, err = client.CreateToken(ctx, TokenScopeFull, sessionTokenTTL)
+			if errors.Is(err, ErrTokensUnsupported) {
+				client.tokensUnsupported = true
+				return client.basicAuth, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to create API token: %w", err)
+			}
+			sessions.put(client.sessionKey(), token)
+		}
+		client.session = token
+	}
+	return bearerAuth(client.session.Token), nil
+}
+
+// sessionKey identifies the server and user a session token belongs to.
+func (client *RDClientImpl) sessionKey() string {
+	return client.makeURL(client.connectionInfo.Host, client.connectionInfo.Port, "") + " " + client.connectionInfo.User
+}
+
+// forgetSession drops the session token, which the server no longer accepts
+// (e.g. because the backend restarted), so that a new one is created.
+func (client *RDClientImpl) forgetSession() {
+	sessions.forget(client.sessionKey(), client.session.Token)
+	client.session = Token{}
+}
+
+func bearerAuth(token string) func(*http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// isTokenCommand checks whether the command is a request to create a token.
+func isTokenCommand(command string) bool {
+	command = strings.TrimSuffix(strings.SplitN(command, "?", 2)[0], "/")
+	return strings.HasSuffix(command, "/tokens") || command == "tokens"
+}
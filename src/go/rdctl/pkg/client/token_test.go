@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer is a fake API server that issues tokens.
+type tokenServer struct {
+	supported bool
+	tokens    map[string]bool
+	created   int
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, password, hasBasicAuth := r.BasicAuth()
+	validBasicAuth := hasBasicAuth && user == "user" && password == "password"
+	if r.URL.Path == "/v1/tokens" {
+		if !s.supported {
+			http.NotFound(w, r)
+			return
+		}
+		if !validBasicAuth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.created++
+		token := Token{Token: fmt.Sprintf("token-%d", s.created), Scope: TokenScopeFull, ExpiresAt: time.Now().Add(time.Hour)}
+		s.tokens[token.Token] = true
+		_ = json.NewEncoder(w).Encode(token)
+		return
+	}
+	authorized := validBasicAuth && !s.supported
+	var token string
+	if _, err := fmt.Sscanf(r.Header.Get("Authorization"), "Bearer %s", &token); err == nil {
+		authorized = s.tokens[token]
+	}
+	if !authorized {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+func newTokenServer(t *testing.T, supported bool) (*tokenServer, *config.ConnectionInfo) {
+	t.Helper()
+	handler := &tokenServer{supported: supported, tokens: map[string]bool{}}
+	server := httptest.NewServer(withVersions(handler, ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return handler, &config.ConnectionInfo{User: "user", Password: "password", Host: serverURL.Hostname(), Port: port}
+}
+
+// useSessionStore gives the test its own session tokens, kept in the given
+// file (or only in memory, if empty), as if in a new process.
+func useSessionStore(t *testing.T, path string) {
+	t.Helper()
+	savedSessions, savedPath := sessions, sessionCachePath
+	t.Cleanup(func() { sessions, sessionCachePath = savedSessions, savedPath })
+	sessions = newSessionStore()
+	sessionCachePath = func() string { return path }
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	useSessionStore(t, "")
+
+	t.Run("reuses a session token", func(t *testing.T) {
+		server, connectionInfo := newTokenServer(t, true)
+		rdClient := NewRDClient(connectionInfo)
+		for i := 0; i < 3; i++ {
+			body, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+			require.NoError(t, err)
+			assert.Equal(t, "ok", string(body))
+		}
+		assert.Equal(t, 1, server.created)
+	})
+
+	t.Run("replaces an expiring session token", func(t *testing.T) {
+		server, connectionInfo := newTokenServer(t, true)
+		rdClient := NewRDClient(connectionInfo)
+		_, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		rdClient.session.ExpiresAt = time.Now().Add(tokenRefreshMargin / 2)
+		sessions.tokens[rdClient.sessionKey()] = rdClient.session
+		_, err = ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, server.created)
+	})
+
+	t.Run("replaces a session token the server has forgotten", func(t *testing.T) {
+		server, connectionInfo := newTokenServer(t, true)
+		rdClient := NewRDClient(connectionInfo)
+		_, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		server.tokens = map[string]bool{}
+		body, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, 2, server.created)
+	})
+
+	t.Run("shares the session token between clients", func(t *testing.T) {
+		server, connectionInfo := newTokenServer(t, true)
+		for i := 0; i < 3; i++ {
+			_, err := ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, server.created)
+	})
+
+	t.Run("keeps the session token for later invocations", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), sessionCacheFileName)
+		useSessionStore(t, cachePath)
+		server, connectionInfo := newTokenServer(t, true)
+		_, err := ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(cachePath)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+
+		useSessionStore(t, cachePath)
+		_, err = ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, server.created)
+
+		// A token the server has forgotten is replaced in the file too.
+		server.tokens = map[string]bool{}
+		useSessionStore(t, cachePath)
+		_, err = ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		useSessionStore(t, cachePath)
+		_, err = ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, server.created)
+	})
+
+	t.Run("falls back to basic auth for older servers", func(t *testing.T) {
+		_, connectionInfo := newTokenServer(t, false)
+		rdClient := NewRDClient(connectionInfo)
+		body, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.True(t, rdClient.tokensUnsupported)
+	})
+
+	t.Run("uses a configured token as is", func(t *testing.T) {
+		server, connectionInfo := newTokenServer(t, true)
+		server.tokens["configured"] = true
+		connectionInfo.User = ""
+		connectionInfo.Password = ""
+		connectionInfo.Token = "configured"
+		body, err := ProcessRequestForUtility(NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Zero(t, server.created)
+	})
+
+	t.Run("creating a token requires the user and password", func(t *testing.T) {
+		_, connectionInfo := newTokenServer(t, true)
+		connectionInfo.Password = ""
+		connectionInfo.Token = "configured"
+		_, err := NewRDClient(connectionInfo).CreateToken(context.Background(), TokenScopeRead, time.Hour)
+		assert.ErrorContains(t, err, "requires the API user and password, or the API socket")
+	})
+}
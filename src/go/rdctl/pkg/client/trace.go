@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxTracedBodySize is the longest body that is logged when tracing; longer
+// bodies are truncated.
+const maxTracedBodySize = 4096
+
+// redacted replaces the values of sensitive fields in traced bodies.
+const redacted = "REDACTED"
+
+// sensitiveFieldNames are the (case-insensitive) substrings of JSON field names
+// whose values are never logged.  These err on the side of hiding too much
+// (e.g. "key" covers apiKey, privateKey and accessKey, but also keyboard).
+var sensitiveFieldNames = []string{
+	"password", "passwd", "passphrase", "secret", "token", "credential", "auth", "key", "cookie", "session",
+}
+
+// traceRequest logs a request about to be sent, if debug logging is enabled.
+// The headers are never logged, as they hold the credentials.
+func traceRequest(req *http.Request, body []byte) {
+	if !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"method": req.Method,
+		"path":   req.URL.RequestURI(),
+		"body":   redactBody(body),
+	}).Debug("API request")
+}
+
+// traceResponse logs the response to a request, if debug logging is enabled.
+// The response body is read in full so that it can be logged, and replaced
+// with a copy for the caller; event streams and upgraded connections are left
+// alone as they never end.
+func traceResponse(req *http.Request, response *http.Response, err error, start time.Time) {
+	if !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	fields := logrus.Fields{
+		"method":  req.Method,
+		"path":    req.URL.RequestURI(),
+		"latency": time.Since(start).Round(time.Microsecond).String(),
+	}
+	if err != nil {
+		logrus.WithFields(fields).WithError(err).Debug("API request failed")
+		return
+	}
+	fields["status"] = response.StatusCode
+	if strings.HasPrefix(response.Header.Get("Content-Type"), "text/event-stream") {
+		logrus.WithFields(fields).Debug("API response (event stream)")
+		return
+	}
+	if response.StatusCode == http.StatusSwitchingProtocols {
+		logrus.WithFields(fields).Debug("API response (switching protocols)")
+		return
+	}
+	body, readErr := io.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{readErr}))
+	fields["body"] = redactBody(body)
+	logrus.WithFields(fields).Debug("API response")
+}
+
+// errorReader returns the error (if any) hit while reading a traced response
+// body, so that the caller still sees it.
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// redactBody returns a request or response body suitable for logging: the
+// values of sensitive fields of JSON bodies are replaced, and long bodies are
+// truncated.  Other bodies are omitted, as there is no telling what they
+// hold.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("(%d bytes, not JSON)", len(body))
+	}
+	redactedBody, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Sprintf("(%d bytes, not JSON)", len(body))
+	}
+	body = redactedBody
+	if len(body) > maxTracedBodySize {
+		return fmt.Sprintf("%s... (%d bytes)", body[:maxTracedBodySize], len(body))
+	}
+	return string(body)
+}
+
+// redactValue replaces the values of sensitive fields in a decoded JSON value.
+func redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if isSensitiveField(key) {
+				value[key] = redacted
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveFieldNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactBody(t *testing.T) {
+	testCases := map[string]struct {
+		body     string
+		expected string
+	}{
+		"empty":             {"", ""},
+		"plain text":        {"password=pw", "(11 bytes, not JSON)"},
+		"no secrets":        {`{"name":"value"}`, `{"name":"value"}`},
+		"top-level secrets": {`{"token":"abc","expiresAt":"soon","Password":"pw"}`, `{"Password":"REDACTED","expiresAt":"soon","token":"REDACTED"}`},
+		"nested secrets":    {`{"proxy":{"user":"u","password":"pw"},"list":[{"clientSecret":"s"}]}`, `{"list":[{"clientSecret":"REDACTED"}],"proxy":{"password":"REDACTED","user":"u"}}`},
+		"keys":              {`{"apiKey":"k","passphrase":"p","name":"n"}`, `{"apiKey":"REDACTED","name":"n","passphrase":"REDACTED"}`},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, redactBody([]byte(testCase.body)))
+		})
+	}
+
+	t.Run("truncates long bodies", func(t *testing.T) {
+		body := `"` + strings.Repeat("x", maxTracedBodySize) + `"`
+		assert.Equal(t, body[:maxTracedBodySize]+"... (4098 bytes)", redactBody([]byte(body)))
+	})
+}
+
+func TestTracing(t *testing.T) {
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"secret-token"}`))
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	hook := test.NewGlobal()
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() {
+		logrus.SetLevel(level)
+		logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	})
+
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "configured-token"})
+	body, err := ProcessRequestForUtility(rdClient.DoRequestWithPayload(context.Background(), http.MethodPost, "/v1/tokens", strings.NewReader(`{"password":"pw"}`)))
+	require.NoError(t, err)
+	assert.Equal(t, `{"token":"secret-token"}`, string(body), "the caller should still get the whole body")
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		line, err := entry.String()
+		require.NoError(t, err)
+		messages = append(messages, line)
+		assert.NotContains(t, line, "secret-token")
+		assert.NotContains(t, line, "configured-token")
+		assert.NotContains(t, line, `\"pw\"`)
+	}
+	assert.Contains(t, strings.Join(messages, ""), "path=/v1/tokens")
+	assert.Contains(t, strings.Join(messages, ""), "status=200")
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits for the connections to the backend.  The backend is local, so a few
+// idle connections are plenty; they are dropped before the backend's
+// keep-alive timeout (the Node.js default of 5 seconds), so that requests are
+// not sent on connections the backend is about to close.
+const (
+	maxIdleConnsPerHost = 4
+	maxConnsPerHost     = 16
+	idleConnTimeout     = 4 * time.Second
+)
+
+// transportKey identifies the settings that need a separate transport.
+type transportKey struct {
+	socket string
+	// certificate is the pinned certificate of the server (DER), or empty
+	// when not using TLS.
+	certificate string
+}
+
+var (
+	httpClientsMutex sync.Mutex
+	httpClients      = map[transportKey]*http.Client{}
+)
+
+// sharedHTTPClient returns the client for the given settings, creating it if
+// needed.  Clients are kept for the life of the process, so that tools
+// sending many requests (or creating many RDClients) reuse their connections.
+func sharedHTTPClient(key transportKey) *http.Client {
+	httpClientsMutex.Lock()
+	defer httpClientsMutex.Unlock()
+	if httpClient, ok := httpClients[key]; ok {
+		return httpClient
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		MaxConnsPerHost:     maxConnsPerHost,
+		IdleConnTimeout:     idleConnTimeout,
+	}
+	if key.socket != "" {
+		socket := key.socket
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialSocket(ctx, socket)
+		}
+	} else {
+		transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if key.certificate != "" {
+		transport.TLSClientConfig = pinnedTLSConfig([]byte(key.certificate))
+	}
+	httpClient := &http.Client{Transport: transport}
+	httpClients[key] = httpClient
+	return httpClient
+}
+
+// CloseIdleConnections closes the idle connections to the backend, e.g.
+// before a long pause in a program that keeps running.
+func CloseIdleConnections() {
+	httpClientsMutex.Lock()
+	defer httpClientsMutex.Unlock()
+	for _, httpClient := range httpClients {
+		httpClient.CloseIdleConnections()
+	}
+}
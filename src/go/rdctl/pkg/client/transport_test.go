@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionReuse(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}), ApiVersion))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	connectionInfo := &config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"}
+
+	for i := 0; i < 2; i++ {
+		rdClient := NewRDClient(connectionInfo)
+		for j := 0; j < 3; j++ {
+			_, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodPut, "/v1/shutdown"))
+			require.NoError(t, err)
+		}
+	}
+	assert.Equal(t, int32(1), connections.Load(), "sequential requests should share a connection")
+}
@@ -57,7 +57,9 @@ func ProcessRequestForUtility(response *http.Response, err error) ([]byte, error
 			// Prefer the error message in the body written by the command-server, not the one from the http server.
 			break
 		case 401:
-			return nil, fmt.Errorf("%s: user/password not accepted", response.Status)
+			return nil, fmt.Errorf("%s: user/password or token not accepted", response.Status)
+		case 403:
+			return nil, fmt.Errorf("%s: the API token does not allow this request", response.Status)
 		case 413:
 			return nil, fmt.Errorf("%s", response.Status)
 		case 500:
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SupportedApiVersions lists the API versions this client can use, in order
+// of preference.
+var SupportedApiVersions = []string{ApiVersion}
+
+// legacyApiVersions are the versions supported by backends that predate
+// version discovery.
+var legacyApiVersions = []string{"v1"}
+
+// ErrApiVersionMismatch is returned when the client and the backend have no
+// API version in common, typically because one of them has been upgraded
+// without the other.
+var ErrApiVersionMismatch = errors.New("rdctl and the Rancher Desktop backend have no API version in common")
+
+// APIVersion returns the API version to use for this session, asking the
+// backend which versions it supports the first time it is called.
+func (client *RDClientImpl) APIVersion(ctx context.Context) (string, error) {
+	if err := client.negotiateVersion(ctx); err != nil {
+		return "", err
+	}
+	if client.apiVersion == "" {
+		return ApiVersion, nil
+	}
+	return client.apiVersion, nil
+}
+
+// negotiateVersion picks the API version to use for the rest of the session.
+// Any failure to talk to the backend is ignored here, so that it is reported
+// by the actual request instead; the negotiation is then retried with the
+// next request.
+func (client *RDClientImpl) negotiateVersion(ctx context.Context) error {
+	if client.apiVersion != "" {
+		return nil
+	}
+	authorization, err := client.authorization(ctx, "/versions")
+	if err != nil {
+		return nil
+	}
+	response, err := client.send(ctx, http.MethodGet, "/versions", "text/plain", nil, authorization)
+	if err != nil {
+		return nil
+	}
+	var serverVersions struct {
+		Versions []string `json:"versions"`
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		body, err := ProcessRequestForUtility(response, nil)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &serverVersions); err != nil {
+			return fmt.Errorf("failed to unmarshal API versions: %w", err)
+		}
+	case http.StatusNotFound:
+		response.Body.Close()
+		serverVersions.Versions = legacyApiVersions
+	default:
+		response.Body.Close()
+		return nil
+	}
+	for _, version := range SupportedApiVersions {
+		if slices.Contains(serverVersions.Versions, version) {
+			client.apiVersion = version
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: rdctl %s supports API versions %s, but the backend supports %s; use the rdctl that was installed with the running application",
+		ErrApiVersionMismatch, Version, strings.Join(SupportedApiVersions, ", "), strings.Join(serverVersions.Versions, ", "))
+}
+
+// versionCommand is like VersionCommand, using the API version negotiated
+// with the backend.
+func (client *RDClientImpl) versionCommand(ctx context.Context, command string) (string, error) {
+	version, err := client.APIVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return VersionCommand(version, command), nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVersionServer(t *testing.T, handler http.Handler) (*config.ConnectionInfo, *int) {
+	t.Helper()
+	discoveries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions" {
+			discoveries++
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return &config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"}, &discoveries
+}
+
+func TestAPIVersion(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	t.Run("pins a common version", func(t *testing.T) {
+		connectionInfo, discoveries := newVersionServer(t, withVersions(ok, "v0", ApiVersion, "v99"))
+		rdClient := NewRDClient(connectionInfo)
+		for i := 0; i < 2; i++ {
+			_, err := ProcessRequestForUtility(rdClient.DoRequest(context.Background(), http.MethodGet, "/v1/about"))
+			require.NoError(t, err)
+		}
+		version, err := rdClient.APIVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, ApiVersion, version)
+		assert.Equal(t, 1, *discoveries)
+	})
+
+	t.Run("assumes v1 for backends without version discovery", func(t *testing.T) {
+		connectionInfo, _ := newVersionServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/versions" {
+				http.NotFound(w, r)
+				return
+			}
+			ok(w, r)
+		}))
+		version, err := NewRDClient(connectionInfo).APIVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v1", version)
+	})
+
+	t.Run("reports a mismatch", func(t *testing.T) {
+		connectionInfo, _ := newVersionServer(t, withVersions(ok, "v99"))
+		_, err := NewRDClient(connectionInfo).DoRequest(context.Background(), http.MethodGet, "/v99/about")
+		assert.ErrorIs(t, err, ErrApiVersionMismatch)
+		assert.ErrorContains(t, err, "the backend supports v99")
+	})
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrVMPauseUnsupported is returned by PauseVM when the backend can't pause
+// the VM.
+var ErrVMPauseUnsupported = errors.New("pausing the VM is not supported")
+
+// vmStatePollInterval is how often WaitForVMState checks the backend state.
+var vmStatePollInterval = 500 * time.Millisecond
+
+// StartVM starts the VM, returning the message from the backend.  It returns
+// once the VM is starting; use WaitForVMState to wait for it to be running.
+func (client *RDClientImpl) StartVM(ctx context.Context) (string, error) {
+	return client.changeVMState(ctx, opStartVM, "start")
+}
+
+// StopVM stops the VM, leaving the application running.
+func (client *RDClientImpl) StopVM(ctx context.Context) (string, error) {
+	return client.changeVMState(ctx, opStopVM, "stop")
+}
+
+// RestartVM stops the VM (if it is running) and starts it again.
+func (client *RDClientImpl) RestartVM(ctx context.Context) (string, error) {
+	return client.changeVMState(ctx, opRestartVM, "restart")
+}
+
+// PauseVM suspends the VM without shutting it down; ErrVMPauseUnsupported is
+// returned if the backend can't do that.
+func (client *RDClientImpl) PauseVM(ctx context.Context) (string, error) {
+	return client.changeVMState(ctx, opPauseVM, "pause")
+}
+
+func (client *RDClientImpl) changeVMState(ctx context.Context, op operation, action string) (string, error) {
+	response, err := client.call(ctx, op, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to %s the VM: %w", action, handleConnectionRefused(err))
+	}
+	switch response.StatusCode {
+	case http.StatusConflict, http.StatusNotImplemented:
+		// The body explains why the action can't be done.
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		message := strings.TrimSpace(string(body))
+		if response.StatusCode == http.StatusNotImplemented {
+			return "", fmt.Errorf("%w: %s", ErrVMPauseUnsupported, message)
+		}
+		return "", fmt.Errorf("failed to %s the VM: %s", action, message)
+	}
+	body, err := ProcessRequestForUtility(response, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to %s the VM: %w", action, err)
+	}
+	return string(body), nil
+}
+
+// WaitForVMState polls the backend state until the VM reaches one of the
+// given states (e.g. "STARTED"), failing if it ends up in the ERROR state
+// instead.
+func (client *RDClientImpl) WaitForVMState(ctx context.Context, states ...string) error {
+	for {
+		state, err := client.GetBackendState(ctx)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(states, state.VMState) {
+			return nil
+		}
+		if state.VMState == "ERROR" {
+			return errors.New("the VM is in an error state; please consult the application logs")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(vmStatePollInterval):
+		}
+	}
+}
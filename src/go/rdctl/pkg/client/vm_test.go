@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMLifecycle(t *testing.T) {
+	pollInterval := vmStatePollInterval
+	vmStatePollInterval = time.Millisecond
+	t.Cleanup(func() { vmStatePollInterval = pollInterval })
+
+	var lastPath string
+	states := []string{"STARTED"}
+	server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/backend_state":
+			state := states[0]
+			if len(states) > 1 {
+				states = states[1:]
+			}
+			_, _ = w.Write([]byte(`{"vmState": "` + state + `", "locked": false}`))
+		case r.Method != http.MethodPost:
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+		case r.URL.Path == "/v1/vm/pause":
+			http.Error(w, "Pausing the VM is not supported by the wsl backend", http.StatusNotImplemented)
+		case r.URL.Path == "/v1/vm/stop":
+			http.Error(w, "Cannot stop the VM while it is STARTING", http.StatusConflict)
+		default:
+			lastPath = r.URL.Path
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("Restarting the VM"))
+		}
+	}), ApiVersion))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	rdClient := NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"})
+
+	t.Run("requests the action", func(t *testing.T) {
+		message, err := rdClient.RestartVM(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/vm/restart", lastPath)
+		assert.Equal(t, "Restarting the VM", message)
+	})
+
+	t.Run("reports why the action can't be done", func(t *testing.T) {
+		_, err := rdClient.StopVM(context.Background())
+		assert.ErrorContains(t, err, "Cannot stop the VM while it is STARTING")
+		_, err = rdClient.PauseVM(context.Background())
+		assert.ErrorIs(t, err, ErrVMPauseUnsupported)
+	})
+
+	t.Run("waits for the VM state", func(t *testing.T) {
+		states = []string{"STOPPING", "STARTING", "STARTING", "STARTED"}
+		require.NoError(t, rdClient.WaitForVMState(context.Background(), "STARTED", "DISABLED"))
+		assert.Equal(t, []string{"STARTED"}, states)
+
+		states = []string{"STARTING", "ERROR"}
+		assert.ErrorContains(t, rdClient.WaitForVMState(context.Background(), "STARTED"), "error state")
+	})
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/retry"
+)
+
+// ErrBackendStarting is returned when the backend can't serve a request yet,
+// because it is starting up (or being restarted by a snapshot operation), and
+// the connection info doesn't ask to wait for it.
+var ErrBackendStarting = errors.New("the backend is starting")
+
+// BackendLocked reports whether another process holds the backend lock (e.g.
+// while restoring a snapshot), in which case a refused connection means the
+// backend will be back shortly.  rdctl sets this; if nil, refused connections
+// are never treated as the backend starting.
+var BackendLocked func() bool
+
+// How long to wait before retrying a request while the backend is starting,
+// when the server doesn't say; the delay doubles on each attempt, up to the
+// maximum.
+var (
+	waitInitialDelay = 250 * time.Millisecond
+	waitMaxDelay     = 5 * time.Second
+)
+
+// waitPolicy retries requests until the backend is ready, or the context is
+// done.
+func waitPolicy() retry.Policy {
+	return retry.Policy{
+		InitialDelay: waitInitialDelay,
+		MaxDelay:     waitMaxDelay,
+		Jitter:       0.2,
+	}
+}
+
+// backendStarting checks whether the outcome of a request means the backend is
+// starting: either a 503 response with a Retry-After header, or a refused
+// connection while the (local) backend is locked.  It also returns the delay
+// requested by the server, if any.
+func (client *RDClientImpl) backendStarting(response *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		if client.connectionInfo.Server != "" || BackendLocked == nil {
+			return 0, false
+		}
+		return 0, errors.Is(handleConnectionRefused(err), ErrConnectionRefused) && BackendLocked()
+	}
+	if response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	retryAfter := response.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(retryAfter)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+	return min(time.Duration(seconds)*time.Second, waitMaxDelay), true
+}
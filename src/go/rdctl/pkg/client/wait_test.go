@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForBackend(t *testing.T) {
+	initialDelay := waitInitialDelay
+	waitInitialDelay = time.Millisecond
+	t.Cleanup(func() { waitInitialDelay = initialDelay })
+
+	// startingServer responds with 503 to the given number of requests before
+	// succeeding.
+	startingServer := func(t *testing.T, failures int) (*RDClientImpl, *int) {
+		requests := 0
+		server := httptest.NewServer(withVersions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= failures {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"vmState": "STARTED", "locked": false}`))
+		}), ApiVersion))
+		t.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		port, err := strconv.Atoi(serverURL.Port())
+		require.NoError(t, err)
+		return NewRDClient(&config.ConnectionInfo{Host: serverURL.Hostname(), Port: port, Token: "token"}), &requests
+	}
+
+	t.Run("reports that the backend is starting", func(t *testing.T) {
+		rdClient, _ := startingServer(t, 1)
+		_, err := rdClient.GetBackendState(context.Background())
+		assert.ErrorIs(t, err, ErrBackendStarting)
+	})
+
+	t.Run("waits until the backend is ready", func(t *testing.T) {
+		rdClient, requests := startingServer(t, 3)
+		rdClient.connectionInfo.WaitForBackend = true
+		state, err := rdClient.GetBackendState(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "STARTED", state.VMState)
+		assert.Equal(t, 4, *requests)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		rdClient, _ := startingServer(t, 1000)
+		rdClient.connectionInfo.WaitForBackend = true
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := rdClient.GetBackendState(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("only treats refused connections as starting while the backend is locked", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+		rdClient := NewRDClient(&config.ConnectionInfo{Host: "127.0.0.1", Port: port, Token: "token"})
+		rdClient.apiVersion = ApiVersion
+
+		t.Cleanup(func() { BackendLocked = nil })
+		BackendLocked = func() bool { return false }
+		_, err = rdClient.GetBackendState(context.Background())
+		assert.ErrorIs(t, err, ErrConnectionRefused)
+		assert.False(t, errors.Is(err, ErrBackendStarting))
+
+		BackendLocked = func() bool { return true }
+		_, err = rdClient.GetBackendState(context.Background())
+		assert.ErrorIs(t, err, ErrConnectionRefused)
+		assert.ErrorIs(t, err, ErrBackendStarting)
+	})
+}
@@ -19,17 +19,22 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"net"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -39,38 +44,85 @@ type ConnectionInfo struct {
 	Password string
 	Host     string
 	Port     int
+	// Socket is the path of a Unix domain socket (or a named pipe on Windows)
+	// to connect to instead of Host and Port.  The backend serves plain HTTP
+	// without authentication on it, relying on the socket permissions.
+	Socket string
+	// Certificate is the path of the (self-signed) certificate of the server;
+	// if set, the connection uses TLS and the server must present exactly
+	// this certificate.
+	Certificate string
+	// Token is a bearer token (see `rdctl token create`) to use instead of
+	// User and Password.
+	Token string
+	// Server is the base URL of the API of a Rancher Desktop instance on
+	// another machine (e.g. through an SSH tunnel), in which case Host, Port
+	// and Socket are not used.  An https URL is verified against Certificate
+	// if set, and the system trust store otherwise.
+	Server string
+	// WaitForBackend makes requests wait (with backoff) while the backend is
+	// starting, instead of failing.
+	WaitForBackend bool
 }
 
+// certificateFileName is the name of the file, next to the config file, that
+// holds the certificate of the server.
+const certificateFileName = "rd-engine.crt"
+
+// Environment variables that override the settings in the config file; any
+// command-line options take precedence over these.
+const (
+	hostEnvVar     = "RD_API_HOST"
+	portEnvVar     = "RD_API_PORT"
+	userEnvVar     = "RD_API_USER"
+	passwordEnvVar = "RD_API_PASSWORD"
+	socketEnvVar   = "RD_API_SOCKET"
+	certEnvVar     = "RD_API_CERTIFICATE"
+	tokenEnvVar    = "RD_API_TOKEN"
+	serverEnvVar   = "RD_API_SERVER"
+)
+
 var (
+	// flagSettings holds the connection settings given on the command line;
+	// these take precedence over the environment and the config file.
+	flagSettings       ConnectionInfo
 	connectionSettings ConnectionInfo
 
 	configPath string
+	// allowInsecureServer permits a plain http --server URL for a host other
+	// than this machine, which sends the credentials unencrypted.
+	allowInsecureServer bool
 	// DefaultConfigPath - used to differentiate not being able to find a user-specified config file from the default
 	DefaultConfigPath string
+
+	// The config file the connection settings were last loaded from, and its
+	// modification time at that point; used to notice when the backend rewrites it.
+	loadedConfigPath    string
+	loadedConfigModTime time.Time
+
+	// How often, and how long to wait between attempts, to re-read a config file
+	// that appears to be in the middle of being rewritten.
+	readConfigRetryCount = 5
+	readConfigRetryWait  = 100 * time.Millisecond
 )
 
 // DefineGlobalFlags sets up the global flags, available for all sub-commands
 func DefineGlobalFlags(rootCmd *cobra.Command) {
-	var configDir string
 	var err error
-	if runtime.GOOS == "linux" && isWSLDistro() {
-		if configDir, err = wslifyConfigDir(); err != nil {
-			log.Fatalf("Can't get WSL config-dir: %v", err)
-		}
-		configDir = filepath.Join(configDir, "rancher-desktop")
-	} else {
-		appPaths, err := paths.GetPaths()
-		if err != nil {
-			log.Fatalf("failed to get paths: %s", err)
-		}
-		configDir = appPaths.AppHome
+	if DefaultConfigPath, err = getDefaultConfigPath(); err != nil {
+		logrus.Fatal(err)
 	}
-	DefaultConfigPath = filepath.Join(configDir, "rd-engine.json")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config-path", "", fmt.Sprintf("config file (default %s)", DefaultConfigPath))
-	rootCmd.PersistentFlags().StringVar(&connectionSettings.User, "user", "", "overrides the user setting in the config file")
-	rootCmd.PersistentFlags().StringVar(&connectionSettings.Host, "host", "", "default is 127.0.0.1; most useful for WSL")
-	rootCmd.PersistentFlags().IntVar(&connectionSettings.Port, "port", 0, "overrides the port setting in the config file")
-	rootCmd.PersistentFlags().StringVar(&connectionSettings.Password, "password", "", "overrides the password setting in the config file")
+	rootCmd.PersistentFlags().StringVar(&flagSettings.User, "user", "", fmt.Sprintf("overrides the user setting in the config file and $%s", userEnvVar))
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Host, "host", "", fmt.Sprintf("overrides the host setting in the config file and $%s; default is 127.0.0.1; most useful for WSL", hostEnvVar))
+	rootCmd.PersistentFlags().IntVar(&flagSettings.Port, "port", 0, fmt.Sprintf("overrides the port setting in the config file and $%s", portEnvVar))
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Password, "password", "", fmt.Sprintf("overrides the password setting in the config file and $%s", passwordEnvVar))
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Token, "token", "", fmt.Sprintf("API token to use instead of the user and password; overrides $%s", tokenEnvVar))
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Certificate, "certificate", "", fmt.Sprintf("certificate the server must present; overrides the certificate setting in the config file and $%s (default %s next to the config file)", certEnvVar, certificateFileName))
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Server, "server", "", fmt.Sprintf("base URL (https://host:port) of a Rancher Desktop instance on another machine, instead of the local one; overrides $%s", serverEnvVar))
+	rootCmd.PersistentFlags().BoolVar(&allowInsecureServer, "insecure", false, "allow a plain http --server URL for a host other than this machine; credentials are sent unencrypted")
+	rootCmd.PersistentFlags().BoolVar(&flagSettings.WaitForBackend, "wait-for-backend", false, "if the backend is starting, wait until it is ready instead of failing")
+	rootCmd.PersistentFlags().StringVar(&flagSettings.Socket, "socket", "", fmt.Sprintf("connect through this Unix domain socket instead of the host and port; overrides the socket setting in the config file and $%s", socketEnvVar))
 }
 
 // GetConnectionInfo returns the connection details of the application API server.
@@ -78,22 +130,41 @@ func DefineGlobalFlags(rootCmd *cobra.Command) {
 // when the config file has not been specified explicitly, the default config file
 // does not exist, and the mayBeMissing parameter is true.
 func GetConnectionInfo(mayBeMissing bool) (*ConnectionInfo, error) {
-	var settings ConnectionInfo
-
-	if configPath == "" {
+	server := flagSettings.Server
+	if server == "" && flagSettings.Host == "" && flagSettings.Port == 0 && flagSettings.Socket == "" {
+		// The environment only selects a remote server if the command line
+		// doesn't pick a local one.
+		server = os.Getenv(serverEnvVar)
+	}
+	if server != "" {
+		return getRemoteConnectionInfo(server)
+	}
+	explicitConfigPath := configPath
+	if explicitConfigPath == "" || explicitConfigPath == DefaultConfigPath {
+		// Recalculate the default, as the instance may have been selected
+		// (via `--instance`) after the flags were defined, and the most
+		// recently written candidate may have changed since.
+		var err error
+		if DefaultConfigPath, err = getDefaultConfigPath(); err != nil {
+			return nil, err
+		}
+		explicitConfigPath = ""
 		configPath = DefaultConfigPath
 	}
-	content, readFileError := os.ReadFile(configPath)
+	settings, modTime, readFileError := readConfigFile(configPath)
+	fileSocket := settings.Socket
 	if readFileError != nil {
 		// It is ok if the default config path doesn't exist; the user may have specified the required settings on the commandline.
 		// But it is an error if the file specified via --config-path can not be read.
-		if configPath != DefaultConfigPath || !errors.Is(readFileError, os.ErrNotExist) {
+		if explicitConfigPath != "" || !errors.Is(readFileError, os.ErrNotExist) {
 			return nil, readFileError
 		}
-	} else if err := json.Unmarshal(content, &settings); err != nil {
-		return nil, fmt.Errorf("error parsing config file %q: %w", configPath, err)
+	}
+	if err := applyEnvironment(&settings); err != nil {
+		return nil, err
 	}
 
+	connectionSettings = flagSettings
 	if connectionSettings.Host == "" {
 		connectionSettings.Host = settings.Host
 		if connectionSettings.Host == "" {
@@ -109,7 +180,31 @@ func GetConnectionInfo(mayBeMissing bool) (*ConnectionInfo, error) {
 	if connectionSettings.Port == 0 {
 		connectionSettings.Port = settings.Port
 	}
-	if connectionSettings.Port == 0 || connectionSettings.User == "" || connectionSettings.Password == "" {
+	if connectionSettings.Socket == "" && flagSettings.Host == "" && flagSettings.Port == 0 {
+		// A host or port given on the command line means the user wants a
+		// TCP connection, even if a socket is configured elsewhere.
+		connectionSettings.Socket = settings.Socket
+		if connectionSettings.Socket == fileSocket && !socketExists(fileSocket) {
+			// The backend advertises its socket in the config file, but it
+			// can't be reached from here (e.g. a named pipe, from inside WSL).
+			connectionSettings.Socket = ""
+		}
+	}
+	if connectionSettings.Certificate == "" {
+		connectionSettings.Certificate = settings.Certificate
+	}
+	if connectionSettings.Token == "" {
+		connectionSettings.Token = settings.Token
+	}
+	if connectionSettings.Certificate == "" && readFileError == nil {
+		// The backend writes its certificate next to the config file.
+		candidate := filepath.Join(filepath.Dir(configPath), certificateFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			connectionSettings.Certificate = candidate
+		}
+	}
+	hasCredentials := connectionSettings.Socket != "" || connectionSettings.Token != "" || (connectionSettings.User != "" && connectionSettings.Password != "")
+	if (connectionSettings.Port == 0 && connectionSettings.Socket == "") || !hasCredentials {
 		// Missing the default config file may or may not be considered an error
 		if readFileError != nil {
 			if mayBeMissing {
@@ -117,12 +212,207 @@ func GetConnectionInfo(mayBeMissing bool) (*ConnectionInfo, error) {
 			}
 			return nil, readFileError
 		}
-		return nil, errors.New("insufficient connection settings (missing one or more of: port, user, and password)")
+		return nil, errors.New("insufficient connection settings (need a socket, or a port with either a user and password or a token)")
 	}
+	loadedConfigPath = configPath
+	loadedConfigModTime = modTime
 
 	return &connectionSettings, nil
 }
 
+// isLoopbackHost reports whether the host name refers to this machine, so
+// that traffic to it never leaves the host.
+func isLoopbackHost(hostname string) bool {
+	if strings.EqualFold(hostname, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// getRemoteConnectionInfo returns the connection details for an instance on
+// another machine.  The local config file describes the local instance, so
+// only the command line and the environment apply.
+func getRemoteConnectionInfo(server string) (*ConnectionInfo, error) {
+	if flagSettings.Host != "" || flagSettings.Port != 0 || flagSettings.Socket != "" {
+		return nil, errors.New("--server cannot be combined with --host, --port or --socket")
+	}
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", server, err)
+	}
+	if serverURL.Scheme != "https" && serverURL.Scheme != "http" {
+		return nil, fmt.Errorf("invalid server URL %q: the scheme must be https or http", server)
+	}
+	if serverURL.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: no host given", server)
+	}
+	if serverURL.Scheme == "http" && !isLoopbackHost(serverURL.Hostname()) && !allowInsecureServer {
+		return nil, fmt.Errorf("invalid server URL %q: use https for a server on another machine, or pass --insecure to send credentials unencrypted", server)
+	}
+	if serverURL.User != nil || serverURL.RawQuery != "" || serverURL.Fragment != "" {
+		return nil, fmt.Errorf("invalid server URL %q: only the scheme, host, port and path may be given", server)
+	}
+	var settings ConnectionInfo
+	if err := applyEnvironment(&settings); err != nil {
+		return nil, err
+	}
+	connectionSettings = flagSettings
+	connectionSettings.Server = strings.TrimSuffix(serverURL.String(), "/")
+	if connectionSettings.User == "" {
+		connectionSettings.User = settings.User
+	}
+	if connectionSettings.Password == "" {
+		connectionSettings.Password = settings.Password
+	}
+	if connectionSettings.Certificate == "" {
+		connectionSettings.Certificate = settings.Certificate
+	}
+	if connectionSettings.Token == "" {
+		connectionSettings.Token = settings.Token
+	}
+	if connectionSettings.Token == "" && (connectionSettings.User == "" || connectionSettings.Password == "") {
+		return nil, errors.New("insufficient connection settings for a remote server (need either a token, or a user and password)")
+	}
+	// There is no config file to watch for changes.
+	loadedConfigPath = ""
+	return &connectionSettings, nil
+}
+
+// ReloadConnectionInfo re-reads the connection details if the config file has
+// been rewritten (or a different candidate config file has appeared) since they
+// were last loaded; this happens when the backend restarts and picks a new port
+// or password.  The returned boolean indicates whether the connection details
+// changed as a result.
+func ReloadConnectionInfo() (*ConnectionInfo, bool, error) {
+	if loadedConfigPath == "" {
+		return &connectionSettings, false, nil
+	}
+	if configPath == loadedConfigPath && configPath != DefaultConfigPath {
+		info, err := os.Stat(configPath)
+		if err != nil || info.ModTime().Equal(loadedConfigModTime) {
+			return &connectionSettings, false, nil
+		}
+	}
+	previous := connectionSettings
+	info, err := GetConnectionInfo(false)
+	if err != nil {
+		return nil, false, err
+	}
+	return info, *info != previous, nil
+}
+
+// readConfigFile reads the connection settings from the given file, returning
+// them along with the file's modification time.  The backend rewrites the file
+// in place on startup, so an empty or unparsable file is retried a few times
+// before giving up.
+func readConfigFile(path string) (ConnectionInfo, time.Time, error) {
+	var settings ConnectionInfo
+	var err error
+	for attempt := 0; attempt < readConfigRetryCount; attempt++ {
+		if attempt > 0 {
+			time.Sleep(readConfigRetryWait)
+		}
+		var info os.FileInfo
+		var content []byte
+		if info, err = os.Stat(path); err != nil {
+			return settings, time.Time{}, err
+		}
+		if content, err = os.ReadFile(path); err != nil {
+			return settings, time.Time{}, err
+		}
+		if len(bytes.TrimSpace(content)) == 0 {
+			err = fmt.Errorf("config file %q is empty", path)
+			continue
+		}
+		settings = ConnectionInfo{}
+		if err = json.Unmarshal(content, &settings); err != nil {
+			err = fmt.Errorf("error parsing config file %q: %w", path, err)
+			continue
+		}
+		return settings, info.ModTime(), nil
+	}
+	return ConnectionInfo{}, time.Time{}, err
+}
+
+// applyEnvironment overrides the given settings with any values specified via
+// environment variables.  This allows clients (such as containers or CI jobs)
+// to connect without having access to the config file.
+func applyEnvironment(settings *ConnectionInfo) error {
+	if host := os.Getenv(hostEnvVar); host != "" {
+		settings.Host = host
+	}
+	if port := os.Getenv(portEnvVar); port != "" {
+		portNumber, err := strconv.Atoi(port)
+		if err != nil || portNumber <= 0 || portNumber > 65535 {
+			return fmt.Errorf("invalid value for %s: %q is not a valid port number", portEnvVar, port)
+		}
+		settings.Port = portNumber
+	}
+	if user := os.Getenv(userEnvVar); user != "" {
+		settings.User = user
+	}
+	if password := os.Getenv(passwordEnvVar); password != "" {
+		settings.Password = password
+	}
+	if socket := os.Getenv(socketEnvVar); socket != "" {
+		settings.Socket = socket
+	}
+	if certificate := os.Getenv(certEnvVar); certificate != "" {
+		settings.Certificate = certificate
+	}
+	if token := os.Getenv(tokenEnvVar); token != "" {
+		settings.Token = token
+	}
+	return nil
+}
+
+// getDefaultConfigPath returns the location of rd-engine.json for the current
+// instance.  If more than one candidate location exists, the most recently
+// written one is used, as that is the one belonging to the running backend.
+func getDefaultConfigPath() (string, error) {
+	candidates, err := candidateConfigPaths()
+	if err != nil {
+		return "", err
+	}
+	result := candidates[0]
+	var newest time.Time
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.ModTime().After(newest) {
+			result = candidate
+			newest = info.ModTime()
+		}
+	}
+	return result, nil
+}
+
+// candidateConfigPaths returns the possible locations of rd-engine.json for the
+// current instance, in order of preference.  Inside a WSL distro the Windows
+// application is preferred, but a Linux build of the application running inside
+// the distro is also supported.
+func candidateConfigPaths() ([]string, error) {
+	var candidates []string
+	if runtime.GOOS == "linux" && isWSLDistro() {
+		localAppData, err := wslifyConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("can't get WSL config-dir: %w", err)
+		}
+		suffix, err := paths.InstanceSuffix()
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, filepath.Join(localAppData, "rancher-desktop"+suffix, "rd-engine.json"))
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		if len(candidates) > 0 {
+			return candidates, nil
+		}
+		return nil, fmt.Errorf("failed to get paths: %w", err)
+	}
+	return append(candidates, filepath.Join(appPaths.AppHome, "rd-engine.json")), nil
+}
+
 // determines if we are running in a wsl linux distro
 // by checking for availability of wslpath and see if it's a symlink
 func isWSLDistro() bool {
@@ -137,7 +427,7 @@ func getLocalAppDataPath() (string, error) {
 	var outBuf bytes.Buffer
 	// changes the codepage to 65001 which is UTF-8
 	subCommand := `chcp 65001 >nul & echo %LOCALAPPDATA%`
-	cmd := exec.Command("cmd.exe", "/c", subCommand)
+	cmd := execctx.Command(context.Background(), 0, "cmd.exe", "/c", subCommand)
 	cmd.Stdout = &outBuf
 	// We are intentionally not using CombinedOutput and
 	// excluding the stderr since it could contain some
@@ -154,7 +444,7 @@ func wslifyConfigDir() (string, error) {
 		return "", err
 	}
 	var outBuf bytes.Buffer
-	cmd := exec.Command("/bin/wslpath", path)
+	cmd := execctx.Command(context.Background(), 0, "/bin/wslpath", path)
 	cmd.Stdout = &outBuf
 	if err = cmd.Run(); err != nil {
 		return "", err
@@ -0,0 +1,11 @@
+//go:build unix
+
+package config
+
+import "os"
+
+// socketExists checks whether there is a Unix domain socket at the given path.
+func socketExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
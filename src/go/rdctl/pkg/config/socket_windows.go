@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// socketExists checks whether there is a named pipe or a Unix domain socket at
+// the given path.
+func socketExists(path string) bool {
+	if strings.HasPrefix(path, `\\.\pipe\`) {
+		pathPtr, err := windows.UTF16PtrFromString(path)
+		if err != nil {
+			return false
+		}
+		attributes, err := windows.GetFileAttributes(pathPtr)
+		return err == nil && attributes != windows.INVALID_FILE_ATTRIBUTES
+	}
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -17,33 +17,36 @@ limitations under the License.
 package directories
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 )
 
-func SetupLimaHome(appHome string) error {
-	candidatePath := path.Join(appHome, "lima")
-	stat, err := os.Stat(candidatePath)
+// SetupLimaHome points LIMA_HOME at the given directory (normally the Lima
+// entry of the application paths), which must exist.
+func SetupLimaHome(limaHome string) error {
+	stat, err := os.Stat(limaHome)
 	if err != nil {
-		return fmt.Errorf("can't find the lima-home directory at %q", candidatePath)
+		return fmt.Errorf("can't find the lima-home directory at %q", limaHome)
 	}
 	if !stat.Mode().IsDir() {
-		return fmt.Errorf("path %q exists but isn't a directory", candidatePath)
+		return fmt.Errorf("path %q exists but isn't a directory", limaHome)
 	}
-	os.Setenv("LIMA_HOME", candidatePath)
+	os.Setenv("LIMA_HOME", limaHome)
 	return nil
 }
 
 func getOSMajorVersion() (int, error) {
 	// syscall.Uname isn't available on macOS, so we need to shell out.
 	// This is only called once by `rdctl shutdown` and once by `rdctl shell` so there's no need to memoize the result
-	version, err := exec.Command("uname", "-r").CombinedOutput()
+	version, err := execctx.Command(context.Background(), 0, "uname", "-r").CombinedOutput()
 	if err != nil {
 		return -1, err
 	}
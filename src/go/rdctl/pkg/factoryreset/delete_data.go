@@ -17,11 +17,11 @@ limitations under the License.
 package factoryreset
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -29,59 +29,158 @@ import (
 	"syscall"
 
 	dockerconfig "github.com/docker/docker/cli/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"github.com/sirupsen/logrus"
 )
 
-func addAppHomeWithoutSnapshots(appHome string) []string {
-	haveSnapshots := false
-	if snapshots, err := os.ReadDir(filepath.Join(appHome, "snapshots")); err == nil {
-		haveSnapshots = len(snapshots) > 0
+// Options selects which parts of the Rancher Desktop state DeleteData removes.
+type Options struct {
+	// RemoveKubernetesCache also removes the cached Kubernetes images.
+	RemoveKubernetesCache bool
+	// KeepImages preserves the container image store, by keeping the VM disk
+	// (or the WSL data distribution), along with the Kubernetes image cache.
+	// Everything else in the VM must have been removed beforehand (see
+	// shutdown.RemoveContainerState), while the VM was still running.
+	KeepImages bool
+	// KeepSettings preserves the application settings file.
+	KeepSettings bool
+	// KeepKubeconfig leaves the Rancher Desktop context in the kubeconfig file.
+	KeepKubeconfig bool
+	// KeepSnapshots preserves any snapshots.
+	KeepSnapshots bool
+	// KeepDistros lists the WSL distributions (p.MainDistro and/or p.DataDistro)
+	// that should not be unregistered.  Only used on Windows.
+	KeepDistros []string
+	// KeepIntegratedDistro keeps the main WSL distribution if WSL integration
+	// is enabled for any other distribution.  Only used on Windows.
+	KeepIntegratedDistro bool
+	// Progress, if set, is called as each item is removed.
+	Progress ProgressFunc
+}
+
+// settingsFileName is the name of the settings file in the config directory.
+const settingsFileName = "settings.json"
+
+// addAppHomeWithout returns the paths to remove in order to clear the
+// application home directory, except for the preserved entries in it.
+// A non-empty snapshots directory is also preserved if keepSnapshots is set.
+func addAppHomeWithout(appHome string, keepSnapshots bool, preserved ...string) []string {
+	if keepSnapshots {
+		if snapshots, err := os.ReadDir(filepath.Join(appHome, "snapshots")); err == nil && len(snapshots) > 0 {
+			preserved = append(preserved, "snapshots")
+		}
+	}
+	return addDirectoryWithout(appHome, preserved...)
+}
+
+// addRelocatedLima returns the Lima directory if it has been moved out of the
+// application home (see paths.LocalDataEnvVar), and so wouldn't otherwise be
+// removed, unless the images should be kept.
+func addRelocatedLima(paths p.Paths, options Options) []string {
+	if options.KeepImages {
+		return nil
 	}
-	if !haveSnapshots {
-		return []string{appHome}
+	rel, err := filepath.Rel(paths.AppHome, paths.Lima)
+	if err == nil && !strings.HasPrefix(rel, "..") {
+		return nil
 	}
-	appHomeMembers, err := os.ReadDir(appHome)
+	return []string{paths.Lima}
+}
+
+// addDirectoryWithout returns the paths to remove in order to clear the given
+// directory, except for the preserved entries in it.
+func addDirectoryWithout(dir string, preserved ...string) []string {
+	if len(preserved) == 0 {
+		return []string{dir}
+	}
+	keep := map[string]bool{}
+	for _, name := range preserved {
+		keep[name] = true
+	}
+	members, err := os.ReadDir(dir)
 	if err != nil {
-		logrus.Errorf("failed to read contents of dir %s: %s", appHome, err)
-		return []string{appHome}
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Errorf("failed to read contents of dir %s: %s", dir, err)
+		}
+		return []string{dir}
 	}
-	pathList := make([]string, 0, len(appHomeMembers))
-	for _, entry := range appHomeMembers {
-		if filepath.Base(entry.Name()) != "snapshots" {
-			pathList = append(pathList, filepath.Join(appHome, entry.Name()))
+	pathList := make([]string, 0, len(members))
+	for _, entry := range members {
+		if !keep[entry.Name()] {
+			pathList = append(pathList, filepath.Join(dir, entry.Name()))
 		}
 	}
 	return pathList
 }
 
+// removeKubeconfigContext removes the Rancher Desktop context from the user's
+// kubeconfig file, unless it should be kept.
+func removeKubeconfigContext(options Options) {
+	if options.KeepKubeconfig {
+		return
+	}
+	kubeconfigPath, err := getKubeconfigPath()
+	if err != nil {
+		logrus.Errorf("Error trying to locate the kubeconfig file: %s", err)
+		return
+	}
+	if err := removeKubeconfigEntries(kubeconfigPath); err != nil {
+		logrus.Errorf("Error trying to remove the Rancher Desktop context from %s: %s", kubeconfigPath, err)
+	}
+}
+
 // Most of the errors in this function are reported, but we continue to try to delete things,
 // because there isn't really a dependency graph here.
 // For example, if we can't delete the Lima VM, that doesn't mean we can't remove docker files
 // or pull the path settings out of the shell profile files.
-func deleteUnixLikeData(paths p.Paths, pathList []string) error {
-	if err := deleteLimaVM(); err != nil {
-		logrus.Errorf("Error trying to delete the Lima VM: %s\n", err)
+func deleteUnixLikeData(paths p.Paths, pathList []string, options Options) error {
+	if options.KeepImages {
+		logrus.Infof("Keeping the Lima VM in %s to preserve container images", paths.Lima)
+	} else if _, err := os.Stat(paths.Lima); err == nil {
+		item := Item{Kind: ItemLimaVM, Location: paths.Lima}
+		if options.Progress != nil {
+			// Only measure the VM if anybody is interested, as it can take a while.
+			item.Size, _ = utils.DiskUsage(paths.Lima)
+		}
+		err := deleteLimaVM()
+		if err != nil {
+			logrus.Errorf("Error trying to delete the Lima VM: %s\n", err)
+		}
+		options.report(item, err)
 	}
 	for _, currentPath := range pathList {
-		if err := os.RemoveAll(currentPath); err != nil {
+		if err := removePath(currentPath, options); err != nil {
 			logrus.Errorf("Error trying to remove %s: %s", currentPath, err)
 		}
 	}
 	if err := clearDockerContext(); err != nil {
 		logrus.Errorf("Error trying to clear the docker context %s", err)
 	}
-	if err := removeDockerCliPlugins(paths.AltAppHome); err != nil {
+	if err := removeDockerCliPlugins(paths.AltAppHome, options); err != nil {
 		logrus.Errorf("Error trying to remove docker plugins %s", err)
 	}
+	removeKubeconfigContext(options)
 
-	homeDir, err := os.UserHomeDir()
+	rawPaths, err := getShellProfilePaths()
 	if err != nil {
 		// If we can't get home directory, none of the below code is valid
 		logrus.Errorf("Error trying to get home dir: %s", err)
 		return nil
 	}
+	return removePathManagement(rawPaths)
+}
+
+// getShellProfilePaths returns the shell startup files that Rancher Desktop
+// may have added PATH management blocks to.
+func getShellProfilePaths() ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
 	rawPaths := []string{
 		".bashrc",
 		".bash_profile",
@@ -94,9 +193,7 @@ func deleteUnixLikeData(paths p.Paths, pathList []string) error {
 	for i, s := range rawPaths {
 		rawPaths[i] = path.Join(homeDir, s)
 	}
-	rawPaths = append(rawPaths, path.Join(homeDir, ".config", "fish", "config.fish"))
-
-	return removePathManagement(rawPaths)
+	return append(rawPaths, path.Join(homeDir, ".config", "fish", "config.fish")), nil
 }
 
 func deleteLimaVM() error {
@@ -104,7 +201,7 @@ func deleteLimaVM() error {
 	if err != nil {
 		return err
 	}
-	if err := directories.SetupLimaHome(paths.AppHome); err != nil {
+	if err := directories.SetupLimaHome(paths.Lima); err != nil {
 		return err
 	}
 	execPath, err := os.Executable()
@@ -116,19 +213,45 @@ func deleteLimaVM() error {
 		return err
 	}
 	limactl := path.Join(path.Dir(path.Dir(execPath)), "lima", "bin", "limactl")
-	return exec.Command(limactl, "delete", "-f", "0").Run()
+	return execctx.Command(context.Background(), 0, limactl, "delete", "-f", "0").Run()
+}
+
+func removeDockerCliPlugins(altAppHomePath string, options Options) error {
+	plugins, err := findDockerCliPlugins(altAppHomePath)
+	if err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		options.report(Item{Kind: ItemDockerCliPlugin, Location: plugin}, os.Remove(plugin))
+	}
+	return nil
 }
 
-func removeDockerCliPlugins(altAppHomePath string) error {
+// removeAutostart disables starting Rancher Desktop on login.
+func removeAutostart(options Options) {
+	items := planAutostart()
+	err := autostart.EnsureAutostart(false, autostart.Options{})
+	if err != nil {
+		logrus.Errorf("Failed to remove autostart configuration: %s", err)
+	}
+	for _, item := range items {
+		options.report(item, err)
+	}
+}
+
+// findDockerCliPlugins returns the docker CLI plugins that are symbolic links
+// into the Rancher Desktop bin directory.
+func findDockerCliPlugins(altAppHomePath string) ([]string, error) {
 	cliPluginsDir := path.Join(dockerconfig.Dir(), "cli-plugins")
 	entries, err := os.ReadDir(cliPluginsDir)
 	if err != nil {
 		if errors.Is(err, syscall.ENOENT) {
 			// Nothing left to do here, since there is no cli-plugins dir
-			return nil
+			return nil, nil
 		}
-		return err
+		return nil, err
 	}
+	var plugins []string
 	for _, entry := range entries {
 		if entry.Type()&os.ModeSymlink != os.ModeSymlink {
 			continue
@@ -140,10 +263,10 @@ func removeDockerCliPlugins(altAppHomePath string) error {
 			continue
 		}
 		if strings.HasPrefix(target, path.Join(altAppHomePath, "bin")+"/") {
-			os.Remove(fullPathName)
+			plugins = append(plugins, fullPathName)
 		}
 	}
-	return nil
+	return plugins, nil
 }
 
 func removePathManagement(dotFiles []string) error {
@@ -229,6 +352,20 @@ func cleanupDockerContextFiles() {
 	os.RemoveAll(path.Join(dockerconfig.Dir(), "contexts", "meta", "b547d66a5de60e5f0843aba28283a8875c2ad72e99ba076060ef9ec7c09917c8"))
 }
 
+// usesRancherDesktopDockerContext reports whether the docker CLI's current
+// context is the one Rancher Desktop created.
+func usesRancherDesktopDockerContext() bool {
+	contents, err := os.ReadFile(path.Join(dockerconfig.Dir(), "config.json"))
+	if err != nil {
+		return false
+	}
+	dockerConfigContents := make(dockerConfigType)
+	if err = json.Unmarshal(contents, &dockerConfigContents); err != nil {
+		return false
+	}
+	return dockerConfigContents["currentContext"] == "rancher-desktop"
+}
+
 func clearDockerContext() error {
 	// Ignore failure to delete this next file:
 	os.Remove(path.Join(dockerconfig.Dir(), "plaintext-credentials.config.json"))
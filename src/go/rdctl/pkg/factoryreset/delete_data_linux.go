@@ -4,16 +4,21 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/sirupsen/logrus"
 )
 
-func DeleteData(paths paths.Paths, removeKubernetesCache bool) error {
-	if err := autostart.EnsureAutostart(false); err != nil {
-		logrus.Errorf("Failed to remove autostart configuration: %s", err)
-	}
+func DeleteData(paths paths.Paths, options Options) error {
+	removeAutostart(options)
+	return deleteUnixLikeData(paths, getPathsToDelete(paths, options), options)
+}
+
+// Plan returns the items that DeleteData would remove, without removing anything.
+func Plan(paths paths.Paths, options Options) ([]Item, error) {
+	return planUnixLikeData(paths, getPathsToDelete(paths, options), options), nil
+}
 
+func getPathsToDelete(paths paths.Paths, options Options) []string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		logrus.Errorf("Error getting home directory: %s", err)
@@ -21,7 +26,6 @@ func DeleteData(paths paths.Paths, removeKubernetesCache bool) error {
 
 	pathList := []string{
 		paths.AltAppHome,
-		paths.Config,
 		paths.Logs,
 		filepath.Join(homeDir, ".local", "state", "rancher-desktop"),
 	}
@@ -35,12 +39,23 @@ func DeleteData(paths paths.Paths, removeKubernetesCache bool) error {
 		pathList = append(pathList, filepath.Join(configPath, "Rancher Desktop"))
 	}
 
-	if removeKubernetesCache {
+	if options.RemoveKubernetesCache && !options.KeepImages {
 		pathList = append(pathList, paths.Cache)
 	} else {
 		pathList = append(pathList, filepath.Join(paths.Cache, "updater-longhorn.json"))
 	}
-	appHomeDirs := addAppHomeWithoutSnapshots(paths.AppHome)
+	var preserved []string
+	if options.KeepImages {
+		// The Lima VM (whose disk holds the image store) lives in the app home.
+		preserved = append(preserved, filepath.Base(paths.Lima))
+	}
+	appHomeDirs := addAppHomeWithout(paths.AppHome, options.KeepSnapshots, preserved...)
 	pathList = append(pathList, appHomeDirs...)
-	return deleteUnixLikeData(paths, pathList)
+	pathList = append(pathList, addRelocatedLima(paths, options)...)
+	if options.KeepSettings {
+		pathList = append(pathList, addDirectoryWithout(paths.Config, settingsFileName)...)
+	} else {
+		pathList = append(pathList, paths.Config)
+	}
+	return pathList
 }
@@ -196,3 +196,27 @@ func TestRemoveManagedBlock(t *testing.T) {
 		verifyMgmtRemoved(t, dotFile)
 	}
 }
+
+func TestAddAppHomeWithout(t *testing.T) {
+	appHome := t.TempDir()
+	for _, dir := range []string{"lima", "snapshots", "logs"} {
+		assert.NoError(t, os.Mkdir(path.Join(appHome, dir), 0o755))
+	}
+
+	t.Run("removes the whole directory without anything to preserve", func(t *testing.T) {
+		assert.Equal(t, []string{appHome}, addAppHomeWithout(appHome, true))
+	})
+
+	t.Run("keeps preserved entries", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{path.Join(appHome, "logs"), path.Join(appHome, "snapshots")}, addAppHomeWithout(appHome, true, "lima"))
+	})
+
+	t.Run("keeps non-empty snapshot directories", func(t *testing.T) {
+		assert.NoError(t, os.Mkdir(path.Join(appHome, "snapshots", "snapshot-id"), 0o755))
+		assert.ElementsMatch(t, []string{path.Join(appHome, "lima"), path.Join(appHome, "logs")}, addAppHomeWithout(appHome, true))
+	})
+
+	t.Run("removes snapshots unless they should be kept", func(t *testing.T) {
+		assert.Equal(t, []string{appHome}, addAppHomeWithout(appHome, false))
+	})
+}
@@ -1,20 +1,27 @@
 package factoryreset
 
 import (
-	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/sirupsen/logrus"
 )
 
-func DeleteData(paths paths.Paths, removeKubernetesCache bool) error {
-	if err := autostart.EnsureAutostart(false); err != nil {
-		logrus.Errorf("Failed to remove autostart configuration: %s", err)
-	}
-	if err := UnregisterWSL(); err != nil {
+func DeleteData(paths paths.Paths, options Options) error {
+	removeAutostart(options)
+	distros, keptDistros := getDistrosToUnregister(paths, options)
+	reportKeptDistros(keptDistros)
+	if err := unregisterWSL(options, distros...); err != nil {
 		logrus.Errorf("could not unregister WSL: %s", err)
 		return err
 	}
-	if err := deleteWindowsData(!removeKubernetesCache, "rancher-desktop"); err != nil {
+	if err := deleteWindowsData(options, keptDistros, "rancher-desktop"); err != nil {
 		logrus.Errorf("could not delete data: %s", err)
 		return err
 	}
@@ -22,6 +29,94 @@ func DeleteData(paths paths.Paths, removeKubernetesCache bool) error {
 		logrus.Errorf("could not clear docker context: %s", err)
 		return err
 	}
+	removeKubeconfigContext(options)
 	logrus.Infoln("successfully cleared data.")
 	return nil
 }
+
+// Plan returns the items that DeleteData would remove, without removing anything.
+func Plan(paths paths.Paths, options Options) ([]Item, error) {
+	items := append(planContainerState(options), planAutostart()...)
+	registered, err := listWSLDistros()
+	if err != nil {
+		return nil, err
+	}
+	distros, keptDistros := getDistrosToUnregister(paths, options)
+	reportKeptDistros(keptDistros)
+	for _, distro := range distros {
+		for _, registeredDistro := range registered {
+			if distro == registeredDistro {
+				items = append(items, Item{Kind: ItemWSLDistro, Location: distro})
+			}
+		}
+	}
+	dirs, err := getDirectoriesToDelete(options, keptDistros, "rancher-desktop")
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, planPaths(dirs)...)
+	return append(items, planClientConfiguration(options)...), nil
+}
+
+// getDistrosToUnregister returns the Rancher Desktop WSL distributions to
+// unregister, along with the reasons for keeping the others.
+func getDistrosToUnregister(appPaths paths.Paths, options Options) ([]string, map[string]string) {
+	keptDistros := map[string]string{}
+	for _, distro := range options.KeepDistros {
+		keptDistros[distro] = "requested on the command line"
+	}
+	if options.KeepImages {
+		keptDistros[paths.DataDistro()] = "it holds the container images"
+	}
+	if options.KeepIntegratedDistro {
+		integrations, err := getEnabledWSLIntegrations(filepath.Join(appPaths.Config, settingsFileName))
+		if err != nil {
+			logrus.Errorf("Failed to read WSL integration settings: %s", err)
+		} else if len(integrations) > 0 {
+			keptDistros[paths.MainDistro()] = fmt.Sprintf("WSL integration is enabled for %s", strings.Join(integrations, ", "))
+		}
+	}
+	var distros []string
+	for _, distro := range []string{paths.MainDistro(), paths.DataDistro()} {
+		if _, ok := keptDistros[distro]; !ok {
+			distros = append(distros, distro)
+		}
+	}
+	return distros, keptDistros
+}
+
+func reportKeptDistros(keptDistros map[string]string) {
+	for _, distro := range []string{paths.MainDistro(), paths.DataDistro()} {
+		if reason, ok := keptDistros[distro]; ok {
+			logrus.Infof("Keeping WSL distribution %s: %s", distro, reason)
+		}
+	}
+}
+
+// getEnabledWSLIntegrations returns the names of the WSL distributions that
+// have integration enabled in the given settings file.
+func getEnabledWSLIntegrations(settingsPath string) ([]string, error) {
+	contents, err := os.ReadFile(settingsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var settings struct {
+		WSL struct {
+			Integrations map[string]bool `json:"integrations"`
+		} `json:"WSL"`
+	}
+	if err := json.Unmarshal(contents, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", settingsPath, err)
+	}
+	var integrations []string
+	for distro, enabled := range settings.WSL.Integrations {
+		if enabled {
+			integrations = append(integrations, distro)
+		}
+	}
+	sort.Strings(integrations)
+	return integrations, nil
+}
@@ -28,10 +28,10 @@ func KillRancherDesktop() error {
 	return fmt.Errorf("internal error: KillRancherDesktop shouldn't be called")
 }
 
-func deleteWindowsData(_ bool, _ string) error {
+func deleteWindowsData(_ Options, _ map[string]string, _ string) error {
 	return fmt.Errorf("internal error: deleteWindowsData shouldn't be called")
 }
 
-func unregisterWSL() error {
+func unregisterWSL(_ Options, _ ...string) error {
 	return fmt.Errorf("internal error: unregisterWSL shouldn't be called")
 }
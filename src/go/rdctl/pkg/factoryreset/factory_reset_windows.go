@@ -18,28 +18,24 @@ package factoryreset
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
-	"sort"
 	"strings"
 	"syscall"
-	"unsafe"
+	"time"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/windows"
-	"golang.org/x/text/encoding/unicode"
-)
-
-var (
-	pKernel32      = windows.NewLazySystemDLL("kernel32.dll")
-	pEnumProcesses = pKernel32.NewProc("K32EnumProcesses")
 )
 
 // CheckProcessWindows - returns true if Rancher Desktop is still running, false if it isn't
@@ -48,11 +44,11 @@ var (
 // It does this by calling `tasklist`, the Windows answer to ps(1)
 
 func CheckProcessWindows() (bool, error) {
-	cmd := exec.Command("tasklist", "/NH", "/FI", "IMAGENAME eq Rancher Desktop.exe", "/FO", "CSV")
+	cmd := execctx.Command(context.Background(), 0, "tasklist", "/NH", "/FI", "IMAGENAME eq Rancher Desktop.exe", "/FO", "CSV")
 	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: CREATE_NO_WINDOW}
 	allOutput, err := cmd.CombinedOutput()
 	if err != nil {
-		return false, fmt.Errorf("Failed to run %q: %w", cmd, err)
+		return false, err
 	}
 	r := csv.NewReader(bytes.NewReader(allOutput))
 	for {
@@ -70,6 +66,10 @@ func CheckProcessWindows() (bool, error) {
 	return false, nil
 }
 
+// processExitTimeout is how long KillRancherDesktop waits for the processes it
+// terminated to exit.
+const processExitTimeout = 10 * time.Second
+
 // KillRancherDesktop terminates all processes where the executable is from the
 // Rancher Desktop application, excluding the current process.
 func KillRancherDesktop() error {
@@ -84,91 +84,14 @@ func KillRancherDesktop() error {
 		return fmt.Errorf("could not find application directory: %w", err)
 	}
 
-	var processes []uint32
-	err = directories.InvokeWin32WithBuffer(func(size int) error {
-		processes = make([]uint32, size)
-		var bytesReturned uint32
-		// We can't use `windows.EnumProcesses`, because it passes in an incorrect
-		// value for the second argument (`cb`).
-		elementSize := unsafe.Sizeof(uint32(0))
-		bufferSize := uintptr(len(processes)) * elementSize
-		n, _, err := pEnumProcesses.Call(
-			uintptr(unsafe.Pointer(&processes[0])),
-			bufferSize,
-			uintptr(unsafe.Pointer(&bytesReturned)),
-		)
-		if n == 0 {
-			return err
-		}
-		if uintptr(bytesReturned) >= bufferSize {
-			return windows.ERROR_INSUFFICIENT_BUFFER
-		}
-		processesFound := uintptr(bytesReturned) / elementSize
-		logrus.Tracef("got %d processes", processesFound)
-		processes = processes[:processesFound]
-		return nil
-	})
+	processes, err := process.FindByExecutablePrefix(appDir)
 	if err != nil {
-		return fmt.Errorf("could not get process list: %w", err)
+		return err
 	}
-
-	sort.Slice(processes, func(i, j int) bool {
-		return processes[i] < processes[j]
-	})
 	var processesToKill []uint32
-	for _, pid := range processes {
-		// Add a scope to help with defer
-		(func(pid uint32) {
-			if pid == uint32(os.Getpid()) {
-				// Skip the current process.
-				return
-			}
-
-			hProc, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
-			if err != nil {
-				// We can't open privileged processes, processes that have exited since,
-				// idle process, etc.; so we log this at trace level instead.
-				logrus.Tracef("failed to open pid %d: %s (skipping)", pid, err)
-				return
-			}
-			defer windows.CloseHandle(hProc)
-
-			var imageName string
-			err = directories.InvokeWin32WithBuffer(func(size int) error {
-				nameBuf := make([]uint16, size)
-				charsWritten := uint32(size)
-				err := windows.QueryFullProcessImageName(hProc, 0, &nameBuf[0], &charsWritten)
-				if err != nil {
-					logrus.Tracef("failed to get image name for pid %d: %s", pid, err)
-					return err
-				}
-				if charsWritten >= uint32(size)-1 {
-					logrus.Tracef("buffer too small for pid %d image name", pid)
-					return windows.ERROR_INSUFFICIENT_BUFFER
-				}
-				imageName = windows.UTF16ToString(nameBuf)
-				return nil
-			})
-			if err != nil {
-				logrus.Debugf("failed to get process name of pid %d: %s (skipping)", pid, err)
-				return
-			}
-
-			relPath, err := filepath.Rel(appDir, imageName)
-			if err != nil {
-				// This may be because they're on different drives, network shares, etc.
-				logrus.Tracef("failed to make pid %d image %s relative to %s: %s", pid, imageName, appDir, err)
-				return
-			}
-			if strings.HasPrefix(relPath, "..") {
-				// Relative path includes "../" prefix, not a child of appDir
-				logrus.Tracef("skipping pid %d (%s), not in app %s", pid, imageName, appDir)
-				return
-			}
-
-			logrus.Tracef("will terminate pid %d image %s", pid, imageName)
-			processesToKill = append(processesToKill, pid)
-		})(pid)
+	for _, proc := range processes {
+		logrus.Tracef("will terminate pid %d image %s", proc.PID, proc.Executable)
+		processesToKill = append(processesToKill, uint32(proc.PID))
 	}
 
 	for _, pid := range processesToKill {
@@ -186,24 +109,41 @@ func KillRancherDesktop() error {
 		})()
 	}
 
+	// Termination is asynchronous; wait for the processes to go away so that
+	// they no longer hold any files we're about to delete.
+	ctx, cancel := context.WithTimeout(context.Background(), processExitTimeout)
+	defer cancel()
+	for _, pid := range processesToKill {
+		if err := process.WaitForProcessExit(ctx, int(pid)); err != nil {
+			logrus.Infof("process %d did not exit: %s", pid, err)
+		}
+	}
+
 	return nil
 }
 
-func deleteWindowsData(keepSystemImages bool, appName string) error {
-	dirs, err := getDirectoriesToDelete(keepSystemImages, appName)
+func deleteWindowsData(options Options, keptDistros map[string]string, appName string) error {
+	dirs, err := getDirectoriesToDelete(options, keptDistros, appName)
 	if err != nil {
 		return err
 	}
 	for _, dir := range dirs {
 		logrus.WithField("path", dir).Trace("Removing directory")
-		if err := os.RemoveAll(dir); err != nil {
+		if err := removePath(dir, options); err != nil {
 			logrus.Errorf("Problem trying to delete %s: %s\n", dir, err)
 		}
 	}
 	return nil
 }
 
-func getDirectoriesToDelete(keepSystemImages bool, appName string) ([]string, error) {
+// wslDistroDirs maps the directories (in the application data directory)
+// holding the disks of the Rancher Desktop WSL distributions to their names.
+func wslDistroDirs() map[string]string {
+	return map[string]string{"distro": paths.MainDistro(), "distro-data": paths.DataDistro()}
+}
+
+func getDirectoriesToDelete(options Options, keptDistros map[string]string, appName string) ([]string, error) {
+	keepSystemImages := !options.RemoveKubernetesCache || options.KeepImages
 	// Ordered from least important to most, so that if delete fails we
 	// still keep some useful data.
 	localAppData, err := directories.GetLocalAppDataDirectory()
@@ -223,7 +163,7 @@ func getDirectoriesToDelete(keepSystemImages bool, appName string) ([]string, er
 	}
 	for _, appDataFile := range appDataFiles {
 		fileName := appDataFile.Name()
-		if fileName == "snapshots" {
+		if fileName == "snapshots" && options.KeepSnapshots {
 			// Only delete snapshots directory if it is empty
 			snapshotsDir := filepath.Join(localRDAppData, fileName)
 			snapshotsDirContents, err := os.ReadDir(snapshotsDir)
@@ -253,6 +193,11 @@ func getDirectoriesToDelete(keepSystemImages bool, appName string) ([]string, er
 				}
 			}
 			deleteLocalRDAppData = false
+		} else if fileName == settingsFileName && options.KeepSettings {
+			deleteLocalRDAppData = false
+		} else if _, kept := keptDistros[wslDistroDirs()[fileName]]; kept {
+			// Keep the disks of any WSL distributions that are not unregistered.
+			deleteLocalRDAppData = false
 		} else {
 			dirs = append(dirs, filepath.Join(localRDAppData, fileName))
 		}
@@ -262,9 +207,9 @@ func getDirectoriesToDelete(keepSystemImages bool, appName string) ([]string, er
 	}
 	roamingAppData, err := directories.GetRoamingAppDataDirectory()
 	if err == nil {
-		dirs = append(dirs, path.Join(roamingAppData, appName))
+		dirs = append(dirs, filepath.Join(roamingAppData, appName))
 		// Electron stores some files in AppData\Roaming\Rancher Desktop
-		dirs = append(dirs, path.Join(roamingAppData, "Rancher Desktop"))
+		dirs = append(dirs, filepath.Join(roamingAppData, "Rancher Desktop"))
 	} else {
 		logrus.Errorf("Could not get AppData (roaming) folder: %s\n", err)
 	}
@@ -273,33 +218,43 @@ func getDirectoriesToDelete(keepSystemImages bool, appName string) ([]string, er
 
 const CREATE_NO_WINDOW = 0x08000000
 
-func UnregisterWSL() error {
-	cmd := exec.Command("wsl", "--list", "--quiet")
-	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: CREATE_NO_WINDOW}
-	rawBytes, err := cmd.CombinedOutput()
+// listWSLDistros returns the names of the registered WSL distributions.
+func listWSLDistros() ([]string, error) {
+	output, err := wslexe.Run(context.Background(), "--list", "--quiet")
 	if err != nil {
-		return fmt.Errorf("error getting current WSLs: %w", err)
+		return nil, fmt.Errorf("error getting current WSLs: %w", err)
 	}
-	decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
-	actualOutput, err := decoder.String(string(rawBytes))
+	return strings.Split(output, "\n"), nil
+}
+
+// UnregisterWSL unregisters the Rancher Desktop WSL distributions.
+func UnregisterWSL() error {
+	return unregisterWSL(Options{}, paths.MainDistro(), paths.DataDistro())
+}
+
+// unregisterWSL unregisters the given WSL distributions, if they exist.
+func unregisterWSL(options Options, distros ...string) error {
+	wsls, err := listWSLDistros()
 	if err != nil {
-		return fmt.Errorf("error getting current WSLs: %w", err)
+		return err
 	}
-	actualOutput = strings.ReplaceAll(actualOutput, "\r", "")
-	wsls := strings.Split(actualOutput, "\n")
 	wslsToKill := []string{}
 	for _, s := range wsls {
-		if s == "rancher-desktop" || s == "rancher-desktop-data" {
-			wslsToKill = append(wslsToKill, s)
+		for _, distro := range distros {
+			if s == distro {
+				wslsToKill = append(wslsToKill, s)
+			}
 		}
 	}
 
 	for _, wsl := range wslsToKill {
-		cmd := exec.Command("wsl", "--unregister", wsl)
-		cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: CREATE_NO_WINDOW}
-		if err := cmd.Run(); err != nil {
+		_, err := wslexe.Run(context.Background(), "--unregister", wsl)
+		if err != nil {
 			logrus.Errorf("Error unregistering WSL %s: %s\n", wsl, err)
+		} else {
+			logrus.Infof("Unregistered WSL distribution %s", wsl)
 		}
+		options.report(Item{Kind: ItemWSLDistro, Location: wsl}, err)
 	}
 	return nil
 }
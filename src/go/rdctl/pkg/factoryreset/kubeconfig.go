@@ -0,0 +1,107 @@
+package factoryreset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeContextName is the name Rancher Desktop uses for the context, cluster,
+// and user it adds to the kubeconfig file.
+const kubeContextName = "rancher-desktop"
+
+// getKubeconfigPath returns the kubeconfig file Rancher Desktop writes its
+// context into: the first entry of $KUBECONFIG, or ~/.kube/config.
+func getKubeconfigPath() (string, error) {
+	if kubeconfig := filepath.SplitList(os.Getenv("KUBECONFIG")); len(kubeconfig) > 0 && kubeconfig[0] != "" {
+		return kubeconfig[0], nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".kube", "config"), nil
+}
+
+// removeKubeconfigEntries removes the Rancher Desktop context, cluster, and
+// user from the given kubeconfig file, leaving everything else intact.
+func removeKubeconfigEntries(kubeconfigPath string) error {
+	document, changed, err := pruneKubeconfig(kubeconfigPath)
+	if err != nil || !changed {
+		return err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to serialize kubeconfig %q: %w", kubeconfigPath, err)
+	}
+	info, err := os.Stat(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(kubeconfigPath, buf.Bytes(), info.Mode())
+}
+
+// pruneKubeconfig reads the given kubeconfig file and removes the Rancher
+// Desktop entries from the parsed document; it returns whether anything was
+// removed.  A missing file is not an error.
+func pruneKubeconfig(kubeconfigPath string) (*yaml.Node, bool, error) {
+	contents, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read kubeconfig %q: %w", kubeconfigPath, err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, false, fmt.Errorf("failed to parse kubeconfig %q: %w", kubeconfigPath, err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return &document, false, nil
+	}
+	root := document.Content[0]
+	changed := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "clusters", "contexts", "users":
+			if value.Kind != yaml.SequenceNode {
+				continue
+			}
+			entries := value.Content[:0]
+			for _, entry := range value.Content {
+				if entryName(entry) == kubeContextName {
+					changed = true
+					continue
+				}
+				entries = append(entries, entry)
+			}
+			value.Content = entries
+		case "current-context":
+			if value.Value == kubeContextName {
+				value.Value = ""
+				changed = true
+			}
+		}
+	}
+	return &document, changed, nil
+}
+
+// entryName returns the value of the "name" field of a kubeconfig list entry.
+func entryName(entry *yaml.Node) string {
+	if entry.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(entry.Content); i += 2 {
+		if entry.Content[i].Value == "name" {
+			return entry.Content[i+1].Value
+		}
+	}
+	return ""
+}
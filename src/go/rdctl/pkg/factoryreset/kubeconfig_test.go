@@ -0,0 +1,55 @@
+package factoryreset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveKubeconfigEntries(t *testing.T) {
+	t.Run("removes only the Rancher Desktop entries", func(t *testing.T) {
+		kubeconfigPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+clusters:
+  - cluster:
+      server: https://127.0.0.1:6443
+    name: rancher-desktop
+  - cluster:
+      server: https://example.com
+    name: other
+contexts:
+  - context:
+      cluster: rancher-desktop
+      user: rancher-desktop
+    name: rancher-desktop
+current-context: rancher-desktop
+kind: Config
+users:
+  - name: rancher-desktop
+    user: {}
+`), 0o600))
+		require.NoError(t, removeKubeconfigEntries(kubeconfigPath))
+		contents, err := os.ReadFile(kubeconfigPath)
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+clusters:
+  - cluster:
+      server: https://example.com
+    name: other
+contexts: []
+current-context: ""
+kind: Config
+users: []
+`, string(contents))
+		info, err := os.Stat(kubeconfigPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("ignores missing files", func(t *testing.T) {
+		assert.NoError(t, removeKubeconfigEntries(filepath.Join(t.TempDir(), "config")))
+	})
+}
@@ -0,0 +1,170 @@
+package factoryreset
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of items removed by a factory reset.
+const (
+	ItemDirectory       = "directory"
+	ItemFile            = "file"
+	ItemAutostart       = "autostart"
+	ItemLimaVM          = "lima-vm"
+	ItemWSLDistro       = "wsl-distro"
+	ItemDockerContext   = "docker-context"
+	ItemDockerCliPlugin = "docker-cli-plugin"
+	ItemShellProfile    = "shell-profile"
+	ItemKubeconfig      = "kubeconfig-context"
+	// ItemContainerState is the containers, volumes, networks and Kubernetes
+	// state in the VM, removed when the VM is kept to keep the images.
+	ItemContainerState = "container-state"
+)
+
+// Item describes something that a factory reset removes (or, for shell
+// profiles and configuration files, modifies).
+type Item struct {
+	Kind string `json:"kind"`
+	// Location is the path, registry value, or name of the item.
+	Location string `json:"location"`
+	// Size is the disk space used, for files and directories.
+	Size int64 `json:"size,omitempty"`
+}
+
+// Result is the outcome of removing an item.
+type Result struct {
+	Item
+	// Error describes why the item could not be removed.
+	Error string `json:"error,omitempty"`
+}
+
+// ProgressFunc is called with the outcome of each item as it is removed.
+type ProgressFunc func(Result)
+
+// report passes the outcome of removing the item to the progress callback.
+func (options Options) report(item Item, err error) {
+	if options.Progress == nil {
+		return
+	}
+	result := Result{Item: item}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	options.Progress(result)
+}
+
+// removePath removes the given file or directory (if it exists), reporting it
+// along with the disk space it used.
+func removePath(currentPath string, options Options) error {
+	var items []Item
+	if options.Progress != nil {
+		items = planPaths([]string{currentPath})
+	}
+	err := os.RemoveAll(currentPath)
+	for _, item := range items {
+		options.report(item, err)
+	}
+	return err
+}
+
+// planPaths returns the items for the paths in the list that exist.
+func planPaths(pathList []string) []Item {
+	var items []Item
+	for _, currentPath := range pathList {
+		info, err := os.Lstat(currentPath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logrus.Errorf("Error trying to inspect %s: %s", currentPath, err)
+			}
+			continue
+		}
+		item := Item{Kind: ItemFile, Location: currentPath, Size: info.Size()}
+		if info.IsDir() {
+			item.Kind = ItemDirectory
+			if item.Size, err = utils.DiskUsage(currentPath); err != nil {
+				logrus.Errorf("Error trying to get the size of %s: %s", currentPath, err)
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// planAutostart returns the autostart configuration, if it exists.
+func planAutostart() []Item {
+	location, exists, err := autostart.GetAutostartLocation()
+	if err != nil {
+		logrus.Errorf("Error trying to inspect the autostart configuration: %s", err)
+	}
+	if !exists {
+		return nil
+	}
+	return []Item{{Kind: ItemAutostart, Location: location}}
+}
+
+// planClientConfiguration returns the docker and kubectl configuration entries
+// that would be removed.
+func planClientConfiguration(options Options) []Item {
+	var items []Item
+	if usesRancherDesktopDockerContext() {
+		items = append(items, Item{Kind: ItemDockerContext, Location: "rancher-desktop"})
+	}
+	if !options.KeepKubeconfig {
+		kubeconfigPath, err := getKubeconfigPath()
+		if err != nil {
+			logrus.Errorf("Error trying to locate the kubeconfig file: %s", err)
+		} else if _, changed, err := pruneKubeconfig(kubeconfigPath); err != nil {
+			logrus.Errorf("Error trying to inspect %s: %s", kubeconfigPath, err)
+		} else if changed {
+			items = append(items, Item{Kind: ItemKubeconfig, Location: kubeconfigPath})
+		}
+	}
+	return items
+}
+
+// planContainerState returns the item for the state removed from the VM
+// before a factory reset that keeps the images; the caller removes it, as the
+// VM must still be running.
+func planContainerState(options Options) []Item {
+	if !options.KeepImages {
+		return nil
+	}
+	return []Item{{Kind: ItemContainerState, Location: "containers, volumes, networks and Kubernetes state"}}
+}
+
+// planUnixLikeData is the counterpart of deleteUnixLikeData, returning the
+// items it would remove.
+func planUnixLikeData(paths p.Paths, pathList []string, options Options) []Item {
+	items := append(planContainerState(options), planAutostart()...)
+	if !options.KeepImages {
+		if _, err := os.Stat(paths.Lima); err == nil {
+			items = append(items, Item{Kind: ItemLimaVM, Location: paths.Lima})
+		}
+	}
+	items = append(items, planPaths(pathList)...)
+	items = append(items, planClientConfiguration(options)...)
+	plugins, err := findDockerCliPlugins(paths.AltAppHome)
+	if err != nil {
+		logrus.Errorf("Error trying to find docker plugins: %s", err)
+	}
+	for _, plugin := range plugins {
+		items = append(items, Item{Kind: ItemDockerCliPlugin, Location: plugin})
+	}
+	dotFiles, err := getShellProfilePaths()
+	if err != nil {
+		logrus.Errorf("Error trying to get home dir: %s", err)
+	}
+	for _, dotFile := range dotFiles {
+		contents, err := os.ReadFile(dotFile)
+		if err == nil && strings.Contains(string(contents), "### MANAGED BY RANCHER DESKTOP START") {
+			items = append(items, Item{Kind: ItemShellProfile, Location: dotFile})
+		}
+	}
+	return items
+}
@@ -0,0 +1,41 @@
+package factoryreset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPaths(t *testing.T) {
+	dir := t.TempDir()
+	subdir := filepath.Join(dir, "subdir")
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.Mkdir(subdir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(subdir, "contents"), make([]byte, 10), 0o644))
+	require.NoError(t, os.WriteFile(file, make([]byte, 5), 0o644))
+
+	items := planPaths([]string{subdir, file, filepath.Join(dir, "missing")})
+	assert.Equal(t, []Item{
+		{Kind: ItemDirectory, Location: subdir, Size: 10},
+		{Kind: ItemFile, Location: file, Size: 5},
+	}, items)
+}
+
+func TestRemovePath(t *testing.T) {
+	dir := t.TempDir()
+	subdir := filepath.Join(dir, "subdir")
+	require.NoError(t, os.Mkdir(subdir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(subdir, "contents"), make([]byte, 10), 0o644))
+
+	var results []Result
+	options := Options{Progress: func(result Result) {
+		results = append(results, result)
+	}}
+	require.NoError(t, removePath(subdir, options))
+	require.NoError(t, removePath(filepath.Join(dir, "missing"), options))
+	assert.NoDirExists(t, subdir)
+	assert.Equal(t, []Result{{Item: Item{Kind: ItemDirectory, Location: subdir, Size: 10}}}, results)
+}
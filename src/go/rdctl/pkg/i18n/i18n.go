@@ -0,0 +1,173 @@
+// Package i18n provides translations of the messages rdctl shows to users.
+// Like the translations for the application itself, the messages are kept in
+// one YAML file per locale (in the translations directory), with nested keys,
+// and may contain placeholders such as {name}.  Messages missing from the
+// catalog of the selected locale fall back to the en-us catalog.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocaleEnvVar overrides the locale from the standard LC_ALL, LC_MESSAGES, and
+// LANG environment variables.
+const LocaleEnvVar = "RD_LOCALE"
+
+// DefaultLocale is the locale with the complete set of messages.
+const DefaultLocale = "en-us"
+
+// localeEnvVars are the environment variables that select the locale, in order
+// of precedence; the first one that is set wins, like for other programs.
+var localeEnvVars = []string{LocaleEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"}
+
+// localeAliases maps locales that don't have a catalog of their own to the
+// catalog for them.
+var localeAliases = map[string]string{
+	"zh-cn": "zh-hans",
+	"zh-sg": "zh-hans",
+}
+
+//go:embed translations/*.yaml
+var translationFiles embed.FS
+
+var placeholderRegexp = regexp.MustCompile(`\{(\w+)\}`)
+
+// Args are the values for the placeholders in a message.
+type Args map[string]interface{}
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]string
+	selected string
+)
+
+func load() {
+	var err error
+	catalogs, err = loadCatalogs(translationFiles)
+	if err != nil {
+		// The catalogs are built in, so this can only be a bug.
+		panic(err)
+	}
+	selected = selectLocale(os.Getenv)
+}
+
+// loadCatalogs reads the catalog of every locale, flattening the keys.
+func loadCatalogs(files fs.FS) (map[string]map[string]string, error) {
+	names, err := fs.Glob(files, "translations/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[string]string)
+	for _, name := range names {
+		contents, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		var tree map[string]interface{}
+		if err := yaml.Unmarshal(contents, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		catalog := make(map[string]string)
+		if err := flatten(catalog, "", tree); err != nil {
+			return nil, fmt.Errorf("invalid translations in %s: %w", name, err)
+		}
+		result[strings.TrimSuffix(path.Base(name), ".yaml")] = catalog
+	}
+	return result, nil
+}
+
+func flatten(catalog map[string]string, prefix string, tree map[string]interface{}) error {
+	for key, value := range tree {
+		switch value := value.(type) {
+		case string:
+			catalog[prefix+key] = value
+		case map[string]interface{}:
+			if err := flatten(catalog, prefix+key+".", value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s%s is not a string", prefix, key)
+		}
+	}
+	return nil
+}
+
+// selectLocale picks the catalog for the locale given by the environment.
+func selectLocale(getenv func(string) string) string {
+	for _, name := range localeEnvVars {
+		if value := getenv(name); value != "" {
+			return matchLocale(value)
+		}
+	}
+	return DefaultLocale
+}
+
+// matchLocale finds the catalog for a POSIX (e.g. "zh_CN.UTF-8") or BCP 47
+// (e.g. "zh-Hans") locale name, falling back to the default.
+func matchLocale(value string) string {
+	locale := strings.ToLower(strings.ReplaceAll(value, "_", "-"))
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if _, ok := catalogs[locale]; ok {
+		return locale
+	}
+	if alias, ok := localeAliases[locale]; ok {
+		return alias
+	}
+	// Otherwise use any catalog for the same language; this doesn't apply to
+	// Chinese, where the variants are written in different scripts.
+	language, _, _ := strings.Cut(locale, "-")
+	if language != "zh" {
+		var names []string
+		for name := range catalogs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == language || strings.HasPrefix(name, language+"-") {
+				return name
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Locale returns the locale that messages are shown in.
+func Locale() string {
+	loadOnce.Do(load)
+	return selected
+}
+
+// T returns the message with the given key in the selected locale, replacing
+// the placeholders with the given values.  Unknown keys are returned as
+// "%key%", to make them easy to spot.
+func T(key string, args ...Args) string {
+	loadOnce.Do(load)
+	message, ok := catalogs[selected][key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return "%" + key + "%"
+	}
+	return placeholderRegexp.ReplaceAllStringFunc(message, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		for _, a := range args {
+			if value, ok := a[name]; ok {
+				return fmt.Sprint(value)
+			}
+		}
+		return placeholder
+	})
+}
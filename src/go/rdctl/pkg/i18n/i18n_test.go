@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setLocale(t *testing.T, locale string) {
+	loadOnce.Do(load)
+	previous := selected
+	selected = locale
+	t.Cleanup(func() { selected = previous })
+}
+
+func placeholders(message string) []string {
+	var result []string
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(message, -1) {
+		result = append(result, match[1])
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestCatalogs(t *testing.T) {
+	loaded, err := loadCatalogs(translationFiles)
+	require.NoError(t, err)
+	require.Contains(t, loaded, DefaultLocale)
+	for locale, catalog := range loaded {
+		for key, message := range catalog {
+			defaultMessage, ok := loaded[DefaultLocale][key]
+			if assert.Truef(t, ok, "%s: key %s is not in the %s catalog", locale, key, DefaultLocale) {
+				assert.Equalf(t, placeholders(defaultMessage), placeholders(message), "%s: placeholders of %s", locale, key)
+			}
+		}
+	}
+}
+
+func TestSelectLocale(t *testing.T) {
+	loadOnce.Do(load)
+	testCases := []struct {
+		env      map[string]string
+		expected string
+	}{
+		{map[string]string{}, DefaultLocale},
+		{map[string]string{"LANG": "C"}, DefaultLocale},
+		{map[string]string{"LANG": "en_GB.UTF-8"}, DefaultLocale},
+		{map[string]string{"LANG": "zh_CN.UTF-8"}, "zh-hans"},
+		{map[string]string{"LANG": "zh_TW.UTF-8"}, DefaultLocale},
+		{map[string]string{"LANG": "fr_FR"}, DefaultLocale},
+		{map[string]string{"LANG": "zh_CN", "LC_MESSAGES": "en_US"}, DefaultLocale},
+		{map[string]string{"LC_MESSAGES": "en_US", "LC_ALL": "zh_SG"}, "zh-hans"},
+		{map[string]string{"LC_ALL": "en_US", LocaleEnvVar: "zh-Hans"}, "zh-hans"},
+	}
+	for _, testCase := range testCases {
+		getenv := func(name string) string { return testCase.env[name] }
+		assert.Equal(t, testCase.expected, selectLocale(getenv), "%v", testCase.env)
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Run("default locale", func(t *testing.T) {
+		setLocale(t, DefaultLocale)
+		assert.Equal(t, "No snapshots present.", T("snapshot.noSnapshots"))
+		assert.Equal(t, "Freed 1 MiB.", T("factoryReset.freed", Args{"size": "1 MiB"}))
+		assert.Equal(t, "Freed {size}.", T("factoryReset.freed"))
+		assert.Equal(t, "%no.such.key%", T("no.such.key"))
+	})
+	t.Run("other locale", func(t *testing.T) {
+		setLocale(t, "zh-hans")
+		assert.Equal(t, "已释放 1 MiB。", T("factoryReset.freed", Args{"size": "1 MiB"}))
+	})
+	t.Run("missing translation", func(t *testing.T) {
+		setLocale(t, "xx")
+		assert.Equal(t, "No snapshots present.", T("snapshot.noSnapshots"))
+	})
+}
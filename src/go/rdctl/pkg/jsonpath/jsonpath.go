@@ -0,0 +1,405 @@
+// Package jsonpath reads, writes and validates settings by their dotted path
+// (e.g. "kubernetes.version"), using the schema generated from the API spec
+// (client.SettingsSchema).  The settings themselves are decoded JSON (maps of
+// map[string]interface{}), which is what the API and deployment profiles use;
+// values are coerced to the JSON types the schema expects.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
+)
+
+// Options control how values are coerced to the types in the schema.
+type Options struct {
+	// NumericBooleans accepts 0 and 1 for booleans, as the registry has no
+	// boolean type.
+	NumericBooleans bool
+	// Strings parses string values given on the command line: "true" for a
+	// boolean, "8" for a number, a comma-separated or JSON list for a list,
+	// and a JSON object for a group of free-form settings.
+	Strings bool
+}
+
+// Error describes a value that doesn't match the schema.
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Setting is a valid leaf value, coerced to the type in the schema.
+type Setting struct {
+	Path  string
+	Value interface{}
+}
+
+// Kind is the JSON type of a setting.
+type Kind int
+
+const (
+	// Any is a free-form value, such as an entry of a Map.
+	Any Kind = iota
+	Bool
+	Int
+	Number
+	String
+	// List is a list of strings.
+	List
+	// Map is a group of free-form settings, which may have any name.
+	Map
+	// Group is a group of settings named by the schema.
+	Group
+)
+
+// Schema describes a setting, or a group of settings.
+type Schema struct {
+	Kind Kind
+	// Fields are the settings of a Group, in the order of the spec.
+	Fields []Field
+}
+
+// Field is a setting of a group, named as in JSON.
+type Field struct {
+	Name   string
+	Schema *Schema
+}
+
+// anySchema describes the entries of a Map.
+var anySchema = &Schema{Kind: Any}
+
+// Field returns the named setting of a group.  Registry names are
+// case-insensitive, so foldCase matches regardless of case.
+func (s *Schema) Field(name string, foldCase bool) (Field, bool) {
+	for _, field := range s.Fields {
+		if field.Name == name || (foldCase && strings.EqualFold(field.Name, name)) {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// SchemaOf returns the schema of a decoded JSON value, for free-form settings.
+func SchemaOf(value interface{}) *Schema {
+	switch value.(type) {
+	case bool:
+		return &Schema{Kind: Bool}
+	case int64:
+		return &Schema{Kind: Int}
+	case float64:
+		return &Schema{Kind: Number}
+	case string:
+		return &Schema{Kind: String}
+	case []string, []interface{}:
+		return &Schema{Kind: List}
+	case map[string]interface{}:
+		return &Schema{Kind: Map}
+	}
+	return anySchema
+}
+
+// Join appends a name to a dotted path.
+func Join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Lookup returns the schema of the setting at the path.  If the path is
+// unknown because of a typo, the error suggests the intended path.
+func Lookup(schema *Schema, path string) (*Schema, error) {
+	current := schema
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		switch current.Kind {
+		case Group:
+			field, ok := current.Field(name, false)
+			if !ok {
+				return nil, &Error{Path: path, Message: unknownPathMessage(schema, current, names, i)}
+			}
+			current = field.Schema
+		case Map:
+			// Free-form settings may have any name.
+			current = anySchema
+		default:
+			return nil, &Error{Path: path, Message: "unknown setting"}
+		}
+	}
+	return current, nil
+}
+
+// unknownPathMessage reports that names[i] isn't a setting of the group; the
+// suggestion is the whole path with that name corrected, if the rest of the
+// path is then valid.
+func unknownPathMessage(schema, group *Schema, names []string, i int) string {
+	suggestion := suggest(group, names[i])
+	if suggestion == "" {
+		return "unknown setting"
+	}
+	corrected := append(append(append([]string{}, names[:i]...), suggestion), names[i+1:]...)
+	if _, err := Lookup(schema, strings.Join(corrected, ".")); err != nil {
+		return "unknown setting"
+	}
+	return fmt.Sprintf("unknown setting; did you mean %q?", strings.Join(corrected, "."))
+}
+
+// Get returns the value at the path in the settings, if there is one.
+func Get(settings map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = settings
+	for _, name := range strings.Split(path, ".") {
+		group, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = group[name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Set coerces the value to the type of the setting at the path, and stores it
+// in the settings, creating the groups leading to it as needed.
+func Set(schema *Schema, settings map[string]interface{}, path string, value interface{}, options Options) error {
+	fieldSchema, err := Lookup(schema, path)
+	if err != nil {
+		return err
+	}
+	c := coercer{options: options}
+	coerced, ok := c.coerce(fieldSchema, value, path)
+	if len(c.errors) > 0 {
+		return c.errors[0]
+	} else if !ok {
+		return &Error{Path: path, Message: "no value"}
+	}
+	names := strings.Split(path, ".")
+	group := settings
+	for i, name := range names[:len(names)-1] {
+		child, ok := group[name]
+		if !ok {
+			child = map[string]interface{}{}
+			group[name] = child
+		}
+		if group, ok = child.(map[string]interface{}); !ok {
+			return &Error{Path: strings.Join(names[:i+1], "."), Message: fmt.Sprintf("expected a group of settings, got %s", Describe(child))}
+		}
+	}
+	group[names[len(names)-1]] = coerced
+	return nil
+}
+
+// Validate checks the settings against the schema, returning the valid
+// settings and the problems found, both ordered by path.
+func Validate(schema *Schema, settings interface{}, options Options) ([]Setting, []*Error) {
+	c := coercer{options: options, collect: true}
+	c.coerce(schema, settings, "")
+	return c.settings, c.errors
+}
+
+// Describe returns a user-facing description of the type of a decoded value.
+func Describe(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "a boolean"
+	case int64, float64:
+		return fmt.Sprintf("the number %v", value)
+	case string:
+		return fmt.Sprintf("the string %q", value)
+	case []string, []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "a group of settings"
+	case nil:
+		return "nothing"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// coercer converts decoded values to the types in the schema.
+type coercer struct {
+	options Options
+	// collect records the valid leaf settings.
+	collect  bool
+	settings []Setting
+	errors   []*Error
+}
+
+func (c *coercer) fail(path, format string, args ...interface{}) (interface{}, bool) {
+	c.errors = append(c.errors, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	return nil, false
+}
+
+func (c *coercer) accept(path string, value interface{}) (interface{}, bool) {
+	if c.collect {
+		c.settings = append(c.settings, Setting{Path: path, Value: value})
+	}
+	return value, true
+}
+
+// coerce returns the value converted to the schema, and whether it is valid.
+func (c *coercer) coerce(schema *Schema, value interface{}, path string) (interface{}, bool) {
+	if text, ok := value.(string); ok && c.options.Strings {
+		value = c.parseString(schema, text)
+	}
+	switch schema.Kind {
+	case Group:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return c.fail(path, "expected a group of settings, got %s", Describe(value))
+		}
+		keys := make([]string, 0, len(valueMap))
+		for key := range valueMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result := map[string]interface{}{}
+		for _, key := range keys {
+			field, ok := schema.Field(key, false)
+			if !ok {
+				c.fail(Join(path, key), "%s", unknownMessage(schema, key))
+				continue
+			}
+			if coerced, ok := c.coerce(field.Schema, valueMap[key], Join(path, key)); ok {
+				result[key] = coerced
+			}
+		}
+		return result, true
+	case Bool:
+		switch typedValue := value.(type) {
+		case bool:
+			return c.accept(path, typedValue)
+		case int64:
+			if c.options.NumericBooleans && (typedValue == 0 || typedValue == 1) {
+				return c.accept(path, typedValue == 1)
+			}
+		}
+		return c.fail(path, "expected a boolean, got %s", Describe(value))
+	case Int:
+		switch typedValue := value.(type) {
+		case int64:
+			return c.accept(path, typedValue)
+		case float64:
+			if typedValue != float64(int64(typedValue)) {
+				return c.fail(path, "expected an integer, got %v", typedValue)
+			}
+			return c.accept(path, int64(typedValue))
+		}
+		return c.fail(path, "expected an integer, got %s", Describe(value))
+	case Number:
+		switch typedValue := value.(type) {
+		case int64:
+			return c.accept(path, float64(typedValue))
+		case float64:
+			return c.accept(path, typedValue)
+		}
+		return c.fail(path, "expected a number, got %s", Describe(value))
+	case String:
+		if _, ok := value.(string); !ok {
+			return c.fail(path, "expected a string, got %s", Describe(value))
+		}
+		return c.accept(path, value)
+	case List:
+		var list []string
+		switch typedValue := value.(type) {
+		case []string:
+			list = typedValue
+		case []interface{}:
+			list = make([]string, 0, len(typedValue))
+			for i, item := range typedValue {
+				itemString, ok := item.(string)
+				if !ok {
+					return c.fail(fmt.Sprintf("%s[%d]", path, i), "expected a string, got %s", Describe(item))
+				}
+				list = append(list, itemString)
+			}
+		default:
+			return c.fail(path, "expected a list, got %s", Describe(value))
+		}
+		return c.accept(path, list)
+	case Map:
+		// Free-form settings; any contents are accepted.
+		if _, ok := value.(map[string]interface{}); !ok {
+			return c.fail(path, "expected a group of settings, got %s", Describe(value))
+		}
+		return c.accept(path, value)
+	case Any:
+		return c.accept(path, value)
+	}
+	return c.fail(path, "unsupported setting kind %d", schema.Kind)
+}
+
+// parseString interprets a value given on the command line according to the
+// schema of the setting; values that can't be parsed are returned unchanged,
+// so the error describes them.
+func (c *coercer) parseString(schema *Schema, text string) interface{} {
+	switch schema.Kind {
+	case Bool:
+		if value, err := strconv.ParseBool(text); err == nil {
+			return value
+		}
+	case Int:
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return value
+		}
+	case Number:
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			return value
+		}
+	case List:
+		if strings.HasPrefix(strings.TrimSpace(text), "[") {
+			var list []interface{}
+			if err := json.Unmarshal([]byte(text), &list); err == nil {
+				return list
+			}
+			break
+		}
+		list := []string{}
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	case Group, Map:
+		var group map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &group); err == nil {
+			return group
+		}
+	case Any:
+		// Free-form settings hold JSON values; anything else is a string.
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err == nil {
+			return value
+		}
+	}
+	return text
+}
+
+// unknownMessage reports an unknown setting, suggesting the closest known
+// one if the name looks like a typo.
+func unknownMessage(group *Schema, name string) string {
+	if suggestion := suggest(group, name); suggestion != "" {
+		return fmt.Sprintf("unknown setting; did you mean %q?", suggestion)
+	}
+	return "unknown setting"
+}
+
+func suggest(group *Schema, name string) string {
+	names := make([]string, len(group.Fields))
+	for i, field := range group.Fields {
+		names[i] = field.Name
+	}
+	return utils.Suggest(name, names)
+}
@@ -0,0 +1,90 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schema = &Schema{Kind: Group, Fields: []Field{
+	{Name: "version", Schema: &Schema{Kind: Int}},
+	{Name: "application", Schema: &Schema{Kind: Group, Fields: []Field{
+		{Name: "adminAccess", Schema: &Schema{Kind: Bool}},
+	}}},
+	{Name: "kubernetes", Schema: &Schema{Kind: Group, Fields: []Field{
+		{Name: "version", Schema: &Schema{Kind: String}},
+		{Name: "port", Schema: &Schema{Kind: Int}},
+	}}},
+	{Name: "containerEngine", Schema: &Schema{Kind: Group, Fields: []Field{
+		{Name: "patterns", Schema: &Schema{Kind: List}},
+	}}},
+	{Name: "WSL", Schema: &Schema{Kind: Group, Fields: []Field{
+		{Name: "integrations", Schema: &Schema{Kind: Map}},
+	}}},
+}}
+
+func TestLookup(t *testing.T) {
+	fieldSchema, err := Lookup(schema, "kubernetes.port")
+	require.NoError(t, err)
+	assert.Equal(t, Int, fieldSchema.Kind)
+
+	fieldSchema, err = Lookup(schema, "WSL.integrations.Ubuntu")
+	require.NoError(t, err)
+	assert.Equal(t, Any, fieldSchema.Kind)
+
+	_, err = Lookup(schema, "kubernetes.prot")
+	assert.EqualError(t, err, `kubernetes.prot: unknown setting; did you mean "kubernetes.port"?`)
+	_, err = Lookup(schema, "kubernets.version")
+	assert.EqualError(t, err, `kubernets.version: unknown setting; did you mean "kubernetes.version"?`)
+	_, err = Lookup(schema, "kubernets.adminAccess")
+	assert.EqualError(t, err, "kubernets.adminAccess: unknown setting")
+	_, err = Lookup(schema, "kubernetes.port.number")
+	assert.EqualError(t, err, "kubernetes.port.number: unknown setting")
+}
+
+func TestSet(t *testing.T) {
+	settings := map[string]interface{}{}
+	options := Options{Strings: true}
+	require.NoError(t, Set(schema, settings, "kubernetes.port", "6443", options))
+	require.NoError(t, Set(schema, settings, "application.adminAccess", "false", options))
+	require.NoError(t, Set(schema, settings, "containerEngine.patterns", "docker.io, ghcr.io", options))
+	require.NoError(t, Set(schema, settings, "WSL.integrations.Ubuntu", "true", options))
+	assert.Equal(t, map[string]interface{}{
+		"application":     map[string]interface{}{"adminAccess": false},
+		"kubernetes":      map[string]interface{}{"port": int64(6443)},
+		"containerEngine": map[string]interface{}{"patterns": []string{"docker.io", "ghcr.io"}},
+		"WSL":             map[string]interface{}{"integrations": map[string]interface{}{"Ubuntu": true}},
+	}, settings)
+
+	value, ok := Get(settings, "kubernetes.port")
+	assert.True(t, ok)
+	assert.Equal(t, int64(6443), value)
+	_, ok = Get(settings, "kubernetes.version")
+	assert.False(t, ok)
+
+	assert.EqualError(t, Set(schema, settings, "kubernetes.port", "many", options),
+		`kubernetes.port: expected an integer, got the string "many"`)
+	assert.EqualError(t, Set(schema, settings, "kubernetes.port", "6443", Options{}),
+		`kubernetes.port: expected an integer, got the string "6443"`)
+}
+
+func TestValidate(t *testing.T) {
+	settings, problems := Validate(schema, map[string]interface{}{
+		"version":     float64(10),
+		"application": map[string]interface{}{"adminAccess": int64(1)},
+		"kubernetes":  map[string]interface{}{"port": 1.5, "verison": "1.29"},
+		"containerEngine": map[string]interface{}{
+			"patterns": []interface{}{"docker.io", int64(3)},
+		},
+	}, Options{NumericBooleans: true})
+	assert.Equal(t, []Setting{
+		{Path: "application.adminAccess", Value: true},
+		{Path: "version", Value: int64(10)},
+	}, settings)
+	assert.Equal(t, []*Error{
+		{Path: "containerEngine.patterns[1]", Message: "expected a string, got the number 3"},
+		{Path: "kubernetes.port", Message: "expected an integer, got 1.5"},
+		{Path: "kubernetes.verison", Message: `unknown setting; did you mean "version"?`},
+	}, problems)
+}
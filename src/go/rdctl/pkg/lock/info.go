@@ -0,0 +1,82 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+)
+
+// Info describes the holder of the backend lock; it is stored in the lock file.
+type Info struct {
+	// Action is the operation that took the lock (e.g. "create").
+	Action string `json:"action"`
+	// PID is the process ID of the holder; zero if unknown (for lock files
+	// written by older versions, which are empty).
+	PID int `json:"pid,omitempty"`
+	// Created is when the lock was taken.
+	Created time.Time `json:"created"`
+}
+
+// Age returns how long the lock has been held.
+func (info *Info) Age() time.Duration {
+	return time.Since(info.Created)
+}
+
+// IsStale returns whether the holder of the lock is known to have exited.
+func (info *Info) IsStale() bool {
+	return info.PID != 0 && !process.IsRunning(info.PID)
+}
+
+func lockFilePath(appPaths paths.Paths) string {
+	return filepath.Join(appPaths.AppHome, backendLockName)
+}
+
+// Status returns information about the holder of the backend lock, or nil if
+// the backend isn't locked.
+func Status(appPaths paths.Paths) (*Info, error) {
+	return readLockFile(lockFilePath(appPaths))
+}
+
+// readLockFile returns information about the holder of the lock in the given
+// file, or nil if it doesn't exist.
+func readLockFile(lockPath string) (*Info, error) {
+	contents, err := os.ReadFile(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read backend lock file %q: %w", lockPath, err)
+	}
+	info := &Info{}
+	if len(contents) == 0 || json.Unmarshal(contents, info) != nil {
+		// Older versions create an empty lock file; fall back to what we can
+		// tell from the file itself.
+		info = &Info{}
+		if stat, err := os.Stat(lockPath); err == nil {
+			info.Created = stat.ModTime()
+		}
+	}
+	return info, nil
+}
+
+// ForceUnlock removes the backend lock, as long as its holder is known to have
+// exited (or is unknown).  It returns the information about the removed lock,
+// or nil if the backend wasn't locked.
+func ForceUnlock(appPaths paths.Paths) (*Info, error) {
+	info, err := Status(appPaths)
+	if err != nil || info == nil {
+		return info, err
+	}
+	if info.PID != 0 && !info.IsStale() {
+		return nil, fmt.Errorf("the backend lock is held by running process %d (snapshot-%s action); refusing to remove it", info.PID, info.Action)
+	}
+	if err := os.Remove(lockFilePath(appPaths)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove backend lock file: %w", err)
+	}
+	return info, nil
+}
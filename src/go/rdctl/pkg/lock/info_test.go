@@ -0,0 +1,49 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	appPaths := paths.Paths{AppHome: t.TempDir()}
+	lockPath := filepath.Join(appPaths.AppHome, backendLockName)
+
+	t.Run("reports no lock", func(t *testing.T) {
+		info, err := Status(appPaths)
+		require.NoError(t, err)
+		assert.Nil(t, info)
+	})
+
+	t.Run("reads the holder", func(t *testing.T) {
+		require.NoError(t, createLockFile(lockPath, "create"))
+		defer os.Remove(lockPath)
+		info, err := Status(appPaths)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, "create", info.Action)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.False(t, info.IsStale())
+		_, err = ForceUnlock(appPaths)
+		assert.ErrorContains(t, err, "refusing to remove it")
+		assert.FileExists(t, lockPath)
+	})
+
+	t.Run("handles legacy empty lock files", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(lockPath, nil, 0o644))
+		info, err := Status(appPaths)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Zero(t, info.PID)
+		assert.False(t, info.IsStale())
+		assert.False(t, info.Created.IsZero())
+		_, err = ForceUnlock(appPaths)
+		require.NoError(t, err)
+		assert.NoFileExists(t, lockPath)
+	})
+}
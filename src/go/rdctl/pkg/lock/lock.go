@@ -1,54 +1,171 @@
 package lock
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/tracing"
 	"os"
-	"path/filepath"
+	"sync/atomic"
 	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const backendLockName = "backend.lock"
 
 type BackendLocker interface {
-	Lock(appPaths paths.Paths, action string) error
+	// Lock takes the exclusive lock, for operations that modify the backend.
+	// The context only applies to stopping the backend.
+	Lock(ctx context.Context, appPaths paths.Paths, action string) error
 	Unlock(appPaths paths.Paths, restart bool) error
+	// RLock takes a shared lock, for read-only operations; call the returned
+	// function to release it.
+	RLock(ctx context.Context, appPaths paths.Paths) (func(), error)
 }
 
 type BackendLock struct {
+	// rwLock is the reader/writer lock file, locked exclusively between Lock
+	// and Unlock.
+	rwLock *os.File
 }
 
-// Lock the backend by creating the lock file and shutting down the VM.
+// Lock the backend by creating the lock file, waiting for read-only operations
+// to finish, and shutting down the VM.
 // The lock file will be deleted if Lock returns an error (e.g. the backend couldn't be stopped).
-func (lock *BackendLock) Lock(appPaths paths.Paths, action string) error {
+// A lock left behind by a process that has since exited is removed automatically.
+func (lock *BackendLock) Lock(ctx context.Context, appPaths paths.Paths, action string) (err error) {
+	ctx, span := tracing.Start(ctx, "lock.acquire", attribute.String("lock.action", action))
+	defer func() { tracing.End(span, err) }()
 	if err := os.MkdirAll(appPaths.AppHome, 0o755); err != nil {
 		return fmt.Errorf("failed to create backend lock parent directory %q: %w", appPaths.AppHome, err)
 	}
-	// Create an empty file whose presence signifies that the backend is locked.
-	lockPath := filepath.Join(appPaths.AppHome, backendLockName)
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0o644)
-	if errors.Is(err, os.ErrExist) {
-		return errors.New("backend lock file already exists; if there is no snapshot operation in progress, you can remove this error with `rdctl snapshot unlock`")
-	} else if err != nil {
-		return fmt.Errorf("unexpected error acquiring backend lock: %w", err)
+	lockPath := lockFilePath(appPaths)
+	if err = acquireLockFile(lockPath, action); err != nil {
+		return err
 	}
-	if err := file.Close(); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to close backend lock file descriptor: %s", err)
+	readersCtx, readersSpan := tracing.Start(ctx, "lock.waitForReaders")
+	err = lock.lockExclusive(readersCtx, appPaths, readersWaitTimeout)
+	tracing.End(readersSpan, err)
+	if err == nil {
+		stopCtx, stopSpan := tracing.Start(ctx, "lock.stopBackend")
+		err = ensureBackendStopped(stopCtx, action)
+		tracing.End(stopSpan, err)
 	}
-	err = ensureBackendStopped(action)
 	if err != nil {
+		lock.unlockExclusive()
 		_ = os.Remove(lockPath)
 	}
 	return err
 }
 
-// Unlock the backend by removing the lock file. Restart the VM if the file was deleted and `restart` is true.
+// lockExclusive takes the reader/writer lock exclusively, waiting up to the
+// given time for read-only operations to finish.
+func (lock *BackendLock) lockExclusive(ctx context.Context, appPaths paths.Paths, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	file, err := lockRWFile(ctx, appPaths, true)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.New("timed out waiting for read-only operations to finish")
+	} else if err != nil {
+		return fmt.Errorf("unexpected error acquiring backend lock: %w", err)
+	}
+	lock.rwLock = file
+	return nil
+}
+
+func (lock *BackendLock) unlockExclusive() {
+	if lock.rwLock != nil {
+		unlockRWFile(lock.rwLock)
+		lock.rwLock = nil
+	}
+}
+
+// acquireLockFile creates the lock file, taking it over if its holder is known
+// to have exited.
+func acquireLockFile(lockPath, action string) error {
+	err := createLockFile(lockPath, action)
+	if errors.Is(err, os.ErrExist) {
+		info, statusErr := readLockFile(lockPath)
+		if statusErr == nil && info != nil && info.IsStale() {
+			logrus.Warnf("Removing stale backend lock left by process %d (snapshot-%s action)", info.PID, info.Action)
+			if err = removeStaleLockFile(lockPath, info); err == nil {
+				err = createLockFile(lockPath, action)
+			}
+		}
+		if errors.Is(err, os.ErrExist) {
+			return lockHeldError(info)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected error acquiring backend lock: %w", err)
+	}
+	return nil
+}
+
+// staleLockCounter makes the names that stale lock files are moved to unique
+// within the process.
+var staleLockCounter atomic.Int64
+
+// removeStaleLockFile removes the lock file if it is still the one left by the
+// stale holder.  Another process may have taken it over since the holder was
+// checked, so the file is first moved out of the way, which only one process
+// can do, and then checked again; if it turns out to be a new lock, it is put
+// back and os.ErrExist is returned.
+func removeStaleLockFile(lockPath string, stale *Info) error {
+	movedPath := fmt.Sprintf("%s.stale-%d-%d", lockPath, os.Getpid(), staleLockCounter.Add(1))
+	if err := os.Rename(lockPath, movedPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Someone else removed it first; try to create the lock anyway.
+			return nil
+		}
+		return err
+	}
+	moved, err := readLockFile(movedPath)
+	if err == nil && moved != nil && moved.PID == stale.PID && moved.Created.Equal(stale.Created) {
+		return os.Remove(movedPath)
+	}
+	if err := os.Link(movedPath, lockPath); err != nil {
+		logrus.Errorf("failed to restore backend lock file taken by another process: %s", err)
+	}
+	_ = os.Remove(movedPath)
+	return os.ErrExist
+}
+
+// createLockFile creates the lock file, recording the holder in it; the file
+// must not already exist.
+func createLockFile(lockPath, action string) error {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info := Info{Action: action, PID: os.Getpid(), Created: time.Now()}
+	if err := json.NewEncoder(file).Encode(info); err != nil {
+		logrus.Errorf("failed to write backend lock file: %s", err)
+	}
+	if err := file.Close(); err != nil {
+		logrus.Errorf("failed to close backend lock file descriptor: %s", err)
+	}
+	return nil
+}
+
+func lockHeldError(info *Info) error {
+	if info == nil || info.PID == 0 {
+		return errors.New("backend lock file already exists; if there is no snapshot operation in progress, you can remove this error with `rdctl snapshot unlock`")
+	}
+	return fmt.Errorf("the backend is locked by process %d (snapshot-%s action, held for %s); see `rdctl lock status`",
+		info.PID, info.Action, info.Age().Round(time.Second))
+}
+
+// Unlock the backend by removing the lock file and releasing the exclusive lock. Restart the VM if the file was deleted and `restart` is true.
 func (lock *BackendLock) Unlock(appPaths paths.Paths, restart bool) error {
-	lockPath := filepath.Join(appPaths.AppHome, backendLockName)
-	err := os.RemoveAll(lockPath)
+	err := os.RemoveAll(lockFilePath(appPaths))
+	lock.unlockExclusive()
 	if err == nil && restart {
 		err = ensureBackendStarted()
 	}
@@ -65,14 +182,16 @@ func ensureBackendStarted() error {
 		VMState: "STARTED",
 		Locked:  false,
 	}
-	err = rdClient.UpdateBackendState(desiredState)
+	// This isn't cancellable: the backend must be unlocked even if the
+	// operation that locked it was interrupted.
+	err = rdClient.UpdateBackendState(context.Background(), desiredState)
 	if err != nil && !errors.Is(err, client.ErrConnectionRefused) {
 		return fmt.Errorf("failed to restart backend: %w", err)
 	}
 	return nil
 }
 
-func ensureBackendStopped(action string) error {
+func ensureBackendStopped(ctx context.Context, action string) error {
 	connectionInfo, err := config.GetConnectionInfo(true)
 	if err != nil || connectionInfo == nil {
 		return err
@@ -80,7 +199,7 @@ func ensureBackendStopped(action string) error {
 
 	// Ensure backend is running if the main process is running at all
 	rdClient := client.NewRDClient(connectionInfo)
-	state, err := rdClient.GetBackendState()
+	state, err := rdClient.GetBackendState(ctx)
 	if errors.Is(err, client.ErrConnectionRefused) {
 		// If we cannot connect to the server, assume that the main
 		// process is not running.
@@ -97,10 +216,10 @@ func ensureBackendStopped(action string) error {
 		VMState: "STOPPED",
 		Locked:  true,
 	}
-	if err := rdClient.UpdateBackendState(desiredState); err != nil {
+	if err := rdClient.UpdateBackendState(ctx, desiredState); err != nil {
 		return fmt.Errorf("failed to stop backend: %w", err)
 	}
-	if err := waitForVMState(rdClient, []string{"STOPPED"}); err != nil {
+	if err := waitForVMState(ctx, rdClient, []string{"STOPPED"}); err != nil {
 		return fmt.Errorf("error waiting for backend to stop: %w", err)
 	}
 
@@ -108,11 +227,11 @@ func ensureBackendStopped(action string) error {
 }
 
 // Normally snapshots can be created at state STARTED or DISABLED
-func waitForVMState(rdClient client.RDClient, desiredStates []string) error {
+func waitForVMState(ctx context.Context, rdClient client.RDClient, desiredStates []string) error {
 	interval := 1 * time.Second
 	numIntervals := 120
 	for i := 0; i < numIntervals; i = i + 1 {
-		state, err := rdClient.GetBackendState()
+		state, err := rdClient.GetBackendState(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to poll backend state: %w", err)
 		}
@@ -121,7 +240,11 @@ func waitForVMState(rdClient client.RDClient, desiredStates []string) error {
 				return nil
 			}
 		}
-		time.Sleep(interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
 	}
 	return fmt.Errorf("timed out waiting for backend state in %s", desiredStates)
 }
@@ -0,0 +1,107 @@
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitedPID returns the PID of a process that has exited.
+func exitedPID(t *testing.T) int {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func writeStaleLockFile(t *testing.T, lockPath string, pid int) {
+	info := Info{Action: "restore", PID: pid, Created: time.Now().Add(-time.Hour)}
+	contents, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lockPath, contents, 0o644))
+}
+
+func TestAcquireLockFile(t *testing.T) {
+	stalePID := exitedPID(t)
+
+	t.Run("fails while the holder is running", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), backendLockName)
+		require.NoError(t, acquireLockFile(lockPath, "create"))
+		assert.ErrorContains(t, acquireLockFile(lockPath, "delete"), "the backend is locked by process")
+	})
+
+	t.Run("takes over a stale lock", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), backendLockName)
+		writeStaleLockFile(t, lockPath, stalePID)
+		require.NoError(t, acquireLockFile(lockPath, "create"))
+		info, err := readLockFile(lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, "create", info.Action)
+		assert.Equal(t, os.Getpid(), info.PID)
+	})
+
+	t.Run("does not remove a lock taken over by another contender", func(t *testing.T) {
+		dir := t.TempDir()
+		lockPath := filepath.Join(dir, backendLockName)
+		writeStaleLockFile(t, lockPath, stalePID)
+		// The first contender finds the lock stale...
+		stale, err := readLockFile(lockPath)
+		require.NoError(t, err)
+		require.True(t, stale.IsStale())
+		// ...but the second one takes it over before the first removes it.
+		require.NoError(t, acquireLockFile(lockPath, "second"))
+		assert.ErrorIs(t, removeStaleLockFile(lockPath, stale), os.ErrExist)
+
+		info, err := readLockFile(lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, "second", info.Action)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("only one of many contenders takes over a stale lock", func(t *testing.T) {
+		for attempt := 0; attempt < 20; attempt++ {
+			dir := t.TempDir()
+			lockPath := filepath.Join(dir, backendLockName)
+			writeStaleLockFile(t, lockPath, stalePID)
+
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			results := make([]error, 8)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-start
+					results[i] = acquireLockFile(lockPath, fmt.Sprintf("contender-%d", i))
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+
+			var winners []int
+			for i, err := range results {
+				if err == nil {
+					winners = append(winners, i)
+				} else {
+					assert.ErrorContains(t, err, "the backend is locked by process")
+				}
+			}
+			require.Len(t, winners, 1, "attempt %d: %v", attempt, results)
+			info, err := readLockFile(lockPath)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("contender-%d", winners[0]), info.Action)
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "the stale lock file must not be left behind")
+		}
+	})
+}
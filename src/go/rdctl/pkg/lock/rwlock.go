@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/sirupsen/logrus"
+)
+
+// Read-only operations hold a shared lock on this file, and operations that
+// modify the backend an exclusive one, as long as they run: a writer waits for
+// the readers to finish, and readers wait for the writer.  The operating
+// system releases the locks of a process that exits, so they can't go stale.
+const rwLockName = "backend.rwlock"
+
+const (
+	readersWaitTimeout = 30 * time.Second
+	rwLockPollInterval = 100 * time.Millisecond
+)
+
+// errLockBusy is returned by tryLockFile if the lock is held in a conflicting
+// mode.
+var errLockBusy = errors.New("the lock is held by another process")
+
+func rwLockFilePath(appPaths paths.Paths) string {
+	return filepath.Join(appPaths.AppHome, rwLockName)
+}
+
+// RLock takes a shared lock for a read-only operation, waiting for any
+// operation holding the exclusive lock to finish; call the returned function
+// to release it.
+func (lock *BackendLock) RLock(ctx context.Context, appPaths paths.Paths) (func(), error) {
+	file, err := lockRWFile(ctx, appPaths, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire shared backend lock: %w", err)
+	}
+	return func() { unlockRWFile(file) }, nil
+}
+
+// lockRWFile opens the reader/writer lock file and locks it, in exclusive mode
+// if requested, polling until the lock can be taken or the context is done.
+func lockRWFile(ctx context.Context, appPaths paths.Paths, exclusive bool) (*os.File, error) {
+	if err := os.MkdirAll(appPaths.AppHome, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backend lock parent directory %q: %w", appPaths.AppHome, err)
+	}
+	file, err := os.OpenFile(rwLockFilePath(appPaths), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	logged := false
+	for {
+		err = tryLockFile(file, exclusive)
+		if !errors.Is(err, errLockBusy) {
+			break
+		}
+		if !logged {
+			logged = true
+			if exclusive {
+				logrus.Info("Waiting for read-only operations to finish")
+			} else if info, _ := Status(appPaths); info != nil {
+				logrus.Infof("Waiting for the snapshot-%s action of process %d to finish", info.Action, info.PID)
+			} else {
+				logrus.Info("Waiting for the backend lock to be released")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			_ = file.Close()
+			return nil, ctx.Err()
+		case <-time.After(rwLockPollInterval):
+		}
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// unlockRWFile releases a lock taken by lockRWFile.
+func unlockRWFile(file *os.File) {
+	if err := unlockFile(file); err != nil {
+		logrus.Errorf("failed to release backend lock: %s", err)
+	}
+	if err := file.Close(); err != nil {
+		logrus.Errorf("failed to close backend lock file descriptor: %s", err)
+	}
+}
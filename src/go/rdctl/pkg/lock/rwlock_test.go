@@ -0,0 +1,91 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRWLock(t *testing.T) {
+	const shortWait = 200 * time.Millisecond
+
+	t.Run("readers share the lock", func(t *testing.T) {
+		appPaths := paths.Paths{AppHome: t.TempDir()}
+		reader := &BackendLock{}
+		unlockFirst, err := reader.RLock(context.Background(), appPaths)
+		require.NoError(t, err)
+		defer unlockFirst()
+		ctx, cancel := context.WithTimeout(context.Background(), shortWait)
+		defer cancel()
+		unlockSecond, err := reader.RLock(ctx, appPaths)
+		require.NoError(t, err)
+		unlockSecond()
+	})
+
+	t.Run("a reader blocks a writer", func(t *testing.T) {
+		appPaths := paths.Paths{AppHome: t.TempDir()}
+		unlock, err := (&BackendLock{}).RLock(context.Background(), appPaths)
+		require.NoError(t, err)
+		writer := &BackendLock{}
+		err = writer.lockExclusive(context.Background(), appPaths, shortWait)
+		assert.ErrorContains(t, err, "timed out waiting for read-only operations to finish")
+		assert.Nil(t, writer.rwLock)
+
+		unlock()
+		require.NoError(t, writer.lockExclusive(context.Background(), appPaths, shortWait))
+		writer.unlockExclusive()
+	})
+
+	t.Run("a writer blocks readers", func(t *testing.T) {
+		appPaths := paths.Paths{AppHome: t.TempDir()}
+		writer := &BackendLock{}
+		require.NoError(t, writer.lockExclusive(context.Background(), appPaths, shortWait))
+		ctx, cancel := context.WithTimeout(context.Background(), shortWait)
+		defer cancel()
+		_, err := (&BackendLock{}).RLock(ctx, appPaths)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		writer.unlockExclusive()
+		unlock, err := (&BackendLock{}).RLock(context.Background(), appPaths)
+		require.NoError(t, err)
+		unlock()
+	})
+
+	t.Run("a writer blocks other writers", func(t *testing.T) {
+		appPaths := paths.Paths{AppHome: t.TempDir()}
+		writer := &BackendLock{}
+		require.NoError(t, writer.lockExclusive(context.Background(), appPaths, shortWait))
+		defer writer.unlockExclusive()
+		assert.Error(t, (&BackendLock{}).lockExclusive(context.Background(), appPaths, shortWait))
+	})
+
+	t.Run("a reader waits for the writer to finish", func(t *testing.T) {
+		appPaths := paths.Paths{AppHome: t.TempDir()}
+		writer := &BackendLock{}
+		require.NoError(t, writer.lockExclusive(context.Background(), appPaths, shortWait))
+		acquired := make(chan error, 1)
+		go func() {
+			unlock, err := (&BackendLock{}).RLock(context.Background(), appPaths)
+			if err == nil {
+				unlock()
+			}
+			acquired <- err
+		}()
+		select {
+		case err := <-acquired:
+			require.Failf(t, "reader acquired the lock held by a writer", "error: %v", err)
+		case <-time.After(shortWait):
+		}
+		writer.unlockExclusive()
+		select {
+		case err := <-acquired:
+			assert.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			require.Fail(t, "reader did not acquire the lock once the writer released it")
+		}
+	})
+}
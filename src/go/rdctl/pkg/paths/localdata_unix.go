@@ -0,0 +1,40 @@
+//go:build linux || darwin
+
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ensurePrivateDir creates the given directory, readable only by the current
+// user, if it doesn't exist; if it does, it checks that it is a directory
+// (not a symbolic link) that belongs to the current user and that no one else
+// can access.  The default local data directory has a predictable name in a
+// directory shared between users, so another user could have created it
+// first.
+func ensurePrivateDir(dir string) error {
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create local data directory %q: %w", dir, err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check local data directory %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("local data directory %q is not a directory; remove it, or set %s", dir, LocalDataEnvVar)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("failed to get the owner of local data directory %q", dir)
+	}
+	if uid := os.Getuid(); int(stat.Uid) != uid {
+		return fmt.Errorf("local data directory %q belongs to user %d instead of %d; remove it, or set %s", dir, stat.Uid, uid, LocalDataEnvVar)
+	}
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		return fmt.Errorf("local data directory %q can be accessed by other users (mode %#o); run `chmod 700` on it", dir, mode)
+	}
+	return nil
+}
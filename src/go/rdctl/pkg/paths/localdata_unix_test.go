@@ -0,0 +1,52 @@
+//go:build linux || darwin
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsurePrivateDir(t *testing.T) {
+	t.Run("creates a private directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "local")
+		if err := ensurePrivateDir(dir); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatalf("Failed to stat directory: %s", err)
+		}
+		if mode := info.Mode().Perm(); mode != 0o700 {
+			t.Errorf("Expected mode 0700, got %#o", mode)
+		}
+		if err := ensurePrivateDir(dir); err != nil {
+			t.Errorf("Unexpected error for an existing private directory: %s", err)
+		}
+	})
+
+	t.Run("rejects a directory others can access", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "local")
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			t.Fatalf("Failed to create directory: %s", err)
+		}
+		if err := os.Chmod(dir, 0o777); err != nil {
+			t.Fatalf("Failed to change mode: %s", err)
+		}
+		if err := ensurePrivateDir(dir); err == nil || !strings.Contains(err.Error(), "can be accessed by other users") {
+			t.Errorf("Expected an error about the mode, got %v", err)
+		}
+	})
+
+	t.Run("rejects a symbolic link", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "local")
+		if err := os.Symlink(t.TempDir(), dir); err != nil {
+			t.Fatalf("Failed to create symlink: %s", err)
+		}
+		if err := ensurePrivateDir(dir); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+			t.Errorf("Expected an error about the symlink, got %v", err)
+		}
+	})
+}
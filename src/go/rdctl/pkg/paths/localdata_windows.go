@@ -0,0 +1,65 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// privateDirSDDL is the security descriptor of the local data directory: it
+// belongs to the user, and only they, SYSTEM and administrators can access it.
+// Inherited permissions (which, in ProgramData, let all users read it) are
+// blocked.
+const privateDirSDDL = "O:%[1]sD:P(A;OICI;FA;;;%[1]s)(A;OICI;FA;;;SY)(A;OICI;FA;;;BA)"
+
+// ensurePrivateDir creates the given directory, accessible only by the
+// current user, if it doesn't exist; if it does, it checks that it is a
+// directory (not a link) with exactly those permissions.  The default local
+// data directory has a predictable name in a directory shared between users,
+// so another user could have created it first.
+func ensurePrivateDir(dir string) error {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+	expected, err := windows.SecurityDescriptorFromString(fmt.Sprintf(privateDirSDDL, user.User.Sid.String()))
+	if err != nil {
+		return fmt.Errorf("failed to build security descriptor for %q: %w", dir, err)
+	}
+	dirPtr, err := windows.UTF16PtrFromString(LongPath(dir))
+	if err != nil {
+		return err
+	}
+	attributes := windows.SecurityAttributes{SecurityDescriptor: expected}
+	attributes.Length = uint32(unsafe.Sizeof(attributes))
+	err = windows.CreateDirectory(dirPtr, &attributes)
+	if err != nil && !errors.Is(err, windows.ERROR_ALREADY_EXISTS) {
+		return fmt.Errorf("failed to create local data directory %q: %w", dir, err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check local data directory %q: %w", dir, err)
+	}
+	if !info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("local data directory %q is not a directory; remove it, or set %s", dir, LocalDataEnvVar)
+	}
+	actual, err := windows.GetNamedSecurityInfo(LongPath(dir), windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return fmt.Errorf("failed to get permissions of local data directory %q: %w", dir, err)
+	}
+	owner, _, err := actual.Owner()
+	if err != nil {
+		return fmt.Errorf("failed to get the owner of local data directory %q: %w", dir, err)
+	}
+	if !owner.Equals(user.User.Sid) {
+		return fmt.Errorf("local data directory %q belongs to %s instead of the current user; remove it, or set %s", dir, owner, LocalDataEnvVar)
+	}
+	if actual.String() != expected.String() {
+		return fmt.Errorf("local data directory %q can be accessed by other users (%s); remove it, or set %s", dir, actual, LocalDataEnvVar)
+	}
+	return nil
+}
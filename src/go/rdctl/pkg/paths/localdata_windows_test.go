@@ -0,0 +1,19 @@
+package paths
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsurePrivateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "local")
+	if err := ensurePrivateDir(dir); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := ensurePrivateDir(dir); err != nil {
+		t.Errorf("Unexpected error for an existing private directory: %s", err)
+	}
+	if err := ensurePrivateDir(t.TempDir()); err == nil {
+		t.Errorf("Expected an error for a directory with inherited permissions")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package paths
+
+// LongPath returns the path unchanged; only Windows limits the length of
+// paths.
+func LongPath(path string) string {
+	return path
+}
+
+// ShortPath returns the path unchanged; only Windows has extended-length
+// paths.
+func ShortPath(path string) string {
+	return path
+}
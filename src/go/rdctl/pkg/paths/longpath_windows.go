@@ -0,0 +1,47 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxShortPath is the length from which Win32 calls need paths in the
+	// extended-length form: MAX_PATH, less room for an 8.3 file name.  The os
+	// package uses the same limit.
+	maxShortPath = 248
+
+	extendedPrefix    = `\\?\`
+	extendedUNCPrefix = `\\?\UNC\`
+)
+
+// LongPath returns the path in the extended-length form (\\?\C:\...) if it is
+// too long for Win32 calls without it.  The os package does this by itself;
+// this is for paths passed directly to the system.  The extended form can't
+// be relative, so relative paths are made absolute.
+func LongPath(path string) string {
+	for _, prefix := range []string{extendedPrefix, `\\.\`, `\??\`} {
+		if strings.HasPrefix(path, prefix) {
+			return path
+		}
+	}
+	// The extended form also disables the handling of "." and ".." and of
+	// forward slashes, which Abs takes care of.
+	absPath, err := filepath.Abs(path)
+	if err != nil || len(absPath) < maxShortPath {
+		return path
+	}
+	if strings.HasPrefix(absPath, `\\`) {
+		return extendedUNCPrefix + absPath[2:]
+	}
+	return extendedPrefix + absPath
+}
+
+// ShortPath removes the extended-length prefix from the path, for programs
+// and checks that don't expect it.
+func ShortPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, extendedUNCPrefix); ok {
+		return `\\` + rest
+	}
+	return strings.TrimPrefix(path, extendedPrefix)
+}
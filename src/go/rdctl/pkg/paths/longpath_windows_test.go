@@ -0,0 +1,38 @@
+package paths
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	deep := `C:\Users\someone\AppData\Local\rancher-desktop\extensions\` + strings.Repeat(`node_modules\package\`, 12) + "index.js"
+	deepShare := `\\server\share\` + strings.Repeat(`node_modules\package\`, 12) + "index.js"
+	testCases := map[string]string{
+		`C:\Users\someone\AppData\Local\rancher-desktop`: `C:\Users\someone\AppData\Local\rancher-desktop`,
+		deep:                               `\\?\` + deep,
+		strings.ReplaceAll(deep, `\`, "/"): `\\?\` + deep,
+		deepShare:                          `\\?\UNC\` + deepShare[2:],
+		`\\?\` + deep:                      `\\?\` + deep,
+		`\\.\pipe\rancher-desktop`:         `\\.\pipe\rancher-desktop`,
+	}
+	for input, expected := range testCases {
+		if actual := LongPath(input); actual != expected {
+			t.Errorf("LongPath(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
+
+func TestShortPath(t *testing.T) {
+	testCases := map[string]string{
+		`C:\Users\someone`:             `C:\Users\someone`,
+		`\\?\C:\Users\someone`:         `C:\Users\someone`,
+		`\\?\UNC\server\share\someone`: `\\server\share\someone`,
+		`\\.\pipe\rancher-desktop`:     `\\.\pipe\rancher-desktop`,
+	}
+	for input, expected := range testCases {
+		if actual := ShortPath(input); actual != expected {
+			t.Errorf("ShortPath(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
@@ -5,10 +5,32 @@ import (
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 const appName = "rancher-desktop"
 
+// InstanceEnvVar is the environment variable that selects a Rancher Desktop
+// instance. Each named instance gets its own data, configuration, cache, logs
+// and Lima home (and therefore its own rd-engine.json and lock files); when
+// the variable is unset, the default instance with the usual locations is used.
+// Using an environment variable means the setting is inherited by the
+// application (which calls `rdctl paths`) and by any helpers it runs.
+const InstanceEnvVar = "RD_INSTANCE"
+
+// LocalDataEnvVar is the environment variable that overrides where the
+// large, frequently written data (the VM disks and the cache) is kept.  By
+// default this is inside the home directory, unless the home directory is on
+// a network file system: running VM disks over NFS or SMB corrupts them, so in
+// that case a directory on a local disk is used instead.
+const LocalDataEnvVar = "RD_LOCAL_DATA_DIR"
+
+// Instance names end up in directory names, and (via the Lima home) in socket
+// paths that have tight length limits, so keep them short and simple.
+const maxInstanceNameLength = 16
+
+var instanceNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
 type Paths struct {
 	// Main location for application data.
 	AppHome string `json:"appHome"`
@@ -38,6 +60,10 @@ type Paths struct {
 	ExtensionRoot string `json:"extensionRoot"`
 	// Directory that holds snapshots
 	Snapshots string `json:"snapshots,omitempty"`
+	// Directories in the usual locations that still hold VM disks or cache
+	// files after those moved to the local data directory (see
+	// LocalDataEnvVar); they are no longer used.
+	StaleData []string `json:"staleData,omitempty"`
 }
 
 func getResourcesPath() (string, error) {
@@ -51,3 +77,126 @@ func getResourcesPath() (string, error) {
 	}
 	return utils.GetParentDir(rdctlPath, 3), nil
 }
+
+// getHomeDir returns the user's home directory with any symbolic links
+// resolved, so that the paths we report match the ones the application and
+// the VM see.  If the directory can't be resolved (e.g. it doesn't exist yet),
+// it is returned unchanged.
+func getHomeDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(homeDir); err == nil {
+		return resolved, nil
+	}
+	return homeDir, nil
+}
+
+// getLocalDataDir returns the directory that should hold the VM disks and the
+// cache instead of their usual locations, or an empty string if they should
+// stay where they are.  The directory given in LocalDataEnvVar always wins;
+// otherwise a local directory is only used if dataDir is on a network file
+// system, and is created (or checked) so that only the current user can
+// access it.
+func getLocalDataDir(dataDir, instanceName string) (string, error) {
+	if localDataDir := os.Getenv(LocalDataEnvVar); localDataDir != "" {
+		if !filepath.IsAbs(localDataDir) {
+			return "", fmt.Errorf("%s must be an absolute path, got %q", LocalDataEnvVar, localDataDir)
+		}
+		return filepath.Clean(localDataDir), nil
+	}
+	isNetwork, err := isNetworkPath(existingAncestor(dataDir))
+	if err != nil || !isNetwork {
+		// Failing to detect the file system shouldn't stop the application
+		// from starting; assume it's local, as we did before.
+		return "", nil
+	}
+	localDataDir, err := defaultLocalDataDir(instanceName)
+	if err != nil {
+		return "", err
+	}
+	if err := ensurePrivateDir(localDataDir); err != nil {
+		return "", err
+	}
+	return localDataDir, nil
+}
+
+// existingPaths returns those of the given paths that exist.
+func existingPaths(paths ...string) []string {
+	var result []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// existingAncestor returns the closest ancestor of the given path (or the path
+// itself) that exists, as the file system can only be queried for those.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// ValidateInstanceName checks that the given instance name is usable.
+func ValidateInstanceName(name string) error {
+	if len(name) > maxInstanceNameLength {
+		return fmt.Errorf("invalid instance name %q: max length is %d", name, maxInstanceNameLength)
+	}
+	if !instanceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: must consist of lower-case letters, digits, and dashes, and not start with a dash", name)
+	}
+	return nil
+}
+
+// WSL distribution names of the default instance; named instances add
+// their suffix (see InstanceSuffix).
+const (
+	mainDistroName = "rancher-desktop"
+	dataDistroName = "rancher-desktop-data"
+)
+
+// MainDistro returns the name of the WSL distribution the current instance
+// runs in.  This must match wslDistroNames in utils/instance.ts.
+func MainDistro() string {
+	return mainDistroName + distroSuffix()
+}
+
+// DataDistro returns the name of the WSL distribution holding the data of the
+// current instance.
+func DataDistro() string {
+	return dataDistroName + distroSuffix()
+}
+
+// distroSuffix is like InstanceSuffix, but an invalid instance name is used
+// as is: the distribution then can't be found, rather than the default
+// instance's being used by mistake.  Commands check the name beforehand.
+func distroSuffix() string {
+	if instance := os.Getenv(InstanceEnvVar); instance != "" {
+		return "-" + instance
+	}
+	return ""
+}
+
+// InstanceSuffix returns the suffix to add to per-instance directory names;
+// it is empty for the default instance.
+func InstanceSuffix() (string, error) {
+	instance := os.Getenv(InstanceEnvVar)
+	if instance == "" {
+		return "", nil
+	}
+	if err := ValidateInstanceName(instance); err != nil {
+		return "", err
+	}
+	return "-" + instance, nil
+}
@@ -5,8 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"golang.org/x/sys/unix"
 )
 
+// Names of the network file systems that can't be trusted to hold VM disks.
+var networkFilesystemTypes = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+}
+
 func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 	var getResourcesPathFunc func() (string, error)
 	switch len(getResourcesPathFuncs) {
@@ -18,17 +28,22 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 		return Paths{}, errors.New("you can only pass one function in getResourcesPathFuncs arg")
 	}
 
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := getHomeDir()
 	if err != nil {
-		return Paths{}, fmt.Errorf("failed to get user home directory: %w", err)
+		return Paths{}, err
 	}
-	appHome := filepath.Join(homeDir, "Library", "Application Support", appName)
-	altAppHome := filepath.Join(homeDir, ".rd")
+	suffix, err := InstanceSuffix()
+	if err != nil {
+		return Paths{}, err
+	}
+	instanceName := appName + suffix
+	appHome := filepath.Join(homeDir, "Library", "Application Support", instanceName)
+	altAppHome := filepath.Join(homeDir, ".rd"+suffix)
 	paths := Paths{
 		AppHome:                 appHome,
 		AltAppHome:              altAppHome,
-		Config:                  filepath.Join(homeDir, "Library", "Preferences", appName),
-		Cache:                   filepath.Join(homeDir, "Library", "Caches", appName),
+		Config:                  filepath.Join(homeDir, "Library", "Preferences", instanceName),
+		Cache:                   filepath.Join(homeDir, "Library", "Caches", instanceName),
 		Lima:                    filepath.Join(appHome, "lima"),
 		Integration:             filepath.Join(altAppHome, "bin"),
 		DeploymentProfileSystem: filepath.Join("/Library", "Preferences"),
@@ -36,9 +51,18 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 		ExtensionRoot:           filepath.Join(appHome, "extensions"),
 		Snapshots:               filepath.Join(appHome, "snapshots"),
 	}
+	localDataDir, err := getLocalDataDir(appHome, instanceName)
+	if err != nil {
+		return Paths{}, err
+	}
+	if localDataDir != "" {
+		paths.StaleData = existingPaths(paths.Lima, paths.Cache)
+		paths.Lima = filepath.Join(localDataDir, "lima")
+		paths.Cache = filepath.Join(localDataDir, "cache")
+	}
 	paths.Logs = os.Getenv("RD_LOGS_DIR")
 	if paths.Logs == "" {
-		paths.Logs = filepath.Join(homeDir, "Library", "Logs", appName)
+		paths.Logs = filepath.Join(homeDir, "Library", "Logs", instanceName)
 	}
 	paths.Resources, err = getResourcesPathFunc()
 	if err != nil {
@@ -47,3 +71,17 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 
 	return paths, nil
 }
+
+func isNetworkPath(path string) (bool, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return false, fmt.Errorf("failed to get file system information for %q: %w", path, err)
+	}
+	return networkFilesystemTypes[unix.ByteSliceToString(stat.Fstypename[:])], nil
+}
+
+// defaultLocalDataDir returns the local directory for the VM disks and cache
+// when the home directory is on a network file system.
+func defaultLocalDataDir(instanceName string) (string, error) {
+	return filepath.Join("/Users", "Shared", fmt.Sprintf("%s-%d", instanceName, os.Getuid())), nil
+}
@@ -3,12 +3,15 @@ package paths
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
 func TestGetPaths(t *testing.T) {
 	t.Run("should return correct paths without environment variables set", func(t *testing.T) {
+		t.Setenv("RD_INSTANCE", "")
 		t.Setenv("RD_LOGS_DIR", "")
+		t.Setenv(LocalDataEnvVar, "")
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			t.Errorf("Unexpected error getting user home directory: %s", err)
@@ -31,7 +34,7 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
@@ -42,7 +45,9 @@ func TestGetPaths(t *testing.T) {
 			t.Errorf("Unexpected error getting user home directory: %s", err)
 		}
 		rdLogsDir := filepath.Join(homeDir, "anotherLogsDir")
+		t.Setenv("RD_INSTANCE", "")
 		t.Setenv("RD_LOGS_DIR", rdLogsDir)
+		t.Setenv(LocalDataEnvVar, "")
 		expectedPaths := Paths{
 			AppHome:                 filepath.Join(homeDir, "Library", "Application Support", appName),
 			AltAppHome:              filepath.Join(homeDir, ".rd"),
@@ -61,7 +66,7 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
@@ -5,8 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"golang.org/x/sys/unix"
 )
 
+// Magic numbers (from statfs(2)) of the network file systems that can't be
+// trusted to hold VM disks.
+var networkFilesystemTypes = map[uint32]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x5346414F: true, // AFS
+	0x00C36400: true, // Ceph
+}
+
 func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 	var getResourcesPathFunc func() (string, error)
 	switch len(getResourcesPathFuncs) {
@@ -18,10 +31,15 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 		return Paths{}, errors.New("you can only pass one function in getResourcesPathFuncs arg")
 	}
 
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := getHomeDir()
 	if err != nil {
-		return Paths{}, fmt.Errorf("failed to get user home directory: %w", err)
+		return Paths{}, err
 	}
+	suffix, err := InstanceSuffix()
+	if err != nil {
+		return Paths{}, err
+	}
+	instanceName := appName + suffix
 	dataHome := os.Getenv("XDG_DATA_HOME")
 	if dataHome == "" {
 		dataHome = filepath.Join(homeDir, ".local", "share")
@@ -34,22 +52,31 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 	if cacheHome == "" {
 		cacheHome = filepath.Join(homeDir, ".cache")
 	}
-	altAppHome := filepath.Join(homeDir, ".rd")
+	altAppHome := filepath.Join(homeDir, ".rd"+suffix)
 	paths := Paths{
-		AppHome:                 filepath.Join(dataHome, appName),
+		AppHome:                 filepath.Join(dataHome, instanceName),
 		AltAppHome:              altAppHome,
-		Config:                  filepath.Join(configHome, appName),
-		Cache:                   filepath.Join(cacheHome, appName),
-		Lima:                    filepath.Join(dataHome, appName, "lima"),
+		Config:                  filepath.Join(configHome, instanceName),
+		Cache:                   filepath.Join(cacheHome, instanceName),
+		Lima:                    filepath.Join(dataHome, instanceName, "lima"),
 		Integration:             filepath.Join(altAppHome, "bin"),
 		DeploymentProfileSystem: filepath.Join("/etc", appName),
 		DeploymentProfileUser:   configHome,
-		ExtensionRoot:           filepath.Join(dataHome, appName, "extensions"),
-		Snapshots:               filepath.Join(dataHome, appName, "snapshots"),
+		ExtensionRoot:           filepath.Join(dataHome, instanceName, "extensions"),
+		Snapshots:               filepath.Join(dataHome, instanceName, "snapshots"),
+	}
+	localDataDir, err := getLocalDataDir(paths.AppHome, instanceName)
+	if err != nil {
+		return Paths{}, err
+	}
+	if localDataDir != "" {
+		paths.StaleData = existingPaths(paths.Lima, paths.Cache)
+		paths.Lima = filepath.Join(localDataDir, "lima")
+		paths.Cache = filepath.Join(localDataDir, "cache")
 	}
 	paths.Logs = os.Getenv("RD_LOGS_DIR")
 	if paths.Logs == "" {
-		paths.Logs = filepath.Join(dataHome, appName, "logs")
+		paths.Logs = filepath.Join(dataHome, instanceName, "logs")
 	}
 	paths.Resources, err = getResourcesPathFunc()
 	if err != nil {
@@ -58,3 +85,18 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 
 	return paths, nil
 }
+
+func isNetworkPath(path string) (bool, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return false, fmt.Errorf("failed to get file system information for %q: %w", path, err)
+	}
+	return networkFilesystemTypes[uint32(stat.Type)], nil
+}
+
+// defaultLocalDataDir returns the local directory for the VM disks and cache
+// when the home directory is on a network file system.  /var/tmp persists
+// across reboots, unlike /tmp.
+func defaultLocalDataDir(instanceName string) (string, error) {
+	return filepath.Join("/var/tmp", fmt.Sprintf("%s-%d", instanceName, os.Getuid())), nil
+}
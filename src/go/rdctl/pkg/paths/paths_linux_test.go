@@ -3,6 +3,7 @@ package paths
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -10,7 +11,9 @@ func TestGetPaths(t *testing.T) {
 	t.Run("should return correct paths without environment variables set", func(t *testing.T) {
 		// Ensure that these variables are not set in the testing environment
 		environment := map[string]string{
+			"RD_INSTANCE":     "",
 			"RD_LOGS_DIR":     "",
+			LocalDataEnvVar:   "",
 			"XDG_DATA_HOME":   "",
 			"XDG_CONFIG_HOME": "",
 			"XDG_CACHE_HOME":  "",
@@ -41,7 +44,7 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
@@ -52,7 +55,9 @@ func TestGetPaths(t *testing.T) {
 			t.Errorf("Unexpected error getting user home directory: %s", err)
 		}
 		environment := map[string]string{
+			"RD_INSTANCE":     "",
 			"RD_LOGS_DIR":     filepath.Join(homeDir, "anotherLogsDir"),
+			LocalDataEnvVar:   "",
 			"XDG_DATA_HOME":   filepath.Join(homeDir, "anotherDataHome"),
 			"XDG_CONFIG_HOME": filepath.Join(homeDir, "anotherConfigHome"),
 			"XDG_CACHE_HOME":  filepath.Join(homeDir, "anotherCacheHome"),
@@ -79,8 +84,109 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
+
+	t.Run("should return separate paths for a named instance", func(t *testing.T) {
+		environment := map[string]string{
+			"RD_INSTANCE":     "second",
+			"RD_LOGS_DIR":     "",
+			LocalDataEnvVar:   "",
+			"XDG_DATA_HOME":   "",
+			"XDG_CONFIG_HOME": "",
+			"XDG_CACHE_HOME":  "",
+		}
+		for key, value := range environment {
+			t.Setenv(key, value)
+		}
+
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			t.Errorf("Unexpected error getting user home directory: %s", err)
+		}
+		instanceName := appName + "-second"
+		expectedPaths := Paths{
+			AppHome:                 filepath.Join(homeDir, ".local/share", instanceName),
+			AltAppHome:              filepath.Join(homeDir, ".rd-second"),
+			Config:                  filepath.Join(homeDir, ".config", instanceName),
+			Logs:                    filepath.Join(homeDir, ".local/share", instanceName, "logs"),
+			Cache:                   filepath.Join(homeDir, ".cache", instanceName),
+			Lima:                    filepath.Join(homeDir, ".local/share", instanceName, "lima"),
+			Integration:             filepath.Join(homeDir, ".rd-second/bin"),
+			Resources:               fakeResourcesPath,
+			DeploymentProfileSystem: filepath.Join("/etc", appName),
+			DeploymentProfileUser:   filepath.Join(homeDir, ".config"),
+			ExtensionRoot:           filepath.Join(homeDir, ".local/share", instanceName, "extensions"),
+			Snapshots:               filepath.Join(homeDir, ".local/share", instanceName, "snapshots"),
+		}
+		actualPaths, err := GetPaths(mockGetResourcesPath)
+		if err != nil {
+			t.Errorf("Unexpected error getting actual paths: %s", err)
+		}
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
+			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
+		}
+	})
+
+	t.Run("should resolve a symlinked home directory", func(t *testing.T) {
+		realHome := filepath.Join(t.TempDir(), "real")
+		if err := os.Mkdir(realHome, 0o755); err != nil {
+			t.Fatalf("Failed to create home directory: %s", err)
+		}
+		realHome, err := filepath.EvalSymlinks(realHome)
+		if err != nil {
+			t.Fatalf("Failed to resolve home directory: %s", err)
+		}
+		linkedHome := filepath.Join(t.TempDir(), "link")
+		if err := os.Symlink(realHome, linkedHome); err != nil {
+			t.Fatalf("Failed to create symlink: %s", err)
+		}
+		for key, value := range map[string]string{
+			"HOME":            linkedHome,
+			"RD_INSTANCE":     "",
+			LocalDataEnvVar:   "",
+			"XDG_DATA_HOME":   "",
+			"XDG_CONFIG_HOME": "",
+			"XDG_CACHE_HOME":  "",
+		} {
+			t.Setenv(key, value)
+		}
+		actualPaths, err := GetPaths(mockGetResourcesPath)
+		if err != nil {
+			t.Fatalf("Unexpected error getting actual paths: %s", err)
+		}
+		if expected := filepath.Join(realHome, ".local/share", appName); actualPaths.AppHome != expected {
+			t.Errorf("Expected app home %q, got %q", expected, actualPaths.AppHome)
+		}
+	})
+
+	t.Run("should relocate the VM and cache to the local data directory", func(t *testing.T) {
+		localDataDir := t.TempDir()
+		t.Setenv("RD_INSTANCE", "")
+		t.Setenv(LocalDataEnvVar, localDataDir)
+		actualPaths, err := GetPaths(mockGetResourcesPath)
+		if err != nil {
+			t.Fatalf("Unexpected error getting actual paths: %s", err)
+		}
+		if expected := filepath.Join(localDataDir, "lima"); actualPaths.Lima != expected {
+			t.Errorf("Expected Lima directory %q, got %q", expected, actualPaths.Lima)
+		}
+		if expected := filepath.Join(localDataDir, "cache"); actualPaths.Cache != expected {
+			t.Errorf("Expected cache directory %q, got %q", expected, actualPaths.Cache)
+		}
+
+		t.Setenv(LocalDataEnvVar, "relative/path")
+		if _, err := GetPaths(mockGetResourcesPath); err == nil {
+			t.Errorf("Expected an error for a relative local data directory")
+		}
+	})
+
+	t.Run("should reject an invalid instance name", func(t *testing.T) {
+		t.Setenv("RD_INSTANCE", "../escape")
+		if _, err := GetPaths(mockGetResourcesPath); err == nil {
+			t.Errorf("Expected an error for an invalid instance name")
+		}
+	})
 }
@@ -1,7 +1,33 @@
 package paths
 
+import "testing"
+
 const fakeResourcesPath = "fakePath"
 
 func mockGetResourcesPath() (string, error) {
 	return fakeResourcesPath, nil
 }
+
+func TestValidateInstanceName(t *testing.T) {
+	for _, name := range []string{"a", "second", "ci-runner-2", "0123456789abcdef"} {
+		if err := ValidateInstanceName(name); err != nil {
+			t.Errorf("Unexpected error validating instance name %q: %s", name, err)
+		}
+	}
+	for _, name := range []string{"", "-leading", "Upper", "has space", "a/b", "..", "0123456789abcdefg"} {
+		if err := ValidateInstanceName(name); err == nil {
+			t.Errorf("Expected an error validating instance name %q", name)
+		}
+	}
+}
+
+func TestDistroNames(t *testing.T) {
+	t.Setenv(InstanceEnvVar, "")
+	if MainDistro() != "rancher-desktop" || DataDistro() != "rancher-desktop-data" {
+		t.Errorf("Unexpected distributions for the default instance: %q, %q", MainDistro(), DataDistro())
+	}
+	t.Setenv(InstanceEnvVar, "lab")
+	if MainDistro() != "rancher-desktop-lab" || DataDistro() != "rancher-desktop-data-lab" {
+		t.Errorf("Unexpected distributions for a named instance: %q, %q", MainDistro(), DataDistro())
+	}
+}
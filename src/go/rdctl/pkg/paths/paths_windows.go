@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
 )
 
 func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
@@ -18,28 +21,43 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 		return Paths{}, errors.New("you can only pass one function in getResourcesPathFuncs arg")
 	}
 
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := getHomeDir()
+	if err != nil {
+		return Paths{}, err
+	}
+	suffix, err := InstanceSuffix()
 	if err != nil {
-		return Paths{}, fmt.Errorf("failed to get user home directory: %w", err)
+		return Paths{}, err
 	}
+	instanceName := appName + suffix
 	localAppData := os.Getenv("LOCALAPPDATA")
 	if localAppData == "" {
 		localAppData = filepath.Join(homeDir, "AppData", "Local")
 	}
-	appHome := filepath.Join(localAppData, appName)
+	appHome := filepath.Join(localAppData, instanceName)
 	paths := Paths{
 		AppHome:       appHome,
 		AltAppHome:    appHome,
 		Config:        appHome,
-		Cache:         filepath.Join(localAppData, appName, "cache"),
-		WslDistro:     filepath.Join(localAppData, appName, "distro"),
-		WslDistroData: filepath.Join(localAppData, appName, "distro-data"),
-		ExtensionRoot: filepath.Join(localAppData, appName, "extensions"),
-		Snapshots:     filepath.Join(localAppData, appName, "snapshots"),
+		Cache:         filepath.Join(appHome, "cache"),
+		WslDistro:     filepath.Join(appHome, "distro"),
+		WslDistroData: filepath.Join(appHome, "distro-data"),
+		ExtensionRoot: filepath.Join(appHome, "extensions"),
+		Snapshots:     filepath.Join(appHome, "snapshots"),
+	}
+	localDataDir, err := getLocalDataDir(appHome, instanceName)
+	if err != nil {
+		return Paths{}, err
+	}
+	if localDataDir != "" {
+		paths.StaleData = existingPaths(paths.Cache, paths.WslDistro, paths.WslDistroData)
+		paths.Cache = filepath.Join(localDataDir, "cache")
+		paths.WslDistro = filepath.Join(localDataDir, "distro")
+		paths.WslDistroData = filepath.Join(localDataDir, "distro-data")
 	}
 	paths.Logs = os.Getenv("RD_LOGS_DIR")
 	if paths.Logs == "" {
-		paths.Logs = filepath.Join(localAppData, appName, "logs")
+		paths.Logs = filepath.Join(appHome, "logs")
 	}
 	paths.Resources, err = getResourcesPathFunc()
 	if err != nil {
@@ -48,3 +66,30 @@ func GetPaths(getResourcesPathFuncs ...func() (string, error)) (Paths, error) {
 
 	return paths, nil
 }
+
+func isNetworkPath(path string) (bool, error) {
+	// The volume of an extended-length path (\\?\C:\...) looks like a share.
+	volume := filepath.VolumeName(ShortPath(path))
+	if strings.HasPrefix(volume, `\\`) {
+		// UNC paths (\\server\share) are always remote.
+		return true, nil
+	}
+	if volume == "" {
+		return false, nil
+	}
+	root, err := windows.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return false, err
+	}
+	return windows.GetDriveType(root) == windows.DRIVE_REMOTE, nil
+}
+
+// defaultLocalDataDir returns the local directory for the WSL disks and cache
+// when the application data directory is on a network drive.
+func defaultLocalDataDir(instanceName string) (string, error) {
+	programData, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ProgramData directory: %w", err)
+	}
+	return filepath.Join(programData, fmt.Sprintf("%s-%s", instanceName, os.Getenv("USERNAME"))), nil
+}
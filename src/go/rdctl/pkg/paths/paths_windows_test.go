@@ -3,6 +3,7 @@ package paths
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -10,9 +11,11 @@ func TestGetPaths(t *testing.T) {
 	t.Run("should return correct paths without environment variables set", func(t *testing.T) {
 		// Ensure that these variables are not set in the testing environment
 		environment := map[string]string{
-			"RD_LOGS_DIR":  "",
-			"LOCALAPPDATA": "",
-			"APPDATA":      "",
+			"RD_INSTANCE":   "",
+			"RD_LOGS_DIR":   "",
+			LocalDataEnvVar: "",
+			"LOCALAPPDATA":  "",
+			"APPDATA":       "",
 		}
 		for key, value := range environment {
 			t.Setenv(key, value)
@@ -38,7 +41,7 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
@@ -49,9 +52,11 @@ func TestGetPaths(t *testing.T) {
 			t.Errorf("Unexpected error getting user home directory: %s", err)
 		}
 		environment := map[string]string{
-			"RD_LOGS_DIR":  filepath.Join(homeDir, "mockRdLogsDir"),
-			"LOCALAPPDATA": filepath.Join(homeDir, "mockLocalAppData"),
-			"APPDATA":      filepath.Join(homeDir, "mockAppData"),
+			"RD_INSTANCE":   "",
+			"RD_LOGS_DIR":   filepath.Join(homeDir, "mockRdLogsDir"),
+			LocalDataEnvVar: "",
+			"LOCALAPPDATA":  filepath.Join(homeDir, "mockLocalAppData"),
+			"APPDATA":       filepath.Join(homeDir, "mockAppData"),
 		}
 		for key, value := range environment {
 			t.Setenv(key, value)
@@ -73,7 +78,7 @@ func TestGetPaths(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error getting actual paths: %s", err)
 		}
-		if actualPaths != expectedPaths {
+		if !reflect.DeepEqual(actualPaths, expectedPaths) {
 			t.Errorf("Actual paths does not match expected paths\nActual paths: %#v\nExpected paths: %#v", actualPaths, expectedPaths)
 		}
 	})
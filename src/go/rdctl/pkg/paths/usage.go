@@ -0,0 +1,95 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DirectoryUsage is the disk space used by one of the directories in Paths.
+type DirectoryUsage struct {
+	// Name is the name of the directory in the JSON form of Paths.
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Size is the total apparent size of the files, as reported by `ls`.
+	Size int64 `json:"size"`
+	// Allocated is the disk space actually used by the files.  This is less
+	// than Size for sparse files (such as VM disks), and doesn't include
+	// blocks shared with files counted earlier (hard links and copy-on-write
+	// clones, such as snapshots of the VM disk, where this can be detected).
+	Allocated int64 `json:"allocated"`
+}
+
+// managedDirectory is a directory in Paths whose disk usage is reported.
+type managedDirectory struct {
+	name string
+	path string
+}
+
+// managedDirectories returns the directories to report disk usage for.  Some
+// of them are nested inside others (e.g. the Lima directory is normally in the
+// application home); these come first, so that the files in them are only
+// counted once.
+func (p Paths) managedDirectories() []managedDirectory {
+	return []managedDirectory{
+		{"lima", p.Lima},
+		{"wslDistro", p.WslDistro},
+		{"wslDistroData", p.WslDistroData},
+		{"snapshots", p.Snapshots},
+		{"cache", p.Cache},
+		{"extensionRoot", p.ExtensionRoot},
+		{"logs", p.Logs},
+		{"appHome", p.AppHome},
+		{"altAppHome", p.AltAppHome},
+		{"config", p.Config},
+	}
+}
+
+// DiskUsage returns the disk space used by each of the Rancher Desktop data
+// directories that exist.  Files in a directory nested inside another are only
+// counted for the innermost one.
+func (p Paths) DiskUsage() ([]DirectoryUsage, error) {
+	tracker := newUsageTracker()
+	measured := map[string]bool{}
+	var result []DirectoryUsage
+	for _, dir := range p.managedDirectories() {
+		if dir.path == "" || measured[dir.path] {
+			continue
+		}
+		if _, err := os.Stat(dir.path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		usage := DirectoryUsage{Name: dir.name, Path: dir.path}
+		err := filepath.WalkDir(dir.path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() && path != dir.path && measured[path] {
+				return filepath.SkipDir
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			usage.Size += info.Size()
+			usage.Allocated += tracker.allocated(path, info)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		measured[dir.path] = true
+		result = append(result, usage)
+	}
+	return result, nil
+}
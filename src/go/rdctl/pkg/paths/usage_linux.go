@@ -0,0 +1,72 @@
+package paths
+
+import (
+	"math"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Definitions from linux/fiemap.h, used to find the extents of a file that are
+// shared with other files (copy-on-write clones made by FICLONE on btrfs or XFS).
+const (
+	fsIocFiemap        = 0xC020660B
+	fiemapExtentLast   = 0x1
+	fiemapExtentShared = 0x2000
+	fiemapBatchSize    = 64
+)
+
+type fiemapExtent struct {
+	Logical    uint64
+	Physical   uint64
+	Length     uint64
+	reserved64 [2]uint64
+	Flags      uint32
+	reserved   [3]uint32
+}
+
+type fiemap struct {
+	Start         uint64
+	Length        uint64
+	Flags         uint32
+	MappedExtents uint32
+	ExtentCount   uint32
+	reserved      uint32
+	Extents       [fiemapBatchSize]fiemapExtent
+}
+
+// unsharedAllocation returns the disk space used by the file that isn't shared
+// with files counted earlier.  If the file system can't tell us which extents
+// are shared, the whole allocation is returned.
+func (tracker *usageTracker) unsharedAllocation(path string, allocated int64) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return allocated
+	}
+	defer file.Close()
+	var result int64
+	var start uint64
+	for {
+		request := fiemap{Start: start, Length: math.MaxUint64, ExtentCount: fiemapBatchSize}
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), fsIocFiemap, uintptr(unsafe.Pointer(&request)))
+		if errno != 0 {
+			return allocated
+		}
+		if request.MappedExtents == 0 {
+			return result
+		}
+		for _, extent := range request.Extents[:request.MappedExtents] {
+			if extent.Flags&fiemapExtentShared == 0 {
+				result += int64(extent.Length)
+			} else if !tracker.extents[extent.Physical] {
+				tracker.extents[extent.Physical] = true
+				result += int64(extent.Length)
+			}
+			if extent.Flags&fiemapExtentLast != 0 {
+				return result
+			}
+			start = extent.Logical + extent.Length
+		}
+	}
+}
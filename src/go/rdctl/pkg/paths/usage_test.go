@@ -0,0 +1,43 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskUsage(t *testing.T) {
+	appHome := t.TempDir()
+	lima := filepath.Join(appHome, "lima")
+	if err := os.MkdirAll(lima, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %s", err)
+	}
+	for path, size := range map[string]int{
+		filepath.Join(appHome, "settings.json"): 10,
+		filepath.Join(lima, "diffdisk"):         100,
+	} {
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %s", path, err)
+		}
+	}
+	appPaths := Paths{
+		AppHome: appHome,
+		Config:  appHome,
+		Lima:    lima,
+		Cache:   filepath.Join(appHome, "missing"),
+	}
+	usage, err := appPaths.DiskUsage()
+	if err != nil {
+		t.Fatalf("Unexpected error getting disk usage: %s", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Expected usage for the lima and app home directories, got %+v", usage)
+	}
+	if usage[0].Name != "lima" || usage[0].Size != 100 {
+		t.Errorf("Unexpected usage for the lima directory: %+v", usage[0])
+	}
+	// The Lima directory is nested in the app home, and must not be counted twice.
+	if usage[1].Name != "appHome" || usage[1].Size != 10 {
+		t.Errorf("Unexpected usage for the app home directory: %+v", usage[1])
+	}
+}
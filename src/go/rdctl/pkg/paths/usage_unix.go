@@ -0,0 +1,49 @@
+//go:build linux || darwin
+
+package paths
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Files smaller than this aren't checked for blocks shared with other files,
+// as it isn't worth the extra system calls.
+const minSharedCheckSize = 1 << 20
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// usageTracker remembers the files (and, where supported, the copy-on-write
+// extents) that have been counted, so that shared blocks are counted once.
+type usageTracker struct {
+	files   map[fileID]bool
+	extents map[uint64]bool
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{files: map[fileID]bool{}, extents: map[uint64]bool{}}
+}
+
+// allocated returns the disk space used by the given file that hasn't been
+// counted already.
+func (tracker *usageTracker) allocated(path string, info fs.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+	if stat.Nlink > 1 {
+		id := fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}
+		if tracker.files[id] {
+			return 0
+		}
+		tracker.files[id] = true
+	}
+	allocated := int64(stat.Blocks) * 512
+	if allocated < minSharedCheckSize {
+		return allocated
+	}
+	return tracker.unsharedAllocation(path, allocated)
+}
@@ -0,0 +1,41 @@
+package paths
+
+import (
+	"io/fs"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	dllKernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procGetCompressedFileSize = dllKernel32.NewProc("GetCompressedFileSizeW")
+)
+
+// invalidFileSize is returned by GetCompressedFileSizeW on failure.
+const invalidFileSize = 0xFFFFFFFF
+
+// usageTracker exists for parity with other platforms; hard links are rare in
+// the application data directories on Windows, so they aren't tracked.
+type usageTracker struct{}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{}
+}
+
+// allocated returns the disk space used by the given file.  The WSL disks are
+// sparse VHDX files, whose apparent size can be much larger than what they use.
+// The path goes straight to the system, so long paths (as in the node_modules
+// trees of extensions) must be in the extended-length form.
+func (tracker *usageTracker) allocated(path string, info fs.FileInfo) int64 {
+	pathPtr, err := windows.UTF16PtrFromString(LongPath(path))
+	if err != nil {
+		return info.Size()
+	}
+	var high uint32
+	low, _, err := procGetCompressedFileSize.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&high)))
+	if uint32(low) == invalidFileSize && err != windows.ERROR_SUCCESS {
+		return info.Size()
+	}
+	return int64(high)<<32 | int64(uint32(low))
+}
@@ -0,0 +1,151 @@
+package plist
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParsePlist parses an XML property list, returning its top-level value.
+// Dictionaries are returned as map[string]interface{}, arrays as []interface{},
+// integers as int64, reals as float64, booleans as bool, and strings, dates and
+// data as string.
+func ParsePlist(contents string) (interface{}, error) {
+	decoder := xml.NewDecoder(strings.NewReader(contents))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no plist element found")
+			}
+			return nil, err
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			if element.Name.Local != "plist" {
+				return nil, fmt.Errorf("expected a plist element, got %q", element.Name.Local)
+			}
+			value, err := parsePlistChild(decoder, element)
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return nil, errors.New("empty plist")
+			}
+			return value, nil
+		}
+	}
+}
+
+// parsePlistChild returns the value of the single element within the given
+// (already started) parent element; it returns nil if the parent is empty.
+func parsePlistChild(decoder *xml.Decoder, parent xml.StartElement) (interface{}, error) {
+	var result interface{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if result != nil {
+				return nil, fmt.Errorf("unexpected second value %q in %q", token.Name.Local, parent.Name.Local)
+			}
+			if result, err = parsePlistValue(decoder, token); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			return result, nil
+		}
+	}
+}
+
+func parsePlistValue(decoder *xml.Decoder, element xml.StartElement) (interface{}, error) {
+	switch element.Name.Local {
+	case "dict":
+		return parsePlistDict(decoder)
+	case "array":
+		result := []interface{}{}
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				value, err := parsePlistValue(decoder, token)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, value)
+			case xml.EndElement:
+				return result, nil
+			}
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return element.Name.Local == "true", nil
+	}
+	var text string
+	if err := decoder.DecodeElement(&text, &element); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch element.Name.Local {
+	case "string", "date", "data":
+		return text, nil
+	case "integer":
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q: %w", text, err)
+		}
+		return value, nil
+	case "real":
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q: %w", text, err)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported plist element %q", element.Name.Local)
+}
+
+func parsePlistDict(decoder *xml.Decoder) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	var key *string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if key == nil {
+				if token.Name.Local != "key" {
+					return nil, fmt.Errorf("expected a key in dict, got %q", token.Name.Local)
+				}
+				var name string
+				if err := decoder.DecodeElement(&name, &token); err != nil {
+					return nil, err
+				}
+				key = &name
+				continue
+			}
+			value, err := parsePlistValue(decoder, token)
+			if err != nil {
+				return nil, err
+			}
+			result[*key] = value
+			key = nil
+		case xml.EndElement:
+			if key != nil {
+				return nil, fmt.Errorf("missing value for key %q", *key)
+			}
+			return result, nil
+		}
+	}
+}
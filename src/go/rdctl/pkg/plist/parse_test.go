@@ -0,0 +1,45 @@
+package plist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlist(t *testing.T) {
+	t.Run("round-trips generated files", func(t *testing.T) {
+		jsonBody := `{"version": 9, "application": { "adminAccess": true, "extensions": { "allowed": { "list": ["wink", "a&b"] } } },
+			"containerEngine": { "name": "moby" }, "diagnostics": { "mutedChecks": { "fraction": 1.5 } } }`
+		contents, err := JsonToPlist(jsonBody)
+		require.NoError(t, err)
+		result, err := ParsePlist(contents)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"version": int64(9),
+			"application": map[string]interface{}{
+				"adminAccess": true,
+				"extensions": map[string]interface{}{
+					"allowed": map[string]interface{}{"list": []interface{}{"wink", "a&b"}},
+				},
+			},
+			"containerEngine": map[string]interface{}{"name": "moby"},
+			"diagnostics": map[string]interface{}{
+				"mutedChecks": map[string]interface{}{"fraction": 1.5},
+			},
+		}, result)
+	})
+
+	t.Run("handles empty dicts", func(t *testing.T) {
+		contents, err := JsonToPlist("{}")
+		require.NoError(t, err)
+		result, err := ParsePlist(contents)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{}, result)
+	})
+
+	t.Run("rejects malformed dicts", func(t *testing.T) {
+		_, err := ParsePlist(`<plist version="1.0"><dict><key>a</key></dict></plist>`)
+		assert.ErrorContains(t, err, `missing value for key "a"`)
+	})
+}
@@ -15,10 +15,13 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 
-	options "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 )
 
@@ -26,7 +29,7 @@ const indentChange = "  "
 
 // convertToPListLines recursively reflects the supplied value into lines for a plist
 
-// structType: type information on the current field for the `value` parameter
+// schema: the schema of the current setting, describing the `value` parameter
 // value: the reflected value of the current field, based on a simple map[string]interface{} JSON-parse
 // indent: the leading whitespace for each line so the generated XML is more readable
 // path: a dotted representation of the fully-qualified name of the field
@@ -36,34 +39,31 @@ const indentChange = "  "
 //	an array of lines representing the generated XML
 //	an error: the only non-nil error this function can return is when it encounters an unhandled data type
 
-func convertToPListLines(structType reflect.Type, value reflect.Value, indent, path string) ([]string, error) {
-	kind := structType.Kind()
-	if value.Kind() == reflect.Interface && kind != reflect.Interface {
+func convertToPListLines(schema *jsonpath.Schema, value reflect.Value, indent, path string) ([]string, error) {
+	if value.Kind() == reflect.Interface {
 		if value.IsNil() {
 			return nil, nil
 		}
-		return convertToPListLines(structType, value.Elem(), indent, path)
+		return convertToPListLines(schema, value.Elem(), indent, path)
 	}
 	if value.Kind() == reflect.Ptr {
-		return nil, fmt.Errorf("plist generation: got an unexpected pointer for %s value %v, expecting type %v", path, value, structType)
+		return nil, fmt.Errorf("plist generation: got an unexpected pointer for %s value %v", path, value)
 	}
-	switch kind {
-	case reflect.Struct:
+	switch schema.Kind {
+	case jsonpath.Group:
 		if value.Kind() != reflect.Map {
-			return nil, fmt.Errorf("expecting actual kind for a typed struct %s to be a map, got %v", path, value.Kind())
+			return nil, fmt.Errorf("expecting actual kind for a group of settings %s to be a map, got %v", path, value.Kind())
 		}
-		numTypedFields := structType.NumField()
 		returnedLines := []string{indent + "<dict>"}
-		// Typed fields are ordered according to options.ServerSettingsForJSON
-		// By walking the list of fields in the structure type, and expanding only those fields
+		// Settings are ordered according to the schema.
+		// By walking the list of fields in the schema, and expanding only those fields
 		// that are specified, we get a consistent order in the output
 		// (e.g. `updater` always appears before `autoStart` in `application`)
-		for i := 0; i < numTypedFields; i++ {
-			field := structType.Field(i)
-			fieldName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		for _, field := range schema.Fields {
+			fieldName := field.Name
 			valueElement := value.MapIndex(reflect.ValueOf(fieldName))
 			if valueElement.IsValid() {
-				newRetLines, err := convertToPListLines(field.Type, valueElement, indent+indentChange, path+"."+fieldName)
+				newRetLines, err := convertToPListLines(field.Schema, valueElement, indent+indentChange, path+"."+fieldName)
 				if err != nil {
 					return nil, err
 				}
@@ -76,9 +76,7 @@ func convertToPListLines(structType reflect.Type, value reflect.Value, indent, p
 		}
 		returnedLines = append(returnedLines, indent+"</dict>")
 		return returnedLines, nil
-	case reflect.Ptr:
-		return convertToPListLines(structType.Elem(), value, indent, path)
-	case reflect.Slice, reflect.Array:
+	case jsonpath.List:
 		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
 			return nil, fmt.Errorf("expected slice or array at %s, got %v", path, value.Kind())
 		}
@@ -99,12 +97,15 @@ func convertToPListLines(structType reflect.Type, value reflect.Value, indent, p
 		}
 		retLines[numValues+1] = indent + "</array>"
 		return retLines, nil
-	case reflect.Map:
+	case jsonpath.Map:
+		if value.Kind() != reflect.Map {
+			return nil, fmt.Errorf("expecting actual kind for free-form settings %s to be a map, got %v", path, value.Kind())
+		}
 		returnedLines := []string{indent + "<dict>"}
 		mapKeys := utils.SortKeys(value.MapKeys())
 		for _, mapKey := range mapKeys {
 			keyAsString := mapKey.StringKey
-			innerLines, err := convertToPListLines(structType.Elem(), value.MapIndex(mapKey.MapKey), indent+indentChange, path+"."+keyAsString)
+			innerLines, err := convertToPListLines(anySchema, value.MapIndex(mapKey.MapKey), indent+indentChange, path+"."+keyAsString)
 			if err != nil {
 				return nil, err
 			} else if len(innerLines) > 0 {
@@ -118,36 +119,43 @@ func convertToPListLines(structType reflect.Type, value reflect.Value, indent, p
 		}
 		returnedLines = append(returnedLines, indent+"</dict>")
 		return returnedLines, nil
-	case reflect.Interface:
+	case jsonpath.Any:
 		// Since we allow whatever here, just use the actual type of the value.
-		// But if it's an interface{} we'll need to dereference it first to avoid
-		// an infinite loop.
-		for value.Kind() == reflect.Interface {
-			value = value.Elem()
+		if valueSchema := jsonpath.SchemaOf(value.Interface()); valueSchema.Kind != jsonpath.Any {
+			return convertToPListLines(valueSchema, value, indent, path)
 		}
-		return convertToPListLines(value.Type(), value, indent, path)
-	case reflect.Bool:
+	case jsonpath.Bool:
 		boolValue := map[bool]string{true: "true", false: "false"}[value.Bool()]
 		return []string{fmt.Sprintf("%s<%s/>", indent, boolValue)}, nil
-	case reflect.Int, reflect.Int8, reflect.Int16,
-		reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16,
-		reflect.Uint32, reflect.Int64, reflect.Uint64:
+	case jsonpath.Int:
 		if value.CanConvert(reflect.TypeOf(int64(0))) {
 			value = value.Convert(reflect.TypeOf(int64(0)))
 		}
 		return []string{fmt.Sprintf("%s<integer>%d</integer>", indent, value.Int())}, nil
-	case reflect.Float32:
-		return []string{fmt.Sprintf("%s<float>%f</float>", indent, value.Float())}, nil
-	case reflect.String:
+	case jsonpath.Number:
+		// Values in free-form maps come from the JSON parse as float64; emit
+		// whole numbers as integers so they keep their type when read back.
+		if value.CanConvert(reflect.TypeOf(float64(0))) {
+			value = value.Convert(reflect.TypeOf(float64(0)))
+		}
+		floatValue := value.Float()
+		if floatValue == math.Trunc(floatValue) && math.Abs(floatValue) < math.MaxInt64 {
+			return []string{fmt.Sprintf("%s<integer>%d</integer>", indent, int64(floatValue))}, nil
+		}
+		return []string{fmt.Sprintf("%s<real>%s</real>", indent, strconv.FormatFloat(floatValue, 'g', -1, 64))}, nil
+	case jsonpath.String:
 		escapedString, err := xmlEscapeText(value.String())
 		if err != nil {
 			return nil, err
 		}
 		return []string{fmt.Sprintf("%s<string>%s</string>", indent, escapedString)}, nil
 	}
-	return nil, fmt.Errorf("convertToPListLines: don't know how to process %s kind: %q, (%T), value: %v", path, kind, structType, value)
+	return nil, fmt.Errorf("convertToPListLines: don't know how to process %s kind: %d, value: %v", path, schema.Kind, value)
 }
 
+// anySchema describes the entries of free-form settings.
+var anySchema = &jsonpath.Schema{Kind: jsonpath.Any}
+
 func xmlEscapeText(s string) (string, error) {
 	recvBuffer := &bytes.Buffer{}
 	err := xml.EscapeText(recvBuffer, []byte(s))
@@ -164,13 +172,13 @@ func JsonToPlist(settingsBodyAsJSON string) (string, error) {
 	if err := json.Unmarshal([]byte(settingsBodyAsJSON), &actualSettingsJSON); err != nil {
 		return "", fmt.Errorf("error in json: %s", err)
 	}
-	// We use the type as a schema, mainly to distinguish the absence of an array or map from an empty instance
+	// We use the settings schema, mainly to distinguish the absence of an array or map from an empty instance
 	// - see https://github.com/golang/go/issues/27589
 	// And the reason why the type-free parse isn't sufficient is that it doesn't distinguish
 	// hashes (like `diagnostics.mutedChecks`) from subtrees.
 	// By walking the two data structures in parallel the converter can figure out exactly which fields were specified,
 	// and how to interpret their values.
-	lines, err := convertToPListLines(reflect.TypeOf(options.ServerSettingsForJSON{}), reflect.ValueOf(actualSettingsJSON), indentChange, "")
+	lines, err := convertToPListLines(client.SettingsSchema, reflect.ValueOf(actualSettingsJSON), indentChange, "")
 	if err != nil {
 		return "", err
 	}
@@ -186,3 +194,9 @@ func JsonToPlist(settingsBodyAsJSON string) (string, error) {
 	headerLines = append(headerLines, trailerLines...)
 	return strings.Join(headerLines, "\n"), nil
 }
+
+// ProfileFileName returns the name of the plist file the application reads
+// deployment profiles of the given type ("defaults" or "locked") from.
+func ProfileFileName(profileType string) string {
+	return fmt.Sprintf("io.rancherdesktop.profile.%s.plist", profileType)
+}
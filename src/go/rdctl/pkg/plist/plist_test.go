@@ -339,6 +339,28 @@ func TestJsonToPlistFormat(t *testing.T) {
 `, s)
 	})
 
+	t.Run("Keeps the types of numbers in free-form maps", func(t *testing.T) {
+		s, err := JsonToPlist(`{"diagnostics": { "mutedChecks": { "whole": 3, "fraction": 1.5 } } }`)
+		assert.NoError(t, err)
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+  <dict>
+    <key>diagnostics</key>
+    <dict>
+      <key>mutedChecks</key>
+      <dict>
+        <key>fraction</key>
+        <real>1.5</real>
+        <key>whole</key>
+        <integer>3</integer>
+      </dict>
+    </dict>
+  </dict>
+</plist>
+`, s)
+	})
+
 	t.Run("Escapes problematic strings", func(t *testing.T) {
 		jsonBody := `{ "application": {
 										"extensions": {
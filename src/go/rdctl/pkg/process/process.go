@@ -0,0 +1,72 @@
+// Package process finds and inspects running processes in the same way on
+// macOS, Linux, and Windows.
+package process
+
+import (
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Process is a running process.
+type Process struct {
+	PID int `json:"pid"`
+	// Executable is the full path to the executable of the process.
+	Executable string `json:"executable"`
+	// CommandLine is the executable and its arguments, separated by spaces.
+	// It is empty on Windows.
+	CommandLine string `json:"commandLine,omitempty"`
+}
+
+// List returns the running processes, sorted by PID.  The current process is
+// never included, and neither are processes that can't be inspected.
+func List() ([]Process, error) {
+	return Find(func(Process) bool { return true })
+}
+
+// Find returns the running processes for which the match function returns
+// true, sorted by PID; see List.
+func Find(match func(Process) bool) ([]Process, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	var result []Process
+	for _, process := range processes {
+		if !isSelf(process.PID) && match(process) {
+			result = append(result, process)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PID < result[j].PID
+	})
+	return result, nil
+}
+
+// FindByExecutablePrefix returns the processes whose executable is inside the
+// given directory (or is the given file), sorted by PID.  The current process
+// is never included.  Processes that can't be inspected (because they belong
+// to another user, have exited in the meantime, etc.) are skipped.
+func FindByExecutablePrefix(prefix string) ([]Process, error) {
+	return Find(func(process Process) bool {
+		return hasPathPrefix(process.Executable, prefix)
+	})
+}
+
+// hasPathPrefix returns whether the path is the prefix itself, or inside the
+// directory it names.
+func hasPathPrefix(path, prefix string) bool {
+	if path == "" || prefix == "" {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		path, prefix = strings.ToLower(path), strings.ToLower(prefix)
+	}
+	relPath, err := filepath.Rel(filepath.Clean(prefix), filepath.Clean(path))
+	if err != nil {
+		// This may be because they're on different drives, network shares, etc.
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
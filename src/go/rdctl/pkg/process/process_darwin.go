@@ -0,0 +1,63 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+)
+
+func listProcesses() ([]Process, error) {
+	// There is no /proc on macOS; `comm` is the full path to the executable.
+	// As both it and the arguments may contain spaces, they have to be listed
+	// separately.
+	executables, err := runPS("comm=")
+	if err != nil {
+		return nil, err
+	}
+	commandLines, err := runPS("args=")
+	if err != nil {
+		return nil, err
+	}
+	return parsePSOutput(executables, commandLines), nil
+}
+
+func runPS(field string) (string, error) {
+	output, err := execctx.Command(context.Background(), 0, "/bin/ps", "-axww", "-o", "pid=", "-o", field).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list processes: %w", err)
+	}
+	return string(output), nil
+}
+
+// parsePSOutput parses the output of `ps -o pid= -o comm=` and of
+// `ps -o pid= -o args=`; processes that exited in between have no command
+// line.
+func parsePSOutput(executables, commandLines string) []Process {
+	args := parsePSLines(commandLines)
+	var processes []Process
+	for pid, executable := range parsePSLines(executables) {
+		processes = append(processes, Process{PID: pid, Executable: executable, CommandLine: args[pid]})
+	}
+	return processes
+}
+
+// parsePSLines parses lines of a PID followed by a single field, which may
+// contain spaces.
+func parsePSLines(output string) map[int]string {
+	result := make(map[int]string)
+	for _, line := range strings.Split(output, "\n") {
+		pidField, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidField)
+		if err != nil {
+			continue
+		}
+		result[pid] = strings.TrimSpace(value)
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePSOutput(t *testing.T) {
+	executables := `    1 /sbin/launchd
+  100 /Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop
+  101 /usr/bin/ssh
+`
+	commandLines := `    1 /sbin/launchd
+  100 /Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop --no-sandbox
+`
+	assert.ElementsMatch(t, []Process{
+		{PID: 1, Executable: "/sbin/launchd", CommandLine: "/sbin/launchd"},
+		{PID: 100, Executable: "/Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop", CommandLine: "/Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop --no-sandbox"},
+		{PID: 101, Executable: "/usr/bin/ssh"},
+	}, parsePSOutput(executables, commandLines))
+}
@@ -0,0 +1,41 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+func listProcesses() ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		executable, err := os.Readlink(filepath.Join("/proc", entry.Name(), "exe"))
+		if err != nil {
+			// The process exited, belongs to another user, or is a kernel thread.
+			logrus.Tracef("failed to get executable of pid %d: %s (skipping)", pid, err)
+			continue
+		}
+		// The arguments are separated (and terminated) by NUL characters.
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil {
+			logrus.Tracef("failed to get command line of pid %d: %s", pid, err)
+		}
+		processes = append(processes, Process{
+			PID:         pid,
+			Executable:  strings.TrimSuffix(executable, " (deleted)"),
+			CommandLine: strings.Join(strings.FieldsFunc(string(cmdline), func(r rune) bool { return r == 0 }), " "),
+		})
+	}
+	return processes, nil
+}
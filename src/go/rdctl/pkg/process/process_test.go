@@ -0,0 +1,49 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPathPrefix(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "opt", "rd")
+	assert.True(t, hasPathPrefix(filepath.Join(dir, "bin", "qemu"), dir))
+	assert.True(t, hasPathPrefix(dir, dir))
+	assert.False(t, hasPathPrefix(filepath.Join(dir+"-other", "bin", "qemu"), dir))
+	assert.False(t, hasPathPrefix(filepath.Join(string(filepath.Separator), "opt", "bin"), dir))
+	assert.False(t, hasPathPrefix("", dir))
+}
+
+func TestFindByExecutablePrefix(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only tested on Linux")
+	}
+	executable, err := exec.LookPath("sleep")
+	require.NoError(t, err)
+	executable, err = filepath.EvalSymlinks(executable)
+	require.NoError(t, err)
+	cmd := exec.Command(executable, "60")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	processes, err := FindByExecutablePrefix(filepath.Dir(executable))
+	require.NoError(t, err)
+	expected := Process{PID: cmd.Process.Pid, Executable: executable, CommandLine: executable + " 60"}
+	assert.Contains(t, processes, expected)
+	for _, process := range processes {
+		assert.NotEqual(t, os.Getpid(), process.PID)
+	}
+	assert.True(t, IsRunning(cmd.Process.Pid))
+
+	processes, err = Find(func(process Process) bool {
+		return process.CommandLine == expected.CommandLine
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Process{expected}, processes)
+}
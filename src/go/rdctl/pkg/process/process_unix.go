@@ -0,0 +1,24 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func isSelf(pid int) bool {
+	return pid == os.Getpid()
+}
+
+// IsRunning returns whether a process with the given PID is running.
+func IsRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	// EPERM means the process exists, but belongs to someone else.
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,105 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	pKernel32      = windows.NewLazySystemDLL("kernel32.dll")
+	pEnumProcesses = pKernel32.NewProc("K32EnumProcesses")
+)
+
+// STILL_ACTIVE is the exit code of a process that hasn't exited yet.
+const stillActive = 259
+
+func isSelf(pid int) bool {
+	return pid == os.Getpid()
+}
+
+// IsRunning returns whether a process with the given PID is running.
+func IsRunning(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means the process exists, but belongs to someone else.
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+	var exitCode uint32
+	if err := windows.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+	return exitCode == stillActive
+}
+
+func listProcesses() ([]Process, error) {
+	var pids []uint32
+	err := directories.InvokeWin32WithBuffer(func(size int) error {
+		pids = make([]uint32, size)
+		var bytesReturned uint32
+		// We can't use `windows.EnumProcesses`, because it passes in an incorrect
+		// value for the second argument (`cb`).
+		elementSize := unsafe.Sizeof(uint32(0))
+		bufferSize := uintptr(len(pids)) * elementSize
+		n, _, err := pEnumProcesses.Call(
+			uintptr(unsafe.Pointer(&pids[0])),
+			bufferSize,
+			uintptr(unsafe.Pointer(&bytesReturned)),
+		)
+		if n == 0 {
+			return err
+		}
+		if uintptr(bytesReturned) >= bufferSize {
+			return windows.ERROR_INSUFFICIENT_BUFFER
+		}
+		processesFound := uintptr(bytesReturned) / elementSize
+		logrus.Tracef("got %d processes", processesFound)
+		pids = pids[:processesFound]
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get process list: %w", err)
+	}
+
+	var processes []Process
+	for _, pid := range pids {
+		executable, err := getExecutable(pid)
+		if err != nil {
+			// We can't open privileged processes, processes that have exited since,
+			// idle process, etc.; so we log this at trace level instead.
+			logrus.Tracef("failed to get executable of pid %d: %s (skipping)", pid, err)
+			continue
+		}
+		processes = append(processes, Process{PID: int(pid), Executable: executable})
+	}
+	return processes, nil
+}
+
+func getExecutable(pid uint32) (string, error) {
+	hProc, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(hProc)
+
+	var imageName string
+	err = directories.InvokeWin32WithBuffer(func(size int) error {
+		nameBuf := make([]uint16, size)
+		charsWritten := uint32(size)
+		err := windows.QueryFullProcessImageName(hProc, 0, &nameBuf[0], &charsWritten)
+		if err != nil {
+			return err
+		}
+		if charsWritten >= uint32(size)-1 {
+			return windows.ERROR_INSUFFICIENT_BUFFER
+		}
+		imageName = windows.UTF16ToString(nameBuf)
+		return nil
+	})
+	return imageName, err
+}
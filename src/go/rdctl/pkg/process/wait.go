@@ -0,0 +1,40 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// waitPollInterval is how often to check whether a process has exited, on
+// platforms where we can't be notified.
+const waitPollInterval = 100 * time.Millisecond
+
+// errWaitUnsupported is returned by the platform-specific implementations
+// when we have to fall back to polling.
+var errWaitUnsupported = errors.New("waiting for process exit is not supported")
+
+// WaitForProcessExit waits until the process with the given PID has exited;
+// it returns immediately if there is no such process.  If the context is done
+// first, its error is returned.  Where possible (pidfd on Linux, process
+// handles on Windows) this waits for a notification; elsewhere, it polls.
+func WaitForProcessExit(ctx context.Context, pid int) error {
+	err := waitForProcessExit(ctx, pid)
+	if errors.Is(err, errWaitUnsupported) {
+		return pollForProcessExit(ctx, pid)
+	}
+	return err
+}
+
+func pollForProcessExit(ctx context.Context, pid int) error {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for IsRunning(pid) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package process
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+func waitForProcessExit(ctx context.Context, pid int) error {
+	fd, err := unix.PidfdOpen(pid, 0)
+	if errors.Is(err, unix.ESRCH) {
+		return nil
+	} else if err != nil {
+		// pidfd_open requires Linux 5.3.
+		return errWaitUnsupported
+	}
+	defer unix.Close(fd)
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		// The pidfd becomes readable once the process exits.
+		n, err := unix.Poll(fds, int(waitPollInterval.Milliseconds()))
+		if err != nil && !errors.Is(err, unix.EINTR) {
+			return errWaitUnsupported
+		}
+		if n > 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package process
+
+import (
+	"context"
+)
+
+func waitForProcessExit(_ context.Context, _ int) error {
+	return errWaitUnsupported
+}
@@ -0,0 +1,33 @@
+package process
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForProcessExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	// Reap the child as soon as it exits, so that it doesn't linger as a zombie.
+	go func() { _ = cmd.Wait() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, WaitForProcessExit(ctx, cmd.Process.Pid), context.DeadlineExceeded)
+
+	require.NoError(t, cmd.Process.Kill())
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, WaitForProcessExit(ctx, cmd.Process.Pid))
+	assert.NoError(t, pollForProcessExit(ctx, cmd.Process.Pid))
+}
@@ -0,0 +1,31 @@
+package process
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+func waitForProcessExit(ctx context.Context, pid int) error {
+	handle, err := windows.OpenProcess(windows.SYNCHRONIZE, false, uint32(pid))
+	if errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+		// There is no process with this PID.
+		return nil
+	} else if err != nil {
+		return errWaitUnsupported
+	}
+	defer windows.CloseHandle(handle)
+	for {
+		event, err := windows.WaitForSingleObject(handle, uint32(waitPollInterval.Milliseconds()))
+		if err != nil {
+			return errWaitUnsupported
+		}
+		if event == windows.WAIT_OBJECT_0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package profile loads deployment profiles in any of the supported formats
+// (Windows .reg files, macOS plist files, and Linux JSON files) and validates
+// them against the settings schema.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/plist"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/reg"
+)
+
+// Profile holds the settings of the sections of a deployment profile, indexed
+// by profile type ("defaults" or "locked").
+type Profile struct {
+	Sections map[string]map[string]interface{}
+	// The registry has no boolean type, so booleans are stored as dwords.
+	fromRegistry bool
+}
+
+// Problem describes a profile entry that doesn't match the settings schema.
+type Problem struct {
+	Section string `json:"section"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Section, p.Path, p.Message)
+}
+
+// Setting is a single valid entry of a profile.
+type Setting struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// Result is the outcome of validating a profile.
+type Result struct {
+	Problems []Problem `json:"problems"`
+	// Settings lists the valid entries in each section, ordered by path.
+	Settings map[string][]Setting `json:"settings"`
+}
+
+// Load reads the deployment profile at the given path; the format is chosen by
+// the file extension.  As plist and JSON files only contain a single section,
+// profileType selects it; if empty, it is derived from the file name (files
+// with "locked" in the name are locked profiles, everything else defaults).
+func Load(path, profileType string) (*Profile, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if profileType == "" {
+		profileType = reg.DefaultsProfileType
+		if strings.Contains(strings.ToLower(filepath.Base(path)), reg.LockedProfileType) {
+			profileType = reg.LockedProfileType
+		}
+	}
+	var settings interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".reg":
+		sections, err := reg.ParseReg(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %q: %w", path, err)
+		}
+		return &Profile{Sections: sections, fromRegistry: true}, nil
+	case ".plist":
+		if settings, err = plist.ParsePlist(string(contents)); err != nil {
+			return nil, fmt.Errorf("error parsing %q: %w", path, err)
+		}
+	case ".json":
+		if err = json.Unmarshal(contents, &settings); err != nil {
+			return nil, fmt.Errorf("error parsing %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized profile format %q; expecting a .reg, .plist, or .json file", filepath.Ext(path))
+	}
+	settingsMap, ok := settings.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("error parsing %q: expected a dictionary at the top level", path)
+	}
+	return &Profile{Sections: map[string]map[string]interface{}{profileType: settingsMap}}, nil
+}
+
+// Validate checks every entry of the profile against the settings schema.
+func (p *Profile) Validate() Result {
+	result := Result{Problems: []Problem{}, Settings: map[string][]Setting{}}
+	sections := make([]string, 0, len(p.Sections))
+	for section := range p.Sections {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		settings, problems := jsonpath.Validate(client.SettingsSchema, p.Sections[section], jsonpath.Options{NumericBooleans: p.fromRegistry})
+		for _, problem := range problems {
+			result.Problems = append(result.Problems, Problem{Section: section, Path: problem.Path, Message: problem.Message})
+		}
+		result.Settings[section] = nil
+		for _, setting := range settings {
+			result.Settings[section] = append(result.Settings[section], Setting{Path: setting.Path, Value: setting.Value})
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("accepts valid JSON profiles", func(t *testing.T) {
+		path := writeProfile(t, "rancher-desktop.locked.json",
+			`{"application": {"adminAccess": false}, "kubernetes": {"version": "1.29.1", "port": 6443}}`)
+		profile, err := Load(path, "")
+		require.NoError(t, err)
+		result := profile.Validate()
+		assert.Empty(t, result.Problems)
+		assert.Equal(t, map[string][]Setting{
+			"locked": {
+				{Path: "application.adminAccess", Value: false},
+				{Path: "kubernetes.port", Value: int64(6443)},
+				{Path: "kubernetes.version", Value: "1.29.1"},
+			},
+		}, result.Settings)
+	})
+
+	t.Run("reports unknown and mistyped entries", func(t *testing.T) {
+		path := writeProfile(t, "defaults.json",
+			`{"application": {"adminAccess": "yes", "colour": "blue"}, "kubernetes": {"port": 1.5}, "containerEngine": "moby"}`)
+		profile, err := Load(path, "")
+		require.NoError(t, err)
+		result := profile.Validate()
+		assert.Equal(t, []Problem{
+			{Section: "defaults", Path: "application.adminAccess", Message: `expected a boolean, got the string "yes"`},
+			{Section: "defaults", Path: "application.colour", Message: "unknown setting"},
+			{Section: "defaults", Path: "containerEngine", Message: `expected a group of settings, got the string "moby"`},
+			{Section: "defaults", Path: "kubernetes.port", Message: "expected an integer, got 1.5"},
+		}, result.Problems)
+	})
+
+	t.Run("accepts registry booleans", func(t *testing.T) {
+		path := writeProfile(t, "profile.reg", `Windows Registry Editor Version 5.00
+[HKEY_CURRENT_USER\SOFTWARE\Policies\Rancher Desktop\defaults\application]
+"adminAccess"=dword:1
+[HKEY_CURRENT_USER\SOFTWARE\Policies\Rancher Desktop\locked\application]
+"adminAccess"=dword:2
+`)
+		profile, err := Load(path, "")
+		require.NoError(t, err)
+		result := profile.Validate()
+		assert.Equal(t, []Setting{{Path: "application.adminAccess", Value: true}}, result.Settings["defaults"])
+		assert.Equal(t, []Problem{
+			{Section: "locked", Path: "application.adminAccess", Message: "expected a boolean, got the number 2"},
+		}, result.Problems)
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		_, err := Load(writeProfile(t, "profile.yaml", ""), "")
+		assert.ErrorContains(t, err, `unrecognized profile format ".yaml"`)
+	})
+}
@@ -0,0 +1,171 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"debug/macho"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/checksum"
+)
+
+const digestPrefix = "sha256:"
+
+// Digest returns the digest of the file at the given path.  Releases are
+// code signed after the manifest is made, so for executables this covers
+// what signing leaves alone:
+//
+//   - Windows executables are hashed the way Authenticode does it, skipping
+//     the checksum, the certificate table and its directory entry.
+//   - macOS executables are hashed by the contents of their sections and the
+//     libraries they load, skipping the headers and the code signature.
+//
+// Anything else is hashed as a whole.
+func Digest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var magic [4]byte
+	if _, err := io.ReadFull(file, magic[:]); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	hasher := sha256.New()
+	switch {
+	case bytes.HasPrefix(magic[:], []byte("MZ")):
+		err = digestPE(hasher, file)
+	case isMachO(magic):
+		err = digestMachO(hasher, file)
+	default:
+		err = digestSection(hasher, file, 0, -1)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return digestPrefix + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// digestSection hashes size bytes from the given offset, or up to the end of
+// the file if size is negative.
+func digestSection(hasher hash.Hash, file *os.File, offset, size int64) error {
+	if size < 0 {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		size = info.Size() - offset
+	}
+	_, err := checksum.Copy(hasher, io.NewSectionReader(file, offset, size))
+	return err
+}
+
+func digestPE(hasher hash.Hash, file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	var header [4]byte
+	if _, err := file.ReadAt(header[:], 0x3c); err != nil {
+		return err
+	}
+	// The optional header follows the signature and the COFF file header.
+	optionalHeader := int64(binary.LittleEndian.Uint32(header[:])) + 4 + 20
+	if _, err := file.ReadAt(header[:2], optionalHeader); err != nil {
+		return err
+	}
+	checksum := optionalHeader + 64
+	var certificateEntry int64
+	switch binary.LittleEndian.Uint16(header[:2]) {
+	case 0x10b: // PE32
+		certificateEntry = optionalHeader + 96 + 4*8
+	case 0x20b: // PE32+
+		certificateEntry = optionalHeader + 112 + 4*8
+	default:
+		return errors.New("unknown PE optional header format")
+	}
+	var entry [8]byte
+	if _, err := file.ReadAt(entry[:], certificateEntry); err != nil {
+		return err
+	}
+	end := info.Size()
+	if offset := int64(binary.LittleEndian.Uint32(entry[:4])); offset != 0 && offset <= end {
+		end = offset
+	}
+	if certificateEntry+8 > end {
+		return errors.New("truncated PE header")
+	}
+	for _, section := range [][2]int64{{0, checksum}, {checksum + 4, certificateEntry}, {certificateEntry + 8, end}} {
+		if err := digestSection(hasher, file, section[0], section[1]-section[0]); err != nil {
+			return err
+		}
+	}
+	// Signing pads the file to a multiple of eight bytes before appending the
+	// certificates; pad unsigned files the same way.
+	_, err = hasher.Write(make([]byte, (8-end%8)%8))
+	return err
+}
+
+func isMachO(magic [4]byte) bool {
+	switch binary.BigEndian.Uint32(magic[:]) {
+	case macho.Magic32, macho.Magic64, macho.MagicFat:
+		return true
+	}
+	switch binary.LittleEndian.Uint32(magic[:]) {
+	case macho.Magic32, macho.Magic64:
+		return true
+	}
+	return false
+}
+
+func digestMachO(hasher hash.Hash, file *os.File) error {
+	var images []*macho.File
+	if fat, err := macho.NewFatFile(file); err == nil {
+		defer fat.Close()
+		for _, arch := range fat.Arches {
+			images = append(images, arch.File)
+		}
+		sort.Slice(images, func(i, j int) bool {
+			return images[i].Cpu < images[j].Cpu
+		})
+	} else if errors.Is(err, macho.ErrNotFat) {
+		image, err := macho.NewFile(file)
+		if err != nil {
+			return err
+		}
+		defer image.Close()
+		images = append(images, image)
+	} else {
+		return err
+	}
+
+	for _, image := range images {
+		fmt.Fprintf(hasher, "cpu %d\n", image.Cpu)
+		libraries, err := image.ImportedLibraries()
+		if err != nil {
+			return err
+		}
+		for _, library := range libraries {
+			fmt.Fprintf(hasher, "library %s\n", library)
+		}
+		for _, section := range image.Sections {
+			fmt.Fprintf(hasher, "section %s,%s %d\n", section.Seg, section.Name, section.Size)
+			if section.Offset == 0 {
+				// Zero-filled sections have no contents in the file.
+				continue
+			}
+			if _, err := checksum.Copy(hasher, section.Open()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Command generate prints the provenance manifest of the helpers in the
+// resources directory, in the form rdctl embeds it.
+//
+// Usage: go run ./pkg/provenance/generate <resources directory> <platform> <builder>
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/provenance"
+)
+
+func main() {
+	if len(os.Args) != 4 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <resources directory> <platform> <builder>\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := run(os.Args[1], os.Args[2], os.Args[3]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(resourcesDir, platform, builder string) error {
+	manifest, err := provenance.Generate(context.Background(), resourcesDir, platform, builder)
+	if err != nil {
+		return err
+	}
+	encoded, err := manifest.Encode()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Print(encoded)
+	return err
+}
@@ -0,0 +1,215 @@
+// Package provenance records how a Rancher Desktop build was made, and the
+// digests of the helper executables that shipped with it, so that an
+// installation can be checked for tampering or a partial upgrade.
+//
+// The manifest is made by ./generate once the other helpers are built, and
+// embedded in rdctl when it is linked:
+//
+//	go build -ldflags "-X github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/provenance.encodedManifest=$(go run ./pkg/provenance/generate ...)"
+//
+// Development builds have no manifest.
+package provenance
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/checksum"
+)
+
+// encodedManifest is the base64-encoded JSON manifest, set at link time.
+var encodedManifest string
+
+// ErrNoManifest is returned for builds without an embedded manifest.
+var ErrNoManifest = errors.New("this build of rdctl has no provenance manifest")
+
+// Status of each file when verifying an installation.
+const (
+	StatusOK         = "ok"
+	StatusModified   = "modified"
+	StatusMissing    = "missing"
+	StatusUnexpected = "unexpected"
+)
+
+// Manifest describes a build.
+type Manifest struct {
+	// Builder identifies the machine or CI job that made the build.
+	Builder string `json:"builder"`
+	// Platform is the name of the directory under resources holding the
+	// helpers, e.g. "darwin" or "win32".
+	Platform string `json:"platform"`
+	// Files maps the path of each helper, relative to the resources directory
+	// and with forward slashes, to its Digest.
+	Files map[string]string `json:"files"`
+}
+
+// Result is the outcome of verifying one file.
+type Result struct {
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Embedded returns the manifest embedded in this build.
+func Embedded() (*Manifest, error) {
+	if encodedManifest == "" {
+		return nil, ErrNoManifest
+	}
+	return Decode(encodedManifest)
+}
+
+// Decode parses an encoded manifest.
+func Decode(encoded string) (*Manifest, error) {
+	contents, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode provenance manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse provenance manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// Encode returns the manifest in the form embedded in rdctl.
+func (m *Manifest) Encode() (string, error) {
+	contents, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(contents), nil
+}
+
+// Commit returns the revision rdctl was built from, as recorded by the Go
+// toolchain, and whether the tree had uncommitted changes.
+func Commit() (revision string, modified bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", false
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	return revision, modified
+}
+
+// Generate makes the manifest for the helpers of the given platform.
+func Generate(ctx context.Context, resourcesDir, platform, builder string) (*Manifest, error) {
+	manifest := &Manifest{Builder: builder, Platform: platform, Files: map[string]string{}}
+	helpers, err := findHelpers(resourcesDir, platform)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range digestHelpers(ctx, resourcesDir, helpers) {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		manifest.Files[helpers[i]] = result.Digest
+	}
+	return manifest, nil
+}
+
+// Verify checks the helpers in the resources directory against the manifest.
+// Helpers found there that are not in the manifest are reported as
+// unexpected, as they are likely left over from another version.
+func (m *Manifest) Verify(ctx context.Context, resourcesDir string) ([]Result, error) {
+	expected := make([]string, 0, len(m.Files))
+	for helper := range m.Files {
+		expected = append(expected, helper)
+	}
+	var results []Result
+	for i, digest := range digestHelpers(ctx, resourcesDir, expected) {
+		result := Result{Path: expected[i], Expected: m.Files[expected[i]]}
+		switch {
+		case errors.Is(digest.Err, fs.ErrNotExist):
+			result.Status = StatusMissing
+		case digest.Err != nil:
+			return nil, digest.Err
+		case digest.Digest != result.Expected:
+			result.Status = StatusModified
+			result.Actual = digest.Digest
+		default:
+			result.Status = StatusOK
+		}
+		results = append(results, result)
+	}
+	helpers, err := findHelpers(resourcesDir, m.Platform)
+	if err != nil {
+		return nil, err
+	}
+	for _, helper := range helpers {
+		if _, ok := m.Files[helper]; !ok {
+			results = append(results, Result{Path: helper, Status: StatusUnexpected})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+	return results, nil
+}
+
+// digestHelpers computes the digests of the helpers in parallel, as there are
+// dozens of them and some are large.
+func digestHelpers(ctx context.Context, resourcesDir string, helpers []string) []checksum.Result {
+	paths := make([]string, len(helpers))
+	for i, helper := range helpers {
+		paths[i] = filepath.Join(resourcesDir, filepath.FromSlash(helper))
+	}
+	return checksum.Files(ctx, paths, 0, Digest)
+}
+
+// findHelpers returns the paths, relative to the resources directory, of the
+// executables shipped for the platform: everything under bin and internal,
+// and the executables beside them (wsl-helper), except rdctl itself.  Other
+// files beside them, such as VM images, are not executables and have
+// extensions.
+func findHelpers(resourcesDir, platform string) ([]string, error) {
+	var helpers []string
+	root := filepath.Join(resourcesDir, platform)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources: %w", err)
+	}
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); entry.Type().IsRegular() && (ext == "" || ext == ".exe") {
+			helpers = append(helpers, path.Join(platform, entry.Name()))
+		}
+	}
+	for _, dir := range []string{"bin", "internal"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(file string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			} else if err != nil || !entry.Type().IsRegular() {
+				return err
+			}
+			relative, err := filepath.Rel(resourcesDir, file)
+			if err != nil {
+				return err
+			}
+			relative = filepath.ToSlash(relative)
+			if name := path.Join(platform, "bin", "rdctl"); relative != name && relative != name+".exe" {
+				helpers = append(helpers, relative)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find helpers: %w", err)
+		}
+	}
+	return helpers, nil
+}
@@ -0,0 +1,161 @@
+package provenance
+
+import (
+	"context"
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, contents []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, contents, 0o755))
+}
+
+func TestVerify(t *testing.T) {
+	resourcesDir := t.TempDir()
+	writeFile(t, filepath.Join(resourcesDir, "win32", "wsl-helper.exe"), []byte("wsl-helper"))
+	writeFile(t, filepath.Join(resourcesDir, "win32", "bin", "docker.exe"), []byte("docker"))
+	writeFile(t, filepath.Join(resourcesDir, "win32", "bin", "rdctl.exe"), []byte("rdctl"))
+	writeFile(t, filepath.Join(resourcesDir, "win32", "internal", "vtunnel.exe"), []byte("vtunnel"))
+	writeFile(t, filepath.Join(resourcesDir, "win32", "distro.tar"), []byte("not a helper"))
+
+	manifest, err := Generate(context.Background(), resourcesDir, "win32", "test")
+	require.NoError(t, err)
+	assert.Len(t, manifest.Files, 3, "rdctl and non-executables must not be included")
+	encoded, err := manifest.Encode()
+	require.NoError(t, err)
+	manifest, err = Decode(encoded)
+	require.NoError(t, err)
+
+	results, err := manifest.Verify(context.Background(), resourcesDir)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, StatusOK, result.Status, result.Path)
+	}
+
+	writeFile(t, filepath.Join(resourcesDir, "win32", "bin", "docker.exe"), []byte("tampered"))
+	require.NoError(t, os.Remove(filepath.Join(resourcesDir, "win32", "internal", "vtunnel.exe")))
+	writeFile(t, filepath.Join(resourcesDir, "win32", "internal", "extra.exe"), []byte("extra"))
+	results, err = manifest.Verify(context.Background(), resourcesDir)
+	require.NoError(t, err)
+	statuses := map[string]string{}
+	for _, result := range results {
+		statuses[result.Path] = result.Status
+	}
+	assert.Equal(t, map[string]string{
+		"win32/bin/docker.exe":       StatusModified,
+		"win32/internal/extra.exe":   StatusUnexpected,
+		"win32/internal/vtunnel.exe": StatusMissing,
+		"win32/wsl-helper.exe":       StatusOK,
+	}, statuses)
+}
+
+func TestEmbedded(t *testing.T) {
+	_, err := Embedded()
+	assert.ErrorIs(t, err, ErrNoManifest)
+}
+
+// buildPE returns a minimal PE32+ image, with the body length chosen so that
+// signing must pad it.
+func buildPE() []byte {
+	const optionalHeader = 0x40 + 4 + 20
+	image := make([]byte, optionalHeader+112+16*8+13)
+	copy(image, "MZ")
+	binary.LittleEndian.PutUint32(image[0x3c:], 0x40)
+	copy(image[0x40:], "PE\x00\x00")
+	binary.LittleEndian.PutUint16(image[optionalHeader:], 0x20b)
+	copy(image[len(image)-13:], "program body")
+	return image
+}
+
+// signPE mimics Authenticode signing: pad the image, append a certificate
+// table, and update the header to point to it.
+func signPE(image []byte) []byte {
+	const optionalHeader = 0x40 + 4 + 20
+	signed := append([]byte{}, image...)
+	signed = append(signed, make([]byte, (8-len(signed)%8)%8)...)
+	certificates := []byte("certificate table contents")
+	binary.LittleEndian.PutUint32(signed[optionalHeader+112+4*8:], uint32(len(signed)))
+	binary.LittleEndian.PutUint32(signed[optionalHeader+112+4*8+4:], uint32(len(certificates)))
+	binary.LittleEndian.PutUint32(signed[optionalHeader+64:], 0x12345678)
+	return append(signed, certificates...)
+}
+
+func TestDigestPE(t *testing.T) {
+	dir := t.TempDir()
+	image := buildPE()
+	unsignedPath := filepath.Join(dir, "unsigned.exe")
+	signedPath := filepath.Join(dir, "signed.exe")
+	tamperedPath := filepath.Join(dir, "tampered.exe")
+	writeFile(t, unsignedPath, image)
+	writeFile(t, signedPath, signPE(image))
+	tampered := signPE(image)
+	tampered[len(image)-2] = '!'
+	writeFile(t, tamperedPath, tampered)
+
+	unsigned, err := Digest(unsignedPath)
+	require.NoError(t, err)
+	signed, err := Digest(signedPath)
+	require.NoError(t, err)
+	assert.Equal(t, unsigned, signed, "signing must not change the digest")
+	modified, err := Digest(tamperedPath)
+	require.NoError(t, err)
+	assert.NotEqual(t, unsigned, modified)
+}
+
+func TestDigestMachO(t *testing.T) {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available to build a Mach-O executable")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.21\n"))
+	writeFile(t, filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"))
+	binaryPath := filepath.Join(dir, "example")
+	cmd := exec.Command(goPath, "build", "-o", binaryPath, ".")
+	cmd.Dir = dir
+	// The linker signs arm64 executables ad hoc.
+	cmd.Env = append(os.Environ(), "GOOS=darwin", "GOARCH=arm64", "CGO_ENABLED=0")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	original, err := Digest(binaryPath)
+	require.NoError(t, err)
+	contents, err := os.ReadFile(binaryPath)
+	require.NoError(t, err)
+	image, err := macho.Open(binaryPath)
+	require.NoError(t, err)
+	var signatureOffset uint32
+	for _, load := range image.Loads {
+		raw := load.Raw()
+		if image.ByteOrder.Uint32(raw) == 0x1d { // LC_CODE_SIGNATURE
+			signatureOffset = image.ByteOrder.Uint32(raw[8:])
+		}
+	}
+	text := image.Section("__text")
+	require.NoError(t, image.Close())
+	require.NotZero(t, signatureOffset)
+	require.NotNil(t, text)
+
+	resigned := append([]byte{}, contents...)
+	resigned[signatureOffset+100] ^= 0xff
+	resigned = append(resigned, make([]byte, 1024)...)
+	writeFile(t, binaryPath, resigned)
+	digest, err := Digest(binaryPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, digest, "replacing the signature must not change the digest")
+
+	tampered := append([]byte{}, contents...)
+	tampered[text.Offset+10] ^= 0xff
+	writeFile(t, binaryPath, tampered)
+	digest, err = Digest(binaryPath)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, digest)
+}
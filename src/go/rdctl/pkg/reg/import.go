@@ -0,0 +1,103 @@
+package reg
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
+)
+
+// booleanMaps lists the free-form settings whose values are booleans; the
+// schema doesn't tell us that, and the registry stores booleans as dwords.
+var booleanMaps = map[string]bool{
+	"WSL.integrations":        true,
+	"diagnostics.mutedChecks": true,
+}
+
+// RegToJson is the inverse of JsonToReg: it converts the settings of a single
+// profile section (as returned by ParseReg or ReadRegistry) back into settings
+// JSON, restoring the types that the registry can't represent (booleans are
+// stored as dwords) and the case of the setting names.
+func RegToJson(values map[string]interface{}) (string, error) {
+	settings, err := convertFromRegFormat(client.SettingsSchema, values, "")
+	if err != nil {
+		return "", err
+	}
+	result, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize settings: %w", err)
+	}
+	return string(result), nil
+}
+
+// convertFromRegFormat converts a registry value to the JSON value for a
+// setting with the given schema; path is the dotted name of the setting.
+func convertFromRegFormat(schema *jsonpath.Schema, value interface{}, path string) (interface{}, error) {
+	switch schema.Kind {
+	case jsonpath.Group:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a registry key, got a value", path)
+		}
+		result := map[string]interface{}{}
+		for key, childValue := range valueMap {
+			// Registry key and value names are case-insensitive.
+			field, ok := schema.Field(key, true)
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown setting", jsonpath.Join(path, key))
+			}
+			name := field.Name
+			converted, err := convertFromRegFormat(field.Schema, childValue, jsonpath.Join(path, name))
+			if err != nil {
+				return nil, err
+			}
+			result[name] = converted
+		}
+		return result, nil
+	case jsonpath.Map:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a registry key, got a value", path)
+		}
+		if !booleanMaps[path] {
+			return valueMap, nil
+		}
+		result := map[string]interface{}{}
+		for key, childValue := range valueMap {
+			converted, err := convertFromRegFormat(boolSchema, childValue, jsonpath.Join(path, key))
+			if err != nil {
+				return nil, err
+			}
+			result[key] = converted
+		}
+		return result, nil
+	case jsonpath.Bool:
+		number, ok := value.(int64)
+		if !ok || (number != 0 && number != 1) {
+			return nil, fmt.Errorf("%s: expected a dword of 0 or 1, got %v", path, value)
+		}
+		return number == 1, nil
+	case jsonpath.Int, jsonpath.Number:
+		if _, ok := value.(int64); !ok {
+			return nil, fmt.Errorf("%s: expected a dword or qword, got %v", path, value)
+		}
+		return value, nil
+	case jsonpath.String:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("%s: expected a string, got %v", path, value)
+		}
+		return value, nil
+	case jsonpath.List:
+		if _, ok := value.([]string); !ok {
+			return nil, fmt.Errorf("%s: expected a multi-string, got %v", path, value)
+		}
+		return value, nil
+	case jsonpath.Any:
+		return value, nil
+	}
+	return nil, fmt.Errorf("%s: don't know how to process kind %d", path, schema.Kind)
+}
+
+// boolSchema describes the entries of booleanMaps.
+var boolSchema = &jsonpath.Schema{Kind: jsonpath.Bool}
@@ -0,0 +1,233 @@
+package reg
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const policyKeyPath = `SOFTWARE\Policies\Rancher Desktop\`
+
+// ParseReg parses the contents of a .reg file (as generated by JsonToReg, or
+// exported by regedit) and returns the settings stored in each section of the
+// Rancher Desktop policy key, indexed by profile type ("defaults" or "locked").
+// Values are returned as int64 (dword and qword), string, or []string (multi-string);
+// keys outside the policy key are ignored.
+func ParseReg(contents string) (map[string]map[string]interface{}, error) {
+	result := map[string]map[string]interface{}{}
+	// The current key, as a profile type followed by the setting path; nil when
+	// the current key is outside the policy key.
+	var currentKey []string
+
+	lines, err := joinContinuationLines(decodeRegText(contents))
+	if err != nil {
+		return nil, err
+	}
+	for lineNumber, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, ";"):
+			continue
+		case lineNumber == 0 && (line == "Windows Registry Editor Version 5.00" || line == "REGEDIT4"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated key %q", lineNumber+1, line)
+			}
+			currentKey = policySubkey(line[1 : len(line)-1])
+			if len(currentKey) > 0 {
+				profileType := currentKey[0]
+				if profileType != DefaultsProfileType && profileType != LockedProfileType {
+					return nil, fmt.Errorf("line %d: unrecognized profile type %q", lineNumber+1, profileType)
+				}
+				if _, ok := result[profileType]; !ok {
+					result[profileType] = map[string]interface{}{}
+				}
+			}
+		case strings.HasPrefix(line, `"`):
+			if len(currentKey) == 0 {
+				continue
+			}
+			name, value, err := parseRegValue(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber+1, err)
+			}
+			parent := result[currentKey[0]]
+			for _, part := range currentKey[1:] {
+				child, ok := parent[part].(map[string]interface{})
+				if !ok {
+					child = map[string]interface{}{}
+					parent[part] = child
+				}
+				parent = child
+			}
+			parent[name] = value
+		case strings.HasPrefix(line, "@="):
+			// Default values are not used by the policy key.
+			continue
+		default:
+			return nil, fmt.Errorf("line %d: unexpected content %q", lineNumber+1, line)
+		}
+	}
+	return result, nil
+}
+
+// decodeRegText converts the contents of a .reg file to a string; regedit
+// writes UTF-16LE files with a byte order mark.
+func decodeRegText(contents string) string {
+	if strings.HasPrefix(contents, "\xff\xfe") {
+		raw := []byte(contents[2:])
+		words := make([]uint16, len(raw)/2)
+		for i := range words {
+			words[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+		}
+		return string(utf16.Decode(words))
+	}
+	return strings.TrimPrefix(contents, "\ufeff")
+}
+
+// joinContinuationLines splits the text into lines, joining hex values that
+// are continued over multiple lines with a trailing backslash.
+func joinContinuationLines(contents string) ([]string, error) {
+	var lines []string
+	var pending strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if pending.Len() > 0 {
+			line = strings.TrimSpace(line)
+		}
+		if strings.HasSuffix(line, `\`) && strings.Contains(pending.String()+line, "=hex") {
+			pending.WriteString(strings.TrimSuffix(line, `\`))
+			continue
+		}
+		pending.WriteString(line)
+		lines = append(lines, pending.String())
+		pending.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if pending.Len() > 0 {
+		lines = append(lines, pending.String())
+	}
+	return lines, nil
+}
+
+// policySubkey returns the path components of the given registry key below the
+// Rancher Desktop policy key, or nil if the key is not inside it.
+func policySubkey(key string) []string {
+	_, subkey, found := strings.Cut(strings.ToUpper(key), strings.ToUpper(`\`+policyKeyPath))
+	if !found || strings.HasPrefix(key, "-") {
+		return nil
+	}
+	// Use the original case for the setting names.
+	subkey = key[len(key)-len(subkey):]
+	return strings.Split(subkey, `\`)
+}
+
+// parseRegValue parses a single `"name"=value` line.
+func parseRegValue(line string) (string, interface{}, error) {
+	name, rest, err := parseRegString(line)
+	if err != nil {
+		return "", nil, err
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return "", nil, fmt.Errorf("expected '=' after value name %q", name)
+	}
+	rest = strings.TrimPrefix(rest, "=")
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		value, trailing, err := parseRegString(rest)
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.TrimSpace(trailing) != "" {
+			return "", nil, fmt.Errorf("unexpected content after value for %q", name)
+		}
+		return name, value, nil
+	case strings.HasPrefix(rest, "dword:"):
+		value, err := strconv.ParseUint(strings.TrimPrefix(rest, "dword:"), 16, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid dword value for %q: %w", name, err)
+		}
+		return name, int64(value), nil
+	case strings.HasPrefix(rest, "qword:"):
+		value, err := strconv.ParseUint(strings.TrimPrefix(rest, "qword:"), 16, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid qword value for %q: %w", name, err)
+		}
+		return name, int64(value), nil
+	case strings.HasPrefix(rest, "hex(b):"):
+		bytes, err := parseHexBytes(strings.TrimPrefix(rest, "hex(b):"))
+		if err != nil || len(bytes) != 8 {
+			return "", nil, fmt.Errorf("invalid qword value for %q", name)
+		}
+		var value uint64
+		for i := len(bytes) - 1; i >= 0; i-- {
+			value = value<<8 | uint64(bytes[i])
+		}
+		return name, int64(value), nil
+	case strings.HasPrefix(rest, "hex(7):"):
+		bytes, err := parseHexBytes(strings.TrimPrefix(rest, "hex(7):"))
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid multi-string value for %q: %w", name, err)
+		}
+		return name, multiStringHexBytesToStrings(bytes), nil
+	}
+	return "", nil, fmt.Errorf("unsupported value type for %q: %q", name, rest)
+}
+
+// parseRegString parses a double-quoted string at the start of the input,
+// returning the unescaped string and the remaining input.
+func parseRegString(input string) (string, string, error) {
+	var result strings.Builder
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				i++
+			}
+			result.WriteByte(input[i])
+		case '"':
+			return result.String(), input[i+1:], nil
+		default:
+			result.WriteByte(input[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string in %q", input)
+}
+
+func parseHexBytes(input string) ([]byte, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(input, ",")
+	bytes := make([]byte, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseUint(strings.TrimSpace(part), 16, 8)
+		if err != nil {
+			return nil, err
+		}
+		bytes[i] = byte(value)
+	}
+	return bytes, nil
+}
+
+// multiStringHexBytesToStrings is the inverse of stringToMultiStringHexBytes.
+func multiStringHexBytesToStrings(bytes []byte) []string {
+	words := make([]uint16, len(bytes)/2)
+	for i := range words {
+		words[i] = uint16(bytes[2*i]) | uint16(bytes[2*i+1])<<8
+	}
+	result := []string{}
+	for _, value := range strings.Split(string(utf16.Decode(words)), "\x00") {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package reg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReg(t *testing.T) {
+	t.Run("round-trips generated files", func(t *testing.T) {
+		jsonBody := `{"version": 19, "application": { "adminAccess": true, "extensions": { "allowed": { "list": ["wink", "blink"] } } },
+			"containerEngine": { "name": "moby" }, "kubernetes": { "port": 6443 }, "diagnostics": { "mutedChecks": { "a": true } } }`
+		lines, err := JsonToReg(HkcuRegistryHive, BothProfileTypes, jsonBody)
+		require.NoError(t, err)
+		result, err := ParseReg(strings.Join(lines, "\r\n"))
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"version": int64(19),
+			"application": map[string]interface{}{
+				"adminAccess": int64(1),
+				"extensions": map[string]interface{}{
+					"allowed": map[string]interface{}{"list": []string{"wink", "blink"}},
+				},
+			},
+			"containerEngine": map[string]interface{}{"name": "moby"},
+			"kubernetes":      map[string]interface{}{"port": int64(6443)},
+			"diagnostics": map[string]interface{}{
+				"mutedChecks": map[string]interface{}{"a": int64(1)},
+			},
+		}
+		assert.Equal(t, map[string]map[string]interface{}{DefaultsProfileType: expected, LockedProfileType: expected}, result)
+	})
+
+	t.Run("handles regedit exports", func(t *testing.T) {
+		contents := strings.Join([]string{
+			"Windows Registry Editor Version 5.00",
+			"",
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Policies\Rancher Desktop\locked\containerEngine\allowedImages]`,
+			`"patterns"=hex(7):61,00,00,00,62,00,63,00,00,00,\`,
+			`  00,00`,
+			`"enabled"=dword:00000001`,
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Other]`,
+			`"ignored"="value"`,
+		}, "\r\n")
+		result, err := ParseReg(contents)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]interface{}{
+			LockedProfileType: {
+				"containerEngine": map[string]interface{}{
+					"allowedImages": map[string]interface{}{
+						"patterns": []string{"a", "bc"},
+						"enabled":  int64(1),
+					},
+				},
+			},
+		}, result)
+	})
+
+	t.Run("rejects unknown profile types", func(t *testing.T) {
+		_, err := ParseReg(`[HKEY_CURRENT_USER\SOFTWARE\Policies\Rancher Desktop\unlocked]`)
+		assert.ErrorContains(t, err, `unrecognized profile type "unlocked"`)
+	})
+}
+
+func TestRegToJson(t *testing.T) {
+	t.Run("round-trips settings JSON", func(t *testing.T) {
+		jsonBody := `{"version": 19, "application": {"adminAccess": true, "extensions": {"allowed": {"enabled": false, "list": ["wink"]}}},
+			"containerEngine": {"name": "moby"}, "kubernetes": {"port": 6443}, "diagnostics": {"mutedChecks": {"a": true, "b": false}}}`
+		lines, err := JsonToReg(HklmRegistryHive, LockedProfileType, jsonBody)
+		require.NoError(t, err)
+		sections, err := ParseReg(strings.Join(lines, "\r\n"))
+		require.NoError(t, err)
+		result, err := RegToJson(sections[LockedProfileType])
+		require.NoError(t, err)
+		assert.JSONEq(t, jsonBody, result)
+	})
+
+	t.Run("restores the case of setting names", func(t *testing.T) {
+		result, err := RegToJson(map[string]interface{}{"Application": map[string]interface{}{"ADMINACCESS": int64(0)}})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"application": {"adminAccess": false}}`, result)
+	})
+
+	t.Run("rejects values that don't match the schema", func(t *testing.T) {
+		_, err := RegToJson(map[string]interface{}{"application": map[string]interface{}{"adminAccess": int64(2)}})
+		assert.ErrorContains(t, err, "application.adminAccess")
+		_, err = RegToJson(map[string]interface{}{"colour": "blue"})
+		assert.ErrorContains(t, err, "colour: unknown setting")
+	})
+}
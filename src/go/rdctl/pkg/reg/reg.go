@@ -1,5 +1,6 @@
-// Package reg is responsible for converting ServerSettingsForJSON structures into
-// importable Windows registry files by running `reg import FILE`.
+// Package reg is responsible for converting settings into
+// importable Windows registry files by running `reg import FILE`, and for
+// reading such files (or the registry itself) back into settings JSON.
 //
 // Note that the `reg` command must be run with administrator privileges because it
 // modifies either a section of `HKEY_LOCAL_MACHINE` or `HKEY_CURRENT_USER\SOFTWARE\Policies`,
@@ -10,9 +11,11 @@ package reg
 import (
 	"encoding/json"
 	"fmt"
-	options "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/jsonpath"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"reflect"
+	"sort"
 	"strings"
 	"unicode/utf16"
 )
@@ -20,6 +23,12 @@ import (
 const HkcuRegistryHive = "hkcu"
 const HklmRegistryHive = "hklm"
 
+const DefaultsProfileType = "defaults"
+const LockedProfileType = "locked"
+
+// BothProfileTypes generates both the defaults and the locked sections from the same settings.
+const BothProfileTypes = "both"
+
 func escape(s string) string {
 	s1 := strings.ReplaceAll(s, "\\", "\\\\")
 	return strings.ReplaceAll(s1, `"`, `\\"`)
@@ -29,7 +38,7 @@ func escape(s string) string {
 //
 // Params:
 // pathParts: represents the registry path to the current item
-// structType: type information on the current field for the `value` parameter
+// schema: the schema of the current setting, describing the `value` parameter
 // value: the reflected value of the current field, based on a simple map[string]interface{} JSON-parse
 // jsonTag: the name of the field, used in json (and the registry)
 // path: a dotted representation of the fully-qualified name of the field
@@ -38,37 +47,38 @@ func escape(s string) string {
 //
 //	an array of lines representing the current value
 //	an error: the only non-nil error this function can return is when it encounters an unhandled value type
-func convertToRegFormat(pathParts []string, structType reflect.Type, value reflect.Value, jsonTag, path string) ([]string, error) {
-	kind := structType.Kind()
-	if value.Kind() == reflect.Interface && kind != reflect.Interface {
+func convertToRegFormat(pathParts []string, schema *jsonpath.Schema, value reflect.Value, jsonTag, path string) ([]string, error) {
+	if value.Kind() == reflect.Interface {
 		if value.IsNil() {
 			return nil, nil
 		}
-		return convertToRegFormat(pathParts, structType, value.Elem(), jsonTag, path)
+		return convertToRegFormat(pathParts, schema, value.Elem(), jsonTag, path)
 	}
 	if value.Kind() == reflect.Ptr {
-		return nil, fmt.Errorf("reg-file generation: got an unexpected pointer for %s value %v, expecting type %v", path, value, structType)
+		return nil, fmt.Errorf("reg-file generation: got an unexpected pointer for %s value %v", path, value)
 	}
-	switch kind {
-	case reflect.Struct:
-		// Processing here is similar to struct fields in plist.go
+	switch schema.Kind {
+	case jsonpath.Group:
+		// Processing here is similar to groups in plist.go
 		// In the plist world we want to order the fields according to their
-		// position in the defined ServerSettingsForJSON struct.
+		// position in the settings schema.
 		// In the registry world the fields are ordered alphabetically ignoring case.
 		//
 		if value.Kind() != reflect.Map {
-			return nil, fmt.Errorf("expecting actual kind for a typed struct to be a map, got %v", value.Kind())
+			return nil, fmt.Errorf("expecting actual kind for a group of settings to be a map, got %v", value.Kind())
 		}
-		numTypedFields := structType.NumField()
-		sortedStructFields := utils.SortStructFields(structType)
-		scalarReturnedLines := make([]string, 0, numTypedFields)
+		fields := append([]jsonpath.Field{}, schema.Fields...)
+		sort.Slice(fields, func(i, j int) bool {
+			return strings.ToLower(fields[i].Name) < strings.ToLower(fields[j].Name)
+		})
+		scalarReturnedLines := make([]string, 0, len(fields))
 		nestedReturnedLines := make([]string, 0)
-		for _, compoundStructField := range sortedStructFields {
-			fieldName := compoundStructField.FieldName
+		for _, field := range fields {
+			fieldName := field.Name
 			valueElement := value.MapIndex(reflect.ValueOf(fieldName))
 			if valueElement.IsValid() {
 				newRetLines, err := convertToRegFormat(append(pathParts, fieldName),
-					compoundStructField.StructField.Type,
+					field.Schema,
 					valueElement,
 					fieldName,
 					path+"."+fieldName)
@@ -94,9 +104,7 @@ func convertToRegFormat(pathParts []string, structType reflect.Type, value refle
 		retLines = append(retLines, scalarReturnedLines...)
 		retLines = append(retLines, nestedReturnedLines...)
 		return retLines, nil
-	case reflect.Ptr:
-		return convertToRegFormat(pathParts, structType.Elem(), value, jsonTag, path)
-	case reflect.Slice, reflect.Array:
+	case jsonpath.List:
 		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
 			return nil, fmt.Errorf("expected slice or array at %s, got %v", path, value.Kind())
 		}
@@ -111,12 +119,15 @@ func convertToRegFormat(pathParts []string, structType reflect.Type, value refle
 			arrayValues[i] = item.String()
 		}
 		return []string{fmt.Sprintf(`"%s"=hex(7):%s`, jsonTag, stringToMultiStringHexBytes(arrayValues))}, nil
-	case reflect.Map:
+	case jsonpath.Map:
+		if value.Kind() != reflect.Map {
+			return nil, fmt.Errorf("expecting actual kind for free-form settings at %s to be a map, got %v", path, value.Kind())
+		}
 		returnedLines := []string{fmt.Sprintf("[%s]", strings.Join(pathParts, "\\"))}
 		mapKeys := utils.SortKeys(value.MapKeys())
 		for _, mapKey := range mapKeys {
 			keyAsString := mapKey.StringKey
-			innerLines, err := convertToRegFormat(append(pathParts, keyAsString), structType.Elem(), value.MapIndex(mapKey.MapKey), keyAsString, path+"."+keyAsString)
+			innerLines, err := convertToRegFormat(append(pathParts, keyAsString), anySchema, value.MapIndex(mapKey.MapKey), keyAsString, path+"."+keyAsString)
 			if err != nil {
 				return nil, err
 			} else if len(innerLines) > 0 {
@@ -124,37 +135,33 @@ func convertToRegFormat(pathParts []string, structType reflect.Type, value refle
 			}
 		}
 		return returnedLines, nil
-	case reflect.Interface:
+	case jsonpath.Any:
 		// Since we allow whatever here, just use the actual type of the value.
-		// But if it's an interface{} we'll need to dereference it first to avoid
-		// an infinite loop.
-		for value.Kind() == reflect.Interface {
-			value = value.Elem()
+		if valueSchema := jsonpath.SchemaOf(value.Interface()); valueSchema.Kind != jsonpath.Any {
+			return convertToRegFormat(pathParts, valueSchema, value, jsonTag, path)
 		}
-		return convertToRegFormat(pathParts, value.Type(), value, jsonTag, path)
-	case reflect.Bool:
+	case jsonpath.Bool:
 		boolValue := map[bool]int{true: 1, false: 0}[value.Bool()]
 		return []string{fmt.Sprintf(`"%s"=dword:%d`, jsonTag, boolValue)}, nil
-	case reflect.Int, reflect.Int8, reflect.Int16,
-		reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16,
-		reflect.Uint32:
+	case jsonpath.Int:
 		if value.CanConvert(reflect.TypeOf(int64(0))) {
 			value = value.Convert(reflect.TypeOf(int64(0)))
 		}
 		return []string{fmt.Sprintf(`"%s"=dword:%x`, jsonTag, value.Int())}, nil
-	case reflect.Int64, reflect.Uint64:
-		if value.CanConvert(reflect.TypeOf(int64(0))) {
-			value = value.Convert(reflect.TypeOf(int64(0)))
+	case jsonpath.Number:
+		if value.CanConvert(reflect.TypeOf(float64(0))) {
+			value = value.Convert(reflect.TypeOf(float64(0)))
 		}
-		return []string{fmt.Sprintf(`"%s"=qword:%x`, jsonTag, value.Int())}, nil
-	case reflect.Float32, reflect.Float64:
 		return []string{fmt.Sprintf(`"%s"=dword:%x`, jsonTag, int(value.Float()))}, nil
-	case reflect.String:
+	case jsonpath.String:
 		return []string{fmt.Sprintf(`"%s"="%s"`, jsonTag, escape(value.String()))}, nil
 	}
-	return nil, fmt.Errorf("convertToRegFormat: don't know how to process %s kind: %q, (%T), value: %v for var %q\n", path, kind, structType, value, jsonTag)
+	return nil, fmt.Errorf("convertToRegFormat: don't know how to process %s kind: %d, value: %v for var %q\n", path, schema.Kind, value, jsonTag)
 }
 
+// anySchema describes the entries of free-form settings.
+var anySchema = &jsonpath.Schema{Kind: jsonpath.Any}
+
 // Encode multi-stringSZ settings in comma-separated ucs2 little-endian bytes
 // e.g.=> ["abc", "def"] would be ucs-2-encoded as '61,00,62,00,63,00,00,00,64,00,65,00,66,00,00,00,00,00'
 // where a null 16-bit word (so two 00 bytes) separate each pair of words and
@@ -176,27 +183,35 @@ func stringToMultiStringHexBytes(values []string) string {
 
 // JsonToReg - convert the json settings to a reg file
 // @param hiveType: "hklm" or "hkcu"
-// @param profileType: "defaults" or "locked"
+// @param profileType: "defaults", "locked", or "both"
 // @param settingsBodyAsJSON - options marshaled as JSON
 // @returns: array of strings, intended for writing to a reg file
 func JsonToReg(hiveType string, profileType string, settingsBodyAsJSON string) ([]string, error) {
 	var actualSettingsJSON map[string]interface{}
 
-	fullHiveType, ok := map[string]string{"hklm": "HKEY_LOCAL_MACHINE", "hkcu": "HKEY_CURRENT_USER"}[hiveType]
+	fullHiveType, ok := map[string]string{HklmRegistryHive: "HKEY_LOCAL_MACHINE", HkcuRegistryHive: "HKEY_CURRENT_USER"}[hiveType]
 	if !ok {
-		return nil, fmt.Errorf(`unrecognized hiveType of %q, must be "hklm" or "hkcu"`, hiveType)
+		return nil, fmt.Errorf(`unrecognized hiveType of %q, must be %q or %q`, hiveType, HklmRegistryHive, HkcuRegistryHive)
 	}
-	_, ok = map[string]bool{"defaults": true, "locked": true}[profileType]
+	profileTypes, ok := map[string][]string{
+		DefaultsProfileType: {DefaultsProfileType},
+		LockedProfileType:   {LockedProfileType},
+		BothProfileTypes:    {DefaultsProfileType, LockedProfileType},
+	}[profileType]
 	if !ok {
-		return nil, fmt.Errorf(`unrecognized profileType of %q, must be "defaults" or "locked"`, profileType)
+		return nil, fmt.Errorf(`unrecognized profileType of %q, must be %q, %q, or %q`, profileType, DefaultsProfileType, LockedProfileType, BothProfileTypes)
 	}
 	if err := json.Unmarshal([]byte(settingsBodyAsJSON), &actualSettingsJSON); err != nil {
 		return nil, fmt.Errorf("error in json: %s", err)
 	}
 	headerLines := []string{"Windows Registry Editor Version 5.00"}
-	bodyLines, err := convertToRegFormat([]string{fullHiveType, "SOFTWARE", "Policies", "Rancher Desktop", profileType}, reflect.TypeOf(options.ServerSettingsForJSON{}), reflect.ValueOf(actualSettingsJSON), "", "")
-	if err != nil {
-		return nil, err
+	var bodyLines []string
+	for _, profileType := range profileTypes {
+		lines, err := convertToRegFormat([]string{fullHiveType, "SOFTWARE", "Policies", "Rancher Desktop", profileType}, client.SettingsSchema, reflect.ValueOf(actualSettingsJSON), "", "")
+		if err != nil {
+			return nil, err
+		}
+		bodyLines = append(bodyLines, lines...)
 	}
 	if len(bodyLines) > 0 {
 		headerLines = append(headerLines, fmt.Sprintf("[%s\\%s\\%s]", fullHiveType, "SOFTWARE", "Policies"))
@@ -29,12 +29,12 @@ func TestJsonToRegFormat(t *testing.T) {
 			{
 				hiveType:      "hkcu",
 				profileType:   "bad-profile",
-				expectedError: `unrecognized profileType of "bad-profile", must be "defaults" or "locked"`,
+				expectedError: `unrecognized profileType of "bad-profile", must be "defaults", "locked", or "both"`,
 			},
 			{
 				hiveType:      "hklm",
 				profileType:   "bad-profile",
-				expectedError: `unrecognized profileType of "bad-profile", must be "defaults" or "locked"`,
+				expectedError: `unrecognized profileType of "bad-profile", must be "defaults", "locked", or "both"`,
 			},
 		}
 		for _, testCase := range testCases {
@@ -92,6 +92,21 @@ func TestJsonToRegFormat(t *testing.T) {
 		}
 	})
 
+	t.Run("generates both sections", func(t *testing.T) {
+		jsonBody := `{"version": 19}`
+		lines, err := JsonToReg("hklm", "both", jsonBody)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"Windows Registry Editor Version 5.00",
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Policies]`,
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Policies\Rancher Desktop]`,
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Policies\Rancher Desktop\defaults]`,
+			`"version"=dword:13`,
+			`[HKEY_LOCAL_MACHINE\SOFTWARE\Policies\Rancher Desktop\locked]`,
+			`"version"=dword:13`,
+		}, lines)
+	})
+
 	t.Run("Handles arrays", func(t *testing.T) {
 		jsonBody := `{"application": { "extensions": { "allowed": {
         "enabled": false,
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package reg
+
+import "errors"
+
+// ReadRegistry reads the deployment profile installed in the registry; this is
+// only possible on Windows.
+func ReadRegistry(hiveType string) (map[string]map[string]interface{}, error) {
+	return nil, errors.New("reading deployment profiles from the registry is only supported on Windows")
+}
@@ -0,0 +1,99 @@
+package reg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// ReadRegistry reads the deployment profile installed in the registry of the
+// given hive ("hklm" or "hkcu"), returning the settings in the same form as
+// ParseReg.  A missing policy key results in an empty profile.
+func ReadRegistry(hiveType string) (map[string]map[string]interface{}, error) {
+	hive, ok := map[string]registry.Key{HklmRegistryHive: registry.LOCAL_MACHINE, HkcuRegistryHive: registry.CURRENT_USER}[hiveType]
+	if !ok {
+		return nil, fmt.Errorf(`unrecognized hiveType of %q, must be %q or %q`, hiveType, HklmRegistryHive, HkcuRegistryHive)
+	}
+	result := map[string]map[string]interface{}{}
+	for _, profileType := range []string{DefaultsProfileType, LockedProfileType} {
+		keyPath := policyKeyPath + profileType
+		key, err := registry.OpenKey(hive, keyPath, registry.READ)
+		if err != nil {
+			if errors.Is(err, registry.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open registry key %q: %w", keyPath, err)
+		}
+		values, err := readRegistryKey(key, keyPath)
+		key.Close()
+		if err != nil {
+			return nil, err
+		}
+		result[profileType] = values
+	}
+	return result, nil
+}
+
+// readRegistryKey returns the values and subkeys of the given key.
+func readRegistryKey(key registry.Key, keyPath string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values of %q: %w", keyPath, err)
+	}
+	for _, name := range names {
+		if name == "" {
+			// Default values are not used by the policy key.
+			continue
+		}
+		_, valueType, err := key.GetValue(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q in %q: %w", name, keyPath, err)
+		}
+		switch valueType {
+		case registry.DWORD, registry.QWORD:
+			value, _, err := key.GetIntegerValue(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %q in %q: %w", name, keyPath, err)
+			}
+			result[name] = int64(value)
+		case registry.SZ, registry.EXPAND_SZ:
+			value, _, err := key.GetStringValue(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %q in %q: %w", name, keyPath, err)
+			}
+			result[name] = value
+		case registry.MULTI_SZ:
+			value, _, err := key.GetStringsValue(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %q in %q: %w", name, keyPath, err)
+			}
+			if value == nil {
+				value = []string{}
+			}
+			result[name] = value
+		default:
+			return nil, fmt.Errorf("unsupported type %d for %q in %q", valueType, name, keyPath)
+		}
+	}
+	subkeys, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subkeys of %q: %w", keyPath, err)
+	}
+	for _, subkeyName := range subkeys {
+		subkeyPath := strings.Join([]string{keyPath, subkeyName}, `\`)
+		subkey, err := registry.OpenKey(key, subkeyName, registry.READ)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open registry key %q: %w", subkeyPath, err)
+		}
+		values, err := readRegistryKey(subkey, subkeyPath)
+		subkey.Close()
+		if err != nil {
+			return nil, err
+		}
+		result[subkeyName] = values
+	}
+	return result, nil
+}
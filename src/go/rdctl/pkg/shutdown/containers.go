@@ -0,0 +1,149 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
+	"github.com/sirupsen/logrus"
+)
+
+// Container engines, as named by the `containerEngine.name` setting.
+const (
+	ContainerEngineMoby       = "moby"
+	ContainerEngineContainerd = "containerd"
+)
+
+// StopContainers asks the given container engine to stop all running
+// containers, so they get a chance to shut down cleanly before the VM goes
+// away.  No timeout is passed to the engine, so each container's own stop
+// timeout (and stop signal) applies.
+func StopContainers(containerEngine string) error {
+	var engineCommand []string
+	switch containerEngine {
+	case ContainerEngineMoby:
+		engineCommand = []string{"docker"}
+	case ContainerEngineContainerd:
+		engineCommand = []string{"nerdctl"}
+	default:
+		return fmt.Errorf("unknown container engine %q", containerEngine)
+	}
+	output, err := runInVM(append(engineCommand, "ps", "--quiet")...)
+	if err != nil {
+		return fmt.Errorf("failed to list running containers: %w", err)
+	}
+	containerIDs := parseContainerIDs(output)
+	if len(containerIDs) == 0 {
+		logrus.Debugln("No running containers to stop")
+		return nil
+	}
+	logrus.Infof("Stopping %d running container(s)", len(containerIDs))
+	args := append(append(engineCommand, "stop"), containerIDs...)
+	if _, err = runInVM(args...); err != nil {
+		return fmt.Errorf("failed to stop containers: %w", err)
+	}
+	return nil
+}
+
+// kubernetesStateScript stops Kubernetes and removes its state, but not the
+// images it uses (which are in the container engine, or in the k3s images
+// directory).  On WSL, services are managed through wsl-service.
+const kubernetesStateScript = `
+if [ -x /usr/local/bin/wsl-service ]; then
+  /usr/local/bin/wsl-service --ifstarted k3s stop
+elif [ -e /etc/init.d/k3s ]; then
+  /sbin/rc-service --ifstarted k3s stop
+fi
+rm -rf /etc/rancher/k3s /var/lib/kubelet /var/lib/cri-dockerd /var/lib/rancher/k3s/server
+if [ -d /var/lib/rancher/k3s/agent ]; then
+  find /var/lib/rancher/k3s/agent -mindepth 1 -maxdepth 1 ! -name images -exec rm -rf {} +
+fi
+`
+
+// Scripts that remove the containers, volumes and networks of each container
+// engine, keeping the images; for containerd, this covers every namespace.
+var containerStateScripts = map[string]string{
+	ContainerEngineMoby: `
+docker ps --all --quiet | xargs -r docker rm --force --volumes
+docker volume ls --quiet | xargs -r docker volume rm --force
+docker network prune --force
+`,
+	ContainerEngineContainerd: `
+for namespace in $(nerdctl namespace list --quiet); do
+  nerdctl --namespace "$namespace" ps --all --quiet | xargs -r nerdctl --namespace "$namespace" rm --force --volumes
+  nerdctl --namespace "$namespace" volume ls --quiet | xargs -r nerdctl --namespace "$namespace" volume rm
+  nerdctl --namespace "$namespace" network prune --force
+done
+`,
+}
+
+// containerStateScript returns the script that removes everything but the
+// images from the given container engine and from Kubernetes.
+func containerStateScript(containerEngine string) (string, error) {
+	script, ok := containerStateScripts[containerEngine]
+	if !ok {
+		return "", fmt.Errorf("unknown container engine %q", containerEngine)
+	}
+	return "set -e\n" + kubernetesStateScript + script, nil
+}
+
+// RemoveContainerState removes everything but the images from the VM: all
+// containers, volumes and networks of the given container engine, and the
+// Kubernetes state.  Kubernetes is stopped first so it doesn't recreate its
+// containers.  The container engine must be running.  This lets a factory
+// reset keep the VM (and so the image store) while starting afresh otherwise.
+func RemoveContainerState(containerEngine string) error {
+	script, err := containerStateScript(containerEngine)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Removing containers, volumes, networks and Kubernetes state")
+	if _, err = runInVM("sh", "-c", script); err != nil {
+		return fmt.Errorf("failed to remove the container engine state: %w", err)
+	}
+	return nil
+}
+
+// parseContainerIDs returns the container IDs from the output of `ps --quiet`.
+func parseContainerIDs(output string) []string {
+	return strings.Fields(output)
+}
+
+// runInVM runs the given command as root in the Rancher Desktop VM (or WSL
+// distribution) and returns its output.
+func runInVM(args ...string) (string, error) {
+	if runtime.GOOS == "windows" {
+		// Stopping containers can take a while, so there is no timeout.
+		wslArgs := append([]string{"--distribution", p.MainDistro(), "--exec", "/usr/local/bin/wsl-exec"}, args...)
+		output, err := wslexe.RunWithOptions(context.Background(), wslexe.Options{}, wslArgs...)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", strings.Join(args, " "), err)
+		}
+		return output, nil
+	}
+	paths, err := p.GetPaths()
+	if err != nil {
+		return "", err
+	}
+	if err = directories.SetupLimaHome(paths.Lima); err != nil {
+		return "", err
+	}
+	limactl, err := directories.GetLimactlPath()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(limactl, append([]string{"shell", "0", "sudo"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
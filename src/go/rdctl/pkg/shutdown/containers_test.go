@@ -0,0 +1,26 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerIDs(t *testing.T) {
+	assert.Empty(t, parseContainerIDs(""))
+	assert.Equal(t, []string{"0123456789ab", "ba9876543210"}, parseContainerIDs("0123456789ab\nba9876543210\n"))
+	assert.Equal(t, []string{"0123456789ab"}, parseContainerIDs("0123456789ab\r\n"))
+}
+
+func TestContainerStateScript(t *testing.T) {
+	for _, engine := range []string{ContainerEngineMoby, ContainerEngineContainerd} {
+		script, err := containerStateScript(engine)
+		if assert.NoError(t, err, engine) {
+			assert.Contains(t, script, "rc-service --ifstarted k3s stop", engine)
+			assert.Contains(t, script, " rm --force --volumes", engine)
+			assert.NotRegexp(t, `\brmi\b|image (rm|prune)`, script, engine)
+		}
+	}
+	_, err := containerStateScript("podman")
+	assert.Error(t, err)
+}
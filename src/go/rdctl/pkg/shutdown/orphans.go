@@ -0,0 +1,157 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of processes that can be left behind by a crashed session, in the
+// order they should be stopped: the lima host agent normally takes care of
+// the rest, so it goes first.
+const (
+	OrphanHostAgent = "hostagent"
+	OrphanQemu      = "qemu"
+	OrphanVirtiofsd = "virtiofsd"
+	OrphanSSH       = "ssh"
+)
+
+var orphanKindOrder = []string{OrphanHostAgent, OrphanQemu, OrphanVirtiofsd, OrphanSSH}
+
+// OrphanProcess is a VM-related process that is still running after Rancher
+// Desktop has exited.
+type OrphanProcess struct {
+	PID     int    `json:"pid"`
+	Kind    string `json:"kind"`
+	Command string `json:"command"`
+}
+
+// FindOrphans returns the VM-related processes belonging to this Rancher
+// Desktop installation (their executable is in the application resources, or
+// they refer to the lima directory) that are still running.  As these are only
+// orphans if the application isn't running, this fails if it is.
+func FindOrphans() ([]OrphanProcess, error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("detecting orphaned processes is not supported on Windows")
+	}
+	paths, err := p.GetPaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paths: %w", err)
+	}
+	checkApp := checkProcessLinux
+	if runtime.GOOS == "darwin" {
+		checkApp = checkProcessDarwin
+	}
+	if running, _ := checkApp(); running {
+		return nil, errors.New("Rancher Desktop is still running; shut it down before cleaning up orphaned processes")
+	}
+	processes, err := process.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return findOrphans(processes, paths.Resources, paths.Lima), nil
+}
+
+// findOrphans picks the orphaned processes out of the running ones.
+func findOrphans(processes []process.Process, resourcesDir, limaDir string) []OrphanProcess {
+	var orphans []OrphanProcess
+	for _, proc := range processes {
+		if !strings.HasPrefix(proc.Executable, resourcesDir+"/") && !strings.Contains(proc.CommandLine, limaDir+"/") {
+			continue
+		}
+		if kind := orphanKind(path.Base(proc.Executable), proc.CommandLine); kind != "" {
+			orphans = append(orphans, OrphanProcess{PID: proc.PID, Kind: kind, Command: proc.CommandLine})
+		}
+	}
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return kindIndex(orphans[i].Kind) < kindIndex(orphans[j].Kind)
+	})
+	return orphans
+}
+
+// orphanKind returns the kind of the process with the given executable name,
+// or an empty string for processes that should be left alone.
+func orphanKind(executable, args string) string {
+	switch {
+	case strings.HasPrefix(executable, "limactl") && strings.Contains(args, " hostagent "):
+		return OrphanHostAgent
+	case strings.HasPrefix(executable, "qemu-system-"):
+		return OrphanQemu
+	case executable == "virtiofsd":
+		return OrphanVirtiofsd
+	case executable == "ssh":
+		return OrphanSSH
+	}
+	return ""
+}
+
+func kindIndex(kind string) int {
+	for i, k := range orphanKindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(orphanKindOrder)
+}
+
+// CleanupOrphans stops the given orphaned processes, escalating from SIGTERM
+// to SIGKILL as configured by the options.  It returns the processes that
+// were stopped.
+func CleanupOrphans(orphans []OrphanProcess, options Options) ([]OrphanProcess, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	var stopped []OrphanProcess
+	var errs []error
+	for _, orphan := range orphans {
+		if !process.IsRunning(orphan.PID) {
+			// Stopping the host agent can take other processes with it.
+			logrus.Debugf("orphaned %s process %d has already exited", orphan.Kind, orphan.PID)
+			continue
+		}
+		if err := stopProcess(orphan.PID, options.TermTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop orphaned %s process %d: %w", orphan.Kind, orphan.PID, err))
+			continue
+		}
+		stopped = append(stopped, orphan)
+	}
+	return stopped, errors.Join(errs...)
+}
+
+// stopProcess sends SIGTERM to the process, and SIGKILL if it hasn't exited
+// within the TERM timeout (right away if that is zero).
+func stopProcess(pid int, termTimeout time.Duration) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if termTimeout > 0 {
+		if err := proc.Signal(syscall.SIGTERM); err != nil {
+			if errors.Is(err, os.ErrProcessDone) {
+				return nil
+			}
+			return fmt.Errorf("failed to send SIGTERM: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), termTimeout)
+		defer cancel()
+		if err := process.WaitForProcessExit(ctx, pid); err == nil {
+			return nil
+		}
+		logrus.Debugf("process %d did not exit within %s of SIGTERM", pid, termTimeout)
+	}
+	if err := proc.Signal(syscall.SIGKILL); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to send SIGKILL: %w", err)
+	}
+	return nil
+}
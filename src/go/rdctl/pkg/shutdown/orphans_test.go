@@ -0,0 +1,26 @@
+package shutdown
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOrphans(t *testing.T) {
+	resourcesDir := "/Applications/Rancher Desktop.app/Contents/Resources/resources/darwin"
+	limaDir := "/Users/me/Library/Application Support/rancher-desktop/lima"
+	processes := []process.Process{
+		{PID: 1, Executable: "/sbin/launchd", CommandLine: "/sbin/launchd"},
+		{PID: 100, Executable: resourcesDir + "/lima/bin/qemu-system-aarch64", CommandLine: resourcesDir + "/lima/bin/qemu-system-aarch64 -m 4096 -drive file=" + limaDir + "/0/diffdisk"},
+		{PID: 101, Executable: resourcesDir + "/lima/bin/limactl", CommandLine: resourcesDir + "/lima/bin/limactl hostagent --pidfile " + limaDir + "/0/ha.pid 0"},
+		{PID: 102, Executable: "/usr/bin/ssh", CommandLine: "ssh -F " + limaDir + "/0/ssh.config -L 6443:127.0.0.1:6443 lima-0"},
+		{PID: 103, Executable: "/usr/bin/vim", CommandLine: "vim " + limaDir + "/0/lima.yaml"},
+		{PID: 105, Executable: "/usr/bin/qemu-system-x86_64", CommandLine: "/usr/bin/qemu-system-x86_64 -m 1024"},
+	}
+	assert.Equal(t, []OrphanProcess{
+		{PID: 101, Kind: OrphanHostAgent, Command: processes[2].CommandLine},
+		{PID: 100, Kind: OrphanQemu, Command: processes[1].CommandLine},
+		{PID: 102, Kind: OrphanSSH, Command: processes[3].CommandLine},
+	}, findOrphans(processes, resourcesDir, limaDir))
+}
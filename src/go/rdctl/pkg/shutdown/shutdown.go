@@ -17,22 +17,86 @@ limitations under the License.
 package shutdown
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/factoryreset"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/tracing"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type shutdownData struct {
-	waitForShutdown bool
+	Options
+	// ctx cancels the commands run during the shutdown.
+	ctx context.Context
+	// traceCtx is the parent of the trace spans for each phase of the
+	// shutdown; it doesn't cancel anything.
+	traceCtx context.Context
+}
+
+// Options controls how long FinishShutdown waits for each component to stop
+// on its own, and how it escalates when it doesn't.
+type Options struct {
+	// WaitForShutdown waits for components to stop before killing them; if
+	// false, they are killed right away.
+	WaitForShutdown bool
+	// VMTimeout is how long to wait for the VM (lima and qemu) to stop.
+	VMTimeout time.Duration
+	// AppTimeout is how long to wait for the application to exit.
+	AppTimeout time.Duration
+	// PollInterval is how often to check whether a component has stopped.
+	PollInterval time.Duration
+	// TermTimeout is how long to wait for a process to exit after sending it
+	// SIGTERM, before sending SIGKILL.  If zero, SIGKILL is sent right away.
+	// Not used on Windows.
+	TermTimeout time.Duration
+}
+
+// DefaultOptions returns the options used when none are specified.
+func DefaultOptions() Options {
+	options := Options{
+		WaitForShutdown: true,
+		VMTimeout:       30 * time.Second,
+		AppTimeout:      5 * time.Second,
+		PollInterval:    time.Second,
+		TermTimeout:     5 * time.Second,
+	}
+	if runtime.GOOS == "windows" {
+		options.AppTimeout = 30 * time.Second
+	}
+	return options
+}
+
+// Validate checks that the options make sense.
+func (o Options) Validate() error {
+	for name, duration := range map[string]time.Duration{
+		"VM timeout":    o.VMTimeout,
+		"app timeout":   o.AppTimeout,
+		"TERM timeout":  o.TermTimeout,
+		"poll interval": o.PollInterval,
+	} {
+		if duration < 0 {
+			return fmt.Errorf("the %s can't be negative (got %s)", name, duration)
+		}
+	}
+	if o.PollInterval == 0 {
+		return fmt.Errorf("the poll interval must be positive")
+	}
+	return nil
 }
 
 type InitiatingCommand string
@@ -44,23 +108,31 @@ const (
 
 var limaCtlPath string
 
-func newShutdownData(waitForShutdown bool) *shutdownData {
-	return &shutdownData{waitForShutdown: waitForShutdown}
+// limaStopTimeout bounds `limactl stop`, which itself waits up to three minutes
+// for the VM to shut down; other commands get execctx.DefaultTimeout.
+const limaStopTimeout = 4 * time.Minute
+
+func newShutdownData(options Options) *shutdownData {
+	return &shutdownData{Options: options, ctx: context.Background(), traceCtx: context.Background()}
 }
 
 // FinishShutdown - ensures that none of the Rancher Desktop related processes are around
 // after a graceful shutdown command has been sent as part of either `rdctl shutdown` or
 // `rdctl factory-reset`.
-func FinishShutdown(waitForShutdown bool, initiatingCommand InitiatingCommand) error {
-	s := newShutdownData(waitForShutdown)
+func FinishShutdown(ctx context.Context, options Options, initiatingCommand InitiatingCommand) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	s := newShutdownData(options)
+	s.ctx, s.traceCtx = ctx, ctx
 	if runtime.GOOS == "windows" {
-		return s.waitForAppToDieOrKillIt(factoryreset.CheckProcessWindows, factoryreset.KillRancherDesktop, 15, 2, "the app")
+		return s.waitForAppToDieOrKillIt(factoryreset.CheckProcessWindows, factoryreset.KillRancherDesktop, s.AppTimeout, "the app")
 	}
 	var err error
 	paths, err := p.GetPaths()
 	if err != nil {
 		logrus.Errorf("Ignoring error trying to get application paths: %s", err)
-	} else if err = directories.SetupLimaHome(paths.AppHome); err != nil {
+	} else if err = directories.SetupLimaHome(paths.Lima); err != nil {
 		logrus.Errorf("Ignoring error trying to get lima directory: %s", err)
 	} else {
 		limaCtlPath, err = directories.GetLimactlPath()
@@ -69,17 +141,17 @@ func FinishShutdown(waitForShutdown bool, initiatingCommand InitiatingCommand) e
 		} else {
 			switch initiatingCommand {
 			case Shutdown:
-				err = s.waitForAppToDieOrKillIt(checkLima, stopLima, 15, 2, "lima")
+				err = s.waitForAppToDieOrKillIt(s.checkLima, s.stopLima, s.VMTimeout, "lima")
 				if err != nil {
 					logrus.Errorf("Ignoring error trying to stop lima: %s", err)
 				}
 				// Check once more to see if lima is still running, and if so, run `limactl stop --force 0`
-				err = s.waitForAppToDieOrKillIt(checkLima, stopLimaWithForce, 1, 0, "lima")
+				err = s.waitForAppToDieOrKillIt(s.checkLima, s.stopLimaWithForce, 0, "lima")
 				if err != nil {
 					logrus.Errorf("Ignoring error trying to force-stop lima: %s", err)
 				}
 			case FactoryReset:
-				err = s.waitForAppToDieOrKillIt(checkLima, deleteLima, 15, 2, "lima")
+				err = s.waitForAppToDieOrKillIt(s.checkLima, s.deleteLima, s.VMTimeout, "lima")
 				if err != nil {
 					logrus.Errorf("Ignoring error trying to delete lima subtree: %s", err)
 				}
@@ -88,26 +160,32 @@ func FinishShutdown(waitForShutdown bool, initiatingCommand InitiatingCommand) e
 			}
 		}
 	}
-	err = s.waitForAppToDieOrKillIt(checkProcessQemu, pkillQemu, 15, 2, "qemu")
+	err = s.waitForAppToDieOrKillIt(checkProcessQemu, s.escalate(checkProcessQemu, pkillQemu, "qemu"), s.VMTimeout, "qemu")
 	if err != nil {
 		logrus.Errorf("Ignoring error trying to kill qemu: %s", err)
 	}
 	switch runtime.GOOS {
 	case "darwin":
-		return s.waitForAppToDieOrKillIt(checkProcessDarwin, pkillDarwin, 5, 1, "the app")
+		return s.waitForAppToDieOrKillIt(checkProcessDarwin, s.escalate(checkProcessDarwin, pkillDarwin, "the app"), s.AppTimeout, "the app")
 	case "linux":
-		return s.waitForAppToDieOrKillIt(checkProcessLinux, pkillLinux, 5, 1, "the app")
+		return s.waitForAppToDieOrKillIt(checkProcessLinux, s.escalate(checkProcessLinux, pkillLinux, "the app"), s.AppTimeout, "the app")
 	default:
 		return fmt.Errorf("unhandled runtime: %q", runtime.GOOS)
 	}
 }
 
-func (s *shutdownData) waitForAppToDieOrKillIt(checkFunc func() (bool, error), killFunc func() error, retryCount int, retryWait int, operation string) error {
-	for iter := 0; s.waitForShutdown && iter < retryCount; iter++ {
-		if iter > 0 {
-			logrus.Debugf("checking %s showed it's still running; sleeping %d seconds\n", operation, retryWait)
-			time.Sleep(time.Duration(retryWait) * time.Second)
-		}
+// waitForAppToDieOrKillIt polls checkFunc until it reports that the operation
+// is no longer running, or the timeout expires; in the latter case, killFunc
+// is called.  The check is always made at least once.
+func (s *shutdownData) waitForAppToDieOrKillIt(checkFunc func() (bool, error), killFunc func() error, timeout time.Duration, operation string) (err error) {
+	var span trace.Span
+	s.traceCtx, span = tracing.Start(s.traceCtx, "shutdown.wait", attribute.String("shutdown.operation", operation))
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for s.WaitForShutdown {
 		status, err := checkFunc()
 		if err != nil {
 			return fmt.Errorf("while checking %s, found error: %w", operation, err)
@@ -116,71 +194,121 @@ func (s *shutdownData) waitForAppToDieOrKillIt(checkFunc func() (bool, error), k
 			logrus.Debugf("%s is no longer running\n", operation)
 			return nil
 		}
+		logrus.Debugf("checking %s showed it's still running; waiting up to %s\n", operation, s.PollInterval)
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			continue
+		}
+		break
 	}
 	logrus.Debugf("About to force-kill %s\n", operation)
+	span.AddEvent("kill")
 	return killFunc()
 }
 
+// escalate returns a kill function that first sends SIGTERM, waits up to the
+// TERM timeout for the process to exit, and then sends SIGKILL.
+func (s *shutdownData) escalate(checkFunc func() (bool, error), signalFunc func(signal string) error, operation string) func() error {
+	return func() error {
+		if s.TermTimeout > 0 {
+			logrus.Debugf("Sending SIGTERM to %s\n", operation)
+			if err := signalFunc("TERM"); err != nil {
+				logrus.Errorf("Ignoring error trying to terminate %s: %s", operation, err)
+			}
+			terminated := *s
+			terminated.WaitForShutdown = true
+			return terminated.waitForAppToDieOrKillIt(checkFunc, func() error { return signalFunc("KILL") }, s.TermTimeout, operation)
+		}
+		return signalFunc("KILL")
+	}
+}
+
 /**
- * checkProcessX function returns [true, nil] if it detects the app is still running, [false, X] otherwise
- * The Linux/macOS functions never return a non-nil error and that field can be ignored.
- * If the Windows function returns a non-nil error, we can't conclude whether the specified process is running
+ * checkProcessX function returns [true, nil] if it detects the app is still running, [false, nil] if it doesn't.
+ * If the function returns a non-nil error, we can't conclude whether the specified process is running
  */
 
 func checkProcessDarwin() (bool, error) {
-	return checkProcessLinuxLike("-f", "Contents/MacOS/Rancher Desktop"), nil
+	return checkProcesses(isAppDarwin)
 }
 
 func checkProcessLinux() (bool, error) {
-	return checkProcessLinuxLike("rancher-desktop"), nil
+	return checkProcesses(isAppLinux)
+}
+
+// isAppDarwin matches the processes of the application, including its helpers.
+func isAppDarwin(proc process.Process) bool {
+	return strings.Contains(proc.CommandLine, "Contents/MacOS/Rancher Desktop")
 }
 
-func checkProcessLinuxLike(commandPattern ...string) bool {
-	result, err := exec.Command("pgrep", commandPattern...).CombinedOutput()
+// isAppLinux matches the processes whose name contains "rancher-desktop",
+// as `pgrep rancher-desktop` used to.
+func isAppLinux(proc process.Process) bool {
+	return strings.Contains(filepath.Base(proc.Executable), "rancher-desktop")
+}
+
+func checkProcesses(match func(process.Process) bool) (bool, error) {
+	processes, err := process.Find(match)
 	if err != nil {
-		return false
+		return false, err
 	}
-	return regexp.MustCompile(`\A[0-9\s]+\z`).Match(result)
+	return len(processes) > 0, nil
 }
 
 // RancherDesktopQemuCommand - be specific to avoid killing other VM-based processes running qemu
 const RancherDesktopQemuCommand = "lima/bin/qemu-system.*rancher-desktop/lima/[0-9]/diffdisk"
 
+var rancherDesktopQemuCommandRegexp = regexp.MustCompile(RancherDesktopQemuCommand)
+
+func isQemu(proc process.Process) bool {
+	return rancherDesktopQemuCommandRegexp.MatchString(proc.CommandLine)
+}
+
 func checkProcessQemu() (bool, error) {
-	return checkProcessLinuxLike("-f", RancherDesktopQemuCommand), nil
+	return checkProcesses(isQemu)
+}
+
+var signalsByName = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
 }
 
-func pkill(args ...string) error {
-	pkillBinary := "pkill"
-	if runtime.GOOS == "darwin" {
-		pkillBinary = "/usr/bin/pkill"
+// signalProcesses sends the named signal to the matching processes; processes
+// that have already exited are ignored.
+func signalProcesses(signalName string, match func(process.Process) bool) error {
+	signal, ok := signalsByName[signalName]
+	if !ok {
+		return fmt.Errorf("internal error: unknown signal %q", signalName)
 	}
-	cmd := exec.Command(pkillBinary, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
+	processes, err := process.Find(match)
 	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			// don't throw an error if the process we are killing has already exited
-			if exitCode := exitError.ExitCode(); exitCode == 0 || exitCode == 1 {
-				return nil
-			}
+		return err
+	}
+	var errs []error
+	for _, proc := range processes {
+		logrus.Debugf("sending SIG%s to process %d (%s)", signalName, proc.PID, proc.Executable)
+		osProcess, err := os.FindProcess(proc.PID)
+		if err == nil {
+			err = osProcess.Signal(signal)
+		}
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
+			errs = append(errs, fmt.Errorf("failed to send SIG%s to process %d: %w", signalName, proc.PID, err))
 		}
-		return fmt.Errorf("error running pkill: %w", err)
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-func pkillQemu() error {
-	err := pkill("-9", "-f", RancherDesktopQemuCommand)
+func pkillQemu(signal string) error {
+	err := signalProcesses(signal, isQemu)
 	if err != nil {
 		return fmt.Errorf("failed to kill qemu: %w", err)
 	}
 	return nil
 }
 
-func checkLima() (bool, error) {
-	cmd := exec.Command(limaCtlPath, "ls", "--format", "{{.Status}}", "0")
+func (s *shutdownData) checkLima() (bool, error) {
+	cmd := execctx.Command(s.ctx, 0, limaCtlPath, "ls", "--format", "{{.Status}}", "0")
 	cmd.Stderr = os.Stderr
 	result, err := cmd.Output()
 	if err != nil {
@@ -189,35 +317,35 @@ func checkLima() (bool, error) {
 	return strings.HasPrefix(string(result), "Running"), nil
 }
 
-func runCommandIgnoreOutput(cmd *exec.Cmd) error {
+func runCommandIgnoreOutput(cmd *execctx.Cmd) error {
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func stopLima() error {
-	return runCommandIgnoreOutput(exec.Command(limaCtlPath, "stop", "0"))
+func (s *shutdownData) stopLima() error {
+	return runCommandIgnoreOutput(execctx.Command(s.ctx, limaStopTimeout, limaCtlPath, "stop", "0"))
 }
 
-func stopLimaWithForce() error {
-	return runCommandIgnoreOutput(exec.Command(limaCtlPath, "stop", "--force", "0"))
+func (s *shutdownData) stopLimaWithForce() error {
+	return runCommandIgnoreOutput(execctx.Command(s.ctx, 0, limaCtlPath, "stop", "--force", "0"))
 }
 
-func deleteLima() error {
-	return runCommandIgnoreOutput(exec.Command(limaCtlPath, "delete", "--force", "0"))
+func (s *shutdownData) deleteLima() error {
+	return runCommandIgnoreOutput(execctx.Command(s.ctx, 0, limaCtlPath, "delete", "--force", "0"))
 }
 
-func pkillDarwin() error {
-	err := pkill("-9", "-a", "-l", "-f", "Contents/MacOS/Rancher Desktop")
+func pkillDarwin(signal string) error {
+	err := signalProcesses(signal, isAppDarwin)
 	if err != nil {
 		return fmt.Errorf("failed to kill Rancher Desktop: %w", err)
 	}
 	return nil
 }
 
-func pkillLinux() error {
-	err := pkill("-9", "rancher-desktop")
+func pkillLinux(signal string) error {
+	err := signalProcesses(signal, isAppLinux)
 	if err != nil {
 		return fmt.Errorf("failed to kill Rancher Desktop: %w", err)
 	}
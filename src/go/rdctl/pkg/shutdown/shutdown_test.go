@@ -0,0 +1,96 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForAppToDieOrKillIt(t *testing.T) {
+	options := Options{WaitForShutdown: true, PollInterval: time.Millisecond, TermTimeout: 10 * time.Millisecond}
+
+	t.Run("does not kill processes that exit", func(t *testing.T) {
+		checks := 0
+		check := func() (bool, error) { checks++; return checks < 3, nil }
+		kill := func() error { t.Fatal("kill should not be called"); return nil }
+		assert.NoError(t, newShutdownData(options).waitForAppToDieOrKillIt(check, kill, time.Second, "test"))
+		assert.Equal(t, 3, checks)
+	})
+
+	t.Run("escalates from TERM to KILL", func(t *testing.T) {
+		s := newShutdownData(options)
+		var signals []string
+		check := func() (bool, error) { return true, nil }
+		signal := func(signal string) error { signals = append(signals, signal); return nil }
+		assert.NoError(t, s.waitForAppToDieOrKillIt(check, s.escalate(check, signal, "test"), 5*time.Millisecond, "test"))
+		assert.Equal(t, []string{"TERM", "KILL"}, signals)
+	})
+
+	t.Run("kills right away without a TERM timeout", func(t *testing.T) {
+		noTerm := options
+		noTerm.WaitForShutdown = false
+		noTerm.TermTimeout = 0
+		s := newShutdownData(noTerm)
+		var signals []string
+		check := func() (bool, error) { t.Fatal("check should not be called"); return true, nil }
+		signal := func(signal string) error { signals = append(signals, signal); return nil }
+		assert.NoError(t, s.waitForAppToDieOrKillIt(check, s.escalate(check, signal, "test"), time.Second, "test"))
+		assert.Equal(t, []string{"KILL"}, signals)
+	})
+}
+
+func TestLimaCommandsUseContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("lima is not used on Windows")
+	}
+	savedPath := limaCtlPath
+	t.Cleanup(func() { limaCtlPath = savedPath })
+	limaCtlPath = filepath.Join(t.TempDir(), "limactl")
+	require.NoError(t, os.WriteFile(limaCtlPath, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s := newShutdownData(DefaultOptions())
+	s.ctx = ctx
+	for name, run := range map[string]func() error{
+		"check":      func() error { _, err := s.checkLima(); return err },
+		"stop":       s.stopLima,
+		"force stop": s.stopLimaWithForce,
+		"delete":     s.deleteLima,
+	} {
+		start := time.Now()
+		assert.Error(t, run(), name)
+		assert.Less(t, time.Since(start), 10*time.Second, name)
+	}
+}
+
+func TestProcessMatchers(t *testing.T) {
+	qemu := process.Process{
+		Executable:  "/opt/rancher-desktop/resources/resources/linux/lima/bin/qemu-system-x86_64",
+		CommandLine: "/opt/rancher-desktop/resources/resources/linux/lima/bin/qemu-system-x86_64 -drive file=/home/me/.local/share/rancher-desktop/lima/0/diffdisk",
+	}
+	otherQemu := process.Process{Executable: "/usr/bin/qemu-system-x86_64", CommandLine: "/usr/bin/qemu-system-x86_64 -drive file=/tmp/disk"}
+	app := process.Process{Executable: "/opt/rancher-desktop/rancher-desktop", CommandLine: "/opt/rancher-desktop/rancher-desktop --type=renderer"}
+	macApp := process.Process{
+		Executable:  "/Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop",
+		CommandLine: "/Applications/Rancher Desktop.app/Contents/MacOS/Rancher Desktop",
+	}
+
+	assert.True(t, isQemu(qemu))
+	assert.False(t, isQemu(otherQemu))
+	assert.True(t, isAppLinux(app))
+	assert.False(t, isAppLinux(qemu))
+	assert.True(t, isAppDarwin(macApp))
+	assert.False(t, isAppDarwin(app))
+}
+
+func TestSignalProcessesRejectsUnknownSignals(t *testing.T) {
+	assert.ErrorContains(t, signalProcesses("HUP", func(process.Process) bool { return false }), `unknown signal "HUP"`)
+}
@@ -0,0 +1,240 @@
+// Package signature verifies detached signatures on downloaded artifacts
+// before they are used, such as the update installers given to `rdctl update
+// apply`.  Two formats are supported, chosen by the format of the public key:
+//
+//   - minisign (https://jedisct1.github.io/minisign/): the key is the contents
+//     of a minisign .pub file, and the signature is a .minisig file; both
+//     legacy and pre-hashed signatures are accepted, and the trusted comment
+//     is checked too.
+//   - cosign (`cosign sign-blob --key`): the key is a PEM-encoded public key,
+//     and the signature is the base64-encoded output of sign-blob.  ECDSA,
+//     RSA and Ed25519 keys are accepted.  Keyless signatures, which need the
+//     Fulcio and Rekor services, are not.
+package signature
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/checksum"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrVerificationFailed is returned when a signature does not match the
+// artifact, or was made with another key.
+var ErrVerificationFailed = errors.New("signature verification failed")
+
+const (
+	minisignLegacyAlgorithm    = "Ed"
+	minisignPrehashedAlgorithm = "ED"
+	untrustedCommentPrefix     = "untrusted comment:"
+	trustedCommentPrefix       = "trusted comment: "
+)
+
+// PublicKey is a key that artifacts can be verified against.
+type PublicKey interface {
+	// Verify checks that the signature was made by this key over the contents
+	// of the reader.
+	Verify(artifact io.Reader, signature []byte) error
+	// Format returns the name of the signature format, "minisign" or "cosign".
+	Format() string
+}
+
+// ParsePublicKey parses a minisign or cosign public key.
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block %q, expected a public key", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+			return &cosignKey{key: key}, nil
+		}
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return parseMinisignKey(data)
+}
+
+// ReadPublicKey parses the public key in the given file.
+func ReadPublicKey(path string) (PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	key, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// VerifyFile checks the signature in signaturePath against the file at
+// artifactPath.
+func VerifyFile(key PublicKey, artifactPath, signaturePath string) error {
+	artifact, err := os.Open(artifactPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", artifactPath, err)
+	}
+	defer artifact.Close()
+	return VerifyOpenFile(key, artifact, signaturePath)
+}
+
+// VerifyOpenFile checks the signature in signaturePath against the contents of
+// the open file, from its current offset.  Callers that go on to use the file
+// should use the same handle, so that what they use is what was verified.
+func VerifyOpenFile(key PublicKey, artifact *os.File, signaturePath string) error {
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	if err := key.Verify(bufio.NewReader(artifact), signature); err != nil {
+		return fmt.Errorf("%s: %w", artifact.Name(), err)
+	}
+	return nil
+}
+
+// DefaultSignaturePath returns where the signature for the given artifact is
+// expected when none is given explicitly, following the conventions of the
+// signing tool.
+func DefaultSignaturePath(key PublicKey, artifactPath string) string {
+	if key.Format() == "minisign" {
+		return artifactPath + ".minisig"
+	}
+	return artifactPath + ".sig"
+}
+
+type minisignKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+func parseMinisignKey(data []byte) (*minisignKey, error) {
+	var encoded string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, untrustedCommentPrefix) {
+			encoded = line
+			break
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != minisignLegacyAlgorithm {
+		return nil, errors.New("not a PEM-encoded or minisign public key")
+	}
+	result := &minisignKey{key: ed25519.PublicKey(raw[10:])}
+	copy(result.id[:], raw[2:10])
+	return result, nil
+}
+
+func (k *minisignKey) Format() string {
+	return "minisign"
+}
+
+func (k *minisignKey) Verify(artifact io.Reader, signature []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(signature), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], untrustedCommentPrefix) || !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return errors.New("malformed minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("malformed minisign trusted comment signature")
+	}
+	if !bytes.Equal(sig[2:10], k.id[:]) {
+		return fmt.Errorf("%w: signed with key %X, expected %X", ErrVerificationFailed,
+			binary.LittleEndian.Uint64(sig[2:10]), binary.LittleEndian.Uint64(k.id[:]))
+	}
+
+	var message []byte
+	switch string(sig[:2]) {
+	case minisignLegacyAlgorithm:
+		if message, err = io.ReadAll(artifact); err != nil {
+			return err
+		}
+	case minisignPrehashedAlgorithm:
+		hash, _ := blake2b.New512(nil)
+		if _, err := checksum.Copy(hash, artifact); err != nil {
+			return err
+		}
+		message = hash.Sum(nil)
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+	if !ed25519.Verify(k.key, message, sig[10:]) {
+		return ErrVerificationFailed
+	}
+	trustedComment := strings.TrimPrefix(lines[2], trustedCommentPrefix)
+	if !ed25519.Verify(k.key, append(bytes.Clone(sig[10:]), trustedComment...), globalSig) {
+		return fmt.Errorf("%w: the trusted comment has been modified", ErrVerificationFailed)
+	}
+	return nil
+}
+
+type cosignKey struct {
+	key crypto.PublicKey
+}
+
+func (k *cosignKey) Format() string {
+	return "cosign"
+}
+
+func (k *cosignKey) Verify(artifact io.Reader, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("malformed cosign signature: %w", err)
+	}
+	var valid bool
+	switch key := k.key.(type) {
+	case ed25519.PublicKey:
+		message, err := io.ReadAll(artifact)
+		if err != nil {
+			return err
+		}
+		valid = ed25519.Verify(key, message, sig)
+	case *ecdsa.PublicKey:
+		digest, err := sha256Digest(artifact)
+		if err != nil {
+			return err
+		}
+		valid = ecdsa.VerifyASN1(key, digest, sig)
+	case *rsa.PublicKey:
+		digest, err := sha256Digest(artifact)
+		if err != nil {
+			return err
+		}
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig) == nil
+	}
+	if !valid {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+func sha256Digest(r io.Reader) ([]byte, error) {
+	hash := sha256.New()
+	if _, err := checksum.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
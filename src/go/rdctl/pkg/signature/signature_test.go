@@ -0,0 +1,138 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+var artifact = []byte("Rancher Desktop installer contents")
+
+// minisignFiles returns the contents of a minisign public key and a signature
+// over the artifact, as written by the minisign tool.
+func minisignFiles(t *testing.T, algorithm string) (key, signature []byte) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	message := artifact
+	if algorithm == minisignPrehashedAlgorithm {
+		digest := blake2b.Sum512(artifact)
+		message = digest[:]
+	}
+	sig := ed25519.Sign(private, message)
+	trustedComment := "timestamp:1700000000\tfile:installer"
+	globalSig := ed25519.Sign(private, append(bytes.Clone(sig), trustedComment...))
+
+	encode := func(parts ...[]byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Join(parts, nil))
+	}
+	key = []byte(fmt.Sprintf("untrusted comment: minisign public key\n%s\n",
+		encode([]byte(minisignLegacyAlgorithm), keyID, public)))
+	signature = []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		encode([]byte(algorithm), keyID, sig), trustedComment, encode(globalSig)))
+	return key, signature
+}
+
+func TestMinisign(t *testing.T) {
+	for _, algorithm := range []string{minisignLegacyAlgorithm, minisignPrehashedAlgorithm} {
+		t.Run(algorithm, func(t *testing.T) {
+			keyData, signature := minisignFiles(t, algorithm)
+			key, err := ParsePublicKey(keyData)
+			require.NoError(t, err)
+			assert.Equal(t, "minisign", key.Format())
+
+			assert.NoError(t, key.Verify(bytes.NewReader(artifact), signature))
+			err = key.Verify(bytes.NewReader(append(bytes.Clone(artifact), '!')), signature)
+			assert.ErrorIs(t, err, ErrVerificationFailed)
+
+			tampered := bytes.Replace(signature, []byte("file:installer"), []byte("file:other"), 1)
+			assert.ErrorIs(t, key.Verify(bytes.NewReader(artifact), tampered), ErrVerificationFailed)
+
+			otherKey, _ := minisignFiles(t, algorithm)
+			key, err = ParsePublicKey(otherKey)
+			require.NoError(t, err)
+			assert.ErrorIs(t, key.Verify(bytes.NewReader(artifact), signature), ErrVerificationFailed)
+		})
+	}
+}
+
+func TestCosign(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	keyData := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	digest := sha256.Sum256(artifact)
+	sig, err := ecdsa.SignASN1(rand.Reader, private, digest[:])
+	require.NoError(t, err)
+	signature := []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+
+	key, err := ParsePublicKey(keyData)
+	require.NoError(t, err)
+	assert.Equal(t, "cosign", key.Format())
+	assert.NoError(t, key.Verify(bytes.NewReader(artifact), signature))
+	err = key.Verify(bytes.NewReader(append(bytes.Clone(artifact), '!')), signature)
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+}
+
+func TestParsePublicKeyErrors(t *testing.T) {
+	_, err := ParsePublicKey([]byte("not a key"))
+	assert.Error(t, err)
+	_, err = ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0}}))
+	assert.ErrorContains(t, err, "expected a public key")
+}
+
+func TestVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyData, signature := minisignFiles(t, minisignPrehashedAlgorithm)
+	key, err := ParsePublicKey(keyData)
+	require.NoError(t, err)
+	artifactPath := filepath.Join(dir, "installer.msi")
+	require.NoError(t, os.WriteFile(artifactPath, artifact, 0o644))
+	signaturePath := DefaultSignaturePath(key, artifactPath)
+	assert.Equal(t, artifactPath+".minisig", signaturePath)
+
+	assert.ErrorIs(t, VerifyFile(key, artifactPath, signaturePath), os.ErrNotExist)
+	require.NoError(t, os.WriteFile(signaturePath, signature, 0o644))
+	assert.NoError(t, VerifyFile(key, artifactPath, signaturePath))
+}
+
+func TestVerifyOpenFile(t *testing.T) {
+	dir := t.TempDir()
+	keyData, signature := minisignFiles(t, minisignPrehashedAlgorithm)
+	key, err := ParsePublicKey(keyData)
+	require.NoError(t, err)
+	artifactPath := filepath.Join(dir, "installer.msi")
+	require.NoError(t, os.WriteFile(artifactPath, artifact, 0o644))
+	signaturePath := DefaultSignaturePath(key, artifactPath)
+	require.NoError(t, os.WriteFile(signaturePath, signature, 0o644))
+
+	file, err := os.Open(artifactPath)
+	require.NoError(t, err)
+	defer file.Close()
+	// Replacing the file after it was opened doesn't change what is verified.
+	replacementPath := filepath.Join(dir, "replacement.msi")
+	require.NoError(t, os.WriteFile(replacementPath, []byte("tampered"), 0o644))
+	require.NoError(t, os.Rename(replacementPath, artifactPath))
+	assert.NoError(t, VerifyOpenFile(key, file, signaturePath))
+
+	tampered, err := os.Open(artifactPath)
+	require.NoError(t, err)
+	defer tampered.Close()
+	assert.ErrorIs(t, VerifyOpenFile(key, tampered, signaturePath), ErrVerificationFailed)
+}
@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -113,8 +114,9 @@ func (manager *Manager) writeMetadataFile(snapshot Snapshot) error {
 	return nil
 }
 
-// Create a new snapshot.
-func (manager *Manager) Create(name, description string) (snapshot Snapshot, err error) {
+// Create a new snapshot.  If the context is cancelled, the partially created
+// snapshot is removed.
+func (manager *Manager) Create(ctx context.Context, name, description string) (snapshot Snapshot, err error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return snapshot, fmt.Errorf("failed to generate ID for snapshot: %w", err)
@@ -125,7 +127,7 @@ func (manager *Manager) Create(name, description string) (snapshot Snapshot, err
 		ID:          id.String(),
 		Description: description,
 	}
-	if err = manager.Lock(manager.Paths, "create"); err != nil {
+	if err = manager.Lock(ctx, manager.Paths, "create"); err != nil {
 		return
 	}
 	defer func() {
@@ -142,7 +144,7 @@ func (manager *Manager) Create(name, description string) (snapshot Snapshot, err
 		return
 	}
 	if err = manager.writeMetadataFile(snapshot); err == nil {
-		err = manager.CreateFiles(manager.Paths, manager.SnapshotDirectory(snapshot))
+		err = manager.CreateFiles(ctx, manager.Paths, manager.SnapshotDirectory(snapshot))
 	}
 	return
 }
@@ -160,26 +162,33 @@ func (manager *Manager) List(includeIncomplete bool) ([]Snapshot, error) {
 		if _, err := uuid.Parse(dirEntry.Name()); err != nil {
 			continue
 		}
+		// Check for completeness first: the metadata of a snapshot that is
+		// being created (by another process) may not be fully written yet.
+		completeFilePath := filepath.Join(manager.Paths.Snapshots, dirEntry.Name(), completeFileName)
+		_, err = os.Stat(completeFilePath)
+		completeFileExists := err == nil
+
+		if !includeIncomplete && !completeFileExists {
+			continue
+		}
+
 		snapshot := Snapshot{}
 		metadataPath := filepath.Join(manager.Paths.Snapshots, dirEntry.Name(), "metadata.json")
 		contents, err := os.ReadFile(metadataPath)
+		if err == nil {
+			err = json.Unmarshal(contents, &snapshot)
+		}
 		if err != nil {
+			// A snapshot that is being deleted loses its complete file first,
+			// and may disappear entirely after we checked for it.
+			if !completeFileExists || errors.Is(err, os.ErrNotExist) {
+				continue
+			}
 			return []Snapshot{}, fmt.Errorf("failed to read %q: %w", metadataPath, err)
 		}
-		if err := json.Unmarshal(contents, &snapshot); err != nil {
-			return []Snapshot{}, fmt.Errorf("failed to unmarshal contents of %q: %w", metadataPath, err)
-		}
 		// TODO this should be done by the caller
 		snapshot.Created = snapshot.Created.Local()
 
-		completeFilePath := filepath.Join(manager.Paths.Snapshots, snapshot.ID, completeFileName)
-		_, err = os.Stat(completeFilePath)
-		completeFileExists := err == nil
-
-		if !includeIncomplete && !completeFileExists {
-			continue
-		}
-
 		snapshots = append(snapshots, snapshot)
 	}
 	return snapshots, nil
@@ -198,14 +207,16 @@ func (manager *Manager) Delete(name string) error {
 	return errors.Join(err, os.RemoveAll(snapshotDir))
 }
 
-// Restore Rancher Desktop to the state saved in a snapshot.
-func (manager *Manager) Restore(name string) (err error) {
+// Restore Rancher Desktop to the state saved in a snapshot.  The context only
+// applies to stopping the backend; once files are being restored, the restore
+// runs to completion.
+func (manager *Manager) Restore(ctx context.Context, name string) (err error) {
 	snapshot, err := manager.Snapshot(name)
 	if err != nil {
 		return err
 	}
 
-	if err := manager.Lock(manager.Paths, "restore"); err != nil {
+	if err := manager.Lock(ctx, manager.Paths, "restore"); err != nil {
 		return err
 	}
 	defer func() {
@@ -215,7 +226,7 @@ func (manager *Manager) Restore(name string) (err error) {
 			err = unlockErr
 		}
 	}()
-	if err = manager.RestoreFiles(manager.Paths, manager.SnapshotDirectory(snapshot)); err != nil {
+	if err = manager.RestoreFiles(ctx, manager.Paths, manager.SnapshotDirectory(snapshot)); err != nil {
 		return fmt.Errorf("failed to restore files: %w", err)
 	}
 
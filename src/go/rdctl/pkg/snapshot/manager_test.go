@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -22,7 +23,7 @@ func TestManager(t *testing.T) {
 		if err := manager.ValidateName(snapshotName); err != nil {
 			t.Fatalf("failed to validate first snapshot: %s", err)
 		}
-		snapshot, err := manager.Create(snapshotName, "")
+		snapshot, err := manager.Create(context.Background(), snapshotName, "")
 		if err != nil {
 			t.Fatalf("failed to create first snapshot: %s", err)
 		}
@@ -114,7 +115,7 @@ func TestManager(t *testing.T) {
 			var lastSnapshot Snapshot
 			for i := range []int{1, 2, 3} {
 				snapshotName := fmt.Sprintf("test-snapshot-%d", i)
-				snapshot, err := manager.Create(snapshotName, "")
+				snapshot, err := manager.Create(context.Background(), snapshotName, "")
 				if err != nil {
 					t.Fatalf("failed to create snapshot %q: %s", snapshotName, err)
 				}
@@ -144,7 +145,7 @@ func TestManager(t *testing.T) {
 	t.Run("Delete", func(t *testing.T) {
 		paths, _ := populateFiles(t, true)
 		manager := newTestManager(paths)
-		snapshot, err := manager.Create("test-snapshot", "")
+		snapshot, err := manager.Create(context.Background(), "test-snapshot", "")
 		if err != nil {
 			t.Fatalf("failed to create snapshot: %s", err)
 		}
@@ -170,7 +171,7 @@ func TestManager(t *testing.T) {
 	t.Run("Restore should return an error if asked to restore a nonexistent snapshot", func(t *testing.T) {
 		paths, _ := populateFiles(t, true)
 		manager := newTestManager(paths)
-		if err := manager.Restore("no-such-snapshot-id"); err == nil {
+		if err := manager.Restore(context.Background(), "no-such-snapshot-id"); err == nil {
 			t.Errorf("Failed to complain when asked to restore a nonexistent snapshot")
 		}
 	})
@@ -178,7 +179,7 @@ func TestManager(t *testing.T) {
 	t.Run("Restore should return the proper error if asked to restore from an incomplete snapshot", func(t *testing.T) {
 		paths, _ := populateFiles(t, true)
 		manager := newTestManager(paths)
-		snapshot, err := manager.Create("test-snapshot", "")
+		snapshot, err := manager.Create(context.Background(), "test-snapshot", "")
 		if err != nil {
 			t.Fatalf("failed to create snapshot: %s", err)
 		}
@@ -186,7 +187,7 @@ func TestManager(t *testing.T) {
 		if err := os.Remove(completeFilePath); err != nil {
 			t.Fatalf("failed to remove %q: %s", completeFileName, err)
 		}
-		if err := manager.Restore(snapshot.Name); err == nil {
+		if err := manager.Restore(context.Background(), snapshot.Name); err == nil {
 			t.Errorf("Failed to complain when asked to restore an incomplete snapshot")
 		}
 	})
@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"context"
 	"errors"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/lock"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
@@ -52,7 +53,7 @@ func TestManagerWindows(t *testing.T) {
 
 		// create snapshot
 		testManager := newTestManager(appPaths)
-		snapshot, err := testManager.Create("test-snapshot", "")
+		snapshot, err := testManager.Create(context.Background(), "test-snapshot", "")
 		if err != nil {
 			t.Fatalf("unexpected error creating snapshot: %s", err)
 		}
@@ -72,7 +73,7 @@ func TestManagerWindows(t *testing.T) {
 	t.Run("Restore should work properly", func(t *testing.T) {
 		appPaths, testFiles := populateFiles(t, false)
 		manager := newTestManager(appPaths)
-		snapshot, err := manager.Create("test-snapshot", "")
+		snapshot, err := manager.Create(context.Background(), "test-snapshot", "")
 		if err != nil {
 			t.Fatalf("failed to create snapshot: %s", err)
 		}
@@ -81,7 +82,7 @@ func TestManagerWindows(t *testing.T) {
 				t.Fatalf("failed to modify %s: %s", testFileName, err)
 			}
 		}
-		if err := manager.Restore(snapshot.ID); err != nil {
+		if err := manager.Restore(context.Background(), snapshot.ID); err != nil {
 			t.Fatalf("failed to restore snapshot: %s", err)
 		}
 		for testFileName, testFile := range testFiles {
@@ -98,7 +99,7 @@ func TestManagerWindows(t *testing.T) {
 	t.Run("Restore should create any needed parent directories", func(t *testing.T) {
 		appPaths, _ := populateFiles(t, true)
 		manager := newTestManager(appPaths)
-		snapshot, err := manager.Create("test-snapshot", "")
+		snapshot, err := manager.Create(context.Background(), "test-snapshot", "")
 		if err != nil {
 			t.Fatalf("failed to create snapshot: %s", err)
 		}
@@ -112,7 +113,7 @@ func TestManagerWindows(t *testing.T) {
 				t.Fatalf("failed to remove test directory %q: %s", testDir, err)
 			}
 		}
-		if err := manager.Restore(snapshot.ID); err != nil {
+		if err := manager.Restore(context.Background(), snapshot.ID); err != nil {
 			t.Fatalf("failed to restore snapshot: %s", err)
 		}
 		for _, testDir := range testDirs {
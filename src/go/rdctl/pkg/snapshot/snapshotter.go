@@ -1,6 +1,12 @@
 package snapshot
 
-import "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+import (
+	"context"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
 
 // Types that implement Snapshotter are responsible for copying/creating
 // files that need to be copied/created for the creation and restoration of
@@ -8,10 +14,19 @@ import "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 type Snapshotter interface {
 	// Does all of the things that can fail when creating a snapshot,
 	// so that the snapshot creation can easily be rolled back upon
-	// a failure.
-	CreateFiles(appPaths paths.Paths, snapshotDir string) error
+	// a failure.  Cancelling the context stops the creation between files.
+	CreateFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error
 	// Like CreateFiles, but for restoring: does all of the things
 	// that can fail when restoring a snapshot so that restoration can
-	// easily be rolled back in the event of a failure.
-	RestoreFiles(appPaths paths.Paths, snapshotDir string) error
+	// easily be rolled back in the event of a failure.  The context is
+	// only used for tracing; a restore is never stopped part way.
+	RestoreFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error
+}
+
+// traced runs one step of creating or restoring a snapshot in a trace span.
+func traced(ctx context.Context, name string, step func() error, attributes ...attribute.KeyValue) error {
+	_, span := tracing.Start(ctx, name, attributes...)
+	err := step()
+	tracing.End(span, err)
+	return err
 }
@@ -3,11 +3,15 @@
 package snapshot
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"os"
 	"path/filepath"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Represents a file that is included in a snapshot.
@@ -84,14 +88,32 @@ func (snapshotter SnapshotterImpl) Files(appPaths paths.Paths, snapshotDir strin
 type SnapshotterImpl struct {
 }
 
+// fileAttributes describes a file being copied, for tracing.
+func fileAttributes(file snapshotFile) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("file.name", filepath.Base(file.WorkingPath)),
+		attribute.Bool("file.copy_on_write", file.CopyOnWrite),
+	}
+}
+
+// copyFile copies one of the files in a snapshot.
+func copyFile(dst, src string, file snapshotFile) error {
+	return utils.CopyFile(dst, src, utils.CopyOptions{Mode: file.FileMode, Clone: file.CopyOnWrite})
+}
+
 func NewSnapshotterImpl() Snapshotter {
 	return SnapshotterImpl{}
 }
 
-func (snapshotter SnapshotterImpl) CreateFiles(appPaths paths.Paths, snapshotDir string) error {
+func (snapshotter SnapshotterImpl) CreateFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error {
 	files := snapshotter.Files(appPaths, snapshotDir)
 	for _, file := range files {
-		err := copyFile(file.SnapshotPath, file.WorkingPath, file.CopyOnWrite, file.FileMode)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := traced(ctx, "snapshot.copyFile", func() error {
+			return copyFile(file.SnapshotPath, file.WorkingPath, file)
+		}, fileAttributes(file)...)
 		if errors.Is(err, os.ErrNotExist) && file.MissingOk {
 			continue
 		} else if err != nil {
@@ -111,12 +133,14 @@ func (snapshotter SnapshotterImpl) CreateFiles(appPaths paths.Paths, snapshotDir
 
 // Restores the files from their location in a snapshot directory
 // to their working location.
-func (snapshotter SnapshotterImpl) RestoreFiles(appPaths paths.Paths, snapshotDir string) error {
+func (snapshotter SnapshotterImpl) RestoreFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error {
 	files := snapshotter.Files(appPaths, snapshotDir)
 	var err error
 	for _, file := range files {
 		filename := filepath.Base(file.WorkingPath)
-		err = copyFile(file.WorkingPath, file.SnapshotPath, file.CopyOnWrite, file.FileMode)
+		err = traced(ctx, "snapshot.restoreFile", func() error {
+			return copyFile(file.WorkingPath, file.SnapshotPath, file)
+		}, fileAttributes(file)...)
 		if errors.Is(err, os.ErrNotExist) && file.MissingOk {
 			if err = os.RemoveAll(file.WorkingPath); err != nil {
 				err = fmt.Errorf("failed to remove %s: %w", filename, err)
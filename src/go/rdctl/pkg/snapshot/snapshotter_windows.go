@@ -1,12 +1,15 @@
 package snapshot
 
 import (
+	"context"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/utils"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/wsl"
-	"io"
 	"os"
 	"path/filepath"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type wslDistro struct {
@@ -20,38 +23,20 @@ type wslDistro struct {
 func (snapshotter SnapshotterImpl) WSLDistros(appPaths paths.Paths) []wslDistro {
 	return []wslDistro{
 		{
-			Name:           "rancher-desktop",
+			Name:           paths.MainDistro(),
 			WorkingDirPath: appPaths.WslDistro,
 		},
 		{
-			Name:           "rancher-desktop-data",
+			Name:           paths.DataDistro(),
 			WorkingDirPath: appPaths.WslDistroData,
 		},
 	}
 }
 
-// Note: on Windows, there are system calls such as CopyFile and CopyFileEx
-// that may speed up the process of copying a file, but they appear to require
-// loading DLL's. This approach works fine for copying smaller files, but if
-// we need to copy big files it may be worth the complexity to use the syscall.
+// copyFile copies the settings file into or out of a snapshot; the distros
+// are exported and imported by WSL instead.
 func copyFile(dst, src string) error {
-	srcFd, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer srcFd.Close()
-	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
-		return fmt.Errorf("failed to create destination parent dir: %w", err)
-	}
-	dstFd, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("failed to open destination file: %w", err)
-	}
-	defer dstFd.Close()
-	if _, err := io.Copy(dstFd, srcFd); err != nil {
-		return fmt.Errorf("failed to copy contents of src to dst: %w", err)
-	}
-	return nil
+	return utils.CopyFile(dst, src, utils.CopyOptions{Mode: 0o644})
 }
 
 // SnapshotterImpl also works as a *Manager receiver
@@ -65,11 +50,17 @@ func NewSnapshotterImpl() SnapshotterImpl {
 	}
 }
 
-func (snapshotter SnapshotterImpl) CreateFiles(appPaths paths.Paths, snapshotDir string) error {
+func (snapshotter SnapshotterImpl) CreateFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error {
 	// export WSL distros to snapshot directory
 	for _, distro := range snapshotter.WSLDistros(appPaths) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		snapshotDistroPath := filepath.Join(snapshotDir, distro.Name+".tar")
-		if err := snapshotter.ExportDistro(distro.Name, snapshotDistroPath); err != nil {
+		err := traced(ctx, "snapshot.exportDistro", func() error {
+			return snapshotter.ExportDistro(distro.Name, snapshotDistroPath)
+		}, attribute.String("wsl.distro", distro.Name))
+		if err != nil {
 			return fmt.Errorf("failed to export WSL distro %q: %w", distro.Name, err)
 		}
 	}
@@ -77,7 +68,10 @@ func (snapshotter SnapshotterImpl) CreateFiles(appPaths paths.Paths, snapshotDir
 	// copy settings.json to snapshot directory
 	workingSettingsPath := filepath.Join(appPaths.Config, "settings.json")
 	snapshotSettingsPath := filepath.Join(snapshotDir, "settings.json")
-	if err := copyFile(snapshotSettingsPath, workingSettingsPath); err != nil {
+	err := traced(ctx, "snapshot.copyFile", func() error {
+		return copyFile(snapshotSettingsPath, workingSettingsPath)
+	}, attribute.String("file.name", "settings.json"))
+	if err != nil {
 		return fmt.Errorf("failed to copy %q to snapshot directory: %w", workingSettingsPath, err)
 	}
 
@@ -91,7 +85,7 @@ func (snapshotter SnapshotterImpl) CreateFiles(appPaths paths.Paths, snapshotDir
 	return nil
 }
 
-func (snapshotter SnapshotterImpl) RestoreFiles(appPaths paths.Paths, snapshotDir string) error {
+func (snapshotter SnapshotterImpl) RestoreFiles(ctx context.Context, appPaths paths.Paths, snapshotDir string) error {
 	// restore WSL distros
 	var err error
 	if err = snapshotter.UnregisterDistros(); err != nil {
@@ -103,7 +97,10 @@ func (snapshotter SnapshotterImpl) RestoreFiles(appPaths paths.Paths, snapshotDi
 			err = fmt.Errorf("failed to create install directory for distro %q: %w", distro.Name, err)
 			break
 		}
-		if err = snapshotter.ImportDistro(distro.Name, distro.WorkingDirPath, snapshotDistroPath); err != nil {
+		err = traced(ctx, "snapshot.importDistro", func() error {
+			return snapshotter.ImportDistro(distro.Name, distro.WorkingDirPath, snapshotDistroPath)
+		}, attribute.String("wsl.distro", distro.Name))
+		if err != nil {
 			err = fmt.Errorf("failed to import WSL distro %q: %w", distro.Name, err)
 			break
 		}
@@ -113,7 +110,10 @@ func (snapshotter SnapshotterImpl) RestoreFiles(appPaths paths.Paths, snapshotDi
 	workingSettingsPath := filepath.Join(appPaths.Config, "settings.json")
 	snapshotSettingsPath := filepath.Join(snapshotDir, "settings.json")
 	if err == nil {
-		if err = copyFile(workingSettingsPath, snapshotSettingsPath); err != nil {
+		err = traced(ctx, "snapshot.restoreFile", func() error {
+			return copyFile(workingSettingsPath, snapshotSettingsPath)
+		}, attribute.String("file.name", "settings.json"))
+		if err != nil {
 			err = fmt.Errorf("failed to restore %q: %w", workingSettingsPath, err)
 		}
 	}
@@ -0,0 +1,217 @@
+// Package telemetry reports which rdctl commands are used, and how they fail,
+// to an endpoint chosen by the user.  Nothing is sent unless the user has
+// explicitly enabled it with `rdctl telemetry enable`.  Reports are anonymous:
+// they carry a random ID, the command and the names of the flags given, but
+// never arguments, flag values, paths, or error messages.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+const (
+	// EndpointEnvVar overrides the configured endpoint, e.g. so that a
+	// platform team can point all the machines they manage to their collector.
+	EndpointEnvVar = "RD_TELEMETRY_ENDPOINT"
+	// DoNotTrackEnvVar disables telemetry, even if it has been enabled,
+	// following the https://consoledonottrack.com convention.
+	DoNotTrackEnvVar = "DO_NOT_TRACK"
+)
+
+const configFileName = "telemetry.json"
+
+// sendTimeout bounds how long reporting can delay rdctl exiting.
+const sendTimeout = 2 * time.Second
+
+// Failure classes.
+const (
+	ResultSuccess    = "success"
+	ResultCanceled   = "canceled"
+	ResultTimeout    = "timeout"
+	ResultUsage      = "usage"
+	ResultConnection = "connection"
+	ResultVersion    = "version"
+	ResultBackend    = "backend"
+	ResultError      = "error"
+)
+
+// Config is the telemetry choice of the user.
+type Config struct {
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint,omitempty"`
+	// ID links the reports from one installation, without identifying the
+	// user; it is random, and replaced each time telemetry is enabled.
+	ID string `json:"id,omitempty"`
+}
+
+// Event is the report for one command.
+type Event struct {
+	ID         string   `json:"id"`
+	Version    string   `json:"version"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	Command    string   `json:"command"`
+	Flags      []string `json:"flags,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+	Result     string   `json:"result"`
+}
+
+type usageError struct {
+	error
+}
+
+func (e usageError) Unwrap() error {
+	return e.error
+}
+
+// NewUsageError marks an error as caused by invalid command-line usage.
+func NewUsageError(err error) error {
+	return usageError{err}
+}
+
+func configPath(appPaths paths.Paths) string {
+	return filepath.Join(appPaths.Config, configFileName)
+}
+
+// Load reads the configuration; telemetry is disabled if it has never been
+// configured.
+func Load(appPaths paths.Paths) (Config, error) {
+	var config Config
+	contents, err := os.ReadFile(configPath(appPaths))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, fmt.Errorf("failed to read telemetry configuration: %w", err)
+	}
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return config, fmt.Errorf("failed to parse telemetry configuration %s: %w", configPath(appPaths), err)
+	}
+	return config, nil
+}
+
+func save(appPaths paths.Paths, config Config) error {
+	contents, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(appPaths.Config, 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(configPath(appPaths), contents, 0o644); err != nil {
+		return fmt.Errorf("failed to write telemetry configuration: %w", err)
+	}
+	return nil
+}
+
+// Enable turns telemetry on, sending to the given endpoint; if it is empty, the
+// previously configured endpoint is kept.  A new ID is generated, so that
+// reports can't be linked to those from before telemetry was last disabled.
+func Enable(appPaths paths.Paths, endpoint string) (Config, error) {
+	config, err := Load(appPaths)
+	if err != nil {
+		return config, err
+	}
+	if endpoint != "" {
+		config.Endpoint = endpoint
+	}
+	if config.Endpoint == "" && os.Getenv(EndpointEnvVar) == "" {
+		return config, fmt.Errorf("no telemetry endpoint is configured; specify one with --endpoint or $%s", EndpointEnvVar)
+	}
+	config.Enabled = true
+	config.ID = uuid.NewString()
+	return config, save(appPaths, config)
+}
+
+// Disable turns telemetry off, forgetting the ID.
+func Disable(appPaths paths.Paths) error {
+	config, err := Load(appPaths)
+	if err != nil {
+		return err
+	}
+	config.Enabled = false
+	config.ID = ""
+	return save(appPaths, config)
+}
+
+// DoNotTrack returns whether the environment forbids telemetry.
+func DoNotTrack() bool {
+	value := os.Getenv(DoNotTrackEnvVar)
+	return value != "" && value != "0" && value != "false"
+}
+
+// EffectiveEndpoint returns where reports are sent.
+func (c Config) EffectiveEndpoint() string {
+	if endpoint := os.Getenv(EndpointEnvVar); endpoint != "" {
+		return endpoint
+	}
+	return c.Endpoint
+}
+
+// Active returns whether reports are sent.
+func (c Config) Active() bool {
+	return c.Enabled && c.EffectiveEndpoint() != "" && !DoNotTrack()
+}
+
+// Classify maps the error returned by a command to its failure class.
+func Classify(err error) string {
+	var usage usageError
+	switch {
+	case err == nil:
+		return ResultSuccess
+	case errors.Is(err, context.Canceled):
+		return ResultCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ResultTimeout
+	case errors.As(err, &usage):
+		return ResultUsage
+	case errors.Is(err, client.ErrConnectionRefused):
+		return ResultConnection
+	case errors.Is(err, client.ErrApiVersionMismatch):
+		return ResultVersion
+	case errors.Is(err, client.ErrBackendStarting):
+		return ResultBackend
+	}
+	return ResultError
+}
+
+// Report sends the event, if telemetry is active; the ID is filled in from the
+// configuration.
+func Report(ctx context.Context, appPaths paths.Paths, event Event) error {
+	config, err := Load(appPaths)
+	if err != nil || !config.Active() {
+		return err
+	}
+	event.ID = config.ID
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, config.EffectiveEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", response.Status)
+	}
+	return nil
+}
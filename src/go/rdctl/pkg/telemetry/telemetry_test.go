@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableDisable(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "")
+	appPaths := paths.Paths{Config: t.TempDir()}
+
+	config, err := Load(appPaths)
+	require.NoError(t, err)
+	assert.False(t, config.Enabled, "telemetry must be off by default")
+
+	_, err = Enable(appPaths, "")
+	assert.ErrorContains(t, err, "no telemetry endpoint")
+
+	config, err = Enable(appPaths, "http://localhost:1234/events")
+	require.NoError(t, err)
+	assert.True(t, config.Enabled)
+	assert.NotEmpty(t, config.ID)
+	firstID := config.ID
+
+	require.NoError(t, Disable(appPaths))
+	config, err = Load(appPaths)
+	require.NoError(t, err)
+	assert.False(t, config.Enabled)
+	assert.Empty(t, config.ID)
+	assert.Equal(t, "http://localhost:1234/events", config.Endpoint)
+
+	config, err = Enable(appPaths, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, firstID, config.ID, "enabling telemetry again must use a new ID")
+}
+
+func TestClassify(t *testing.T) {
+	testCases := map[string]error{
+		ResultSuccess:    nil,
+		ResultCanceled:   fmt.Errorf("wrapped: %w", context.Canceled),
+		ResultTimeout:    context.DeadlineExceeded,
+		ResultUsage:      NewUsageError(errors.New("unknown flag: --foo")),
+		ResultConnection: fmt.Errorf("wrapped: %w", client.ErrConnectionRefused),
+		ResultVersion:    client.ErrApiVersionMismatch,
+		ResultBackend:    client.ErrBackendStarting,
+		ResultError:      errors.New("something else"),
+	}
+	for expected, err := range testCases {
+		assert.Equal(t, expected, Classify(err), "%v", err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if assert.NoError(t, json.NewDecoder(r.Body).Decode(&event)) {
+			received = append(received, event)
+		}
+	}))
+	defer server.Close()
+	t.Setenv(EndpointEnvVar, "")
+	t.Setenv(DoNotTrackEnvVar, "")
+	appPaths := paths.Paths{Config: t.TempDir()}
+	event := Event{Command: "rdctl snapshot list", Result: ResultSuccess}
+
+	require.NoError(t, Report(context.Background(), appPaths, event))
+	assert.Empty(t, received, "nothing must be sent before telemetry is enabled")
+
+	config, err := Enable(appPaths, server.URL)
+	require.NoError(t, err)
+	require.NoError(t, Report(context.Background(), appPaths, event))
+	require.Len(t, received, 1)
+	assert.Equal(t, config.ID, received[0].ID)
+	assert.Equal(t, event.Command, received[0].Command)
+
+	t.Setenv(DoNotTrackEnvVar, "1")
+	require.NoError(t, Report(context.Background(), appPaths, event))
+	assert.Len(t, received, 1, "nothing must be sent with DO_NOT_TRACK set")
+
+	t.Setenv(DoNotTrackEnvVar, "")
+	require.NoError(t, Disable(appPaths))
+	require.NoError(t, Report(context.Background(), appPaths, event))
+	assert.Len(t, received, 1, "nothing must be sent once telemetry is disabled")
+}
@@ -0,0 +1,89 @@
+// Package terminal adapts rdctl to whether someone is at the keyboard.  In CI,
+// or when rdctl isn't attached to a terminal (as when the application runs
+// it), nobody can answer a question, so confirmations must be given with
+// --yes instead, and commands fail straight away rather than wait for input
+// that will never come.
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/i18n"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// ErrConfirmationRequired is returned by Confirm when the user can't be asked.
+var ErrConfirmationRequired = errors.New("confirmation required")
+
+// ErrNotConfirmed is returned by Confirm when the user declines.
+var ErrNotConfirmed = errors.New("cancelled")
+
+// ciEnvVars are set by CI services; CI is the de facto standard, and the others
+// cover services that don't set it.
+var ciEnvVars = []string{"CI", "BUILD_NUMBER", "BUILDKITE", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL", "TEAMCITY_VERSION", "TF_BUILD"}
+
+// These are variables for testing.
+var (
+	stdin      io.Reader = os.Stdin
+	stderr     io.Writer = os.Stderr
+	isTerminal           = func() bool {
+		return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+	}
+)
+
+// IsCI reports whether rdctl runs in a CI service.
+func IsCI() bool {
+	for _, name := range ciEnvVars {
+		if value := os.Getenv(name); value != "" && value != "0" && !strings.EqualFold(value, "false") {
+			return true
+		}
+	}
+	return false
+}
+
+// Interactive reports whether the user can be asked questions: standard input
+// and standard error are a terminal, and this isn't CI (which may provide a
+// terminal nobody is looking at).
+func Interactive() bool {
+	return isTerminal() && !IsCI()
+}
+
+// DisableColors turns off colors in log messages in CI, or if $NO_COLOR is
+// set (see https://no-color.org); logrus already leaves them out when not
+// writing to a terminal.
+func DisableColors() {
+	if !IsCI() && os.Getenv("NO_COLOR") == "" {
+		return
+	}
+	if formatter, ok := logrus.StandardLogger().Formatter.(*logrus.TextFormatter); ok {
+		formatter.DisableColors = true
+	}
+}
+
+// Confirm asks the user the question, unless assumeYes is set (by --yes).
+// If the user can't be asked, it fails with ErrConfirmationRequired instead of
+// waiting for an answer; if the user doesn't answer yes, with ErrNotConfirmed.
+func Confirm(question string, assumeYes bool) error {
+	if assumeYes {
+		return nil
+	}
+	if !Interactive() {
+		return fmt.Errorf("%w: %s", ErrConfirmationRequired, i18n.T("terminal.confirmationRequired"))
+	}
+	fmt.Fprintf(stderr, "%s %s ", question, i18n.T("terminal.yesNo"))
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", strings.ToLower(i18n.T("terminal.yes")):
+		return nil
+	}
+	return ErrNotConfirmed
+}
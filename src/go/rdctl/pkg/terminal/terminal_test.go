@@ -0,0 +1,68 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTerminal makes Confirm read the given input from a terminal, returning
+// what it writes.
+func fakeTerminal(t *testing.T, input string) *bytes.Buffer {
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+	var output bytes.Buffer
+	originalStdin, originalStderr, originalIsTerminal := stdin, stderr, isTerminal
+	t.Cleanup(func() {
+		stdin, stderr, isTerminal = originalStdin, originalStderr, originalIsTerminal
+	})
+	stdin, stderr = strings.NewReader(input), &output
+	isTerminal = func() bool { return true }
+	return &output
+}
+
+func TestConfirm(t *testing.T) {
+	t.Run("answers", func(t *testing.T) {
+		for input, confirmed := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+			output := fakeTerminal(t, input)
+			err := Confirm("Delete it?", false)
+			if confirmed {
+				assert.NoError(t, err, "%q", input)
+			} else {
+				assert.ErrorIs(t, err, ErrNotConfirmed, "%q", input)
+			}
+			assert.Equal(t, "Delete it? [y/N] ", output.String())
+		}
+	})
+	t.Run("assume yes", func(t *testing.T) {
+		output := fakeTerminal(t, "n\n")
+		assert.NoError(t, Confirm("Delete it?", true))
+		assert.Empty(t, output.String())
+	})
+	t.Run("not a terminal", func(t *testing.T) {
+		output := fakeTerminal(t, "y\n")
+		isTerminal = func() bool { return false }
+		assert.ErrorIs(t, Confirm("Delete it?", false), ErrConfirmationRequired)
+		assert.Empty(t, output.String())
+	})
+	t.Run("CI", func(t *testing.T) {
+		output := fakeTerminal(t, "y\n")
+		t.Setenv("GITHUB_ACTIONS", "true")
+		assert.ErrorIs(t, Confirm("Delete it?", false), ErrConfirmationRequired)
+		assert.Empty(t, output.String())
+	})
+}
+
+func TestIsCI(t *testing.T) {
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+	assert.False(t, IsCI())
+	t.Setenv("CI", "false")
+	assert.False(t, IsCI())
+	t.Setenv("CI", "1")
+	assert.True(t, IsCI())
+}
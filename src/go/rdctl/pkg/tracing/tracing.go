@@ -0,0 +1,73 @@
+// Package tracing records OpenTelemetry spans for rdctl operations, to help
+// find out why they are slow on a particular machine.  Nothing is recorded
+// unless the RD_TRACING environment variable is set.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnvVar is the environment variable that enables tracing.  It is either the
+// URL of an OTLP/HTTP endpoint (e.g. http://localhost:4318), or any other
+// true value to use the standard OTEL_EXPORTER_OTLP_* variables, which default
+// to the local endpoint.
+const EnvVar = "RD_TRACING"
+
+const tracerName = "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl"
+
+// Setup starts exporting spans, if tracing is enabled.  The returned function
+// must be called before exiting, to flush the spans still being batched.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	value := os.Getenv(EnvVar)
+	if enabled, err := strconv.ParseBool(value); value == "" || (err == nil && !enabled) {
+		return func(context.Context) error { return nil }, nil
+	}
+	var options []otlptracehttp.Option
+	if strings.Contains(value, "://") {
+		options = append(options, otlptracehttp.WithEndpointURL(value))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "rdctl"),
+			attribute.Int("process.pid", os.Getpid()),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logrus.Warnf("Tracing: %s", err)
+	}))
+	return provider.Shutdown, nil
+}
+
+// Start starts a span, as a child of the span in the context (if any).
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends the span, recording the error that the operation failed with, if
+// any.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
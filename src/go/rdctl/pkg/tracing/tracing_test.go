@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	for _, value := range []string{"", "false", "0"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv(EnvVar, value)
+			provider := otel.GetTracerProvider()
+			shutdown, err := Setup(context.Background())
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+			assert.Equal(t, provider, otel.GetTracerProvider())
+			_, span := Start(context.Background(), "test")
+			assert.False(t, span.IsRecording())
+			End(span, nil)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CopyOptions control how CopyFile copies a file.
+type CopyOptions struct {
+	// Mode is the permissions of the destination file, if it is created.
+	Mode os.FileMode
+	// Clone makes a copy-on-write clone of the file where the filesystem
+	// supports it (clonefile on macOS, FICLONE on Linux); the copy then takes
+	// no time or space, so this suits large files such as VM disks.
+	Clone bool
+	// Progress, if set, is called as the copy proceeds with the number of
+	// bytes done and the size of the file.
+	Progress func(done, total int64)
+}
+
+// copyBufferSize is the size of the buffers used when the contents of a file
+// have to be copied through user space.
+const copyBufferSize = 1024 * 1024
+
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, copyBufferSize)
+		return &buffer
+	},
+}
+
+// segment is a region of a file that holds data, as opposed to a hole.
+type segment struct {
+	offset, length int64
+}
+
+// progressTracker accumulates the progress of a copy.
+type progressTracker struct {
+	done, total int64
+	report      func(done, total int64)
+}
+
+func (p *progressTracker) add(n int64) {
+	p.done += n
+	if p.report != nil {
+		p.report(p.done, p.total)
+	}
+}
+
+// CopyFile copies the file at src to dst, creating the directory holding dst
+// if needed.  It uses the fastest method available: a copy-on-write clone if
+// requested, else copying within the kernel (copy_file_range or sendfile on
+// Linux), else copying through a pooled buffer.  Holes in sparse files are
+// preserved.
+func CopyFile(dst, src string, options CopyOptions) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create destination parent dir: %w", err)
+	}
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+	info, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read source file info: %w", err)
+	}
+	progress := &progressTracker{total: info.Size(), report: options.Progress}
+	if options.Clone {
+		cloned, err := cloneFile(dst, src, srcFile, options.Mode)
+		if err != nil {
+			return fmt.Errorf("failed to clone src to dst: %w", err)
+		}
+		if cloned {
+			progress.add(info.Size())
+			return nil
+		}
+	}
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, options.Mode)
+	if err != nil {
+		return fmt.Errorf("failed to open destination file: %w", err)
+	}
+	defer dstFile.Close()
+	if err := copyContents(dstFile, srcFile, info.Size(), progress); err != nil {
+		return fmt.Errorf("failed to copy contents of src to dst: %w", err)
+	}
+	return dstFile.Close()
+}
+
+// copyContents copies the data segments of the source file, skipping holes,
+// and then sets the size of the destination so that a trailing hole is kept.
+func copyContents(dst, src *os.File, size int64, progress *progressTracker) error {
+	segments, err := dataSegments(src, size)
+	if err != nil {
+		return err
+	}
+	var position int64
+	for _, segment := range segments {
+		// Holes are not written, but count towards the progress.
+		progress.add(segment.offset - position)
+		if err := copyRange(dst, src, segment.offset, segment.length, progress); err != nil {
+			return err
+		}
+		position = segment.offset + segment.length
+	}
+	progress.add(size - position)
+	return dst.Truncate(size)
+}
+
+// bufferedCopy copies a range of the file through a pooled buffer.
+func bufferedCopy(dst, src *os.File, offset, length int64, progress *progressTracker) error {
+	bufferPointer := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(bufferPointer)
+	buffer := *bufferPointer
+	for length > 0 {
+		chunk := buffer[:min(int64(len(buffer)), length)]
+		n, err := src.ReadAt(chunk, offset)
+		if n > 0 {
+			if _, err := dst.WriteAt(chunk[:n], offset); err != nil {
+				return err
+			}
+			offset += int64(n)
+			length -= int64(n)
+			progress.add(int64(n))
+		}
+		if errors.Is(err, io.EOF) {
+			// The file was truncated while being copied.
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
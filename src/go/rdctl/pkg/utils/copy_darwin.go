@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile replaces dst with a clone of src made with clonefile, returning
+// false if the filesystem doesn't support it, or the files are on different
+// volumes.
+func cloneFile(dst, src string, _ *os.File, _ os.FileMode) (bool, error) {
+	if err := os.RemoveAll(dst); err != nil {
+		return false, fmt.Errorf("failed to remove existing destination file: %w", err)
+	}
+	err := unix.Clonefile(src, dst, 0)
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// copyRange copies a range of the file; macOS has no equivalent of
+// copy_file_range for regular files.
+func copyRange(dst, src *os.File, offset, length int64, progress *progressTracker) error {
+	return bufferedCopy(dst, src, offset, length, progress)
+}
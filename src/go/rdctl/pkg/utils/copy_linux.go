@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// kernelCopyChunk is how much is copied by each copy_file_range or sendfile
+// call, so that progress is reported regularly.
+const kernelCopyChunk = 16 * 1024 * 1024
+
+// cloneFile makes dst a clone of src with the FICLONE ioctl, returning false
+// if the filesystem doesn't support it, or the files are on different
+// filesystems.
+func cloneFile(dst, _ string, srcFile *os.File, mode os.FileMode) (bool, error) {
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return false, fmt.Errorf("failed to open destination file: %w", err)
+	}
+	defer dstFile.Close()
+	err = unix.IoctlFileClone(int(dstFile.Fd()), int(srcFile.Fd()))
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, dstFile.Close()
+}
+
+// isUnsupported reports whether a kernel copy failed because it can't be used
+// for these files, rather than because of an I/O error.
+func isUnsupported(err error) bool {
+	for _, errno := range []error{unix.ENOSYS, unix.EXDEV, unix.EINVAL, unix.EOPNOTSUPP, unix.EPERM} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyRange copies a range of the file with copy_file_range, which copies
+// within the kernel and may share blocks on filesystems that support it,
+// falling back to sendfile.
+func copyRange(dst, src *os.File, offset, length int64, progress *progressTracker) error {
+	for length > 0 {
+		srcOffset, dstOffset := offset, offset
+		n, err := unix.CopyFileRange(int(src.Fd()), &srcOffset, int(dst.Fd()), &dstOffset, int(min(length, kernelCopyChunk)), 0)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		} else if isUnsupported(err) {
+			return sendfileRange(dst, src, offset, length, progress)
+		} else if err != nil {
+			return err
+		} else if n == 0 {
+			// The file was truncated while being copied.
+			return nil
+		}
+		offset += int64(n)
+		length -= int64(n)
+		progress.add(int64(n))
+	}
+	return nil
+}
+
+// sendfileRange copies a range of the file with sendfile, which writes at the
+// current offset of the destination, falling back to a buffered copy.
+func sendfileRange(dst, src *os.File, offset, length int64, progress *progressTracker) error {
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	for length > 0 {
+		srcOffset := offset
+		n, err := unix.Sendfile(int(dst.Fd()), int(src.Fd()), &srcOffset, int(min(length, kernelCopyChunk)))
+		if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+			continue
+		} else if isUnsupported(err) {
+			return bufferedCopy(dst, src, offset, length, progress)
+		} else if err != nil {
+			return err
+		} else if n == 0 {
+			return nil
+		}
+		offset += int64(n)
+		length -= int64(n)
+		progress.add(int64(n))
+	}
+	return nil
+}
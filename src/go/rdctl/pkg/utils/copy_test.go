@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFile(t *testing.T) {
+	contents := bytes.Repeat([]byte("rancher desktop "), 300*1024)
+	for name, clone := range map[string]bool{"copy": false, "clone": true} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			dst := filepath.Join(dir, "nested", "dst")
+			require.NoError(t, os.WriteFile(src, contents, 0o644))
+			var done, total int64
+			err := CopyFile(dst, src, CopyOptions{
+				Mode:  0o600,
+				Clone: clone,
+				Progress: func(d, t int64) {
+					done, total = d, t
+				},
+			})
+			require.NoError(t, err)
+			copied, err := os.ReadFile(dst)
+			require.NoError(t, err)
+			assert.Equal(t, contents, copied)
+			assert.Equal(t, int64(len(contents)), done)
+			assert.Equal(t, int64(len(contents)), total)
+		})
+	}
+}
+
+func TestCopyFileReplaces(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("new"), 0o644))
+	require.NoError(t, os.WriteFile(dst, []byte("much longer old contents"), 0o644))
+	require.NoError(t, CopyFile(dst, src, CopyOptions{Mode: 0o644}))
+	copied, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(copied))
+}
+
+func TestCopyFileSparse(t *testing.T) {
+	const size = 64 * 1024 * 1024
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	file, err := os.Create(src)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte("start"), 0)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte("middle"), size/2)
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(size))
+	require.NoError(t, file.Close())
+
+	require.NoError(t, CopyFile(dst, src, CopyOptions{Mode: 0o644}))
+	expected, err := os.ReadFile(src)
+	require.NoError(t, err)
+	copied, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), len(copied))
+	assert.True(t, bytes.Equal(expected, copied), "the contents must match")
+	if srcUsage, err := allocatedSize(src); err == nil {
+		dstUsage, err := allocatedSize(dst)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, dstUsage, srcUsage+1024*1024, "holes must be preserved")
+	}
+}
+
+func TestCopyFileMissing(t *testing.T) {
+	dir := t.TempDir()
+	err := CopyFile(filepath.Join(dir, "dst"), filepath.Join(dir, "missing"), CopyOptions{Mode: 0o644})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,41 @@
+//go:build linux || darwin
+
+package utils
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dataSegments returns the regions of the file that hold data, found with
+// SEEK_DATA and SEEK_HOLE.  If the filesystem can't tell, the whole file is
+// one segment.
+func dataSegments(file *os.File, size int64) ([]segment, error) {
+	var segments []segment
+	fd := int(file.Fd())
+	for offset := int64(0); offset < size; {
+		start, err := unix.Seek(fd, offset, unix.SEEK_DATA)
+		if errors.Is(err, unix.ENXIO) {
+			// There is no more data after offset.
+			break
+		} else if err != nil {
+			if offset == 0 && errors.Is(err, unix.EINVAL) {
+				return []segment{{0, size}}, nil
+			}
+			return nil, err
+		}
+		end, err := unix.Seek(fd, start, unix.SEEK_HOLE)
+		if err != nil {
+			return nil, err
+		}
+		end = min(end, size)
+		if start >= end {
+			break
+		}
+		segments = append(segments, segment{start, end - start})
+		offset = end
+	}
+	return segments, nil
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocatedSize returns the space used by the file on disk.
+func allocatedSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Sys().(*syscall.Stat_t).Blocks * 512, nil
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"os"
+)
+
+// cloneFile is not supported on Windows, where block cloning is limited to
+// ReFS volumes.
+func cloneFile(_, _ string, _ *os.File, _ os.FileMode) (bool, error) {
+	return false, nil
+}
+
+// dataSegments treats the whole file as data, as sparse files are rare on
+// Windows.
+func dataSegments(_ *os.File, size int64) ([]segment, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	return []segment{{0, size}}, nil
+}
+
+// copyRange copies a range of the file through a buffer.
+func copyRange(dst, src *os.File, offset, length int64, progress *progressTracker) error {
+	return bufferedCopy(dst, src, offset, length, progress)
+}
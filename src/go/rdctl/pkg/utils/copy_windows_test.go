@@ -0,0 +1,10 @@
+package utils
+
+import (
+	"errors"
+)
+
+// allocatedSize is not checked on Windows, where holes are not preserved.
+func allocatedSize(string) (int64, error) {
+	return 0, errors.New("not supported")
+}
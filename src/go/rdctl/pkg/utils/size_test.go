@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0 B", FormatSize(0))
+	assert.Equal(t, "1023 B", FormatSize(1023))
+	assert.Equal(t, "1.0 KiB", FormatSize(1024))
+	assert.Equal(t, "1.5 MiB", FormatSize(3*512*1024))
+	assert.Equal(t, "2048.0 TiB", FormatSize(2048*1024*1024*1024*1024))
+}
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "b"), make([]byte, 23), 0o644))
+	size, err := DiskUsage(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), size)
+
+	size, err = DiskUsage(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"kubernetes", "application", "WSL", "version"}
+	assert.Equal(t, "kubernetes", Suggest("kubernets", candidates))
+	assert.Equal(t, "version", Suggest("verison", candidates))
+	assert.Equal(t, "WSL", Suggest("wsl", candidates))
+	assert.Equal(t, "", Suggest("containerEngine", candidates))
+	assert.Equal(t, "", Suggest("kubernetes", candidates))
+}
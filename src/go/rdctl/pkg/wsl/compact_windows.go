@@ -0,0 +1,81 @@
+package wsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CompactVHDX shrinks the dynamically sized virtual disk at the given path,
+// returning the space freed inside it to the host (WSL never does this on its
+// own).  The disk must not be in use, and this needs administrator
+// privileges.  Optimize-VHD is used if the Hyper-V module is installed;
+// otherwise, diskpart is used.
+func CompactVHDX(ctx context.Context, path string) error {
+	err := optimizeVHD(ctx, path)
+	if err == nil {
+		return nil
+	}
+	logrus.Debugf("Optimize-VHD failed, falling back to diskpart: %s", err)
+	if err := diskpartCompact(ctx, path); err != nil {
+		return fmt.Errorf("failed to compact %s: %w", path, err)
+	}
+	return nil
+}
+
+func optimizeVHD(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+		"Optimize-VHD -Path $env:RD_VHDX_PATH -Mode Full")
+	// Pass the path through the environment, to avoid having to quote it.
+	cmd.Env = append(os.Environ(), "RD_VHDX_PATH="+path)
+	if output, err := cmd.Output(); err != nil {
+		return wrapExecError(output, err)
+	}
+	return nil
+}
+
+func diskpartCompact(ctx context.Context, path string) error {
+	// Paths on Windows can't contain double quotes, so this is safe.
+	selectDisk := fmt.Sprintf("select vdisk file=\"%s\"", path)
+	err := runDiskpart(ctx, selectDisk, "attach vdisk readonly", "compact vdisk", "detach vdisk")
+	if err != nil {
+		// diskpart stops at the first error; make sure the disk isn't left
+		// attached.  This fails if it was never attached, which is fine.
+		_ = runDiskpart(context.Background(), selectDisk, "detach vdisk")
+	}
+	return err
+}
+
+// runDiskpart runs the given diskpart commands as a script.
+func runDiskpart(ctx context.Context, commands ...string) error {
+	scriptDir, err := os.MkdirTemp("", "rdctl-diskpart-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scriptDir)
+	scriptPath := filepath.Join(scriptDir, "script.txt")
+	if err := os.WriteFile(scriptPath, []byte(strings.Join(commands, "\r\n")+"\r\n"), 0o600); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "diskpart.exe", "/s", scriptPath)
+	if output, err := cmd.Output(); err != nil {
+		return wrapExecError(output, err)
+	}
+	return nil
+}
+
+// wrapExecError combines the error from *exec.Cmd.Output() with the output of
+// the command, to make it more helpful.
+func wrapExecError(output []byte, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w stdout: %q stderr: %q", err, string(output), exitErr.Stderr)
+	}
+	return fmt.Errorf("%w: stdout: %q", err, string(output))
+}
@@ -1,9 +1,11 @@
 package wsl
 
 import (
+	"context"
 	"fmt"
+
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/factoryreset"
-	"os/exec"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
 )
 
 type WSL interface {
@@ -16,6 +18,8 @@ type WSL interface {
 	// and names it distroName. Installs the distro in the directory
 	// given by installLocation.
 	ImportDistro(distroName, installLocation, fileName string) error
+	// Stops the given distro, if it is running.
+	TerminateDistro(distroName string) error
 }
 
 type WSLImpl struct{}
@@ -25,28 +29,27 @@ func (wsl WSLImpl) UnregisterDistros() error {
 }
 
 func (wsl WSLImpl) ExportDistro(distroName, fileName string) error {
-	cmd := exec.Command("wsl.exe", "--export", distroName, fileName)
-	if output, err := cmd.Output(); err != nil {
-		return fmt.Errorf("failed to export WSL distro %q: %w", distroName, wrapWSLError(output, err))
+	// Exporting can take a long time, so there is no timeout.
+	if _, err := wslexe.RunWithOptions(context.Background(), wslexe.Options{}, "--export", distroName, fileName); err != nil {
+		return fmt.Errorf("failed to export WSL distro %q: %w", distroName, err)
 	}
 	return nil
 }
 
 func (wsl WSLImpl) ImportDistro(distroName, installLocation, fileName string) error {
-	cmd := exec.Command("wsl.exe", "--import", distroName, installLocation, fileName, "--version", "2")
-	if output, err := cmd.Output(); err != nil {
-		return fmt.Errorf("failed to import WSL distro %q: %w", distroName, wrapWSLError(output, err))
+	// Importing isn't retried, as a failed attempt may leave the distro
+	// registered.
+	_, err := wslexe.RunWithOptions(context.Background(), wslexe.Options{},
+		"--import", distroName, installLocation, fileName, "--version", "2")
+	if err != nil {
+		return fmt.Errorf("failed to import WSL distro %q: %w", distroName, err)
 	}
 	return nil
 }
 
-// wrapWSLError is used to make errors returned from
-// *exec.Cmd.Output() more helpful. It combines the string from the
-// returned error, any data written to stdout, and any data written
-// to stderr into the string of one error.
-func wrapWSLError(output []byte, err error) error {
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		return fmt.Errorf("%w stdout: %q stderr: %q", err, string(output), exitErr.Stderr)
+func (wsl WSLImpl) TerminateDistro(distroName string) error {
+	if _, err := wslexe.Run(context.Background(), "--terminate", distroName); err != nil {
+		return fmt.Errorf("failed to terminate WSL distro %q: %w", distroName, err)
 	}
-	return fmt.Errorf("%w: stdout: %q", err, string(output))
+	return nil
 }
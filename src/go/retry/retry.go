@@ -0,0 +1,159 @@
+// Package retry repeats operations that can fail transiently, such as
+// requests to a backend that is starting or commands the WSL service rejects
+// while it is busy, waiting between attempts with exponential backoff and
+// jitter so that several clients don't retry in lockstep.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Policy describes how an operation is retried.
+type Policy struct {
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts; zero means no cap.
+	MaxDelay time.Duration
+	// Multiplier scales the delay after each attempt; zero means 2.
+	Multiplier float64
+	// Jitter is the fraction of each delay that is randomized: with 0.2, a
+	// delay of one second becomes between 0.8 and 1 second.
+	Jitter float64
+	// MaxAttempts is the number of attempts, including the first; zero means
+	// no limit, other than the context.
+	MaxAttempts int
+	// Retryable classifies errors; nil means every error is retryable.
+	// Errors made with Permanent are never retried.
+	Retryable func(error) bool
+	// Notify, if set, is called before waiting to retry.
+	Notify func(err error, delay time.Duration)
+}
+
+// random returns a number in [0, 1); it is a variable for testing.
+var random = rand.Float64
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error that must not be retried; Do returns the error
+// itself.  Permanent(nil) is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type afterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *afterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *afterError) Unwrap() error {
+	return e.err
+}
+
+// After marks an error that should be retried after the given delay (e.g. as
+// asked by a Retry-After header) instead of the one the policy computes.  It
+// is still capped by MaxDelay; a zero delay means the computed one.
+func After(err error, delay time.Duration) error {
+	return &afterError{err: err, delay: delay}
+}
+
+// Do calls the operation until it succeeds, fails with an error that isn't
+// retryable, runs out of attempts, or the context is done.  The error is the
+// one returned by the last attempt; if the context is done while waiting, it
+// also wraps the context's error.
+func Do(ctx context.Context, policy Policy, operation func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, operation(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, policy Policy, operation func(ctx context.Context) (T, error)) (T, error) {
+	backoff := policy.capped(policy.InitialDelay)
+	for attempt := 1; ; attempt++ {
+		value, err := operation(ctx)
+		if err == nil {
+			return value, nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return value, permanent.err
+		}
+		if policy.Retryable != nil && !policy.Retryable(err) {
+			return value, err
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return value, unwrapAfter(err)
+		}
+		delay := policy.jitter(backoff)
+		var after *afterError
+		if errors.As(err, &after) && after.delay > 0 {
+			delay = policy.capped(after.delay)
+		}
+		backoff = policy.next(backoff)
+		err = unwrapAfter(err)
+		if policy.Notify != nil {
+			policy.Notify(err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// unwrapAfter removes the delay requested with After from the error.
+func unwrapAfter(err error) error {
+	if after, ok := err.(*afterError); ok {
+		return after.err
+	}
+	return err
+}
+
+// jitter randomizes the given fraction of the delay.
+func (p Policy) jitter(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return delay
+	}
+	return delay - time.Duration(float64(delay)*min(p.Jitter, 1)*random())
+}
+
+// next returns the delay after the given one.
+func (p Policy) next(delay time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	return p.capped(time.Duration(float64(delay) * multiplier))
+}
+
+func (p Policy) capped(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
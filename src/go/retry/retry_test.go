@@ -0,0 +1,138 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+// recordDelays makes the policy record the delays it waits for.
+func recordDelays(policy *Policy) *[]time.Duration {
+	var delays []time.Duration
+	policy.Notify = func(_ error, delay time.Duration) {
+		delays = append(delays, delay)
+	}
+	return &delays
+}
+
+func TestDoSucceedsAfterRetries(t *testing.T) {
+	policy := Policy{InitialDelay: time.Millisecond, MaxDelay: 3 * time.Millisecond}
+	delays := recordDelays(&policy)
+	attempts := 0
+	err := Do(context.Background(), policy, func(context.Context) error {
+		attempts++
+		if attempts < 4 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	if len(*delays) != len(expected) {
+		t.Fatalf("Expected delays %v, got %v", expected, *delays)
+	}
+	for i, delay := range *delays {
+		if delay != expected[i] {
+			t.Errorf("Expected delays %v, got %v", expected, *delays)
+		}
+	}
+}
+
+func TestDoStops(t *testing.T) {
+	errFatal := errors.New("fatal")
+	for name, tc := range map[string]struct {
+		policy   Policy
+		err      error
+		expected error
+		attempts int
+	}{
+		"after the maximum attempts": {
+			policy:   Policy{MaxAttempts: 3},
+			err:      errTransient,
+			expected: errTransient,
+			attempts: 3,
+		},
+		"on errors that are not retryable": {
+			policy:   Policy{Retryable: func(err error) bool { return errors.Is(err, errTransient) }},
+			err:      errFatal,
+			expected: errFatal,
+			attempts: 1,
+		},
+		"on permanent errors": {
+			err:      Permanent(errFatal),
+			expected: errFatal,
+			attempts: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			err := Do(context.Background(), tc.policy, func(context.Context) error {
+				attempts++
+				return tc.err
+			})
+			if err != tc.expected {
+				t.Errorf("Expected error %v, got %v", tc.expected, err)
+			}
+			if attempts != tc.attempts {
+				t.Errorf("Expected %d attempts, got %d", tc.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := Policy{InitialDelay: time.Hour}
+	policy.Notify = func(error, time.Duration) { cancel() }
+	err := Do(ctx, policy, func(context.Context) error {
+		return errTransient
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errTransient) {
+		t.Errorf("Expected the context error and the last error, got %v", err)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	policy := Policy{InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MaxAttempts: 3}
+	delays := recordDelays(&policy)
+	err := Do(context.Background(), policy, func(context.Context) error {
+		return After(errTransient, time.Hour)
+	})
+	if err != errTransient {
+		t.Errorf("Expected the error given to After, got %v", err)
+	}
+	for _, delay := range *delays {
+		if delay != 5*time.Millisecond {
+			t.Errorf("Expected the requested delay capped at the maximum, got %v", *delays)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	oldRandom := random
+	t.Cleanup(func() { random = oldRandom })
+	random = func() float64 { return 0.5 }
+	policy := Policy{Jitter: 0.2}
+	if delay := policy.jitter(time.Second); delay != 900*time.Millisecond {
+		t.Errorf("Expected 900ms, got %s", delay)
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	attempts := 0
+	value, err := DoValue(context.Background(), Policy{}, func(context.Context) (int, error) {
+		attempts++
+		if attempts < 2 {
+			return 0, errTransient
+		}
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Errorf("Expected 42, got %d, %v", value, err)
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"context"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/pkg/config"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/pkg/vmsock"
@@ -30,27 +29,24 @@ var hostCmd = &cobra.Command{
 	Use:   "host",
 	Short: "vtunnel host process",
 	Long: `vtunnel host process runs on the host machine and binds to localhost
-and a given port acting as a host end of the tunnel.`,
+and a given port acting as a host end of the tunnel. Writing a "reload" line
+to its standard input makes it reload the configuration file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 		configPath, err := cmd.Flags().GetString("config-path")
 		if err != nil {
 			return err
 		}
-		conf, err := config.NewConfig(configPath)
-		if err != nil {
-			return err
-		}
-		errs, _ := errgroup.WithContext(context.Background())
-		for _, tun := range conf.Tunnel {
+		ctx := cmd.Context()
+		manager := newTunnelManager(func(ctx context.Context, tun config.Tunnel) error {
 			hostConnector := vmsock.HostConnector{
 				UpstreamServerAddress: tun.UpstreamServerAddress,
 				VsockListenPort:       tun.VsockHostPort,
 				PeerHandshakePort:     tun.HandshakePort,
 			}
-			errs.Go(hostConnector.ListenAndDial)
-		}
-		return errs.Wait()
+			return hostConnector.ListenAndDial(ctx)
+		})
+		return manager.serve(ctx, configPath, reloadRequests(ctx))
 	},
 }
 
@@ -30,30 +30,28 @@ var peerCmd = &cobra.Command{
 	Use:   "peer",
 	Short: "vtunnel peer process",
 	Long: `vtunnel peer process runs in the WSL VM and binds to a given
-IP and port acting as a peer end of the tunnel.`,
+IP and port acting as a peer end of the tunnel. Sending it SIGHUP makes it
+reload the configuration file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 		path, err := cmd.Flags().GetString("config-path")
 		if err != nil {
 			return err
 		}
-		conf, err := config.NewConfig(path)
-		if err != nil {
-			return err
-		}
-
-		errs, _ := errgroup.WithContext(context.Background())
-		for _, tun := range conf.Tunnel {
+		ctx := cmd.Context()
+		manager := newTunnelManager(func(ctx context.Context, tun config.Tunnel) error {
 			peerConnector := vmsock.PeerConnector{
 				IPv4ListenAddress:  tun.PeerAddress,
 				TCPListenPort:      tun.PeerPort,
 				VsockHandshakePort: tun.HandshakePort,
 				VsockHostPort:      tun.VsockHostPort,
 			}
-			go peerConnector.ListenAndHandshake()
-			errs.Go(peerConnector.ListenTCP)
-		}
-		return errs.Wait()
+			errs, ctx := errgroup.WithContext(ctx)
+			errs.Go(func() error { return peerConnector.ListenAndHandshake(ctx) })
+			errs.Go(func() error { return peerConnector.ListenTCP(ctx) })
+			return errs.Wait()
+		})
+		return manager.serve(ctx, path, reloadRequests(ctx))
 	},
 }
 
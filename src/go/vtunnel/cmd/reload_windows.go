@@ -0,0 +1,51 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// reloadMessage is the control message, written as a line to standard input,
+// that asks the process to reload its configuration; Windows has no SIGHUP.
+const reloadMessage = "reload"
+
+// reloadRequests returns a channel that receives whenever a reload message is
+// read from standard input.
+func reloadRequests(ctx context.Context) <-chan struct{} {
+	reload := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			message := strings.TrimSpace(scanner.Text())
+			if message != reloadMessage {
+				logrus.Warnf("ignoring unknown control message %q", message)
+				continue
+			}
+			select {
+			case reload <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return reload
+}
@@ -16,12 +16,17 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
 	"os"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/profiling"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// profilingCloser stops serving profiles when the command is done.
+var profilingCloser io.Closer
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "vtunnel",
@@ -29,6 +34,15 @@ var rootCmd = &cobra.Command{
 	Long: `vtunnel is a network communication tunnel that bridges the host and the WSL VM
 communications over TCP. The tunnel's peer process listens on a provided IP:HOST inside the WSL VM.
 The host process on windows forwards the TCP payload to a given address over TCP.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Profiling is only for diagnosis; don't fail the tunnel over it.
+		closer, err := profiling.Start(cmd.CommandPath())
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to serve profiles")
+		} else {
+			profilingCloser = closer
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -38,6 +52,9 @@ func Execute() {
 	defer writer.Close()
 	rootCmd.SetErr(writer)
 	err := rootCmd.Execute()
+	if profilingCloser != nil {
+		_ = profilingCloser.Close()
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/pkg/config"
+)
+
+// tunnelFunc runs a single tunnel until the context is cancelled.
+type tunnelFunc func(ctx context.Context, tun config.Tunnel) error
+
+type runningTunnel struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// tunnelManager runs the tunnels in the configuration, starting and stopping
+// them as the configuration is reloaded.
+type tunnelManager struct {
+	run     tunnelFunc
+	running map[config.Tunnel]*runningTunnel
+	errs    chan error
+}
+
+func newTunnelManager(run tunnelFunc) *tunnelManager {
+	return &tunnelManager{
+		run:     run,
+		running: make(map[config.Tunnel]*runningTunnel),
+		errs:    make(chan error),
+	}
+}
+
+// serve runs the tunnels in the configuration file until one of them fails or
+// the context is cancelled, reloading the file whenever reload receives.
+func (m *tunnelManager) serve(ctx context.Context, configPath string, reload <-chan struct{}) error {
+	conf, err := config.NewConfig(configPath)
+	if err != nil {
+		return err
+	}
+	defer m.apply(ctx, nil)
+	m.apply(ctx, conf.Tunnel)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-m.errs:
+			return err
+		case <-reload:
+			conf, err := config.NewConfig(configPath)
+			if err != nil {
+				logrus.Errorf("failed to reload configuration from %s, keeping existing tunnels: %v", configPath, err)
+				continue
+			}
+			logrus.Infof("reloaded configuration from %s", configPath)
+			m.apply(ctx, conf.Tunnel)
+		}
+	}
+}
+
+// apply stops the running tunnels that are not in the given list, and then
+// starts the ones that are not yet running.  A tunnel that has changed is
+// restarted, as its ports may have.
+func (m *tunnelManager) apply(ctx context.Context, tunnels []config.Tunnel) {
+	wanted := make(map[config.Tunnel]bool)
+	for _, tun := range tunnels {
+		wanted[tun] = true
+	}
+	for tun, r := range m.running {
+		if !wanted[tun] {
+			logrus.Infof("stopping tunnel %q", tun.Name)
+			r.cancel()
+			<-r.done
+			delete(m.running, tun)
+		}
+	}
+	for _, tun := range tunnels {
+		if _, ok := m.running[tun]; ok {
+			continue
+		}
+		logrus.Infof("starting tunnel %q", tun.Name)
+		tunCtx, cancel := context.WithCancel(ctx)
+		r := &runningTunnel{cancel: cancel, done: make(chan struct{})}
+		m.running[tun] = r
+		go func(tun config.Tunnel) {
+			defer close(r.done)
+			if err := m.run(tunCtx, tun); err != nil && tunCtx.Err() == nil {
+				select {
+				case m.errs <- fmt.Errorf("tunnel %q: %w", tun.Name, err):
+				case <-tunCtx.Done():
+				}
+			}
+		}(tun)
+	}
+}
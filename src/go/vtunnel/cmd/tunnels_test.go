@@ -0,0 +1,143 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/pkg/config"
+)
+
+// fakeTunnels records which tunnels are running.
+type fakeTunnels struct {
+	mutex   sync.Mutex
+	running map[string]bool
+	started chan string
+	fail    chan error
+}
+
+func newFakeTunnels() *fakeTunnels {
+	return &fakeTunnels{
+		running: make(map[string]bool),
+		started: make(chan string, 10),
+		fail:    make(chan error),
+	}
+}
+
+func (f *fakeTunnels) run(ctx context.Context, tun config.Tunnel) error {
+	f.mutex.Lock()
+	f.running[tun.Name] = true
+	f.mutex.Unlock()
+	defer func() {
+		f.mutex.Lock()
+		delete(f.running, tun.Name)
+		f.mutex.Unlock()
+	}()
+	f.started <- tun.Name
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-f.fail:
+		return err
+	}
+}
+
+func (f *fakeTunnels) names() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var names []string
+	for name := range f.running {
+		names = append(names, name)
+	}
+	return names
+}
+
+func writeConfig(t *testing.T, path string, tunnels ...config.Tunnel) {
+	data, err := yaml.Marshal(config.Config{Tunnel: tunnels})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func waitStarted(t *testing.T, f *fakeTunnels, expected ...string) {
+	var started []string
+	for range expected {
+		select {
+		case name := <-f.started:
+			started = append(started, name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for tunnels %v, got %v", expected, started)
+		}
+	}
+	assert.ElementsMatch(t, expected, started)
+}
+
+func TestTunnelManagerReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	first := config.Tunnel{Name: "first", HandshakePort: 1, VsockHostPort: 2, PeerPort: 3}
+	second := config.Tunnel{Name: "second", HandshakePort: 4, VsockHostPort: 5, PeerPort: 6}
+	writeConfig(t, configPath, first, second)
+
+	fake := newFakeTunnels()
+	ctx, cancel := context.WithCancel(context.Background())
+	reload := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- newTunnelManager(fake.run).serve(ctx, configPath, reload)
+	}()
+	waitStarted(t, fake, "first", "second")
+
+	// Removing a tunnel stops it, and changing one restarts it.
+	changed := second
+	changed.PeerPort = 7
+	third := config.Tunnel{Name: "third", HandshakePort: 8, VsockHostPort: 9, PeerPort: 10}
+	writeConfig(t, configPath, changed, third)
+	reload <- struct{}{}
+	waitStarted(t, fake, "second", "third")
+	assert.ElementsMatch(t, []string{"second", "third"}, fake.names())
+
+	// An invalid configuration keeps the existing tunnels.
+	require.NoError(t, os.WriteFile(configPath, []byte("tunnel: ["), 0o644))
+	reload <- struct{}{}
+	assert.ElementsMatch(t, []string{"second", "third"}, fake.names())
+
+	cancel()
+	assert.NoError(t, <-result)
+	assert.Empty(t, fake.names())
+}
+
+func TestTunnelManagerFailure(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configPath, config.Tunnel{Name: "broken"})
+
+	fake := newFakeTunnels()
+	result := make(chan error)
+	go func() {
+		result <- newTunnelManager(fake.run).serve(context.Background(), configPath, nil)
+	}()
+	waitStarted(t, fake, "broken")
+	fake.fail <- errors.New("listen failed")
+	assert.ErrorContains(t, <-result, `tunnel "broken": listen failed`)
+}
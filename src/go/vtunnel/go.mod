@@ -5,7 +5,11 @@ go 1.21
 require (
 	github.com/Microsoft/go-winio v0.6.1
 	github.com/google/uuid v1.4.0
+	github.com/hashicorp/yamux v0.1.1
 	github.com/linuxkit/virtsock v0.0.0-20220523201153-1a23e78aa7a2
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry v0.0.0
 	github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper v0.0.0-20220526041742-c1ed19db6a88
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.8.0
@@ -24,3 +28,9 @@ require (
 	golang.org/x/mod v0.8.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 )
+
+replace (
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging => ../logging
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling => ../profiling
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry => ../retry
+)
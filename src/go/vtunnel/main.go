@@ -13,8 +13,12 @@ limitations under the License.
 
 package main
 
-import "github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/cmd"
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/vtunnel/cmd"
+)
 
 func main() {
+	defer logging.HandlePanic()
 	cmd.Execute()
 }
@@ -14,12 +14,17 @@ limitations under the License.
 package vmsock
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/yamux"
 	"github.com/linuxkit/virtsock/pkg/vsock"
 	"github.com/sirupsen/logrus"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/retry"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dockerproxy/util"
 )
 
@@ -28,21 +33,35 @@ type PeerConnector struct {
 	TCPListenPort      int
 	VsockHandshakePort uint32
 	VsockHostPort      uint32
+
+	// dialHost connects to the host; it dials VsockHostPort when nil.
+	dialHost func() (net.Conn, error)
+
+	// mutex protects session, which carries the tunneled connections to the
+	// host; it is dialed when first needed, and again if it is closed.
+	mutex   sync.Mutex
+	session *yamux.Session
 }
 
 // ListenAndHandshake listens for incoming VSOCK connections from the Host process
 // The handshake is performed once during startup/restart to make sure that
 // host process is talking to a right hyper-v VM (most likely WSL)
-func (p *PeerConnector) ListenAndHandshake() {
+// It returns once the context is cancelled.
+func (p *PeerConnector) ListenAndHandshake(ctx context.Context) error {
 	l, err := vsock.Listen(vsock.CIDAny, p.VsockHandshakePort)
 	if err != nil {
-		logrus.Fatalf("PeerHandshake listen for incoming vsock: %v", err)
+		return fmt.Errorf("PeerHandshake listen for incoming vsock: %w", err)
 	}
 	defer l.Close()
+	stop := context.AfterFunc(ctx, func() { l.Close() })
+	defer stop()
 
 	for {
 		conn, err := l.Accept()
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
 			logrus.Errorf("PeerHandshake accepting incoming socket connection: %v", err)
 			continue
 		}
@@ -59,16 +78,23 @@ func (p *PeerConnector) ListenAndHandshake() {
 // ListenTCP starts a tcp listener and accepts TCP connections on a given port and addr
 // when a new connection is accepted, ListenTCP handles the connection by establishing
 // virtual socket to the host and sends the packets over the AF_VSOCK
-func (p *PeerConnector) ListenTCP() error {
+// It returns once the context is cancelled.
+func (p *PeerConnector) ListenTCP(ctx context.Context) error {
 	l, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP(p.IPv4ListenAddress), Port: p.TCPListenPort})
 	if err != nil {
 		return fmt.Errorf("ListenTCP: %w", err)
 	}
 	defer l.Close()
+	defer p.closeSession()
+	stop := context.AfterFunc(ctx, func() { l.Close() })
+	defer stop()
 
 	for {
 		conn, err := l.Accept()
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
 			logrus.Errorf("ListenTCP accept connection: %v", err)
 			continue
 		}
@@ -78,9 +104,10 @@ func (p *PeerConnector) ListenTCP() error {
 
 func (p *PeerConnector) handleTCP(tConn net.Conn) {
 	defer tConn.Close()
-	vConn, err := vsock.Dial(vsock.CIDHost, p.VsockHostPort)
+	vConn, err := p.openStream()
 	if err != nil {
-		logrus.Fatalf("handleTCP dial to vsock host: %v", err)
+		logrus.Errorf("handleTCP open stream to vsock host: %v", err)
+		return
 	}
 	defer vConn.Close()
 
@@ -90,3 +117,75 @@ func (p *PeerConnector) handleTCP(tConn net.Conn) {
 		return
 	}
 }
+
+// openStream opens a new stream to the host over the shared session.  If that
+// fails, the session is assumed to be dead (e.g. the host process restarted)
+// and a new one is dialed once.
+func (p *PeerConnector) openStream() (net.Conn, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var session *yamux.Session
+		session, err = p.getSession()
+		if err != nil {
+			return nil, err
+		}
+		var stream *yamux.Stream
+		stream, err = session.OpenStream()
+		if err == nil {
+			return stream, nil
+		}
+		session.Close()
+	}
+	return nil, fmt.Errorf("failed to open stream: %w", err)
+}
+
+// dialPolicy retries dialing the host for a few seconds, so that connections
+// made while the host process restarts are not lost.
+var dialPolicy = retry.Policy{
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     time.Second,
+	Jitter:       0.2,
+	MaxAttempts:  6,
+	Notify: func(err error, delay time.Duration) {
+		logrus.Debugf("failed to dial vsock host, retrying in %s: %v", delay, err)
+	},
+}
+
+// getSession returns the session to the host, dialing it if there is none.
+func (p *PeerConnector) getSession() (*yamux.Session, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.session != nil && !p.session.IsClosed() {
+		return p.session, nil
+	}
+	dial := p.dialHost
+	if dial == nil {
+		dial = func() (net.Conn, error) {
+			return vsock.Dial(vsock.CIDHost, p.VsockHostPort)
+		}
+	}
+	conn, err := retry.DoValue(context.Background(), dialPolicy, func(context.Context) (net.Conn, error) {
+		return dial()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial vsock host: %w", err)
+	}
+	session, err := yamux.Client(conn, muxConfig())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	p.session = session
+	return session, nil
+}
+
+// closeSession closes the session to the host, if any, along with all of the
+// streams over it.
+func (p *PeerConnector) closeSession() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.session != nil {
+		p.session.Close()
+		p.session = nil
+	}
+}
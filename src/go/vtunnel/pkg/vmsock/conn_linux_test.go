@@ -0,0 +1,202 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package vmsock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// modeEcho makes the fake host echo everything sent on the stream.
+	modeEcho = 'e'
+	// modeStall makes the fake host stop reading the stream until released.
+	modeStall = 's'
+)
+
+// fakeHost stands in for the host process: it accepts connections over TCP
+// instead of AF_VSOCK, and serves each of them as a session.
+type fakeHost struct {
+	listener net.Listener
+	release  chan struct{}
+
+	mutex sync.Mutex
+	conns []net.Conn
+}
+
+func newFakeHost(t *testing.T) *fakeHost {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &fakeHost{listener: listener, release: make(chan struct{})}
+	t.Cleanup(func() {
+		cancel()
+		listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			h.mutex.Lock()
+			h.conns = append(h.conns, conn)
+			h.mutex.Unlock()
+			go serveSession(ctx, conn, h.handle)
+		}
+	}()
+	return h
+}
+
+// handle reads the mode of the stream from its first byte.
+func (h *fakeHost) handle(conn net.Conn) {
+	defer conn.Close()
+	mode := make([]byte, 1)
+	if _, err := io.ReadFull(conn, mode); err != nil {
+		return
+	}
+	if mode[0] == modeStall {
+		<-h.release
+	}
+	_, _ = io.Copy(conn, conn)
+}
+
+// sessions returns the number of connections the peer has made.
+func (h *fakeHost) sessions() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.conns)
+}
+
+// drop closes every connection from the peer, as if the host had restarted.
+func (h *fakeHost) drop() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, conn := range h.conns {
+		conn.Close()
+	}
+}
+
+func (h *fakeHost) peer(t *testing.T) *PeerConnector {
+	p := &PeerConnector{
+		dialHost: func() (net.Conn, error) {
+			return net.Dial("tcp", h.listener.Addr().String())
+		},
+	}
+	t.Cleanup(p.closeSession)
+	return p
+}
+
+// echo sends message over a new stream and checks that it comes back.
+func echo(p *PeerConnector, message string) error {
+	stream, err := p.openStream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	if _, err := stream.Write(append([]byte{modeEcho}, message...)); err != nil {
+		return err
+	}
+	reply := make([]byte, len(message))
+	if _, err := io.ReadFull(stream, reply); err != nil {
+		return err
+	}
+	if string(reply) != message {
+		return fmt.Errorf("expected %q, got %q", message, reply)
+	}
+	return nil
+}
+
+func TestOpenStream(t *testing.T) {
+	t.Run("multiplexes concurrent streams over one session", func(t *testing.T) {
+		host := newFakeHost(t)
+		p := host.peer(t)
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < cap(errs); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- echo(p, fmt.Sprintf("stream %d", i))
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, host.sessions())
+	})
+
+	t.Run("dials a new session after the host goes away", func(t *testing.T) {
+		host := newFakeHost(t)
+		p := host.peer(t)
+		require.NoError(t, echo(p, "before"))
+		host.drop()
+		// Whether or not the session has noticed the host is gone yet, the
+		// next stream must be opened over a new session.
+		require.Eventually(t, func() bool { return echo(p, "after") == nil }, 10*time.Second, 10*time.Millisecond)
+		assert.Equal(t, 2, host.sessions())
+	})
+
+	t.Run("a stalled stream does not block the others", func(t *testing.T) {
+		host := newFakeHost(t)
+		p := host.peer(t)
+		stalled, err := p.openStream()
+		require.NoError(t, err)
+		defer stalled.Close()
+
+		// Write more than a stream's flow control window, which cannot
+		// complete until the host reads from the stream.
+		payload := bytes.Repeat([]byte{'x'}, 4*int(muxConfig().MaxStreamWindowSize))
+		written := make(chan error, 1)
+		go func() {
+			_, err := stalled.Write(append([]byte{modeStall}, payload...))
+			written <- err
+		}()
+		select {
+		case err := <-written:
+			require.Failf(t, "write to a stalled stream completed", "error: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		assert.NoError(t, echo(p, "still flowing"))
+
+		close(host.release)
+		received := make(chan []byte, 1)
+		go func() {
+			data, _ := io.ReadAll(io.LimitReader(stalled, int64(len(payload))))
+			received <- data
+		}()
+		select {
+		case err := <-written:
+			assert.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			require.Fail(t, "write to the stream did not complete once the host read it")
+		}
+		assert.Equal(t, payload, <-received)
+	})
+}
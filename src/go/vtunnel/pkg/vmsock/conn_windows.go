@@ -14,6 +14,7 @@ limitations under the License.
 package vmsock
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -43,20 +44,27 @@ type HostConnector struct {
 
 // ListenAndDial listens for VSOCK connections from
 // the peer and dials into the provided TCP address to pipe
-// the payload.
-func (h *HostConnector) ListenAndDial() error {
+// the payload, until the context is cancelled.  Each VSOCK
+// connection carries a session multiplexing many streams.
+func (h *HostConnector) ListenAndDial(ctx context.Context) error {
 	vl, err := h.vsockListen()
 	if err != nil {
 		return err
 	}
+	defer vl.Close()
+	stop := context.AfterFunc(ctx, func() { vl.Close() })
+	defer stop()
 
 	for {
 		conn, err := vl.Accept()
 		if err != nil {
-			logrus.Errorf("ListenAndDial accept connection: %v")
+			if ctx.Err() != nil {
+				return nil
+			}
+			logrus.Errorf("ListenAndDial accept connection: %v", err)
 			continue
 		}
-		go h.handleConn(conn)
+		go serveSession(ctx, conn, h.handleConn)
 	}
 }
 
@@ -0,0 +1,59 @@
+/*
+Copyright © 2023 SUSE LLC
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmsock
+
+import (
+	"context"
+	"net"
+
+	"github.com/hashicorp/yamux"
+	"github.com/sirupsen/logrus"
+)
+
+// muxLog receives the messages of all sessions.
+var muxLog = logrus.StandardLogger().WriterLevel(logrus.DebugLevel)
+
+// muxConfig returns the configuration of the sessions that multiplex the
+// tunneled connections over a single, persistent AF_VSOCK connection, so that
+// each connection does not pay for setting up a new virtual socket.  Every
+// stream has its own flow control window, so a slow reader only stalls its
+// own stream, and keepalives detect a peer that has gone away.
+func muxConfig() *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.LogOutput = muxLog
+	return config
+}
+
+// serveSession accepts the streams the peer opens over a single connection,
+// and hands each of them to handle, until the session or the context ends.
+func serveSession(ctx context.Context, conn net.Conn, handle func(net.Conn)) {
+	session, err := yamux.Server(conn, muxConfig())
+	if err != nil {
+		logrus.Errorf("serveSession failed to start session: %v", err)
+		conn.Close()
+		return
+	}
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			logrus.Debugf("serveSession, session with peer ended: %v", err)
+			return
+		}
+		go handle(stream)
+	}
+}
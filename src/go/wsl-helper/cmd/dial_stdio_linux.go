@@ -0,0 +1,68 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var dialStdioViper = viper.New()
+
+// dialStdioCmd is the `wsl-helper dial-stdio` command; it relays standard
+// input and output to a unix socket, so that the host can reach the socket of
+// the container engine.
+var dialStdioCmd = &cobra.Command{
+	Use:   "dial-stdio",
+	Short: "Relay standard input and output to a unix socket",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath := dialStdioViper.GetString("socket")
+		conn, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", socketPath, err)
+		}
+		defer conn.Close()
+		cmd.SilenceUsage = true
+
+		go func() {
+			if _, err := io.Copy(conn, os.Stdin); err != nil {
+				logrus.WithError(err).Debug("failed to copy standard input")
+			}
+			// Let the other end know there is nothing more to read.
+			_ = conn.(*net.UnixConn).CloseWrite()
+		}()
+		// Exit once the socket side is done; a closed standard input only
+		// half-closes the connection.
+		if _, err := io.Copy(os.Stdout, conn); err != nil {
+			return fmt.Errorf("failed to copy from %s: %w", socketPath, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dialStdioCmd.Flags().String("socket", "/var/run/docker.sock", "Path to the unix socket to connect to")
+	dialStdioViper.AutomaticEnv()
+	dialStdioViper.BindPFlags(dialStdioCmd.Flags())
+	rootCmd.AddCommand(dialStdioCmd)
+}
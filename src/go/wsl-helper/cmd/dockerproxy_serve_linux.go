@@ -48,7 +48,7 @@ var dockerproxyServeCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		err = dockerproxy.Serve(endpoint, dialer)
+		err = dockerproxy.Serve(endpoint, dialer, "")
 		if err != nil {
 			return err
 		}
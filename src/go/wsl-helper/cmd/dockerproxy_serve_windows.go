@@ -38,11 +38,12 @@ var dockerproxyServeCmd = &cobra.Command{
 		cmd.SilenceErrors = true
 		endpoint := dockerproxyServeViper.GetString("endpoint")
 		port := dockerproxyServeViper.GetUint32("port")
+		policy := dockerproxyServeViper.GetString("policy")
 		dialer, err := platform.MakeDialer(port)
 		if err != nil {
 			return err
 		}
-		err = dockerproxy.Serve(endpoint, dialer)
+		err = dockerproxy.Serve(endpoint, dialer, policy)
 		if err != nil {
 			return err
 		}
@@ -53,6 +54,7 @@ var dockerproxyServeCmd = &cobra.Command{
 func init() {
 	dockerproxyServeCmd.Flags().String("endpoint", platform.DefaultEndpoint, "Endpoint to listen on")
 	dockerproxyServeCmd.Flags().Uint32("port", dockerproxy.DefaultPort, "Vsock port docker is listening on")
+	dockerproxyServeCmd.Flags().String("policy", "", "Policy restricting what clients may do, in JSON")
 	dockerproxyServeViper.AutomaticEnv()
 	dockerproxyServeViper.BindPFlags(dockerproxyServeCmd.Flags())
 	dockerproxyCmd.AddCommand(dockerproxyServeCmd)
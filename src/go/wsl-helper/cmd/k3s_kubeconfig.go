@@ -27,29 +27,32 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 type kubeConfig struct {
-	Clusters []struct {
-		Cluster struct {
-			Server string
-			Extras map[string]interface{} `yaml:",inline"`
-		} `yaml:"cluster"`
-		Name   string                 `yaml:"name"`
-		Extras map[string]interface{} `yaml:",inline"`
-	} `yaml:"clusters"`
-	Contexts []struct {
-		Name   string                 `yaml:"name"`
-		Extras map[string]interface{} `yaml:",inline"`
-	} `yaml:"contexts"`
-	CurrentContext string `yaml:"current-context"`
-	Users          []struct {
-		Name   string                 `yaml:"name"`
+	Clusters       []kubeCluster          `yaml:"clusters"`
+	Contexts       []kubeNamedEntry       `yaml:"contexts"`
+	CurrentContext string                 `yaml:"current-context"`
+	Users          []kubeNamedEntry       `yaml:"users"`
+	Extras         map[string]interface{} `yaml:",inline"`
+}
+
+type kubeCluster struct {
+	Cluster struct {
+		Server string
 		Extras map[string]interface{} `yaml:",inline"`
-	} `yaml:"users"`
+	} `yaml:"cluster"`
+	Name   string                 `yaml:"name"`
+	Extras map[string]interface{} `yaml:",inline"`
+}
+
+// kubeNamedEntry is a context or user in a kubeconfig.
+type kubeNamedEntry struct {
+	Name   string                 `yaml:"name"`
 	Extras map[string]interface{} `yaml:",inline"`
 }
 
@@ -145,7 +148,7 @@ func getClusterIP() (net.IP, error) {
 			return nil, err
 		}
 		iface = &ifaces[0]
-		fmt.Fprintf(os.Stderr, "Could not find eth0, using fallback interface %s\n", iface.Name)
+		logrus.Warnf("Could not find eth0, using fallback interface %s", iface.Name)
 	}
 	addrs, err := iface.Addrs()
 	if err != nil {
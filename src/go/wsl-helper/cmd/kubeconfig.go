@@ -20,13 +20,20 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -37,6 +44,26 @@ var kubeconfigViper = viper.New()
 
 const rdCluster = "rancher-desktop"
 
+// Ways the kubeconfig command can provide the Rancher Desktop configuration.
+const (
+	// kubeconfigModeMerge merges the configuration into ~/.kube/config,
+	// keeping the other clusters, contexts and users.
+	kubeconfigModeMerge = "merge"
+	// kubeconfigModeStandalone writes the configuration to its own file,
+	// leaving ~/.kube/config alone.
+	kubeconfigModeStandalone = "standalone"
+	// kubeconfigModeFragment prints the configuration, so that it can be
+	// saved somewhere and added to $KUBECONFIG.
+	kubeconfigModeFragment = "fragment"
+)
+
+var kubeconfigSettings struct {
+	mode     string
+	output   string
+	watch    bool
+	interval time.Duration
+}
+
 // kubeconfigCmd represents the kubeconfig command, used to set up kubeconfig
 // in WSL distributions (running on the Linux side).  Note that we must
 // pass the kubeconfig path in as an environment variable to take advantage of
@@ -44,13 +71,38 @@ const rdCluster = "rancher-desktop"
 var kubeconfigCmd = &cobra.Command{
 	Use:   "kubeconfig",
 	Short: "Set up ~/.kube/config in the WSL2 environment",
-	Long:  `This command configures the Kubernetes configuration inside a WSL2 distribution.`,
-	Args:  cobra.ExactArgs(0),
+	Long: `This command configures the Kubernetes configuration inside a WSL2 distribution.
+
+By default, the Rancher Desktop cluster, context and user are merged into
+~/.kube/config, replacing any previous Rancher Desktop entries in place and
+keeping everything else.  With --mode=standalone they are written to a file of
+their own instead (see --output), and with --mode=fragment they are printed,
+so that they can be added to $KUBECONFIG.
+
+With --watch, the command keeps running and updates the configuration whenever
+the Windows kubeconfig or the cluster address changes (for example, when the
+cluster restarts on a different port), so that it never goes stale.`,
+	Args: cobra.ExactArgs(0),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		winConfigPath := kubeconfigViper.GetString("kubeconfig")
 		linuxConfigDir := filepath.Join(homedir.HomeDir(), ".kube")
 		linuxConfigPath := filepath.Join(linuxConfigDir, "config")
 		enable := kubeconfigViper.GetBool("enable")
+		mode := kubeconfigSettings.mode
+
+		switch mode {
+		case kubeconfigModeMerge, kubeconfigModeStandalone, kubeconfigModeFragment:
+		default:
+			return fmt.Errorf("invalid mode %q: must be one of %s, %s or %s",
+				mode, kubeconfigModeMerge, kubeconfigModeStandalone, kubeconfigModeFragment)
+		}
+		if kubeconfigSettings.watch && kubeconfigSettings.interval <= 0 {
+			return fmt.Errorf("invalid --interval %s: must be positive", kubeconfigSettings.interval)
+		}
+		standalonePath := kubeconfigSettings.output
+		if standalonePath == "" {
+			standalonePath = filepath.Join(linuxConfigDir, "rancher-desktop.yaml")
+		}
 
 		if winConfigPath == "" {
 			//lint:ignore ST1005 The capitalization is for a proper noun.
@@ -71,43 +123,103 @@ var kubeconfigCmd = &cobra.Command{
 		}
 
 		if !enable {
+			if mode == kubeconfigModeStandalone {
+				if err := os.Remove(standalonePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to remove %s: %w", standalonePath, err)
+				}
+			}
 			return nil
 		}
 
 		cmd.SilenceUsage = true
 
-		winConfig, err := readKubeConfig(winConfigPath)
-		if err != nil {
-			return err
+		if !kubeconfigSettings.watch {
+			return syncKubeConfig(winConfigPath, linuxConfigPath, standalonePath, mode, cmd.OutOrStdout())
 		}
-
-		linuxConfig, err := readKubeConfig(linuxConfigPath)
-		if err != nil && !errors.Is(err, os.ErrNotExist) {
-			return err
+		if mode == kubeconfigModeFragment {
+			return fmt.Errorf("--watch can't be used with --mode=%s", kubeconfigModeFragment)
 		}
 
-		cleanConfig := removeExistingRDConfig(rdCluster, &linuxConfig)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		watchKubeConfig(ctx, kubeconfigSettings.interval, func() error {
+			return syncKubeConfig(winConfigPath, linuxConfigPath, standalonePath, mode, cmd.OutOrStdout())
+		})
+		return nil
+	},
+}
 
-		kubeConfig, err := updateKubeConfig(winConfig, *cleanConfig, rdNetworking)
-		if err != nil {
-			return fmt.Errorf("failed to construct kubeconfig: %w", err)
+// watchKubeConfig calls sync right away, and then every interval until the
+// context is done.  The Windows kubeconfig is on a drvfs mount, which doesn't
+// support inotify, so it (and the cluster address) must be polled.
+func watchKubeConfig(ctx context.Context, interval time.Duration, sync func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sync(); err != nil {
+			// The Windows kubeconfig may be missing while the cluster restarts.
+			logrus.WithError(err).Debug("failed to update kubeconfig")
 		}
-
-		var finalKubeConfigFile *os.File
-		if err := os.MkdirAll(linuxConfigDir, 0o750); err != nil {
-			return err
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		finalKubeConfigFile, err = os.Create(linuxConfigPath)
+	}
+}
+
+// syncKubeConfig updates the Linux kubeconfig (according to the mode) from
+// the Windows one.
+func syncKubeConfig(winConfigPath, linuxConfigPath, standalonePath, mode string, out io.Writer) error {
+	winConfig, err := readKubeConfig(winConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if mode != kubeconfigModeMerge {
+		kubeConfig, err := updateKubeConfig(winConfig, newKubeConfig(), rdNetworking)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to construct kubeconfig: %w", err)
 		}
-		defer finalKubeConfigFile.Close()
-		err = yaml.NewEncoder(finalKubeConfigFile).Encode(kubeConfig)
-		if err != nil {
-			return err
+		kubeConfig.CurrentContext = rdCluster
+		if mode == kubeconfigModeFragment {
+			return yaml.NewEncoder(out).Encode(kubeConfig)
 		}
+		return writeKubeConfig(standalonePath, kubeConfig)
+	}
+
+	linuxConfig, err := readKubeConfig(linuxConfigPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	kubeConfig, err := updateKubeConfig(winConfig, linuxConfig, rdNetworking)
+	if err != nil {
+		return fmt.Errorf("failed to construct kubeconfig: %w", err)
+	}
+	return writeKubeConfig(linuxConfigPath, kubeConfig)
+}
+
+// newKubeConfig returns an empty kubeconfig.
+func newKubeConfig() kubeConfig {
+	return kubeConfig{Extras: map[string]interface{}{"apiVersion": "v1", "kind": "Config"}}
+}
+
+// writeKubeConfig writes the kubeconfig, creating its directory if needed;
+// the file is left alone if it already has the same contents.  The file holds
+// credentials, so it is only readable by the user.
+func writeKubeConfig(configPath string, config kubeConfig) error {
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(config); err != nil {
+		return err
+	}
+	if existing, err := os.ReadFile(configPath); err == nil && bytes.Equal(existing, buf.Bytes()) {
 		return nil
-	},
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, buf.Bytes(), 0o600)
 }
 
 func readKubeConfig(configPath string) (kubeConfig, error) {
@@ -158,75 +270,94 @@ func updateKubeConfig(winConfig, linuxConfig kubeConfig, rdNetworking bool) (kub
 	return mergeKubeConfigs(winConfig, linuxConfig), nil
 }
 
-func removeExistingRDConfig(name string, config *kubeConfig) *kubeConfig {
-	// Remove clusters with the specified name
-	var filteredClusters []struct {
-		Cluster struct {
-			Server string
-			Extras map[string]interface{} `yaml:",inline"`
-		} `yaml:"cluster"`
-		Name   string                 `yaml:"name"`
-		Extras map[string]interface{} `yaml:",inline"`
-	}
-	for _, cluster := range config.Clusters {
-		if cluster.Name != name {
-			filteredClusters = append(filteredClusters, cluster)
+// mergeKubeConfigs copies the Rancher Desktop cluster, context and user from
+// the Windows config into the Linux one.  Existing Rancher Desktop entries are
+// replaced in place (keeping the namespace the user chose for the context), and
+// all other entries are left alone.
+func mergeKubeConfigs(winConfig, linuxConfig kubeConfig) kubeConfig {
+	for _, cluster := range winConfig.Clusters {
+		if cluster.Name == rdCluster {
+			linuxConfig.Clusters = replaceNamed(linuxConfig.Clusters, cluster, func(c kubeCluster) string { return c.Name })
 		}
 	}
-	config.Clusters = filteredClusters
-
-	// Remove contexts with the specified name
-	var filteredContexts []struct {
-		Name   string                 `yaml:"name"`
-		Extras map[string]interface{} `yaml:",inline"`
-	}
-	for _, context := range config.Contexts {
-		if context.Name != name {
-			filteredContexts = append(filteredContexts, context)
+	for _, context := range winConfig.Contexts {
+		if context.Name != rdCluster {
+			continue
 		}
+		for _, existing := range linuxConfig.Contexts {
+			if existing.Name == rdCluster {
+				context.Extras = keepNamespace(existing.Extras, context.Extras)
+				break
+			}
+		}
+		linuxConfig.Contexts = replaceNamed(linuxConfig.Contexts, context, func(c kubeNamedEntry) string { return c.Name })
 	}
-	config.Contexts = filteredContexts
-
-	// Remove users with the specified name
-	var filteredUsers []struct {
-		Name   string                 `yaml:"name"`
-		Extras map[string]interface{} `yaml:",inline"`
-	}
-	for _, user := range config.Users {
-		if user.Name != name {
-			filteredUsers = append(filteredUsers, user)
+	for _, user := range winConfig.Users {
+		if user.Name == rdCluster {
+			linuxConfig.Users = replaceNamed(linuxConfig.Users, user, func(u kubeNamedEntry) string { return u.Name })
 		}
 	}
-	config.Users = filteredUsers
+	if linuxConfig.Extras == nil {
+		linuxConfig.Extras = newKubeConfig().Extras
+	}
 
-	return config
+	return linuxConfig
 }
 
-func mergeKubeConfigs(winConfig, linuxConfig kubeConfig) kubeConfig {
-	for _, ctx := range winConfig.Clusters {
-		if ctx.Name == rdCluster {
-			linuxConfig.Clusters = append(linuxConfig.Clusters, ctx)
+// replaceNamed replaces the first entry with the same name as the given one,
+// or appends it if there is none; any other entries with that name are
+// dropped.
+func replaceNamed[T any](entries []T, entry T, name func(T) string) []T {
+	var result []T
+	replaced := false
+	for _, existing := range entries {
+		if name(existing) != name(entry) {
+			result = append(result, existing)
+		} else if !replaced {
+			result = append(result, entry)
+			replaced = true
 		}
 	}
-	for _, ctx := range winConfig.Contexts {
-		if ctx.Name == rdCluster {
-			linuxConfig.Contexts = append(linuxConfig.Contexts, ctx)
-		}
+	if !replaced {
+		result = append(result, entry)
 	}
+	return result
+}
 
-	for _, user := range winConfig.Users {
-		if user.Name == rdCluster {
-			linuxConfig.Users = append(linuxConfig.Users, user)
-		}
+// keepNamespace returns the new context details, using the namespace from the
+// existing ones if the new ones don't set one.
+func keepNamespace(existing, updated map[string]interface{}) map[string]interface{} {
+	existingContext, _ := existing["context"].(map[string]interface{})
+	updatedContext, _ := updated["context"].(map[string]interface{})
+	namespace, ok := existingContext["namespace"]
+	if !ok || updatedContext == nil {
+		return updated
 	}
-
-	return linuxConfig
+	if _, ok := updatedContext["namespace"]; ok {
+		return updated
+	}
+	result := make(map[string]interface{}, len(updated))
+	for k, v := range updated {
+		result[k] = v
+	}
+	context := make(map[string]interface{}, len(updatedContext)+1)
+	for k, v := range updatedContext {
+		context[k] = v
+	}
+	context["namespace"] = namespace
+	result["context"] = context
+	return result
 }
 
 func init() {
 	kubeconfigCmd.PersistentFlags().Bool("enable", true, "Set up config file")
 	kubeconfigCmd.PersistentFlags().String("kubeconfig", "", "Path to Windows kubeconfig, in /mnt/... form.")
 	kubeconfigCmd.Flags().BoolVar(&rdNetworking, "rd-networking", false, "Enable the experimental Rancher Desktop Networking")
+	kubeconfigCmd.Flags().StringVar(&kubeconfigSettings.mode, "mode", kubeconfigModeMerge,
+		fmt.Sprintf("How to provide the config: %s, %s or %s", kubeconfigModeMerge, kubeconfigModeStandalone, kubeconfigModeFragment))
+	kubeconfigCmd.Flags().StringVar(&kubeconfigSettings.output, "output", "", "File to write in standalone mode (default ~/.kube/rancher-desktop.yaml)")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigSettings.watch, "watch", false, "Keep running, updating the config when the cluster changes")
+	kubeconfigCmd.Flags().DurationVar(&kubeconfigSettings.interval, "interval", 5*time.Second, "How often to check for changes with --watch")
 	kubeconfigViper.AutomaticEnv()
 	kubeconfigViper.BindPFlags(kubeconfigCmd.PersistentFlags())
 	rootCmd.AddCommand(kubeconfigCmd)
@@ -0,0 +1,142 @@
+//go:build linux
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWindowsKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: rancher-desktop
+  cluster:
+    server: https://127.0.0.1:%s
+contexts:
+- name: rancher-desktop
+  context:
+    cluster: rancher-desktop
+    user: rancher-desktop
+users:
+- name: rancher-desktop
+  user:
+    token: secret
+`
+
+func writeWindowsKubeConfig(t *testing.T, path, port string) {
+	t.Helper()
+	contents := []byte(fmt.Sprintf(testWindowsKubeConfig, port))
+	require.NoError(t, os.WriteFile(path, contents, 0o644))
+}
+
+func TestKubeConfigRejectsInvalidInterval(t *testing.T) {
+	saved := kubeconfigSettings
+	t.Cleanup(func() { kubeconfigSettings = saved })
+	kubeconfigSettings.mode = kubeconfigModeMerge
+	kubeconfigSettings.watch = true
+	for _, interval := range []time.Duration{0, -time.Second} {
+		kubeconfigSettings.interval = interval
+		err := kubeconfigCmd.RunE(kubeconfigCmd, nil)
+		assert.ErrorContains(t, err, "invalid --interval", interval)
+	}
+}
+
+func TestWatchKubeConfig(t *testing.T) {
+	t.Run("syncs until the context is done, despite errors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		calls := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watchKubeConfig(ctx, time.Millisecond, func() error {
+				calls++
+				if calls == 3 {
+					cancel()
+				}
+				return errors.New("the Windows kubeconfig is missing")
+			})
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("watchKubeConfig did not return after the context was canceled")
+		}
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("picks up changes to the Windows kubeconfig", func(t *testing.T) {
+		savedNetworking := rdNetworking
+		rdNetworking = true
+		t.Cleanup(func() { rdNetworking = savedNetworking })
+		dir := t.TempDir()
+		winConfigPath := filepath.Join(dir, "windows-config")
+		standalonePath := filepath.Join(dir, "rancher-desktop.yaml")
+		writeWindowsKubeConfig(t, winConfigPath, "6443")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watchKubeConfig(ctx, time.Millisecond, func() error {
+				return syncKubeConfig(winConfigPath, "", standalonePath, kubeconfigModeStandalone, io.Discard)
+			})
+		}()
+
+		serverIs := func(port string) func() bool {
+			return func() bool {
+				config, err := readKubeConfig(standalonePath)
+				return err == nil && len(config.Clusters) == 1 &&
+					config.Clusters[0].Cluster.Server == "https://gateway.rancher-desktop.internal:"+port
+			}
+		}
+		assert.Eventually(t, serverIs("6443"), 10*time.Second, time.Millisecond)
+		writeWindowsKubeConfig(t, winConfigPath, "6444")
+		assert.Eventually(t, serverIs("6444"), 10*time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func TestWriteKubeConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".kube", "config")
+	config := newKubeConfig()
+	config.CurrentContext = rdCluster
+	require.NoError(t, writeKubeConfig(configPath, config))
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Make any rewrite show up in the modification time.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(configPath, past, past))
+
+	t.Run("leaves an unchanged file alone", func(t *testing.T) {
+		require.NoError(t, writeKubeConfig(configPath, config))
+		info, err := os.Stat(configPath)
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(past), "modified at %s", info.ModTime())
+	})
+
+	t.Run("rewrites a changed file", func(t *testing.T) {
+		config.CurrentContext = "other"
+		require.NoError(t, writeKubeConfig(configPath, config))
+		info, err := os.Stat(configPath)
+		require.NoError(t, err)
+		assert.False(t, info.ModTime().Equal(past))
+		written, err := readKubeConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "other", written.CurrentContext)
+	})
+}
@@ -18,25 +18,52 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/fips"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/profiling"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// profilingCloser stops serving profiles when the command is done.
+var profilingCloser io.Closer
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "wsl-helper",
 	Short: "Rancher Desktop WSL2 integration helper",
 	Long:  `This command handles various WSL2 integration tasks for Rancher Desktop.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		logrus.SetLevel(logrus.InfoLevel + logrus.Level(viper.GetInt("verbose")))
+		// Without --verbose, keep the level from the environment.
+		if verbose := viper.GetInt("verbose"); verbose > 0 {
+			logrus.SetLevel(logrus.InfoLevel + logrus.Level(verbose))
+		}
+		// Profiling is only for diagnosis; don't fail the command over it.
+		closer, err := profiling.Start(cmd.CommandPath())
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to serve profiles")
+		} else {
+			profilingCloser = closer
+		}
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	logCloser := logging.Init("wsl-helper")
+	if err := fips.Verify(); err != nil {
+		logrus.Fatal(err)
+	}
+	err := rootCmd.Execute()
+	if profilingCloser != nil {
+		_ = profilingCloser.Close()
+	}
+	_ = logCloser.Close()
+	cobra.CheckErr(err)
 }
 
 func init() {
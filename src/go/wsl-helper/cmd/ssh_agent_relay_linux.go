@@ -0,0 +1,119 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var sshAgentRelayViper = viper.New()
+
+// sshAgentRelayAcceptTimeout is how long to wait for the host to connect back
+// for each agent connection.
+const sshAgentRelayAcceptTimeout = 30 * time.Second
+
+// sshAgentRelayCmd is the `wsl-helper ssh-agent-relay` command; it listens on
+// a unix socket for SSH agent connections, so that BuildKit can use the SSH
+// agent of the Windows host (which is a named pipe, that can't be reached from
+// the VM).  For each connection, a one-off socket path is written to standard
+// output; the host is expected to connect its agent to it (with `dial-stdio`).
+// The relay exits once standard input is closed.
+var sshAgentRelayCmd = &cobra.Command{
+	Use:   "ssh-agent-relay",
+	Short: "Relay SSH agent connections to the host",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath := sshAgentRelayViper.GetString("socket")
+		_ = os.Remove(socketPath)
+		listener, err := net.Listen("unix", socketPath)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+		}
+		defer os.Remove(socketPath)
+		cmd.SilenceUsage = true
+
+		var stdoutMutex sync.Mutex
+		// Let the host know we're ready to accept connections.
+		fmt.Fprintln(os.Stdout, socketPath)
+		go func() {
+			_, _ = io.Copy(io.Discard, os.Stdin)
+			listener.Close()
+		}()
+		for id := 0; ; id++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				// The listener is closed once the host is done.
+				return nil
+			}
+			go func(conn net.Conn, hostSocketPath string) {
+				defer conn.Close()
+				if err := relaySSHAgentConnection(conn, hostSocketPath, func() {
+					stdoutMutex.Lock()
+					defer stdoutMutex.Unlock()
+					fmt.Fprintln(os.Stdout, hostSocketPath)
+				}); err != nil {
+					logrus.WithError(err).Error("failed to relay SSH agent connection")
+				}
+			}(conn, fmt.Sprintf("%s.%d", socketPath, id))
+		}
+	},
+}
+
+// relaySSHAgentConnection listens on hostSocketPath, calls notify so that the
+// host connects to it, and then relays between the connections.
+func relaySSHAgentConnection(conn net.Conn, hostSocketPath string, notify func()) error {
+	listener, err := net.Listen("unix", hostSocketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", hostSocketPath, err)
+	}
+	defer os.Remove(hostSocketPath)
+	defer listener.Close()
+	_ = listener.(*net.UnixListener).SetDeadline(time.Now().Add(sshAgentRelayAcceptTimeout))
+	notify()
+	hostConn, err := listener.Accept()
+	if err != nil {
+		return fmt.Errorf("host did not connect to %s: %w", hostSocketPath, err)
+	}
+	defer hostConn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(hostConn, conn)
+		_ = hostConn.(*net.UnixConn).CloseWrite()
+		close(done)
+	}()
+	_, _ = io.Copy(conn, hostConn)
+	_ = conn.(*net.UnixConn).CloseWrite()
+	<-done
+	return nil
+}
+
+func init() {
+	sshAgentRelayCmd.Flags().String("socket", "", "Path to the unix socket to listen on")
+	_ = sshAgentRelayCmd.MarkFlagRequired("socket")
+	sshAgentRelayViper.AutomaticEnv()
+	sshAgentRelayViper.BindPFlags(sshAgentRelayCmd.Flags())
+	rootCmd.AddCommand(sshAgentRelayCmd)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/gpu"
+)
+
+var wslGPUViper = viper.New()
+
+// wslGPUCmd represents the `wsl gpu` command
+var wslGPUCmd = &cobra.Command{
+	Use:   "gpu",
+	Short: "Detect and configure GPU support",
+	Long: `Detect the GPU support WSL provides, printing it as JSON (--mode=detect), or
+make the GPU available to containers through a CDI spec (--mode=enable) or
+stop doing so (--mode=disable).  Changing the configuration must be done as
+root, and takes effect once the container engine is restarted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		cdiDir := wslGPUViper.GetString("cdi-dir")
+		switch mode := cmd.Flags().Lookup("mode").Value.String(); mode {
+		case "detect":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(gpu.Detect())
+		case "enable":
+			return gpu.Enable(cdiDir, wslGPUViper.GetString("docker-config"))
+		case "disable":
+			return gpu.Disable(cdiDir)
+		default:
+			return fmt.Errorf("unknown operation %q", mode)
+		}
+	},
+}
+
+func init() {
+	wslGPUCmd.Flags().Var(&enumValue{val: "detect", allowed: []string{"detect", "enable", "disable"}}, "mode", "Operation mode")
+	wslGPUCmd.Flags().String("cdi-dir", "/etc/cdi", "Directory for CDI specs")
+	wslGPUCmd.Flags().String("docker-config", "/etc/docker/daemon.json", "Docker daemon configuration to enable CDI in; empty to skip")
+	wslGPUViper.AutomaticEnv()
+	wslGPUViper.BindPFlags(wslGPUCmd.Flags())
+	wslCmd.AddCommand(wslGPUCmd)
+}
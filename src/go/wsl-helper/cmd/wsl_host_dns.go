@@ -0,0 +1,56 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dns"
+)
+
+var wslHostDNSViper = viper.New()
+
+// wslHostDNSCmd represents the `wsl host-dns` command.
+var wslHostDNSCmd = &cobra.Command{
+	Use:   "host-dns",
+	Short: "List the DNS servers configured on the host",
+	Long: `List the DNS servers configured on the host's network adapters, as a JSON
+array.  With --exclude-vpn, the servers of adapters that appear to belong to a
+VPN client are left out.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		servers, err := dns.HostServers(wslHostDNSViper.GetBool("exclude-vpn"))
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(os.Stdout).Encode(servers)
+	},
+}
+
+func init() {
+	wslHostDNSCmd.Flags().Bool("exclude-vpn", false, "Skip the DNS servers of VPN adapters")
+	wslHostDNSViper.AutomaticEnv()
+	wslHostDNSViper.BindPFlags(wslHostDNSCmd.Flags())
+	wslCmd.AddCommand(wslHostDNSCmd)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+var wslIntegrationCertificatesViper = viper.New()
+
+// wslIntegrationCertificatesCmd represents the `wsl integration certificates` command
+var wslIntegrationCertificatesCmd = &cobra.Command{
+	Use:   "certificates",
+	Short: "Install the host's CA certificates into the WSL distribution",
+	Long: `Install the CA certificates trusted by the host into the system trust store of
+the WSL distribution, replacing the ones installed previously; with
+--state=false, remove them instead.  This must be run as root.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		state := wslIntegrationCertificatesViper.GetBool("state")
+		bundlePath := wslIntegrationCertificatesViper.GetString("certificates")
+
+		store, err := integration.FindTrustStore()
+		if err != nil {
+			if !state {
+				// Nothing can have been installed.
+				return nil
+			}
+			return err
+		}
+
+		var changed bool
+		if state {
+			if bundlePath == "" {
+				return errors.New("the certificates to install were not supplied")
+			}
+			bundle, err := os.ReadFile(bundlePath)
+			if err != nil {
+				return fmt.Errorf("failed to read certificates: %w", err)
+			}
+			changed, err = store.Sync(bundle)
+			if err != nil {
+				return err
+			}
+		} else if changed, err = store.Remove(); err != nil {
+			return err
+		}
+
+		if !changed {
+			logrus.Debugf("CA certificates in %s are up to date", store.Dir)
+			return nil
+		}
+		return store.UpdateSystem()
+	},
+}
+
+func init() {
+	wslIntegrationCertificatesCmd.Flags().String("certificates", "", "Path to the certificates to install, in PEM format")
+	wslIntegrationCertificatesCmd.Flags().Bool("state", false, "Desired state")
+	wslIntegrationCertificatesViper.AutomaticEnv()
+	wslIntegrationCertificatesViper.BindPFlags(wslIntegrationCertificatesCmd.Flags())
+	wslIntegrationCmd.AddCommand(wslIntegrationCertificatesCmd)
+}
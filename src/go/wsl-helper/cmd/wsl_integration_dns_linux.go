@@ -0,0 +1,89 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dns"
+)
+
+var wslIntegrationDNSViper = viper.New()
+
+// wslIntegrationDNSCmd represents the `wsl integration dns` command
+var wslIntegrationDNSCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Check and repair name resolution in the WSL distribution",
+	Long: `Check whether the name servers in /etc/resolv.conf can resolve names.  With
+--mode=repair, pin /etc/resolv.conf to the given name servers (falling back to
+the host resolver) that work, and stop WSL from regenerating it; --mode=restore
+undoes this.  In all modes, the resulting state is printed as JSON.  Modifying
+the configuration must be done as root.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ctx := cmd.Context()
+		probe := wslIntegrationDNSViper.GetString("probe")
+		timeout := wslIntegrationDNSViper.GetDuration("timeout")
+
+		switch mode := cmd.Flags().Lookup("mode").Value.String(); mode {
+		case "check":
+		case "repair":
+			candidates := wslIntegrationDNSViper.GetStringSlice("nameserver")
+			if hostResolver, err := dns.HostResolver(); err != nil {
+				logrus.WithError(err).Debug("Could not find the host resolver")
+			} else {
+				candidates = append(candidates, hostResolver)
+			}
+			nameservers, err := dns.Repair(ctx, candidates, probe, timeout)
+			if err != nil {
+				return err
+			}
+			logrus.Debugf("Pinned name servers to %v", nameservers)
+		case "restore":
+			if err := dns.Restore(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown operation %q", mode)
+		}
+
+		report, err := dns.Check(ctx, probe, timeout)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		return encoder.Encode(report)
+	},
+}
+
+func init() {
+	wslIntegrationDNSCmd.Flags().Var(&enumValue{val: "check", allowed: []string{"check", "repair", "restore"}}, "mode", "Operation mode")
+	wslIntegrationDNSCmd.Flags().StringSlice("nameserver", nil, "Name servers to try when repairing, before the host resolver")
+	wslIntegrationDNSCmd.Flags().String("probe", "rancherdesktop.io", "Name to resolve to check that a name server works")
+	wslIntegrationDNSCmd.Flags().Duration("timeout", 3*time.Second, "How long to wait for each name server")
+	wslIntegrationDNSViper.AutomaticEnv()
+	wslIntegrationDNSViper.BindPFlags(wslIntegrationDNSCmd.Flags())
+	wslIntegrationCmd.AddCommand(wslIntegrationDNSCmd)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+var wslIntegrationDockerConfigViper = viper.New()
+
+// wslIntegrationDockerConfigCmd represents the `wsl integration docker-config` command
+var wslIntegrationDockerConfigCmd = &cobra.Command{
+	Use:   "docker-config",
+	Short: "Manage the docker CLI configuration in the WSL distribution",
+	Long: `Set the credential helper and proxies in the docker CLI configuration
+(config.json), so that the CLI in the distribution matches Rancher Desktop.
+Values the user has set are left alone; with --state=false, the values set
+previously are removed instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		configDir, err := dockerConfigDir()
+		if err != nil {
+			return err
+		}
+		config := integration.DockerClientConfig{
+			CredsStore: wslIntegrationDockerConfigViper.GetString("credential-helper"),
+			HTTPProxy:  wslIntegrationDockerConfigViper.GetString("http-proxy"),
+			HTTPSProxy: wslIntegrationDockerConfigViper.GetString("https-proxy"),
+			NoProxy:    wslIntegrationDockerConfigViper.GetString("no-proxy"),
+		}
+
+		return integration.DockerConfig(configDir, config, wslIntegrationDockerConfigViper.GetBool("state"))
+	},
+}
+
+func init() {
+	wslIntegrationDockerConfigCmd.Flags().String("credential-helper", "", "Docker credential helper to use (credsStore)")
+	wslIntegrationDockerConfigCmd.Flags().String("http-proxy", "", "HTTP proxy for containers")
+	wslIntegrationDockerConfigCmd.Flags().String("https-proxy", "", "HTTPS proxy for containers")
+	wslIntegrationDockerConfigCmd.Flags().String("no-proxy", "", "Comma-separated hosts that bypass the proxy")
+	wslIntegrationDockerConfigCmd.Flags().Bool("state", false, "Desired state")
+	wslIntegrationDockerConfigViper.AutomaticEnv()
+	wslIntegrationDockerConfigViper.BindPFlags(wslIntegrationDockerConfigCmd.Flags())
+	wslIntegrationCmd.AddCommand(wslIntegrationDockerConfigCmd)
+}
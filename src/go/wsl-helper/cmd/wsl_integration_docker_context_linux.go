@@ -0,0 +1,79 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dockerproxy"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+var wslIntegrationDockerContextViper = viper.New()
+
+// wslIntegrationDockerContextCmd represents the `wsl integration docker-context` command
+var wslIntegrationDockerContextCmd = &cobra.Command{
+	Use:   "docker-context",
+	Short: "Manage the Rancher Desktop docker context in the WSL distribution",
+	Long: `Create a named docker context pointing at the Rancher Desktop docker socket,
+without making it the current context; with --state=false, remove it instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		state := wslIntegrationDockerContextViper.GetBool("state")
+		name := wslIntegrationDockerContextViper.GetString("name")
+		socketPath := wslIntegrationDockerContextViper.GetString("socket")
+
+		configDir, err := dockerConfigDir()
+		if err != nil {
+			return err
+		}
+		if state && socketPath == "" {
+			if socketPath, err = dockerproxy.GetDefaultProxyEndpoint(); err != nil {
+				return err
+			}
+		}
+
+		return integration.DockerContext(configDir, name, socketPath, state)
+	},
+}
+
+// dockerConfigDir returns the docker CLI configuration directory.
+func dockerConfigDir() (string, error) {
+	if configDir := os.Getenv("DOCKER_CONFIG"); configDir != "" {
+		return configDir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".docker"), nil
+}
+
+func init() {
+	wslIntegrationDockerContextCmd.Flags().String("name", "rancher-desktop", "Name of the docker context")
+	wslIntegrationDockerContextCmd.Flags().String("socket", "", "Path to the docker socket (default: the Rancher Desktop socket in /mnt/wsl)")
+	wslIntegrationDockerContextCmd.Flags().Bool("state", false, "Desired state")
+	wslIntegrationDockerContextViper.AutomaticEnv()
+	wslIntegrationDockerContextViper.BindPFlags(wslIntegrationDockerContextCmd.Flags())
+	wslIntegrationCmd.AddCommand(wslIntegrationDockerContextCmd)
+}
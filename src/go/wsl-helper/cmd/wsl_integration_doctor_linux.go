@@ -0,0 +1,79 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dockerproxy"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+var wslIntegrationDoctorViper = viper.New()
+
+// wslIntegrationDoctorCmd represents the `wsl integration doctor` command
+var wslIntegrationDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the Rancher Desktop integration of the WSL distribution",
+	Long: `Check the Rancher Desktop integration of the WSL distribution: the
+integration marker, the interop settings in /etc/wsl.conf, the docker CLI
+plugin links, and (with --docker) the docker socket and CLI.  With
+--mode=repair, the problems that can be fixed from within the distribution are
+fixed first.  The remaining problems are printed as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("could not get home directory: %w", err)
+		}
+		opts := integration.DiagnoseOptions{
+			HomeDir: homeDir,
+			PathEnv: os.Getenv("PATH"),
+		}
+		if wslIntegrationDoctorViper.GetBool("docker") {
+			if opts.DockerSocket, err = dockerproxy.GetDefaultProxyEndpoint(); err != nil {
+				return err
+			}
+		}
+
+		switch mode := cmd.Flags().Lookup("mode").Value.String(); mode {
+		case "check":
+		case "repair":
+			if err := integration.Repair(opts); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown operation %q", mode)
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(integration.Diagnose(opts))
+	},
+}
+
+func init() {
+	wslIntegrationDoctorCmd.Flags().Var(&enumValue{val: "check", allowed: []string{"check", "repair"}}, "mode", "Operation mode")
+	wslIntegrationDoctorCmd.Flags().Bool("docker", false, "Check that docker is usable")
+	wslIntegrationDoctorViper.AutomaticEnv()
+	wslIntegrationDoctorViper.BindPFlags(wslIntegrationDoctorCmd.Flags())
+	wslIntegrationCmd.AddCommand(wslIntegrationDoctorCmd)
+}
@@ -0,0 +1,51 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	wslutils "github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/wsl-utils"
+)
+
+// wslPreflightCmd represents the `wsl preflight` command.
+var wslPreflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check that WSL can run Rancher Desktop",
+	Long: `Check that WSL can run Rancher Desktop, and output a JSON report of any
+problems found.  The report is output even if there are fatal problems.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		report, err := wslutils.Preflight(cmd.Context())
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	},
+}
+
+func init() {
+	wslCmd.AddCommand(wslPreflightCmd)
+}
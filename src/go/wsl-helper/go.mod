@@ -14,6 +14,11 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/linuxkit/virtsock v0.0.0-20201010232012-f8cee7dfc7a3
 	github.com/pkg/errors v0.9.1
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling v0.0.0
+	github.com/rancher-sandbox/rancher-desktop/src/go/wslexe v0.0.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.7.0
 	github.com/spf13/viper v1.16.0
@@ -41,6 +46,7 @@ require (
 	github.com/oklog/ulid v1.3.1 // indirect
 	github.com/pelletier/go-toml/v2 v2.0.8 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry v0.0.0 // indirect
 	github.com/spf13/afero v1.9.5 // indirect
 	github.com/spf13/cast v1.5.1 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
@@ -50,3 +56,12 @@ require (
 	golang.org/x/text v0.9.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 )
+
+replace (
+	github.com/rancher-sandbox/rancher-desktop/src/go/execctx => ../execctx
+	github.com/rancher-sandbox/rancher-desktop/src/go/fips => ../fips
+	github.com/rancher-sandbox/rancher-desktop/src/go/logging => ../logging
+	github.com/rancher-sandbox/rancher-desktop/src/go/profiling => ../profiling
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry => ../retry
+	github.com/rancher-sandbox/rancher-desktop/src/go/wslexe => ../wslexe
+)
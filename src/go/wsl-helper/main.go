@@ -15,8 +15,12 @@ limitations under the License.
 */
 package main
 
-import "github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/cmd"
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/logging"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/cmd"
+)
 
 func main() {
+	defer logging.HandlePanic()
 	cmd.Execute()
 }
@@ -0,0 +1,275 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dns checks and repairs name resolution inside a WSL distribution;
+// this is commonly broken by VPN clients on the host.
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Paths to the files we manage; these are variables for testing.
+var (
+	ResolvConfPath = "/etc/resolv.conf"
+	WSLConfPath    = "/etc/wsl.conf"
+	routePath      = "/proc/net/route"
+)
+
+// backupSuffix is appended to the path of resolv.conf to keep the original
+// file when it is replaced.
+const backupSuffix = ".rancher-desktop-backup"
+
+// resolvConfHeader marks the resolv.conf files we write.
+const resolvConfHeader = "# Written by Rancher Desktop (wsl-helper wsl dns --mode=repair).\n" +
+	"# Run wsl-helper wsl dns --mode=restore to undo.\n"
+
+// NameserverStatus is the result of checking a name server.
+type NameserverStatus struct {
+	Address string `json:"address"`
+	Working bool   `json:"working"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report describes the state of name resolution in the distribution.
+type Report struct {
+	// Nameservers are the servers in resolv.conf.
+	Nameservers []NameserverStatus `json:"nameservers"`
+	// Working is set if at least one of the name servers works.
+	Working bool `json:"working"`
+	// Pinned is set if resolv.conf was written by Repair.
+	Pinned bool `json:"pinned"`
+}
+
+// Nameservers returns the name servers listed in resolv.conf contents.
+func Nameservers(contents []byte) []string {
+	var result []string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			result = append(result, fields[1])
+		}
+	}
+	return result
+}
+
+// Probe checks whether the name server at the given address can resolve the
+// name.
+func Probe(ctx context.Context, address, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, net.JoinHostPort(address, "53"))
+		},
+	}
+	addrs, err := resolver.LookupHost(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", name)
+	}
+	return nil
+}
+
+// Check probes each of the name servers in resolv.conf.
+func Check(ctx context.Context, name string, timeout time.Duration) (*Report, error) {
+	contents, err := os.ReadFile(ResolvConfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ResolvConfPath, err)
+	}
+	report := &Report{
+		Nameservers: []NameserverStatus{},
+		Pinned:      bytes.HasPrefix(contents, []byte(resolvConfHeader)),
+	}
+	for _, address := range Nameservers(contents) {
+		status := NameserverStatus{Address: address, Working: true}
+		if err := Probe(ctx, address, name, timeout); err != nil {
+			status.Working = false
+			status.Error = err.Error()
+		}
+		report.Working = report.Working || status.Working
+		report.Nameservers = append(report.Nameservers, status)
+	}
+	return report, nil
+}
+
+// HostResolver returns the address of the host as seen from the
+// distribution (its default gateway), where WSL runs a DNS proxy.
+func HostResolver() (string, error) {
+	contents, err := os.ReadFile(routePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read routes: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags ...; addresses are little endian hex.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gateway, err := hex.DecodeString(fields[2])
+		if err != nil || len(gateway) != 4 {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(gateway))
+		return ip.String(), nil
+	}
+	return "", errors.New("could not find the default gateway")
+}
+
+// Repair pins resolv.conf to the candidates that can resolve the name, and
+// stops WSL from overwriting it.  It returns the name servers used.
+func Repair(ctx context.Context, candidates []string, name string, timeout time.Duration) ([]string, error) {
+	var working []string
+	for _, address := range candidates {
+		if slices.Contains(working, address) {
+			continue
+		}
+		if err := Probe(ctx, address, name, timeout); err != nil {
+			continue
+		}
+		working = append(working, address)
+	}
+	if len(working) == 0 {
+		return nil, fmt.Errorf("none of the name servers %s can resolve %s", strings.Join(candidates, ", "), name)
+	}
+
+	if err := backupResolvConf(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(resolvConfHeader)
+	for _, address := range working {
+		fmt.Fprintf(&buf, "nameserver %s\n", address)
+	}
+	if err := os.WriteFile(ResolvConfPath, buf.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ResolvConfPath, err)
+	}
+	if err := disableGenerateResolvConf(); err != nil {
+		return nil, err
+	}
+	return working, nil
+}
+
+// Restore undoes Repair, letting WSL manage resolv.conf again.
+func Restore() error {
+	backupPath := ResolvConfPath + backupSuffix
+	if _, err := os.Lstat(backupPath); err == nil {
+		if err := os.Remove(ResolvConfPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", ResolvConfPath, err)
+		}
+		if err := os.Rename(backupPath, ResolvConfPath); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", ResolvConfPath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return restoreGenerateResolvConf()
+}
+
+// backupResolvConf keeps the original resolv.conf (which is usually a symlink
+// managed by WSL), unless that was already done.
+func backupResolvConf() error {
+	backupPath := ResolvConfPath + backupSuffix
+	if _, err := os.Lstat(backupPath); err == nil {
+		return os.Remove(ResolvConfPath)
+	}
+	if err := os.Rename(ResolvConfPath, backupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to back up %s: %w", ResolvConfPath, err)
+	}
+	return nil
+}
+
+// disableGenerateResolvConf stops WSL from overwriting resolv.conf, recording
+// the original setting in wsl.conf so that Restore can put it back.  Like the
+// resolv.conf backup, the setting is only recorded the first time.
+func disableGenerateResolvConf() error {
+	backupPath := WSLConfPath + backupSuffix
+	if _, err := os.Lstat(backupPath); errors.Is(err, os.ErrNotExist) {
+		contents, err := os.ReadFile(WSLConfPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", WSLConfPath, err)
+		}
+		// The backup holds just the original setting, if there was one.
+		var original []byte
+		if value, ok := GetINIValue(contents, "network", "generateResolvConf"); ok {
+			original = SetINIValue(nil, "network", "generateResolvConf", value)
+		}
+		if err := os.WriteFile(backupPath, original, 0o644); err != nil {
+			return fmt.Errorf("failed to back up the generateResolvConf setting: %w", err)
+		}
+	} else if err != nil {
+		return err
+	}
+	return updateWSLConf(func(conf []byte) []byte {
+		return SetINIValue(conf, "network", "generateResolvConf", "false")
+	})
+}
+
+// restoreGenerateResolvConf puts back the generateResolvConf setting recorded
+// by disableGenerateResolvConf.  Without a record, the setting is removed.
+func restoreGenerateResolvConf() error {
+	backupPath := WSLConfPath + backupSuffix
+	original, err := os.ReadFile(backupPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", backupPath, err)
+	}
+	value, ok := GetINIValue(original, "network", "generateResolvConf")
+	if err := updateWSLConf(func(conf []byte) []byte {
+		if ok {
+			return SetINIValue(conf, "network", "generateResolvConf", value)
+		}
+		return RemoveINIValue(conf, "network", "generateResolvConf")
+	}); err != nil {
+		return err
+	}
+	if err := os.Remove(backupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", backupPath, err)
+	}
+	return nil
+}
+
+func updateWSLConf(update func([]byte) []byte) error {
+	contents, err := os.ReadFile(WSLConfPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", WSLConfPath, err)
+	}
+	updated := update(contents)
+	if bytes.Equal(contents, updated) {
+		return nil
+	}
+	if err := os.WriteFile(WSLConfPath, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", WSLConfPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameservers(t *testing.T) {
+	contents := []byte("# comment\nsearch example.com\nnameserver 10.0.0.1\nnameserver  1.1.1.1 \noptions ndots:1\n")
+	assert.Equal(t, []string{"10.0.0.1", "1.1.1.1"}, Nameservers(contents))
+	assert.Empty(t, Nameservers(nil))
+}
+
+func TestHostResolver(t *testing.T) {
+	routePath = filepath.Join(t.TempDir(), "route")
+	t.Cleanup(func() { routePath = "/proc/net/route" })
+	routes := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+		"eth0\t0010A8C0\t00000000\t0001\t0\t0\t0\t00F0FFFF\t0\t0\t0\n" +
+		"eth0\t00000000\t0110A8C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n"
+	require.NoError(t, os.WriteFile(routePath, []byte(routes), 0o644))
+	address, err := HostResolver()
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.16.1", address)
+}
+
+func TestSetINIValue(t *testing.T) {
+	t.Run("adds section", func(t *testing.T) {
+		actual := SetINIValue([]byte("[boot]\nsystemd = true\n"), "network", "generateResolvConf", "false")
+		assert.Equal(t, "[boot]\nsystemd = true\n\n[network]\ngenerateResolvConf = false\n", string(actual))
+	})
+	t.Run("creates file", func(t *testing.T) {
+		actual := SetINIValue(nil, "network", "generateResolvConf", "false")
+		assert.Equal(t, "[network]\ngenerateResolvConf = false\n", string(actual))
+	})
+	t.Run("replaces value", func(t *testing.T) {
+		actual := SetINIValue([]byte("[network]\n# keep\ngenerateresolvconf=true\n[boot]\n"), "network", "generateResolvConf", "false")
+		assert.Equal(t, "[network]\n# keep\ngenerateResolvConf = false\n[boot]\n", string(actual))
+	})
+	t.Run("adds to existing section", func(t *testing.T) {
+		actual := SetINIValue([]byte("[network]\nhostname = box\n[boot]\n"), "network", "generateResolvConf", "false")
+		assert.Equal(t, "[network]\ngenerateResolvConf = false\nhostname = box\n[boot]\n", string(actual))
+	})
+}
+
+func TestGetINIValue(t *testing.T) {
+	contents := []byte("[interop]\n# enabled = true\nappendWindowsPath=false\n[network]\nenabled = true\n")
+	value, ok := GetINIValue(contents, "interop", "appendwindowspath")
+	assert.True(t, ok)
+	assert.Equal(t, "false", value)
+	_, ok = GetINIValue(contents, "interop", "enabled")
+	assert.False(t, ok)
+}
+
+func TestRemoveINIValue(t *testing.T) {
+	contents := []byte("[network]\ngenerateResolvConf = false\nhostname = box\n")
+	assert.Equal(t, "[network]\nhostname = box\n", string(RemoveINIValue(contents, "network", "generateResolvConf")))
+	assert.Equal(t, string(contents), string(RemoveINIValue(contents, "boot", "generateResolvConf")))
+}
+
+func useTempConfPaths(t *testing.T) {
+	dir := t.TempDir()
+	ResolvConfPath = filepath.Join(dir, "resolv.conf")
+	WSLConfPath = filepath.Join(dir, "wsl.conf")
+	t.Cleanup(func() {
+		ResolvConfPath = "/etc/resolv.conf"
+		WSLConfPath = "/etc/wsl.conf"
+	})
+}
+
+func TestRestore(t *testing.T) {
+	useTempConfPaths(t)
+	require.NoError(t, os.Symlink("/mnt/wsl/resolv.conf", ResolvConfPath+backupSuffix))
+	require.NoError(t, os.WriteFile(ResolvConfPath, []byte(resolvConfHeader+"nameserver 1.1.1.1\n"), 0o644))
+	require.NoError(t, os.WriteFile(WSLConfPath, []byte("[network]\ngenerateResolvConf = false\n"), 0o644))
+
+	require.NoError(t, Restore())
+	target, err := os.Readlink(ResolvConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/mnt/wsl/resolv.conf", target)
+	assert.NoFileExists(t, ResolvConfPath+backupSuffix)
+	contents, err := os.ReadFile(WSLConfPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[network]\n", string(contents))
+}
+
+func TestGenerateResolvConf(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		original string
+	}{
+		{name: "unset", original: "[boot]\nsystemd = true\n"},
+		{name: "enabled", original: "[network]\ngenerateResolvConf = true\n"},
+		{name: "already disabled", original: "[network]\ngenerateResolvConf = false\n"},
+		{name: "no wsl.conf"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			useTempConfPaths(t)
+			if tc.original != "" {
+				require.NoError(t, os.WriteFile(WSLConfPath, []byte(tc.original), 0o644))
+			}
+
+			require.NoError(t, disableGenerateResolvConf())
+			contents, err := os.ReadFile(WSLConfPath)
+			require.NoError(t, err)
+			value, _ := GetINIValue(contents, "network", "generateResolvConf")
+			assert.Equal(t, "false", value)
+
+			// Repairing again must not lose the original setting.
+			require.NoError(t, disableGenerateResolvConf())
+
+			require.NoError(t, restoreGenerateResolvConf())
+			contents, err = os.ReadFile(WSLConfPath)
+			require.NoError(t, err)
+			expected, expectedSet := GetINIValue([]byte(tc.original), "network", "generateResolvConf")
+			actual, actualSet := GetINIValue(contents, "network", "generateResolvConf")
+			assert.Equal(t, expectedSet, actualSet)
+			assert.Equal(t, expected, actual)
+			assert.NoFileExists(t, WSLConfPath+backupSuffix)
+		})
+	}
+}
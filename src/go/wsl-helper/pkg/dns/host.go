@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"net"
+	"regexp"
+)
+
+// Network interface types (IANAifType) that are normally used by VPN clients.
+const (
+	ifTypePPP         = 23
+	ifTypePropVirtual = 53
+	ifTypeTunnel      = 131
+)
+
+// vpnDescriptionPattern matches the descriptions of the network adapters of
+// common VPN clients that present themselves as Ethernet adapters.
+var vpnDescriptionPattern = regexp.MustCompile(
+	`(?i)vpn|anyconnect|globalprotect|pangp|fortinet|forticlient|juniper|pulse secure|zscaler|wireguard|wintun|tap-windows|openvpn`)
+
+// HostAdapter describes a network adapter on the host, for picking the DNS
+// servers to use.
+type HostAdapter struct {
+	Name        string
+	Description string
+	IfType      uint32
+	Up          bool
+	Servers     []net.IP
+}
+
+// isVPN guesses whether the adapter belongs to a VPN client.
+func (a HostAdapter) isVPN() bool {
+	switch a.IfType {
+	case ifTypePPP, ifTypePropVirtual, ifTypeTunnel:
+		return true
+	}
+	return vpnDescriptionPattern.MatchString(a.Description) || vpnDescriptionPattern.MatchString(a.Name)
+}
+
+// selectHostServers returns the DNS servers of the adapters that are up, in
+// order and without duplicates, optionally skipping those of VPN adapters.
+// The deprecated site-local IPv6 addresses Windows lists when no IPv6 DNS
+// server is configured are skipped.
+func selectHostServers(adapters []HostAdapter, excludeVPN bool) []string {
+	_, siteLocal, _ := net.ParseCIDR("fec0::/10")
+	seen := make(map[string]bool)
+	servers := []string{}
+	for _, adapter := range adapters {
+		if !adapter.Up || (excludeVPN && adapter.isVPN()) {
+			continue
+		}
+		for _, server := range adapter.Servers {
+			if server == nil || siteLocal.Contains(server) || seen[server.String()] {
+				continue
+			}
+			seen[server.String()] = true
+			servers = append(servers, server.String())
+		}
+	}
+	return servers
+}
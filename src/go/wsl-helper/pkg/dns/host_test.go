@@ -0,0 +1,66 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectHostServers(t *testing.T) {
+	adapters := []HostAdapter{
+		{
+			Name:        "Ethernet",
+			Description: "Intel(R) Ethernet Connection",
+			Up:          true,
+			Servers:     []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("fec0:0:0:ffff::1")},
+		},
+		{
+			Name:        "Ethernet 2",
+			Description: "Cisco AnyConnect Secure Mobility Client Virtual Miniport Adapter",
+			Up:          true,
+			Servers:     []net.IP{net.ParseIP("10.0.0.53")},
+		},
+		{
+			Name:    "Corporate VPN",
+			IfType:  ifTypePPP,
+			Up:      true,
+			Servers: []net.IP{net.ParseIP("10.0.0.54")},
+		},
+		{
+			Name:    "Wi-Fi",
+			Up:      false,
+			Servers: []net.IP{net.ParseIP("192.168.2.1")},
+		},
+		{
+			Name:    "Ethernet 3",
+			Up:      true,
+			Servers: []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("2001:db8::1")},
+		},
+	}
+	t.Run("all", func(t *testing.T) {
+		assert.Equal(t, []string{"192.168.1.1", "10.0.0.53", "10.0.0.54", "2001:db8::1"}, selectHostServers(adapters, false))
+	})
+	t.Run("exclude VPN", func(t *testing.T) {
+		assert.Equal(t, []string{"192.168.1.1", "2001:db8::1"}, selectHostServers(adapters, true))
+	})
+	t.Run("none", func(t *testing.T) {
+		assert.Equal(t, []string{}, selectHostServers(nil, true))
+	})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// HostServers returns the DNS servers configured on the host, in the order of
+// the network adapters.  If excludeVPN is set, the servers of adapters that
+// look like they belong to a VPN client are skipped; this is useful when the
+// VPN pushes DNS servers that are unreachable from the VM.
+func HostServers(excludeVPN bool) ([]string, error) {
+	adapters, err := hostAdapters()
+	if err != nil {
+		return nil, err
+	}
+	return selectHostServers(adapters, excludeVPN), nil
+}
+
+// Flags for GetAdaptersAddresses that x/sys/windows doesn't define.
+const (
+	gaaFlagSkipUnicast   = 0x0001
+	gaaFlagSkipAnycast   = 0x0002
+	gaaFlagSkipMulticast = 0x0004
+)
+
+// hostAdapters lists the network adapters on the host.
+func hostAdapters() ([]HostAdapter, error) {
+	flags := uint32(gaaFlagSkipUnicast | gaaFlagSkipAnycast | gaaFlagSkipMulticast)
+	size := uint32(15 * 1024)
+	var buf []byte
+	for {
+		buf = make([]byte, size)
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, flags, 0, (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])), &size)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, windows.ERROR_BUFFER_OVERFLOW) {
+			return nil, fmt.Errorf("failed to list network adapters: %w", err)
+		}
+	}
+	var adapters []HostAdapter
+	for addr := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])); addr != nil; addr = addr.Next {
+		adapter := HostAdapter{
+			Name:        windows.UTF16PtrToString(addr.FriendlyName),
+			Description: windows.UTF16PtrToString(addr.Description),
+			IfType:      addr.IfType,
+			Up:          addr.OperStatus == windows.IfOperStatusUp,
+		}
+		for server := addr.FirstDnsServerAddress; server != nil; server = server.Next {
+			adapter.Servers = append(adapter.Servers, server.Address.IP())
+		}
+		adapters = append(adapters, adapter)
+	}
+	return adapters, nil
+}
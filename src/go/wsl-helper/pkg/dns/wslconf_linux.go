@@ -0,0 +1,122 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bytes"
+	"strings"
+)
+
+// wsl.conf is an INI file; these helpers edit it line by line so that the
+// rest of the file (including comments) is preserved.
+
+// iniLines splits the contents into lines, returning the index range
+// [start, end) of the lines in the section (excluding its header), and
+// whether the section was found.
+func iniLines(contents []byte, section string) (lines []string, start, end int, found bool) {
+	text := strings.TrimSuffix(string(contents), "\n")
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	start, end = len(lines), len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+			continue
+		}
+		if found {
+			end = i
+			break
+		}
+		if strings.EqualFold(strings.TrimSpace(trimmed[1:len(trimmed)-1]), section) {
+			found = true
+			start = i + 1
+		}
+	}
+	return
+}
+
+// iniKey returns the key of a "key = value" line, or an empty string.
+func iniKey(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+		return ""
+	}
+	key, _, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+func joinLines(lines []string) []byte {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// GetINIValue returns the value of the key in the section, if it is set.
+func GetINIValue(contents []byte, section, key string) (string, bool) {
+	lines, start, end, _ := iniLines(contents, section)
+	for i := start; i < end; i++ {
+		if strings.EqualFold(iniKey(lines[i]), key) {
+			_, value, _ := strings.Cut(lines[i], "=")
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+// SetINIValue sets the key in the section, adding the section if needed.
+func SetINIValue(contents []byte, section, key, value string) []byte {
+	lines, start, end, found := iniLines(contents, section)
+	entry := key + " = " + value
+	if !found {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		return joinLines(append(lines, "["+section+"]", entry))
+	}
+	for i := start; i < end; i++ {
+		if strings.EqualFold(iniKey(lines[i]), key) {
+			if lines[i] == entry {
+				return contents
+			}
+			lines[i] = entry
+			return joinLines(lines)
+		}
+	}
+	lines = append(lines[:start], append([]string{entry}, lines[start:]...)...)
+	return joinLines(lines)
+}
+
+// RemoveINIValue removes the key from the section, if it is set.
+func RemoveINIValue(contents []byte, section, key string) []byte {
+	lines, start, end, found := iniLines(contents, section)
+	if !found {
+		return contents
+	}
+	for i := start; i < end; i++ {
+		if strings.EqualFold(iniKey(lines[i]), key) {
+			return joinLines(append(lines[:i], lines[i+1:]...))
+		}
+	}
+	return contents
+}
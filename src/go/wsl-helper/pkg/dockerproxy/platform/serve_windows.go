@@ -17,14 +17,16 @@ limitations under the License.
 package platform
 
 import (
+	"context"
 	"fmt"
 	"net"
-	"os/exec"
+	"os"
 	"regexp"
 	"strings"
 
 	"github.com/Microsoft/go-winio"
 	"github.com/linuxkit/virtsock/pkg/hvsock"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
 )
 
 // DefaultEndpoint is the platform-specific location that dockerd listens on by
@@ -144,15 +146,34 @@ func IsAbsolutePath(input string) bool {
 	return false
 }
 
+// stripExtendedPrefix converts an extended-length path (\\?\C:\... or
+// \\?\UNC\server\share\...), which clients use for paths longer than MAX_PATH,
+// to the normal form, as wslpath doesn't understand the prefix.  WSL itself
+// has no limit on the length of paths.
+func stripExtendedPrefix(windowsPath string) string {
+	if rest, ok := strings.CutPrefix(windowsPath, `\\?\UNC\`); ok {
+		return `\\` + rest
+	}
+	return strings.TrimPrefix(windowsPath, `\\?\`)
+}
+
+// wslDistro returns the name of the WSL distribution Rancher Desktop runs in;
+// named instances (see `rdctl --instance`) have their own.
+func wslDistro() string {
+	if instance := os.Getenv("RD_INSTANCE"); instance != "" {
+		return "rancher-desktop-" + instance
+	}
+	return "rancher-desktop"
+}
+
 // TranslatePathFromClient converts a client path to a path that can be used by
 // the docker daemon.
 func TranslatePathFromClient(windowsPath string) (string, error) {
 	// TODO: See if we can do something faster than shelling out.
-	cmd := exec.Command("wsl", "--distribution", "rancher-desktop", "--exec", "/bin/wslpath", "-a", "-u", windowsPath)
-	output, err := cmd.Output()
+	output, err := wslexe.Run(context.Background(), "--distribution", wslDistro(), "--exec", "/bin/wslpath", "-a", "-u", stripExtendedPrefix(windowsPath))
 	if err != nil {
 		return "", fmt.Errorf("error getting WSL path: %w", err)
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
@@ -49,3 +49,16 @@ func TestParseBindString(t *testing.T) {
 		})
 	}
 }
+
+func TestStripExtendedPrefix(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		`C:\Windows`:               `C:\Windows`,
+		`\\?\C:\Windows`:           `C:\Windows`,
+		`\\?\UNC\server\share\foo`: `\\server\share\foo`,
+		`\\server\share\foo`:       `\\server\share\foo`,
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, stripExtendedPrefix(input), input)
+	}
+}
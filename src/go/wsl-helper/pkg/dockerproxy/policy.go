@@ -0,0 +1,302 @@
+//go:build linux || windows
+// +build linux windows
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dockerproxy/platform"
+)
+
+// Policy restricts what clients of the proxy may ask of the docker daemon.  It
+// is passed in by Rancher Desktop from the (possibly locked) settings.
+type Policy struct {
+	// AllowPrivileged permits privileged containers and exec sessions, as well
+	// as anything else that gives a container control of the VM: added
+	// capabilities, devices, host namespaces and unconfined security options.
+	AllowPrivileged bool `json:"allowPrivileged"`
+	// AllowedMountRoots are the host directories that may be bind mounted,
+	// along with everything under them.  All other host paths are rejected.
+	AllowedMountRoots []string `json:"allowedMountRoots"`
+}
+
+// errPolicyDenied is wrapped by the errors for requests the policy rejects.
+var errPolicyDenied = errors.New("denied by the docker socket policy")
+
+// policyContainersCreateBody has the fields of POST /containers/create that
+// the policy is concerned with.
+type policyContainersCreateBody struct {
+	HostConfig struct {
+		Privileged  bool
+		CapAdd      []string
+		Devices     []struct{ PathOnHost string }
+		IpcMode     string
+		NetworkMode string
+		PidMode     string
+		UsernsMode  string
+		SecurityOpt []string
+		Binds       []string
+		Mounts      []struct {
+			Type          string
+			Source        string
+			VolumeOptions *struct {
+				DriverConfig *struct {
+					Options map[string]string
+				}
+			}
+		}
+	}
+}
+
+// policyExecCreateBody has the fields of POST /containers/{id}/exec that the
+// policy is concerned with.
+type policyExecCreateBody struct {
+	Privileged bool
+}
+
+// policyVolumesCreateBody has the fields of POST /volumes/create that the
+// policy is concerned with.
+type policyVolumesCreateBody struct {
+	DriverOpts map[string]string
+}
+
+var policyExecCreatePattern = regexp.MustCompile(`\A/containers/[^/]+/exec\z`)
+
+// check returns an error if the request, to the given API path (without the
+// version prefix), is not allowed by the policy.
+func (p *Policy) check(req *http.Request, requestPath string) error {
+	if req.Method != http.MethodPost {
+		return nil
+	}
+	switch {
+	case requestPath == "/containers/create":
+		var body policyContainersCreateBody
+		if err := readPolicyBody(req, &body); err != nil {
+			return err
+		}
+		if !p.AllowPrivileged {
+			if err := checkUnprivileged(&body); err != nil {
+				return err
+			}
+		}
+		for _, bind := range body.HostConfig.Binds {
+			host, _, _, isPath := platform.ParseBindString(bind)
+			if err := p.checkMount(host, isPath); err != nil {
+				return err
+			}
+		}
+		for _, mount := range body.HostConfig.Mounts {
+			if mount.Type == "bind" {
+				if err := p.checkMount(mount.Source, true); err != nil {
+					return err
+				}
+			}
+			if mount.VolumeOptions != nil && mount.VolumeOptions.DriverConfig != nil {
+				if err := p.checkDriverOptions(mount.VolumeOptions.DriverConfig.Options); err != nil {
+					return err
+				}
+			}
+		}
+	case requestPath == "/volumes/create":
+		var body policyVolumesCreateBody
+		if err := readPolicyBody(req, &body); err != nil {
+			return err
+		}
+		return p.checkDriverOptions(body.DriverOpts)
+	case policyExecCreatePattern.MatchString(requestPath):
+		var body policyExecCreateBody
+		if err := readPolicyBody(req, &body); err != nil {
+			return err
+		}
+		if body.Privileged && !p.AllowPrivileged {
+			return fmt.Errorf("privileged exec sessions are %w", errPolicyDenied)
+		}
+	}
+	return nil
+}
+
+// checkUnprivileged checks that a container would not get more control of the
+// VM than an unprivileged one.
+func checkUnprivileged(body *policyContainersCreateBody) error {
+	hostConfig := &body.HostConfig
+	if hostConfig.Privileged {
+		return fmt.Errorf("privileged containers are %w", errPolicyDenied)
+	}
+	if len(hostConfig.CapAdd) > 0 {
+		return fmt.Errorf("adding capabilities (%s) is %w", strings.Join(hostConfig.CapAdd, ", "), errPolicyDenied)
+	}
+	if len(hostConfig.Devices) > 0 {
+		return fmt.Errorf("adding device %s is %w", hostConfig.Devices[0].PathOnHost, errPolicyDenied)
+	}
+	namespaces := []struct{ name, mode string }{
+		{"IPC", hostConfig.IpcMode},
+		{"network", hostConfig.NetworkMode},
+		{"PID", hostConfig.PidMode},
+		{"user", hostConfig.UsernsMode},
+	}
+	for _, namespace := range namespaces {
+		if namespace.mode == "host" {
+			return fmt.Errorf("using the host %s namespace is %w", namespace.name, errPolicyDenied)
+		}
+	}
+	for _, opt := range hostConfig.SecurityOpt {
+		if isUnconfinedSecurityOpt(opt) {
+			return fmt.Errorf("security option %.40q is %w", opt, errPolicyDenied)
+		}
+	}
+	return nil
+}
+
+// isUnconfinedSecurityOpt checks if a security option lifts the restrictions
+// that apply to containers by default.  A custom seccomp profile is included,
+// as it can allow any system call.
+func isUnconfinedSecurityOpt(opt string) bool {
+	key, value, found := strings.Cut(opt, "=")
+	if !found {
+		// Older clients separate the key and the value with a colon.
+		key, value, _ = strings.Cut(opt, ":")
+	}
+	switch key {
+	case "seccomp":
+		return true
+	case "apparmor", "systempaths":
+		return value == "unconfined"
+	case "label":
+		return value == "disable"
+	}
+	return false
+}
+
+// checkDriverOptions checks the options of the local volume driver, which can
+// bind mount an arbitrary device or directory.
+func (p *Policy) checkDriverOptions(options map[string]string) error {
+	if device, ok := options["device"]; ok {
+		return p.checkMount(device, true)
+	}
+	return nil
+}
+
+// checkMount checks that the source of a mount is allowed; isPath indicates
+// whether it was given as a host path (rather than e.g. a volume name).
+// Anything that looks like an absolute path is checked regardless, as the
+// daemon would resolve it inside the VM.
+func (p *Policy) checkMount(source string, isPath bool) error {
+	if !isPath && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, `\`) {
+		return nil
+	}
+	for _, root := range p.AllowedMountRoots {
+		if isPathUnder(source, root) {
+			return nil
+		}
+	}
+	return fmt.Errorf("mounting %s is %w", source, errPolicyDenied)
+}
+
+// isPathUnder checks if the given absolute path is the root directory, or
+// anything under it.  On Windows, the comparison is case insensitive.
+func isPathUnder(input, root string) bool {
+	input = strings.TrimPrefix(input, `\\?\`)
+	root = strings.TrimPrefix(root, `\\?\`)
+	if !filepath.IsAbs(input) || !filepath.IsAbs(root) {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(input))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// readPolicyBody reads the request body as JSON, leaving a copy of it in the
+// request for the daemon.
+func readPolicyBody(req *http.Request, data interface{}) error {
+	if req.Body == nil {
+		return nil
+	}
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("could not read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewBuffer(buf))
+	if len(buf) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(buf, data); err != nil {
+		return fmt.Errorf("could not unmarshal request body: %w", err)
+	}
+	return nil
+}
+
+// policyChecker applies the policy the proxy was started with, if any.
+type policyChecker struct {
+	policy *Policy
+	// err is set if the policy could not be parsed.
+	err error
+}
+
+// newPolicyChecker parses the given policy, in JSON; an empty string means
+// there is no policy.
+func newPolicyChecker(policyJSON string) *policyChecker {
+	if policyJSON == "" {
+		return &policyChecker{}
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal([]byte(policyJSON), policy); err != nil {
+		err = fmt.Errorf("could not parse docker socket policy: %w", err)
+		logrus.WithError(err).Error("rejecting all requests")
+		return &policyChecker{err: err}
+	}
+	logrus.WithField("policy", policy).Info("loaded docker socket policy")
+	return &policyChecker{policy: policy}
+}
+
+// checkRequest checks the request against the policy.  If the policy could not
+// be parsed, all requests are rejected rather than allowing everything.
+func (c *policyChecker) checkRequest(req *http.Request, requestPath string) error {
+	if c.err != nil {
+		return fmt.Errorf("request %w: %w", errPolicyDenied, c.err)
+	}
+	if c.policy == nil {
+		return nil
+	}
+	return c.policy.check(req, requestPath)
+}
+
+// writePolicyError responds to a request that failed the policy check, in the
+// same form as errors from the docker daemon so that clients display it.
+func writePolicyError(w http.ResponseWriter, err error) {
+	status := http.StatusForbidden
+	if !errors.Is(err, errPolicyDenied) {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
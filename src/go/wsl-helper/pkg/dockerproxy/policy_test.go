@@ -0,0 +1,120 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerproxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPolicyRequest(t *testing.T, body string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, "http://proxy.invalid/", strings.NewReader(body))
+	require.NoError(t, err)
+	return req
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := &Policy{AllowedMountRoots: []string{"/home/user/projects"}}
+	testCases := []struct {
+		name    string
+		path    string
+		body    string
+		allowed bool
+	}{
+		{"plain container", "/containers/create", `{"Image":"busybox"}`, true},
+		{"privileged container", "/containers/create", `{"HostConfig":{"Privileged":true}}`, false},
+		{"added capability", "/containers/create", `{"HostConfig":{"CapAdd":["SYS_ADMIN"]}}`, false},
+		{"dropped capability", "/containers/create", `{"HostConfig":{"CapDrop":["NET_RAW"]}}`, true},
+		{"device", "/containers/create", `{"HostConfig":{"Devices":[{"PathOnHost":"/dev/sda","PathInContainer":"/dev/sda"}]}}`, false},
+		{"host IPC namespace", "/containers/create", `{"HostConfig":{"IpcMode":"host"}}`, false},
+		{"host network", "/containers/create", `{"HostConfig":{"NetworkMode":"host"}}`, false},
+		{"bridge network", "/containers/create", `{"HostConfig":{"NetworkMode":"bridge"}}`, true},
+		{"host PID namespace", "/containers/create", `{"HostConfig":{"PidMode":"host"}}`, false},
+		{"host user namespace", "/containers/create", `{"HostConfig":{"UsernsMode":"host"}}`, false},
+		{"unconfined seccomp", "/containers/create", `{"HostConfig":{"SecurityOpt":["seccomp=unconfined"]}}`, false},
+		{"custom seccomp profile", "/containers/create", `{"HostConfig":{"SecurityOpt":["seccomp={\"defaultAction\":\"SCMP_ACT_ALLOW\"}"]}}`, false},
+		{"unconfined apparmor", "/containers/create", `{"HostConfig":{"SecurityOpt":["apparmor:unconfined"]}}`, false},
+		{"disabled labels", "/containers/create", `{"HostConfig":{"SecurityOpt":["label=disable"]}}`, false},
+		{"unconfined system paths", "/containers/create", `{"HostConfig":{"SecurityOpt":["systempaths=unconfined"]}}`, false},
+		{"no new privileges", "/containers/create", `{"HostConfig":{"SecurityOpt":["no-new-privileges"]}}`, true},
+		{"bind under root", "/containers/create", `{"HostConfig":{"Binds":["/home/user/projects/app:/app:ro"]}}`, true},
+		{"bind of root", "/containers/create", `{"HostConfig":{"Binds":["/home/user/projects:/app"]}}`, true},
+		{"bind outside root", "/containers/create", `{"HostConfig":{"Binds":["/etc:/host-etc"]}}`, false},
+		{"bind escaping root", "/containers/create", `{"HostConfig":{"Binds":["/home/user/projects/../.ssh:/ssh"]}}`, false},
+		{"bind of sibling", "/containers/create", `{"HostConfig":{"Binds":["/home/user/projects2:/app"]}}`, false},
+		{"named volume", "/containers/create", `{"HostConfig":{"Binds":["data:/data"]}}`, true},
+		{"bind mount outside root", "/containers/create", `{"HostConfig":{"Mounts":[{"Type":"bind","Source":"/"}]}}`, false},
+		{"volume mount", "/containers/create", `{"HostConfig":{"Mounts":[{"Type":"volume","Source":"data"}]}}`, true},
+		{"volume mount with device", "/containers/create", `{"HostConfig":{"Mounts":[{"Type":"volume","Source":"data","VolumeOptions":{"DriverConfig":{"Options":{"device":"/etc"}}}}]}}`, false},
+		{"volume with device", "/volumes/create", `{"Name":"data","DriverOpts":{"type":"none","o":"bind","device":"/etc"}}`, false},
+		{"volume", "/volumes/create", `{"Name":"data"}`, true},
+		{"exec", "/containers/abc/exec", `{"Cmd":["sh"]}`, true},
+		{"privileged exec", "/containers/abc/exec", `{"Cmd":["sh"],"Privileged":true}`, false},
+		{"other endpoint", "/containers/abc/start", ``, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := newPolicyRequest(t, testCase.body)
+			err := policy.check(req, testCase.path)
+			if testCase.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errPolicyDenied)
+			}
+			// The body must still be available for the daemon.
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.body, string(body))
+		})
+	}
+
+	t.Run("privileged allowed", func(t *testing.T) {
+		policy := &Policy{AllowPrivileged: true}
+		req := newPolicyRequest(t, `{"HostConfig":{"Privileged":true,"CapAdd":["ALL"],"PidMode":"host","SecurityOpt":["seccomp=unconfined"]}}`)
+		assert.NoError(t, policy.check(req, "/containers/create"))
+	})
+}
+
+func TestPolicyChecker(t *testing.T) {
+	privileged := func() *http.Request {
+		return newPolicyRequest(t, `{"HostConfig":{"Privileged":true}}`)
+	}
+
+	t.Run("allows everything without a policy", func(t *testing.T) {
+		checker := newPolicyChecker("")
+		assert.NoError(t, checker.checkRequest(privileged(), "/containers/create"))
+	})
+
+	t.Run("applies the policy", func(t *testing.T) {
+		checker := newPolicyChecker(`{"allowPrivileged":false}`)
+		assert.ErrorIs(t, checker.checkRequest(privileged(), "/containers/create"), errPolicyDenied)
+		assert.NoError(t, checker.checkRequest(newPolicyRequest(t, ``), "/_ping"))
+	})
+
+	t.Run("rejects everything if the policy can't be parsed", func(t *testing.T) {
+		checker := newPolicyChecker(`{`)
+		assert.ErrorIs(t, checker.checkRequest(newPolicyRequest(t, ``), "/_ping"), errPolicyDenied)
+	})
+}
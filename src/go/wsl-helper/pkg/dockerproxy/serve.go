@@ -53,8 +53,9 @@ type containerInspectResponseBody struct {
 const dockerAPIVersion = "v1.41.0"
 
 // Serve up the docker proxy at the given endpoint, using the given function to
-// create a connection to the real dockerd.
-func Serve(endpoint string, dialer func() (net.Conn, error)) error {
+// create a connection to the real dockerd.  If policy is not empty, it is the
+// JSON form of a Policy to apply to the requests.
+func Serve(endpoint string, dialer func() (net.Conn, error), policy string) error {
 	listener, err := platform.Listen(endpoint)
 	if err != nil {
 		return err
@@ -74,6 +75,7 @@ func Serve(endpoint string, dialer func() (net.Conn, error)) error {
 	logWriter := logrus.StandardLogger().Writer()
 	defer logWriter.Close()
 	munger := newRequestMunger()
+	checker := newPolicyChecker(policy)
 	proxy := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			logrus.WithField("request", req).
@@ -128,6 +130,14 @@ func Serve(endpoint string, dialer func() (net.Conn, error)) error {
 	}
 
 	contextAttacher := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := checker.checkRequest(req, munger.getRequestPath(req)); err != nil {
+			logrus.WithError(err).
+				WithField("method", req.Method).
+				WithField("url", req.URL).
+				Warn("rejecting request")
+			writePolicyError(w, err)
+			return
+		}
 		ctx := context.WithValue(req.Context(), requestContext, &RequestContextValue{})
 		newReq := req.WithContext(ctx)
 		proxy.ServeHTTP(w, newReq)
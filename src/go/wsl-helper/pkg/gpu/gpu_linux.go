@@ -0,0 +1,170 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gpu detects the GPU support WSL provides (the /dev/dxg paravirtual
+// device, plus the DirectML and CUDA libraries from the host driver), and
+// exposes it to containers through a CDI (Container Device Interface) spec.
+package gpu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Paths used for detection; these are variables for testing.
+var (
+	DevicePath = "/dev/dxg"
+	LibraryDir = "/usr/lib/wsl/lib"
+	DriverDir  = "/usr/lib/wsl/drivers"
+)
+
+// CDIKind is the kind of the devices in the CDI spec; containers request the
+// GPU with e.g. `docker run --device rancherdesktop.io/gpu=all`.
+const CDIKind = "rancherdesktop.io/gpu"
+
+// cdiSpecName is the name of the CDI spec file we manage.
+const cdiSpecName = "rancher-desktop-gpu.json"
+
+// Info describes the GPU support available in the distribution.
+type Info struct {
+	Available bool `json:"available"`
+	// Reason explains why the GPU is not available.
+	Reason string `json:"reason,omitempty"`
+	// DirectML is set if the DirectX (and so DirectML) libraries exist.
+	DirectML bool `json:"directML"`
+	// CUDA is set if the host driver provides the CUDA libraries.
+	CUDA bool `json:"cuda"`
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Detect checks for GPU support.
+func Detect() Info {
+	info := Info{
+		DirectML: exists(filepath.Join(LibraryDir, "libd3d12.so")),
+		CUDA:     exists(filepath.Join(LibraryDir, "libcuda.so")) || exists(filepath.Join(LibraryDir, "libcuda.so.1")),
+	}
+	switch {
+	case !exists(DevicePath):
+		info.Reason = fmt.Sprintf("The WSL GPU device %s does not exist; this needs WSL 2 and a GPU driver with WSL support on the host.", DevicePath)
+	case !exists(filepath.Join(LibraryDir, "libdxcore.so")):
+		info.Reason = fmt.Sprintf("The WSL GPU libraries are missing from %s; update WSL with `wsl --update`.", LibraryDir)
+	case !info.DirectML && !info.CUDA:
+		info.Reason = "The host GPU driver does not provide DirectML or CUDA libraries to WSL; install a driver with WSL support."
+	default:
+		info.Available = true
+	}
+	return info
+}
+
+// cdiSpec returns the CDI spec giving containers access to the GPU.
+func cdiSpec() ([]byte, error) {
+	mounts := []map[string]any{}
+	for _, dir := range []string{LibraryDir, DriverDir} {
+		if exists(dir) {
+			mounts = append(mounts, map[string]any{
+				"hostPath":      dir,
+				"containerPath": dir,
+				"options":       []string{"ro", "nosuid", "nodev", "bind"},
+			})
+		}
+	}
+	return json.MarshalIndent(map[string]any{
+		"cdiVersion": "0.5.0",
+		"kind":       CDIKind,
+		"devices": []map[string]any{{
+			"name":           "all",
+			"containerEdits": map[string]any{"deviceNodes": []map[string]string{{"path": DevicePath}}},
+		}},
+		"containerEdits": map[string]any{
+			"mounts": mounts,
+			// The libraries are not in the default search path of images.
+			"env": []string{"LD_LIBRARY_PATH=" + LibraryDir},
+		},
+	}, "", "  ")
+}
+
+// Enable writes the CDI spec into cdiDir, and turns on CDI support in the
+// docker daemon configuration at dockerConfigPath (if not empty).
+func Enable(cdiDir, dockerConfigPath string) error {
+	if info := Detect(); !info.Available {
+		return errors.New(info.Reason)
+	}
+	spec, err := cdiSpec()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cdiDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create CDI spec directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(cdiDir, cdiSpecName), spec, 0o644); err != nil {
+		return fmt.Errorf("failed to write CDI spec: %w", err)
+	}
+	if dockerConfigPath != "" {
+		return enableDockerCDI(dockerConfigPath)
+	}
+	return nil
+}
+
+// Disable removes the CDI spec.  CDI support is left enabled in the docker
+// daemon, as it has no effect without specs.
+func Disable(cdiDir string) error {
+	err := os.Remove(filepath.Join(cdiDir, cdiSpecName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove CDI spec: %w", err)
+	}
+	return nil
+}
+
+// enableDockerCDI sets features.cdi in the docker daemon configuration,
+// keeping the rest of it.
+func enableDockerCDI(configPath string) error {
+	config := map[string]any{}
+	contents, err := os.ReadFile(configPath)
+	if err == nil {
+		if err := json.Unmarshal(contents, &config); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", configPath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	features, _ := config["features"].(map[string]any)
+	if features == nil {
+		features = map[string]any{}
+	}
+	if features["cdi"] == true {
+		return nil
+	}
+	features["cdi"] = true
+	config["features"] = features
+	updated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(configPath), err)
+	}
+	if err := os.WriteFile(configPath, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	return nil
+}
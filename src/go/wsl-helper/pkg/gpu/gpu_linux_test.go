@@ -0,0 +1,103 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gpu
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWSL points the detection paths at a temporary directory containing the
+// given files.
+func fakeWSL(t *testing.T, files ...string) string {
+	root := t.TempDir()
+	oldDevice, oldLib, oldDriver := DevicePath, LibraryDir, DriverDir
+	t.Cleanup(func() { DevicePath, LibraryDir, DriverDir = oldDevice, oldLib, oldDriver })
+	DevicePath = filepath.Join(root, "dev", "dxg")
+	LibraryDir = filepath.Join(root, "lib")
+	DriverDir = filepath.Join(root, "drivers")
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+	return root
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("no device", func(t *testing.T) {
+		fakeWSL(t, "lib/libdxcore.so", "lib/libd3d12.so")
+		info := Detect()
+		assert.False(t, info.Available)
+		assert.Contains(t, info.Reason, "does not exist")
+		assert.True(t, info.DirectML)
+	})
+	t.Run("no compute libraries", func(t *testing.T) {
+		fakeWSL(t, "dev/dxg", "lib/libdxcore.so")
+		info := Detect()
+		assert.False(t, info.Available)
+		assert.Contains(t, info.Reason, "DirectML or CUDA")
+	})
+	t.Run("CUDA", func(t *testing.T) {
+		fakeWSL(t, "dev/dxg", "lib/libdxcore.so", "lib/libcuda.so.1")
+		assert.Equal(t, Info{Available: true, CUDA: true}, Detect())
+	})
+}
+
+func TestEnable(t *testing.T) {
+	root := fakeWSL(t, "dev/dxg", "lib/libdxcore.so", "lib/libd3d12.so")
+	cdiDir := filepath.Join(root, "etc", "cdi")
+	dockerConfigPath := filepath.Join(root, "etc", "docker", "daemon.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dockerConfigPath), 0o755))
+	require.NoError(t, os.WriteFile(dockerConfigPath, []byte(`{"debug": true, "features": {"buildkit": true}}`), 0o644))
+
+	require.NoError(t, Enable(cdiDir, dockerConfigPath))
+
+	contents, err := os.ReadFile(filepath.Join(cdiDir, cdiSpecName))
+	require.NoError(t, err)
+	var spec struct {
+		Kind    string
+		Devices []struct {
+			Name           string
+			ContainerEdits struct{ DeviceNodes []struct{ Path string } }
+		}
+		ContainerEdits struct {
+			Mounts []struct{ HostPath string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(contents, &spec))
+	assert.Equal(t, CDIKind, spec.Kind)
+	require.Len(t, spec.Devices, 1)
+	assert.Equal(t, "all", spec.Devices[0].Name)
+	assert.Equal(t, DevicePath, spec.Devices[0].ContainerEdits.DeviceNodes[0].Path)
+	// The drivers directory doesn't exist, so it isn't mounted.
+	require.Len(t, spec.ContainerEdits.Mounts, 1)
+	assert.Equal(t, LibraryDir, spec.ContainerEdits.Mounts[0].HostPath)
+
+	contents, err = os.ReadFile(dockerConfigPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"debug": true, "features": {"buildkit": true, "cdi": true}}`, string(contents))
+
+	require.NoError(t, Disable(cdiDir))
+	assert.NoFileExists(t, filepath.Join(cdiDir, cdiSpecName))
+	require.NoError(t, Disable(cdiDir))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/execctx"
+)
+
+// certificatePrefix is the prefix of the names of the certificate files we
+// manage; any other files in the trust store are left alone.
+const certificatePrefix = "rancher-desktop-"
+
+// TrustStore is a directory of extra CA certificates in a distribution, and
+// the command that rebuilds the system trust store from it.
+type TrustStore struct {
+	Dir    string
+	Update []string
+}
+
+// knownTrustStores are the trust store layouts of the common distributions,
+// in the order they are tried.
+var knownTrustStores = []TrustStore{
+	// Fedora, RHEL and derivatives
+	{Dir: "/etc/pki/ca-trust/source/anchors", Update: []string{"update-ca-trust", "extract"}},
+	// openSUSE and SLES
+	{Dir: "/etc/pki/trust/anchors", Update: []string{"update-ca-certificates"}},
+	// Arch Linux
+	{Dir: "/etc/ca-certificates/trust-source/anchors", Update: []string{"trust", "extract-compat"}},
+	// Debian, Ubuntu and Alpine
+	{Dir: "/usr/local/share/ca-certificates", Update: []string{"update-ca-certificates"}},
+}
+
+// FindTrustStore returns the trust store of the distribution the process is
+// running in.
+func FindTrustStore() (*TrustStore, error) {
+	for _, store := range knownTrustStores {
+		if info, err := os.Stat(store.Dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if _, err := exec.LookPath(store.Update[0]); err != nil {
+			continue
+		}
+		return &store, nil
+	}
+	return nil, errors.New("could not find a supported CA certificate store")
+}
+
+// Sync installs the certificates in the PEM bundle, removing any previously
+// installed certificates that are no longer in it.  It returns whether
+// anything changed, in which case UpdateSystem needs to be called.
+func (store *TrustStore) Sync(bundle []byte) (bool, error) {
+	wanted := map[string][]byte{}
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		sum := sha256.Sum256(block.Bytes)
+		name := fmt.Sprintf("%s%s.crt", certificatePrefix, hex.EncodeToString(sum[:8]))
+		wanted[name] = pem.EncodeToMemory(block)
+	}
+
+	changed, err := store.removeExcept(wanted)
+	if err != nil {
+		return changed, err
+	}
+	for name, contents := range wanted {
+		certPath := filepath.Join(store.Dir, name)
+		if existing, err := os.ReadFile(certPath); err == nil && bytes.Equal(existing, contents) {
+			continue
+		}
+		if err := os.WriteFile(certPath, contents, 0o644); err != nil {
+			return changed, fmt.Errorf("failed to write certificate %s: %w", certPath, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// Remove removes all the certificates installed by Sync, returning whether
+// there were any.
+func (store *TrustStore) Remove() (bool, error) {
+	return store.removeExcept(nil)
+}
+
+func (store *TrustStore) removeExcept(keep map[string][]byte) (bool, error) {
+	existing, err := filepath.Glob(filepath.Join(store.Dir, certificatePrefix+"*.crt"))
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for _, certPath := range existing {
+		if _, ok := keep[filepath.Base(certPath)]; ok {
+			continue
+		}
+		if err := os.Remove(certPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return changed, fmt.Errorf("failed to remove certificate %s: %w", certPath, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// UpdateSystem rebuilds the system trust store from the certificates in the
+// directory.
+func (store *TrustStore) UpdateSystem() error {
+	cmd := execctx.Command(context.Background(), 0, store.Update[0], store.Update[1:]...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration_test
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+func pemBundle(contents ...string) []byte {
+	var bundle []byte
+	for _, content := range contents {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte(content)})...)
+	}
+	return bundle
+}
+
+func managedFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "rancher-desktop-*.crt"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestTrustStore(t *testing.T) {
+	t.Run("installs certificates", func(t *testing.T) {
+		store := &integration.TrustStore{Dir: t.TempDir()}
+		changed, err := store.Sync(pemBundle("first", "second"))
+		require.NoError(t, err)
+		assert.True(t, changed)
+		files := managedFiles(t, store.Dir)
+		require.Len(t, files, 2)
+		for _, file := range files {
+			contents, err := os.ReadFile(file)
+			require.NoError(t, err)
+			block, _ := pem.Decode(contents)
+			require.NotNil(t, block)
+			assert.Contains(t, []string{"first", "second"}, string(block.Bytes))
+		}
+	})
+	t.Run("does nothing if up to date", func(t *testing.T) {
+		store := &integration.TrustStore{Dir: t.TempDir()}
+		_, err := store.Sync(pemBundle("first"))
+		require.NoError(t, err)
+		changed, err := store.Sync(pemBundle("first"))
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+	t.Run("removes stale certificates", func(t *testing.T) {
+		store := &integration.TrustStore{Dir: t.TempDir()}
+		unmanaged := filepath.Join(store.Dir, "corporate.crt")
+		require.NoError(t, os.WriteFile(unmanaged, pemBundle("corporate"), 0o644))
+		_, err := store.Sync(pemBundle("first", "second"))
+		require.NoError(t, err)
+		changed, err := store.Sync(pemBundle("second"))
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Len(t, managedFiles(t, store.Dir), 1)
+
+		changed, err = store.Remove()
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Empty(t, managedFiles(t, store.Dir))
+		assert.FileExists(t, unmanaged, "certificates we didn't install should be kept")
+	})
+}
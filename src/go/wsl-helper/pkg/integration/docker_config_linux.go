@@ -0,0 +1,190 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// dockerConfigStateName is the file, next to the docker config.json, where we
+// record the values we wrote; this lets us tell them apart from values the
+// user set, which are never changed or removed.
+const dockerConfigStateName = ".rancher-desktop-managed.json"
+
+// dockerConfigManagedKeys are the (dotted) paths in config.json we manage.
+var dockerConfigManagedKeys = []string{"credsStore", "proxies.default"}
+
+// DockerClientConfig is the docker CLI configuration to apply.  Empty fields
+// are not set.
+type DockerClientConfig struct {
+	// CredsStore is the credential helper, e.g. "wincred.exe" to use the
+	// Windows credential helper through WSL interop.
+	CredsStore string
+	// HTTPProxy, HTTPSProxy, and NoProxy are passed to containers (and builds)
+	// as the proxy environment variables.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// DockerConfig updates config.json in the docker configuration directory with
+// the given configuration; with enabled false, the values previously set are
+// removed instead.
+func DockerConfig(configDir string, desired DockerClientConfig, enabled bool) error {
+	configPath := filepath.Join(configDir, "config.json")
+	statePath := filepath.Join(configDir, dockerConfigStateName)
+	config, err := readJSONObject(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read docker config: %w", err)
+	}
+	previous, err := readJSONObject(statePath)
+	if err != nil {
+		return fmt.Errorf("failed to read docker config state: %w", err)
+	}
+
+	wanted := map[string]any{}
+	if enabled {
+		if desired.CredsStore != "" {
+			wanted["credsStore"] = desired.CredsStore
+		}
+		proxy := map[string]any{}
+		for key, value := range map[string]string{
+			"httpProxy":  desired.HTTPProxy,
+			"httpsProxy": desired.HTTPSProxy,
+			"noProxy":    desired.NoProxy,
+		} {
+			if value != "" {
+				proxy[key] = value
+			}
+		}
+		if len(proxy) > 0 {
+			wanted["proxies.default"] = proxy
+		}
+	}
+
+	managed := map[string]any{}
+	changed := false
+	for _, key := range dockerConfigManagedKeys {
+		current, exists := jsonPathGet(config, key)
+		if exists && !reflect.DeepEqual(current, previous[key]) {
+			// The user has set this; leave it alone.
+			continue
+		}
+		if value, ok := wanted[key]; ok {
+			if !reflect.DeepEqual(current, value) {
+				jsonPathSet(config, key, value)
+				changed = true
+			}
+			managed[key] = value
+		} else if exists {
+			jsonPathDelete(config, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := os.MkdirAll(configDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create docker config directory: %w", err)
+		}
+		if err := writeJSONObject(configPath, config); err != nil {
+			return fmt.Errorf("failed to write docker config: %w", err)
+		}
+	}
+	if len(managed) == 0 {
+		if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove docker config state: %w", err)
+		}
+	} else if !reflect.DeepEqual(managed, previous) {
+		if err := writeJSONObject(statePath, managed); err != nil {
+			return fmt.Errorf("failed to write docker config state: %w", err)
+		}
+	}
+	return nil
+}
+
+// readJSONObject reads a JSON object from the file; a missing file is
+// treated as an empty object.
+func readJSONObject(path string) (map[string]any, error) {
+	result := map[string]any{}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func writeJSONObject(path string, value map[string]any) error {
+	contents, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0o600)
+}
+
+func jsonPathGet(object map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := object[part].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		object = child
+	}
+	value, ok := object[parts[len(parts)-1]]
+	return value, ok
+}
+
+func jsonPathSet(object map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := object[part].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			object[part] = child
+		}
+		object = child
+	}
+	object[parts[len(parts)-1]] = value
+}
+
+// jsonPathDelete removes the value at the path, along with any parent
+// objects left empty.
+func jsonPathDelete(object map[string]any, path string) {
+	parts := strings.Split(path, ".")
+	if len(parts) > 1 {
+		child, ok := object[parts[0]].(map[string]any)
+		if !ok {
+			return
+		}
+		jsonPathDelete(child, strings.Join(parts[1:], "."))
+		if len(child) > 0 {
+			return
+		}
+	}
+	delete(object, parts[0])
+}
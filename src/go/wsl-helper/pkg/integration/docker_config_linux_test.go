@@ -0,0 +1,85 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+func TestDockerConfig(t *testing.T) {
+	desired := integration.DockerClientConfig{
+		CredsStore: "wincred.exe",
+		HTTPProxy:  "http://proxy.example:3128",
+		HTTPSProxy: "http://proxy.example:3128",
+		NoProxy:    "localhost,10.0.0.0/8",
+	}
+
+	t.Run("sets and removes values", func(t *testing.T) {
+		configDir := t.TempDir()
+		configPath := filepath.Join(configDir, "config.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"auths": {}}`), 0o600))
+		require.NoError(t, integration.DockerConfig(configDir, desired, true))
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"auths": {},
+			"credsStore": "wincred.exe",
+			"proxies": {"default": {
+				"httpProxy": "http://proxy.example:3128",
+				"httpsProxy": "http://proxy.example:3128",
+				"noProxy": "localhost,10.0.0.0/8"
+			}}
+		}`, string(contents))
+
+		require.NoError(t, integration.DockerConfig(configDir, desired, false))
+		contents, err = os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"auths": {}}`, string(contents))
+	})
+
+	t.Run("updates values it set", func(t *testing.T) {
+		configDir := t.TempDir()
+		configPath := filepath.Join(configDir, "config.json")
+		require.NoError(t, integration.DockerConfig(configDir, desired, true))
+		require.NoError(t, integration.DockerConfig(configDir, integration.DockerClientConfig{CredsStore: "wincred.exe"}, true))
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"credsStore": "wincred.exe"}`, string(contents))
+	})
+
+	t.Run("leaves user values alone", func(t *testing.T) {
+		configDir := t.TempDir()
+		configPath := filepath.Join(configDir, "config.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"credsStore": "pass"}`), 0o600))
+		require.NoError(t, integration.DockerConfig(configDir, integration.DockerClientConfig{CredsStore: "wincred.exe"}, true))
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"credsStore": "pass"}`, string(contents))
+
+		require.NoError(t, integration.DockerConfig(configDir, desired, false))
+		contents, err = os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"credsStore": "pass"}`, string(contents))
+	})
+}
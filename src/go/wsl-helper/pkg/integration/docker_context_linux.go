@@ -0,0 +1,108 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dockerContextMeta is the metadata of a docker context, as stored in
+// ~/.docker/contexts/meta/<sha256 of name>/meta.json.
+type dockerContextMeta struct {
+	Name      string
+	Metadata  map[string]string
+	Endpoints map[string]dockerContextEndpoint
+}
+
+type dockerContextEndpoint struct {
+	Host          string
+	SkipTLSVerify bool
+}
+
+// DockerContext creates (or removes) a named docker context in the docker
+// configuration directory pointing at the given socket.  The current context
+// is not changed when creating it, so that any endpoints the user configured
+// stay in effect until they switch with `docker context use`.
+func DockerContext(configDir, name, socketPath string, enabled bool) error {
+	sum := sha256.Sum256([]byte(name))
+	contextDir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(sum[:]))
+
+	if !enabled {
+		if err := os.RemoveAll(contextDir); err != nil {
+			return fmt.Errorf("failed to remove docker context %s: %w", name, err)
+		}
+		return clearCurrentDockerContext(configDir, name)
+	}
+
+	meta, err := json.Marshal(dockerContextMeta{
+		Name:     name,
+		Metadata: map[string]string{"Description": "Rancher Desktop moby context"},
+		Endpoints: map[string]dockerContextEndpoint{
+			"docker": {Host: "unix://" + socketPath},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	metaPath := filepath.Join(contextDir, "meta.json")
+	if existing, err := os.ReadFile(metaPath); err == nil && bytes.Equal(existing, meta) {
+		return nil
+	}
+	if err := os.MkdirAll(contextDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create docker context directory: %w", err)
+	}
+	if err := os.WriteFile(metaPath, meta, 0o644); err != nil {
+		return fmt.Errorf("failed to write docker context %s: %w", name, err)
+	}
+	return nil
+}
+
+// clearCurrentDockerContext switches back to the default context if the named
+// context was the current one, so that docker doesn't fail to find it.
+func clearCurrentDockerContext(configDir, name string) error {
+	configPath := filepath.Join(configDir, "config.json")
+	contents, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read docker config: %w", err)
+	}
+	// Keep any fields we don't know about.
+	var config map[string]any
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return fmt.Errorf("failed to parse docker config: %w", err)
+	}
+	if config["currentContext"] != name {
+		return nil
+	}
+	delete(config, "currentContext")
+	contents, err = json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, contents, 0o600); err != nil {
+		return fmt.Errorf("failed to write docker config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+func TestDockerContext(t *testing.T) {
+	// This is the same path the docker CLI uses for the rancher-desktop context.
+	const contextHash = "b547d66a5de60e5f0843aba28283a8875c2ad72e99ba076060ef9ec7c09917c8"
+
+	t.Run("creates the context", func(t *testing.T) {
+		configDir := t.TempDir()
+		require.NoError(t, integration.DockerContext(configDir, "rancher-desktop", "/mnt/wsl/rancher-desktop/run/docker.sock", true))
+		contents, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", contextHash, "meta.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"Name": "rancher-desktop",
+			"Metadata": {"Description": "Rancher Desktop moby context"},
+			"Endpoints": {"docker": {"Host": "unix:///mnt/wsl/rancher-desktop/run/docker.sock", "SkipTLSVerify": false}}
+		}`, string(contents))
+		assert.NoFileExists(t, filepath.Join(configDir, "config.json"))
+	})
+
+	t.Run("removes the context", func(t *testing.T) {
+		configDir := t.TempDir()
+		configPath := filepath.Join(configDir, "config.json")
+		require.NoError(t, integration.DockerContext(configDir, "rancher-desktop", "/var/run/docker.sock", true))
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"currentContext": "rancher-desktop", "credsStore": "pass"}`), 0o600))
+		require.NoError(t, integration.DockerContext(configDir, "rancher-desktop", "", false))
+		assert.NoDirExists(t, filepath.Join(configDir, "contexts", "meta", contextHash))
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"credsStore": "pass"}`, string(contents))
+	})
+
+	t.Run("leaves other current contexts alone", func(t *testing.T) {
+		configDir := t.TempDir()
+		configPath := filepath.Join(configDir, "config.json")
+		config := `{"currentContext": "remote"}`
+		require.NoError(t, os.WriteFile(configPath, []byte(config), 0o600))
+		require.NoError(t, integration.DockerContext(configDir, "rancher-desktop", "", false))
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, config, string(contents))
+	})
+}
@@ -0,0 +1,183 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/dns"
+)
+
+// Problem is an issue with the integration found by Diagnose.
+type Problem struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Fix is a suggestion for the user to fix the problem.
+	Fix string `json:"fix,omitempty"`
+	// Repairable is set if Repair can fix the problem.
+	Repairable bool `json:"repairable"`
+}
+
+// DiagnoseOptions describes the distribution to check.
+type DiagnoseOptions struct {
+	// Root is prepended to system paths; this is used for testing.
+	Root string
+	// HomeDir is the home directory of the user.
+	HomeDir string
+	// DockerSocket is the path to the docker socket; if empty, docker is not
+	// expected to be available and is not checked.
+	DockerSocket string
+	// PathEnv is the value of $PATH to search for the docker CLI.
+	PathEnv string
+}
+
+// Diagnose checks the integration of the distribution with Rancher Desktop,
+// returning the problems found.
+func Diagnose(opts DiagnoseOptions) []Problem {
+	problems := []Problem{}
+	problems = append(problems, diagnoseMarker(opts)...)
+	problems = append(problems, diagnoseWSLConf(opts)...)
+	problems = append(problems, diagnoseDockerPlugins(opts)...)
+	if opts.DockerSocket != "" {
+		problems = append(problems, diagnoseDockerSocket(opts)...)
+		problems = append(problems, diagnoseDockerCLI(opts)...)
+	}
+	return problems
+}
+
+// Repair fixes the problems Diagnose marks as repairable.  Other problems are
+// fixed by Rancher Desktop setting up the integration again.
+func Repair(opts DiagnoseOptions) error {
+	var errs []error
+	for _, link := range danglingDockerPlugins(opts) {
+		if err := os.Remove(link); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", link, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func diagnoseMarker(opts DiagnoseOptions) []Problem {
+	if _, err := os.Stat(filepath.Join(opts.Root, markerPath)); err == nil {
+		return nil
+	}
+	return []Problem{{
+		ID:          "marker",
+		Description: "The distribution is not marked as integrated with Rancher Desktop.",
+		Fix:         "Disable and enable the integration in the Rancher Desktop preferences.",
+	}}
+}
+
+func diagnoseWSLConf(opts DiagnoseOptions) []Problem {
+	contents, err := os.ReadFile(filepath.Join(opts.Root, dns.WSLConfPath))
+	if err != nil {
+		// WSL uses the defaults when wsl.conf doesn't exist.
+		return nil
+	}
+	var problems []Problem
+	if value, ok := dns.GetINIValue(contents, "interop", "enabled"); ok && strings.EqualFold(value, "false") {
+		problems = append(problems, Problem{
+			ID:          "wsl-interop",
+			Description: "Windows interoperability is disabled in /etc/wsl.conf, so Windows tools (including the credential helper) can't be run.",
+			Fix:         "Remove `enabled = false` from the `[interop]` section of /etc/wsl.conf, then run `wsl --terminate` on the distribution.",
+		})
+	}
+	if value, ok := dns.GetINIValue(contents, "interop", "appendWindowsPath"); ok && strings.EqualFold(value, "false") {
+		problems = append(problems, Problem{
+			ID:          "wsl-windows-path",
+			Description: "The Windows PATH is not added to the PATH in the distribution (in /etc/wsl.conf), so Windows tools (including the credential helper) can't be found.",
+			Fix:         "Remove `appendWindowsPath = false` from the `[interop]` section of /etc/wsl.conf, then run `wsl --terminate` on the distribution.",
+		})
+	}
+	return problems
+}
+
+// danglingDockerPlugins returns the docker CLI plugins that are symbolic links
+// to files that no longer exist (usually from a different install location).
+func danglingDockerPlugins(opts DiagnoseOptions) []string {
+	pluginDir := filepath.Join(opts.HomeDir, ".docker", "cli-plugins")
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		link := filepath.Join(pluginDir, entry.Name())
+		if _, err := os.Stat(link); errors.Is(err, os.ErrNotExist) {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+func diagnoseDockerPlugins(opts DiagnoseOptions) []Problem {
+	var problems []Problem
+	for _, link := range danglingDockerPlugins(opts) {
+		target, _ := os.Readlink(link)
+		problems = append(problems, Problem{
+			ID:          "docker-plugin-link",
+			Description: fmt.Sprintf("The docker CLI plugin %s links to %s, which does not exist.", link, target),
+			Repairable:  true,
+		})
+	}
+	return problems
+}
+
+func diagnoseDockerSocket(opts DiagnoseOptions) []Problem {
+	problem := Problem{
+		ID:  "docker-socket",
+		Fix: "Make sure Rancher Desktop is running with the dockerd (moby) container engine.",
+	}
+	info, err := os.Stat(opts.DockerSocket)
+	switch {
+	case err != nil:
+		problem.Description = fmt.Sprintf("The docker socket %s does not exist.", opts.DockerSocket)
+	case info.Mode()&os.ModeSocket == 0:
+		problem.Description = fmt.Sprintf("The docker socket %s is not a socket.", opts.DockerSocket)
+	default:
+		conn, err := net.DialTimeout("unix", opts.DockerSocket, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		problem.Description = fmt.Sprintf("The docker socket %s is not accepting connections: %s", opts.DockerSocket, err)
+	}
+	return []Problem{problem}
+}
+
+func diagnoseDockerCLI(opts DiagnoseOptions) []Problem {
+	for _, dir := range filepath.SplitList(opts.PathEnv) {
+		info, err := os.Stat(filepath.Join(dir, "docker"))
+		if err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return nil
+		}
+	}
+	return []Problem{{
+		ID:          "docker-cli",
+		Description: "The docker CLI was not found in the PATH of the distribution.",
+		Fix:         "Install the docker CLI in the distribution (for example, the `docker-ce-cli` or `docker.io` package).",
+	}}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wsl-helper/pkg/integration"
+)
+
+func problemIDs(problems []integration.Problem) []string {
+	result := []string{}
+	for _, problem := range problems {
+		result = append(result, problem.ID)
+	}
+	return result
+}
+
+func TestDiagnose(t *testing.T) {
+	newOptions := func(t *testing.T) integration.DiagnoseOptions {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".rancher-desktop-integration"), nil, 0o644))
+		binDir := filepath.Join(root, "bin")
+		require.NoError(t, os.MkdirAll(binDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "docker"), nil, 0o755))
+		return integration.DiagnoseOptions{
+			Root:    root,
+			HomeDir: filepath.Join(root, "home"),
+			PathEnv: binDir,
+		}
+	}
+
+	t.Run("no problems", func(t *testing.T) {
+		opts := newOptions(t)
+		listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "docker.sock"))
+		require.NoError(t, err)
+		defer listener.Close()
+		opts.DockerSocket = listener.Addr().String()
+		assert.Empty(t, integration.Diagnose(opts))
+	})
+
+	t.Run("finds problems", func(t *testing.T) {
+		opts := newOptions(t)
+		opts.DockerSocket = filepath.Join(opts.Root, "missing.sock")
+		opts.PathEnv = ""
+		require.NoError(t, os.Remove(filepath.Join(opts.Root, ".rancher-desktop-integration")))
+		wslConf := "[interop]\nenabled = false\nappendWindowsPath = false\n"
+		require.NoError(t, os.WriteFile(filepath.Join(opts.Root, "etc", "wsl.conf"), []byte(wslConf), 0o644))
+		assert.Equal(t,
+			[]string{"marker", "wsl-interop", "wsl-windows-path", "docker-socket", "docker-cli"},
+			problemIDs(integration.Diagnose(opts)))
+	})
+
+	t.Run("repairs plugin links", func(t *testing.T) {
+		opts := newOptions(t)
+		pluginDir := filepath.Join(opts.HomeDir, ".docker", "cli-plugins")
+		require.NoError(t, os.MkdirAll(pluginDir, 0o755))
+		require.NoError(t, os.Symlink("/does/not/exist", filepath.Join(pluginDir, "docker-buildx")))
+		problems := integration.Diagnose(opts)
+		require.Equal(t, []string{"docker-plugin-link"}, problemIDs(problems))
+		assert.True(t, problems[0].Repairable)
+		require.NoError(t, integration.Repair(opts))
+		assert.Empty(t, integration.Diagnose(opts))
+	})
+}
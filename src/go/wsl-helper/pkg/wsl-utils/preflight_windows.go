@@ -0,0 +1,258 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wslutils
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PreflightProblem is an issue with the WSL installation found by Preflight.
+type PreflightProblem struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Fix         string `json:"fix,omitempty"`
+	// Fatal is set if Rancher Desktop can't start because of the problem.
+	Fatal bool `json:"fatal"`
+}
+
+// PreflightReport is the result of Preflight.
+type PreflightReport struct {
+	// Passed is set if there are no fatal problems.
+	Passed   bool               `json:"passed"`
+	Problems []PreflightProblem `json:"problems"`
+	WSL      *WSLInfo           `json:"wsl"`
+	// KernelVersion is the WSL kernel version, if it could be determined.
+	KernelVersion string `json:"kernelVersion,omitempty"`
+}
+
+const (
+	// featureVirtualMachinePlatform is the Windows feature WSL2 needs.
+	featureVirtualMachinePlatform = "VirtualMachinePlatform"
+	// featureWSL is the Windows feature providing the in-box WSL.
+	featureWSL = "Microsoft-Windows-Subsystem-Linux"
+	// minimumKernelMajor and minimumKernelMinor are the oldest WSL kernel
+	// supported; older kernels come from the standalone kernel MSI that is no
+	// longer updated.
+	minimumKernelMajor = 5
+	minimumKernelMinor = 10
+)
+
+// knownBadVersion describes releases of the Store WSL that are known to break
+// Rancher Desktop; versions in [first, last] are affected.
+type knownBadVersion struct {
+	first, last PackageVersion
+	reason      string
+}
+
+var knownBadVersions = []knownBadVersion{
+	{
+		first:  PackageVersion{Major: 0},
+		last:   PackageVersion{Major: 0, Minor: 0xffff, Build: 0xffff, Revision: 0xffff},
+		reason: "Preview releases of WSL from the Microsoft Store are not supported.",
+	},
+}
+
+func (v PackageVersion) compare(other PackageVersion) int {
+	for _, pair := range [][2]uint16{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Build, other.Build},
+		{v.Revision, other.Revision},
+	} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Preflight checks that WSL is installed and usable by Rancher Desktop.
+func Preflight(ctx context.Context) (*PreflightReport, error) {
+	info, err := GetWSLInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	features, err := getOptionalFeatures(ctx, featureVirtualMachinePlatform, featureWSL)
+	if err != nil {
+		// This can fail in restricted environments; let WSL itself complain.
+		logrus.WithError(err).Debug("Failed to check Windows features")
+		features = nil
+	}
+	kernelVersion := ""
+	if info.Installed && !info.Inbox {
+		// The in-box WSL doesn't support --version.
+		if output, err := runWSLExeFromContext(ctx)(ctx, "--version"); err != nil {
+			logrus.WithError(err).Debug("Failed to get the WSL kernel version")
+		} else {
+			kernelVersion = parseKernelVersion(output)
+		}
+	}
+	return evaluatePreflight(info, features, kernelVersion), nil
+}
+
+// evaluatePreflight builds the report from what was found.  features maps the
+// Windows features to whether they are enabled; it is nil if unknown.
+func evaluatePreflight(info *WSLInfo, features map[string]bool, kernelVersion string) *PreflightReport {
+	report := &PreflightReport{Problems: []PreflightProblem{}, WSL: info, KernelVersion: kernelVersion}
+	add := func(problem PreflightProblem) {
+		report.Problems = append(report.Problems, problem)
+	}
+
+	if enabled, ok := features[featureVirtualMachinePlatform]; ok && !enabled {
+		add(PreflightProblem{
+			ID:          "feature-virtual-machine-platform",
+			Description: "The Virtual Machine Platform Windows feature, which WSL 2 needs, is not enabled.",
+			Fix:         "Run `wsl.exe --install --no-distribution` as an administrator, then restart Windows.",
+			Fatal:       true,
+		})
+	}
+	if !info.Installed {
+		add(PreflightProblem{
+			ID:          "wsl-not-installed",
+			Description: "Windows Subsystem for Linux is not installed.",
+			Fix:         "Run `wsl.exe --install --no-distribution` as an administrator, then restart Windows.",
+			Fatal:       true,
+		})
+		report.Passed = !hasFatal(report.Problems)
+		return report
+	}
+	if enabled, ok := features[featureWSL]; info.Inbox && ok && !enabled {
+		add(PreflightProblem{
+			ID:          "feature-wsl",
+			Description: "The Windows Subsystem for Linux Windows feature is not enabled.",
+			Fix:         "Run `wsl.exe --install --no-distribution` as an administrator, then restart Windows.",
+			Fatal:       true,
+		})
+	}
+	if !info.HasKernel {
+		add(PreflightProblem{
+			ID:          "wsl-kernel-missing",
+			Description: "The WSL 2 kernel is not installed.",
+			Fix:         "Run `wsl.exe --update`.",
+			Fatal:       true,
+		})
+	}
+	if info.Inbox {
+		add(PreflightProblem{
+			ID:          "wsl-inbox",
+			Description: "The version of WSL shipped with Windows is installed; it is no longer updated.",
+			Fix:         "Run `wsl.exe --update` to switch to WSL from the Microsoft Store.",
+		})
+	}
+	for _, bad := range knownBadVersions {
+		if !info.Inbox && info.Version.compare(bad.first) >= 0 && info.Version.compare(bad.last) <= 0 {
+			add(PreflightProblem{
+				ID:          "wsl-known-bad-version",
+				Description: fmt.Sprintf("WSL %s is installed.  %s", info.Version, bad.reason),
+				Fix:         "Run `wsl.exe --update`.",
+			})
+		}
+	}
+	if major, minor, ok := kernelMajorMinor(kernelVersion); ok {
+		if major < minimumKernelMajor || (major == minimumKernelMajor && minor < minimumKernelMinor) {
+			add(PreflightProblem{
+				ID: "wsl-kernel-too-old",
+				Description: fmt.Sprintf("The WSL kernel %s is too old; version %d.%d or later is needed.",
+					kernelVersion, minimumKernelMajor, minimumKernelMinor),
+				Fix: "Run `wsl.exe --update`; if a custom kernel is set in .wslconfig, update it.",
+			})
+		}
+	}
+	report.Passed = !hasFatal(report.Problems)
+	return report
+}
+
+func hasFatal(problems []PreflightProblem) bool {
+	for _, problem := range problems {
+		if problem.Fatal {
+			return true
+		}
+	}
+	return false
+}
+
+// runWSLExeFromContext returns the function to run wsl.exe, which may be
+// overridden for testing.
+func runWSLExeFromContext(ctx context.Context) func(context.Context, ...string) (string, error) {
+	if f := ctx.Value(&kWSLExeOverride); f != nil {
+		return f.(func(context.Context, ...string) (string, error))
+	}
+	return runWSLExe
+}
+
+var kernelVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+(?:\.\d+)?(?:-[\w.]+)?`)
+
+// parseKernelVersion extracts the kernel version from the output of
+// `wsl.exe --version`.  The labels are localized, so the kernel line is found
+// by name if possible, and by position (it is the second line) otherwise.
+func parseKernelVersion(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line), "kernel") {
+			return kernelVersionPattern.FindString(line)
+		}
+	}
+	if len(lines) > 1 {
+		return kernelVersionPattern.FindString(lines[1])
+	}
+	return ""
+}
+
+func kernelMajorMinor(version string) (int, int, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	return major, minor, err1 == nil && err2 == nil
+}
+
+// getOptionalFeatures returns whether the given Windows features are enabled.
+// This uses WMI, which (unlike DISM) doesn't need administrator privileges.
+func getOptionalFeatures(ctx context.Context, names ...string) (map[string]bool, error) {
+	var filters []string
+	for _, name := range names {
+		filters = append(filters, fmt.Sprintf("Name='%s'", name))
+	}
+	script := fmt.Sprintf(
+		`Get-CimInstance -ClassName Win32_OptionalFeature -Filter "%s" | ForEach-Object { "$($_.Name)=$($_.InstallState)" }`,
+		strings.Join(filters, " OR "))
+	output, err := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Windows features: %w", err)
+	}
+	result := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		name, state, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok {
+			// Win32_OptionalFeature.InstallState: 1 is enabled.
+			result[name] = state == "1"
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wslutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKernelVersion(t *testing.T) {
+	t.Run("labelled", func(t *testing.T) {
+		output := "WSL version: 2.0.14.0\r\nKernel version: 5.15.133.1-1\r\nWSLg version: 1.0.59\r\n"
+		assert.Equal(t, "5.15.133.1-1", parseKernelVersion(output))
+	})
+	t.Run("localized", func(t *testing.T) {
+		output := "Version de WSL : 2.0.14.0\r\nVersion du noyau : 5.10.102.1\r\n"
+		assert.Equal(t, "5.10.102.1", parseKernelVersion(output))
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", parseKernelVersion(""))
+	})
+}
+
+func TestEvaluatePreflight(t *testing.T) {
+	ids := func(report *PreflightReport) []string {
+		result := []string{}
+		for _, problem := range report.Problems {
+			result = append(result, problem.ID)
+		}
+		return result
+	}
+	storeVersion := PackageVersion{Major: 2, Minor: 0, Build: 14}
+
+	t.Run("healthy", func(t *testing.T) {
+		info := &WSLInfo{Installed: true, HasKernel: true, Version: storeVersion}
+		features := map[string]bool{featureVirtualMachinePlatform: true, featureWSL: true}
+		report := evaluatePreflight(info, features, "5.15.133.1-1")
+		assert.True(t, report.Passed)
+		assert.Empty(t, report.Problems)
+	})
+	t.Run("not installed", func(t *testing.T) {
+		report := evaluatePreflight(&WSLInfo{}, map[string]bool{featureVirtualMachinePlatform: false}, "")
+		assert.False(t, report.Passed)
+		assert.Equal(t, []string{"feature-virtual-machine-platform", "wsl-not-installed"}, ids(report))
+	})
+	t.Run("inbox without kernel", func(t *testing.T) {
+		info := &WSLInfo{Installed: true, Inbox: true}
+		report := evaluatePreflight(info, nil, "")
+		assert.False(t, report.Passed)
+		assert.Equal(t, []string{"wsl-kernel-missing", "wsl-inbox"}, ids(report))
+	})
+	t.Run("old kernel", func(t *testing.T) {
+		info := &WSLInfo{Installed: true, HasKernel: true, Version: storeVersion}
+		report := evaluatePreflight(info, nil, "5.4.72")
+		assert.True(t, report.Passed)
+		assert.Equal(t, []string{"wsl-kernel-too-old"}, ids(report))
+	})
+	t.Run("known bad version", func(t *testing.T) {
+		info := &WSLInfo{Installed: true, HasKernel: true, Version: PackageVersion{Minor: 70}}
+		report := evaluatePreflight(info, nil, "")
+		assert.True(t, report.Passed)
+		assert.Equal(t, []string{"wsl-known-bad-version"}, ids(report))
+	})
+}
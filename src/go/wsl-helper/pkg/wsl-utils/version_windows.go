@@ -20,12 +20,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"unsafe"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/wslexe"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/windows"
 )
@@ -214,32 +213,13 @@ func getPackageVersion(fullName string) (*PackageVersion, error) {
 // runWSLExe runs WSL.exe and returns the standard output.
 // This can be replaced for testing.
 func runWSLExe(ctx context.Context, args ...string) (string, error) {
-	systemDir, err := windows.GetSystemDirectory()
-	if err != nil {
-		return "", fmt.Errorf("failed to get system directory: %w", err)
-	}
-	wslPath := filepath.Join(systemDir, "wsl.exe")
-	cmd := exec.CommandContext(ctx, wslPath, "--status")
-	cmd.Stderr = os.Stderr
-	stdout, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get WSL status: %w", err)
-	}
-	output := windows.UTF16PtrToString(
-		(*uint16)(unsafe.Pointer(unsafe.SliceData(append(stdout, 0, 0)))),
-	)
-	return output, nil
+	return wslexe.Run(ctx, args...)
 }
 
 // isInboxWSLInstalled checks if the "in-box" version of WSL is installed,
 // returning whether it's installed, and whether the kernel is installed
 func isInboxWSLInstalled(ctx context.Context) (bool, bool, error) {
-	runWSLExeFunc := runWSLExe
-	if f := ctx.Value(&kWSLExeOverride); f != nil {
-		runWSLExeFunc = f.(func(context.Context, ...string) (string, error))
-	}
-
-	output, err := runWSLExeFunc(ctx, "--status")
+	output, err := runWSLExeFromContext(ctx)(ctx, "--status")
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) && exitErr.ExitCode() == wslExitNotInstalled {
 		return false, false, nil
@@ -0,0 +1,19 @@
+module github.com/rancher-sandbox/rancher-desktop/src/go/wslexe
+
+go 1.21
+
+require (
+	github.com/rancher-sandbox/rancher-desktop/src/go/retry v0.0.0
+	github.com/sirupsen/logrus v1.7.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/sys v0.8.0
+	golang.org/x/text v0.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/rancher-sandbox/rancher-desktop/src/go/retry => ../retry
@@ -0,0 +1,282 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wslexe runs wsl.exe.  It decodes the UTF-16 output wsl.exe uses for
+// its own messages, classifies failures, retries the transient errors the WSL
+// service returns while it is busy, and applies timeouts.
+package wslexe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/retry"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Kind classifies why running wsl.exe failed.
+type Kind int
+
+const (
+	// KindFailed is any failure not classified otherwise.
+	KindFailed Kind = iota
+	// KindNotInstalled means WSL is not installed or not enabled.
+	KindNotInstalled
+	// KindDistroNotFound means the distribution given does not exist.
+	KindDistroNotFound
+	// KindTransient means the WSL service was busy; trying again may work.
+	KindTransient
+	// KindTimeout means wsl.exe did not finish in time.
+	KindTimeout
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotInstalled:
+		return "not installed"
+	case KindDistroNotFound:
+		return "distribution not found"
+	case KindTransient:
+		return "transient"
+	case KindTimeout:
+		return "timeout"
+	}
+	return "failed"
+}
+
+// Error is returned when running wsl.exe fails.
+type Error struct {
+	Args []string
+	Kind Kind
+	// ExitCode is the exit code of wsl.exe, or -1 if it did not exit.
+	ExitCode int
+	// Output is the decoded output of wsl.exe (both stdout and stderr).
+	Output string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("wsl.exe %s failed (%s): %s", strings.Join(e.Args, " "), e.Kind, e.Err)
+	if output := strings.TrimSpace(e.Output); output != "" {
+		message += ": " + output
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsKind returns whether the error is an Error of the given kind.
+func IsKind(err error, kind Kind) bool {
+	var wslErr *Error
+	return errors.As(err, &wslErr) && wslErr.Kind == kind
+}
+
+// Options control how wsl.exe is run.
+type Options struct {
+	// Timeout is how long each attempt may take; zero means no limit.
+	Timeout time.Duration
+	// Retries is how many more times to try after a transient failure.
+	// Commands that are not safe to repeat should leave this as zero.
+	Retries int
+	// Env holds additional environment variables, as KEY=value.
+	Env []string
+}
+
+// DefaultOptions are used by Run; they suit quick commands such as listing or
+// terminating distributions.
+var DefaultOptions = Options{Timeout: time.Minute, Retries: 3}
+
+var (
+	// retryDelay is how long to wait before the first retry; it doubles for
+	// each one after that.
+	retryDelay = 500 * time.Millisecond
+	// runOnce runs wsl.exe once, returning its stdout and stderr.  This is a
+	// variable for testing.
+	runOnce = execWSL
+)
+
+// Run runs wsl.exe with the default options and returns its decoded output.
+func Run(ctx context.Context, args ...string) (string, error) {
+	return RunWithOptions(ctx, DefaultOptions, args...)
+}
+
+// RunWithOptions runs wsl.exe and returns its decoded output.  If it fails,
+// the error is an *Error.
+func RunWithOptions(ctx context.Context, options Options, args ...string) (string, error) {
+	policy := retry.Policy{
+		InitialDelay: retryDelay,
+		Jitter:       0.2,
+		MaxAttempts:  options.Retries + 1,
+		Retryable: func(err error) bool {
+			return IsKind(err, KindTransient)
+		},
+		Notify: func(err error, delay time.Duration) {
+			logrus.Debugf("%s; retrying in %s", err, delay)
+		},
+	}
+	stdout, err := retry.DoValue(ctx, policy, func(ctx context.Context) (string, error) {
+		stdout, err := runAttempt(ctx, options, args)
+		// Avoid returning a nil *Error as a non-nil error.
+		if err != nil {
+			return stdout, err
+		}
+		return stdout, nil
+	})
+	if _, ok := err.(*Error); err != nil && !ok {
+		// The context was done while waiting to retry.
+		return "", &Error{Args: args, Kind: KindFailed, ExitCode: -1, Err: ctx.Err()}
+	}
+	return stdout, err
+}
+
+// runAttempt runs wsl.exe once, applying the timeout.
+func runAttempt(ctx context.Context, options Options, args []string) (string, *Error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	rawStdout, rawStderr, err := runOnce(ctx, options.Env, args)
+	stdout := DecodeOutput(rawStdout)
+	if err == nil {
+		return stdout, nil
+	}
+	output := strings.TrimSpace(strings.Join([]string{stdout, DecodeOutput(rawStderr)}, "\n"))
+	result := &Error{Args: args, ExitCode: -1, Output: output, Err: err}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Kind = KindTimeout
+	} else {
+		result.Kind = classify(err, output)
+	}
+	return stdout, result
+}
+
+// These are matched against the output of wsl.exe; newer versions print a
+// (non-localized) error code, while older ones only print a message.
+var (
+	notInstalledPatterns = []string{
+		"WSL_E_WSL_OPTIONAL_COMPONENT_REQUIRED",
+		"Windows Subsystem for Linux has not been enabled",
+	}
+	distroNotFoundPatterns = []string{
+		"WSL_E_DISTRO_NOT_FOUND",
+		"There is no distribution with the supplied name",
+	}
+	transientPatterns = []string{
+		"0x80070490", // ERROR_NOT_FOUND
+		"Element not found",
+		"0x800700aa", // ERROR_BUSY
+		"The requested resource is in use",
+		"0x80070425", // ERROR_SERVICE_CANNOT_ACCEPT_CTRL
+		"The service cannot accept control messages at this time",
+	}
+)
+
+// classify determines the kind of failure from the error and output.
+func classify(err error, output string) Kind {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return KindNotInstalled
+	}
+	lower := strings.ToLower(output)
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if strings.Contains(lower, strings.ToLower(pattern)) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case matches(notInstalledPatterns):
+		return KindNotInstalled
+	case matches(distroNotFoundPatterns):
+		return KindDistroNotFound
+	case matches(transientPatterns):
+		return KindTransient
+	}
+	return KindFailed
+}
+
+// DecodeOutput converts output from wsl.exe to a string.  wsl.exe writes its
+// own messages as UTF-16, but passes through the (normally UTF-8) output of
+// commands run in a distribution unchanged, so the encoding is detected.  Line
+// endings are normalized to "\n".
+func DecodeOutput(raw []byte) string {
+	var output string
+	if isUTF16(raw) {
+		decoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+		decoded, err := decoder.Bytes(raw)
+		if err != nil {
+			decoded = raw
+		}
+		output = string(decoded)
+	} else {
+		output = string(bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")))
+	}
+	return strings.ReplaceAll(output, "\r\n", "\n")
+}
+
+// isUTF16 guesses whether the output is UTF-16LE: either it has a byte order
+// mark, or most of its high bytes are zero (as for ASCII text).
+func isUTF16(raw []byte) bool {
+	if bytes.HasPrefix(raw, []byte{0xff, 0xfe}) {
+		return true
+	}
+	if len(raw) < 2 || len(raw)%2 != 0 {
+		return false
+	}
+	zeros := 0
+	for i := 1; i < len(raw); i += 2 {
+		if raw[i] == 0 {
+			zeros++
+		}
+	}
+	return zeros*2 > len(raw)/2
+}
+
+// newCommand returns the command to run wsl.exe with the given additional
+// environment variables and arguments.
+func newCommand(ctx context.Context, env []string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, executablePath(), args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	configureCommand(cmd)
+	return cmd
+}
+
+// execWSL runs wsl.exe once.
+func execWSL(ctx context.Context, env []string, args []string) ([]byte, []byte, error) {
+	cmd := newCommand(ctx, env, args)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wslexe
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+type fakeExitError int
+
+func (e fakeExitError) Error() string { return "exit status" }
+func (e fakeExitError) ExitCode() int { return int(e) }
+
+func encodeUTF16(t *testing.T, s string) []byte {
+	encoded, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(s))
+	require.NoError(t, err)
+	return encoded
+}
+
+// fakeWSL replaces runOnce with a function returning the given results in
+// order, and returns a pointer to the number of calls made.
+func fakeWSL(t *testing.T, results ...func(ctx context.Context) ([]byte, error)) *int {
+	oldRunOnce, oldRetryDelay := runOnce, retryDelay
+	t.Cleanup(func() {
+		runOnce, retryDelay = oldRunOnce, oldRetryDelay
+	})
+	retryDelay = time.Millisecond
+	calls := 0
+	runOnce = func(ctx context.Context, _ []string, _ []string) ([]byte, []byte, error) {
+		result := results[calls]
+		calls++
+		stdout, err := result(ctx)
+		return stdout, nil, err
+	}
+	return &calls
+}
+
+func TestDecodeOutput(t *testing.T) {
+	assert.Equal(t, "Ubuntu\nrancher-desktop\n", DecodeOutput(encodeUTF16(t, "Ubuntu\r\nrancher-desktop\r\n")))
+	assert.Equal(t, "Ubuntu\n", DecodeOutput(append([]byte{0xff, 0xfe}, encodeUTF16(t, "Ubuntu\n")...)))
+	assert.Equal(t, "abcd\n", DecodeOutput([]byte("abcd\n")))
+	assert.Equal(t, "abc", DecodeOutput([]byte("\xef\xbb\xbfabc")))
+	assert.Equal(t, "", DecodeOutput(nil))
+}
+
+func TestRunRetriesTransientErrors(t *testing.T) {
+	ctx := context.Background()
+	busy := func(context.Context) ([]byte, error) {
+		return encodeUTF16(t, "Element not found.\r\nError code: Wsl/Service/0x80070490\r\n"), fakeExitError(1)
+	}
+
+	t.Run("recovers", func(t *testing.T) {
+		calls := fakeWSL(t, busy, func(context.Context) ([]byte, error) {
+			return encodeUTF16(t, "Ubuntu\r\n"), nil
+		})
+		output, err := RunWithOptions(ctx, Options{Retries: 2}, "--list", "--quiet")
+		require.NoError(t, err)
+		assert.Equal(t, "Ubuntu\n", output)
+		assert.Equal(t, 2, *calls)
+	})
+	t.Run("gives up", func(t *testing.T) {
+		calls := fakeWSL(t, busy, busy, busy)
+		_, err := RunWithOptions(ctx, Options{Retries: 2}, "--terminate", "rancher-desktop")
+		assert.True(t, IsKind(err, KindTransient), "unexpected error %v", err)
+		assert.Equal(t, 3, *calls)
+		var wslErr *Error
+		require.ErrorAs(t, err, &wslErr)
+		assert.Equal(t, 1, wslErr.ExitCode)
+		assert.Contains(t, wslErr.Error(), "Element not found.")
+	})
+}
+
+func TestRunClassifiesErrors(t *testing.T) {
+	ctx := context.Background()
+	t.Run("distro not found", func(t *testing.T) {
+		calls := fakeWSL(t, func(context.Context) ([]byte, error) {
+			return encodeUTF16(t, "There is no distribution with the supplied name.\r\n"), fakeExitError(1)
+		})
+		_, err := RunWithOptions(ctx, Options{Retries: 2}, "--terminate", "missing")
+		assert.True(t, IsKind(err, KindDistroNotFound), "unexpected error %v", err)
+		assert.Equal(t, 1, *calls)
+	})
+	t.Run("not installed", func(t *testing.T) {
+		fakeWSL(t, func(context.Context) ([]byte, error) {
+			return nil, &exec.Error{Name: "wsl.exe", Err: exec.ErrNotFound}
+		})
+		_, err := Run(ctx, "--list")
+		assert.True(t, IsKind(err, KindNotInstalled), "unexpected error %v", err)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		fakeWSL(t, func(ctx context.Context) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		_, err := RunWithOptions(ctx, Options{Timeout: time.Millisecond, Retries: 2}, "--list")
+		assert.True(t, IsKind(err, KindTimeout), "unexpected error %v", err)
+	})
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wslexe
+
+import (
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// executablePath returns the path to wsl.exe; it is looked up in the system
+// directory, so that another wsl.exe in PATH is not used by mistake.
+func executablePath() string {
+	systemDir, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "wsl.exe"
+	}
+	return filepath.Join(systemDir, "wsl.exe")
+}
+
+// configureCommand prevents wsl.exe from opening a console window.
+func configureCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NO_WINDOW}
+}
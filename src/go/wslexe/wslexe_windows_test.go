@@ -0,0 +1,42 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wslexe
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+)
+
+func TestNewCommand(t *testing.T) {
+	cmd := newCommand(context.Background(), []string{"WSLENV=RD_INSTANCE/u"}, []string{"--list", "--quiet"})
+
+	systemDir, err := windows.GetSystemDirectory()
+	require.NoError(t, err)
+	assert.True(t, strings.EqualFold(filepath.Join(systemDir, "wsl.exe"), cmd.Path), "unexpected path %s", cmd.Path)
+	assert.Equal(t, []string{"--list", "--quiet"}, cmd.Args[1:])
+	assert.Contains(t, cmd.Env, "WSLENV=RD_INSTANCE/u")
+	// Every wsl.exe run by the helpers (e.g. stopping containers on shutdown)
+	// must not flash a console window.
+	require.NotNil(t, cmd.SysProcAttr)
+	assert.NotZero(t, cmd.SysProcAttr.CreationFlags&windows.CREATE_NO_WINDOW, "CREATE_NO_WINDOW is not set")
+}